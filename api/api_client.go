@@ -1,7 +1,10 @@
 package api
 
 import (
-	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/ava-labs/avalanchego/api/admin"
 	"github.com/ava-labs/avalanchego/api/health"
@@ -37,21 +40,63 @@ type APIClient struct {
 // Returns a new API client for a node at [ipAddr]:[port].
 type NewAPIClientF func(ipAddr string, port uint16) Client
 
+// ClientOptions configures the clients returned by NewAPIClientWithOptions
+type ClientOptions struct {
+	// Sent on all the requests, e.g. auth tokens, tracing headers or
+	// User-Agent, for nodes behind authenticating proxies
+	Headers http.Header
+	// Timeout of the calls whose context has no deadline, so that a hung
+	// node doesn't hang them. Calls that need another timeout, e.g. fast
+	// health checks or heavy debug endpoints, give it on their context.
+	// No timeout if 0.
+	RequestTimeout time.Duration
+}
+
 // NewAPIClient initialize most of avalanchego apis
 func NewAPIClient(ipAddr string, port uint16) Client {
-	uri := fmt.Sprintf("http://%s:%d", ipAddr, port)
+	return newAPIClient(ipAddr, port, ClientOptions{}, nil, nil)
+}
+
+// NewAPIClientWithHeaders returns a NewAPIClientF whose clients send
+// [headers] on all their requests, e.g. auth tokens, tracing headers or
+// User-Agent, for nodes behind authenticating proxies.
+func NewAPIClientWithHeaders(headers http.Header) NewAPIClientF {
+	return NewAPIClientWithOptions(ClientOptions{Headers: headers})
+}
+
+// NewAPIClientWithOptions returns a NewAPIClientF whose clients
+// are configured by [options]
+func NewAPIClientWithOptions(options ClientOptions) NewAPIClientF {
+	return func(ipAddr string, port uint16) Client {
+		return newAPIClient(ipAddr, port, options, nil, nil)
+	}
+}
+
+// Returns a new API client for a node at [ipAddr]:[port], configured by
+// [options]. If [recorder] or [replayer] is set, its calls are recorded
+// or replayed by it.
+func newAPIClient(ipAddr string, port uint16, options ClientOptions, recorder *Recorder, replayer *Replayer) Client {
+	host := net.JoinHostPort(ipAddr, strconv.Itoa(int(port)))
+	uri := "http://" + host
+	c := &caller{
+		host:     host,
+		headers:  options.Headers.Clone(),
+		timeout:  options.RequestTimeout,
+		recorder: recorder,
+		replayer: replayer,
+	}
 	return &APIClient{
-		platform:     platformvm.NewClient(uri),
-		xChain:       avm.NewClient(uri, "X"),
-		xChainWallet: avm.NewWalletClient(uri, "X"),
-		cChain:       evm.NewCChainClient(uri),
-		cChainEth:    NewEthClient(ipAddr, uint(port)), // wrapper over ethclient.Client
-		info:         info.NewClient(uri),
-		health:       health.NewClient(uri),
-		keystore:     keystore.NewClient(uri),
-		admin:        admin.NewClient(uri),
-		pindex:       indexer.NewClient(uri + "/ext/index/P/block"),
-		cindex:       indexer.NewClient(uri + "/ext/index/C/block"),
+		platform:     &platformClient{name: "PChainAPI", client: platformvm.NewClient(uri), caller: c},
+		xChain:       &xChainClient{name: "XChainAPI", client: avm.NewClient(uri, "X"), caller: c},
+		xChainWallet: &xChainWalletClient{name: "XChainWalletAPI", client: avm.NewWalletClient(uri, "X"), caller: c},
+		cChain:       &cChainClient{name: "CChainAPI", client: evm.NewCChainClient(uri), caller: c},
+		cChainEth:    newEthClient(ipAddr, uint(port), "C", options.Headers), // wrapper over ethclient.Client
+		info:         &infoClient{name: "InfoAPI", client: info.NewClient(uri), caller: c},
+		health:       &healthClient{name: "HealthAPI", client: health.NewClient(uri), caller: c},
+		keystore:     &keystoreClient{name: "KeystoreAPI", client: keystore.NewClient(uri), caller: c},
+		admin:        &adminClient{name: "AdminAPI", client: admin.NewClient(uri), caller: c},
+		pindex:       &indexerClient{name: "PChainIndexAPI", client: indexer.NewClient(uri + "/ext/index/P/block"), caller: c},
+		cindex:       &indexerClient{name: "CChainIndexAPI", client: indexer.NewClient(uri + "/ext/index/C/block"), caller: c},
 	}
 }
 
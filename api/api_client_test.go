@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIClientWithHeaders(t *testing.T) {
+	require := require.New(t)
+	headersCh := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headersCh <- r.Header
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"healthy":true}}`))
+	}))
+	defer server.Close()
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(err)
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer token")
+	headers.Set("User-Agent", "test-runner")
+	client := NewAPIClientWithHeaders(headers)(host, uint16(port))
+	_, err = client.HealthAPI().Health(context.Background(), nil)
+	require.NoError(err)
+	received := <-headersCh
+	require.Equal("Bearer token", received.Get("Authorization"))
+	require.Equal("test-runner", received.Get("User-Agent"))
+
+	// only sent by the clients they were given to
+	client = NewAPIClient(host, uint16(port))
+	_, err = client.HealthAPI().Health(context.Background(), nil)
+	require.NoError(err)
+	received = <-headersCh
+	require.Empty(received.Get("Authorization"))
+	require.NotEqual("test-runner", received.Get("User-Agent"))
+	_, err = http.Get(server.URL)
+	require.NoError(err)
+	received = <-headersCh
+	require.Empty(received.Get("Authorization"))
+
+	// the headers given on the call are sent too
+	client = NewAPIClientWithHeaders(headers)(host, uint16(port))
+	_, err = client.HealthAPI().Health(context.Background(), nil, rpc.WithHeader("X-Request-ID", "1"))
+	require.NoError(err)
+	received = <-headersCh
+	require.Equal("Bearer token", received.Get("Authorization"))
+	require.Equal("1", received.Get("X-Request-ID"))
+}
+
+func TestNewAPIClientWithRequestTimeout(t *testing.T) {
+	require := require.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"healthy":true}}`))
+	}))
+	defer server.Close()
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(err)
+
+	client := NewAPIClientWithOptions(ClientOptions{RequestTimeout: 50 * time.Millisecond})(host, uint16(port))
+	_, err = client.HealthAPI().Health(context.Background(), nil)
+	require.ErrorIs(err, context.DeadlineExceeded)
+
+	// the deadline of the call overrides the timeout of the client
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reply, err := client.HealthAPI().Health(ctx, nil)
+	require.NoError(err)
+	require.True(reply.Healthy)
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/rpc"
+)
+
+// caller runs the calls of the avalanchego API clients of an APIClient,
+// adding the options of the APIClient to each of them, and recording or
+// replaying them. The avalanchego clients send their requests with
+// http.DefaultClient, that is shared by the whole process, so this is
+// done on each call instead.
+type caller struct {
+	// host:port of the node, identifying it in the recorded calls
+	host string
+	// sent on all the requests, before the headers given on the call
+	headers http.Header
+	// timeout of the calls whose context has no deadline, none if 0
+	timeout time.Duration
+	// if set, the calls are recorded by it
+	recorder *Recorder
+	// if set, the calls are replayed by it, without being sent
+	replayer *Replayer
+}
+
+// The results of an API call returning more than one
+type results2[R1, R2 any] struct {
+	R1 R1
+	R2 R2
+}
+
+type results3[R1, R2, R3 any] struct {
+	R1 R1
+	R2 R2
+	R3 R3
+}
+
+// Runs [do] with the options of the call, [options], after the ones of [c],
+// bounded by the timeout of [c] if [ctx] has no deadline.
+// [method] is the API method called, and [args] its arguments but the
+// context and options, that identify the call when recorded.
+func call[R any](
+	ctx context.Context,
+	c *caller,
+	method string,
+	args []interface{},
+	options []rpc.Option,
+	do func(context.Context, []rpc.Option) (R, error),
+) (R, error) {
+	if c.replayer != nil {
+		var result R
+		err := c.replayer.replay(c.host, method, args, &result)
+		return result, err
+	}
+	if len(c.headers) != 0 {
+		options = append([]rpc.Option{withHeaders(c.headers)}, options...)
+	}
+	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	result, err := do(ctx, options)
+	if c.recorder != nil {
+		c.recorder.record(c.host, method, args, result, err)
+	}
+	return result, err
+}
+
+// Returns an option adding [headers] to the request, unlike
+// rpc.WithHeader, that only keeps one value per header
+func withHeaders(headers http.Header) rpc.Option {
+	return func(o *rpc.Options) {
+		for k, values := range headers {
+			for _, v := range values {
+				o.Headers().Add(k, v)
+			}
+		}
+	}
+}
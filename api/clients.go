@@ -0,0 +1,843 @@
+package api
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	avagoapi "github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/api/admin"
+	"github.com/ava-labs/avalanchego/api/health"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/api/keystore"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/indexer"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/upgrade"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/ava-labs/avalanchego/vms/components/gas"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	platformapi "github.com/ava-labs/avalanchego/vms/platformvm/api"
+	"github.com/ava-labs/avalanchego/vms/platformvm/signer"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
+	"github.com/ava-labs/coreth/plugin/evm"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/exp/slog"
+)
+
+// interface compliance
+var (
+	_ platformvm.Client = (*platformClient)(nil)
+	_ avm.Client        = (*xChainClient)(nil)
+	_ avm.WalletClient  = (*xChainWalletClient)(nil)
+	_ evm.Client        = (*cChainClient)(nil)
+	_ info.Client       = (*infoClient)(nil)
+	_ health.Client     = (*healthClient)(nil)
+	_ keystore.Client   = (*keystoreClient)(nil)
+	_ admin.Client      = (*adminClient)(nil)
+	_ indexer.Client    = (*indexerClient)(nil)
+)
+
+// platformClient sends the calls of a P-Chain API client through a caller
+type platformClient struct {
+	// identifies the API in the recorded calls
+	name   string
+	client platformvm.Client
+	caller *caller
+}
+
+func (c *platformClient) GetHeight(ctx context.Context, options ...rpc.Option) (uint64, error) {
+	return call(ctx, c.caller, c.name+".GetHeight", nil, options, func(ctx context.Context, options []rpc.Option) (uint64, error) {
+		return c.client.GetHeight(ctx, options...)
+	})
+}
+
+func (c *platformClient) GetProposedHeight(ctx context.Context, options ...rpc.Option) (uint64, error) {
+	return call(ctx, c.caller, c.name+".GetProposedHeight", nil, options, func(ctx context.Context, options []rpc.Option) (uint64, error) {
+		return c.client.GetProposedHeight(ctx, options...)
+	})
+}
+
+func (c *platformClient) ExportKey(ctx context.Context, user avagoapi.UserPass, address ids.ShortID, options ...rpc.Option) (*secp256k1.PrivateKey, error) {
+	return call(ctx, c.caller, c.name+".ExportKey", []interface{}{user, address}, options, func(ctx context.Context, options []rpc.Option) (*secp256k1.PrivateKey, error) {
+		return c.client.ExportKey(ctx, user, address, options...)
+	})
+}
+
+func (c *platformClient) GetBalance(ctx context.Context, addrs []ids.ShortID, options ...rpc.Option) (*platformvm.GetBalanceResponse, error) {
+	return call(ctx, c.caller, c.name+".GetBalance", []interface{}{addrs}, options, func(ctx context.Context, options []rpc.Option) (*platformvm.GetBalanceResponse, error) {
+		return c.client.GetBalance(ctx, addrs, options...)
+	})
+}
+
+func (c *platformClient) ListAddresses(ctx context.Context, user avagoapi.UserPass, options ...rpc.Option) ([]ids.ShortID, error) {
+	return call(ctx, c.caller, c.name+".ListAddresses", []interface{}{user}, options, func(ctx context.Context, options []rpc.Option) ([]ids.ShortID, error) {
+		return c.client.ListAddresses(ctx, user, options...)
+	})
+}
+
+func (c *platformClient) GetUTXOs(ctx context.Context, addrs []ids.ShortID, limit uint32, startAddress ids.ShortID, startUTXOID ids.ID, options ...rpc.Option) ([][]byte, ids.ShortID, ids.ID, error) {
+	r, err := call(ctx, c.caller, c.name+".GetUTXOs", []interface{}{addrs, limit, startAddress, startUTXOID}, options, func(ctx context.Context, options []rpc.Option) (results3[[][]byte, ids.ShortID, ids.ID], error) {
+		r1, r2, r3, err := c.client.GetUTXOs(ctx, addrs, limit, startAddress, startUTXOID, options...)
+		return results3[[][]byte, ids.ShortID, ids.ID]{r1, r2, r3}, err
+	})
+	return r.R1, r.R2, r.R3, err
+}
+
+func (c *platformClient) GetAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, sourceChain string, limit uint32, startAddress ids.ShortID, startUTXOID ids.ID, options ...rpc.Option) ([][]byte, ids.ShortID, ids.ID, error) {
+	r, err := call(ctx, c.caller, c.name+".GetAtomicUTXOs", []interface{}{addrs, sourceChain, limit, startAddress, startUTXOID}, options, func(ctx context.Context, options []rpc.Option) (results3[[][]byte, ids.ShortID, ids.ID], error) {
+		r1, r2, r3, err := c.client.GetAtomicUTXOs(ctx, addrs, sourceChain, limit, startAddress, startUTXOID, options...)
+		return results3[[][]byte, ids.ShortID, ids.ID]{r1, r2, r3}, err
+	})
+	return r.R1, r.R2, r.R3, err
+}
+
+func (c *platformClient) GetSubnet(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (platformvm.GetSubnetClientResponse, error) {
+	return call(ctx, c.caller, c.name+".GetSubnet", []interface{}{subnetID}, options, func(ctx context.Context, options []rpc.Option) (platformvm.GetSubnetClientResponse, error) {
+		return c.client.GetSubnet(ctx, subnetID, options...)
+	})
+}
+
+func (c *platformClient) GetSubnets(ctx context.Context, subnetIDs []ids.ID, options ...rpc.Option) ([]platformvm.ClientSubnet, error) {
+	return call(ctx, c.caller, c.name+".GetSubnets", []interface{}{subnetIDs}, options, func(ctx context.Context, options []rpc.Option) ([]platformvm.ClientSubnet, error) {
+		return c.client.GetSubnets(ctx, subnetIDs, options...)
+	})
+}
+
+func (c *platformClient) GetStakingAssetID(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".GetStakingAssetID", []interface{}{subnetID}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.GetStakingAssetID(ctx, subnetID, options...)
+	})
+}
+
+func (c *platformClient) GetCurrentValidators(ctx context.Context, subnetID ids.ID, nodeIDs []ids.NodeID, options ...rpc.Option) ([]platformvm.ClientPermissionlessValidator, error) {
+	return call(ctx, c.caller, c.name+".GetCurrentValidators", []interface{}{subnetID, nodeIDs}, options, func(ctx context.Context, options []rpc.Option) ([]platformvm.ClientPermissionlessValidator, error) {
+		return c.client.GetCurrentValidators(ctx, subnetID, nodeIDs, options...)
+	})
+}
+
+func (c *platformClient) GetL1Validator(ctx context.Context, validationID ids.ID, options ...rpc.Option) (platformvm.L1Validator, uint64, error) {
+	r, err := call(ctx, c.caller, c.name+".GetL1Validator", []interface{}{validationID}, options, func(ctx context.Context, options []rpc.Option) (results2[platformvm.L1Validator, uint64], error) {
+		r1, r2, err := c.client.GetL1Validator(ctx, validationID, options...)
+		return results2[platformvm.L1Validator, uint64]{r1, r2}, err
+	})
+	return r.R1, r.R2, err
+}
+
+func (c *platformClient) GetCurrentSupply(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (uint64, uint64, error) {
+	r, err := call(ctx, c.caller, c.name+".GetCurrentSupply", []interface{}{subnetID}, options, func(ctx context.Context, options []rpc.Option) (results2[uint64, uint64], error) {
+		r1, r2, err := c.client.GetCurrentSupply(ctx, subnetID, options...)
+		return results2[uint64, uint64]{r1, r2}, err
+	})
+	return r.R1, r.R2, err
+}
+
+func (c *platformClient) SampleValidators(ctx context.Context, subnetID ids.ID, sampleSize uint16, options ...rpc.Option) ([]ids.NodeID, error) {
+	return call(ctx, c.caller, c.name+".SampleValidators", []interface{}{subnetID, sampleSize}, options, func(ctx context.Context, options []rpc.Option) ([]ids.NodeID, error) {
+		return c.client.SampleValidators(ctx, subnetID, sampleSize, options...)
+	})
+}
+
+func (c *platformClient) GetBlockchainStatus(ctx context.Context, blockchainID string, options ...rpc.Option) (status.BlockchainStatus, error) {
+	return call(ctx, c.caller, c.name+".GetBlockchainStatus", []interface{}{blockchainID}, options, func(ctx context.Context, options []rpc.Option) (status.BlockchainStatus, error) {
+		return c.client.GetBlockchainStatus(ctx, blockchainID, options...)
+	})
+}
+
+func (c *platformClient) ValidatedBy(ctx context.Context, blockchainID ids.ID, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".ValidatedBy", []interface{}{blockchainID}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.ValidatedBy(ctx, blockchainID, options...)
+	})
+}
+
+func (c *platformClient) Validates(ctx context.Context, subnetID ids.ID, options ...rpc.Option) ([]ids.ID, error) {
+	return call(ctx, c.caller, c.name+".Validates", []interface{}{subnetID}, options, func(ctx context.Context, options []rpc.Option) ([]ids.ID, error) {
+		return c.client.Validates(ctx, subnetID, options...)
+	})
+}
+
+func (c *platformClient) GetBlockchains(ctx context.Context, options ...rpc.Option) ([]platformvm.APIBlockchain, error) {
+	return call(ctx, c.caller, c.name+".GetBlockchains", nil, options, func(ctx context.Context, options []rpc.Option) ([]platformvm.APIBlockchain, error) {
+		return c.client.GetBlockchains(ctx, options...)
+	})
+}
+
+func (c *platformClient) IssueTx(ctx context.Context, tx []byte, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".IssueTx", []interface{}{tx}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.IssueTx(ctx, tx, options...)
+	})
+}
+
+func (c *platformClient) GetTx(ctx context.Context, txID ids.ID, options ...rpc.Option) ([]byte, error) {
+	return call(ctx, c.caller, c.name+".GetTx", []interface{}{txID}, options, func(ctx context.Context, options []rpc.Option) ([]byte, error) {
+		return c.client.GetTx(ctx, txID, options...)
+	})
+}
+
+func (c *platformClient) GetTxStatus(ctx context.Context, txID ids.ID, options ...rpc.Option) (*platformvm.GetTxStatusResponse, error) {
+	return call(ctx, c.caller, c.name+".GetTxStatus", []interface{}{txID}, options, func(ctx context.Context, options []rpc.Option) (*platformvm.GetTxStatusResponse, error) {
+		return c.client.GetTxStatus(ctx, txID, options...)
+	})
+}
+
+func (c *platformClient) GetStake(ctx context.Context, addrs []ids.ShortID, validatorsOnly bool, options ...rpc.Option) (map[ids.ID]uint64, [][]byte, error) {
+	r, err := call(ctx, c.caller, c.name+".GetStake", []interface{}{addrs, validatorsOnly}, options, func(ctx context.Context, options []rpc.Option) (results2[map[ids.ID]uint64, [][]byte], error) {
+		r1, r2, err := c.client.GetStake(ctx, addrs, validatorsOnly, options...)
+		return results2[map[ids.ID]uint64, [][]byte]{r1, r2}, err
+	})
+	return r.R1, r.R2, err
+}
+
+func (c *platformClient) GetMinStake(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (uint64, uint64, error) {
+	r, err := call(ctx, c.caller, c.name+".GetMinStake", []interface{}{subnetID}, options, func(ctx context.Context, options []rpc.Option) (results2[uint64, uint64], error) {
+		r1, r2, err := c.client.GetMinStake(ctx, subnetID, options...)
+		return results2[uint64, uint64]{r1, r2}, err
+	})
+	return r.R1, r.R2, err
+}
+
+func (c *platformClient) GetTotalStake(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (uint64, error) {
+	return call(ctx, c.caller, c.name+".GetTotalStake", []interface{}{subnetID}, options, func(ctx context.Context, options []rpc.Option) (uint64, error) {
+		return c.client.GetTotalStake(ctx, subnetID, options...)
+	})
+}
+
+func (c *platformClient) GetRewardUTXOs(ctx context.Context, args *avagoapi.GetTxArgs, options ...rpc.Option) ([][]byte, error) {
+	return call(ctx, c.caller, c.name+".GetRewardUTXOs", []interface{}{args}, options, func(ctx context.Context, options []rpc.Option) ([][]byte, error) {
+		return c.client.GetRewardUTXOs(ctx, args, options...)
+	})
+}
+
+func (c *platformClient) GetTimestamp(ctx context.Context, options ...rpc.Option) (time.Time, error) {
+	return call(ctx, c.caller, c.name+".GetTimestamp", nil, options, func(ctx context.Context, options []rpc.Option) (time.Time, error) {
+		return c.client.GetTimestamp(ctx, options...)
+	})
+}
+
+func (c *platformClient) GetValidatorsAt(ctx context.Context, subnetID ids.ID, height platformapi.Height, options ...rpc.Option) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+	return call(ctx, c.caller, c.name+".GetValidatorsAt", []interface{}{subnetID, height}, options, func(ctx context.Context, options []rpc.Option) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+		return c.client.GetValidatorsAt(ctx, subnetID, height, options...)
+	})
+}
+
+func (c *platformClient) GetBlock(ctx context.Context, blockID ids.ID, options ...rpc.Option) ([]byte, error) {
+	return call(ctx, c.caller, c.name+".GetBlock", []interface{}{blockID}, options, func(ctx context.Context, options []rpc.Option) ([]byte, error) {
+		return c.client.GetBlock(ctx, blockID, options...)
+	})
+}
+
+func (c *platformClient) GetBlockByHeight(ctx context.Context, height uint64, options ...rpc.Option) ([]byte, error) {
+	return call(ctx, c.caller, c.name+".GetBlockByHeight", []interface{}{height}, options, func(ctx context.Context, options []rpc.Option) ([]byte, error) {
+		return c.client.GetBlockByHeight(ctx, height, options...)
+	})
+}
+
+func (c *platformClient) GetFeeConfig(ctx context.Context, options ...rpc.Option) (*gas.Config, error) {
+	return call(ctx, c.caller, c.name+".GetFeeConfig", nil, options, func(ctx context.Context, options []rpc.Option) (*gas.Config, error) {
+		return c.client.GetFeeConfig(ctx, options...)
+	})
+}
+
+func (c *platformClient) GetFeeState(ctx context.Context, options ...rpc.Option) (gas.State, gas.Price, time.Time, error) {
+	r, err := call(ctx, c.caller, c.name+".GetFeeState", nil, options, func(ctx context.Context, options []rpc.Option) (results3[gas.State, gas.Price, time.Time], error) {
+		r1, r2, r3, err := c.client.GetFeeState(ctx, options...)
+		return results3[gas.State, gas.Price, time.Time]{r1, r2, r3}, err
+	})
+	return r.R1, r.R2, r.R3, err
+}
+
+// xChainClient sends the calls of a X-Chain API client through a caller
+type xChainClient struct {
+	// identifies the API in the recorded calls
+	name   string
+	client avm.Client
+	caller *caller
+}
+
+func (c *xChainClient) GetBlock(ctx context.Context, blkID ids.ID, options ...rpc.Option) ([]byte, error) {
+	return call(ctx, c.caller, c.name+".GetBlock", []interface{}{blkID}, options, func(ctx context.Context, options []rpc.Option) ([]byte, error) {
+		return c.client.GetBlock(ctx, blkID, options...)
+	})
+}
+
+func (c *xChainClient) GetBlockByHeight(ctx context.Context, height uint64, options ...rpc.Option) ([]byte, error) {
+	return call(ctx, c.caller, c.name+".GetBlockByHeight", []interface{}{height}, options, func(ctx context.Context, options []rpc.Option) ([]byte, error) {
+		return c.client.GetBlockByHeight(ctx, height, options...)
+	})
+}
+
+func (c *xChainClient) GetHeight(ctx context.Context, options ...rpc.Option) (uint64, error) {
+	return call(ctx, c.caller, c.name+".GetHeight", nil, options, func(ctx context.Context, options []rpc.Option) (uint64, error) {
+		return c.client.GetHeight(ctx, options...)
+	})
+}
+
+func (c *xChainClient) GetTxStatus(ctx context.Context, txID ids.ID, options ...rpc.Option) (choices.Status, error) {
+	return call(ctx, c.caller, c.name+".GetTxStatus", []interface{}{txID}, options, func(ctx context.Context, options []rpc.Option) (choices.Status, error) {
+		return c.client.GetTxStatus(ctx, txID, options...)
+	})
+}
+
+func (c *xChainClient) GetTx(ctx context.Context, txID ids.ID, options ...rpc.Option) ([]byte, error) {
+	return call(ctx, c.caller, c.name+".GetTx", []interface{}{txID}, options, func(ctx context.Context, options []rpc.Option) ([]byte, error) {
+		return c.client.GetTx(ctx, txID, options...)
+	})
+}
+
+func (c *xChainClient) GetUTXOs(ctx context.Context, addrs []ids.ShortID, limit uint32, startAddress ids.ShortID, startUTXOID ids.ID, options ...rpc.Option) ([][]byte, ids.ShortID, ids.ID, error) {
+	r, err := call(ctx, c.caller, c.name+".GetUTXOs", []interface{}{addrs, limit, startAddress, startUTXOID}, options, func(ctx context.Context, options []rpc.Option) (results3[[][]byte, ids.ShortID, ids.ID], error) {
+		r1, r2, r3, err := c.client.GetUTXOs(ctx, addrs, limit, startAddress, startUTXOID, options...)
+		return results3[[][]byte, ids.ShortID, ids.ID]{r1, r2, r3}, err
+	})
+	return r.R1, r.R2, r.R3, err
+}
+
+func (c *xChainClient) GetAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, sourceChain string, limit uint32, startAddress ids.ShortID, startUTXOID ids.ID, options ...rpc.Option) ([][]byte, ids.ShortID, ids.ID, error) {
+	r, err := call(ctx, c.caller, c.name+".GetAtomicUTXOs", []interface{}{addrs, sourceChain, limit, startAddress, startUTXOID}, options, func(ctx context.Context, options []rpc.Option) (results3[[][]byte, ids.ShortID, ids.ID], error) {
+		r1, r2, r3, err := c.client.GetAtomicUTXOs(ctx, addrs, sourceChain, limit, startAddress, startUTXOID, options...)
+		return results3[[][]byte, ids.ShortID, ids.ID]{r1, r2, r3}, err
+	})
+	return r.R1, r.R2, r.R3, err
+}
+
+func (c *xChainClient) GetAssetDescription(ctx context.Context, assetID string, options ...rpc.Option) (*avm.GetAssetDescriptionReply, error) {
+	return call(ctx, c.caller, c.name+".GetAssetDescription", []interface{}{assetID}, options, func(ctx context.Context, options []rpc.Option) (*avm.GetAssetDescriptionReply, error) {
+		return c.client.GetAssetDescription(ctx, assetID, options...)
+	})
+}
+
+func (c *xChainClient) GetBalance(ctx context.Context, addr ids.ShortID, assetID string, includePartial bool, options ...rpc.Option) (*avm.GetBalanceReply, error) {
+	return call(ctx, c.caller, c.name+".GetBalance", []interface{}{addr, assetID, includePartial}, options, func(ctx context.Context, options []rpc.Option) (*avm.GetBalanceReply, error) {
+		return c.client.GetBalance(ctx, addr, assetID, includePartial, options...)
+	})
+}
+
+func (c *xChainClient) GetAllBalances(ctx context.Context, addr ids.ShortID, includePartial bool, options ...rpc.Option) ([]avm.Balance, error) {
+	return call(ctx, c.caller, c.name+".GetAllBalances", []interface{}{addr, includePartial}, options, func(ctx context.Context, options []rpc.Option) ([]avm.Balance, error) {
+		return c.client.GetAllBalances(ctx, addr, includePartial, options...)
+	})
+}
+
+func (c *xChainClient) CreateAsset(ctx context.Context, user avagoapi.UserPass, from []ids.ShortID, changeAddr ids.ShortID, name string, symbol string, denomination byte, holders []*avm.ClientHolder, minters []avm.ClientOwners, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".CreateAsset", []interface{}{user, from, changeAddr, name, symbol, denomination, holders, minters}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.CreateAsset(ctx, user, from, changeAddr, name, symbol, denomination, holders, minters, options...)
+	})
+}
+
+func (c *xChainClient) CreateFixedCapAsset(ctx context.Context, user avagoapi.UserPass, from []ids.ShortID, changeAddr ids.ShortID, name string, symbol string, denomination byte, holders []*avm.ClientHolder, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".CreateFixedCapAsset", []interface{}{user, from, changeAddr, name, symbol, denomination, holders}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.CreateFixedCapAsset(ctx, user, from, changeAddr, name, symbol, denomination, holders, options...)
+	})
+}
+
+func (c *xChainClient) CreateVariableCapAsset(ctx context.Context, user avagoapi.UserPass, from []ids.ShortID, changeAddr ids.ShortID, name string, symbol string, denomination byte, minters []avm.ClientOwners, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".CreateVariableCapAsset", []interface{}{user, from, changeAddr, name, symbol, denomination, minters}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.CreateVariableCapAsset(ctx, user, from, changeAddr, name, symbol, denomination, minters, options...)
+	})
+}
+
+func (c *xChainClient) CreateNFTAsset(ctx context.Context, user avagoapi.UserPass, from []ids.ShortID, changeAddr ids.ShortID, name string, symbol string, minters []avm.ClientOwners, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".CreateNFTAsset", []interface{}{user, from, changeAddr, name, symbol, minters}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.CreateNFTAsset(ctx, user, from, changeAddr, name, symbol, minters, options...)
+	})
+}
+
+func (c *xChainClient) CreateAddress(ctx context.Context, user avagoapi.UserPass, options ...rpc.Option) (ids.ShortID, error) {
+	return call(ctx, c.caller, c.name+".CreateAddress", []interface{}{user}, options, func(ctx context.Context, options []rpc.Option) (ids.ShortID, error) {
+		return c.client.CreateAddress(ctx, user, options...)
+	})
+}
+
+func (c *xChainClient) ListAddresses(ctx context.Context, user avagoapi.UserPass, options ...rpc.Option) ([]ids.ShortID, error) {
+	return call(ctx, c.caller, c.name+".ListAddresses", []interface{}{user}, options, func(ctx context.Context, options []rpc.Option) ([]ids.ShortID, error) {
+		return c.client.ListAddresses(ctx, user, options...)
+	})
+}
+
+func (c *xChainClient) ExportKey(ctx context.Context, user avagoapi.UserPass, addr ids.ShortID, options ...rpc.Option) (*secp256k1.PrivateKey, error) {
+	return call(ctx, c.caller, c.name+".ExportKey", []interface{}{user, addr}, options, func(ctx context.Context, options []rpc.Option) (*secp256k1.PrivateKey, error) {
+		return c.client.ExportKey(ctx, user, addr, options...)
+	})
+}
+
+func (c *xChainClient) ImportKey(ctx context.Context, user avagoapi.UserPass, privateKey *secp256k1.PrivateKey, options ...rpc.Option) (ids.ShortID, error) {
+	return call(ctx, c.caller, c.name+".ImportKey", []interface{}{user, privateKey}, options, func(ctx context.Context, options []rpc.Option) (ids.ShortID, error) {
+		return c.client.ImportKey(ctx, user, privateKey, options...)
+	})
+}
+
+func (c *xChainClient) Mint(ctx context.Context, user avagoapi.UserPass, from []ids.ShortID, changeAddr ids.ShortID, amount uint64, assetID string, to ids.ShortID, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".Mint", []interface{}{user, from, changeAddr, amount, assetID, to}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.Mint(ctx, user, from, changeAddr, amount, assetID, to, options...)
+	})
+}
+
+func (c *xChainClient) SendNFT(ctx context.Context, user avagoapi.UserPass, from []ids.ShortID, changeAddr ids.ShortID, assetID string, groupID uint32, to ids.ShortID, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".SendNFT", []interface{}{user, from, changeAddr, assetID, groupID, to}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.SendNFT(ctx, user, from, changeAddr, assetID, groupID, to, options...)
+	})
+}
+
+func (c *xChainClient) MintNFT(ctx context.Context, user avagoapi.UserPass, from []ids.ShortID, changeAddr ids.ShortID, assetID string, payload []byte, to ids.ShortID, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".MintNFT", []interface{}{user, from, changeAddr, assetID, payload, to}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.MintNFT(ctx, user, from, changeAddr, assetID, payload, to, options...)
+	})
+}
+
+func (c *xChainClient) Import(ctx context.Context, user avagoapi.UserPass, to ids.ShortID, sourceChain string, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".Import", []interface{}{user, to, sourceChain}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.Import(ctx, user, to, sourceChain, options...)
+	})
+}
+
+func (c *xChainClient) Export(ctx context.Context, user avagoapi.UserPass, from []ids.ShortID, changeAddr ids.ShortID, amount uint64, to ids.ShortID, toChainIDAlias string, assetID string, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".Export", []interface{}{user, from, changeAddr, amount, to, toChainIDAlias, assetID}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.Export(ctx, user, from, changeAddr, amount, to, toChainIDAlias, assetID, options...)
+	})
+}
+
+func (c *xChainClient) IssueTx(ctx context.Context, tx []byte, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".IssueTx", []interface{}{tx}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.IssueTx(ctx, tx, options...)
+	})
+}
+
+func (c *xChainClient) Send(ctx context.Context, user avagoapi.UserPass, from []ids.ShortID, changeAddr ids.ShortID, amount uint64, assetID string, to ids.ShortID, memo string, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".Send", []interface{}{user, from, changeAddr, amount, assetID, to, memo}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.Send(ctx, user, from, changeAddr, amount, assetID, to, memo, options...)
+	})
+}
+
+func (c *xChainClient) SendMultiple(ctx context.Context, user avagoapi.UserPass, from []ids.ShortID, changeAddr ids.ShortID, outputs []avm.ClientSendOutput, memo string, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".SendMultiple", []interface{}{user, from, changeAddr, outputs, memo}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.SendMultiple(ctx, user, from, changeAddr, outputs, memo, options...)
+	})
+}
+
+// xChainWalletClient sends the calls of a X-Chain wallet API client through a caller
+type xChainWalletClient struct {
+	// identifies the API in the recorded calls
+	name   string
+	client avm.WalletClient
+	caller *caller
+}
+
+func (c *xChainWalletClient) IssueTx(ctx context.Context, tx []byte, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".IssueTx", []interface{}{tx}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.IssueTx(ctx, tx, options...)
+	})
+}
+
+func (c *xChainWalletClient) Send(ctx context.Context, user avagoapi.UserPass, from []ids.ShortID, changeAddr ids.ShortID, amount uint64, assetID string, to ids.ShortID, memo string, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".Send", []interface{}{user, from, changeAddr, amount, assetID, to, memo}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.Send(ctx, user, from, changeAddr, amount, assetID, to, memo, options...)
+	})
+}
+
+func (c *xChainWalletClient) SendMultiple(ctx context.Context, user avagoapi.UserPass, from []ids.ShortID, changeAddr ids.ShortID, outputs []avm.ClientSendOutput, memo string, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".SendMultiple", []interface{}{user, from, changeAddr, outputs, memo}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.SendMultiple(ctx, user, from, changeAddr, outputs, memo, options...)
+	})
+}
+
+// cChainClient sends the calls of a C-Chain API client through a caller
+type cChainClient struct {
+	// identifies the API in the recorded calls
+	name   string
+	client evm.Client
+	caller *caller
+}
+
+func (c *cChainClient) IssueTx(ctx context.Context, txBytes []byte, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".IssueTx", []interface{}{txBytes}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.IssueTx(ctx, txBytes, options...)
+	})
+}
+
+func (c *cChainClient) GetAtomicTxStatus(ctx context.Context, txID ids.ID, options ...rpc.Option) (evm.Status, error) {
+	return call(ctx, c.caller, c.name+".GetAtomicTxStatus", []interface{}{txID}, options, func(ctx context.Context, options []rpc.Option) (evm.Status, error) {
+		return c.client.GetAtomicTxStatus(ctx, txID, options...)
+	})
+}
+
+func (c *cChainClient) GetAtomicTx(ctx context.Context, txID ids.ID, options ...rpc.Option) ([]byte, error) {
+	return call(ctx, c.caller, c.name+".GetAtomicTx", []interface{}{txID}, options, func(ctx context.Context, options []rpc.Option) ([]byte, error) {
+		return c.client.GetAtomicTx(ctx, txID, options...)
+	})
+}
+
+func (c *cChainClient) GetAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, sourceChain string, limit uint32, startAddress ids.ShortID, startUTXOID ids.ID, options ...rpc.Option) ([][]byte, ids.ShortID, ids.ID, error) {
+	r, err := call(ctx, c.caller, c.name+".GetAtomicUTXOs", []interface{}{addrs, sourceChain, limit, startAddress, startUTXOID}, options, func(ctx context.Context, options []rpc.Option) (results3[[][]byte, ids.ShortID, ids.ID], error) {
+		r1, r2, r3, err := c.client.GetAtomicUTXOs(ctx, addrs, sourceChain, limit, startAddress, startUTXOID, options...)
+		return results3[[][]byte, ids.ShortID, ids.ID]{r1, r2, r3}, err
+	})
+	return r.R1, r.R2, r.R3, err
+}
+
+func (c *cChainClient) ExportKey(ctx context.Context, userPass avagoapi.UserPass, addr common.Address, options ...rpc.Option) (*secp256k1.PrivateKey, string, error) {
+	r, err := call(ctx, c.caller, c.name+".ExportKey", []interface{}{userPass, addr}, options, func(ctx context.Context, options []rpc.Option) (results2[*secp256k1.PrivateKey, string], error) {
+		r1, r2, err := c.client.ExportKey(ctx, userPass, addr, options...)
+		return results2[*secp256k1.PrivateKey, string]{r1, r2}, err
+	})
+	return r.R1, r.R2, err
+}
+
+func (c *cChainClient) ImportKey(ctx context.Context, userPass avagoapi.UserPass, privateKey *secp256k1.PrivateKey, options ...rpc.Option) (common.Address, error) {
+	return call(ctx, c.caller, c.name+".ImportKey", []interface{}{userPass, privateKey}, options, func(ctx context.Context, options []rpc.Option) (common.Address, error) {
+		return c.client.ImportKey(ctx, userPass, privateKey, options...)
+	})
+}
+
+func (c *cChainClient) Import(ctx context.Context, userPass avagoapi.UserPass, to common.Address, sourceChain string, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".Import", []interface{}{userPass, to, sourceChain}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.Import(ctx, userPass, to, sourceChain, options...)
+	})
+}
+
+func (c *cChainClient) ExportAVAX(ctx context.Context, userPass avagoapi.UserPass, amount uint64, to ids.ShortID, targetChain string, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".ExportAVAX", []interface{}{userPass, amount, to, targetChain}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.ExportAVAX(ctx, userPass, amount, to, targetChain, options...)
+	})
+}
+
+func (c *cChainClient) Export(ctx context.Context, userPass avagoapi.UserPass, amount uint64, to ids.ShortID, targetChain string, assetID string, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".Export", []interface{}{userPass, amount, to, targetChain, assetID}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.Export(ctx, userPass, amount, to, targetChain, assetID, options...)
+	})
+}
+
+func (c *cChainClient) StartCPUProfiler(ctx context.Context, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".StartCPUProfiler", nil, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.StartCPUProfiler(ctx, options...)
+	})
+	return err
+}
+
+func (c *cChainClient) StopCPUProfiler(ctx context.Context, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".StopCPUProfiler", nil, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.StopCPUProfiler(ctx, options...)
+	})
+	return err
+}
+
+func (c *cChainClient) MemoryProfile(ctx context.Context, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".MemoryProfile", nil, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.MemoryProfile(ctx, options...)
+	})
+	return err
+}
+
+func (c *cChainClient) LockProfile(ctx context.Context, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".LockProfile", nil, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.LockProfile(ctx, options...)
+	})
+	return err
+}
+
+func (c *cChainClient) SetLogLevel(ctx context.Context, level slog.Level, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".SetLogLevel", []interface{}{level}, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.SetLogLevel(ctx, level, options...)
+	})
+	return err
+}
+
+func (c *cChainClient) GetVMConfig(ctx context.Context, options ...rpc.Option) (*evm.Config, error) {
+	return call(ctx, c.caller, c.name+".GetVMConfig", nil, options, func(ctx context.Context, options []rpc.Option) (*evm.Config, error) {
+		return c.client.GetVMConfig(ctx, options...)
+	})
+}
+
+// infoClient sends the calls of a info API client through a caller
+type infoClient struct {
+	// identifies the API in the recorded calls
+	name   string
+	client info.Client
+	caller *caller
+}
+
+func (c *infoClient) GetNodeVersion(ctx context.Context, options ...rpc.Option) (*info.GetNodeVersionReply, error) {
+	return call(ctx, c.caller, c.name+".GetNodeVersion", nil, options, func(ctx context.Context, options []rpc.Option) (*info.GetNodeVersionReply, error) {
+		return c.client.GetNodeVersion(ctx, options...)
+	})
+}
+
+func (c *infoClient) GetNodeID(ctx context.Context, options ...rpc.Option) (ids.NodeID, *signer.ProofOfPossession, error) {
+	r, err := call(ctx, c.caller, c.name+".GetNodeID", nil, options, func(ctx context.Context, options []rpc.Option) (results2[ids.NodeID, *signer.ProofOfPossession], error) {
+		r1, r2, err := c.client.GetNodeID(ctx, options...)
+		return results2[ids.NodeID, *signer.ProofOfPossession]{r1, r2}, err
+	})
+	return r.R1, r.R2, err
+}
+
+func (c *infoClient) GetNodeIP(ctx context.Context, options ...rpc.Option) (netip.AddrPort, error) {
+	return call(ctx, c.caller, c.name+".GetNodeIP", nil, options, func(ctx context.Context, options []rpc.Option) (netip.AddrPort, error) {
+		return c.client.GetNodeIP(ctx, options...)
+	})
+}
+
+func (c *infoClient) GetNetworkID(ctx context.Context, options ...rpc.Option) (uint32, error) {
+	return call(ctx, c.caller, c.name+".GetNetworkID", nil, options, func(ctx context.Context, options []rpc.Option) (uint32, error) {
+		return c.client.GetNetworkID(ctx, options...)
+	})
+}
+
+func (c *infoClient) GetNetworkName(ctx context.Context, options ...rpc.Option) (string, error) {
+	return call(ctx, c.caller, c.name+".GetNetworkName", nil, options, func(ctx context.Context, options []rpc.Option) (string, error) {
+		return c.client.GetNetworkName(ctx, options...)
+	})
+}
+
+func (c *infoClient) GetBlockchainID(ctx context.Context, alias string, options ...rpc.Option) (ids.ID, error) {
+	return call(ctx, c.caller, c.name+".GetBlockchainID", []interface{}{alias}, options, func(ctx context.Context, options []rpc.Option) (ids.ID, error) {
+		return c.client.GetBlockchainID(ctx, alias, options...)
+	})
+}
+
+func (c *infoClient) Peers(ctx context.Context, nodeIDs []ids.NodeID, options ...rpc.Option) ([]info.Peer, error) {
+	return call(ctx, c.caller, c.name+".Peers", []interface{}{nodeIDs}, options, func(ctx context.Context, options []rpc.Option) ([]info.Peer, error) {
+		return c.client.Peers(ctx, nodeIDs, options...)
+	})
+}
+
+func (c *infoClient) IsBootstrapped(ctx context.Context, chainID string, options ...rpc.Option) (bool, error) {
+	return call(ctx, c.caller, c.name+".IsBootstrapped", []interface{}{chainID}, options, func(ctx context.Context, options []rpc.Option) (bool, error) {
+		return c.client.IsBootstrapped(ctx, chainID, options...)
+	})
+}
+
+func (c *infoClient) GetTxFee(ctx context.Context, options ...rpc.Option) (*info.GetTxFeeResponse, error) {
+	return call(ctx, c.caller, c.name+".GetTxFee", nil, options, func(ctx context.Context, options []rpc.Option) (*info.GetTxFeeResponse, error) {
+		return c.client.GetTxFee(ctx, options...)
+	})
+}
+
+func (c *infoClient) Upgrades(ctx context.Context, options ...rpc.Option) (*upgrade.Config, error) {
+	return call(ctx, c.caller, c.name+".Upgrades", nil, options, func(ctx context.Context, options []rpc.Option) (*upgrade.Config, error) {
+		return c.client.Upgrades(ctx, options...)
+	})
+}
+
+func (c *infoClient) Uptime(ctx context.Context, options ...rpc.Option) (*info.UptimeResponse, error) {
+	return call(ctx, c.caller, c.name+".Uptime", nil, options, func(ctx context.Context, options []rpc.Option) (*info.UptimeResponse, error) {
+		return c.client.Uptime(ctx, options...)
+	})
+}
+
+func (c *infoClient) GetVMs(ctx context.Context, options ...rpc.Option) (map[ids.ID][]string, error) {
+	return call(ctx, c.caller, c.name+".GetVMs", nil, options, func(ctx context.Context, options []rpc.Option) (map[ids.ID][]string, error) {
+		return c.client.GetVMs(ctx, options...)
+	})
+}
+
+// healthClient sends the calls of a health API client through a caller
+type healthClient struct {
+	// identifies the API in the recorded calls
+	name   string
+	client health.Client
+	caller *caller
+}
+
+func (c *healthClient) Readiness(ctx context.Context, tags []string, options ...rpc.Option) (*health.APIReply, error) {
+	return call(ctx, c.caller, c.name+".Readiness", []interface{}{tags}, options, func(ctx context.Context, options []rpc.Option) (*health.APIReply, error) {
+		return c.client.Readiness(ctx, tags, options...)
+	})
+}
+
+func (c *healthClient) Health(ctx context.Context, tags []string, options ...rpc.Option) (*health.APIReply, error) {
+	return call(ctx, c.caller, c.name+".Health", []interface{}{tags}, options, func(ctx context.Context, options []rpc.Option) (*health.APIReply, error) {
+		return c.client.Health(ctx, tags, options...)
+	})
+}
+
+func (c *healthClient) Liveness(ctx context.Context, tags []string, options ...rpc.Option) (*health.APIReply, error) {
+	return call(ctx, c.caller, c.name+".Liveness", []interface{}{tags}, options, func(ctx context.Context, options []rpc.Option) (*health.APIReply, error) {
+		return c.client.Liveness(ctx, tags, options...)
+	})
+}
+
+// keystoreClient sends the calls of a keystore API client through a caller
+type keystoreClient struct {
+	// identifies the API in the recorded calls
+	name   string
+	client keystore.Client
+	caller *caller
+}
+
+func (c *keystoreClient) CreateUser(ctx context.Context, user avagoapi.UserPass, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".CreateUser", []interface{}{user}, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.CreateUser(ctx, user, options...)
+	})
+	return err
+}
+
+func (c *keystoreClient) ListUsers(ctx context.Context, options ...rpc.Option) ([]string, error) {
+	return call(ctx, c.caller, c.name+".ListUsers", nil, options, func(ctx context.Context, options []rpc.Option) ([]string, error) {
+		return c.client.ListUsers(ctx, options...)
+	})
+}
+
+func (c *keystoreClient) ExportUser(ctx context.Context, user avagoapi.UserPass, options ...rpc.Option) ([]byte, error) {
+	return call(ctx, c.caller, c.name+".ExportUser", []interface{}{user}, options, func(ctx context.Context, options []rpc.Option) ([]byte, error) {
+		return c.client.ExportUser(ctx, user, options...)
+	})
+}
+
+func (c *keystoreClient) ImportUser(ctx context.Context, importTo avagoapi.UserPass, exportedUser []byte, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".ImportUser", []interface{}{importTo, exportedUser}, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.ImportUser(ctx, importTo, exportedUser, options...)
+	})
+	return err
+}
+
+func (c *keystoreClient) DeleteUser(ctx context.Context, user avagoapi.UserPass, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".DeleteUser", []interface{}{user}, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.DeleteUser(ctx, user, options...)
+	})
+	return err
+}
+
+// adminClient sends the calls of a admin API client through a caller
+type adminClient struct {
+	// identifies the API in the recorded calls
+	name   string
+	client admin.Client
+	caller *caller
+}
+
+func (c *adminClient) StartCPUProfiler(ctx context.Context, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".StartCPUProfiler", nil, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.StartCPUProfiler(ctx, options...)
+	})
+	return err
+}
+
+func (c *adminClient) StopCPUProfiler(ctx context.Context, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".StopCPUProfiler", nil, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.StopCPUProfiler(ctx, options...)
+	})
+	return err
+}
+
+func (c *adminClient) MemoryProfile(ctx context.Context, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".MemoryProfile", nil, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.MemoryProfile(ctx, options...)
+	})
+	return err
+}
+
+func (c *adminClient) LockProfile(ctx context.Context, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".LockProfile", nil, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.LockProfile(ctx, options...)
+	})
+	return err
+}
+
+func (c *adminClient) Alias(ctx context.Context, endpoint string, alias string, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".Alias", []interface{}{endpoint, alias}, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.Alias(ctx, endpoint, alias, options...)
+	})
+	return err
+}
+
+func (c *adminClient) AliasChain(ctx context.Context, chainID string, alias string, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".AliasChain", []interface{}{chainID, alias}, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.AliasChain(ctx, chainID, alias, options...)
+	})
+	return err
+}
+
+func (c *adminClient) GetChainAliases(ctx context.Context, chainID string, options ...rpc.Option) ([]string, error) {
+	return call(ctx, c.caller, c.name+".GetChainAliases", []interface{}{chainID}, options, func(ctx context.Context, options []rpc.Option) ([]string, error) {
+		return c.client.GetChainAliases(ctx, chainID, options...)
+	})
+}
+
+func (c *adminClient) Stacktrace(ctx context.Context, options ...rpc.Option) error {
+	_, err := call(ctx, c.caller, c.name+".Stacktrace", nil, options, func(ctx context.Context, options []rpc.Option) (struct{}, error) {
+		return struct{}{}, c.client.Stacktrace(ctx, options...)
+	})
+	return err
+}
+
+func (c *adminClient) LoadVMs(ctx context.Context, options ...rpc.Option) (map[ids.ID][]string, map[ids.ID]string, error) {
+	r, err := call(ctx, c.caller, c.name+".LoadVMs", nil, options, func(ctx context.Context, options []rpc.Option) (results2[map[ids.ID][]string, map[ids.ID]string], error) {
+		r1, r2, err := c.client.LoadVMs(ctx, options...)
+		return results2[map[ids.ID][]string, map[ids.ID]string]{r1, r2}, err
+	})
+	return r.R1, r.R2, err
+}
+
+func (c *adminClient) SetLoggerLevel(ctx context.Context, loggerName string, logLevel string, displayLevel string, options ...rpc.Option) (map[string]admin.LogAndDisplayLevels, error) {
+	return call(ctx, c.caller, c.name+".SetLoggerLevel", []interface{}{loggerName, logLevel, displayLevel}, options, func(ctx context.Context, options []rpc.Option) (map[string]admin.LogAndDisplayLevels, error) {
+		return c.client.SetLoggerLevel(ctx, loggerName, logLevel, displayLevel, options...)
+	})
+}
+
+func (c *adminClient) GetLoggerLevel(ctx context.Context, loggerName string, options ...rpc.Option) (map[string]admin.LogAndDisplayLevels, error) {
+	return call(ctx, c.caller, c.name+".GetLoggerLevel", []interface{}{loggerName}, options, func(ctx context.Context, options []rpc.Option) (map[string]admin.LogAndDisplayLevels, error) {
+		return c.client.GetLoggerLevel(ctx, loggerName, options...)
+	})
+}
+
+func (c *adminClient) GetConfig(ctx context.Context, options ...rpc.Option) (interface{}, error) {
+	return call(ctx, c.caller, c.name+".GetConfig", nil, options, func(ctx context.Context, options []rpc.Option) (interface{}, error) {
+		return c.client.GetConfig(ctx, options...)
+	})
+}
+
+func (c *adminClient) DBGet(ctx context.Context, key []byte, options ...rpc.Option) ([]byte, error) {
+	return call(ctx, c.caller, c.name+".DBGet", []interface{}{key}, options, func(ctx context.Context, options []rpc.Option) ([]byte, error) {
+		return c.client.DBGet(ctx, key, options...)
+	})
+}
+
+// indexerClient sends the calls of a index API client through a caller
+type indexerClient struct {
+	// identifies the API in the recorded calls
+	name   string
+	client indexer.Client
+	caller *caller
+}
+
+func (c *indexerClient) GetContainerRange(ctx context.Context, startIndex uint64, numToFetch int, options ...rpc.Option) ([]indexer.Container, error) {
+	return call(ctx, c.caller, c.name+".GetContainerRange", []interface{}{startIndex, numToFetch}, options, func(ctx context.Context, options []rpc.Option) ([]indexer.Container, error) {
+		return c.client.GetContainerRange(ctx, startIndex, numToFetch, options...)
+	})
+}
+
+func (c *indexerClient) GetContainerByIndex(ctx context.Context, index uint64, options ...rpc.Option) (indexer.Container, error) {
+	return call(ctx, c.caller, c.name+".GetContainerByIndex", []interface{}{index}, options, func(ctx context.Context, options []rpc.Option) (indexer.Container, error) {
+		return c.client.GetContainerByIndex(ctx, index, options...)
+	})
+}
+
+func (c *indexerClient) GetLastAccepted(ctx context.Context, options ...rpc.Option) (indexer.Container, uint64, error) {
+	r, err := call(ctx, c.caller, c.name+".GetLastAccepted", nil, options, func(ctx context.Context, options []rpc.Option) (results2[indexer.Container, uint64], error) {
+		r1, r2, err := c.client.GetLastAccepted(ctx, options...)
+		return results2[indexer.Container, uint64]{r1, r2}, err
+	})
+	return r.R1, r.R2, err
+}
+
+func (c *indexerClient) GetIndex(ctx context.Context, containerID ids.ID, options ...rpc.Option) (uint64, error) {
+	return call(ctx, c.caller, c.name+".GetIndex", []interface{}{containerID}, options, func(ctx context.Context, options []rpc.Option) (uint64, error) {
+		return c.client.GetIndex(ctx, containerID, options...)
+	})
+}
+
+func (c *indexerClient) IsAccepted(ctx context.Context, containerID ids.ID, options ...rpc.Option) (bool, error) {
+	return call(ctx, c.caller, c.name+".IsAccepted", []interface{}{containerID}, options, func(ctx context.Context, options []rpc.Option) (bool, error) {
+		return c.client.IsAccepted(ctx, containerID, options...)
+	})
+}
+
+func (c *indexerClient) GetContainerByID(ctx context.Context, containerID ids.ID, options ...rpc.Option) (indexer.Container, uint64, error) {
+	r, err := call(ctx, c.caller, c.name+".GetContainerByID", []interface{}{containerID}, options, func(ctx context.Context, options []rpc.Option) (results2[indexer.Container, uint64], error) {
+		r1, r2, err := c.client.GetContainerByID(ctx, containerID, options...)
+		return results2[indexer.Container, uint64]{r1, r2}, err
+	})
+	return r.R1, r.R2, err
+}
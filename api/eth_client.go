@@ -4,13 +4,17 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"net/http"
 	"sync"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/coreth/core/types"
 	"github.com/ava-labs/coreth/ethclient"
 	"github.com/ava-labs/coreth/interfaces"
+	"github.com/ava-labs/coreth/rpc"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"golang.org/x/sync/errgroup"
 )
 
 // Interface compliance
@@ -24,6 +28,7 @@ type EthClient interface {
 	BlockByNumber(context.Context, *big.Int) (*types.Block, error)
 	BlockByHash(context.Context, common.Hash) (*types.Block, error)
 	BlockNumber(context.Context) (uint64, error)
+	ChainID(context.Context) (*big.Int, error)
 	CallContract(context.Context, interfaces.CallMsg, *big.Int) ([]byte, error)
 	NonceAt(context.Context, common.Address, *big.Int) (uint64, error)
 	AssetBalanceAt(context.Context, common.Address, ids.ID, *big.Int) (*big.Int, error)
@@ -37,14 +42,32 @@ type EthClient interface {
 	SuggestGasTipCap(context.Context) (*big.Int, error)
 	FilterLogs(context.Context, interfaces.FilterQuery) ([]types.Log, error)
 	SubscribeFilterLogs(context.Context, interfaces.FilterQuery, chan<- types.Log) (interfaces.Subscription, error)
+	// Sends all the requests in a single JSON-RPC batch. Errors of the
+	// individual requests are set on their BatchElem.
+	BatchCallContext(context.Context, []rpc.BatchElem) error
+	// Returns the balances of the accounts at the given block, or at the
+	// latest one if nil, fetched in batches
+	BalancesAt(context.Context, []common.Address, *big.Int) ([]*big.Int, error)
+	// Returns the receipts of the txs, fetched with up to the given number
+	// of concurrent requests, or 8 if not positive
+	TransactionReceipts(context.Context, []common.Hash, int) ([]*types.Receipt, error)
 }
 
+const (
+	// max requests sent in a JSON-RPC batch, below the node's default limit
+	maxEthBatchSize = 100
+	// concurrent requests made by TransactionReceipts by default
+	defaultEthConcurrency = 8
+)
+
 // ethClient websocket ethclient.Client with mutexed api calls and lazy conn (on first call)
 // All calls are wrapped in a mutex, and try to create a connection if it doesn't exist yet
 type ethClient struct {
 	ipAddr  string
 	chainID string
 	port    uint
+	// sent on the websocket handshake
+	headers http.Header
 	client  ethclient.Client
 	lock    sync.Mutex
 }
@@ -60,25 +83,45 @@ func NewEthClient(ipAddr string, port uint) EthClient {
 // NewEthClientWithChainID creates an EthClient initialized to connect to
 // ipAddr/port and communicate with the given chainID.
 func NewEthClientWithChainID(ipAddr string, port uint, chainID string) EthClient {
+	return newEthClient(ipAddr, port, chainID, nil)
+}
+
+func newEthClient(ipAddr string, port uint, chainID string, headers http.Header) EthClient {
 	return &ethClient{
 		ipAddr:  ipAddr,
 		port:    port,
 		chainID: chainID,
+		headers: headers,
 	}
 }
 
 // connect attempts to connect with websocket ethclient API
 func (c *ethClient) connect() error {
 	if c.client == ethclient.Client(nil) {
-		client, err := ethclient.Dial(fmt.Sprintf("ws://%s:%d/ext/bc/%s/ws", c.ipAddr, c.port, c.chainID))
+		rpcClient, err := rpc.DialOptions(
+			context.Background(),
+			fmt.Sprintf("ws://%s:%d/ext/bc/%s/ws", c.ipAddr, c.port, c.chainID),
+			rpc.WithHeaders(c.headers),
+		)
 		if err != nil {
 			return err
 		}
-		c.client = client
+		c.client = ethclient.NewClient(rpcClient)
 	}
 	return nil
 }
 
+// getClient returns the connected client, for the calls that are made
+// concurrently instead of being serialized
+func (c *ethClient) getClient() (ethclient.Client, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c.client, nil
+}
+
 // Close closes opened connection (if any)
 func (c *ethClient) Close() {
 	if c.client == ethclient.Client(nil) {
@@ -143,6 +186,15 @@ func (c *ethClient) BlockNumber(ctx context.Context) (uint64, error) {
 	return c.client.BlockNumber(ctx)
 }
 
+func (c *ethClient) ChainID(ctx context.Context) (*big.Int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c.client.ChainID(ctx)
+}
+
 func (c *ethClient) CallContract(ctx context.Context, msg interfaces.CallMsg, blockNumber *big.Int) ([]byte, error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -259,3 +311,65 @@ func (c *ethClient) SubscribeFilterLogs(ctx context.Context, query interfaces.Fi
 	}
 	return c.client.SubscribeFilterLogs(ctx, query, ch)
 }
+
+func (c *ethClient) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	client, err := c.getClient()
+	if err != nil {
+		return err
+	}
+	return client.Client().BatchCallContext(ctx, b)
+}
+
+func (c *ethClient) BalancesAt(ctx context.Context, accounts []common.Address, blockNumber *big.Int) ([]*big.Int, error) {
+	results := make([]hexutil.Big, len(accounts))
+	batch := make([]rpc.BatchElem, len(accounts))
+	for i, account := range accounts {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getBalance",
+			Args:   []interface{}{account, ethclient.ToBlockNumArg(blockNumber)},
+			Result: &results[i],
+		}
+	}
+	for start := 0; start < len(batch); start += maxEthBatchSize {
+		end := min(start+maxEthBatchSize, len(batch))
+		if err := c.BatchCallContext(ctx, batch[start:end]); err != nil {
+			return nil, err
+		}
+	}
+	balances := make([]*big.Int, len(accounts))
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("couldn't get balance of %s: %w", accounts[i], elem.Error)
+		}
+		balances[i] = results[i].ToInt()
+	}
+	return balances, nil
+}
+
+func (c *ethClient) TransactionReceipts(ctx context.Context, txHashes []common.Hash, concurrency int) ([]*types.Receipt, error) {
+	client, err := c.getClient()
+	if err != nil {
+		return nil, err
+	}
+	if concurrency <= 0 {
+		concurrency = defaultEthConcurrency
+	}
+	receipts := make([]*types.Receipt, len(txHashes))
+	errGr, ctx := errgroup.WithContext(ctx)
+	errGr.SetLimit(concurrency)
+	for i, txHash := range txHashes {
+		i, txHash := i, txHash
+		errGr.Go(func() error {
+			receipt, err := client.TransactionReceipt(ctx, txHash)
+			if err != nil {
+				return fmt.Errorf("couldn't get receipt of tx %s: %w", txHash, err)
+			}
+			receipts[i] = receipt
+			return nil
+		})
+	}
+	if err := errGr.Wait(); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+var errUnknownTx = errors.New("unknown tx")
+
+// testEthService serves the eth namespace methods used by the batch helpers
+type testEthService struct {
+	// receipt requests being served
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+// Balance of each account is its last byte
+func (*testEthService) GetBalance(account common.Address, _ string) *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(int64(account[common.AddressLength-1])))
+}
+
+func (s *testEthService) GetTransactionReceipt(txHash common.Hash) (*types.Receipt, error) {
+	inFlight := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+	for {
+		maxInFlight := s.maxInFlight.Load()
+		if inFlight <= maxInFlight || s.maxInFlight.CompareAndSwap(maxInFlight, inFlight) {
+			break
+		}
+	}
+	if txHash == (common.Hash{}) {
+		return nil, errUnknownTx
+	}
+	return &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      txHash,
+		Logs:        []*types.Log{},
+		BlockNumber: big.NewInt(1),
+	}, nil
+}
+
+func newTestEthClient(t *testing.T, service *testEthService) EthClient {
+	require := require.New(t)
+	server := rpc.NewServer(0)
+	server.SetBatchLimits(maxEthBatchSize, 0)
+	require.NoError(server.RegisterName("eth", service))
+	httpServer := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	t.Cleanup(httpServer.Close)
+	host, portStr, err := net.SplitHostPort(httpServer.Listener.Addr().String())
+	require.NoError(err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(err)
+	client := NewEthClient(host, uint(port))
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestEthClientBalancesAt(t *testing.T) {
+	require := require.New(t)
+	client := newTestEthClient(t, &testEthService{})
+
+	// more than a batch
+	accounts := make([]common.Address, 2*maxEthBatchSize+1)
+	for i := range accounts {
+		accounts[i][common.AddressLength-1] = byte(i)
+	}
+	balances, err := client.BalancesAt(context.Background(), accounts, nil)
+	require.NoError(err)
+	require.Len(balances, len(accounts))
+	for i, balance := range balances {
+		require.Equal(int64(byte(i)), balance.Int64())
+	}
+
+	balances, err = client.BalancesAt(context.Background(), nil, nil)
+	require.NoError(err)
+	require.Empty(balances)
+}
+
+func TestEthClientTransactionReceipts(t *testing.T) {
+	require := require.New(t)
+	service := &testEthService{}
+	client := newTestEthClient(t, service)
+
+	txHashes := make([]common.Hash, 10)
+	for i := range txHashes {
+		txHashes[i][0] = byte(i + 1)
+	}
+	receipts, err := client.TransactionReceipts(context.Background(), txHashes, 3)
+	require.NoError(err)
+	require.Len(receipts, len(txHashes))
+	for i, receipt := range receipts {
+		require.Equal(txHashes[i], receipt.TxHash)
+	}
+	require.LessOrEqual(service.maxInFlight.Load(), int32(3))
+
+	_, err = client.TransactionReceipts(context.Background(), []common.Hash{txHashes[0], {}}, 0)
+	require.ErrorContains(err, errUnknownTx.Error())
+}
+
+func TestEthClientBatchCallContext(t *testing.T) {
+	require := require.New(t)
+	client := newTestEthClient(t, &testEthService{})
+
+	var balance hexutil.Big
+	batch := []rpc.BatchElem{
+		{Method: "eth_getBalance", Args: []interface{}{common.Address{19: 7}, "latest"}, Result: &balance},
+		{Method: "eth_unknown", Result: new(string)},
+	}
+	require.NoError(client.BatchCallContext(context.Background(), batch))
+	require.NoError(batch[0].Error)
+	require.Equal(int64(7), balance.ToInt().Int64())
+	require.Error(batch[1].Error)
+}
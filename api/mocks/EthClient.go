@@ -14,6 +14,8 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	rpc "github.com/ava-labs/coreth/rpc"
+
 	types "github.com/ava-labs/coreth/core/types"
 )
 
@@ -150,6 +152,46 @@ func (_m *EthClient) BalanceAt(_a0 context.Context, _a1 common.Address, _a2 *big
 	return r0, r1
 }
 
+// BalancesAt provides a mock function with given fields: _a0, _a1, _a2
+func (_m *EthClient) BalancesAt(_a0 context.Context, _a1 []common.Address, _a2 *big.Int) ([]*big.Int, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []*big.Int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []common.Address, *big.Int) ([]*big.Int, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []common.Address, *big.Int) []*big.Int); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*big.Int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []common.Address, *big.Int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BatchCallContext provides a mock function with given fields: _a0, _a1
+func (_m *EthClient) BatchCallContext(_a0 context.Context, _a1 []rpc.BatchElem) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []rpc.BatchElem) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // BlockByHash provides a mock function with given fields: _a0, _a1
 func (_m *EthClient) BlockByHash(_a0 context.Context, _a1 common.Hash) (*types.Block, error) {
 	ret := _m.Called(_a0, _a1)
@@ -252,6 +294,32 @@ func (_m *EthClient) CallContract(_a0 context.Context, _a1 interfaces.CallMsg, _
 	return r0, r1
 }
 
+// ChainID provides a mock function with given fields: _a0
+func (_m *EthClient) ChainID(_a0 context.Context) (*big.Int, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *big.Int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*big.Int, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *big.Int); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*big.Int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Close provides a mock function with given fields:
 func (_m *EthClient) Close() {
 	_m.Called()
@@ -501,6 +569,32 @@ func (_m *EthClient) TransactionReceipt(_a0 context.Context, _a1 common.Hash) (*
 	return r0, r1
 }
 
+// TransactionReceipts provides a mock function with given fields: _a0, _a1, _a2
+func (_m *EthClient) TransactionReceipts(_a0 context.Context, _a1 []common.Hash, _a2 int) ([]*types.Receipt, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []*types.Receipt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []common.Hash, int) ([]*types.Receipt, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []common.Hash, int) []*types.Receipt); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Receipt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []common.Hash, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewEthClient interface {
 	mock.TestingT
 	Cleanup(func())
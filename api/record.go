@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+var ErrNoRecordedResponse = errors.New("no recorded response")
+
+// Interaction is an API call of a client to a node, and its results
+type Interaction struct {
+	// host:port of the node
+	Host string `json:"host"`
+	// API and method called, e.g. "HealthAPI.Health"
+	Method string `json:"method"`
+	// JSON encoded arguments of the call, but the context and the options
+	Request string `json:"request"`
+	// JSON encoded results of the call, but the error
+	Response string `json:"response"`
+	// Message of the error returned by the call, if any
+	Error string `json:"error,omitempty"`
+}
+
+// Returns the key of the call of [i], that is replayed with its results
+func (i Interaction) key() string {
+	return i.Host + " " + i.Method + " " + i.Request
+}
+
+// Recorder records the calls of the API clients it creates, and their
+// results, so that they can be replayed by a Replayer. The calls of the
+// C-Chain eth client, a websocket, aren't recorded.
+type Recorder struct {
+	path string
+	lock sync.Mutex
+	// in the order they were made
+	interactions []Interaction
+	// first call that couldn't be recorded, returned by Save
+	err error
+}
+
+// NewRecorder returns a Recorder that writes the interactions to
+// [path] on Save
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path}
+}
+
+// NewAPIClient is a NewAPIClientF returning clients whose calls are recorded
+func (r *Recorder) NewAPIClient(ipAddr string, port uint16) Client {
+	return newAPIClient(ipAddr, port, ClientOptions{}, r, nil)
+}
+
+// Records the call of [method] on [host] with [args], that returned
+// [result] and [callErr]
+func (r *Recorder) record(host string, method string, args []interface{}, result interface{}, callErr error) {
+	interaction, err := newInteraction(host, method, args)
+	if err == nil {
+		var response []byte
+		response, err = json.Marshal(result)
+		interaction.Response = string(response)
+	}
+	if callErr != nil {
+		interaction.Error = callErr.Error()
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err != nil {
+		if r.err == nil {
+			r.err = fmt.Errorf("couldn't record %s call to %s: %w", method, host, err)
+		}
+		return
+	}
+	r.interactions = append(r.interactions, interaction)
+}
+
+// Interactions returns the interactions recorded so far
+func (r *Recorder) Interactions() []Interaction {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]Interaction{}, r.interactions...)
+}
+
+// Save writes the interactions recorded so far to the recorder path.
+// Fails if a call couldn't be recorded, e.g. because its arguments
+// or results can't be encoded to JSON.
+func (r *Recorder) Save() error {
+	r.lock.Lock()
+	err := r.err
+	r.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	interactionsJSON, err := json.MarshalIndent(r.Interactions(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, interactionsJSON, 0o644)
+}
+
+// Replayer serves the results recorded by a Recorder to the calls of the
+// API clients it creates, without sending them to the nodes. The results
+// of the same call are served in the order they were recorded, the last
+// one being served again once all were. Calls that weren't recorded get
+// ErrNoRecordedResponse. The results are decoded from their JSON encoding,
+// and the errors only keep their messages. As the nodes are matched by
+// address, they must have the same ports as when recorded.
+type Replayer struct {
+	lock sync.Mutex
+	// call key --> results not served yet
+	interactions map[string][]Interaction
+}
+
+// NewReplayer returns a Replayer of the interactions written by
+// Recorder.Save to [path]
+func NewReplayer(path string) (*Replayer, error) {
+	interactionsJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(interactionsJSON, &interactions); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal recorded interactions: %w", err)
+	}
+	return NewReplayerFromInteractions(interactions), nil
+}
+
+// NewReplayerFromInteractions returns a Replayer of [interactions],
+// e.g. as returned by Recorder.Interactions
+func NewReplayerFromInteractions(interactions []Interaction) *Replayer {
+	r := &Replayer{
+		interactions: map[string][]Interaction{},
+	}
+	for _, interaction := range interactions {
+		key := interaction.key()
+		r.interactions[key] = append(r.interactions[key], interaction)
+	}
+	return r
+}
+
+// NewAPIClient is a NewAPIClientF returning clients whose calls are replayed
+func (r *Replayer) NewAPIClient(ipAddr string, port uint16) Client {
+	return newAPIClient(ipAddr, port, ClientOptions{}, nil, r)
+}
+
+// Decodes into [result] the results recorded for the call of [method]
+// on [host] with [args], and returns the error recorded for it
+func (r *Replayer) replay(host string, method string, args []interface{}, result interface{}) error {
+	interaction, err := newInteraction(host, method, args)
+	if err != nil {
+		return err
+	}
+	key := interaction.key()
+
+	r.lock.Lock()
+	interactions := r.interactions[key]
+	if len(interactions) > 1 {
+		r.interactions[key] = interactions[1:]
+	}
+	r.lock.Unlock()
+
+	if len(interactions) == 0 {
+		return fmt.Errorf("%w for %s call to %s", ErrNoRecordedResponse, method, host)
+	}
+	interaction = interactions[0]
+	if err := json.Unmarshal([]byte(interaction.Response), result); err != nil {
+		return fmt.Errorf("couldn't unmarshal recorded %s results: %w", method, err)
+	}
+	if interaction.Error != "" {
+		return errors.New(interaction.Error)
+	}
+	return nil
+}
+
+// Returns the interaction of the call of [method] on [host] with [args],
+// without its results
+func newInteraction(host string, method string, args []interface{}) (Interaction, error) {
+	if args == nil {
+		args = []interface{}{}
+	}
+	request, err := json.Marshal(args)
+	if err != nil {
+		return Interaction{}, err
+	}
+	return Interaction{
+		Host:    host,
+		Method:  method,
+		Request: string(request),
+	}, nil
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReplay(t *testing.T) {
+	require := require.New(t)
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"healthy":` + strconv.FormatBool(healthy) + `}}`))
+		healthy = !healthy
+	}))
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(err)
+
+	path := filepath.Join(t.TempDir(), "interactions.json")
+	recorder := NewRecorder(path)
+	client := recorder.NewAPIClient(host, uint16(port))
+	for _, expected := range []bool{true, false} {
+		reply, err := client.HealthAPI().Health(context.Background(), nil)
+		require.NoError(err)
+		require.Equal(expected, reply.Healthy)
+	}
+	require.Len(recorder.Interactions(), 2)
+	require.Equal("HealthAPI.Health", recorder.Interactions()[0].Method)
+	require.NoError(recorder.Save())
+	server.Close()
+
+	// replayed without the node
+	replayer, err := NewReplayer(path)
+	require.NoError(err)
+	client = replayer.NewAPIClient(host, uint16(port))
+	for _, expected := range []bool{true, false, false} {
+		reply, err := client.HealthAPI().Health(context.Background(), nil)
+		require.NoError(err)
+		require.Equal(expected, reply.Healthy)
+	}
+	_, err = client.InfoAPI().GetNodeVersion(context.Background())
+	require.ErrorIs(err, ErrNoRecordedResponse)
+
+	// only the calls of the clients created by the replayer are replayed
+	client = NewAPIClient(host, uint16(port))
+	_, err = client.HealthAPI().Health(context.Background(), nil)
+	require.Error(err)
+	require.NotErrorIs(err, ErrNoRecordedResponse)
+	client = replayer.NewAPIClient(host, uint16(port))
+	reply, err := client.HealthAPI().Health(context.Background(), nil)
+	require.NoError(err)
+	require.False(reply.Healthy)
+	// the arguments identify the calls
+	_, err = client.HealthAPI().Health(context.Background(), []string{"tag"})
+	require.ErrorIs(err, ErrNoRecordedResponse)
+}
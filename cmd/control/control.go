@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/client"
+	"github.com/ava-labs/avalanche-network-runner/cmd/exitcode"
 	"github.com/ava-labs/avalanche-network-runner/rpcpb"
 	"github.com/ava-labs/avalanche-network-runner/utils"
 	"github.com/ava-labs/avalanche-network-runner/utils/constants"
@@ -135,7 +136,7 @@ func setLogs() error {
 	}
 	lvl, err := logging.ToLevel(logLevel)
 	if err != nil {
-		return err
+		return exitcode.Wrap(exitcode.Config, err)
 	}
 	logFactory := logging.NewFactory(logging.Config{
 		RotatingWriterConfig: logging.RotatingWriterConfig{
@@ -265,7 +266,7 @@ func newStartCommand() *cobra.Command {
 		&reassignPortsIfUsed,
 		"reassign-ports-if-used",
 		false,
-		"true to reassign default/given ports if already taken",
+		"true to reassign default/given ports if already taken (shifted by 1000 if free, random otherwise)",
 	)
 	cmd.PersistentFlags().BoolVar(
 		&dynamicPorts,
@@ -408,11 +409,11 @@ func startFunc(*cobra.Command, []string) error {
 	}
 
 	if err := setWalletPrivateKeyOptions(&opts); err != nil {
-		return err
+		return exitcode.Wrap(exitcode.Config, err)
 	}
 
 	if err := setNetworkOptions(&opts); err != nil {
-		return err
+		return exitcode.Wrap(exitcode.Config, err)
 	}
 
 	if globalNodeConfig != "" {
@@ -428,7 +429,7 @@ func startFunc(*cobra.Command, []string) error {
 	if customNodeConfigs != "" {
 		nodeConfigs := make(map[string]string)
 		if err := json.Unmarshal([]byte(customNodeConfigs), &nodeConfigs); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithCustomNodeConfigs(nodeConfigs))
 	}
@@ -436,7 +437,7 @@ func startFunc(*cobra.Command, []string) error {
 	if blockchainSpecsStr != "" {
 		blockchainSpecs := []*rpcpb.BlockchainSpec{}
 		if err := json.Unmarshal([]byte(blockchainSpecsStr), &blockchainSpecs); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithBlockchainSpecs(blockchainSpecs))
 	}
@@ -444,21 +445,21 @@ func startFunc(*cobra.Command, []string) error {
 	if chainConfigs != "" {
 		chainConfigsMap := make(map[string]string)
 		if err := json.Unmarshal([]byte(chainConfigs), &chainConfigsMap); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithChainConfigs(chainConfigsMap))
 	}
 	if upgradeConfigs != "" {
 		upgradeConfigsMap := make(map[string]string)
 		if err := json.Unmarshal([]byte(upgradeConfigs), &upgradeConfigsMap); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithUpgradeConfigs(upgradeConfigsMap))
 	}
 	if subnetConfigs != "" {
 		subnetConfigsMap := make(map[string]string)
 		if err := json.Unmarshal([]byte(subnetConfigs), &subnetConfigsMap); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithSubnetConfigs(subnetConfigsMap))
 	}
@@ -499,7 +500,7 @@ func createBlockchainsFunc(_ *cobra.Command, args []string) error {
 
 	blockchainSpecs := []*rpcpb.BlockchainSpec{}
 	if err := json.Unmarshal([]byte(blockchainSpecsStr), &blockchainSpecs); err != nil {
-		return err
+		return exitcode.Wrap(exitcode.Config, err)
 	}
 
 	ctx := getAsyncContext()
@@ -537,7 +538,7 @@ func createSubnetsFunc(_ *cobra.Command, args []string) error {
 
 	subnetSpecs := []*rpcpb.SubnetSpec{}
 	if err := json.Unmarshal([]byte(subnetSpecsStr), &subnetSpecs); err != nil {
-		return err
+		return exitcode.Wrap(exitcode.Config, err)
 	}
 
 	ctx := getAsyncContext()
@@ -615,7 +616,7 @@ func transformElasticSubnetsFunc(_ *cobra.Command, args []string) error {
 
 	elasticSubnetSpecs := []*rpcpb.ElasticSubnetSpec{}
 	if err := json.Unmarshal([]byte(elasticSubnetSpecsStr), &elasticSubnetSpecs); err != nil {
-		return err
+		return exitcode.Wrap(exitcode.Config, err)
 	}
 
 	ctx := getAsyncContext()
@@ -643,7 +644,7 @@ func addPermissionlessDelegatorFunc(_ *cobra.Command, args []string) error {
 
 	delegatorSpecs := []*rpcpb.PermissionlessStakerSpec{}
 	if err := json.Unmarshal([]byte(delegatorSpecsStr), &delegatorSpecs); err != nil {
-		return err
+		return exitcode.Wrap(exitcode.Config, err)
 	}
 
 	ctx := getAsyncContext()
@@ -671,7 +672,7 @@ func addPermissionlessValidatorFunc(_ *cobra.Command, args []string) error {
 
 	validatorSpecs := []*rpcpb.PermissionlessStakerSpec{}
 	if err := json.Unmarshal([]byte(validatorSpecsStr), &validatorSpecs); err != nil {
-		return err
+		return exitcode.Wrap(exitcode.Config, err)
 	}
 
 	ctx := getAsyncContext()
@@ -699,7 +700,7 @@ func addSubnetValidatorsFunc(_ *cobra.Command, args []string) error {
 
 	validatorSpecs := []*rpcpb.SubnetValidatorsSpec{}
 	if err := json.Unmarshal([]byte(validatorSpecsStr), &validatorSpecs); err != nil {
-		return err
+		return exitcode.Wrap(exitcode.Config, err)
 	}
 
 	ctx := getAsyncContext()
@@ -727,7 +728,7 @@ func removeSubnetValidatorFunc(_ *cobra.Command, args []string) error {
 
 	validatorSpecs := []*rpcpb.RemoveSubnetValidatorSpec{}
 	if err := json.Unmarshal([]byte(validatorSpecsStr), &validatorSpecs); err != nil {
-		return err
+		return exitcode.Wrap(exitcode.Config, err)
 	}
 
 	ctx := getAsyncContext()
@@ -1071,21 +1072,21 @@ func addNodeFunc(_ *cobra.Command, args []string) error {
 	if chainConfigs != "" {
 		chainConfigsMap := make(map[string]string)
 		if err := json.Unmarshal([]byte(chainConfigs), &chainConfigsMap); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithChainConfigs(chainConfigsMap))
 	}
 	if upgradeConfigs != "" {
 		upgradeConfigsMap := make(map[string]string)
 		if err := json.Unmarshal([]byte(upgradeConfigs), &upgradeConfigsMap); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithUpgradeConfigs(upgradeConfigsMap))
 	}
 	if subnetConfigs != "" {
 		subnetConfigsMap := make(map[string]string)
 		if err := json.Unmarshal([]byte(subnetConfigs), &subnetConfigsMap); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithSubnetConfigs(subnetConfigsMap))
 	}
@@ -1170,21 +1171,21 @@ func restartNodeFunc(_ *cobra.Command, args []string) error {
 	if chainConfigs != "" {
 		chainConfigsMap := make(map[string]string)
 		if err := json.Unmarshal([]byte(chainConfigs), &chainConfigsMap); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithChainConfigs(chainConfigsMap))
 	}
 	if upgradeConfigs != "" {
 		upgradeConfigsMap := make(map[string]string)
 		if err := json.Unmarshal([]byte(upgradeConfigs), &upgradeConfigsMap); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithUpgradeConfigs(upgradeConfigsMap))
 	}
 	if subnetConfigs != "" {
 		subnetConfigsMap := make(map[string]string)
 		if err := json.Unmarshal([]byte(subnetConfigs), &subnetConfigsMap); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithSubnetConfigs(subnetConfigsMap))
 	}
@@ -1418,7 +1419,7 @@ func newLoadSnapshotCommand() *cobra.Command {
 		&reassignPortsIfUsed,
 		"reassign-ports-if-used",
 		false,
-		"true to reassign snapshot ports if already taken",
+		"true to reassign snapshot ports if already taken (shifted by 1000 if free, random otherwise)",
 	)
 	cmd.PersistentFlags().BoolVar(
 		&inPlace,
@@ -1456,27 +1457,27 @@ func loadSnapshotFunc(_ *cobra.Command, args []string) error {
 	}
 
 	if err := setWalletPrivateKeyOptions(&opts); err != nil {
-		return err
+		return exitcode.Wrap(exitcode.Config, err)
 	}
 
 	if chainConfigs != "" {
 		chainConfigsMap := make(map[string]string)
 		if err := json.Unmarshal([]byte(chainConfigs), &chainConfigsMap); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithChainConfigs(chainConfigsMap))
 	}
 	if upgradeConfigs != "" {
 		upgradeConfigsMap := make(map[string]string)
 		if err := json.Unmarshal([]byte(upgradeConfigs), &upgradeConfigsMap); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithUpgradeConfigs(upgradeConfigsMap))
 	}
 	if subnetConfigs != "" {
 		subnetConfigsMap := make(map[string]string)
 		if err := json.Unmarshal([]byte(subnetConfigs), &subnetConfigsMap); err != nil {
-			return err
+			return exitcode.Wrap(exitcode.Config, err)
 		}
 		opts = append(opts, client.WithSubnetConfigs(subnetConfigsMap))
 	}
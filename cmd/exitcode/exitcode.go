@@ -0,0 +1,136 @@
+// Copyright (C) 2019-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package exitcode defines the exit codes of the avalanche-network-runner
+// commands, one per kind of failure, so that CI pipelines can branch on why
+// a command failed without parsing its output.
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// The command succeeded
+	OK = 0
+	// The command failed for a reason not given by the other codes,
+	// e.g. a bug or a server that can't be reached
+	Internal = 1
+	// The flags, arguments or configs given to the command are invalid
+	Config = 2
+	// A node, or the server, failed to start
+	Startup = 3
+	// The network, or a node, didn't become healthy in time, e.g. before
+	// the request timeout
+	HealthTimeout = 4
+)
+
+// The errors returned by the server keep only their messages, so the
+// errors are told apart by them. Checked in order: a startup timeout is a
+// startup failure, not a health timeout.
+var classes = []struct {
+	code     int
+	codes    []codes.Code
+	errs     []error
+	messages []string
+}{
+	{
+		code:  Config,
+		codes: []codes.Code{codes.InvalidArgument},
+		errs: []error{
+			server.ErrInvalidVMName,
+			server.ErrInvalidPort,
+			server.ErrNotEnoughNodesForStart,
+			server.ErrNoBlockchainSpec,
+			server.ErrNoSubnetID,
+			server.ErrNoElasticSubnetSpec,
+			server.ErrNoValidatorSpec,
+			network.ErrNoBeacons,
+			network.ErrStartDelay,
+		},
+		messages: []string{"unknown command", "config failed validation", "failed to validate JSON"},
+	},
+	{
+		code: Startup,
+		errs: []error{network.ErrStartupTimeout, network.ErrNodesExited},
+		messages: []string{
+			"node stopped unexpectedly",
+			"failed before startup time",
+			"couldn't create new node process",
+		},
+	},
+	{
+		code:  HealthTimeout,
+		codes: []codes.Code{codes.DeadlineExceeded},
+		errs: []error{
+			context.DeadlineExceeded,
+			network.ErrNodeUnhealthy,
+			network.ErrNotEnoughPeers,
+			network.ErrBeaconNotConnected,
+		},
+	},
+}
+
+// Error is a command error with the code the command exits with
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns [err] with the exit code [code], or nil if [err] is nil
+func Wrap(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Of returns the code a command failing with [err] exits with: the one
+// given by Wrap, or else the one of the kind of failure [err] tells, or
+// Internal if unknown. OK if [err] is nil.
+func Of(err error) int {
+	if err == nil {
+		return OK
+	}
+	var exitErr *Error
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	grpcStatus, isGRPC := status.FromError(err)
+	msg := err.Error()
+	for _, class := range classes {
+		if isGRPC {
+			for _, code := range class.codes {
+				if grpcStatus.Code() == code {
+					return class.code
+				}
+			}
+		}
+		for _, classErr := range class.errs {
+			if errors.Is(err, classErr) || strings.Contains(msg, classErr.Error()) {
+				return class.code
+			}
+		}
+		for _, classMsg := range class.messages {
+			if strings.Contains(msg, classMsg) {
+				return class.code
+			}
+		}
+	}
+	return Internal
+}
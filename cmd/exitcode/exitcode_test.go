@@ -0,0 +1,42 @@
+// Copyright (C) 2019-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/server"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "nil", err: nil, code: OK},
+		{name: "wrapped", err: fmt.Errorf("start: %w", Wrap(Startup, errors.New("port in use"))), code: Startup},
+		{name: "server config error", err: status.Error(codes.Unknown, server.ErrNotEnoughNodesForStart.Error()), code: Config},
+		{name: "invalid argument", err: status.Error(codes.InvalidArgument, "bad request"), code: Config},
+		{name: "startup timeout", err: fmt.Errorf("node1: %w", network.ErrStartupTimeout), code: Startup},
+		{name: "server startup failure", err: status.Error(codes.Unknown, "node \"node1\": node stopped unexpectedly, last logs:\n"), code: Startup},
+		{name: "deadline", err: fmt.Errorf("wait: %w", context.DeadlineExceeded), code: HealthTimeout},
+		{name: "grpc deadline", err: status.Error(codes.DeadlineExceeded, "context deadline exceeded"), code: HealthTimeout},
+		{name: "unhealthy", err: status.Error(codes.Unknown, "node \"node1\": "+network.ErrNodeUnhealthy.Error()), code: HealthTimeout},
+		{name: "unknown", err: errors.New("boom"), code: Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.code, Of(tt.err))
+		})
+	}
+	require.NoError(t, Wrap(Config, nil))
+}
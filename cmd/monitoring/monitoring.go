@@ -0,0 +1,132 @@
+// Copyright (C) 2019-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package monitoring
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/client"
+	"github.com/ava-labs/avalanche-network-runner/network/monitoring"
+	"github.com/ava-labs/avalanche-network-runner/utils/constants"
+	"github.com/ava-labs/avalanche-network-runner/ux"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logLevel       string
+	endpoint       string
+	dialTimeout    time.Duration
+	requestTimeout time.Duration
+	dir            string
+	launch         bool
+)
+
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "monitoring [options]",
+		Short: "Prometheus and Grafana monitoring of the network nodes.",
+	}
+
+	cmd.PersistentFlags().StringVar(&logLevel, "log-level", logging.Info.String(), "log level")
+	cmd.PersistentFlags().StringVar(&dir, "dir", filepath.Join(os.TempDir(), constants.RootDirPrefix, "monitoring"), "directory of the monitoring stack")
+	cmd.PersistentFlags().DurationVar(&requestTimeout, "request-timeout", 3*time.Minute, "request timeout")
+
+	cmd.AddCommand(
+		newConfigCommand(),
+		newDownCommand(),
+	)
+
+	return cmd
+}
+
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config [options]",
+		Short: "Writes the Prometheus config scraping the nodes of the server network, and the Grafana dashboards.",
+		RunE:  configFunc,
+		Args:  cobra.ExactArgs(0),
+	}
+	cmd.PersistentFlags().StringVar(&endpoint, "endpoint", "localhost:8080", "server endpoint")
+	cmd.PersistentFlags().DurationVar(&dialTimeout, "dial-timeout", 10*time.Second, "server dial timeout")
+	cmd.PersistentFlags().BoolVar(&launch, "launch", false, "launch Prometheus and Grafana with docker compose")
+	return cmd
+}
+
+func configFunc(*cobra.Command, []string) error {
+	log, err := newLogger()
+	if err != nil {
+		return err
+	}
+	cli, err := client.New(client.Config{
+		Endpoint:    endpoint,
+		DialTimeout: dialTimeout,
+	}, log)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	uris, err := cli.URIs(ctx)
+	if err != nil {
+		return err
+	}
+	targets := make([]string, len(uris))
+	for i, uri := range uris {
+		targets[i] = strings.TrimPrefix(uri, "http://")
+	}
+	if err := monitoring.WriteStack(dir, monitoring.PrometheusConfig(targets, nil)); err != nil {
+		return err
+	}
+	ux.Print(log, logging.Green.Wrap("monitoring stack written to %s"), dir)
+	if !launch {
+		return nil
+	}
+	if err := monitoring.Up(ctx, dir); err != nil {
+		return err
+	}
+	ux.Print(log, logging.Green.Wrap("prometheus on %s, grafana on %s"), monitoring.PrometheusURL, monitoring.GrafanaURL)
+	return nil
+}
+
+func newDownCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "down [options]",
+		Short: "Stops the Prometheus and Grafana launched by config --launch.",
+		RunE:  downFunc,
+		Args:  cobra.ExactArgs(0),
+	}
+}
+
+func downFunc(*cobra.Command, []string) error {
+	log, err := newLogger()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if err := monitoring.Down(ctx, dir); err != nil {
+		return err
+	}
+	ux.Print(log, logging.Green.Wrap("monitoring stack stopped"))
+	return nil
+}
+
+func newLogger() (logging.Logger, error) {
+	lvl, err := logging.ToLevel(logLevel)
+	if err != nil {
+		return nil, err
+	}
+	logFactory := logging.NewFactory(logging.Config{
+		DisplayLevel: lvl,
+		LogLevel:     logging.Off,
+	})
+	return logFactory.Make(constants.LogNameControl)
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 
 	"github.com/ava-labs/avalanche-network-runner/cmd/control"
+	"github.com/ava-labs/avalanche-network-runner/cmd/exitcode"
+	"github.com/ava-labs/avalanche-network-runner/cmd/monitoring"
 	"github.com/ava-labs/avalanche-network-runner/cmd/ping"
 	"github.com/ava-labs/avalanche-network-runner/cmd/server"
 	"github.com/spf13/cobra"
@@ -31,13 +33,33 @@ func init() {
 		server.NewCommand(),
 		ping.NewCommand(),
 		control.NewCommand(),
+		monitoring.NewCommand(),
 	)
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return exitcode.Wrap(exitcode.Config, err)
+	})
+	wrapArgsErrors(rootCmd)
 }
 
+// wrapArgsErrors makes the args validation errors of [cmd] and its
+// subcommands exit with exitcode.Config
+func wrapArgsErrors(cmd *cobra.Command) {
+	if args := cmd.Args; args != nil {
+		cmd.Args = func(cmd *cobra.Command, argv []string) error {
+			return exitcode.Wrap(exitcode.Config, args(cmd, argv))
+		}
+	}
+	for _, subCmd := range cmd.Commands() {
+		wrapArgsErrors(subCmd)
+	}
+}
+
+// Execute runs the command given by the process args, and exits with the
+// code of the kind of its failure, see exitcode
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "avalanche-network-runner failed %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcode.Of(err))
 	}
-	os.Exit(0)
+	os.Exit(exitcode.OK)
 }
@@ -5,12 +5,15 @@ package server
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
 	"time"
 
+	"github.com/ava-labs/avalanche-network-runner/cmd/exitcode"
+	"github.com/ava-labs/avalanche-network-runner/network"
 	"github.com/ava-labs/avalanche-network-runner/server"
 	"github.com/ava-labs/avalanche-network-runner/utils"
 	"github.com/ava-labs/avalanche-network-runner/utils/constants"
@@ -34,6 +37,11 @@ var (
 	dialTimeout        time.Duration
 	disableNodesOutput bool
 	snapshotsDir       string
+	readyFile          string
+	readyCommand       string
+	hostsFile          string
+	hostsDomain        string
+	labels             map[string]string
 )
 
 func NewCommand() *cobra.Command {
@@ -52,6 +60,11 @@ func NewCommand() *cobra.Command {
 	cmd.PersistentFlags().DurationVar(&dialTimeout, "dial-timeout", 10*time.Second, "server dial timeout")
 	cmd.PersistentFlags().BoolVar(&disableNodesOutput, "disable-nodes-output", false, "true to disable nodes stdout/stderr")
 	cmd.PersistentFlags().StringVar(&snapshotsDir, "snapshots-dir", "", "directory for snapshots")
+	cmd.PersistentFlags().StringVar(&readyFile, "ready-file", "", "file the network endpoints are written to, as JSON, each time the network is healthy")
+	cmd.PersistentFlags().StringVar(&readyCommand, "ready-command", "", "shell command run when a network first becomes healthy, with its endpoints as JSON on stdin")
+	cmd.PersistentFlags().StringVar(&hostsFile, "hosts-file", "", "hosts file (e.g. /etc/hosts) the node hostnames are written to each time the network is healthy, and removed from when it stops")
+	cmd.PersistentFlags().StringVar(&hostsDomain, "hosts-domain", network.DefaultHostsDomain, "domain of the node hostnames written to the hosts file, e.g. node1.<domain>")
+	cmd.PersistentFlags().StringToStringVar(&labels, "labels", nil, "labels of the networks started, e.g. job=ci,branch=main, given to the node processes and added to their metrics on the prometheus config")
 
 	return cmd
 }
@@ -72,7 +85,7 @@ func serverFunc(*cobra.Command, []string) (err error) {
 
 	logLevel, err := logging.ToLevel(logLevel)
 	if err != nil {
-		return err
+		return exitcode.Wrap(exitcode.Config, err)
 	}
 
 	logFactory := logging.NewFactory(logging.Config{
@@ -95,9 +108,17 @@ func serverFunc(*cobra.Command, []string) (err error) {
 		RedirectNodesOutput: !disableNodesOutput,
 		SnapshotsDir:        snapshotsDir,
 		LogLevel:            logLevel,
+		ReadyFile:           readyFile,
+		ReadyCommand:        readyCommand,
+		HostsFile:           hostsFile,
+		HostsDomain:         hostsDomain,
+		Labels:              labels,
 	}, log)
+	if errors.Is(err, server.ErrInvalidPort) {
+		return exitcode.Wrap(exitcode.Config, err)
+	}
 	if err != nil {
-		return err
+		return exitcode.Wrap(exitcode.Startup, err)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -121,6 +142,7 @@ func serverFunc(*cobra.Command, []string) (err error) {
 	case serverClosed := <-errChan:
 		// The server stopped.
 		log.Warn("server closed", zap.Error(serverClosed))
+		return serverClosed
 	}
 	return nil
 }
@@ -5,12 +5,10 @@ import (
 	"fmt"
 	"go/build"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/local"
-	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/utils"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"go.uber.org/zap"
 )
@@ -21,26 +19,6 @@ const (
 
 var goPath = os.ExpandEnv("$GOPATH")
 
-// Blocks until a signal is received on [signalChan], upon which
-// [n.Stop()] is called. If [signalChan] is closed, does nothing.
-// Closes [closedOnShutdownChan] amd [signalChan] when done shutting down network.
-// This function should only be called once.
-func shutdownOnSignal(
-	log logging.Logger,
-	n network.Network,
-	signalChan chan os.Signal,
-	closedOnShutdownChan chan struct{},
-) {
-	sig := <-signalChan
-	log.Info("got OS signal", zap.Stringer("signal", sig))
-	if err := n.Stop(context.Background()); err != nil {
-		log.Info("error stopping network", zap.Error(err))
-	}
-	signal.Reset()
-	close(signalChan)
-	close(closedOnShutdownChan)
-}
-
 // Shows example usage of the Avalanche Network Runner.
 // Creates a local five node Avalanche network
 // and waits for all nodes to become healthy.
@@ -78,14 +56,10 @@ func run(log logging.Logger, binaryPath string) error {
 		}
 	}()
 
-	// When we get a SIGINT or SIGTERM, stop the network and close [closedOnShutdownCh]
-	signalsChan := make(chan os.Signal, 1)
-	signal.Notify(signalsChan, syscall.SIGINT)
-	signal.Notify(signalsChan, syscall.SIGTERM)
-	closedOnShutdownCh := make(chan struct{})
-	go func() {
-		shutdownOnSignal(log, nw, signalsChan, closedOnShutdownCh)
-	}()
+	// When we get a SIGINT or SIGTERM, stop the network
+	signalsCtx, signalsCancel := context.WithCancel(context.Background())
+	defer signalsCancel()
+	stopErrCh := utils.WatchShutdownSignals(signalsCtx, nw)
 
 	// Wait until the nodes in the network are ready
 	ctx, cancel := context.WithTimeout(context.Background(), healthyTimeout)
@@ -97,6 +71,11 @@ func run(log logging.Logger, binaryPath string) error {
 
 	log.Info("All nodes healthy. Network will run until you CTRL + C to exit...")
 	// Wait until done shutting down network after SIGINT/SIGTERM
-	<-closedOnShutdownCh
+	if err := nw.Wait(context.Background()); err != nil {
+		return err
+	}
+	if err := <-stopErrCh; err != nil {
+		log.Info("error stopping network", zap.Error(err))
+	}
 	return nil
 }
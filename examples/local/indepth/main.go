@@ -15,6 +15,7 @@ import (
 	"github.com/ava-labs/avalanchego/staking"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
 )
 
 const (
@@ -101,17 +102,15 @@ func run(log logging.Logger, binaryPath string) error {
 	}
 
 	// Print the node names
-	nodeNames, err := nw.GetNodeNames()
+	nodes, err := nw.GetAllNodes()
 	if err != nil {
 		return err
 	}
+	nodeNames := maps.Keys(nodes)
 	log.Info("current network's nodes", zap.Strings("nodes", nodeNames))
 
 	// Get one node
-	node1, err := nw.GetNode(nodeNames[0])
-	if err != nil {
-		return err
-	}
+	node1 := nodes[nodeNames[0]]
 
 	// Get its node ID through its API and print it
 	node1ID, _, err := node1.GetAPIClient().InfoAPI().GetNodeID(context.Background())
@@ -0,0 +1,36 @@
+package local
+
+import (
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+func init() {
+	if err := network.RegisterBackend(network.DefaultBackend, newBackendFactory(nil)); err != nil {
+		panic(err)
+	}
+}
+
+// NodeProcessCreatorFactory returns the NodeProcessCreator launching
+// the node processes of a network using [log]
+type NodeProcessCreatorFactory func(log logging.Logger) NodeProcessCreator
+
+// RegisterProcessBackend registers a network backend under [name], creating
+// local networks whose node processes are launched by the NodeProcessCreator
+// returned by [factory], e.g. as containers or micro VMs, so that config files
+// can select it with network.Config.Backend. See network.RegisterBackend.
+func RegisterProcessBackend(name string, factory NodeProcessCreatorFactory) error {
+	return network.RegisterBackend(name, newBackendFactory(factory))
+}
+
+// Returns a network.BackendFactory creating local networks, reassigning the
+// ports found in use, whose node processes are launched by the creator
+// returned by [factory], or by the default one if nil
+func newBackendFactory(factory NodeProcessCreatorFactory) network.BackendFactory {
+	return func(log logging.Logger, config network.Config, rootDir string) (network.Network, error) {
+		if factory == nil {
+			return NewNetwork(log, config, rootDir, "", "", true, false, false, "", false)
+		}
+		return newNetworkFromConfig(log, config, factory(log), rootDir, "", "", true, false, false, "", false)
+	}
+}
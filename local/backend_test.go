@@ -0,0 +1,30 @@
+package local
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterProcessBackend(t *testing.T) {
+	require := require.New(t)
+	require.Contains(network.Backends(), network.DefaultBackend)
+	npc := &localTestSuccessfulNodeProcessCreator{}
+	require.NoError(RegisterProcessBackend("test-process", func(logging.Logger) NodeProcessCreator {
+		return npc
+	}))
+	require.Error(RegisterProcessBackend(network.DefaultBackend, nil))
+
+	networkConfig := testNetworkConfig(t)
+	networkConfig.Backend = "test-process"
+	// local.NewNetwork only creates networks of the default backend
+	_, err := NewNetwork(logging.NoLog{}, networkConfig, t.TempDir(), "", t.TempDir(), false, false, false, "", false)
+	require.Error(err)
+	net, err := network.NewBackendNetwork(logging.NoLog{}, networkConfig, t.TempDir())
+	require.NoError(err)
+	require.Same(npc, net.(*localNetwork).nodeProcessCreator)
+	require.NoError(net.Stop(context.Background()))
+}
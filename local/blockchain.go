@@ -11,7 +11,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -83,12 +85,13 @@ type blockchainInfo struct {
 	blockchainID ids.ID
 }
 
-// get node with minimum port number
+// get node with minimum port number, among the ones that sync the whole
+// primary network, see node.Config.SubnetOnly
 func (ln *localNetwork) getNode() node.Node {
 	var node node.Node
 	minAPIPortNumber := uint16(0)
 	for _, n := range ln.nodes {
-		if n.paused {
+		if n.paused || n.config.SubnetOnly {
 			continue
 		}
 		if minAPIPortNumber == 0 || n.GetAPIPort() < minAPIPortNumber {
@@ -125,10 +128,19 @@ func (ln *localNetwork) getClientURI() (string, error) { //nolint
 func (ln *localNetwork) CreateBlockchains(
 	ctx context.Context,
 	chainSpecs []network.BlockchainSpec, // VM name + genesis bytes
-) ([]ids.ID, error) {
+) (_ []network.BlockchainInfo, err error) {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
+	vmNames := make([]string, 0, len(chainSpecs))
+	for _, chainSpec := range chainSpecs {
+		vmNames = append(vmNames, chainSpec.VMName)
+	}
+	record := ln.startOperation("CreateBlockchains", "", map[string]string{"vms": strings.Join(vmNames, ",")})
+	defer ln.finishOperation(record, &err)
 
+	if ln.staticValidators {
+		return nil, network.ErrStaticValidators
+	}
 	chainInfos, err := ln.installCustomChains(ctx, chainSpecs)
 	if err != nil {
 		return nil, err
@@ -142,12 +154,16 @@ func (ln *localNetwork) CreateBlockchains(
 		return nil, err
 	}
 
-	chainIDs := []ids.ID{}
+	createdChains := []network.BlockchainInfo{}
 	for _, chainInfo := range chainInfos {
-		chainIDs = append(chainIDs, chainInfo.blockchainID)
+		createdChains = append(createdChains, network.BlockchainInfo{
+			ID:      chainInfo.blockchainID,
+			VMID:    chainInfo.vmID,
+			Aliases: slices.Clone(ln.blockchainAliases[chainInfo.blockchainID.String()]),
+		})
 	}
 
-	return chainIDs, ln.persistNetwork()
+	return createdChains, ln.persistNetwork()
 }
 
 // if alias is defined in blockchain-specs, registers an alias for the previously created blockchain
@@ -166,14 +182,115 @@ func (ln *localNetwork) registerBlockchainAliases(
 		ln.log.Info("registering blockchain alias",
 			zap.String("alias", blockchainAlias),
 			zap.String("chain-id", blockchainID))
-		if err := ln.setBlockchainAlias(ctx, blockchainID, blockchainAlias); err != nil {
+		if err := ln.aliasBlockchain(ctx, blockchainID, blockchainAlias); err != nil {
 			return err
 		}
-		ln.blockchainAliases[blockchainID] = append(ln.blockchainAliases[blockchainID], blockchainAlias)
 	}
 	return nil
 }
 
+// See network.Network
+func (ln *localNetwork) AliasBlockchain(ctx context.Context, blockchainID ids.ID, blockchainAlias string) (err error) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+	record := ln.startOperation("AliasBlockchain", "", map[string]string{
+		"blockchainID": blockchainID.String(),
+		"alias":        blockchainAlias,
+	})
+	defer ln.finishOperation(record, &err)
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	if blockchainAlias == "" {
+		return errors.New("empty blockchain alias")
+	}
+	if err := ln.aliasBlockchain(ctx, blockchainID.String(), blockchainAlias); err != nil {
+		return err
+	}
+	return ln.persistNetwork()
+}
+
+// See network.Network
+func (ln *localNetwork) GetBlockchainAliases(blockchainID ids.ID) ([]string, error) {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	if ln.stopCalled() {
+		return nil, network.ErrStopped
+	}
+	return slices.Clone(ln.blockchainAliases[blockchainID.String()]), nil
+}
+
+// aliasBlockchain registers [blockchainAlias] on all running nodes, and records
+// it so it is saved on snapshots and registered again when they are loaded.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) aliasBlockchain(ctx context.Context, blockchainID string, blockchainAlias string) error {
+	if slices.Contains(ln.blockchainAliases[blockchainID], blockchainAlias) {
+		return nil
+	}
+	if err := ln.setBlockchainAlias(ctx, blockchainID, blockchainAlias); err != nil {
+		return err
+	}
+	ln.blockchainAliases[blockchainID] = append(ln.blockchainAliases[blockchainID], blockchainAlias)
+	return nil
+}
+
+// See network.Network
+func (ln *localNetwork) AliasVM(ctx context.Context, vmID ids.ID, vmAlias string) (err error) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+	record := ln.startOperation("AliasVM", "", map[string]string{
+		"vmID":  vmID.String(),
+		"alias": vmAlias,
+	})
+	defer ln.finishOperation(record, &err)
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	if vmAlias == "" {
+		return errors.New("empty vm alias")
+	}
+	if slices.Contains(ln.vmAliases[vmID.String()], vmAlias) {
+		return nil
+	}
+	for otherVMID, vmAliases := range ln.vmAliases {
+		if slices.Contains(vmAliases, vmAlias) {
+			return fmt.Errorf("vm alias %q already used for vm %s", vmAlias, otherVMID)
+		}
+	}
+	ln.vmAliases[vmID.String()] = append(ln.vmAliases[vmID.String()], vmAlias)
+	ln.log.Info("registering vm alias, restarting nodes",
+		zap.String("alias", vmAlias),
+		zap.String("vm-id", vmID.String()),
+	)
+	// vm aliases are read by avalanchego on startup
+	nodeNames := []string{}
+	for nodeName, node := range ln.nodes {
+		if !node.paused {
+			nodeNames = append(nodeNames, nodeName)
+		}
+	}
+	for _, nodeName := range nodeNames {
+		if err := ln.restartNode(ctx, nodeName, "", "", "", nil, nil, nil); err != nil {
+			return &network.NodeError{NodeName: nodeName, Op: "restart", Err: err}
+		}
+	}
+	return ln.persistNetwork()
+}
+
+// See network.Network
+func (ln *localNetwork) GetVMAliases(vmID ids.ID) ([]string, error) {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	if ln.stopCalled() {
+		return nil, network.ErrStopped
+	}
+	return slices.Clone(ln.vmAliases[vmID.String()]), nil
+}
+
 func (ln *localNetwork) setBlockchainAlias(ctx context.Context, blockchainID string, blockchainAlias string) error {
 	for nodeName, node := range ln.nodes {
 		if node.paused {
@@ -195,10 +312,15 @@ func (ln *localNetwork) setBlockchainAlias(ctx context.Context, blockchainID str
 func (ln *localNetwork) AddSubnetValidators(
 	ctx context.Context,
 	subnetSpecs []network.SubnetValidatorsSpec,
-) error {
+) (err error) {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
+	record := ln.startOperation("AddSubnetValidators", "", map[string]string{"subnets": strconv.Itoa(len(subnetSpecs))})
+	defer ln.finishOperation(record, &err)
 
+	if ln.staticValidators {
+		return network.ErrStaticValidators
+	}
 	if err := ln.addSubnetValidators(ctx, subnetSpecs); err != nil {
 		return err
 	}
@@ -208,10 +330,15 @@ func (ln *localNetwork) AddSubnetValidators(
 func (ln *localNetwork) RemoveSubnetValidators(
 	ctx context.Context,
 	subnetSpecs []network.SubnetValidatorsSpec,
-) error {
+) (err error) {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
+	record := ln.startOperation("RemoveSubnetValidators", "", map[string]string{"subnets": strconv.Itoa(len(subnetSpecs))})
+	defer ln.finishOperation(record, &err)
 
+	if ln.staticValidators {
+		return network.ErrStaticValidators
+	}
 	if err := ln.removeSubnetValidators(ctx, subnetSpecs); err != nil {
 		return err
 	}
@@ -221,10 +348,15 @@ func (ln *localNetwork) RemoveSubnetValidators(
 func (ln *localNetwork) AddPermissionlessValidators(
 	ctx context.Context,
 	validatorSpec []network.PermissionlessStakerSpec,
-) error {
+) (err error) {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
+	record := ln.startOperation("AddPermissionlessValidators", "", map[string]string{"validators": strconv.Itoa(len(validatorSpec))})
+	defer ln.finishOperation(record, &err)
 
+	if ln.staticValidators {
+		return network.ErrStaticValidators
+	}
 	if err := ln.addPermissionlessValidators(ctx, validatorSpec); err != nil {
 		return err
 	}
@@ -234,10 +366,15 @@ func (ln *localNetwork) AddPermissionlessValidators(
 func (ln *localNetwork) AddPermissionlessDelegators(
 	ctx context.Context,
 	delegatorSpecs []network.PermissionlessStakerSpec,
-) error {
+) (err error) {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
+	record := ln.startOperation("AddPermissionlessDelegators", "", map[string]string{"delegators": strconv.Itoa(len(delegatorSpecs))})
+	defer ln.finishOperation(record, &err)
 
+	if ln.staticValidators {
+		return network.ErrStaticValidators
+	}
 	if err := ln.addPermissionlessDelegators(ctx, delegatorSpecs); err != nil {
 		return err
 	}
@@ -247,10 +384,15 @@ func (ln *localNetwork) AddPermissionlessDelegators(
 func (ln *localNetwork) TransformSubnet(
 	ctx context.Context,
 	elasticSubnetConfig []network.ElasticSubnetSpec,
-) ([]ids.ID, []ids.ID, error) {
+) (_ []ids.ID, _ []ids.ID, err error) {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
+	record := ln.startOperation("TransformSubnet", "", map[string]string{"subnets": strconv.Itoa(len(elasticSubnetConfig))})
+	defer ln.finishOperation(record, &err)
 
+	if ln.staticValidators {
+		return nil, nil, network.ErrStaticValidators
+	}
 	elasticSubnetIDs, assetIDs, err := ln.transformToElasticSubnets(ctx, elasticSubnetConfig)
 	if err != nil {
 		return elasticSubnetIDs, assetIDs, err
@@ -261,10 +403,15 @@ func (ln *localNetwork) TransformSubnet(
 func (ln *localNetwork) CreateSubnets(
 	ctx context.Context,
 	subnetSpecs []network.SubnetSpec,
-) ([]ids.ID, error) {
+) (_ []ids.ID, err error) {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
+	record := ln.startOperation("CreateSubnets", "", map[string]string{"subnets": strconv.Itoa(len(subnetSpecs))})
+	defer ln.finishOperation(record, &err)
 
+	if ln.staticValidators {
+		return nil, network.ErrStaticValidators
+	}
 	subnetIDs, err := ln.installSubnets(ctx, subnetSpecs)
 	if err != nil {
 		return subnetIDs, err
@@ -342,12 +489,14 @@ func (ln *localNetwork) installCustomChains(
 			subnetSpecsMap[*chainSpec.SubnetID] = subnetSpec
 		}
 	}
-	// if no participants are given for a new subnet, assume all nodes should be participants
-	allNodeNames := maps.Keys(ln.nodes)
-	sort.Strings(allNodeNames)
+	// if no participants are given for a new subnet, assume all nodes but observers should be participants
+	validatorNodeNames := ln.validatorNodeNames()
 	for i := range subnetSpecs {
 		if len(subnetSpecs[i].Participants) == 0 {
-			subnetSpecs[i].Participants = allNodeNames
+			subnetSpecs[i].Participants = validatorNodeNames
+		}
+		if err := ln.checkNotObservers("add subnet validator", subnetSpecs[i].Participants...); err != nil {
+			return nil, err
 		}
 	}
 
@@ -503,6 +652,9 @@ func (ln *localNetwork) addSubnetValidators(
 		if len(spec.NodeNames) == 0 {
 			return fmt.Errorf("no validators provided for subnet %s", spec.SubnetID)
 		}
+		if err := ln.checkNotObservers("add subnet validator", spec.NodeNames...); err != nil {
+			return err
+		}
 	}
 
 	// create new nodes
@@ -572,12 +724,14 @@ func (ln *localNetwork) installSubnets(
 		return nil, err
 	}
 
-	// if no participants are given, assume all nodes should be participants
-	allNodeNames := maps.Keys(ln.nodes)
-	sort.Strings(allNodeNames)
+	// if no participants are given, assume all nodes but observers should be participants
+	validatorNodeNames := ln.validatorNodeNames()
 	for i := range subnetSpecs {
 		if len(subnetSpecs[i].Participants) == 0 {
-			subnetSpecs[i].Participants = allNodeNames
+			subnetSpecs[i].Participants = validatorNodeNames
+		}
+		if err := ln.checkNotObservers("add subnet validator", subnetSpecs[i].Participants...); err != nil {
+			return nil, err
 		}
 	}
 
@@ -715,7 +869,7 @@ func (ln *localNetwork) waitForCustomChainsReady(
 					return errAborted
 				case <-ctx.Done():
 					return ctx.Err()
-				case <-time.After(blockchainLogPullFrequency):
+				case <-ln.clock.After(blockchainLogPullFrequency):
 				}
 			}
 		}
@@ -728,8 +882,8 @@ func (ln *localNetwork) waitForCustomChainsReady(
 				continue
 			}
 			for {
-				boostrapped, err := node.client.InfoAPI().IsBootstrapped(ctx, chainInfo.blockchainID.String())
-				if err != nil && !strings.Contains(err.Error(), "there is no chain with alias/ID") {
+				boostrapped, err := isChainBootstrapped(ctx, node.client, chainInfo.blockchainID)
+				if err != nil {
 					return err
 				}
 				if boostrapped {
@@ -745,7 +899,7 @@ func (ln *localNetwork) waitForCustomChainsReady(
 					return errAborted
 				case <-ctx.Done():
 					return ctx.Err()
-				case <-time.After(blockchainBootstrapCheckFrequency):
+				case <-ln.clock.After(blockchainBootstrapCheckFrequency):
 				}
 			}
 		}
@@ -815,6 +969,12 @@ func (ln *localNetwork) restartNodes(
 		}
 
 		for i, subnetID := range subnetIDs {
+			// observers serve the APIs of all the subnets
+			if node.config.Observer {
+				trackSubnetIDsSet.Add(subnetID.String())
+				needsRestart = true
+				continue
+			}
 			for _, participant := range subnetSpecs[i].Participants {
 				if participant == nodeName {
 					trackSubnetIDsSet.Add(subnetID.String())
@@ -850,7 +1010,7 @@ func (ln *localNetwork) restartNodes(
 		}
 
 		if err := ln.restartNode(ctx, nodeName, "", "", "", nil, nil, nil); err != nil {
-			return err
+			return &network.NodeError{NodeName: nodeName, Op: "restart", Err: err}
 		}
 	}
 	if err := ln.healthy(ctx); err != nil {
@@ -876,20 +1036,9 @@ func newWallet(
 	subnetIDs []ids.ID,
 	walletPrivateKey string,
 ) (*wallet, error) {
-	var (
-		err        error
-		privateKey *secp256k1.PrivateKey
-	)
-	if walletPrivateKey == "" {
-		privateKey = genesis.EWOQKey
-	} else {
-		walletPrivateKeyBytes, err := hex.DecodeString(walletPrivateKey)
-		if err != nil {
-			return nil, err
-		}
-		if privateKey, err = secp256k1.ToPrivateKey(walletPrivateKeyBytes); err != nil {
-			return nil, err
-		}
+	privateKey, err := getWalletKey(walletPrivateKey)
+	if err != nil {
+		return nil, err
 	}
 	kc := secp256k1fx.NewKeychain(privateKey)
 	primaryAVAXState, err := primary.FetchState(ctx, uri, kc.Addresses())
@@ -929,12 +1078,24 @@ func newWallet(
 	return &w, nil
 }
 
+// Returns the key of [walletPrivateKey], given as hex, or genesis.EWOQKey if empty
+func getWalletKey(walletPrivateKey string) (*secp256k1.PrivateKey, error) {
+	if walletPrivateKey == "" {
+		return genesis.EWOQKey, nil
+	}
+	walletPrivateKeyBytes, err := hex.DecodeString(walletPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return secp256k1.ToPrivateKey(walletPrivateKeyBytes)
+}
+
 func (w *wallet) reload(uri string) {
 	pClient := platformvm.NewClient(uri)
 	w.pWallet = pwallet.New(p.NewClient(pClient, w.pBackend), w.pBuilder, w.pSigner)
 }
 
-// add all nodes as validators of the primary network, in case they are not
+// add all nodes but observers as validators of the primary network, in case they are not
 // the validation starts as soon as possible and its duration is as long as possible, that is,
 // it is set to max accepted duration by avalanchego
 func (ln *localNetwork) addPrimaryValidators(
@@ -957,7 +1118,7 @@ func (ln *localNetwork) addPrimaryValidators(
 	for nodeName, node := range ln.nodes {
 		nodeID := node.GetNodeID()
 
-		if curValidators.Contains(nodeID) {
+		if node.config.Observer || curValidators.Contains(nodeID) {
 			continue
 		}
 
@@ -1157,6 +1318,9 @@ func (ln *localNetwork) addPermissionlessDelegators(
 	// wallet needs txs for all previously created subnets
 	subnetIDs := make([]ids.ID, len(delegatorSpecs))
 	for i, delegatorSpec := range delegatorSpecs {
+		if err := ln.checkNotObservers("add permissionless delegator", delegatorSpec.NodeName); err != nil {
+			return err
+		}
 		subnetID, err := ids.FromString(delegatorSpec.SubnetID)
 		if err != nil {
 			return err
@@ -1258,6 +1422,9 @@ func (ln *localNetwork) addPermissionlessValidators(
 	platformCli := platformvm.NewClient(clientURI)
 	subnetIDs := make([]ids.ID, len(validatorSpecs))
 	for i, validatorSpec := range validatorSpecs {
+		if err := ln.checkNotObservers("add permissionless validator", validatorSpec.NodeName); err != nil {
+			return err
+		}
 		subnetID, err := ids.FromString(validatorSpec.SubnetID)
 		if err != nil {
 			return err
@@ -1577,7 +1744,7 @@ func (ln *localNetwork) issueSubnetValidatorTxs(
 	return nil
 }
 
-// waits until all nodes start validating the primary network
+// waits until all nodes but observers start validating the primary network
 func (ln *localNetwork) waitPrimaryValidators(
 	ctx context.Context,
 	platformCli platformvm.Client,
@@ -1596,6 +1763,9 @@ func (ln *localNetwork) waitPrimaryValidators(
 			primaryValidators.Add(v.NodeID)
 		}
 		for _, node := range ln.nodes {
+			if node.config.Observer {
+				continue
+			}
 			nodeID := node.GetNodeID()
 			if isValidator := primaryValidators.Contains(nodeID); !isValidator {
 				ready = false
@@ -1609,7 +1779,7 @@ func (ln *localNetwork) waitPrimaryValidators(
 			return errAborted
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(waitForValidatorsPullFrequency):
+		case <-ln.clock.After(waitForValidatorsPullFrequency):
 		}
 	}
 }
@@ -1658,7 +1828,7 @@ func (ln *localNetwork) waitSubnetValidators(
 			return errAborted
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(waitForValidatorsPullFrequency):
+		case <-ln.clock.After(waitForValidatorsPullFrequency):
 		}
 	}
 }
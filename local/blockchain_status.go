@@ -0,0 +1,130 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/avalanche-network-runner/api"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"golang.org/x/sync/errgroup"
+)
+
+// Returned by info.isBootstrapped for the chains the node doesn't know
+const unknownChainErrMsg = "there is no chain with alias/ID"
+
+// Returns true if the node of [client] bootstrapped the chain [chainID].
+// False if the node doesn't know the chain, e.g. because it doesn't track
+// its subnet, or hasn't created it yet.
+func isChainBootstrapped(ctx context.Context, client api.Client, chainID ids.ID) (bool, error) {
+	bootstrapped, err := client.InfoAPI().IsBootstrapped(ctx, chainID.String())
+	if err != nil && strings.Contains(err.Error(), unknownChainErrMsg) {
+		return false, nil
+	}
+	return bootstrapped, err
+}
+
+// Returns the blockchains known by the P-chain of [node]
+func (node *localNode) getBlockchains(ctx context.Context) ([]platformvm.APIBlockchain, error) {
+	if !node.beginCall() {
+		return nil, &network.NodeError{NodeName: node.name, Op: "get blockchains from", Err: errNodeDrained}
+	}
+	defer node.endCall()
+	blockchains, err := node.client.PChainAPI().GetBlockchains(ctx)
+	if err != nil {
+		return nil, &network.NodeError{NodeName: node.name, Op: "get blockchains from", Err: err}
+	}
+	return blockchains, nil
+}
+
+// Returns whether [node] bootstrapped each of the chains [chainIDs], in order
+func (node *localNode) chainsBootstrapped(ctx context.Context, chainIDs []ids.ID) ([]bool, error) {
+	if !node.beginCall() {
+		return nil, &network.NodeError{NodeName: node.name, Op: "get bootstrap status of", Err: errNodeDrained}
+	}
+	defer node.endCall()
+	bootstrapped := make([]bool, len(chainIDs))
+	for i, chainID := range chainIDs {
+		var err error
+		bootstrapped[i], err = isChainBootstrapped(ctx, node.client, chainID)
+		if err != nil {
+			return nil, &network.NodeError{NodeName: node.name, Op: "get bootstrap status of", Err: err}
+		}
+	}
+	return bootstrapped, nil
+}
+
+// See network.Network
+func (ln *localNetwork) Blockchains(ctx context.Context) ([]network.Blockchain, error) {
+	ln.lock.RLock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return nil, network.ErrStopped
+	}
+	nodes := ln.runningNodes()
+	ln.lock.RUnlock()
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].name < nodes[j].name
+	})
+
+	// the P-chain of the first node not being removed gives them
+	var apiBlockchains []platformvm.APIBlockchain
+	found := false
+	for _, node := range nodes {
+		var err error
+		apiBlockchains, err = node.getBlockchains(ctx)
+		if errors.Is(err, errNodeDrained) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		break
+	}
+	if !found {
+		return nil, network.ErrNoRunningNodes
+	}
+	chainIDs := make([]ids.ID, len(apiBlockchains))
+	blockchains := make([]network.Blockchain, len(apiBlockchains))
+	for i, apiBlockchain := range apiBlockchains {
+		chainIDs[i] = apiBlockchain.ID
+		blockchains[i] = network.Blockchain{
+			ID:                apiBlockchain.ID,
+			Name:              apiBlockchain.Name,
+			SubnetID:          apiBlockchain.SubnetID,
+			VMID:              apiBlockchain.VMID,
+			NodesBootstrapped: make(map[string]bool, len(nodes)),
+		}
+	}
+
+	var lock sync.Mutex
+	errGr, ctx := errgroup.WithContext(ctx)
+	for _, node := range nodes {
+		node := node
+		errGr.Go(func() error {
+			bootstrapped, err := node.chainsBootstrapped(ctx, chainIDs)
+			if errors.Is(err, errNodeDrained) {
+				// being removed, as if it was paused
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			for i := range blockchains {
+				blockchains[i].NodesBootstrapped[node.name] = bootstrapped[i]
+			}
+			return nil
+		})
+	}
+	if err := errGr.Wait(); err != nil {
+		return nil, err
+	}
+	return blockchains, nil
+}
@@ -0,0 +1,103 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/api"
+	apimocks "github.com/ava-labs/avalanche-network-runner/api/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/stretchr/testify/require"
+)
+
+type blockchainsPChainClient struct {
+	platformvm.Client
+	blockchains []platformvm.APIBlockchain
+}
+
+func (c blockchainsPChainClient) GetBlockchains(context.Context, ...rpc.Option) ([]platformvm.APIBlockchain, error) {
+	return c.blockchains, nil
+}
+
+// bootstrapInfoClient reports the chains in [bootstrapped] as bootstrapped,
+// and the other ones as unknown
+type bootstrapInfoClient struct {
+	info.Client
+	bootstrapped map[string]bool
+}
+
+func (c bootstrapInfoClient) IsBootstrapped(_ context.Context, chainID string, _ ...rpc.Option) (bool, error) {
+	if !c.bootstrapped[chainID] {
+		return false, errors.New("problem getting chain: " + unknownChainErrMsg + " " + chainID)
+	}
+	return true, nil
+}
+
+func TestBlockchains(t *testing.T) {
+	require := require.New(t)
+	xChain := platformvm.APIBlockchain{ID: ids.GenerateTestID(), Name: "X-Chain", SubnetID: ids.Empty, VMID: ids.GenerateTestID()}
+	subnetChain := platformvm.APIBlockchain{ID: ids.GenerateTestID(), Name: "subnetevm", SubnetID: ids.GenerateTestID(), VMID: ids.GenerateTestID()}
+	newAPIClient := func(bootstrapped ...ids.ID) api.Client {
+		client := newMockAPISuccessful("", 0).(*apimocks.Client)
+		client.On("PChainAPI").Return(blockchainsPChainClient{blockchains: []platformvm.APIBlockchain{xChain, subnetChain}})
+		infoClient := bootstrapInfoClient{bootstrapped: map[string]bool{}}
+		for _, chainID := range bootstrapped {
+			infoClient.bootstrapped[chainID.String()] = true
+		}
+		client.On("InfoAPI").Return(infoClient)
+		return client
+	}
+	net, err := newNetwork(
+		logging.NoLog{},
+		func(string, uint16) api.Client {
+			return newAPIClient(xChain.ID, subnetChain.ID)
+		},
+		&localTestSuccessfulNodeProcessCreator{},
+		t.TempDir(),
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	// doesn't track the subnet
+	net.nodes["node1"].client = newAPIClient(xChain.ID)
+	require.NoError(net.PauseNode(context.Background(), "node2"))
+
+	blockchains, err := net.Blockchains(context.Background())
+	require.NoError(err)
+	require.Equal([]network.Blockchain{
+		{
+			ID:                xChain.ID,
+			Name:              "X-Chain",
+			SubnetID:          ids.Empty,
+			VMID:              xChain.VMID,
+			NodesBootstrapped: map[string]bool{"node0": true, "node1": true},
+		},
+		{
+			ID:                subnetChain.ID,
+			Name:              "subnetevm",
+			SubnetID:          subnetChain.SubnetID,
+			VMID:              subnetChain.VMID,
+			NodesBootstrapped: map[string]bool{"node0": true, "node1": false},
+		},
+	}, blockchains)
+	require.True(blockchains[0].Bootstrapped())
+	require.False(blockchains[1].Bootstrapped())
+
+	require.NoError(net.Stop(context.Background()))
+	_, err = net.Blockchains(context.Background())
+	require.ErrorIs(err, network.ErrStopped)
+}
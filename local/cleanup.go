@@ -0,0 +1,193 @@
+package local
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanche-network-runner/utils/constants"
+	"github.com/shirou/gopsutil/process"
+)
+
+// Name of the file of a root data dir listing the pids of the processes
+// using it, one per line: the runner and, for detached networks, the
+// nodes, so that Clean doesn't remove the runs still in use
+const runPIDsFileName = "runner.pids"
+
+// artifactRun is the root data dir of a network created without one
+type artifactRun struct {
+	path string
+	// last modification of the dir or any of its files
+	modTime time.Time
+	// total size of its files
+	size int64
+	// if true, the run is never removed
+	inUse bool
+}
+
+// defaultArtifactsDir returns the dir where the root data dirs of
+// networks created without one are written
+func defaultArtifactsDir() string {
+	return filepath.Join(os.TempDir(), constants.RootDirPrefix)
+}
+
+// newRootDataDir creates the root data dir of a network created without one,
+// named after [networkName], if given, and the current time, so that the runs
+// can be told apart
+func newRootDataDir(networkName string) (string, error) {
+	dirPrefix := networkRootDirPrefix
+	if networkName != "" {
+		dirPrefix += "_" + networkName
+	}
+	return utils.MkDirWithTimestamp(filepath.Join(defaultArtifactsDir(), dirPrefix))
+}
+
+// Clean removes the runs, i.e. the root data dirs of networks created without
+// one, that [policy] doesn't keep from [artifactsDir], and returns their
+// paths. If [artifactsDir] is empty, the runner's default artifacts dir
+// <temp dir>/network-runner-root-data is used. Other dirs, such as the
+// server and client log dirs, are left as is. The runs still in use, i.e.
+// whose runner or, for detached networks, nodes are still running, are
+// kept, and count first towards the limits of [policy].
+func Clean(artifactsDir string, policy network.CleanupPolicy) ([]string, error) {
+	return cleanRuns(artifactsDir, policy, "")
+}
+
+// See Clean. [ownRootDir] is the root data dir of the caller, always kept.
+func cleanRuns(artifactsDir string, policy network.CleanupPolicy, ownRootDir string) ([]string, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if artifactsDir == "" {
+		artifactsDir = defaultArtifactsDir()
+	}
+	entries, err := os.ReadDir(artifactsDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	runs := []artifactRun{}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), networkRootDirPrefix+"_") {
+			continue
+		}
+		run, err := getArtifactRun(filepath.Join(artifactsDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		run.inUse = (ownRootDir != "" && sameDir(run.path, ownRootDir)) || runInUse(run.path)
+		runs = append(runs, run)
+	}
+	// in use first, then most recent first
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].inUse != runs[j].inUse {
+			return runs[i].inUse
+		}
+		return runs[i].modTime.After(runs[j].modTime)
+	})
+
+	now := time.Now()
+	var keptSize int64
+	kept := 0
+	sizeExceeded := false
+	removed := []string{}
+	for _, run := range runs {
+		if policy.MaxTotalSize != 0 && keptSize+run.size > policy.MaxTotalSize {
+			sizeExceeded = true
+		}
+		keep := run.inUse || (!sizeExceeded &&
+			(policy.KeepLast == 0 || kept < policy.KeepLast) &&
+			(policy.MaxAge == 0 || now.Sub(run.modTime) <= policy.MaxAge))
+		if keep {
+			kept++
+			keptSize += run.size
+			continue
+		}
+		if err := os.RemoveAll(run.path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, run.path)
+	}
+	return removed, nil
+}
+
+// getArtifactRun walks the dir at [path] to get its size and last modification.
+// Files removed meanwhile, e.g. by a network still running, are skipped.
+func getArtifactRun(path string) (artifactRun, error) {
+	run := artifactRun{path: path}
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(run.modTime) {
+			run.modTime = info.ModTime()
+		}
+		if info.Mode().IsRegular() {
+			run.size += info.Size()
+		}
+		return nil
+	})
+	return run, err
+}
+
+// Returns true if [path1] and [path2] are the same dir
+func sameDir(path1 string, path2 string) bool {
+	info1, err := os.Stat(path1)
+	if err != nil {
+		return false
+	}
+	info2, err := os.Stat(path2)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(info1, info2)
+}
+
+// Writes [pids] as the processes using the run at [rootDir]
+func writeRunPIDs(rootDir string, pids []int) error {
+	lines := make([]string, 0, len(pids))
+	for _, pid := range pids {
+		lines = append(lines, strconv.Itoa(pid))
+	}
+	return os.WriteFile(filepath.Join(rootDir, runPIDsFileName), []byte(strings.Join(lines, "\n")+"\n"), 0o644)
+}
+
+// Returns true if a process using the run at [rootDir] is still running
+func runInUse(rootDir string) bool {
+	pidsFile, err := os.ReadFile(filepath.Join(rootDir, runPIDsFileName))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Fields(string(pidsFile)) {
+		pid, err := strconv.Atoi(line)
+		if err != nil {
+			continue
+		}
+		if pid == os.Getpid() {
+			return true
+		}
+		if exists, err := process.PidExists(int32(pid)); err == nil && exists {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package local
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClean(t *testing.T) {
+	now := time.Now()
+	// creates runs, from the most recent to the oldest, each an hour older,
+	// with a file of [size] bytes each
+	newRuns := func(t *testing.T, size int, names ...string) string {
+		require := require.New(t)
+		artifactsDir := t.TempDir()
+		for i, name := range names {
+			dir := filepath.Join(artifactsDir, name)
+			require.NoError(os.MkdirAll(dir, os.ModePerm))
+			file := filepath.Join(dir, "db")
+			require.NoError(os.WriteFile(file, make([]byte, size), 0o600))
+			modTime := now.Add(-time.Duration(i) * time.Hour)
+			require.NoError(os.Chtimes(file, modTime, modTime))
+			require.NoError(os.Chtimes(dir, modTime, modTime))
+		}
+		return artifactsDir
+	}
+
+	tests := map[string]struct {
+		policy          network.CleanupPolicy
+		expectedRemoved []string
+	}{
+		"zero policy": {},
+		"keep last": {
+			policy:          network.CleanupPolicy{KeepLast: 2},
+			expectedRemoved: []string{"network_3", "network_4"},
+		},
+		"max age": {
+			policy:          network.CleanupPolicy{MaxAge: 90 * time.Minute},
+			expectedRemoved: []string{"network_3", "network_4"},
+		},
+		"max total size": {
+			policy:          network.CleanupPolicy{MaxTotalSize: 250},
+			expectedRemoved: []string{"network_3", "network_4"},
+		},
+		"all limits": {
+			policy:          network.CleanupPolicy{KeepLast: 3, MaxAge: 150 * time.Minute, MaxTotalSize: 150},
+			expectedRemoved: []string{"network_2", "network_3", "network_4"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			artifactsDir := newRuns(t, 100, "network_1", "network_2", "network_3", "network_4", "server_1")
+			removed, err := Clean(artifactsDir, tt.policy)
+			require.NoError(err)
+			expectedRemoved := []string{}
+			for _, name := range tt.expectedRemoved {
+				expectedRemoved = append(expectedRemoved, filepath.Join(artifactsDir, name))
+			}
+			require.Equal(expectedRemoved, removed)
+			for _, dir := range removed {
+				require.NoDirExists(dir)
+			}
+			// only network runs are removed
+			require.DirExists(filepath.Join(artifactsDir, "server_1"))
+		})
+	}
+
+	require := require.New(t)
+	_, err := Clean(t.TempDir(), network.CleanupPolicy{KeepLast: -1})
+	require.Error(err)
+	removed, err := Clean(filepath.Join(t.TempDir(), "missing"), network.CleanupPolicy{KeepLast: 1})
+	require.NoError(err)
+	require.Empty(removed)
+}
+
+func TestCleanRunsInUse(t *testing.T) {
+	require := require.New(t)
+	artifactsDir := t.TempDir()
+	now := time.Now()
+	for i, name := range []string{"network_1", "network_2", "network_3", "network_4"} {
+		dir := filepath.Join(artifactsDir, name)
+		require.NoError(os.MkdirAll(dir, os.ModePerm))
+		modTime := now.Add(-time.Duration(i) * time.Hour)
+		require.NoError(os.Chtimes(dir, modTime, modTime))
+	}
+	// network_3 is used by this process, network_4 by a process that exited
+	require.NoError(writeRunPIDs(filepath.Join(artifactsDir, "network_3"), []int{os.Getpid()}))
+	exited := exec.Command("true")
+	require.NoError(exited.Run())
+	require.NoError(writeRunPIDs(filepath.Join(artifactsDir, "network_4"), []int{exited.Process.Pid}))
+
+	// network_2 is the caller's, and kept along with network_3,
+	// though older than network_1
+	removed, err := cleanRuns(artifactsDir, network.CleanupPolicy{KeepLast: 1}, filepath.Join(artifactsDir, "network_2"))
+	require.NoError(err)
+	require.ElementsMatch([]string{filepath.Join(artifactsDir, "network_1"), filepath.Join(artifactsDir, "network_4")}, removed)
+	require.DirExists(filepath.Join(artifactsDir, "network_2"))
+	require.DirExists(filepath.Join(artifactsDir, "network_3"))
+}
+
+func TestNetworkRunInUse(t *testing.T) {
+	require := require.New(t)
+	rootDir := t.TempDir()
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, rootDir, "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.True(runInUse(rootDir))
+	require.NoError(net.Stop(context.Background()))
+	require.False(runInUse(rootDir))
+	require.DirExists(rootDir)
+}
+
+func TestNewRootDataDir(t *testing.T) {
+	require := require.New(t)
+	t.Setenv("TMPDIR", t.TempDir())
+
+	dir1, err := newRootDataDir("mynet")
+	require.NoError(err)
+	require.Equal(defaultArtifactsDir(), filepath.Dir(dir1))
+	require.True(strings.HasPrefix(filepath.Base(dir1), "network_mynet_"))
+	// networks created at the same time get their own dirs
+	dir2, err := newRootDataDir("mynet")
+	require.NoError(err)
+	require.NotEqual(dir1, dir2)
+	require.DirExists(dir2)
+
+	// named runs are cleaned up too
+	removed, err := Clean("", network.CleanupPolicy{KeepLast: 1})
+	require.NoError(err)
+	require.Len(removed, 1)
+}
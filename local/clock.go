@@ -0,0 +1,37 @@
+package local
+
+import "time"
+
+var _ clock = realClock{}
+
+// clock is used by the network's timing logic: polling loops, delayed node
+// starts and node start times, so that tests can run it without waiting.
+// Timeouts are given by contexts, and are not affected.
+type clock interface {
+	Now() time.Time
+	// Returns a channel that receives the current time once [d] has elapsed.
+	After(d time.Duration) <-chan time.Time
+	// Calls [f] in its own goroutine once [d] has elapsed.
+	AfterFunc(d time.Duration, f func()) stopper
+}
+
+// stopper prevents a function scheduled with clock.AfterFunc from being
+// called. Returns false if it was already called or stopped.
+type stopper interface {
+	Stop() bool
+}
+
+// realClock is a clock backed by the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) stopper {
+	return time.AfterFunc(d, f)
+}
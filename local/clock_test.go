@@ -0,0 +1,80 @@
+package local
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ clock = (*fakeClock)(nil)
+
+// fakeClock is a clock where time only passes when it is waited for, and
+// does so instantly: After and AfterFunc advance it by the given duration.
+type fakeClock struct {
+	lock sync.Mutex
+	now  time.Time
+	// called once the time reaches the given deadline
+	deadlines []fakeDeadline
+}
+
+type fakeDeadline struct {
+	at     time.Time
+	cancel context.CancelFunc
+}
+
+type fakeStopper struct{}
+
+func (fakeStopper) Stop() bool {
+	return false
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Now()}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.advance(d)
+	return ch
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) stopper {
+	c.advance(d)
+	go f()
+	return fakeStopper{}
+}
+
+// withTimeout returns a context that is cancelled once the clock
+// is advanced by [d], so that timeouts don't wait either.
+func (c *fakeClock) withTimeout(d time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.deadlines = append(c.deadlines, fakeDeadline{at: c.now.Add(d), cancel: cancel})
+	return ctx, cancel
+}
+
+func (c *fakeClock) advance(d time.Duration) time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = c.now.Add(d)
+	deadlines := c.deadlines[:0]
+	for _, deadline := range c.deadlines {
+		if c.now.Before(deadline.at) {
+			deadlines = append(deadlines, deadline)
+		} else {
+			deadline.cancel()
+		}
+	}
+	c.deadlines = deadlines
+	return c.now
+}
@@ -0,0 +1,167 @@
+package local
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// max number of lines of the node's main log kept in a crash report
+	crashLogTailLines = 100
+	// max number of bytes read from the end of the node's main log
+	// to get its last lines
+	crashLogTailMaxBytes = 64 * 1024
+	// max size of the panic trace kept in a crash report, as a panic
+	// dumps the traces of all the goroutines of the node
+	maxPanicTraceSize = 1024 * 1024
+	// max number of lines of a node's output, or main log, added to the
+	// errors on its start
+	startupErrorLogLines = 20
+)
+
+// panicPrefixes start the message a go program writes to stderr
+// before its goroutine traces when it crashes
+var panicPrefixes = []string{"panic: ", "fatal error: "}
+
+// stderrCapture receives the stderr of a node process, keeping the panic
+// trace it writes when it crashes, and its last lines in [recent], if set,
+// and forwarding it to [out], if set.
+type stderrCapture struct {
+	lock sync.Mutex
+	// the stderr is forwarded here, if redirected
+	out io.WriteCloser
+	// current line, not yet terminated
+	line []byte
+	// set once a line starting the panic trace is seen
+	panicking  bool
+	panicTrace bytes.Buffer
+	recent     *recentLogs
+	// if set, the lines are forwarded and kept stamped,
+	// and so only once terminated
+	stamper *logStamper
+}
+
+func (c *stderrCapture) Write(p []byte) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.out != nil && c.stamper == nil {
+		// the output is only for display, don't fail the process on it
+		_, _ = c.out.Write(p)
+	}
+	splitLines(&c.line, p, c.addLine)
+	return len(p), nil
+}
+
+// Assumes [c.lock] is held.
+func (c *stderrCapture) addLine(line []byte) {
+	if c.stamper != nil {
+		stamped := c.stamper.stamp(line)
+		if c.out != nil {
+			_, _ = c.out.Write(stamped)
+		}
+		if c.recent != nil {
+			c.recent.add(logLine(stamped))
+		}
+	} else if c.recent != nil {
+		c.recent.add(logLine(line))
+	}
+	if !c.panicking {
+		for _, prefix := range panicPrefixes {
+			if bytes.HasPrefix(line, []byte(prefix)) {
+				c.panicking = true
+				break
+			}
+		}
+	}
+	if c.panicking && c.panicTrace.Len()+len(line) <= maxPanicTraceSize {
+		c.panicTrace.Write(line)
+	}
+}
+
+// Returns the panic trace written so far, or the empty string if none.
+func (c *stderrCapture) getPanicTrace() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(c.line) > 0 {
+		// the process exited in the middle of a line
+		c.addLine(c.line)
+		c.line = c.line[:0]
+	}
+	return c.panicTrace.String()
+}
+
+// Close keeps the last line, if not terminated, and closes [c.out], if set.
+// Called once the process exits.
+func (c *stderrCapture) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(c.line) > 0 {
+		c.addLine(c.line)
+		c.line = c.line[:0]
+	}
+	if c.out == nil {
+		return nil
+	}
+	return c.out.Close()
+}
+
+// Returns the last [n] lines of the file at [path], reading at most
+// [crashLogTailMaxBytes] from its end.
+func readLogTail(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := max(info.Size()-crashLogTailMaxBytes, 0)
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	tail, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimRight(string(tail), "\n"), "\n")
+	if offset > 0 {
+		// the first line read is likely partial
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		return nil, nil
+	}
+	return lines, nil
+}
+
+// Returns the last lines [node] wrote to its stdout and stderr, or if none,
+// the last lines of its main log, so that the errors on its start show
+// their cause, e.g. a bad flag or a port bind failure.
+func (node *localNode) startupLogs() []string {
+	if lines := node.RecentLogs(startupErrorLogLines); len(lines) != 0 {
+		return lines
+	}
+	lines, _ := readLogTail(filepath.Join(node.logsDir, mainLogFileName), startupErrorLogLines)
+	return lines
+}
+
+// Returns [err] followed by the node log [lines], if any
+func withLogs(err error, lines []string) error {
+	if len(lines) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w, last logs:\n%s", err, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,242 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+// localTestCrashingProcessCreator creates processes that are running until
+// [crash] is closed, and then report [crashReport]
+type localTestCrashingProcessCreator struct {
+	crash       chan struct{}
+	crashReport node.CrashReport
+}
+
+func (lt *localTestCrashingProcessCreator) NewNodeProcess(config node.Config, _ time.Duration, _ ...string) (NodeProcess, error) {
+	crashReport := lt.crashReport
+	crashReport.NodeName = config.Name
+	return &localTestCrashingProcess{crash: lt.crash, crashReport: crashReport}, nil
+}
+
+func (*localTestCrashingProcessCreator) GetNodeVersion(node.Config) (string, error) {
+	return nodeVersion, nil
+}
+
+type localTestCrashingProcess struct {
+	crash       chan struct{}
+	crashReport node.CrashReport
+}
+
+func (*localTestCrashingProcess) Stop(context.Context) int {
+	return 0
+}
+
+func (lp *localTestCrashingProcess) Status() status.Status {
+	select {
+	case <-lp.crash:
+		return status.Stopped
+	default:
+		return status.Running
+	}
+}
+
+func (lp *localTestCrashingProcess) Done() <-chan struct{} {
+	return lp.crash
+}
+
+func (lp *localTestCrashingProcess) CrashReport() *node.CrashReport {
+	crashReport := lp.crashReport
+	return &crashReport
+}
+
+func TestStderrCapture(t *testing.T) {
+	require := require.New(t)
+	capture := &stderrCapture{}
+	for _, s := range []string{
+		"some warning\n",
+		"pan",
+		"ic: boom\n\ngoroutine 1 [running]:\nmain.main()\n",
+		"\tmain.go:5 +0x1d",
+	} {
+		n, err := capture.Write([]byte(s))
+		require.NoError(err)
+		require.Len(s, n)
+	}
+	require.Equal("panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\tmain.go:5 +0x1d", capture.getPanicTrace())
+
+	capture = &stderrCapture{}
+	_, err := capture.Write([]byte("fatal error: concurrent map writes\n"))
+	require.NoError(err)
+	require.Equal("fatal error: concurrent map writes\n", capture.getPanicTrace())
+
+	capture = &stderrCapture{}
+	_, err = capture.Write([]byte("no panic here: panic: not at line start\n"))
+	require.NoError(err)
+	require.Empty(capture.getPanicTrace())
+}
+
+func TestReadLogTail(t *testing.T) {
+	require := require.New(t)
+	path := filepath.Join(t.TempDir(), mainLogFileName)
+
+	_, err := readLogTail(path, 10)
+	require.ErrorIs(err, os.ErrNotExist)
+
+	require.NoError(os.WriteFile(path, nil, 0o600))
+	lines, err := readLogTail(path, 10)
+	require.NoError(err)
+	require.Empty(lines)
+
+	require.NoError(os.WriteFile(path, []byte("line 1\nline 2\nline 3\n"), 0o600))
+	lines, err = readLogTail(path, 2)
+	require.NoError(err)
+	require.Equal([]string{"line 2", "line 3"}, lines)
+
+	// only the end of big logs is read, without the partial first line
+	longLine := strings.Repeat("x", crashLogTailMaxBytes)
+	require.NoError(os.WriteFile(path, []byte(longLine+"\nlast\n"), 0o600))
+	lines, err = readLogTail(path, 10)
+	require.NoError(err)
+	require.Equal([]string{"last"}, lines)
+}
+
+// TestNodeProcessCrashReport checks that a process exiting on its own
+// reports its exit code and panic trace, while a stopped one doesn't
+func TestNodeProcessCrashReport(t *testing.T) {
+	require := require.New(t)
+	npc := &nodeProcessCreator{log: logging.NoLog{}, colorPicker: utils.NewColorPicker()}
+
+	proc, err := npc.NewNodeProcess(
+		node.Config{Name: "crashing", BinaryPath: "sh"},
+		0,
+		"-c", `echo "panic: boom" >&2; echo "goroutine 1 [running]:" >&2; exit 2`,
+	)
+	require.NoError(err)
+	<-proc.Done()
+	crashReport := proc.CrashReport()
+	require.NotNil(crashReport)
+	require.Equal("crashing", crashReport.NodeName)
+	require.Equal(2, crashReport.ExitCode)
+	require.Equal("panic: boom\ngoroutine 1 [running]:\n", crashReport.PanicTrace)
+	require.False(crashReport.Time.IsZero())
+
+	proc, err = npc.NewNodeProcess(node.Config{Name: "stopped", BinaryPath: "sleep"}, 0, "10")
+	require.NoError(err)
+	_ = proc.Stop(context.Background())
+	<-proc.Done()
+	require.Nil(proc.CrashReport())
+}
+
+func TestNodeCrash(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	creator := &localTestCrashingProcessCreator{
+		crash:       make(chan struct{}),
+		crashReport: node.CrashReport{ExitCode: 2, PanicTrace: "panic: boom\n"},
+	}
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		creator,
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	var (
+		lock    sync.Mutex
+		crashed = map[string]node.CrashReport{}
+		doneCh  = make(chan struct{})
+	)
+	net.RegisterNodeHooks(network.NodeHooks{
+		OnNodeCrash: func(node node.Node, crashReport node.CrashReport) {
+			lock.Lock()
+			defer lock.Unlock()
+			crashed[node.GetName()] = crashReport
+			if len(crashed) == 3 {
+				close(doneCh)
+			}
+		},
+	})
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	node0, err := net.GetNode("node0")
+	require.NoError(err)
+	require.Nil(node0.CrashReport())
+	require.NoError(os.MkdirAll(node0.GetLogsDir(), 0o750))
+	require.NoError(os.WriteFile(
+		filepath.Join(node0.GetLogsDir(), mainLogFileName),
+		[]byte(fmt.Sprintf("%s\nlast line\n", strings.Repeat("line\n", crashLogTailLines))),
+		0o600,
+	))
+
+	close(creator.crash)
+	<-doneCh
+	crashReport := node0.CrashReport()
+	require.NotNil(crashReport)
+	require.Equal(crashed["node0"], *crashReport)
+	require.Equal("node0", crashReport.NodeName)
+	require.Equal(2, crashReport.ExitCode)
+	require.Equal("panic: boom\n", crashReport.PanicTrace)
+	require.Len(crashReport.LogTail, crashLogTailLines)
+	require.Equal("last line", crashReport.LogTail[crashLogTailLines-1])
+	// the report outlives the node's dirs
+	require.NoError(os.RemoveAll(node0.GetLogsDir()))
+	require.Equal(crashReport, node0.CrashReport())
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestNodeProcessStartupErrorLogs(t *testing.T) {
+	require := require.New(t)
+	npc := &nodeProcessCreator{log: logging.NoLog{}, colorPicker: utils.NewColorPicker()}
+
+	_, err := npc.NewNodeProcess(
+		node.Config{Name: "misconfigured", BinaryPath: "sh"},
+		500*time.Millisecond,
+		"-c", `echo "couldn't load node config" >&2; echo "unknown flag: --foo" >&2; exit 1`,
+	)
+	require.ErrorContains(err, "process failed before startup time")
+	require.ErrorContains(err, "couldn't load node config\nunknown flag: --foo")
+}
+
+func TestHealthyNodeStoppedLogs(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	creator := &localTestCrashingProcessCreator{crash: make(chan struct{})}
+	net, err := newNetwork(logging.NoLog{}, newMockAPIUnhealthy, creator, "", "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	for _, node := range net.nodes {
+		require.NoError(os.MkdirAll(node.GetLogsDir(), 0o750))
+		require.NoError(os.WriteFile(
+			filepath.Join(node.GetLogsDir(), mainLogFileName),
+			[]byte(strings.Repeat("line\n", 2*startupErrorLogLines)+"couldn't parse genesis\n"),
+			0o600,
+		))
+	}
+
+	close(creator.crash)
+	err = net.Healthy(context.Background())
+	require.ErrorIs(err, errNodeStopped)
+	require.ErrorContains(err, "couldn't parse genesis")
+	require.Equal(startupErrorLogLines, strings.Count(err.Error(), "\n"))
+}
@@ -0,0 +1,323 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/api"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/shirou/gopsutil/process"
+	"go.uber.org/zap"
+)
+
+// time between checks of whether an adopted process is still running
+const adoptedProcessPollInterval = 500 * time.Millisecond
+
+var (
+	_ NodeProcess        = (*adoptedProcess)(nil)
+	_ pidGetter          = (*adoptedProcess)(nil)
+	_ signaler           = (*adoptedProcess)(nil)
+	_ NodeProcessCreator = (*adoptingProcessCreator)(nil)
+)
+
+// ControlFile describes a network started by StartDetached, so that other
+// processes can reattach to it with Adopt
+type ControlFile struct {
+	RootDir      string `json:"rootDir"`
+	LogRootDir   string `json:"logRootDir"`
+	SnapshotsDir string `json:"snapshotsDir"`
+	// Config of the network, with the current node ports
+	Config network.Config `json:"config"`
+	// Node name --> ID of its OS process, for the running nodes
+	PIDs map[string]int `json:"pids"`
+}
+
+// StartDetached starts a network as NewNetwork does, but with node processes
+// that outlive this process, e.g. a test binary, and writes the control file
+// at [controlFilePath], that other processes give to Adopt to reattach to
+// the network, so that it can be started once for many test binaries. The
+// control file is kept up to date as the network changes. The node output
+// is discarded, see the node log files. The node processes are only stopped
+// by Stop, e.g. called on the network by the last process adopting it.
+func StartDetached(
+	log logging.Logger,
+	networkConfig network.Config,
+	rootDir string,
+	controlFilePath string,
+) (network.Network, error) {
+	npc := &nodeProcessCreator{
+		colorPicker: utils.NewColorPicker(),
+		log:         log,
+		stdout:      os.Stdout,
+		stderr:      os.Stderr,
+		detached:    true,
+	}
+	un, err := newUnstartedNetwork(log, networkConfig, npc, rootDir, "", "", false, false, false, "", false)
+	if err != nil {
+		return nil, err
+	}
+	un.ln.controlFilePath = controlFilePath
+	net, err := un.Start(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if err := un.ln.persistNetwork(); err != nil {
+		return net, fmt.Errorf("couldn't write control file: %w", err)
+	}
+	return net, nil
+}
+
+// Adopt reattaches to the network started by StartDetached, described by
+// the control file at [controlFilePath]. The nodes whose processes are no
+// longer running are started again. Stop stops the node processes, so it
+// should only be called by the last process using the network.
+func Adopt(log logging.Logger, controlFilePath string) (network.Network, error) {
+	controlFileJSON, err := os.ReadFile(controlFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read control file: %w", err)
+	}
+	var controlFile ControlFile
+	if err := json.Unmarshal(controlFileJSON, &controlFile); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal control file: %w", err)
+	}
+	beaconSet, err := utils.BeaconMapToSet(controlFile.Config.BeaconConfig)
+	if err != nil {
+		return nil, err
+	}
+	npc := &nodeProcessCreator{
+		colorPicker: utils.NewColorPicker(),
+		log:         log,
+		stdout:      os.Stdout,
+		stderr:      os.Stderr,
+		detached:    true,
+	}
+	return adopt(log, controlFilePath, controlFile, newAPIClientF(nil, 0), npc, beaconSet)
+}
+
+// See Adopt.
+// [nodeProcessCreator] starts the nodes whose processes aren't running.
+func adopt(
+	log logging.Logger,
+	controlFilePath string,
+	controlFile ControlFile,
+	newAPIClientF api.NewAPIClientF,
+	nodeProcessCreator NodeProcessCreator,
+	beaconSet beacon.Set,
+) (network.Network, error) {
+	ln, err := newNetwork(
+		log,
+		newAPIClientF,
+		&adoptingProcessCreator{
+			NodeProcessCreator: nodeProcessCreator,
+			log:                log,
+			pids:               controlFile.PIDs,
+		},
+		controlFile.RootDir,
+		controlFile.LogRootDir,
+		controlFile.SnapshotsDir,
+		false,
+		false,
+		false,
+		"",
+		beaconSet,
+		false,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := ln.loadNetworkState(ln.rootDir); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("couldn't load network state: %w", err)
+	}
+	if err := ln.loadConfig(context.Background(), controlFile.Config); err != nil {
+		return nil, err
+	}
+	ln.nodeProcessCreator = nodeProcessCreator
+	ln.controlFilePath = controlFilePath
+	return ln, ln.persistNetwork()
+}
+
+// Writes the control file of the network, given its config as persisted.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) writeControlFile(networkConfig network.Config) error {
+	pids := map[string]int{}
+	// the nodes keep using the run once this process exits
+	runPIDs := []int{os.Getpid()}
+	for name, node := range ln.nodes {
+		if pid := node.PID(); pid != 0 {
+			pids[name] = pid
+			runPIDs = append(runPIDs, pid)
+		}
+	}
+	if err := writeRunPIDs(ln.rootDir, runPIDs); err != nil {
+		return err
+	}
+	controlFileJSON, err := json.MarshalIndent(ControlFile{
+		RootDir:      ln.rootDir,
+		LogRootDir:   ln.logRootDir,
+		SnapshotsDir: ln.snapshotsDir,
+		Config:       networkConfig,
+		PIDs:         pids,
+	}, "", "    ")
+	if err != nil {
+		return err
+	}
+	return createFileAndWrite(ln.controlFilePath, controlFileJSON)
+}
+
+// adoptingProcessCreator returns the running processes of the nodes
+// given by [pids] instead of creating them, see Adopt
+type adoptingProcessCreator struct {
+	NodeProcessCreator
+	log  logging.Logger
+	pids map[string]int
+}
+
+func (apc *adoptingProcessCreator) NewNodeProcess(config node.Config, startupTime time.Duration, args ...string) (NodeProcess, error) {
+	pid, ok := apc.pids[config.Name]
+	if ok {
+		if exists, err := process.PidExists(int32(pid)); err == nil && exists {
+			return newAdoptedProcess(config.Name, apc.log, pid), nil
+		}
+		apc.log.Info("adopted node process is not running, starting it again",
+			zap.String("node-name", config.Name),
+			zap.Int("pid", pid),
+		)
+	}
+	return apc.NodeProcessCreator.NewNodeProcess(config, startupTime, args...)
+}
+
+// adoptedProcess is a running node process that was started by another
+// process, and so can't be waited for: its exit is polled for
+type adoptedProcess struct {
+	name string
+	log  logging.Logger
+	pid  int
+	lock sync.RWMutex
+	// Process status
+	state status.Status
+	// Closed when the process exits.
+	closedOnStop chan struct{}
+	// Set if the process exited without Stop being called
+	crashReport *node.CrashReport
+}
+
+func newAdoptedProcess(name string, log logging.Logger, pid int) *adoptedProcess {
+	p := &adoptedProcess{
+		name:         name,
+		log:          log,
+		pid:          pid,
+		state:        status.Running,
+		closedOnStop: make(chan struct{}),
+	}
+	go p.awaitExit()
+	return p
+}
+
+// Polls for the process to exit.
+// When it does, update the state and close [p.closedOnStop]
+func (p *adoptedProcess) awaitExit() {
+	for {
+		if exists, err := process.PidExists(int32(p.pid)); err == nil && !exists {
+			break
+		}
+		time.Sleep(adoptedProcessPollInterval)
+	}
+	p.log.Debug("adopted node process finished", zap.String("node", p.name))
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.state == status.Running {
+		// not stopped by Stop, the exit code is unknown
+		p.crashReport = &node.CrashReport{
+			NodeName: p.name,
+			ExitCode: -1,
+			Time:     time.Now(),
+		}
+	}
+	p.state = status.Stopped
+	close(p.closedOnStop)
+}
+
+func (p *adoptedProcess) Stop(ctx context.Context) int {
+	p.lock.Lock()
+	if p.state != status.Running {
+		p.lock.Unlock()
+		<-p.closedOnStop
+		return 0
+	}
+	p.state = status.Stopping
+	p.lock.Unlock()
+
+	proc, err := os.FindProcess(p.pid)
+	if err != nil {
+		p.log.Warn("couldn't find adopted process", zap.Error(err))
+	} else if err := proc.Signal(os.Interrupt); err != nil {
+		p.log.Warn("sending SIGINT errored", zap.Error(err))
+	}
+
+	select {
+	case <-ctx.Done():
+		p.log.Warn("context cancelled while waiting for node to stop", zap.String("node", p.name))
+		killDescendants(int32(p.pid), p.log)
+		if proc != nil {
+			if err := proc.Signal(os.Kill); err != nil {
+				p.log.Warn("sending SIGKILL errored", zap.Error(err))
+			}
+		}
+	case <-p.closedOnStop:
+	}
+
+	<-p.closedOnStop
+	return 0
+}
+
+func (p *adoptedProcess) Status() status.Status {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.state
+}
+
+func (p *adoptedProcess) Done() <-chan struct{} {
+	return p.closedOnStop
+}
+
+func (p *adoptedProcess) CrashReport() *node.CrashReport {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.crashReport == nil {
+		return nil
+	}
+	crashReport := *p.crashReport
+	return &crashReport
+}
+
+func (p *adoptedProcess) getPID() int {
+	return p.pid
+}
+
+func (p *adoptedProcess) signal(sig os.Signal) error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.state != status.Running {
+		return errNodeNotRunning
+	}
+	proc, err := os.FindProcess(p.pid)
+	if err != nil {
+		return err
+	}
+	return proc.Signal(sig)
+}
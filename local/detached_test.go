@@ -0,0 +1,100 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/local/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdoptedProcess(t *testing.T) {
+	require := require.New(t)
+	cmd := exec.Command("sleep", "60")
+	require.NoError(cmd.Start())
+	go func() { _ = cmd.Wait() }()
+
+	p := newAdoptedProcess("node0", logging.NoLog{}, cmd.Process.Pid)
+	require.Equal(status.Running, p.Status())
+	require.Equal(cmd.Process.Pid, p.getPID())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.Equal(0, p.Stop(ctx))
+	require.Equal(status.Stopped, p.Status())
+	require.Nil(p.CrashReport())
+	require.ErrorIs(p.signal(os.Interrupt), errNodeNotRunning)
+
+	// exits without Stop
+	exitingCmd := exec.Command("sleep", "60")
+	require.NoError(exitingCmd.Start())
+	go func() { _ = exitingCmd.Wait() }()
+	p = newAdoptedProcess("node1", logging.NoLog{}, exitingCmd.Process.Pid)
+	require.NoError(exitingCmd.Process.Kill())
+	select {
+	case <-p.Done():
+	case <-ctx.Done():
+		require.FailNow("adopted process exit not detected")
+	}
+	crashReport := p.CrashReport()
+	require.NotNil(crashReport)
+	require.Equal("node1", crashReport.NodeName)
+	require.Equal(-1, crashReport.ExitCode)
+}
+
+func TestAdopt(t *testing.T) {
+	require := require.New(t)
+	controlFilePath := filepath.Join(t.TempDir(), "control.json")
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", t.TempDir(), false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	net.controlFilePath = controlFilePath
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	require.NoError(net.persistNetwork())
+
+	controlFileJSON, err := os.ReadFile(controlFilePath)
+	require.NoError(err)
+	var controlFile ControlFile
+	require.NoError(json.Unmarshal(controlFileJSON, &controlFile))
+	require.Equal(net.rootDir, controlFile.RootDir)
+	require.Len(controlFile.Config.NodeConfigs, 3)
+
+	// node0 is still running, node1 isn't, node2 was never known
+	cmd := exec.Command("sleep", "60")
+	require.NoError(cmd.Start())
+	go func() { _ = cmd.Wait() }()
+	defer func() { _ = cmd.Process.Kill() }()
+	exited := exec.Command("true")
+	require.NoError(exited.Run())
+	controlFile.PIDs = map[string]int{
+		"node0": cmd.Process.Pid,
+		"node1": exited.Process.Pid,
+	}
+	adopted, err := adopt(logging.NoLog{}, controlFilePath, controlFile, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, beacon.NewSet())
+	require.NoError(err)
+	ln := adopted.(*localNetwork)
+	require.Len(ln.nodes, 3)
+	require.IsType(&adoptedProcess{}, ln.nodes["node0"].process)
+	require.IsType(&mocks.NodeProcess{}, ln.nodes["node1"].process)
+	require.IsType(&mocks.NodeProcess{}, ln.nodes["node2"].process)
+	require.Equal(cmd.Process.Pid, ln.nodes["node0"].PID())
+
+	// the control file is kept up to date
+	controlFileJSON, err = os.ReadFile(controlFilePath)
+	require.NoError(err)
+	controlFile = ControlFile{}
+	require.NoError(json.Unmarshal(controlFileJSON, &controlFile))
+	require.Equal(map[string]int{"node0": cmd.Process.Pid}, controlFile.PIDs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	adoptedProcess := ln.nodes["node0"].process
+	require.NoError(adopted.Stop(ctx))
+	require.Equal(status.Stopped, adoptedProcess.Status())
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package local
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// Makes [cmd] run on its own process group, so that it doesn't get the
+// signals sent to the group of this process, e.g. on Ctrl+C
+func setDetached(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
@@ -0,0 +1,169 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	dircopy "github.com/otiai10/copy"
+	"github.com/shirou/gopsutil/disk"
+	"go.uber.org/zap"
+)
+
+const (
+	// ballast file written to a node's db dir to fill its disk
+	diskFillFileName = "anr-disk-fill"
+	// size of the writes of the ballast file
+	diskFillChunkSize = 1024 * 1024
+)
+
+var errDiskChaosNotSupported = errors.New("disk chaos is only supported on linux")
+
+// See network.Network
+func (ln *localNetwork) SetNodeDiskChaos(ctx context.Context, nodeName string, chaos network.DiskChaos) (err error) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+	record := ln.startOperation("SetNodeDiskChaos", nodeName, map[string]string{"chaos": fmt.Sprintf("%+v", chaos)})
+	defer ln.finishOperation(record, &err)
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	if err := ln.setNodeDiskChaos(ctx, nodeName, chaos); err != nil {
+		return &network.NodeError{NodeName: nodeName, Op: "set disk chaos of", Err: err}
+	}
+	return nil
+}
+
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) setNodeDiskChaos(ctx context.Context, nodeName string, chaos network.DiskChaos) error {
+	node, ok := ln.nodes[nodeName]
+	if !ok {
+		return network.ErrNodeNotFound
+	}
+	if node.paused {
+		return errNodePaused
+	}
+	if err := chaos.Validate(node.config); err != nil {
+		return err
+	}
+	if chaos.ReadBytesPerSec != 0 || chaos.WriteBytesPerSec != 0 || node.cgroupDir != "" {
+		process, ok := node.process.(pidGetter)
+		if !ok {
+			return errors.New("node process has no process id")
+		}
+		cgroupDir, err := throttleDiskIO(node.cgroupDir, process.getPID(), node.dbDir, chaos.ReadBytesPerSec, chaos.WriteBytesPerSec)
+		if err != nil {
+			return fmt.Errorf("couldn't throttle disk I/O: %w", err)
+		}
+		node.cgroupDir = cgroupDir
+	}
+	if err := os.Remove(filepath.Join(node.dbDir, diskFillFileName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if chaos.FillDisk {
+		if err := fillDisk(ctx, node.dbDir, chaos.FreeBytes); err != nil {
+			return fmt.Errorf("couldn't fill disk: %w", err)
+		}
+	}
+	ln.log.Info("set node disk chaos",
+		zap.String("node", nodeName),
+		zap.Uint64("read-bytes-per-sec", chaos.ReadBytesPerSec),
+		zap.Uint64("write-bytes-per-sec", chaos.WriteBytesPerSec),
+		zap.Bool("fill-disk", chaos.FillDisk),
+		zap.Uint64("free-bytes", chaos.FreeBytes),
+	)
+	return nil
+}
+
+// fillDisk writes a ballast file to [dir], until its filesystem
+// has only [freeBytes] free, or is full.
+func fillDisk(ctx context.Context, dir string, freeBytes uint64) error {
+	usage, err := disk.Usage(dir)
+	if err != nil {
+		return err
+	}
+	if usage.Free <= freeBytes {
+		return nil
+	}
+	toWrite := usage.Free - freeBytes
+	f, err := os.OpenFile(filepath.Join(dir, diskFillFileName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	chunk := make([]byte, diskFillChunkSize)
+	for toWrite > 0 {
+		if err := ctx.Err(); err != nil {
+			_ = f.Close()
+			return err
+		}
+		n := min(uint64(len(chunk)), toWrite)
+		if _, err := f.Write(chunk[:n]); err != nil {
+			if errors.Is(err, syscall.ENOSPC) {
+				break
+			}
+			_ = f.Close()
+			return err
+		}
+		toWrite -= n
+	}
+	return f.Close()
+}
+
+// Mounts a tmpfs of [sizeLimit] bytes on [dbDir], the db dir of the node
+// [nodeName], unless it is already mounted, e.g. because the node is
+// restarted or resumed. The files already in [dbDir], e.g. restored from
+// a snapshot, are copied to the tmpfs.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) mountDBDir(nodeName string, dbDir string, sizeLimit int64) error {
+	if _, ok := ln.dbDirMounts[nodeName]; ok {
+		return nil
+	}
+	var seedDir string
+	if _, err := os.Stat(dbDir); err == nil {
+		seedDir = dbDir + ".seed"
+		if err := os.Rename(dbDir, seedDir); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(dbDir, 0o750); err != nil {
+		return err
+	}
+	if err := mountTmpfs(dbDir, sizeLimit); err != nil {
+		if seedDir != "" {
+			// restore the db
+			_ = os.Remove(dbDir)
+			_ = os.Rename(seedDir, dbDir)
+		}
+		return fmt.Errorf("couldn't mount db dir %q: %w", dbDir, err)
+	}
+	ln.dbDirMounts[nodeName] = dbDir
+	ln.log.Info("mounted node db dir", zap.String("node", nodeName), zap.String("db-dir", dbDir), zap.Int64("size-limit", sizeLimit))
+	if seedDir == "" {
+		return nil
+	}
+	if err := dircopy.Copy(seedDir, dbDir); err != nil {
+		return fmt.Errorf("couldn't copy db to mounted db dir: %w", err)
+	}
+	return os.RemoveAll(seedDir)
+}
+
+// Unmounts the db dir of the node [nodeName], if mounted.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) unmountDBDir(nodeName string) error {
+	dbDir, ok := ln.dbDirMounts[nodeName]
+	if !ok {
+		return nil
+	}
+	if err := unmountTmpfs(dbDir); err != nil {
+		return fmt.Errorf("couldn't unmount db dir %q: %w", dbDir, err)
+	}
+	delete(ln.dbDirMounts, nodeName)
+	return nil
+}
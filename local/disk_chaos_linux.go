@@ -0,0 +1,129 @@
+//go:build linux
+
+package local
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	// cgroup the node cgroups are created under, on the cgroup v2 hierarchy
+	cgroupParentName = "avalanche-network-runner"
+	mountInfoPath    = "/proc/self/mountinfo"
+)
+
+func mountTmpfs(dir string, sizeLimit int64) error {
+	return syscall.Mount("tmpfs", dir, "tmpfs", 0, fmt.Sprintf("size=%d", sizeLimit))
+}
+
+func unmountTmpfs(dir string) error {
+	return syscall.Unmount(dir, 0)
+}
+
+// throttleDiskIO limits the rate at which the process [pid] reads from and
+// writes to the block device of [dbDir] to [readBytesPerSec] and
+// [writeBytesPerSec], zero being unlimited, by moving it to a cgroup v2 with
+// an io.max. The cgroup is [cgroupDir], if the process was already moved to
+// one, or a new one. Returns the cgroup.
+func throttleDiskIO(cgroupDir string, pid int, dbDir string, readBytesPerSec uint64, writeBytesPerSec uint64) (string, error) {
+	device, err := getBlockDevice(dbDir)
+	if err != nil {
+		return "", err
+	}
+	if cgroupDir == "" {
+		cgroupRoot, err := getCgroup2Root()
+		if err != nil {
+			return "", err
+		}
+		parentDir := filepath.Join(cgroupRoot, cgroupParentName)
+		if err := os.MkdirAll(parentDir, 0o755); err != nil {
+			return "", err
+		}
+		// the io controller must be enabled on the ancestors of the cgroup
+		for _, dir := range []string{cgroupRoot, parentDir} {
+			if err := os.WriteFile(filepath.Join(dir, "cgroup.subtree_control"), []byte("+io"), 0o600); err != nil {
+				return "", fmt.Errorf("couldn't enable the io controller: %w", err)
+			}
+		}
+		cgroupDir = filepath.Join(parentDir, fmt.Sprintf("node-%d", pid))
+		if err := os.Mkdir(cgroupDir, 0o755); err != nil && !errors.Is(err, os.ErrExist) {
+			return "", err
+		}
+		if err := os.WriteFile(filepath.Join(cgroupDir, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0o600); err != nil {
+			_ = os.Remove(cgroupDir)
+			return "", fmt.Errorf("couldn't move node process to cgroup: %w", err)
+		}
+	}
+	ioMax := fmt.Sprintf("%s rbps=%s wbps=%s", device, ioMaxRate(readBytesPerSec), ioMaxRate(writeBytesPerSec))
+	if err := os.WriteFile(filepath.Join(cgroupDir, "io.max"), []byte(ioMax), 0o600); err != nil {
+		return cgroupDir, fmt.Errorf("couldn't set io.max: %w", err)
+	}
+	return cgroupDir, nil
+}
+
+// removeCgroup removes the cgroup created by throttleDiskIO,
+// once its process exited.
+func removeCgroup(cgroupDir string) error {
+	return os.Remove(cgroupDir)
+}
+
+func ioMaxRate(bytesPerSec uint64) string {
+	if bytesPerSec == 0 {
+		return "max"
+	}
+	return strconv.FormatUint(bytesPerSec, 10)
+}
+
+// Returns the mount point of the cgroup v2 hierarchy
+func getCgroup2Root() (string, error) {
+	f, err := os.Open(mountInfoPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// e.g. 35 24 0:30 / /sys/fs/cgroup rw,nosuid - cgroup2 cgroup2 rw
+		fields := strings.Fields(scanner.Text())
+		sep := slices.Index(fields, "-")
+		if sep > 4 && sep+1 < len(fields) && fields[sep+1] == "cgroup2" {
+			return fields[4], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("cgroup v2 is not mounted")
+}
+
+// Returns the major:minor numbers of the disk holding [dir], as
+// io.max doesn't accept partitions
+func getBlockDevice(dir string) (string, error) {
+	var stat syscall.Stat_t
+	if err := syscall.Stat(dir, &stat); err != nil {
+		return "", err
+	}
+	dev := uint64(stat.Dev) //nolint:unconvert
+	major := (dev>>8)&0xfff | (dev>>32)&^0xfff
+	minor := dev&0xff | (dev>>12)&^0xff
+	sysDir, err := filepath.EvalSymlinks(fmt.Sprintf("/sys/dev/block/%d:%d", major, minor))
+	if err != nil {
+		return "", fmt.Errorf("%q is not on a block device: %w", dir, err)
+	}
+	if _, err := os.Stat(filepath.Join(sysDir, "partition")); err == nil {
+		sysDir = filepath.Dir(sysDir)
+	}
+	device, err := os.ReadFile(filepath.Join(sysDir, "dev"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(device)), nil
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package local
+
+func mountTmpfs(string, int64) error {
+	return errDiskChaosNotSupported
+}
+
+func unmountTmpfs(string) error {
+	return errDiskChaosNotSupported
+}
+
+func throttleDiskIO(string, int, string, uint64, uint64) (string, error) {
+	return "", errDiskChaosNotSupported
+}
+
+func removeCgroup(string) error {
+	return errDiskChaosNotSupported
+}
@@ -0,0 +1,129 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/stretchr/testify/require"
+)
+
+const testDBDirSizeLimit = 8 * 1024 * 1024
+
+func skipUnlessCanMount(t *testing.T) {
+	if runtime.GOOS != "linux" || os.Geteuid() != 0 {
+		t.Skip("mounting a tmpfs requires root on linux")
+	}
+}
+
+func TestFillDisk(t *testing.T) {
+	skipUnlessCanMount(t)
+	require := require.New(t)
+	dir := t.TempDir()
+	require.NoError(mountTmpfs(dir, testDBDirSizeLimit))
+	defer func() {
+		require.NoError(unmountTmpfs(dir))
+	}()
+
+	require.NoError(fillDisk(context.Background(), dir, 1024*1024))
+	usage, err := disk.Usage(dir)
+	require.NoError(err)
+	require.Equal(uint64(1024*1024), usage.Free)
+
+	// filling until full
+	require.NoError(os.Remove(filepath.Join(dir, diskFillFileName)))
+	require.NoError(fillDisk(context.Background(), dir, 0))
+	usage, err = disk.Usage(dir)
+	require.NoError(err)
+	require.Zero(usage.Free)
+}
+
+func TestNodeDiskChaos(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+
+	err = net.SetNodeDiskChaos(context.Background(), "missing", network.DiskChaos{})
+	require.ErrorIs(err, network.ErrNodeNotFound)
+	// the host disk is never filled
+	err = net.SetNodeDiskChaos(context.Background(), "node1", network.DiskChaos{FillDisk: true})
+	require.ErrorContains(err, "requires a db dir size limit")
+	// mocked processes can't be throttled
+	err = net.SetNodeDiskChaos(context.Background(), "node1", network.DiskChaos{ReadBytesPerSec: 1024})
+	require.ErrorContains(err, "no process id")
+	require.NoError(net.SetNodeDiskChaos(context.Background(), "node1", network.DiskChaos{}))
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	err = net.SetNodeDiskChaos(context.Background(), "node1", network.DiskChaos{})
+	require.ErrorIs(err, errNodePaused)
+}
+
+func TestNodeDBDirSizeLimit(t *testing.T) {
+	skipUnlessCanMount(t)
+	require := require.New(t)
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+
+	limited, err := net.AddNode(node.Config{Name: "limited", DBDirSizeLimit: testDBDirSizeLimit})
+	require.NoError(err)
+	dbDir := limited.GetDbDir()
+	usage, err := disk.Usage(dbDir)
+	require.NoError(err)
+	require.Equal(uint64(testDBDirSizeLimit), usage.Total)
+	require.NoError(os.WriteFile(filepath.Join(dbDir, "db"), []byte("db"), 0o600))
+
+	err = net.SetNodeDiskChaos(context.Background(), "limited", network.DiskChaos{ReadBytesPerSec: 1024})
+	require.ErrorContains(err, "not supported with a db dir size limit")
+	require.NoError(net.SetNodeDiskChaos(context.Background(), "limited", network.DiskChaos{
+		FillDisk:  true,
+		FreeBytes: 1024 * 1024,
+	}))
+	usage, err = disk.Usage(dbDir)
+	require.NoError(err)
+	require.Equal(uint64(1024*1024), usage.Free)
+	require.NoError(net.SetNodeDiskChaos(context.Background(), "limited", network.DiskChaos{}))
+	require.NoFileExists(filepath.Join(dbDir, diskFillFileName))
+
+	// the db is kept on restart, and lost on removal
+	require.NoError(net.RestartNode(context.Background(), "limited", "", "", "", nil, nil, nil))
+	require.FileExists(filepath.Join(dbDir, "db"))
+	require.NoError(net.RemoveNode(context.Background(), "limited"))
+	require.NoFileExists(filepath.Join(dbDir, "db"))
+	require.Empty(net.dbDirMounts)
+	require.NoError(net.Stop(context.Background()))
+}
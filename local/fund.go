@@ -0,0 +1,196 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/params"
+	"github.com/ava-labs/coreth/plugin/evm"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// time between checks of whether a C-Chain funding tx was accepted
+const fundReceiptPollInterval = 100 * time.Millisecond
+
+var (
+	// C-Chain balances are in wei, 1 nAVAX is 10^9 wei
+	weiPerNAVAX = big.NewInt(1_000_000_000)
+
+	errZeroFundAmount = errors.New("fund amount must be positive")
+)
+
+// See network.Network
+func (ln *localNetwork) Fund(ctx context.Context, addr string, amount uint64, chain network.Chain) (txID ids.ID, err error) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+	record := ln.startOperation("Fund", "", map[string]string{
+		"addr":   addr,
+		"amount": strconv.FormatUint(amount, 10),
+		"chain":  string(chain),
+	})
+	defer ln.finishOperation(record, &err)
+
+	if ln.stopCalled() {
+		return ids.Empty, network.ErrStopped
+	}
+	if amount == 0 {
+		return ids.Empty, errZeroFundAmount
+	}
+	switch chain {
+	case network.XChain, network.PChain:
+		to, err := parseFundAddress(addr, chain)
+		if err != nil {
+			return ids.Empty, err
+		}
+		txID, err = ln.fundAVAX(ctx, to, amount, chain)
+		if err != nil {
+			return ids.Empty, err
+		}
+	case network.CChain:
+		if !ethcommon.IsHexAddress(addr) {
+			return ids.Empty, fmt.Errorf("invalid C-Chain address %q", addr)
+		}
+		txID, err = ln.fundCChain(ctx, ethcommon.HexToAddress(addr), amount)
+		if err != nil {
+			return ids.Empty, err
+		}
+	default:
+		return ids.Empty, fmt.Errorf("unknown chain %q", chain)
+	}
+	ln.log.Info("address funded",
+		zap.String("addr", addr),
+		zap.Uint64("amount", amount),
+		zap.String("chain", string(chain)),
+		zap.Stringer("tx-ID", txID),
+	)
+	return txID, nil
+}
+
+// Returns the address of [addr], given as "<chain alias>-<bech32>",
+// checking that it is an address of [chain]
+func parseFundAddress(addr string, chain network.Chain) (ids.ShortID, error) {
+	chainAlias, _, addrBytes, err := address.Parse(addr)
+	if err != nil {
+		return ids.ShortEmpty, fmt.Errorf("invalid %s-Chain address %q: %w", chain, addr, err)
+	}
+	if chainAlias != string(chain) {
+		return ids.ShortEmpty, fmt.Errorf("address %q is not a %s-Chain address", addr, chain)
+	}
+	return ids.ToShortID(addrBytes)
+}
+
+// Issues a base tx sending [amount] nAVAX to [to] on the X-Chain or P-Chain
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) fundAVAX(ctx context.Context, to ids.ShortID, amount uint64, chain network.Chain) (ids.ID, error) {
+	issuer := ln.getNode()
+	if issuer == nil {
+		return ids.Empty, network.ErrNoRunningNodes
+	}
+	w, err := newWallet(ctx, issuer.GetURI(), nil, ln.walletPrivateKey)
+	if err != nil {
+		return ids.Empty, err
+	}
+	outputs := []*avax.TransferableOutput{{
+		Asset: avax.Asset{ID: w.pCTX.AVAXAssetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: amount,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{to},
+			},
+		},
+	}}
+	if chain == network.XChain {
+		tx, err := w.xWallet.IssueBaseTx(outputs, common.WithContext(ctx))
+		if err != nil {
+			return ids.Empty, fmt.Errorf("X-Wallet Tx Error %s %w", "IssueBaseTx", err)
+		}
+		return tx.ID(), nil
+	}
+	tx, err := w.pWallet.IssueBaseTx(outputs, common.WithContext(ctx))
+	if err != nil {
+		return ids.Empty, fmt.Errorf("P-Wallet Tx Error %s %w", "IssueBaseTx", err)
+	}
+	return tx.ID(), nil
+}
+
+// Sends [amount] nAVAX to [to] on the C-Chain, and waits for the tx to be accepted
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) fundCChain(ctx context.Context, to ethcommon.Address, amount uint64) (ids.ID, error) {
+	issuer := ln.getNode()
+	if issuer == nil {
+		return ids.Empty, network.ErrNoRunningNodes
+	}
+	key, err := getWalletKey(ln.walletPrivateKey)
+	if err != nil {
+		return ids.Empty, err
+	}
+	client := issuer.GetAPIClient().CChainEthAPI()
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("couldn't get C-Chain ID: %w", err)
+	}
+	nonce, err := client.AcceptedNonceAt(ctx, evm.GetEthAddress(key))
+	if err != nil {
+		return ids.Empty, fmt.Errorf("couldn't get nonce: %w", err)
+	}
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("couldn't get gas price: %w", err)
+	}
+	tx, err := signEthTransfer(key, chainID, nonce, to, new(big.Int).Mul(new(big.Int).SetUint64(amount), weiPerNAVAX), gasPrice)
+	if err != nil {
+		return ids.Empty, err
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		return ids.Empty, fmt.Errorf("couldn't send tx: %w", err)
+	}
+	for {
+		if receipt, err := client.TransactionReceipt(ctx, tx.Hash()); err == nil {
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return ids.Empty, fmt.Errorf("tx %s failed", tx.Hash())
+			}
+			return ids.ID(tx.Hash()), nil
+		}
+		select {
+		case <-ctx.Done():
+			return ids.Empty, fmt.Errorf("tx %s wasn't accepted: %w", tx.Hash(), ctx.Err())
+		case <-ln.onStopCh:
+			return ids.Empty, errAborted
+		case <-ln.clock.After(fundReceiptPollInterval):
+		}
+	}
+}
+
+// Returns the tx sending [value] wei from [key] to [to]
+func signEthTransfer(
+	key *secp256k1.PrivateKey,
+	chainID *big.Int,
+	nonce uint64,
+	to ethcommon.Address,
+	value *big.Int,
+	gasPrice *big.Int,
+) (*types.Transaction, error) {
+	tx, err := types.SignTx(
+		types.NewTransaction(nonce, to, value, params.TxGas, gasPrice, nil),
+		types.LatestSignerForChainID(chainID),
+		key.ToECDSA(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't sign tx: %w", err)
+	}
+	return tx, nil
+}
@@ -0,0 +1,82 @@
+package local
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/api"
+	apimocks "github.com/ava-labs/avalanche-network-runner/api/mocks"
+	healthmocks "github.com/ava-labs/avalanche-network-runner/local/mocks/health"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/api/health"
+	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/plugin/evm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFund(t *testing.T) {
+	require := require.New(t)
+	chainID := big.NewInt(43112)
+	var sent *types.Transaction
+	newAPIClient := func(string, uint16) api.Client {
+		healthClient := &healthmocks.Client{}
+		healthClient.On("Health", mock.Anything, mock.Anything).Return(&health.APIReply{Healthy: true}, nil)
+		ethClient := &apimocks.EthClient{}
+		ethClient.On("Close").Return()
+		ethClient.On("ChainID", mock.Anything).Return(chainID, nil)
+		ethClient.On("AcceptedNonceAt", mock.Anything, evm.GetEthAddress(genesis.EWOQKey)).Return(uint64(5), nil)
+		ethClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(25_000_000_000), nil)
+		ethClient.On("SendTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			sent = args.Get(1).(*types.Transaction)
+		}).Return(nil)
+		ethClient.On("TransactionReceipt", mock.Anything, mock.Anything).Return(&types.Receipt{Status: types.ReceiptStatusSuccessful}, nil)
+		client := &apimocks.Client{}
+		client.On("HealthAPI").Return(healthClient)
+		client.On("CChainEthAPI").Return(ethClient)
+		return client
+	}
+	net, err := newNetwork(logging.NoLog{}, newAPIClient, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+
+	to := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	txID, err := net.Fund(context.Background(), to.Hex(), 3, network.CChain)
+	require.NoError(err)
+	require.NotNil(sent)
+	require.Equal(ids.ID(sent.Hash()), txID)
+	require.Equal(to, *sent.To())
+	require.Equal(big.NewInt(3_000_000_000), sent.Value())
+	require.Equal(uint64(5), sent.Nonce())
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), sent)
+	require.NoError(err)
+	require.Equal(evm.GetEthAddress(genesis.EWOQKey), sender)
+
+	_, err = net.Fund(context.Background(), to.Hex(), 0, network.CChain)
+	require.ErrorIs(err, errZeroFundAmount)
+	_, err = net.Fund(context.Background(), "not an address", 1, network.CChain)
+	require.ErrorContains(err, "invalid C-Chain address")
+	_, err = net.Fund(context.Background(), "X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p", 1, network.PChain)
+	require.ErrorContains(err, "is not a P-Chain address")
+	_, err = net.Fund(context.Background(), to.Hex(), 1, "D")
+	require.ErrorContains(err, "unknown chain")
+
+	require.NoError(net.Stop(context.Background()))
+	_, err = net.Fund(context.Background(), to.Hex(), 1, network.CChain)
+	require.ErrorIs(err, network.ErrStopped)
+}
+
+func TestParseFundAddress(t *testing.T) {
+	require := require.New(t)
+	addr, err := parseFundAddress("X-custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p", network.XChain)
+	require.NoError(err)
+	require.Equal(genesis.EWOQKey.Address(), addr)
+	_, err = parseFundAddress("custom18jma8ppw3nhx5r4ap8clazz0dps7rv5u9xde7p", network.XChain)
+	require.ErrorContains(err, "invalid X-Chain address")
+}
@@ -0,0 +1,161 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+)
+
+var errNoAlarmCallback = errors.New("health watch alarm callback not given")
+
+// See network.Network
+// The network lock is only held to get the nodes to check, so that
+// other operations aren't blocked while watching.
+func (ln *localNetwork) WatchHealth(ctx context.Context, config network.HealthWatchConfig) error {
+	if config.OnAlarm == nil {
+		return errNoAlarmCallback
+	}
+	interval := config.Interval
+	if interval == 0 {
+		interval = network.DefaultHealthWatchInterval
+	}
+	ln.log.Info("watching network health",
+		zap.Duration("interval", interval),
+		zap.Duration("debounce", config.Debounce),
+		zap.Int("min-peers", config.MinPeers),
+	)
+	// node name --> when it was first seen degraded, for degraded nodes
+	degradedSince := map[string]time.Time{}
+	// names of the degraded nodes OnAlarm was called for
+	alarmed := map[string]bool{}
+	for first := true; ; first = false {
+		ln.lock.RLock()
+		if ln.stopCalled() {
+			ln.lock.RUnlock()
+			if first {
+				return network.ErrStopped
+			}
+			return nil
+		}
+		nodes, _ := ln.splitMaintenanceNodes()
+		ln.lock.RUnlock()
+
+		nodeErrs := ln.checkNodesDegraded(ctx, nodes, config.MinPeers)
+		if ctx.Err() != nil {
+			// checks may have been cut short, don't report them
+			return nil
+		}
+		watched := map[string]*localNode{}
+		for _, node := range nodes {
+			watched[node.name] = node
+		}
+		for nodeName := range degradedSince {
+			if watched[nodeName] == nil {
+				// paused or removed
+				delete(degradedSince, nodeName)
+				delete(alarmed, nodeName)
+			}
+		}
+		now := ln.clock.Now()
+		nodeNames := maps.Keys(nodeErrs)
+		slices.Sort(nodeNames)
+		for _, nodeName := range nodeNames {
+			err := nodeErrs[nodeName]
+			since, degraded := degradedSince[nodeName]
+			if err == nil {
+				if alarmed[nodeName] {
+					ln.log.Info("node recovered", zap.String("node", nodeName))
+					config.OnAlarm(network.HealthAlarm{NodeName: nodeName, Since: since})
+				}
+				delete(degradedSince, nodeName)
+				delete(alarmed, nodeName)
+				continue
+			}
+			if !degraded {
+				since = now
+				degradedSince[nodeName] = since
+			}
+			if !alarmed[nodeName] && now.Sub(since) >= config.Debounce {
+				alarmed[nodeName] = true
+				ln.log.Warn("node degraded", zap.String("node", nodeName), zap.Error(err))
+				config.OnAlarm(network.HealthAlarm{
+					NodeName: nodeName,
+					Err:      err,
+					Since:    since,
+					Crash:    watched[nodeName].CrashReport(),
+				})
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ln.onStopCh:
+			return nil
+		case <-ln.clock.After(interval):
+		}
+	}
+}
+
+// Checks each of [nodes] once, concurrently. Returns, for each node checked,
+// why it is degraded, or nil if it isn't. Nodes removed, paused or restarted
+// meanwhile are left out.
+// Assumes [ln.lock] is not held.
+func (ln *localNetwork) checkNodesDegraded(ctx context.Context, nodes []*localNode, minPeers int) map[string]error {
+	var (
+		lock     sync.Mutex
+		wg       sync.WaitGroup
+		nodeErrs = make(map[string]error, len(nodes))
+	)
+	for _, node := range nodes {
+		node := node
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := node.checkDegraded(ctx, minPeers)
+			if err != nil && ln.isNodeGone(node) {
+				return
+			}
+			lock.Lock()
+			nodeErrs[node.name] = err
+			lock.Unlock()
+		}()
+	}
+	wg.Wait()
+	return nodeErrs
+}
+
+// Returns why the node is degraded, or nil if it isn't.
+func (node *localNode) checkDegraded(ctx context.Context, minPeers int) error {
+	if node.Status() != status.Running {
+		return errNodeStopped
+	}
+	if !node.isHealthy(ctx) {
+		return network.ErrNodeUnhealthy
+	}
+	if minPeers == 0 {
+		return nil
+	}
+	if !node.beginCall() {
+		return errNodeStopped
+	}
+	defer node.endCall()
+	ctx, cancel := context.WithTimeout(ctx, node.network.healthRequestTimeout)
+	defer cancel()
+	peers, err := node.client.InfoAPI().Peers(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't get peers: %w", err)
+	}
+	if len(peers) < minPeers {
+		return fmt.Errorf("%w: %d of %d", network.ErrNotEnoughPeers, len(peers), minPeers)
+	}
+	return nil
+}
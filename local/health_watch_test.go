@@ -0,0 +1,252 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/api"
+	apimocks "github.com/ava-labs/avalanche-network-runner/api/mocks"
+	healthmocks "github.com/ava-labs/avalanche-network-runner/local/mocks/health"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/api/health"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// peersInfoClient is an info.Client whose Peers method returns [numPeers]
+// peers. Its other methods must not be called.
+type peersInfoClient struct {
+	info.Client
+	numPeers int
+}
+
+func (c peersInfoClient) Peers(context.Context, []ids.NodeID, ...rpc.Option) ([]info.Peer, error) {
+	return make([]info.Peer, c.numPeers), nil
+}
+
+func TestWatchHealth(t *testing.T) {
+	require := require.New(t)
+	// API port --> whether the node is reported unhealthy
+	var unhealthyPorts sync.Map
+	newAPIClient := func(_ string, port uint16) api.Client {
+		healthClient := &healthmocks.Client{}
+		healthClient.On("Health", mock.Anything, mock.Anything).Return(
+			func(context.Context, []string, ...rpc.Option) *health.APIReply {
+				_, unhealthy := unhealthyPorts.Load(port)
+				return &health.APIReply{Healthy: !unhealthy}
+			},
+			nil,
+		)
+		ethClient := &apimocks.EthClient{}
+		ethClient.On("Close").Return()
+		client := &apimocks.Client{}
+		client.On("HealthAPI").Return(healthClient)
+		client.On("InfoAPI").Return(peersInfoClient{numPeers: 2})
+		client.On("CChainEthAPI").Return(ethClient)
+		return client
+	}
+	net, err := newNetwork(
+		logging.NoLog{},
+		newAPIClient,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	clock := newFakeClock()
+	net.clock = clock
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	require.NoError(net.Healthy(context.Background()))
+
+	require.ErrorIs(net.WatchHealth(context.Background(), network.HealthWatchConfig{}), errNoAlarmCallback)
+
+	// the node is reported once it has been unhealthy for the debounce
+	// period, and again once it recovers
+	unhealthyPorts.Store(net.nodes["node1"].GetAPIPort(), struct{}{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	start := clock.Now()
+	var alarms []network.HealthAlarm
+	var alarmTimes []time.Time
+	require.NoError(net.WatchHealth(ctx, network.HealthWatchConfig{
+		Interval: time.Second,
+		Debounce: 3 * time.Second,
+		OnAlarm: func(alarm network.HealthAlarm) {
+			alarms = append(alarms, alarm)
+			alarmTimes = append(alarmTimes, clock.Now())
+			if alarm.Err != nil {
+				unhealthyPorts.Delete(net.nodes["node1"].GetAPIPort())
+			} else {
+				cancel()
+			}
+		},
+	}))
+	require.Len(alarms, 2)
+	require.Equal("node1", alarms[0].NodeName)
+	require.ErrorIs(alarms[0].Err, network.ErrNodeUnhealthy)
+	require.Equal(start, alarms[0].Since)
+	require.Equal(start.Add(3*time.Second), alarmTimes[0])
+	require.Equal("node1", alarms[1].NodeName)
+	require.NoError(alarms[1].Err)
+	require.Equal(start, alarms[1].Since)
+
+	// each node has 2 peers
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	alarms = nil
+	require.NoError(net.WatchHealth(ctx, network.HealthWatchConfig{
+		MinPeers: 3,
+		OnAlarm: func(alarm network.HealthAlarm) {
+			alarms = append(alarms, alarm)
+			if len(alarms) == 3 {
+				cancel()
+			}
+		},
+	}))
+	require.Len(alarms, 3)
+	for i, alarm := range alarms {
+		require.Equal(testNetworkConfig(t).NodeConfigs[i].Name, alarm.NodeName)
+		require.ErrorIs(alarm.Err, network.ErrNotEnoughPeers)
+	}
+
+	require.NoError(net.Stop(context.Background()))
+	require.ErrorIs(net.WatchHealth(context.Background(), network.HealthWatchConfig{
+		OnAlarm: func(network.HealthAlarm) {},
+	}), network.ErrStopped)
+}
+
+// beaconPeersInfoClient is an info.Client whose Peers method returns
+// the peers with [peerIDs]. Its other methods must not be called.
+type beaconPeersInfoClient struct {
+	info.Client
+	peerIDs []ids.NodeID
+}
+
+func (c beaconPeersInfoClient) Peers(context.Context, []ids.NodeID, ...rpc.Option) ([]info.Peer, error) {
+	peers := make([]info.Peer, len(c.peerIDs))
+	for i, peerID := range c.peerIDs {
+		peers[i].ID = peerID
+	}
+	return peers, nil
+}
+
+func TestHealthyBeaconNotConnected(t *testing.T) {
+	require := require.New(t)
+	// the beacon, node0, is only a peer if set
+	var beaconConnected atomic.Bool
+	var beaconID ids.NodeID
+	newAPIClient := func(string, uint16) api.Client {
+		healthClient := &healthmocks.Client{}
+		healthClient.On("Health", mock.Anything, mock.Anything).Return(&health.APIReply{Healthy: false}, nil)
+		ethClient := &apimocks.EthClient{}
+		ethClient.On("Close").Return()
+		client := &apimocks.Client{}
+		client.On("CChainEthAPI").Return(ethClient)
+		client.On("HealthAPI").Return(healthClient)
+		client.On("InfoAPI").Return(
+			func() info.Client {
+				if beaconConnected.Load() {
+					return beaconPeersInfoClient{peerIDs: []ids.NodeID{beaconID}}
+				}
+				return beaconPeersInfoClient{}
+			},
+		)
+		return client
+	}
+	net, err := newNetwork(logging.NoLog{}, newAPIClient, &localTestSuccessfulNodeProcessCreator{}, "", "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	clock := newFakeClock()
+	net.clock = clock
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	beaconID = net.nodes["node0"].GetNodeID()
+
+	// node0 is the first beacon, and has no beacons itself
+	err = net.Healthy(context.Background())
+	require.ErrorIs(err, network.ErrBeaconNotConnected)
+	var nodeErr *network.NodeError
+	require.ErrorAs(err, &nodeErr)
+	require.Contains([]string{"node1", "node2"}, nodeErr.NodeName)
+	require.ErrorContains(err, beaconID.String())
+
+	// connected to the beacon, the nodes are just not healthy yet
+	beaconConnected.Store(true)
+	ctx, cancel := clock.withTimeout(2 * beaconConnectTimeout)
+	defer cancel()
+	err = net.Healthy(ctx)
+	require.ErrorIs(err, context.Canceled)
+	require.NotErrorIs(err, network.ErrBeaconNotConnected)
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestHealthyStartupTimeout(t *testing.T) {
+	require := require.New(t)
+	var responding atomic.Bool
+	// the nodes are connected to their beacon, node0
+	var beaconID ids.NodeID
+	newAPIClient := func(string, uint16) api.Client {
+		healthClient := &healthmocks.Client{}
+		healthClient.On("Health", mock.Anything, mock.Anything).Return(
+			func(context.Context, []string, ...rpc.Option) *health.APIReply {
+				return &health.APIReply{Healthy: true}
+			},
+			func(context.Context, []string, ...rpc.Option) error {
+				if responding.Load() {
+					return nil
+				}
+				return errors.New("connection refused")
+			},
+		)
+		ethClient := &apimocks.EthClient{}
+		ethClient.On("Close").Return()
+		client := &apimocks.Client{}
+		client.On("CChainEthAPI").Return(ethClient)
+		client.On("HealthAPI").Return(healthClient)
+		client.On("InfoAPI").Return(
+			func() info.Client {
+				return beaconPeersInfoClient{peerIDs: []ids.NodeID{beaconID}}
+			},
+		)
+		return client
+	}
+	net, err := newNetwork(logging.NoLog{}, newAPIClient, &localTestSuccessfulNodeProcessCreator{}, "", "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	clock := newFakeClock()
+	net.clock = clock
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs[1].StartupTimeout = 10 * time.Second
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	beaconID = net.nodes["node0"].GetNodeID()
+
+	err = net.Healthy(context.Background())
+	require.ErrorIs(err, network.ErrStartupTimeout)
+	var nodeErr *network.NodeError
+	require.ErrorAs(err, &nodeErr)
+	require.Equal(networkConfig.NodeConfigs[1].Name, nodeErr.NodeName)
+
+	// once the node responded, only the timeout of the wait applies
+	responding.Store(true)
+	require.NoError(net.Healthy(context.Background()))
+	responding.Store(false)
+	ctx, cancel := clock.withTimeout(2 * healthCheckFreq)
+	defer cancel()
+	err = net.AwaitNodeHealthy(ctx, networkConfig.NodeConfigs[1].Name)
+	require.ErrorIs(err, context.Canceled)
+	require.NotErrorIs(err, network.ErrStartupTimeout)
+	require.NoError(net.Stop(context.Background()))
+}
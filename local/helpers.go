@@ -3,35 +3,102 @@ package local
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"math"
 	"math/rand"
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/utils"
 	"github.com/ava-labs/avalanchego/config"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	dircopy "github.com/otiai10/copy"
 )
 
 const (
 	stakingPath = "staking"
 	configsPath = "configs"
+	// when restoring nodes whose saved ports are in use, the ports
+	// are first retried shifted by this offset
+	portRemapOffset = 1000
 )
 
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-func getFreePort() (uint16, error) {
-	l, err := net.Listen("tcp", "127.0.0.1:0")
+// isFreePort returns true if [port] can be bound on localhost
+func isFreePort(port uint16) bool {
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port))))
 	if err != nil {
-		return 0, err
+		return false
 	}
-	port := uint16(l.Addr().(*net.TCPAddr).Port)
 	_ = l.Close()
-	return port, nil
+	return true
+}
+
+// seedDBDir creates [dbDir] as a copy of [seedDir], or as a symlink to it if
+// [symlink] is true, unless [dbDir] already exists, e.g. because the node was
+// already started, or restored from a snapshot.
+func seedDBDir(seedDir string, dbDir string, symlink bool) error {
+	if _, err := os.Lstat(dbDir); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	info, err := os.Stat(seedDir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("db seed %q is not a directory", seedDir)
+	}
+	if err := os.MkdirAll(filepath.Dir(dbDir), os.ModePerm); err != nil {
+		return err
+	}
+	if symlink {
+		absSeedDir, err := filepath.Abs(seedDir)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(absSeedDir, dbDir)
+	}
+	return dircopy.Copy(seedDir, dbDir)
+}
+
+// remapPorts shifts the API and P2P ports given in [flags] by [portRemapOffset],
+// so that the URIs of a restored node can be derived from the saved ones.
+// Returns false, leaving [flags] untouched, if the ports are not given in [flags],
+// or the shifted ones are out of range or not free.
+func remapPorts(flags map[string]interface{}) (bool, error) {
+	remapped := map[string]uint16{}
+	for _, portKey := range []string{config.HTTPPortKey, config.StakingPortKey} {
+		if _, ok := flags[portKey]; !ok {
+			return false, nil
+		}
+		port, err := getPort(flags, nil, portKey)
+		if err != nil {
+			return false, err
+		}
+		if port == 0 || uint32(port)+portRemapOffset > math.MaxUint16 {
+			return false, nil
+		}
+		remappedPort := port + portRemapOffset
+		if !isFreePort(remappedPort) {
+			return false, nil
+		}
+		remapped[portKey] = remappedPort
+	}
+	for portKey, port := range remapped {
+		flags[portKey] = int(port)
+	}
+	return true, nil
 }
 
 func getStakingTLSKeyPath(nodeRootDir string) string {
@@ -59,6 +126,7 @@ func getSubnetConfigDir(rootDir string) string {
 func writeFiles(
 	genesisData []byte,
 	upgradeData []byte,
+	vmAliasesData []byte,
 	nodeRootDir string,
 	nodeConfig *node.Config,
 ) (map[string]string, error) {
@@ -108,6 +176,14 @@ func writeFiles(
 			contents:  upgradeData,
 		})
 	}
+	if len(vmAliasesData) > 0 {
+		files = append(files, file{
+			flagValue: filepath.Join(nodeRootDir, configsPath, vmAliasesFileName),
+			path:      filepath.Join(nodeRootDir, configsPath, vmAliasesFileName),
+			pathKey:   config.VMAliasesFileKey,
+			contents:  vmAliasesData,
+		})
+	}
 	flags := map[string]string{}
 	for _, f := range files {
 		if f.flagValue != "" {
@@ -222,7 +298,7 @@ func getPort(
 		}
 		port = uint16(portFromConfigFile)
 	} else {
-		port, err = getFreePort()
+		port, err = utils.GetFreePort()
 		if err != nil {
 			return 0, fmt.Errorf("couldn't get free port: %w", err)
 		}
@@ -0,0 +1,106 @@
+package local
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+)
+
+// file of the root dir the operation records are appended to
+const historyFileName = "history.jsonl"
+
+// operationHistory is the append-only audit log of a network.
+// It has its own lock, so that it can be read while operations run.
+type operationHistory struct {
+	lock    sync.Mutex
+	log     logging.Logger
+	records []network.OperationRecord
+	// file the records are also appended to, as JSON lines
+	path string
+}
+
+// Appends [record] to the history, and to its file.
+// Failing to write the file is only logged.
+func (h *operationHistory) add(record network.OperationRecord) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.records = append(h.records, record)
+	recordJSON, err := json.Marshal(record)
+	if err == nil {
+		err = appendLine(h.path, recordJSON)
+	}
+	// the root dir of ephemeral networks is removed on stop
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		h.log.Warn("couldn't write operation record", zap.String("path", h.path), zap.Error(err))
+	}
+}
+
+// Returns a copy of the records
+func (h *operationHistory) get() []network.OperationRecord {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return append([]network.OperationRecord(nil), h.records...)
+}
+
+func appendLine(path string, line []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// See network.Network
+func (ln *localNetwork) History() []network.OperationRecord {
+	return ln.history.get()
+}
+
+// Returns the record of operation [op] starting now, to be
+// completed and added to the history by [ln.finishOperation]
+func (ln *localNetwork) startOperation(op string, nodeName string, params map[string]string) *network.OperationRecord {
+	return &network.OperationRecord{
+		Time:     ln.clock.Now(),
+		Op:       op,
+		NodeName: nodeName,
+		Params:   params,
+	}
+}
+
+// Adds [record] to the history, with the error [*errPtr] the operation
+// returned, unless it was rejected because the network was stopped.
+// Meant to be deferred with a pointer to the named error result.
+func (ln *localNetwork) finishOperation(record *network.OperationRecord, errPtr *error) {
+	err := *errPtr
+	if errors.Is(err, network.ErrStopped) {
+		return
+	}
+	record.Duration = ln.clock.Now().Sub(record.Time)
+	if err != nil {
+		record.Err = err.Error()
+	}
+	ln.history.add(*record)
+}
+
+// Returns [params] without the empty values, or nil if all are empty
+func nonEmptyParams(params map[string]string) map[string]string {
+	maps.DeleteFunc(params, func(_ string, v string) bool {
+		return v == ""
+	})
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
@@ -0,0 +1,80 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+	"golang.org/x/sync/errgroup"
+)
+
+// See network.Network
+// The network lock is only held to get the nodes, so that other
+// operations aren't blocked while the users are created.
+func (ln *localNetwork) CreateKeystoreUser(
+	ctx context.Context,
+	nodeNames []string,
+	user string,
+	pass string,
+	privateKeys ...*secp256k1.PrivateKey,
+) (err error) {
+	record := ln.startOperation("CreateKeystoreUser", "", nonEmptyParams(map[string]string{
+		"nodes": strings.Join(nodeNames, ","),
+		"user":  user,
+	}))
+	defer ln.finishOperation(record, &err)
+
+	allNodes := len(nodeNames) == 0
+	ln.lock.RLock()
+	if allNodes {
+		nodeNames = maps.Keys(ln.nodes)
+	}
+	nodes, err := ln.keystoreUserNodes(nodeNames)
+	ln.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+	ln.log.Info("creating keystore user",
+		zap.String("user", user),
+		zap.Strings("nodes", nodeNames),
+		zap.Int("keys", len(privateKeys)),
+	)
+	errGr, ctx := errgroup.WithContext(ctx)
+	for _, node := range nodes {
+		node := node
+		errGr.Go(func() error {
+			err := node.CreateKeystoreUser(ctx, user, pass, privateKeys...)
+			if allNodes && errors.Is(err, errNodeDrained) {
+				// being removed, as if it was not in the network
+				return nil
+			}
+			return err
+		})
+	}
+	return errGr.Wait()
+}
+
+// Returns the nodes named [nodeNames], failing if any of them
+// is not in the network or paused.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) keystoreUserNodes(nodeNames []string) ([]*localNode, error) {
+	if ln.stopCalled() {
+		return nil, network.ErrStopped
+	}
+	nodes := make([]*localNode, 0, len(nodeNames))
+	for _, nodeName := range nodeNames {
+		node, ok := ln.nodes[nodeName]
+		if !ok {
+			return nil, &network.NodeError{NodeName: nodeName, Op: "create keystore user on", Err: network.ErrNodeNotFound}
+		}
+		if node.paused {
+			return nil, &network.NodeError{NodeName: nodeName, Op: "create keystore user on", Err: errNodePaused}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
@@ -0,0 +1,504 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/set"
+	gopsnet "github.com/shirou/gopsutil/net"
+	"go.uber.org/zap"
+)
+
+const (
+	// time between syncs of the shaped connections with the ones of the nodes
+	linkShapingInterval = time.Second
+	// interface the connections between the nodes go through
+	loopbackInterface = "lo"
+	// handle of the HTB qdisc the links are shaped by
+	linkShapingQdiscHandle = "1:"
+)
+
+var (
+	errLinkBandwidthNotSupported = errors.New("link bandwidth limits are only supported on linux")
+	errSameNodeLink              = errors.New("a link needs two distinct nodes")
+	errNoFreeLinkShapingID       = errors.New("no free tc class or filter ID left")
+	errForeignRootQdisc          = errors.New("the loopback interface has a root qdisc other than the HTB one links are shaped by")
+
+	// tc class and filter IDs in use, shared by the networks of the
+	// process, as the qdisc of the loopback interface is
+	linkShapingIDs = struct {
+		lock sync.Mutex
+		used set.Set[uint16]
+	}{used: set.Set[uint16]{}}
+
+	// link shapers of the process using the qdisc of the loopback interface,
+	// so that the last one removes it, if the process added it
+	linkShapingQdisc struct {
+		lock  sync.Mutex
+		users int
+		added bool
+	}
+)
+
+// nodeLink is one direction of the link between two nodes
+type nodeLink struct {
+	from string
+	to   string
+}
+
+// flow is one direction of a TCP connection
+type flow struct {
+	src netip.AddrPort
+	dst netip.AddrPort
+}
+
+// See network.Network
+func (ln *localNetwork) SetLinkBandwidth(ctx context.Context, nodeName1 string, nodeName2 string, bitsPerSec uint64) (err error) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+	record := ln.startOperation("SetLinkBandwidth", nodeName1, map[string]string{
+		"peer":       nodeName2,
+		"bitsPerSec": strconv.FormatUint(bitsPerSec, 10),
+	})
+	defer ln.finishOperation(record, &err)
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	if nodeName1 == nodeName2 {
+		return &network.NodeError{NodeName: nodeName1, Op: "set link bandwidth of", Err: errSameNodeLink}
+	}
+	for _, nodeName := range []string{nodeName1, nodeName2} {
+		if _, ok := ln.nodes[nodeName]; !ok {
+			return &network.NodeError{NodeName: nodeName, Op: "set link bandwidth of", Err: network.ErrNodeNotFound}
+		}
+	}
+	if err := ln.setLinkBandwidth(ctx, nodeName1, nodeName2, bitsPerSec); err != nil {
+		return &network.NodeError{NodeName: nodeName1, Op: "set link bandwidth of", Err: err}
+	}
+	return nil
+}
+
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) setLinkBandwidth(ctx context.Context, nodeName1 string, nodeName2 string, bitsPerSec uint64) error {
+	if ln.linkShaper == nil {
+		if bitsPerSec == 0 {
+			return nil
+		}
+		shaper, err := newLinkShaper(ctx)
+		if err != nil {
+			return err
+		}
+		ln.linkShaper = shaper
+		go ln.shapeLinks()
+	}
+	for _, link := range []nodeLink{{from: nodeName1, to: nodeName2}, {from: nodeName2, to: nodeName1}} {
+		if bitsPerSec == 0 {
+			delete(ln.linkBandwidths, link)
+		} else {
+			ln.linkBandwidths[link] = bitsPerSec
+		}
+	}
+	if err := ln.linkShaper.sync(ctx, ln.getNodePIDs(), ln.linkBandwidths); err != nil {
+		return err
+	}
+	ln.log.Info("set link bandwidth",
+		zap.String("node", nodeName1),
+		zap.String("peer", nodeName2),
+		zap.Uint64("bits-per-sec", bitsPerSec),
+	)
+	return nil
+}
+
+// Removes the bandwidth limits of the links of the node [nodeName],
+// once it is removed. They are removed from the connections on the next sync.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) removeNodeLinks(nodeName string) {
+	for link := range ln.linkBandwidths {
+		if link.from == nodeName || link.to == nodeName {
+			delete(ln.linkBandwidths, link)
+		}
+	}
+}
+
+// Returns the ID of the OS process of each running node, by node name
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) getNodePIDs() map[string]int {
+	pids := map[string]int{}
+	for nodeName, node := range ln.nodes {
+		if pid := node.PID(); pid != 0 {
+			pids[nodeName] = pid
+		}
+	}
+	return pids
+}
+
+// Shapes the connections established between the nodes since the last sync,
+// until the network is stopped
+func (ln *localNetwork) shapeLinks() {
+	for {
+		select {
+		case <-ln.onStopCh:
+			return
+		case <-ln.clock.After(linkShapingInterval):
+		}
+		ln.lock.RLock()
+		if ln.stopCalled() {
+			ln.lock.RUnlock()
+			return
+		}
+		pids := ln.getNodePIDs()
+		linkBandwidths := make(map[nodeLink]uint64, len(ln.linkBandwidths))
+		for link, bitsPerSec := range ln.linkBandwidths {
+			linkBandwidths[link] = bitsPerSec
+		}
+		ln.lock.RUnlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), linkShapingInterval)
+		if err := ln.linkShaper.sync(ctx, pids, linkBandwidths); err != nil {
+			ln.log.Warn("couldn't shape node links", zap.Error(err))
+		}
+		cancel()
+	}
+}
+
+// linkShaper limits the bandwidth of the links between nodes on the loopback
+// interface, with an HTB class per link direction, and a u32 filter per
+// connection direction between the node processes, as the nodes all dial
+// from the same address, so that the ends of a connection can only be told
+// apart by their ports.
+type linkShaper struct {
+	lock sync.Mutex
+	// runs tc with [args]
+	runTC func(ctx context.Context, args ...string) error
+	// returns the TCP connections of the process [pid]
+	getConns func(ctx context.Context, pid int32) ([]gopsnet.ConnectionStat, error)
+	// link direction --> its class
+	classes map[nodeLink]linkClass
+	// connection direction --> its filter
+	filters map[flow]linkFilter
+	// set if the qdisc of the loopback interface was acquired by the
+	// shaper, to be released on close
+	qdiscAcquired bool
+	// set once the classes and filters are removed
+	closed bool
+}
+
+type linkClass struct {
+	id         uint16
+	bitsPerSec uint64
+}
+
+type linkFilter struct {
+	// filter preference, unique to the filter, so that it can be removed
+	pref  uint16
+	class uint16
+}
+
+// Returns a link shaper, adding the HTB qdisc to the loopback interface
+// unless it is already there, e.g. added for another network. The qdisc
+// leaves the traffic not matching any filter unshaped, and is removed
+// once the last shaper of the process is closed, if the process added it.
+func newLinkShaper(ctx context.Context) (*linkShaper, error) {
+	if err := checkLinkShaping(); err != nil {
+		return nil, err
+	}
+	if err := acquireLinkShapingQdisc(ctx, tcOutput); err != nil {
+		return nil, err
+	}
+	return &linkShaper{
+		runTC:         runTC,
+		getConns:      getConns,
+		classes:       map[nodeLink]linkClass{},
+		filters:       map[flow]linkFilter{},
+		qdiscAcquired: true,
+	}, nil
+}
+
+// Adds a user to the HTB qdisc of the loopback interface, adding the qdisc
+// with [tc] unless it is already there, e.g. added by another process, that
+// then removes it. Fails if the interface has another root qdisc, as links
+// can't be shaped without replacing it.
+func acquireLinkShapingQdisc(ctx context.Context, tc func(context.Context, ...string) (string, error)) error {
+	linkShapingQdisc.lock.Lock()
+	defer linkShapingQdisc.lock.Unlock()
+
+	if linkShapingQdisc.users > 0 {
+		linkShapingQdisc.users++
+		return nil
+	}
+	output, err := tc(ctx, "qdisc", "show", "dev", loopbackInterface)
+	if err != nil {
+		return fmt.Errorf("couldn't get qdiscs: %w", err)
+	}
+	kind, handle := parseRootQdisc(output)
+	switch {
+	case kind == "htb" && handle == linkShapingQdiscHandle:
+		// added by another process, e.g. running another network
+	case handle == "" || handle == "0:":
+		// the default qdisc of the interface
+		if _, err := tc(ctx, "qdisc", "add", "dev", loopbackInterface, "root", "handle", linkShapingQdiscHandle, "htb"); err != nil {
+			return fmt.Errorf("couldn't add qdisc: %w", err)
+		}
+		linkShapingQdisc.added = true
+	default:
+		return fmt.Errorf("%w: %s %s", errForeignRootQdisc, kind, handle)
+	}
+	linkShapingQdisc.users++
+	return nil
+}
+
+// Removes a user from the HTB qdisc of the loopback interface, removing
+// the qdisc with [tc] if it was the last one and the process added it
+func releaseLinkShapingQdisc(ctx context.Context, tc func(context.Context, ...string) (string, error)) error {
+	linkShapingQdisc.lock.Lock()
+	defer linkShapingQdisc.lock.Unlock()
+
+	linkShapingQdisc.users--
+	if linkShapingQdisc.users > 0 || !linkShapingQdisc.added {
+		return nil
+	}
+	linkShapingQdisc.added = false
+	if _, err := tc(ctx, "qdisc", "del", "dev", loopbackInterface, "root"); err != nil {
+		return fmt.Errorf("couldn't remove qdisc: %w", err)
+	}
+	return nil
+}
+
+// Returns the kind and handle of the root qdisc listed on [output],
+// the output of tc qdisc show, or empty strings if there is none
+func parseRootQdisc(output string) (string, string) {
+	for _, line := range strings.Split(output, "\n") {
+		// e.g. qdisc htb 1: root refcnt 2 r2q 10 default 0
+		fields := strings.Fields(line)
+		if len(fields) >= 4 && fields[0] == "qdisc" && fields[3] == "root" {
+			return fields[1], fields[2]
+		}
+	}
+	return "", ""
+}
+
+// Runs tc with [args], returning its output on errors
+func runTC(ctx context.Context, args ...string) error {
+	_, err := tcOutput(ctx, args...)
+	return err
+}
+
+// Runs tc with [args], returning its output
+func tcOutput(ctx context.Context, args ...string) (string, error) {
+	output, err := exec.CommandContext(ctx, "tc", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("tc %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+func getConns(ctx context.Context, pid int32) ([]gopsnet.ConnectionStat, error) {
+	return gopsnet.ConnectionsPidWithContext(ctx, "tcp4", pid)
+}
+
+// Shapes the connections between the node processes [pids], by node name,
+// with the limits [linkBandwidths], by link direction, removing the classes
+// and filters no longer needed
+func (s *linkShaper) sync(ctx context.Context, pids map[string]int, linkBandwidths map[nodeLink]uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	// local end of a connection --> the node it belongs to
+	owners := map[netip.AddrPort]string{}
+	nodeFlows := map[string][]flow{}
+	for nodeName, pid := range pids {
+		conns, err := s.getConns(ctx, int32(pid))
+		if err != nil {
+			return fmt.Errorf("couldn't get connections of node %q: %w", nodeName, err)
+		}
+		for _, conn := range conns {
+			if conn.Status != "ESTABLISHED" {
+				continue
+			}
+			src, err := parseConnAddr(conn.Laddr)
+			if err != nil {
+				continue
+			}
+			dst, err := parseConnAddr(conn.Raddr)
+			if err != nil {
+				continue
+			}
+			owners[src] = nodeName
+			nodeFlows[nodeName] = append(nodeFlows[nodeName], flow{src: src, dst: dst})
+		}
+	}
+
+	for link, bitsPerSec := range linkBandwidths {
+		class, ok := s.classes[link]
+		if ok && class.bitsPerSec == bitsPerSec {
+			continue
+		}
+		if !ok {
+			id, err := allocLinkShapingID()
+			if err != nil {
+				return err
+			}
+			class.id = id
+		}
+		if err := s.runTC(ctx, "class", "replace", "dev", loopbackInterface, "parent", linkShapingQdiscHandle,
+			"classid", linkClassID(class.id), "htb", "rate", strconv.FormatUint(bitsPerSec, 10)+"bit",
+		); err != nil {
+			if !ok {
+				freeLinkShapingID(class.id)
+			}
+			return fmt.Errorf("couldn't set class of link %s->%s: %w", link.from, link.to, err)
+		}
+		class.bitsPerSec = bitsPerSec
+		s.classes[link] = class
+	}
+
+	// connection direction --> the class shaping it
+	wanted := map[flow]uint16{}
+	for nodeName, flows := range nodeFlows {
+		for _, f := range flows {
+			peerName, ok := owners[f.dst]
+			if !ok {
+				continue
+			}
+			link := nodeLink{from: nodeName, to: peerName}
+			if linkBandwidths[link] != 0 {
+				wanted[f] = s.classes[link].id
+			}
+		}
+	}
+	for f, filter := range s.filters {
+		if classID, ok := wanted[f]; ok && classID == filter.class {
+			continue
+		}
+		if err := s.removeFilter(ctx, f); err != nil {
+			return err
+		}
+	}
+	for f, classID := range wanted {
+		if _, ok := s.filters[f]; ok {
+			continue
+		}
+		pref, err := allocLinkShapingID()
+		if err != nil {
+			return err
+		}
+		if err := s.runTC(ctx, "filter", "add", "dev", loopbackInterface, "parent", linkShapingQdiscHandle,
+			"protocol", "ip", "prio", strconv.Itoa(int(pref)), "u32",
+			"match", "ip", "src", f.src.Addr().String()+"/32",
+			"match", "ip", "dst", f.dst.Addr().String()+"/32",
+			"match", "ip", "sport", strconv.Itoa(int(f.src.Port())), "0xffff",
+			"match", "ip", "dport", strconv.Itoa(int(f.dst.Port())), "0xffff",
+			"flowid", linkClassID(classID),
+		); err != nil {
+			freeLinkShapingID(pref)
+			return fmt.Errorf("couldn't add filter of connection %s->%s: %w", f.src, f.dst, err)
+		}
+		s.filters[f] = linkFilter{pref: pref, class: classID}
+	}
+
+	for link, class := range s.classes {
+		if linkBandwidths[link] != 0 {
+			continue
+		}
+		if err := s.removeClass(ctx, link, class); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Removes the classes and filters of the links, so that they are unshaped,
+// and releases the qdisc of the loopback interface
+func (s *linkShaper) close(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	for f := range s.filters {
+		if err := s.removeFilter(ctx, f); err != nil {
+			return err
+		}
+	}
+	for link, class := range s.classes {
+		if err := s.removeClass(ctx, link, class); err != nil {
+			return err
+		}
+	}
+	s.closed = true
+	if s.qdiscAcquired {
+		return releaseLinkShapingQdisc(ctx, tcOutput)
+	}
+	return nil
+}
+
+// Assumes [s.lock] is held.
+func (s *linkShaper) removeFilter(ctx context.Context, f flow) error {
+	filter := s.filters[f]
+	if err := s.runTC(ctx, "filter", "del", "dev", loopbackInterface, "parent", linkShapingQdiscHandle,
+		"protocol", "ip", "prio", strconv.Itoa(int(filter.pref)),
+	); err != nil {
+		return fmt.Errorf("couldn't remove filter of connection %s->%s: %w", f.src, f.dst, err)
+	}
+	freeLinkShapingID(filter.pref)
+	delete(s.filters, f)
+	return nil
+}
+
+// Assumes [s.lock] is held, and the filters of [class] are removed.
+func (s *linkShaper) removeClass(ctx context.Context, link nodeLink, class linkClass) error {
+	if err := s.runTC(ctx, "class", "del", "dev", loopbackInterface, "classid", linkClassID(class.id)); err != nil {
+		return fmt.Errorf("couldn't remove class of link %s->%s: %w", link.from, link.to, err)
+	}
+	freeLinkShapingID(class.id)
+	delete(s.classes, link)
+	return nil
+}
+
+// Returns the tc class ID of the class with minor [id] on the qdisc
+func linkClassID(id uint16) string {
+	return linkShapingQdiscHandle + strconv.FormatUint(uint64(id), 16)
+}
+
+func parseConnAddr(addr gopsnet.Addr) (netip.AddrPort, error) {
+	ip, err := netip.ParseAddr(addr.IP)
+	if err != nil {
+		return netip.AddrPort{}, err
+	}
+	return netip.AddrPortFrom(ip, uint16(addr.Port)), nil
+}
+
+// Returns a tc class ID or filter preference not used by the process
+func allocLinkShapingID() (uint16, error) {
+	linkShapingIDs.lock.Lock()
+	defer linkShapingIDs.lock.Unlock()
+
+	// 1:0 is the qdisc itself
+	for id := uint16(1); id != 0; id++ {
+		if !linkShapingIDs.used.Contains(id) {
+			linkShapingIDs.used.Add(id)
+			return id, nil
+		}
+	}
+	return 0, errNoFreeLinkShapingID
+}
+
+func freeLinkShapingID(id uint16) {
+	linkShapingIDs.lock.Lock()
+	defer linkShapingIDs.lock.Unlock()
+
+	linkShapingIDs.used.Remove(id)
+}
@@ -0,0 +1,16 @@
+//go:build linux
+
+package local
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// Returns an error if the links between nodes can't be shaped
+func checkLinkShaping() error {
+	if _, err := exec.LookPath("tc"); err != nil {
+		return fmt.Errorf("couldn't find tc: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package local
+
+func checkLinkShaping() error {
+	return errLinkBandwidthNotSupported
+}
@@ -0,0 +1,211 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	gopsnet "github.com/shirou/gopsutil/net"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns a link shaper running the tc commands on [tc], and getting
+// the connections of the processes from [conns], by pid
+func newTestLinkShaper(tc func(args ...string) error, conns map[int32][]gopsnet.ConnectionStat) *linkShaper {
+	var lock sync.Mutex
+	return &linkShaper{
+		runTC: func(_ context.Context, args ...string) error {
+			lock.Lock()
+			defer lock.Unlock()
+			return tc(args...)
+		},
+		getConns: func(_ context.Context, pid int32) ([]gopsnet.ConnectionStat, error) {
+			return conns[pid], nil
+		},
+		classes: map[nodeLink]linkClass{},
+		filters: map[flow]linkFilter{},
+	}
+}
+
+func testConn(laddr string, lport uint32, raddr string, rport uint32) gopsnet.ConnectionStat {
+	return gopsnet.ConnectionStat{
+		Laddr:  gopsnet.Addr{IP: laddr, Port: lport},
+		Raddr:  gopsnet.Addr{IP: raddr, Port: rport},
+		Status: "ESTABLISHED",
+	}
+}
+
+func TestLinkShaper(t *testing.T) {
+	require := require.New(t)
+	commands := []string{}
+	var tcErr error
+	conns := map[int32][]gopsnet.ConnectionStat{
+		// node1 dialed node2, node3 dialed node1
+		1: {
+			testConn("127.0.0.1", 40000, "127.0.0.1", 9653),
+			testConn("127.0.0.1", 9651, "127.0.0.1", 40001),
+			// not to a node
+			testConn("127.0.0.1", 40002, "127.0.0.1", 8080),
+			{Laddr: gopsnet.Addr{IP: "127.0.0.1", Port: 9651}, Status: "LISTEN"},
+		},
+		2: {testConn("127.0.0.1", 9653, "127.0.0.1", 40000)},
+		3: {testConn("127.0.0.1", 40001, "127.0.0.1", 9651)},
+	}
+	s := newTestLinkShaper(func(args ...string) error {
+		if tcErr != nil {
+			return tcErr
+		}
+		commands = append(commands, strings.Join(args[:2], " "))
+		return nil
+	}, conns)
+	pids := map[string]int{"node1": 1, "node2": 2, "node3": 3}
+	node1To2 := nodeLink{from: "node1", to: "node2"}
+	node2To1 := nodeLink{from: "node2", to: "node1"}
+	limits := map[nodeLink]uint64{node1To2: 1_000_000, node2To1: 1_000_000}
+
+	require.NoError(s.sync(context.Background(), pids, limits))
+	require.Equal([]string{"class replace", "class replace", "filter add", "filter add"}, commands)
+	require.Len(s.classes, 2)
+	require.NotEqual(s.classes[node1To2].id, s.classes[node2To1].id)
+	// only the connection between node1 and node2 is shaped, each way by its link
+	require.Len(s.filters, 2)
+	for f, filter := range s.filters {
+		switch f.src.Port() {
+		case 40000:
+			require.Equal(s.classes[node1To2].id, filter.class)
+		case 9653:
+			require.Equal(s.classes[node2To1].id, filter.class)
+		default:
+			require.FailNow("unexpected filter", f)
+		}
+	}
+
+	// nothing changed
+	commands = commands[:0]
+	require.NoError(s.sync(context.Background(), pids, limits))
+	require.Empty(commands)
+
+	// the rate of a direction is changed
+	limits[node2To1] = 2_000_000
+	require.NoError(s.sync(context.Background(), pids, limits))
+	require.Equal([]string{"class replace"}, commands)
+	require.Equal(uint64(2_000_000), s.classes[node2To1].bitsPerSec)
+
+	// tc errors are returned, and the state is kept
+	commands = commands[:0]
+	tcErr = errors.New("tc failed")
+	require.ErrorIs(s.sync(context.Background(), pids, map[nodeLink]uint64{}), tcErr)
+	require.Len(s.filters, 2)
+	tcErr = nil
+
+	// the connections of stopped nodes are no longer shaped
+	require.NoError(s.sync(context.Background(), map[string]int{"node1": 1, "node3": 3}, limits))
+	require.Equal([]string{"filter del", "filter del"}, commands)
+	require.Empty(s.filters)
+	require.Len(s.classes, 2)
+
+	// the limits are removed
+	commands = commands[:0]
+	require.NoError(s.sync(context.Background(), pids, limits))
+	require.NoError(s.sync(context.Background(), pids, map[nodeLink]uint64{}))
+	require.Equal([]string{"filter add", "filter add", "filter del", "filter del", "class del", "class del"}, commands)
+	require.Empty(s.filters)
+	require.Empty(s.classes)
+
+	commands = commands[:0]
+	require.NoError(s.sync(context.Background(), pids, limits))
+	require.NoError(s.close(context.Background()))
+	require.Empty(s.filters)
+	require.Empty(s.classes)
+	// closed shapers don't shape
+	commands = commands[:0]
+	require.NoError(s.sync(context.Background(), pids, limits))
+	require.Empty(commands)
+}
+
+func TestLinkShapingQdisc(t *testing.T) {
+	require := require.New(t)
+	rootQdisc := "qdisc noqueue 0: root refcnt 2\n"
+	commands := []string{}
+	tc := func(_ context.Context, args ...string) (string, error) {
+		commands = append(commands, strings.Join(args[:2], " "))
+		if args[1] == "show" {
+			return rootQdisc, nil
+		}
+		return "", nil
+	}
+
+	// added in place of the default qdisc, and removed by its last user
+	require.NoError(acquireLinkShapingQdisc(context.Background(), tc))
+	require.NoError(acquireLinkShapingQdisc(context.Background(), tc))
+	require.Equal([]string{"qdisc show", "qdisc add"}, commands)
+	require.NoError(releaseLinkShapingQdisc(context.Background(), tc))
+	require.Equal([]string{"qdisc show", "qdisc add"}, commands)
+	require.NoError(releaseLinkShapingQdisc(context.Background(), tc))
+	require.Equal([]string{"qdisc show", "qdisc add", "qdisc del"}, commands)
+
+	// the one added by another process is used, but not removed
+	commands = commands[:0]
+	rootQdisc = "qdisc htb 1: root refcnt 2 r2q 10 default 0 direct_packets_stat 0\n"
+	require.NoError(acquireLinkShapingQdisc(context.Background(), tc))
+	require.NoError(releaseLinkShapingQdisc(context.Background(), tc))
+	require.Equal([]string{"qdisc show"}, commands)
+
+	// other root qdiscs are not replaced
+	commands = commands[:0]
+	rootQdisc = "qdisc netem 8001: root refcnt 2 limit 1000 delay 100ms\n"
+	require.ErrorIs(acquireLinkShapingQdisc(context.Background(), tc), errForeignRootQdisc)
+	require.Equal([]string{"qdisc show"}, commands)
+}
+
+func TestSetLinkBandwidth(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+
+	err = net.SetLinkBandwidth(context.Background(), "node0", "node0", 1)
+	require.ErrorIs(err, errSameNodeLink)
+	err = net.SetLinkBandwidth(context.Background(), "node0", "missing", 1)
+	require.ErrorIs(err, network.ErrNodeNotFound)
+	// nothing to remove
+	require.NoError(net.SetLinkBandwidth(context.Background(), "node0", "node1", 0))
+	require.Nil(net.linkShaper)
+
+	tcCommands := 0
+	net.linkShaper = newTestLinkShaper(func(...string) error {
+		tcCommands++
+		return nil
+	}, nil)
+	require.NoError(net.SetLinkBandwidth(context.Background(), "node0", "node1", 1_000_000))
+	require.NoError(net.SetLinkBandwidth(context.Background(), "node2", "node1", 2_000_000))
+	require.Equal(map[nodeLink]uint64{
+		{from: "node0", to: "node1"}: 1_000_000,
+		{from: "node1", to: "node0"}: 1_000_000,
+		{from: "node1", to: "node2"}: 2_000_000,
+		{from: "node2", to: "node1"}: 2_000_000,
+	}, net.linkBandwidths)
+	// a class per link direction
+	require.Equal(4, tcCommands)
+
+	// the limits are kept on restart, and removed with the node
+	require.NoError(net.RestartNode(context.Background(), "node2", "", "", "", nil, nil, nil))
+	require.Len(net.linkBandwidths, 4)
+	require.NoError(net.RemoveNode(context.Background(), "node0"))
+	require.Equal(map[nodeLink]uint64{
+		{from: "node1", to: "node2"}: 2_000_000,
+		{from: "node2", to: "node1"}: 2_000_000,
+	}, net.linkBandwidths)
+
+	// the classes are removed on stop
+	require.NoError(net.Stop(context.Background()))
+	require.True(net.linkShaper.closed)
+	require.Empty(net.linkShaper.classes)
+	require.ErrorIs(net.SetLinkBandwidth(context.Background(), "node1", "node2", 0), network.ErrStopped)
+}
@@ -0,0 +1,50 @@
+package local
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var _ logStamperSetter = (*nodeProcessCreator)(nil)
+
+// logStamperSetter is implemented by the node process creators that can
+// prefix the output lines they capture with the ones of a logStamper
+type logStamperSetter interface {
+	// Sets the stamper of the processes created from now on, nil for none
+	setLogStamper(stamper *logStamper)
+}
+
+// logStamper prefixes the output lines of the node processes of a network
+// with a sequence number shared by all of them, and the time elapsed since
+// the stamper was created, see network.Config.LogTimestamps. The lines are
+// numbered and timed under the same lock, so that both are in the order in
+// which the runner received the lines.
+type logStamper struct {
+	lock  sync.Mutex
+	clock clock
+	// read from [clock], so that, on the real clock, the elapsed
+	// time is given by the monotonic clock
+	start time.Time
+	// sequence number of the last line stamped
+	seq uint64
+}
+
+func newLogStamper(clock clock) *logStamper {
+	return &logStamper{clock: clock, start: clock.Now()}
+}
+
+// Returns [line] prefixed with its sequence number and time, e.g.
+// "#42 +1.503021s <line>"
+func (s *logStamper) stamp(line []byte) []byte {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.seq++
+	elapsed := s.clock.Now().Sub(s.start)
+	return append(fmt.Appendf(nil, "#%d +%.6fs ", s.seq, elapsed.Seconds()), line...)
+}
+
+func (npc *nodeProcessCreator) setLogStamper(stamper *logStamper) {
+	npc.logStamper = stamper
+}
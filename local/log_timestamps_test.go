@@ -0,0 +1,73 @@
+package local
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _ io.WriteCloser = (*bufferWriteCloser)(nil)
+
+// bufferWriteCloser is a buffer that can be given as the output of a capture
+type bufferWriteCloser struct {
+	lock sync.Mutex
+	buf  strings.Builder
+}
+
+func (b *bufferWriteCloser) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (*bufferWriteCloser) Close() error {
+	return nil
+}
+
+func (b *bufferWriteCloser) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func TestLogStamper(t *testing.T) {
+	require := require.New(t)
+	clock := newFakeClock()
+	stamper := newLogStamper(clock)
+	require.Equal("#1 +0.000000s line 1\n", string(stamper.stamp([]byte("line 1\n"))))
+	clock.advance(1503021 * time.Microsecond)
+	require.Equal("#2 +1.503021s line 2", string(stamper.stamp([]byte("line 2"))))
+}
+
+// TestLogTimestampsCapture checks that the lines of the nodes sharing a
+// stamper are numbered in the order they are received, once terminated
+func TestLogTimestampsCapture(t *testing.T) {
+	require := require.New(t)
+	clock := newFakeClock()
+	stamper := newLogStamper(clock)
+	out0, out1 := &bufferWriteCloser{}, &bufferWriteCloser{}
+	recent0, recent1 := newRecentLogs(recentLogsSize), newRecentLogs(recentLogsSize)
+	stdout0 := &stdoutCapture{out: out0, recent: recent0, stamper: stamper}
+	stderr1 := &stderrCapture{out: out1, recent: recent1, stamper: stamper}
+
+	_, err := stdout0.Write([]byte("node0 li"))
+	require.NoError(err)
+	clock.advance(time.Second)
+	_, err = stderr1.Write([]byte("panic: node1\n"))
+	require.NoError(err)
+	clock.advance(time.Second)
+	_, err = stdout0.Write([]byte("ne\nunterminated"))
+	require.NoError(err)
+	require.NoError(stdout0.Close())
+
+	require.Equal("#2 +2.000000s node0 line\n#3 +2.000000s unterminated", out0.String())
+	require.Equal([]string{"#2 +2.000000s node0 line", "#3 +2.000000s unterminated"}, recent0.last(0))
+	require.Equal("#1 +1.000000s panic: node1\n", out1.String())
+	require.Equal([]string{"#1 +1.000000s panic: node1"}, recent1.last(0))
+	// the panics are still detected, and reported as written
+	require.Equal("panic: node1\n", stderr1.getPanicTrace())
+}
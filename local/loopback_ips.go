@@ -0,0 +1,56 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"go.uber.org/zap"
+)
+
+var (
+	// the first loopback IP given to a node, 127.0.0.1 is left to the
+	// processes not knowing about the nodes IPs
+	firstNodeLoopbackIP = netip.MustParseAddr("127.0.0.2")
+	loopbackPrefix      = netip.MustParsePrefix("127.0.0.0/8")
+
+	errNoFreeLoopbackIP = errors.New("no free loopback IP left for the node")
+)
+
+// setNodeLoopbackIP gives the node with flags [nodeConfigFlags] and config file
+// [configFile] its own loopback IP, not used by any other node, as public IP
+// and HTTP host, see network.Config.LoopbackIPs. The IP is written to the
+// node flags, so that it is kept on restarts and snapshots. Nodes whose
+// public IP or HTTP host are given, other than by the network flags, keep them.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) setNodeLoopbackIP(nodeConfigFlags map[string]interface{}, configFile map[string]interface{}) error {
+	for _, key := range []string{config.PublicIPKey, config.HTTPHostKey} {
+		// the network flags, e.g. the default public IP, are overridden
+		if val, ok := nodeConfigFlags[key]; ok && val != ln.flags[key] {
+			return nil
+		}
+		if _, ok := configFile[key]; ok {
+			return nil
+		}
+	}
+	usedIPs := set.Set[string]{}
+	for _, node := range ln.nodes {
+		usedIPs.Add(node.publicIP)
+	}
+	ip := firstNodeLoopbackIP
+	for usedIPs.Contains(ip.String()) {
+		ip = ip.Next()
+	}
+	if !loopbackPrefix.Contains(ip) {
+		return errNoFreeLoopbackIP
+	}
+	if err := ensureLoopbackIP(ip.String()); err != nil {
+		return fmt.Errorf("couldn't set up loopback IP %s: %w", ip, err)
+	}
+	ln.log.Debug("node given its own loopback IP", zap.Stringer("ip", ip))
+	nodeConfigFlags[config.PublicIPKey] = ip.String()
+	nodeConfigFlags[config.HTTPHostKey] = ip.String()
+	return nil
+}
@@ -0,0 +1,35 @@
+//go:build darwin
+
+package local
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+)
+
+// macOS only routes 127.0.0.1 to the loopback interface, so the other
+// loopback IPs are added to it as aliases, what requires root
+func ensureLoopbackIP(ip string) error {
+	if isLocalIP(ip) {
+		return nil
+	}
+	if out, err := exec.Command("ifconfig", "lo0", "alias", ip, "up").CombinedOutput(); err != nil {
+		return fmt.Errorf("ifconfig lo0 alias failed: %w: %s", err, out)
+	}
+	return nil
+}
+
+// Returns true if [ip] is assigned to a network interface of the host
+func isLocalIP(ip string) bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.String() == ip {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,8 @@
+//go:build !darwin
+
+package local
+
+// Linux and Windows route all of 127.0.0.0/8 to the loopback interface
+func ensureLoopbackIP(string) error {
+	return nil
+}
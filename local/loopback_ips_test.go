@@ -0,0 +1,41 @@
+package local
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoopbackIPs(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.LoopbackIPs = true
+	// a public IP given to the node is kept, the network default one isn't
+	networkConfig.NodeConfigs[1].Flags[config.PublicIPKey] = "127.0.0.10"
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	require.Equal("127.0.0.2", net.nodes["node0"].GetIP())
+	require.Equal("127.0.0.10", net.nodes["node1"].GetIP())
+	require.Equal("127.0.0.3", net.nodes["node2"].GetIP())
+	require.Equal("127.0.0.3", net.nodes["node2"].httpHost)
+	require.Equal("127.0.0.3", net.nodes["node2"].GetConfig().Flags[config.HTTPHostKey])
+
+	// the IPs of removed nodes are reused
+	require.NoError(net.RemoveNode(context.Background(), "node0"))
+	_, err = net.AddNode(node.Config{Name: "node3"})
+	require.NoError(err)
+	require.Equal("127.0.0.2", net.nodes["node3"].GetIP())
+
+	savedConfig, err := net.ToConfig()
+	require.NoError(err)
+	require.True(savedConfig.LoopbackIPs)
+	require.NoError(net.Stop(context.Background()))
+}
@@ -0,0 +1,73 @@
+package local
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"go.uber.org/zap"
+)
+
+// See network.Network
+func (ln *localNetwork) SetNodeMaintenance(nodeName string, inMaintenance bool) (err error) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+	record := ln.startOperation("SetNodeMaintenance", nodeName, map[string]string{"inMaintenance": strconv.FormatBool(inMaintenance)})
+	defer ln.finishOperation(record, &err)
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	if _, ok := ln.nodes[nodeName]; !ok {
+		return &network.NodeError{NodeName: nodeName, Op: "set maintenance of", Err: network.ErrNodeNotFound}
+	}
+	if inMaintenance {
+		ln.maintenanceNodes.Add(nodeName)
+	} else {
+		ln.maintenanceNodes.Remove(nodeName)
+	}
+	ln.log.Info("node maintenance set", zap.String("node", nodeName), zap.Bool("in-maintenance", inMaintenance))
+	return nil
+}
+
+// See network.Network
+func (ln *localNetwork) NodesInMaintenance() ([]string, error) {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	if ln.stopCalled() {
+		return nil, network.ErrStopped
+	}
+	nodeNames := ln.maintenanceNodes.List()
+	slices.Sort(nodeNames)
+	return nodeNames, nil
+}
+
+// Returns the running nodes that are not in maintenance,
+// and the names of the ones skipped for being in maintenance, sorted.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) splitMaintenanceNodes() ([]*localNode, []string) {
+	nodes := ln.runningNodes()
+	checked := make([]*localNode, 0, len(nodes))
+	skipped := []string{}
+	for _, node := range nodes {
+		if ln.maintenanceNodes.Contains(node.name) {
+			skipped = append(skipped, node.name)
+			continue
+		}
+		checked = append(checked, node)
+	}
+	slices.Sort(skipped)
+	return checked, skipped
+}
+
+// Returns the nodes checked by Healthy, logging the ones skipped
+// for being in maintenance.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) healthCheckedNodes() []*localNode {
+	nodes, skipped := ln.splitMaintenanceNodes()
+	if len(skipped) != 0 {
+		ln.log.Info("skipping health check of nodes in maintenance", zap.Strings("nodes", skipped))
+	}
+	return nodes
+}
@@ -0,0 +1,49 @@
+package local
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeMaintenance(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	healthyClient := net.nodes["node1"].client
+	net.nodes["node1"].client = newMockAPIUnhealthy("", 0)
+
+	require.ErrorIs(net.SetNodeMaintenance("node3", true), network.ErrNodeNotFound)
+	require.NoError(net.SetNodeMaintenance("node1", true))
+	nodeNames, err := net.NodesInMaintenance()
+	require.NoError(err)
+	require.Equal([]string{"node1"}, nodeNames)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(net.Healthy(ctx))
+
+	require.NoError(net.SetNodeMaintenance("node1", false))
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.Error(net.Healthy(ctx))
+
+	// removing the node ends its maintenance
+	require.NoError(net.SetNodeMaintenance("node1", true))
+	net.nodes["node1"].client = healthyClient
+	require.NoError(net.RemoveNode(context.Background(), "node1"))
+	nodeNames, err = net.NodesInMaintenance()
+	require.NoError(err)
+	require.Empty(nodeNames)
+
+	require.NoError(net.Stop(context.Background()))
+	require.ErrorIs(net.SetNodeMaintenance("node0", true), network.ErrStopped)
+	_, err = net.NodesInMaintenance()
+	require.ErrorIs(err, network.ErrStopped)
+}
@@ -0,0 +1,270 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	metricsEndpoint   = "/ext/metrics"
+	metricsCheckFreq  = time.Second
+	metricsGetTimeout = 10 * time.Second
+)
+
+var errUnsupportedMetricType = errors.New("unsupported metric type")
+
+// See network.Network
+func (ln *localNetwork) AwaitMetric(
+	ctx context.Context,
+	metricName string,
+	predicate func(float64) bool,
+) error {
+	if _, _, err := parseMetricSelector(metricName); err != nil {
+		return err
+	}
+	ln.log.Info("waiting for metric", zap.String("metric", metricName))
+	var lastNodeErr error
+	for {
+		done, nodeErr, err := ln.checkMetric(ctx, metricName, predicate)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if nodeErr != nil {
+			lastNodeErr = nodeErr
+		}
+		select {
+		case <-ctx.Done():
+			if lastNodeErr != nil {
+				return fmt.Errorf("metric %q didn't satisfy the predicate on all nodes within timeout: %w (last error: %s)", metricName, ctx.Err(), lastNodeErr)
+			}
+			return fmt.Errorf("metric %q didn't satisfy the predicate on all nodes within timeout: %w", metricName, ctx.Err())
+		case <-ln.onStopCh:
+			return network.ErrStopped
+		case <-ln.clock.After(metricsCheckFreq):
+		}
+	}
+}
+
+// checkMetric returns true if [metricName] satisfies [predicate] on all running nodes.
+// Nodes that can't be scraped yet are considered to not satisfy it, and the
+// error of one of them is returned as [nodeErr].
+// Returns a non-nil [err] if the metric can't ever be checked.
+func (ln *localNetwork) checkMetric(
+	ctx context.Context,
+	metricName string,
+	predicate func(float64) bool,
+) (done bool, nodeErr error, err error) {
+	ln.lock.RLock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return false, nil, network.ErrStopped
+	}
+	nodes := map[string]*localNode{}
+	for nodeName, node := range ln.nodes {
+		if node.paused {
+			continue
+		}
+		nodes[nodeName] = node
+	}
+	ln.lock.RUnlock()
+	if len(nodes) == 0 {
+		return false, nil, network.ErrNoRunningNodes
+	}
+
+	type result struct {
+		satisfied bool
+		err       error
+	}
+	errGr, ctx := errgroup.WithContext(ctx)
+	results := make(chan result, len(nodes))
+	for nodeName, node := range nodes {
+		nodeName, node := nodeName, node
+		errGr.Go(func() error {
+			if !node.beginCall() {
+				// being removed, as if it was paused
+				results <- result{satisfied: true}
+				return nil
+			}
+			value, err := getMetricValue(ctx, node.GetURI(), metricName)
+			node.endCall()
+			if errors.Is(err, errUnsupportedMetricType) {
+				return err
+			}
+			if err != nil {
+				ln.log.Debug("couldn't get metric", zap.String("node", nodeName), zap.Error(err))
+				results <- result{err: fmt.Errorf("node %q: %w", nodeName, err)}
+				return nil
+			}
+			results <- result{satisfied: predicate(value)}
+			return nil
+		})
+	}
+	if err := errGr.Wait(); err != nil {
+		return false, nil, err
+	}
+	close(results)
+	done = true
+	for r := range results {
+		if !r.satisfied {
+			done = false
+		}
+		if r.err != nil {
+			nodeErr = r.err
+		}
+	}
+	return done, nodeErr, nil
+}
+
+// See network.Network
+func (ln *localNetwork) GetMetric(ctx context.Context, metricName string) (map[string]float64, error) {
+	if _, _, err := parseMetricSelector(metricName); err != nil {
+		return nil, err
+	}
+	ln.lock.RLock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return nil, network.ErrStopped
+	}
+	nodes := ln.runningNodes()
+	ln.lock.RUnlock()
+	if len(nodes) == 0 {
+		return nil, network.ErrNoRunningNodes
+	}
+
+	var lock sync.Mutex
+	values := make(map[string]float64, len(nodes))
+	errGr, ctx := errgroup.WithContext(ctx)
+	for _, node := range nodes {
+		node := node
+		errGr.Go(func() error {
+			if !node.beginCall() {
+				// being removed, as if it was paused
+				return nil
+			}
+			value, err := getMetricValue(ctx, node.GetURI(), metricName)
+			node.endCall()
+			if err != nil {
+				return &network.NodeError{NodeName: node.name, Op: "get metric of", Err: err}
+			}
+			lock.Lock()
+			values[node.name] = value
+			lock.Unlock()
+			return nil
+		})
+	}
+	if err := errGr.Wait(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// getMetricValue scrapes the metrics of the node at [uri] and returns the value of [metricName],
+// added up over all its label combinations, or only over the ones with the
+// label values given in it, as in `name{label="value"}`.
+// Only counter, gauge and untyped metrics are supported.
+func getMetricValue(ctx context.Context, uri string, metricName string) (float64, error) {
+	name, labels, err := parseMetricSelector(metricName)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, metricsGetTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri+metricsEndpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code %d getting metrics from %s", resp.StatusCode, uri)
+	}
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't parse metrics from %s: %w", uri, err)
+	}
+	family, ok := families[name]
+	if !ok {
+		return 0, fmt.Errorf("metric %q not found at %s", metricName, uri)
+	}
+	var value float64
+	var found bool
+	for _, metric := range family.GetMetric() {
+		if !hasLabels(metric, labels) {
+			continue
+		}
+		found = true
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			value += metric.GetCounter().GetValue()
+		case dto.MetricType_GAUGE:
+			value += metric.GetGauge().GetValue()
+		case dto.MetricType_UNTYPED:
+			value += metric.GetUntyped().GetValue()
+		default:
+			return 0, fmt.Errorf("%w %s for metric %q", errUnsupportedMetricType, family.GetType(), metricName)
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("metric %q not found at %s", metricName, uri)
+	}
+	return value, nil
+}
+
+// parseMetricSelector splits [selector], e.g. `name{label="value",other="value"}`,
+// into the metric name and the label values.
+func parseMetricSelector(selector string) (string, map[string]string, error) {
+	name, rest, ok := strings.Cut(selector, "{")
+	if !ok {
+		return selector, nil, nil
+	}
+	rest, ok = strings.CutSuffix(rest, "}")
+	if !ok || name == "" {
+		return "", nil, fmt.Errorf("invalid metric selector %q", selector)
+	}
+	labels := map[string]string{}
+	for _, matcher := range strings.Split(rest, ",") {
+		label, quotedValue, ok := strings.Cut(strings.TrimSpace(matcher), "=")
+		if !ok {
+			return "", nil, fmt.Errorf("invalid label matcher %q in metric selector %q", matcher, selector)
+		}
+		value, err := strconv.Unquote(quotedValue)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid label value %s in metric selector %q", quotedValue, selector)
+		}
+		labels[label] = value
+	}
+	return name, labels, nil
+}
+
+// hasLabels returns true if [metric] has all the label values in [labels]
+func hasLabels(metric *dto.Metric, labels map[string]string) bool {
+	matched := 0
+	for _, label := range metric.GetLabel() {
+		if value, ok := labels[label.GetName()]; ok {
+			if value != label.GetValue() {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(labels)
+}
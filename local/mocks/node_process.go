@@ -7,6 +7,8 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	node "github.com/ava-labs/avalanche-network-runner/network/node"
+
 	status "github.com/ava-labs/avalanche-network-runner/network/node/status"
 )
 
@@ -15,6 +17,38 @@ type NodeProcess struct {
 	mock.Mock
 }
 
+// CrashReport provides a mock function with given fields:
+func (_m *NodeProcess) CrashReport() *node.CrashReport {
+	ret := _m.Called()
+
+	var r0 *node.CrashReport
+	if rf, ok := ret.Get(0).(func() *node.CrashReport); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*node.CrashReport)
+		}
+	}
+
+	return r0
+}
+
+// Done provides a mock function with given fields:
+func (_m *NodeProcess) Done() <-chan struct{} {
+	ret := _m.Called()
+
+	var r0 <-chan struct{}
+	if rf, ok := ret.Get(0).(func() <-chan struct{}); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan struct{})
+		}
+	}
+
+	return r0
+}
+
 // Status provides a mock function with given fields:
 func (_m *NodeProcess) Status() status.Status {
 	ret := _m.Called()
@@ -8,11 +8,14 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"math/big"
+	"net/http"
 	"net/netip"
 	"os"
 	"os/user"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,15 +28,19 @@ import (
 	"github.com/ava-labs/avalanche-network-runner/utils"
 	"github.com/ava-labs/avalanche-network-runner/utils/constants"
 	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/genesis"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/peer"
 	avagonode "github.com/ava-labs/avalanchego/node"
 	"github.com/ava-labs/avalanchego/staking"
 	"github.com/ava-labs/avalanchego/utils/beacon"
+	avagoConstants "github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/ava-labs/coreth/plugin/evm"
 	"go.uber.org/zap"
 	"golang.org/x/exp/maps"
 	"golang.org/x/mod/semver"
@@ -41,16 +48,21 @@ import (
 )
 
 const (
-	defaultNodeNamePrefix       = "node"
-	configFileName              = "config.json"
-	upgradeConfigFileName       = "upgrade.json"
-	stakingTLSKeyFileName       = "staker.key"
-	stakingCertFileName         = "staker.crt"
-	stakingSignerKeyFileName    = "signer.key"
-	genesisFileName             = "genesis.json"
-	upgradeFileName             = "upgrade.json"
+	defaultNodeNamePrefix    = "node"
+	configFileName           = "config.json"
+	upgradeConfigFileName    = "upgrade.json"
+	stakingTLSKeyFileName    = "staker.key"
+	stakingCertFileName      = "staker.crt"
+	stakingSignerKeyFileName = "signer.key"
+	genesisFileName          = "genesis.json"
+	upgradeFileName          = "upgrade.json"
+	vmAliasesFileName        = "vm-aliases.json"
+	mainLogFileName          = "main.log"
+	// avalanchego error message when registering an alias that already exists
+	aliasAlreadyMappedMsg       = "alias already mapped"
 	stopTimeout                 = 30 * time.Second
 	healthCheckFreq             = 3 * time.Second
+	waitCheckFreq               = time.Second
 	snapshotPrefix              = "anr-snapshot-"
 	networkRootDirPrefix        = "network"
 	defaultDBSubdir             = "db"
@@ -58,6 +70,13 @@ const (
 	nodeStartupTime             = 1 * time.Second
 	processContextWaitTimeout   = 3 * time.Second
 	processContextCheckInterval = 100 * time.Millisecond
+
+	// a node that isn't healthy yet, and isn't connected to any of its
+	// beacons this long after being started, is considered misconfigured
+	beaconConnectTimeout = time.Minute
+	// on network creation, nodes wait at most this long
+	// for the nodes they depend on to be healthy
+	dependencyHealthyTimeout = 5 * time.Minute
 )
 
 // interface compliance
@@ -74,6 +93,10 @@ var (
 	snapshotsRelPath = filepath.Join(".avalanche-network-runner", "snapshots")
 
 	ErrSnapshotNotFound = errors.New("snapshot not found")
+	errNodeStopped      = errors.New("node stopped unexpectedly")
+	errNodePaused       = errors.New("node is paused")
+	errNodeDrained      = errors.New("node is being removed")
+	errNodeNotRunning   = errors.New("node process is not running")
 )
 
 // network keeps information uses for network management, and accessing all the nodes
@@ -88,6 +111,25 @@ type localNetwork struct {
 	// This network's upgrade file.
 	// May be nil
 	upgradeData []byte
+	// If true, the validator set is the genesis one, and the
+	// operations issuing staking txs are rejected
+	staticValidators bool
+	// If set, nodes are only added if the host has the resources they need
+	resourceGuard *network.ResourceGuard
+	// Returns the resources of the host, so that tests can replace it
+	getHostResources func() (network.HostResources, error)
+	// Labels given to all the node processes, see network.Config.Labels
+	labels map[string]string
+	// Times a node failing to bind its allocated ports is started again
+	portRaceRetries int
+	// a health request taking longer is retried, so that a hung request
+	// doesn't hold the whole health wait, see network.Config.HealthRequestTimeout
+	healthRequestTimeout time.Duration
+	// If true, each node gets its own loopback IP, see network.Config.LoopbackIPs
+	loopbackIPs bool
+	// Prefixes the captured node output lines, nil if they aren't,
+	// see network.Config.LogTimestamps
+	logStamper *logStamper
 	// Used to create a new API client
 	newAPIClientF api.NewAPIClientF
 	// Used to create new node processes
@@ -95,6 +137,9 @@ type localNetwork struct {
 	stopOnce           sync.Once
 	// Closed when Stop begins.
 	onStopCh chan struct{}
+	// Closed once the nodes are stopped, either by Stop or SaveSnapshot.
+	nodesStoppedCh   chan struct{}
+	nodesStoppedOnce sync.Once
 	// For node name generation
 	nextNodeSuffix uint64
 	// Node Name --> Node
@@ -104,6 +149,8 @@ type localNetwork struct {
 	// rootDir is the root directory under which we write all node
 	// databases, etc
 	rootDir string
+	// how [rootDir] is managed, as given by network.Config.RootDataDir
+	rootDataDirMode network.RootDataDirMode
 	// logRootDir is the root directory under which we write all node logs
 	logRootDir string
 	// directory where networks can be persistently saved
@@ -118,7 +165,8 @@ type localNetwork struct {
 	upgradeConfigFiles map[string]string
 	// subnet config files to use per default
 	subnetConfigFiles map[string]string
-	// if true, for ports given in conf that are already taken, assign new random ones
+	// if true, for ports given in conf that are already taken, assign new ones:
+	// the given ones shifted by [portRemapOffset] if free, random ones otherwise
 	reassignPortsIfUsed bool
 	// if true, direct this node's Stdout to os.Stdout
 	redirectStdout bool
@@ -128,11 +176,46 @@ type localNetwork struct {
 	subnetID2ElasticSubnetID map[ids.ID]ids.ID
 	// map from blockchain id to blockchain aliases
 	blockchainAliases map[string][]string
+	// map from vm id to vm aliases, set on nodes at startup
+	vmAliases map[string][]string
 	// wallet private key used. IF nil, genesis ewoq key will be used
 	walletPrivateKey string
 	// nodes always returns 127.0.0.1 as IP
 	// if not set, may return 0.0.0.0 depending on httpHost settings
 	zeroIP bool
+	// hooks called on node lifecycle events
+	nodeHooks []network.NodeHooks
+	// Node Name --> Node waiting for its start delay to pass
+	delayedNodes map[string]*delayedNode
+	// used by the timing logic, so that tests can replace it
+	clock clock
+	// Node Name --> beacons of other networks the node also bootstraps from
+	peers map[string]map[ids.NodeID]netip.AddrPort
+	// max fraction of the primary network stake that can be stopped at once
+	// by a guarded operation. 0 if the quorum guard is disabled.
+	maxStoppedStake float64
+	// Node Name --> db dir mounted as a tmpfs, for nodes with a db dir size limit
+	dbDirMounts map[string]string
+	// audit log of the mutating operations
+	history *operationHistory
+	// time the network took to start, see StartupReport
+	startup *networkStartup
+	// if set, the control file of the detached network, see StartDetached
+	controlFilePath string
+	// names of the nodes skipped by the health checks, see SetNodeMaintenance
+	maintenanceNodes set.Set[string]
+	// link direction --> bandwidth limit in bits per second, for the links
+	// limited by SetLinkBandwidth
+	linkBandwidths map[nodeLink]uint64
+	// shapes the limited links, set once a link is first limited
+	linkShaper *linkShaper
+}
+
+// delayedNode is a node scheduled to start after its start delay
+type delayedNode struct {
+	config    node.Config
+	startTime time.Time
+	timer     stopper
 }
 
 type deprecatedFlagEsp struct {
@@ -142,6 +225,13 @@ type deprecatedFlagEsp struct {
 	ValueMap string `json:"value_map"`
 }
 
+// Balances of the pre-funded addresses on the default genesis, in nAVAX
+// on the X-Chain, and in wei on the C-Chain
+var (
+	defaultXChainBalance    uint64 = 300 * units.MegaAvax
+	defaultCChainBalance, _        = new(big.Int).SetString("295BE96E64066972000000", 16)
+)
+
 var (
 	//go:embed default
 	embeddedDefaultNetworkConfigDir embed.FS
@@ -170,7 +260,12 @@ func init() {
 // Files (e.g. logs, databases) default to being written at directory [rootDir].
 // If there isn't a directory at [dir] one will be created.
 // If len([dir]) == 0, files will be written underneath a new temporary directory.
+// Alternatively, the root dir and how it is managed may be given by
+// [networkConfig.RootDataDir], in which case [rootDir] must be empty.
 // Snapshots are saved to snapshotsDir, defaults to DefaultSnapshotsDir if not given
+// If [reassignPortsIfUsed] is true, nodes whose given ports are already taken are
+// started with their ports shifted by 1000, or with random ports if those are taken too.
+// The nodes are started before returning, see NewUnstartedNetwork to start them later.
 func NewNetwork(
 	log logging.Logger,
 	networkConfig network.Config,
@@ -183,13 +278,47 @@ func NewNetwork(
 	walletPrivateKey string,
 	zeroIP bool,
 ) (network.Network, error) {
-	beaconSet, err := utils.BeaconMapToSet(networkConfig.BeaconConfig)
+	un, err := NewUnstartedNetwork(
+		log,
+		networkConfig,
+		rootDir,
+		logRootDir,
+		snapshotsDir,
+		reassignPortsIfUsed,
+		redirectStdout,
+		redirectStderr,
+		walletPrivateKey,
+		zeroIP,
+	)
 	if err != nil {
 		return nil, err
 	}
-	net, err := newNetwork(
+	return un.Start(context.Background())
+}
+
+// NewUnstartedNetwork returns a new network as NewNetwork does, but without
+// starting its nodes, so that their settings can be inspected and adjusted
+// before their processes are launched. Errors returned here are on the
+// config or the dirs of the network, the ones on starting the nodes are
+// returned by UnstartedNetwork.Start.
+func NewUnstartedNetwork(
+	log logging.Logger,
+	networkConfig network.Config,
+	rootDir string,
+	logRootDir string,
+	snapshotsDir string,
+	reassignPortsIfUsed bool,
+	redirectStdout bool,
+	redirectStderr bool,
+	walletPrivateKey string,
+	zeroIP bool,
+) (*UnstartedNetwork, error) {
+	if networkConfig.Backend != "" && networkConfig.Backend != network.DefaultBackend {
+		return nil, fmt.Errorf("network config selects backend %q, not %q", networkConfig.Backend, network.DefaultBackend)
+	}
+	return newUnstartedNetwork(
 		log,
-		api.NewAPIClient,
+		networkConfig,
 		&nodeProcessCreator{
 			colorPicker: utils.NewColorPicker(),
 			log:         log,
@@ -203,13 +332,126 @@ func NewNetwork(
 		redirectStdout,
 		redirectStderr,
 		walletPrivateKey,
+		zeroIP,
+	)
+}
+
+// See NewNetwork.
+// [nodeProcessCreator] is used to launch the node processes.
+func newNetworkFromConfig(
+	log logging.Logger,
+	networkConfig network.Config,
+	nodeProcessCreator NodeProcessCreator,
+	rootDir string,
+	logRootDir string,
+	snapshotsDir string,
+	reassignPortsIfUsed bool,
+	redirectStdout bool,
+	redirectStderr bool,
+	walletPrivateKey string,
+	zeroIP bool,
+) (network.Network, error) {
+	un, err := newUnstartedNetwork(
+		log,
+		networkConfig,
+		nodeProcessCreator,
+		rootDir,
+		logRootDir,
+		snapshotsDir,
+		reassignPortsIfUsed,
+		redirectStdout,
+		redirectStderr,
+		walletPrivateKey,
+		zeroIP,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return un.Start(context.Background())
+}
+
+// See NewUnstartedNetwork.
+// [nodeProcessCreator] is used to launch the node processes.
+func newUnstartedNetwork(
+	log logging.Logger,
+	networkConfig network.Config,
+	nodeProcessCreator NodeProcessCreator,
+	rootDir string,
+	logRootDir string,
+	snapshotsDir string,
+	reassignPortsIfUsed bool,
+	redirectStdout bool,
+	redirectStderr bool,
+	walletPrivateKey string,
+	zeroIP bool,
+) (*UnstartedNetwork, error) {
+	beaconSet, err := utils.BeaconMapToSet(networkConfig.BeaconConfig)
+	if err != nil {
+		return nil, err
+	}
+	if networkConfig.RootDataDir.Mode != network.DefaultRootDataDir {
+		if rootDir != "" {
+			return nil, errors.New("root data dir given both on the network config and as a parameter")
+		}
+		// empty on ephemeral mode, so that a temporary dir is created
+		rootDir = networkConfig.RootDataDir.Path
+	}
+	if rootDir == "" && networkConfig.RootDataDir.Name != "" {
+		rootDir, err = newRootDataDir(networkConfig.RootDataDir.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	net, err := newNetwork(
+		log,
+		newAPIClientF(networkConfig.APIHeaders, networkConfig.APIRequestTimeout),
+		nodeProcessCreator,
+		rootDir,
+		logRootDir,
+		snapshotsDir,
+		reassignPortsIfUsed,
+		redirectStdout,
+		redirectStderr,
+		walletPrivateKey,
 		beaconSet,
 		zeroIP,
 	)
 	if err != nil {
-		return net, err
+		return nil, err
+	}
+	net.rootDataDirMode = networkConfig.RootDataDir.Mode
+	if !networkConfig.Cleanup.IsZero() {
+		// after creating the root data dir, so that it is the most recent run
+		removed, err := cleanRuns("", networkConfig.Cleanup, net.rootDir)
+		if err != nil {
+			log.Warn("couldn't clean up previous runs", zap.Error(err))
+		}
+		for _, dir := range removed {
+			log.Info("removed previous run", zap.String("dir", dir))
+		}
+	}
+	startOrder, err := net.prepareConfig(networkConfig)
+	if err != nil {
+		net.releaseRootDir()
+		return nil, err
+	}
+	return &UnstartedNetwork{ln: net, nodeConfigs: nameNodeConfigs(startOrder)}, nil
+}
+
+// Returns the NewAPIClientF of the API clients of the nodes, sending [headers]
+// on their requests, with [requestTimeout] as given by network.Config.
+func newAPIClientF(headers map[string]string, requestTimeout time.Duration) api.NewAPIClientF {
+	options := api.ClientOptions{RequestTimeout: requestTimeout}
+	if requestTimeout == 0 {
+		options.RequestTimeout = network.DefaultAPIRequestTimeout
+	}
+	if len(headers) != 0 {
+		options.Headers = http.Header{}
+		for k, v := range headers {
+			options.Headers.Set(k, v)
+		}
 	}
-	return net, net.loadConfig(context.Background(), networkConfig)
+	return api.NewAPIClientWithOptions(options)
 }
 
 // See NewNetwork.
@@ -230,14 +472,12 @@ func newNetwork(
 	zeroIP bool,
 ) (*localNetwork, error) {
 	var err error
-	if rootDir == "" {
-		anrRootDir := filepath.Join(os.TempDir(), constants.RootDirPrefix)
-		err = os.MkdirAll(anrRootDir, os.ModePerm)
-		if err != nil {
+	if rootDir != "" {
+		if err := os.MkdirAll(rootDir, os.ModePerm); err != nil {
 			return nil, err
 		}
-		networkRootDir := filepath.Join(anrRootDir, networkRootDirPrefix)
-		rootDir, err = utils.MkDirWithTimestamp(networkRootDir)
+	} else {
+		rootDir, err = newRootDataDir("")
 		if err != nil {
 			return nil, err
 		}
@@ -253,11 +493,17 @@ func newNetwork(
 	if err != nil {
 		return nil, err
 	}
+	// so that the run isn't removed by the cleanup of other networks
+	if err := writeRunPIDs(rootDir, []int{os.Getpid()}); err != nil {
+		return nil, err
+	}
 	// Create the network
 	net := &localNetwork{
 		nextNodeSuffix:           1,
 		nodes:                    map[string]*localNode{},
+		delayedNodes:             map[string]*delayedNode{},
 		onStopCh:                 make(chan struct{}),
+		nodesStoppedCh:           make(chan struct{}),
 		log:                      log,
 		bootstraps:               beaconSet,
 		newAPIClientF:            newAPIClientF,
@@ -270,8 +516,20 @@ func newNetwork(
 		redirectStderr:           redirectStderr,
 		subnetID2ElasticSubnetID: map[ids.ID]ids.ID{},
 		blockchainAliases:        map[string][]string{},
+		vmAliases:                map[string][]string{},
+		peers:                    map[string]map[ids.NodeID]netip.AddrPort{},
+		dbDirMounts:              map[string]string{},
+		linkBandwidths:           map[nodeLink]uint64{},
 		walletPrivateKey:         walletPrivateKey,
 		zeroIP:                   zeroIP,
+		clock:                    realClock{},
+		getHostResources:         getHostResources,
+		healthRequestTimeout:     network.DefaultHealthRequestTimeout,
+		history: &operationHistory{
+			log:  log,
+			path: filepath.Join(rootDir, historyFileName),
+		},
+		startup: &networkStartup{startTime: time.Now()},
 	}
 	return net, nil
 }
@@ -291,6 +549,7 @@ func newNetwork(
 // * NodeID-NFBbbJ4qCmNaCzeW7sxErhvWqvEQMnYcN
 // * NodeID-GWPcbFJZFfZreETSoWjPimr846mXEKCtu
 // * NodeID-P7oB2McjBGgW2NXXWVYjV8JEDFoW9xDE5
+// The validator IDs can also be obtained programmatically with DefaultNodeIDs.
 func NewDefaultNetwork(
 	log logging.Logger,
 	binaryPath string,
@@ -360,6 +619,25 @@ func loadDefaultNetworkFiles() (map[string]interface{}, []byte, []*utils.NodeKey
 	return flags, cChainConfig, nodeKeys, nil
 }
 
+// DefaultNodeIDs returns the NodeIDs of the default network nodes, in
+// node1..node5 order. They are derived from the embedded staking keys, so
+// they are stable across runs and can be used on test assertions.
+func DefaultNodeIDs() ([]ids.NodeID, error) {
+	_, _, nodeKeys, err := loadDefaultNetworkFiles()
+	if err != nil {
+		return nil, err
+	}
+	nodeIDs := make([]ids.NodeID, 0, len(nodeKeys))
+	for _, keys := range nodeKeys {
+		nodeID, err := utils.ToNodeID(keys.StakingKey, keys.StakingCert)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get node ID: %w", err)
+		}
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	return nodeIDs, nil
+}
+
 // NewDefaultConfigNNodes creates a new default network config, with an arbitrary number of nodes
 func NewDefaultConfigNNodes(
 	binaryPath string,
@@ -368,6 +646,71 @@ func NewDefaultConfigNNodes(
 	genesisPath string,
 	upgradePath string,
 	beaconConfig map[ids.NodeID]netip.AddrPort,
+) (network.Config, error) {
+	return newDefaultConfig(binaryPath, numNodes, networkID, upgradePath, beaconConfig,
+		func(networkID uint32, nodeKeys []*utils.NodeKeys) ([]byte, error) {
+			if len(genesisPath) == 0 {
+				return utils.GenerateGenesis(networkID, nodeKeys)
+			}
+			if _, err := os.Stat(genesisPath); err != nil {
+				return nil, fmt.Errorf("could not find genesis file: %w", err)
+			}
+			genesisBytes, err := os.ReadFile(genesisPath)
+			if err != nil {
+				return nil, fmt.Errorf("could not read genesis file: %w", err)
+			}
+			return genesisBytes, nil
+		},
+	)
+}
+
+// NewDefaultConfigWithGenesisGenerator creates a new default network config,
+// with [numNodes] nodes, as NewDefaultConfigNNodes, except that the genesis of
+// custom network IDs is generated by [generator], with the nodes as
+// validators, and the pre-funded addresses of NewDefaultNetwork funded as on
+// the default genesis.
+func NewDefaultConfigWithGenesisGenerator(
+	binaryPath string,
+	numNodes uint32,
+	networkID uint32,
+	generator network.GenesisGenerator,
+	upgradePath string,
+	beaconConfig map[ids.NodeID]netip.AddrPort,
+) (network.Config, error) {
+	return newDefaultConfig(binaryPath, numNodes, networkID, upgradePath, beaconConfig,
+		func(networkID uint32, nodeKeys []*utils.NodeKeys) ([]byte, error) {
+			spec := network.GenesisSpec{
+				NetworkID: networkID,
+				XChainBalances: []network.AddrAndBalance{{
+					Addr:    genesis.EWOQKey.Address(),
+					Balance: new(big.Int).SetUint64(defaultXChainBalance),
+				}},
+				CChainBalances: []network.AddrAndBalance{{
+					Addr:    ids.ShortID(evm.GetEthAddress(genesis.EWOQKey)),
+					Balance: new(big.Int).Set(defaultCChainBalance),
+				}},
+			}
+			for _, keys := range nodeKeys {
+				nodeID, err := keys.NodeID()
+				if err != nil {
+					return nil, fmt.Errorf("couldn't get node ID: %w", err)
+				}
+				spec.Validators = append(spec.Validators, nodeID)
+			}
+			return generator.GenerateGenesis(spec)
+		},
+	)
+}
+
+// Creates a new default network config with [numNodes] nodes, whose genesis
+// on custom network IDs is given by [getGenesis] for the node keys
+func newDefaultConfig(
+	binaryPath string,
+	numNodes uint32,
+	networkID uint32,
+	upgradePath string,
+	beaconConfig map[ids.NodeID]netip.AddrPort,
+	getGenesis func(networkID uint32, nodeKeys []*utils.NodeKeys) ([]byte, error),
 ) (network.Config, error) {
 	if networkID == 0 {
 		networkID = constants.DefaultNetworkID
@@ -427,22 +770,11 @@ func NewDefaultConfigNNodes(
 		cfg.Upgrade = string(upgrade)
 	}
 	if utils.IsCustomNetwork(networkID) {
-		var genesis []byte
-		if len(genesisPath) != 0 {
-			if _, err := os.Stat(genesisPath); err != nil {
-				return network.Config{}, fmt.Errorf("could not find genesis file: %w", err)
-			}
-			genesis, err = os.ReadFile(genesisPath)
-			if err != nil {
-				return network.Config{}, fmt.Errorf("could not read genesis file: %w", err)
-			}
-		} else {
-			genesis, err = utils.GenerateGenesis(networkID, nodeKeys)
-			if err != nil {
-				return network.Config{}, err
-			}
+		genesisBytes, err := getGenesis(networkID, nodeKeys)
+		if err != nil {
+			return network.Config{}, err
 		}
-		cfg.Genesis = string(genesis)
+		cfg.Genesis = string(genesisBytes)
 		cfg.ChainConfigFiles = map[string]string{
 			"C": string(cChainConfig),
 		}
@@ -468,9 +800,23 @@ func NewDefaultConfig(
 	)
 }
 
+// loadConfig loads [networkConfig] into the network, and starts its nodes
 func (ln *localNetwork) loadConfig(ctx context.Context, networkConfig network.Config) error {
+	startOrder, err := ln.prepareConfig(networkConfig)
+	if err != nil {
+		return err
+	}
+	return ln.startNodes(ctx, startOrder)
+}
+
+// prepareConfig validates [networkConfig] and loads its network settings,
+// without starting any node. Returns the node configs in start order.
+func (ln *localNetwork) prepareConfig(networkConfig network.Config) ([]node.Config, error) {
+	if err := networkConfig.ApplyBeaconPolicy(); err != nil {
+		return nil, err
+	}
 	if err := networkConfig.Validate(); err != nil {
-		return fmt.Errorf("config failed validation: %w", err)
+		return nil, fmt.Errorf("config failed validation: %w", err)
 	}
 	ln.log.Info("creating network", zap.Int("node-num", len(networkConfig.NodeConfigs)))
 
@@ -479,19 +825,36 @@ func (ln *localNetwork) loadConfig(ctx context.Context, networkConfig network.Co
 		ln.genesisData = []byte(networkConfig.Genesis)
 		genesisNetworkID, err := utils.NetworkIDFromGenesis(ln.genesisData)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		switch {
 		case ln.networkID == 0:
 			ln.networkID = genesisNetworkID
 		case ln.networkID != genesisNetworkID:
 			if ln.genesisData, err = utils.SetGenesisNetworkID(ln.genesisData, ln.networkID); err != nil {
-				return fmt.Errorf("couldn't set network ID to genesis: %w", err)
+				return nil, fmt.Errorf("couldn't set network ID to genesis: %w", err)
 			}
 		}
 	}
 
 	ln.upgradeData = []byte(networkConfig.Upgrade)
+	ln.staticValidators = networkConfig.StaticValidators
+	ln.resourceGuard = networkConfig.ResourceGuard
+	ln.labels = maps.Clone(networkConfig.Labels)
+	ln.portRaceRetries = networkConfig.PortRaceRetries
+	if ln.portRaceRetries == 0 {
+		ln.portRaceRetries = network.DefaultPortRaceRetries
+	}
+	if networkConfig.HealthRequestTimeout != 0 {
+		ln.healthRequestTimeout = networkConfig.HealthRequestTimeout
+	}
+	ln.loopbackIPs = networkConfig.LoopbackIPs
+	if networkConfig.LogTimestamps {
+		ln.logStamper = newLogStamper(ln.clock)
+	}
+	if setter, ok := ln.nodeProcessCreator.(logStamperSetter); ok {
+		setter.setLogStamper(ln.logStamper)
+	}
 
 	// save node defaults
 	ln.flags = networkConfig.Flags
@@ -500,7 +863,7 @@ func (ln *localNetwork) loadConfig(ctx context.Context, networkConfig network.Co
 
 	beaconConf, err := utils.BeaconMapToSet(networkConfig.BeaconConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	ln.bootstraps = beaconConf
 	if ln.chainConfigFiles == nil {
@@ -515,43 +878,67 @@ func (ln *localNetwork) loadConfig(ctx context.Context, networkConfig network.Co
 		ln.subnetConfigFiles = map[string]string{}
 	}
 
-	// Sort node configs so beacons start first
-	var nodeConfigs []node.Config
-	for _, nodeConfig := range networkConfig.NodeConfigs {
-		if nodeConfig.IsBeacon {
-			nodeConfigs = append(nodeConfigs, nodeConfig)
-		}
-	}
-	for _, nodeConfig := range networkConfig.NodeConfigs {
-		if !nodeConfig.IsBeacon {
+	// Sort node configs so beacons start first, and nodes start after
+	// the nodes they depend on
+	// Nodes with a start delay are scheduled after the rest are started
+	return networkConfig.StartOrder()
+}
+
+// startNodes starts the nodes of [startOrder], as returned by prepareConfig,
+// stopping the network if one fails to start.
+func (ln *localNetwork) startNodes(ctx context.Context, startOrder []node.Config) error {
+	var nodeConfigs, delayedNodeConfigs []node.Config
+	for _, nodeConfig := range startOrder {
+		if nodeConfig.StartDelay > 0 {
+			delayedNodeConfigs = append(delayedNodeConfigs, nodeConfig)
+		} else {
 			nodeConfigs = append(nodeConfigs, nodeConfig)
 		}
 	}
 
 	for i := range nodeConfigs {
-		if node, nodeErr := ln.addNode(nodeConfigs[i]); nodeErr != nil {
-			if node != nil {
-				if mainLog, err := os.ReadFile(filepath.Join(node.GetLogsDir(), "main.log")); err == nil {
-					if strings.Contains(string(mainLog), "bind: address already in use") {
-						if ln.reassignPortsIfUsed {
-							ln.log.Info(fmt.Sprintf(
-								"failed to start node %s with given ports. executing again with dynamic ones.",
-								nodeConfigs[i].Name,
-							))
-							// execute again asking avago to set ports by itself
-							nodeConfigs[i].Flags[config.HTTPPortKey] = 0
-							nodeConfigs[i].Flags[config.StakingPortKey] = 0
-							_, nodeErr = ln.addNode(nodeConfigs[i])
-							if nodeErr == nil {
-								continue
-							}
-						} else {
-							nodeErr = fmt.Errorf(
-								"failed to start node %s with given ports. probably another avalanchego process is running",
-								nodeConfigs[i].Name,
-							)
+		if err := ln.awaitDependencies(ctx, nodeConfigs[i]); err != nil {
+			if err := ln.stop(ctx); err != nil {
+				ln.log.Debug("error stopping network", zap.Error(err))
+			}
+			return fmt.Errorf("error adding node %s: %w", nodeConfigs[i].Name, err)
+		}
+		if node, nodeErr := ln.addNodeRetryingPortRaces(nodeConfigs[i]); nodeErr != nil {
+			// allocated ports were already retried
+			if failedToBindPorts(node) && !ln.allocatesPorts(nodeConfigs[i]) {
+				if ln.reassignPortsIfUsed {
+					// first try deterministic ports, so the new URIs can be derived from the given ones
+					remapped, err := remapPorts(nodeConfigs[i].Flags)
+					if err != nil {
+						ln.log.Debug("couldn't remap node ports", zap.Error(err))
+					}
+					if remapped {
+						ln.log.Info(fmt.Sprintf(
+							"failed to start node %s with given ports. executing again with ports shifted by %d.",
+							nodeConfigs[i].Name,
+							portRemapOffset,
+						))
+						_, nodeErr = ln.addNode(nodeConfigs[i])
+						if nodeErr == nil {
+							continue
 						}
 					}
+					ln.log.Info(fmt.Sprintf(
+						"failed to start node %s with given ports. executing again with dynamic ones.",
+						nodeConfigs[i].Name,
+					))
+					// execute again asking avago to set ports by itself
+					nodeConfigs[i].Flags[config.HTTPPortKey] = 0
+					nodeConfigs[i].Flags[config.StakingPortKey] = 0
+					_, nodeErr = ln.addNode(nodeConfigs[i])
+					if nodeErr == nil {
+						continue
+					}
+				} else {
+					nodeErr = fmt.Errorf(
+						"failed to start node %s with given ports. probably another avalanchego process is running",
+						nodeConfigs[i].Name,
+					)
 				}
 			}
 			if err := ln.stop(ctx); err != nil {
@@ -561,6 +948,73 @@ func (ln *localNetwork) loadConfig(ctx context.Context, networkConfig network.Co
 			return fmt.Errorf("error adding node %s: %w", nodeConfigs[i].Name, nodeErr)
 		}
 	}
+	for _, nodeConfig := range delayedNodeConfigs {
+		if err := ln.scheduleNodeStart(nodeConfig); err != nil {
+			if err := ln.stop(ctx); err != nil {
+				ln.log.Debug("error stopping network", zap.Error(err))
+			}
+			return fmt.Errorf("error scheduling node %s: %w", nodeConfig.Name, err)
+		}
+	}
+	return nil
+}
+
+// awaitDependencies waits until the nodes [nodeConfig] depends on are
+// healthy, for at most [dependencyHealthyTimeout].
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) awaitDependencies(ctx context.Context, nodeConfig node.Config) error {
+	if len(nodeConfig.DependsOn) == 0 {
+		return nil
+	}
+	deps := make([]*localNode, len(nodeConfig.DependsOn))
+	for i, dep := range nodeConfig.DependsOn {
+		// dependencies are started first
+		deps[i] = ln.nodes[dep]
+	}
+	ln.log.Info("waiting for node dependencies", zap.String("node-name", nodeConfig.Name), zap.Strings("dependencies", nodeConfig.DependsOn))
+	ctx, cancel := context.WithTimeout(ctx, dependencyHealthyTimeout)
+	defer cancel()
+	if err := ln.awaitHealthy(ctx, deps, ln.nodeHooks, func(*localNode) bool { return false }); err != nil {
+		return fmt.Errorf("dependencies not healthy: %w", err)
+	}
+	return nil
+}
+
+// scheduleNodeStart adds the node with config [nodeConfig] to the network
+// after its start delay has passed, unless the network is stopped before.
+// Errors on the delayed start are logged, as there is no caller to return them to.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) scheduleNodeStart(nodeConfig node.Config) error {
+	if err := ln.setNodeName(&nodeConfig); err != nil {
+		return err
+	}
+	delay := nodeConfig.StartDelay
+	// the node is not delayed again if the network is saved and reloaded after it starts
+	nodeConfig.StartDelay = 0
+	ln.log.Info("scheduling node start", zap.String("node-name", nodeConfig.Name), zap.Duration("delay", delay))
+	ln.delayedNodes[nodeConfig.Name] = &delayedNode{
+		config:    nodeConfig,
+		startTime: ln.clock.Now().Add(delay),
+		timer: ln.clock.AfterFunc(delay, func() {
+			ln.lock.Lock()
+			defer ln.lock.Unlock()
+			// the start is canceled if the network was stopped in the meantime
+			if _, ok := ln.delayedNodes[nodeConfig.Name]; !ok {
+				return
+			}
+			delete(ln.delayedNodes, nodeConfig.Name)
+			if ln.stopCalled() {
+				return
+			}
+			if _, err := ln.addNode(nodeConfig); err != nil {
+				ln.log.Error("failed to start delayed node", zap.String("node-name", nodeConfig.Name), zap.Error(err))
+				return
+			}
+			if err := ln.persistNetwork(); err != nil {
+				ln.log.Error("failed to persist network", zap.Error(err))
+			}
+		}),
+	}
 	return nil
 }
 
@@ -577,23 +1031,63 @@ func (ln *localNetwork) GetNetworkID() (uint32, error) {
 }
 
 // See network.Network
-func (ln *localNetwork) AddNode(nodeConfig node.Config) (node.Node, error) {
+func (ln *localNetwork) AddNode(nodeConfig node.Config) (_ *network.AddedNode, err error) {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
+	record := ln.startOperation("AddNode", nodeConfig.Name, nil)
+	defer ln.finishOperation(record, &err)
 
 	if ln.stopCalled() {
 		return nil, network.ErrStopped
 	}
+	if nodeConfig.StartDelay > 0 {
+		return nil, network.ErrStartDelay
+	}
+	if err := ln.checkResources(1); err != nil {
+		return nil, &network.NodeError{NodeName: nodeConfig.Name, Op: "add", Err: err}
+	}
 
-	node, err := ln.addNode(nodeConfig)
+	node, err := ln.addNodeRetryingPortRaces(nodeConfig)
 	if err != nil {
-		return node, err
+		return nil, &network.NodeError{NodeName: nodeConfig.Name, Op: "add", Err: err}
 	}
-	return node, ln.persistNetwork()
+	record.NodeName = node.GetName()
+	return network.NewAddedNode(node), ln.persistNetwork()
 }
 
-// Assumes [ln.lock] is held and [ln.Stop] hasn't been called.
-func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
+// See network.Network
+func (ln *localNetwork) AddNodeFromTemplate(templateName string, overrides network.NodeOverrides) (_ *network.AddedNode, err error) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+	record := ln.startOperation("AddNodeFromTemplate", overrides.Name, map[string]string{"template": templateName})
+	defer ln.finishOperation(record, &err)
+
+	if ln.stopCalled() {
+		return nil, network.ErrStopped
+	}
+	template, ok := ln.nodes[templateName]
+	if !ok {
+		return nil, &network.NodeError{NodeName: templateName, Op: "add node from", Err: network.ErrNodeNotFound}
+	}
+	nodeConfig, err := network.NewNodeConfigFromTemplate(template.GetConfig(), overrides)
+	if err != nil {
+		return nil, &network.NodeError{NodeName: templateName, Op: "add node from", Err: err}
+	}
+	if err := ln.checkResources(1); err != nil {
+		return nil, &network.NodeError{NodeName: nodeConfig.Name, Op: "add", Err: err}
+	}
+	node, err := ln.addNodeRetryingPortRaces(nodeConfig)
+	if err != nil {
+		return nil, &network.NodeError{NodeName: nodeConfig.Name, Op: "add", Err: err}
+	}
+	record.NodeName = node.GetName()
+	return network.NewAddedNode(node), ln.persistNetwork()
+}
+
+// applyNodeDefaults sets the network defaults, i.e. binary path, config
+// files and flags, that [nodeConfig] doesn't give.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) applyNodeDefaults(nodeConfig *node.Config) {
 	if nodeConfig.Flags == nil {
 		nodeConfig.Flags = map[string]interface{}{}
 	}
@@ -630,6 +1124,13 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 		}
 	}
 	addNetworkFlags(ln.flags, nodeConfig.Flags)
+}
+
+// Assumes [ln.lock] is held and [ln.Stop] hasn't been called.
+func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
+	startup := newStartupTracker(ln.clock)
+
+	ln.applyNodeDefaults(&nodeConfig)
 
 	if err := ln.setNodeName(&nodeConfig); err != nil {
 		return nil, err
@@ -689,6 +1190,7 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 		encodedKey := base64.StdEncoding.EncodeToString(keyBytes)
 		nodeConfig.StakingSigningKey = encodedKey
 	}
+	startup.endPhase(network.StartupPhaseKeyGeneration)
 
 	// If config file is given, don't overwrite API port, P2P port, DB path, logs path
 	var configFile map[string]interface{}
@@ -697,6 +1199,11 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 			return nil, fmt.Errorf("couldn't unmarshal config file: %w", err)
 		}
 	}
+	if ln.loopbackIPs {
+		if err := ln.setNodeLoopbackIP(nodeConfig.Flags, configFile); err != nil {
+			return nil, err
+		}
+	}
 
 	// Get node version
 	nodeSemVer, err := ln.getNodeSemVer(nodeConfig)
@@ -709,6 +1216,26 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 		return nil, err
 	}
 
+	if nodeConfig.DBSeedDir != "" {
+		if err := seedDBDir(nodeConfig.DBSeedDir, nodeData.dbDir, nodeConfig.DBSeedSymlink); err != nil {
+			return nil, fmt.Errorf("couldn't seed db dir: %w", err)
+		}
+	}
+	if nodeConfig.DBDirSizeLimit > 0 {
+		if err := ln.mountDBDir(nodeConfig.Name, nodeData.dbDir, nodeConfig.DBDirSizeLimit); err != nil {
+			return nil, err
+		}
+	}
+	if nodeConfig.RunAsUser != "" {
+		u, err := lookupRunAsUser(nodeConfig.RunAsUser)
+		if err != nil {
+			return nil, err
+		}
+		if err := u.chownDirs(nodeData.dataDir, nodeData.dbDir, nodeData.logsDir); err != nil {
+			return nil, err
+		}
+	}
+
 	// Parse this node's ID
 	nodeID, err := utils.ToNodeID([]byte(nodeConfig.StakingKey), []byte(nodeConfig.StakingCert))
 	if err != nil {
@@ -730,19 +1257,40 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 		config:        nodeConfig,
 		pluginDir:     nodeData.pluginDir,
 		httpHost:      nodeData.httpHost,
+		flags:         nodeData.flags,
 		zeroIP:        ln.zeroIP,
 		attachedPeers: map[string]peer.Peer{},
+		onStopCh:      make(chan struct{}),
+		network:       ln,
+		startup:       startup,
 	}
 
-	// Start the AvalancheGo node and pass it the flags defined above
-	nodeProcess, err := ln.nodeProcessCreator.NewNodeProcess(nodeConfig, nodeStartupTime, nodeData.args...)
-	if err != nil {
-		return node, fmt.Errorf(
-			"couldn't create new node process with binary %q and args %v: %w",
+	for _, hooks := range ln.nodeHooks {
+		if hooks.OnBeforeNodeStart != nil {
+			if err := hooks.OnBeforeNodeStart(nodeConfig); err != nil {
+				return nil, fmt.Errorf("node %q start aborted by hook: %w", nodeConfig.Name, err)
+			}
+		}
+	}
+
+	startup.endPhase(network.StartupPhaseDirSetup)
+
+	// the process gets the labels of the network, overridden by the node ones
+	processConfig := nodeConfig
+	if len(ln.labels) != 0 {
+		processConfig.Labels = maps.Clone(ln.labels)
+		maps.Copy(processConfig.Labels, nodeConfig.Labels)
+	}
+	// Start the AvalancheGo node and pass it the flags defined above
+	nodeProcess, err := ln.nodeProcessCreator.NewNodeProcess(processConfig, nodeStartupTime, nodeData.args...)
+	if err != nil {
+		return node, fmt.Errorf(
+			"couldn't create new node process with binary %q and args %v: %w",
 			nodeConfig.BinaryPath, nodeData.args, err,
 		)
 	}
 	node.process = nodeProcess
+	node.startTime = ln.clock.Now()
 
 	if node.apiPort == 0 {
 		processFilePath := filepath.Join(nodeData.dataDir, config.DefaultProcessContextFilename)
@@ -775,6 +1323,8 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 		node.p2pPort = p2pPort
 	}
 
+	startup.endPhase(network.StartupPhaseProcessStart)
+
 	node.client = ln.newAPIClientF(node.publicIP, node.apiPort)
 
 	// If this node is a beacon, add its IP/ID to the beacon lists.
@@ -812,24 +1362,232 @@ func (ln *localNetwork) addNode(nodeConfig node.Config) (node.Node, error) {
 	)
 
 	ln.nodes[node.name] = node
+	go ln.watchNodeCrash(node)
+	if len(ln.blockchainAliases) > 0 {
+		node.blockchainAliases = make(map[string][]string, len(ln.blockchainAliases))
+		for blockchainID, blockchainAliases := range ln.blockchainAliases {
+			node.blockchainAliases[blockchainID] = slices.Clone(blockchainAliases)
+		}
+	}
+	go ln.watchNodeHealthy(node)
 	return node, ln.persistNetwork()
 }
 
+// watchNodeCrash waits for the process of [node] to exit, and if it wasn't stopped
+// by the network, keeps its crash report, with the tail of its main log, so that
+// it outlives the node's dirs, and calls the crash hooks.
+// Gives up if the node or the network are stopped.
+// Assumes [ln.lock] is not held.
+func (ln *localNetwork) watchNodeCrash(node *localNode) {
+	select {
+	case <-node.process.Done():
+	case <-node.onStopCh:
+		return
+	case <-ln.onStopCh:
+		return
+	}
+	crashReport := node.process.CrashReport()
+	if crashReport == nil {
+		return
+	}
+	logTail, err := readLogTail(filepath.Join(node.logsDir, mainLogFileName), crashLogTailLines)
+	if err != nil {
+		ln.log.Warn("couldn't read log of crashed node", zap.String("node", node.name), zap.Error(err))
+	}
+	crashReport.LogTail = logTail
+	node.setCrashReport(crashReport)
+	ln.log.Error("node crashed",
+		zap.String("node", node.name),
+		zap.Int("exit-code", crashReport.ExitCode),
+		zap.String("panic", crashReport.PanicTrace),
+	)
+
+	ln.lock.RLock()
+	hooks := slices.Clone(ln.nodeHooks)
+	ln.lock.RUnlock()
+	for _, hooks := range hooks {
+		if hooks.OnNodeCrash != nil {
+			hooks.OnNodeCrash(node, *crashReport)
+		}
+	}
+}
+
+// watchNodeHealthy waits for [node] to be healthy, and then registers its
+// blockchain aliases and calls the healthy hooks, so that they don't depend
+// on Healthy being called. Gives up if the node or the network are stopped.
+// Assumes [ln.lock] is not held.
+func (ln *localNetwork) watchNodeHealthy(node *localNode) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-ln.onStopCh:
+			cancel()
+		case <-node.onStopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	for !node.stopped() && node.Status() == status.Running {
+		if node.isHealthy(ctx) {
+			ln.lock.RLock()
+			hooks := slices.Clone(ln.nodeHooks)
+			ln.lock.RUnlock()
+			ln.onNodeHealthy(ctx, node, hooks)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ln.clock.After(healthCheckFreq):
+		}
+	}
+}
+
+// onNodeHealthy registers the blockchain aliases of [node] and calls [hooks] for it,
+// only the first time it is seen healthy for the node process, either by
+// its health poll or by a health check of the network.
+// Doesn't require [ln.lock], as it only accesses immutable node fields.
+func (ln *localNetwork) onNodeHealthy(ctx context.Context, node *localNode, hooks []network.NodeHooks) {
+	node.seenHealthy.Store(true)
+	ln.registerNodeAliases(ctx, node)
+	node.onHealthyOnce.Do(func() {
+		for _, hooks := range hooks {
+			if hooks.OnAfterNodeHealthy != nil {
+				hooks.OnAfterNodeHealthy(node)
+			}
+		}
+	})
+}
+
+// registerNodeAliases registers the blockchain aliases of [node] on it, only the first time
+// it is called for the node process. Failures are logged, as the node may not track
+// the blockchain's subnet.
+func (ln *localNetwork) registerNodeAliases(ctx context.Context, node *localNode) {
+	if !node.beginCall() {
+		return
+	}
+	defer node.endCall()
+	node.registerAliasesOnce.Do(func() {
+		for blockchainID, blockchainAliases := range node.blockchainAliases {
+			for _, blockchainAlias := range blockchainAliases {
+				err := node.client.AdminAPI().AliasChain(ctx, blockchainID, blockchainAlias)
+				if err != nil && !strings.Contains(err.Error(), aliasAlreadyMappedMsg) {
+					ln.log.Warn("failure to register blockchain alias",
+						zap.String("node", node.name),
+						zap.String("chain-id", blockchainID),
+						zap.String("alias", blockchainAlias),
+						zap.Error(err),
+					)
+				}
+			}
+		}
+	})
+}
+
 // See network.Network
+// The network lock is only held to get the nodes to check, so that
+// other operations aren't blocked while waiting for them to be healthy.
 func (ln *localNetwork) Healthy(ctx context.Context) error {
 	ln.lock.RLock()
-	defer ln.lock.RUnlock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return network.ErrStopped
+	}
+	nodes := ln.healthCheckedNodes()
+	hooks := slices.Clone(ln.nodeHooks)
+	ln.lock.RUnlock()
 
-	return ln.healthy(ctx)
+	if err := ln.awaitHealthy(ctx, nodes, hooks, ln.isNodeGone); err != nil {
+		return err
+	}
+	ln.markHealthy()
+	return nil
 }
 
+// Assumes [ln.lock] is held.
 func (ln *localNetwork) healthy(ctx context.Context) error {
-	ln.log.Info("checking local network healthiness", zap.Int("num-of-nodes", len(ln.nodes)))
-
 	// Return unhealthy if the network is stopped
 	if ln.stopCalled() {
 		return network.ErrStopped
 	}
+	// nodes can't be removed or paused while the lock is held
+	if err := ln.awaitHealthy(ctx, ln.healthCheckedNodes(), ln.nodeHooks, func(*localNode) bool { return false }); err != nil {
+		return err
+	}
+	ln.markHealthy()
+	return nil
+}
+
+// See network.Network
+func (ln *localNetwork) AwaitNodeHealthy(ctx context.Context, nodeName string) error {
+	ln.lock.RLock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return network.ErrStopped
+	}
+	node, ok := ln.nodes[nodeName]
+	ln.lock.RUnlock()
+	if !ok {
+		return &network.NodeError{NodeName: nodeName, Op: "check health of", Err: network.ErrNodeNotFound}
+	}
+	return ln.awaitNodeHealthy(ctx, node)
+}
+
+// Waits until [node] is healthy. Unlike with Healthy, the node
+// being removed or paused while waiting makes it fail.
+// Assumes [ln.lock] is not held.
+func (ln *localNetwork) awaitNodeHealthy(ctx context.Context, node *localNode) error {
+	ln.lock.RLock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return network.ErrStopped
+	}
+	if node.paused {
+		ln.lock.RUnlock()
+		return &network.NodeError{NodeName: node.name, Op: "check health of", Err: errNodePaused}
+	}
+	hooks := slices.Clone(ln.nodeHooks)
+	ln.lock.RUnlock()
+
+	return ln.awaitHealthy(ctx, []*localNode{node}, hooks, func(*localNode) bool { return false })
+}
+
+// Returns the nodes that are not paused.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) runningNodes() []*localNode {
+	nodes := make([]*localNode, 0, len(ln.nodes))
+	for _, node := range ln.nodes {
+		if node.paused {
+			// no health check for paused nodes
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// Returns true if [node] was removed, paused, or replaced by
+// another process, e.g. on restart.
+// Assumes [ln.lock] is not held.
+func (ln *localNetwork) isNodeGone(node *localNode) bool {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	return ln.nodes[node.name] != node || node.paused
+}
+
+// Waits until all of [nodes] are healthy, calling [hooks] for each of them
+// the first time it is seen healthy. A node that is not running makes
+// the check fail, unless [isNodeGone] reports that it was stopped on purpose.
+// Doesn't require [ln.lock], as it only accesses immutable node fields.
+func (ln *localNetwork) awaitHealthy(
+	ctx context.Context,
+	nodes []*localNode,
+	hooks []network.NodeHooks,
+	isNodeGone func(*localNode) bool,
+) error {
+	ln.log.Info("checking local network healthiness", zap.Int("num-of-nodes", len(nodes)))
 
 	// Derive a new context that's cancelled when Stop is called,
 	// so that calls to Healthy() below immediately return.
@@ -846,31 +1604,66 @@ func (ln *localNetwork) healthy(ctx context.Context) error {
 	}(ctx)
 
 	errGr, ctx := errgroup.WithContext(ctx)
-	for _, node := range ln.nodes {
-		if node.paused {
-			// no health check for paused nodes
-			continue
-		}
+	for _, node := range nodes {
 		node := node
 		nodeName := node.GetName()
 		errGr.Go(func() error {
+			// set once the node is seen connected to one of its beacons
+			beaconConnected := false
 			// Every [healthCheckFreq], query node for health status.
 			// Do this until ctx timeout or network closed.
 			for {
-				if node.Status() != status.Running {
+				if node.stopped() || node.Status() != status.Running {
+					if ln.stopCalled() {
+						// the nodes are removed when the network stops
+						return network.ErrStopped
+					}
+					if isNodeGone(node) {
+						// removed, paused or restarted since the check started
+						return nil
+					}
 					// If we had stopped this node ourselves, it wouldn't be in [ln.nodes].
 					// Since it is, it means the node stopped unexpectedly.
-					return fmt.Errorf("node %q stopped unexpectedly", nodeName)
+					return &network.NodeError{NodeName: nodeName, Op: "check health of", Err: withLogs(errNodeStopped, node.startupLogs())}
 				}
-				health, err := node.client.HealthAPI().Health(ctx, nil)
-				if err == nil && health.Healthy {
+				if node.isHealthy(ctx) {
 					ln.log.Debug("node became healthy", zap.String("name", nodeName))
+					ln.onNodeHealthy(ctx, node, hooks)
 					return nil
 				}
+				if startupTimeout := node.config.StartupTimeout; startupTimeout > 0 && !node.seenResponding.Load() && ln.clock.Now().Sub(node.startTime) >= startupTimeout {
+					return &network.NodeError{
+						NodeName: nodeName,
+						Op:       "check health of",
+						Err:      withLogs(fmt.Errorf("%w of %s", network.ErrStartupTimeout, startupTimeout), node.startupLogs()),
+					}
+				}
+				if !beaconConnected && !node.seenHealthy.Load() && ln.clock.Now().Sub(node.startTime) >= beaconConnectTimeout {
+					var err error
+					beaconConnected, err = node.isBeaconConnected(ctx)
+					if err == nil && !beaconConnected {
+						return &network.NodeError{
+							NodeName: nodeName,
+							Op:       "check health of",
+							Err: fmt.Errorf(
+								"%w %s after start, check its bootstrap ids %q and ips %q",
+								network.ErrBeaconNotConnected,
+								beaconConnectTimeout,
+								node.flags[config.BootstrapIDsKey],
+								node.flags[config.BootstrapIPsKey],
+							),
+						}
+					}
+				}
 				select {
 				case <-ctx.Done():
-					return fmt.Errorf("node %q failed to become healthy within timeout, or network stopped", nodeName)
-				case <-time.After(healthCheckFreq):
+					return &network.NodeError{
+						NodeName: nodeName,
+						Op:       "check health of",
+						Err:      fmt.Errorf("not healthy within timeout, or network stopped: %w", ctx.Err()),
+					}
+				case <-node.onStopCh:
+				case <-ln.clock.After(healthCheckFreq):
 				}
 			}
 		})
@@ -904,7 +1697,39 @@ func (ln *localNetwork) GetNodeNames() ([]string, error) {
 		return nil, network.ErrStopped
 	}
 
-	return maps.Keys(ln.nodes), nil
+	nodeNames := maps.Keys(ln.nodes)
+	slices.Sort(nodeNames)
+	return nodeNames, nil
+}
+
+// See network.Network
+func (ln *localNetwork) GetNodeNamesWithFilter(ctx context.Context, filter network.NodeFilter) ([]string, error) {
+	ln.lock.RLock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return nil, network.ErrStopped
+	}
+	nodes := []*localNode{}
+	for _, node := range ln.nodes {
+		if filter.Healthy && node.paused {
+			continue
+		}
+		if filter.MatchesConfig(node.config) {
+			nodes = append(nodes, node)
+		}
+	}
+	ln.lock.RUnlock()
+
+	nodeNames := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		// the health checks are done without the lock, as in Healthy
+		if filter.Healthy && (node.Status() != status.Running || !node.isHealthy(ctx)) {
+			continue
+		}
+		nodeNames = append(nodeNames, node.name)
+	}
+	slices.Sort(nodeNames)
+	return nodeNames, ctx.Err()
 }
 
 // See network.Network
@@ -923,6 +1748,84 @@ func (ln *localNetwork) GetAllNodes() (map[string]node.Node, error) {
 	return nodesCopy, nil
 }
 
+// See network.Network
+func (ln *localNetwork) Versions(ctx context.Context) (map[string]node.Version, error) {
+	ln.lock.RLock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return nil, network.ErrStopped
+	}
+	nodes := ln.runningNodes()
+	ln.lock.RUnlock()
+	if len(nodes) == 0 {
+		return nil, network.ErrNoRunningNodes
+	}
+
+	var lock sync.Mutex
+	versions := make(map[string]node.Version, len(nodes))
+	errGr, ctx := errgroup.WithContext(ctx)
+	for _, node := range nodes {
+		node := node
+		errGr.Go(func() error {
+			version, err := node.GetVersion(ctx)
+			if errors.Is(err, errNodeDrained) {
+				// being removed, as if it was paused
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			lock.Lock()
+			versions[node.name] = version
+			lock.Unlock()
+			return nil
+		})
+	}
+	if err := errGr.Wait(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// See network.Network
+func (ln *localNetwork) SetLogLevel(ctx context.Context, level string) error {
+	ln.lock.RLock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return network.ErrStopped
+	}
+	nodes := ln.runningNodes()
+	ln.lock.RUnlock()
+	if len(nodes) == 0 {
+		return network.ErrNoRunningNodes
+	}
+
+	errGr, ctx := errgroup.WithContext(ctx)
+	for _, node := range nodes {
+		node := node
+		errGr.Go(func() error {
+			err := node.SetLogLevel(ctx, level)
+			if errors.Is(err, errNodeDrained) {
+				// being removed, as if it was paused
+				return nil
+			}
+			return err
+		})
+	}
+	return errGr.Wait()
+}
+
+// See network.Network
+func (ln *localNetwork) ToConfig() (network.Config, error) {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	if ln.stopCalled() {
+		return network.Config{}, network.ErrStopped
+	}
+	return ln.getNetworkConfig(false)
+}
+
 func (ln *localNetwork) Stop(ctx context.Context) error {
 	err := network.ErrStopped
 	ln.stopOnce.Do(
@@ -932,14 +1835,75 @@ func (ln *localNetwork) Stop(ctx context.Context) error {
 			ln.lock.Lock()
 			defer ln.lock.Unlock()
 
+			record := ln.startOperation("Stop", "", nil)
 			err = ln.stop(ctx)
+			ln.finishOperation(record, &err)
+			ln.releaseRootDir()
 		},
 	)
 	return err
 }
 
+// Removes the root data dir on ephemeral mode, or else marks it as no
+// longer in use, once the nodes are stopped.
+func (ln *localNetwork) releaseRootDir() {
+	if ln.rootDataDirMode != network.EphemeralRootDataDir {
+		if err := os.Remove(filepath.Join(ln.rootDir, runPIDsFileName)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			ln.log.Warn("couldn't remove root data dir pids file", zap.String("dir", ln.rootDir), zap.Error(err))
+		}
+		return
+	}
+	if err := os.RemoveAll(ln.rootDir); err != nil {
+		ln.log.Warn("couldn't remove ephemeral root data dir", zap.String("dir", ln.rootDir), zap.Error(err))
+	}
+}
+
+// See network.Network
+func (ln *localNetwork) Wait(ctx context.Context) error {
+	for {
+		if ln.nodesExited() {
+			return network.ErrNodesExited
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ln.onStopCh:
+			return nil
+		case <-ln.nodesStoppedCh:
+			return nil
+		case <-ln.clock.After(waitCheckFreq):
+		}
+	}
+}
+
+// nodesExited returns true if there are nodes that are not paused,
+// and all their processes exited without Stop being called
+func (ln *localNetwork) nodesExited() bool {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	if ln.stopCalled() {
+		return false
+	}
+	exited := false
+	for _, node := range ln.nodes {
+		if node.paused {
+			continue
+		}
+		if node.Status() != status.Stopped {
+			return false
+		}
+		exited = true
+	}
+	return exited
+}
+
 // Assumes [ln.lock] is held.
 func (ln *localNetwork) stop(ctx context.Context) error {
+	for nodeName, delayedNode := range ln.delayedNodes {
+		delayedNode.timer.Stop()
+		delete(ln.delayedNodes, nodeName)
+	}
 	errs := wrappers.Errs{}
 	for nodeName := range ln.nodes {
 		stopCtx, stopCtxCancel := context.WithTimeout(ctx, stopTimeout)
@@ -949,30 +1913,154 @@ func (ln *localNetwork) stop(ctx context.Context) error {
 		}
 		stopCtxCancel()
 	}
+	for nodeName := range ln.dbDirMounts {
+		if err := ln.unmountDBDir(nodeName); err != nil {
+			ln.log.Error("error unmounting node db dir", zap.String("name", nodeName), zap.Error(err))
+			errs.Add(err)
+		}
+	}
+	if ln.linkShaper != nil {
+		if err := ln.linkShaper.close(ctx); err != nil {
+			ln.log.Error("error removing link bandwidth limits", zap.Error(err))
+			errs.Add(err)
+		}
+	}
+	ln.nodesStoppedOnce.Do(func() {
+		close(ln.nodesStoppedCh)
+	})
 	ln.log.Info("done stopping network")
 	return errs.Err
 }
 
 // Sends a SIGTERM to the given node and removes it from this network.
 func (ln *localNetwork) RemoveNode(ctx context.Context, nodeName string) error {
+	ln.lock.RLock()
+	force := ln.maxStoppedStake == 0
+	ln.lock.RUnlock()
+	return ln.RemoveNodeWithOptions(ctx, nodeName, network.RemoveNodeOptions{Force: force})
+}
+
+// See network.Network
+func (ln *localNetwork) RemoveNodeWithOptions(ctx context.Context, nodeName string, opts network.RemoveNodeOptions) (err error) {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
+	record := ln.startOperation("RemoveNode", nodeName, map[string]string{
+		"force":      strconv.FormatBool(opts.Force),
+		"drainFirst": strconv.FormatBool(opts.DrainFirst),
+	})
+	defer ln.finishOperation(record, &err)
 
 	if ln.stopCalled() {
 		return network.ErrStopped
 	}
-	if err := ln.removeNode(ctx, nodeName); err != nil {
-		return err
+	if node, ok := ln.nodes[nodeName]; ok && !node.paused {
+		if !opts.Force {
+			maxStoppedStake := ln.maxStoppedStake
+			if maxStoppedStake == 0 {
+				maxStoppedStake = network.DefaultMaxStoppedStake
+			}
+			if err := ln.checkStoppedStake(ctx, maxStoppedStake, node); err != nil {
+				return &network.NodeError{NodeName: nodeName, Op: "remove", Err: err}
+			}
+		}
+		if opts.DrainFirst {
+			if err := node.drain(ctx); err != nil {
+				return &network.NodeError{NodeName: nodeName, Op: "remove", Err: err}
+			}
+		}
+	}
+	removeErr := ln.removeNode(ctx, nodeName)
+	ln.maintenanceNodes.Remove(nodeName)
+	// unmounted even if the node exited with an error, as it is removed anyway
+	if err := ln.unmountDBDir(nodeName); err != nil && removeErr == nil {
+		removeErr = err
+	}
+	if removeErr != nil {
+		return &network.NodeError{NodeName: nodeName, Op: "remove", Err: removeErr}
 	}
+	delete(ln.peers, nodeName)
+	ln.removeNodeLinks(nodeName)
 	return ln.persistNetwork()
 }
 
+// See network.Network
+func (ln *localNetwork) SetQuorumGuard(maxStoppedStake float64) (err error) {
+	record := ln.startOperation("SetQuorumGuard", "", map[string]string{
+		"maxStoppedStake": strconv.FormatFloat(maxStoppedStake, 'g', -1, 64),
+	})
+	defer ln.finishOperation(record, &err)
+	if maxStoppedStake < 0 || maxStoppedStake > 1 {
+		return fmt.Errorf("max stopped stake must be between 0 and 1, got %v", maxStoppedStake)
+	}
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	ln.maxStoppedStake = maxStoppedStake
+	return nil
+}
+
+// Returns ErrQuorumLoss if the quorum guard is set, and stopping [nodes]
+// at once would stop more stake than it allows.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) checkQuorumGuard(ctx context.Context, nodes ...*localNode) error {
+	if ln.maxStoppedStake == 0 {
+		return nil
+	}
+	return ln.checkStoppedStake(ctx, ln.maxStoppedStake, nodes...)
+}
+
+// Returns ErrQuorumLoss if some of [nodes] are running primary network
+// validators, and stopping them would leave more than [maxStoppedStake]
+// of the primary network stake stopped.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) checkStoppedStake(ctx context.Context, maxStoppedStake float64, nodes ...*localNode) error {
+	stopping := set.Set[ids.NodeID]{}
+	var client api.Client
+	for _, node := range nodes {
+		if !node.paused && node.Status() == status.Running {
+			stopping.Add(node.nodeID)
+			client = node.client
+		}
+	}
+	if client == nil {
+		return nil
+	}
+	vdrs, err := client.PChainAPI().GetCurrentValidators(ctx, avagoConstants.PrimaryNetworkID, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't get validators to check quorum: %w", err)
+	}
+	running := set.Set[ids.NodeID]{}
+	for _, node := range ln.nodes {
+		if !stopping.Contains(node.nodeID) && !node.paused && node.Status() == status.Running {
+			running.Add(node.nodeID)
+		}
+	}
+	var stopsValidator bool
+	var totalWeight, stoppedWeight uint64
+	for _, vdr := range vdrs {
+		totalWeight += vdr.Weight
+		if stopping.Contains(vdr.NodeID) {
+			stopsValidator = true
+		}
+		if !running.Contains(vdr.NodeID) {
+			stoppedWeight += vdr.Weight
+		}
+	}
+	if !stopsValidator || totalWeight == 0 {
+		return nil
+	}
+	if float64(stoppedWeight)/float64(totalWeight) > maxStoppedStake {
+		return fmt.Errorf("%w: %d of %d stake would be stopped", network.ErrQuorumLoss, stoppedWeight, totalWeight)
+	}
+	return nil
+}
+
 // Assumes [ln.lock] is held.
 func (ln *localNetwork) removeNode(ctx context.Context, nodeName string) error {
 	ln.log.Debug("removing node", zap.String("name", nodeName))
 	node, ok := ln.nodes[nodeName]
 	if !ok {
-		return fmt.Errorf("node %q not found", nodeName)
+		return network.ErrNodeNotFound
 	}
 
 	paused := node.paused
@@ -985,22 +2073,51 @@ func (ln *localNetwork) removeNode(ctx context.Context, nodeName string) error {
 		// cchain eth api uses a websocket connection and must be closed before stopping the node,
 		// to avoid errors logs at client
 		node.client.CChainEthAPI().Close()
-		if exitCode := node.process.Stop(ctx); exitCode != 0 {
+		exitCode := node.process.Stop(ctx)
+		node.markStopped()
+		ln.runNodeStopHooks(node)
+		if exitCode != 0 {
 			return fmt.Errorf("node %q exited with exit code: %d", nodeName, exitCode)
 		}
 	}
 	return nil
 }
 
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) runNodeStopHooks(node *localNode) {
+	for _, hooks := range ln.nodeHooks {
+		if hooks.OnNodeStop != nil {
+			hooks.OnNodeStop(node)
+		}
+	}
+}
+
 // Sends a SIGTERM to the given node and keeps it in the network with paused state
 func (ln *localNetwork) PauseNode(ctx context.Context, nodeName string) error {
+	return ln.pauseNodeOf(ctx, nodeName, nil)
+}
+
+// Pauses [nodeName]. If [handle] is given, the node is only paused if it is
+// the one [handle] was returned for, not another one added with the same name.
+// Assumes [ln.lock] is not held.
+func (ln *localNetwork) pauseNodeOf(ctx context.Context, nodeName string, handle *localNode) (err error) {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
+	record := ln.startOperation("PauseNode", nodeName, nil)
+	defer ln.finishOperation(record, &err)
 	if ln.stopCalled() {
 		return network.ErrStopped
 	}
+	if err := ln.checkNodeHandle(nodeName, handle); err != nil {
+		return &network.NodeError{NodeName: nodeName, Op: "pause", Err: err}
+	}
+	if node, ok := ln.nodes[nodeName]; ok {
+		if err := ln.checkQuorumGuard(ctx, node); err != nil {
+			return &network.NodeError{NodeName: nodeName, Op: "pause", Err: err}
+		}
+	}
 	if err := ln.pauseNode(ctx, nodeName); err != nil {
-		return err
+		return &network.NodeError{NodeName: nodeName, Op: "pause", Err: err}
 	}
 	return ln.persistNetwork()
 }
@@ -1010,7 +2127,7 @@ func (ln *localNetwork) pauseNode(ctx context.Context, nodeName string) error {
 	ln.log.Debug("pausing node", zap.String("name", nodeName))
 	node, ok := ln.nodes[nodeName]
 	if !ok {
-		return fmt.Errorf("node %q not found", nodeName)
+		return network.ErrNodeNotFound
 	}
 	if node.paused {
 		return fmt.Errorf("node has been paused already")
@@ -1018,7 +2135,10 @@ func (ln *localNetwork) pauseNode(ctx context.Context, nodeName string) error {
 	// cchain eth api uses a websocket connection and must be closed before stopping the node,
 	// to avoid errors logs at client
 	node.client.CChainEthAPI().Close()
-	if exitCode := node.process.Stop(ctx); exitCode != 0 {
+	exitCode := node.process.Stop(ctx)
+	node.markStopped()
+	ln.runNodeStopHooks(node)
+	if exitCode != 0 {
 		return fmt.Errorf("node %q exited with exit code: %d", nodeName, exitCode)
 	}
 	node.paused = true
@@ -1030,11 +2150,23 @@ func (ln *localNetwork) ResumeNode(
 	ctx context.Context,
 	nodeName string,
 ) error {
+	return ln.resumeNodeOf(ctx, nodeName, nil)
+}
+
+// Resumes [nodeName]. If [handle] is given, the node is only resumed if it is
+// the one [handle] was returned for, not another one added with the same name.
+// Assumes [ln.lock] is not held.
+func (ln *localNetwork) resumeNodeOf(ctx context.Context, nodeName string, handle *localNode) (err error) {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
+	record := ln.startOperation("ResumeNode", nodeName, nil)
+	defer ln.finishOperation(record, &err)
 
+	if err := ln.checkNodeHandle(nodeName, handle); err != nil {
+		return &network.NodeError{NodeName: nodeName, Op: "resume", Err: err}
+	}
 	if err := ln.resumeNode(ctx, nodeName); err != nil {
-		return err
+		return &network.NodeError{NodeName: nodeName, Op: "resume", Err: err}
 	}
 	return ln.persistNetwork()
 }
@@ -1046,7 +2178,7 @@ func (ln *localNetwork) resumeNode(
 ) error {
 	node, ok := ln.nodes[nodeName]
 	if !ok {
-		return fmt.Errorf("node %q not found", nodeName)
+		return network.ErrNodeNotFound
 	}
 	if !node.paused {
 		return fmt.Errorf("node has not been paused")
@@ -1057,8 +2189,27 @@ func (ln *localNetwork) resumeNode(
 	nodeConfig.Flags[config.LogsDirKey] = node.GetLogsDir()
 	nodeConfig.Flags[config.HTTPPortKey] = int(node.GetAPIPort())
 	nodeConfig.Flags[config.StakingPortKey] = int(node.GetP2PPort())
-	if _, err := ln.addNode(nodeConfig); err != nil {
-		return err
+	return ln.replaceNode(node, nodeConfig)
+}
+
+// Adds the node of [nodeConfig] in place of [node], paused or stopped to restart
+// it, so that the handles returned for [node] refer to the new node process.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) replaceNode(node *localNode, nodeConfig node.Config) error {
+	_, err := ln.addNode(nodeConfig)
+	if newNode, ok := ln.nodes[node.name]; ok && newNode != node {
+		node.next.Store(newNode)
+	}
+	return err
+}
+
+// Returns ErrNodeNotFound if [handle] is given and isn't the current node
+// named [nodeName], as it was removed, even if another one was added with
+// the same name.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) checkNodeHandle(nodeName string, handle *localNode) error {
+	if handle != nil && ln.nodes[nodeName] != handle.current() {
+		return network.ErrNodeNotFound
 	}
 	return nil
 }
@@ -1074,10 +2225,21 @@ func (ln *localNetwork) RestartNode(
 	chainConfigs map[string]string,
 	upgradeConfigs map[string]string,
 	subnetConfigs map[string]string,
-) error {
+) (err error) {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
-
+	record := ln.startOperation("RestartNode", nodeName, nonEmptyParams(map[string]string{
+		"binaryPath":   binaryPath,
+		"pluginDir":    pluginDir,
+		"trackSubnets": trackSubnets,
+	}))
+	defer ln.finishOperation(record, &err)
+
+	if node, ok := ln.nodes[nodeName]; ok {
+		if err := ln.checkQuorumGuard(ctx, node); err != nil {
+			return &network.NodeError{NodeName: nodeName, Op: "restart", Err: err}
+		}
+	}
 	if err := ln.restartNode(
 		ctx,
 		nodeName,
@@ -1088,7 +2250,7 @@ func (ln *localNetwork) RestartNode(
 		upgradeConfigs,
 		subnetConfigs,
 	); err != nil {
-		return err
+		return &network.NodeError{NodeName: nodeName, Op: "restart", Err: err}
 	}
 	return ln.persistNetwork()
 }
@@ -1105,7 +2267,7 @@ func (ln *localNetwork) restartNode(
 ) error {
 	node, ok := ln.nodes[nodeName]
 	if !ok {
-		return fmt.Errorf("node %q not found", nodeName)
+		return network.ErrNodeNotFound
 	}
 
 	nodeConfig := node.GetConfig()
@@ -1146,11 +2308,15 @@ func (ln *localNetwork) restartNode(
 		}
 	}
 
-	if _, err := ln.addNode(nodeConfig); err != nil {
-		return err
-	}
+	return ln.replaceNode(node, nodeConfig)
+}
 
-	return nil
+// See network.Network
+func (ln *localNetwork) RegisterNodeHooks(hooks network.NodeHooks) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	ln.nodeHooks = append(ln.nodeHooks, hooks)
 }
 
 // Returns whether Stop has been called.
@@ -1177,7 +2343,8 @@ func (ln *localNetwork) setNodeName(nodeConfig *node.Config) error {
 		for {
 			nodeConfig.Name = fmt.Sprintf("%s%d", defaultNodeNamePrefix, ln.nextNodeSuffix)
 			_, ok := ln.nodes[nodeConfig.Name]
-			if !ok {
+			_, delayed := ln.delayedNodes[nodeConfig.Name]
+			if !ok && !delayed {
 				break
 			}
 			ln.nextNodeSuffix++
@@ -1188,11 +2355,15 @@ func (ln *localNetwork) setNodeName(nodeConfig *node.Config) error {
 	if node, ok := ln.nodes[nodeConfig.Name]; ok && !node.paused {
 		return fmt.Errorf("repeated node name %q", nodeConfig.Name)
 	}
+	if _, ok := ln.delayedNodes[nodeConfig.Name]; ok {
+		return fmt.Errorf("repeated node name %q, scheduled to start", nodeConfig.Name)
+	}
 	return nil
 }
 
 type buildArgsReturn struct {
 	args      []string
+	flags     map[string]string
 	publicIP  string
 	apiPort   uint16
 	p2pPort   uint16
@@ -1285,9 +2456,16 @@ func (ln *localNetwork) buildArgs(
 	if logsDir != filepath.Join(dataDir, defaultLogsSubdir) {
 		flags[config.LogsDirKey] = logsDir
 	}
+	if nodeConfig.SubnetOnly {
+		flags[config.PartialSyncPrimaryNetworkKey] = "true"
+	}
 	if !utils.IsPublicNetwork(ln.networkID) {
-		flags[config.BootstrapIPsKey] = ln.bootstraps.IPsArg()
-		flags[config.BootstrapIDsKey] = ln.bootstraps.IDsArg()
+		bootstraps, err := ln.getNodeBootstraps(nodeConfig.Name)
+		if err != nil {
+			return buildArgsReturn{}, err
+		}
+		flags[config.BootstrapIPsKey] = bootstraps.IPsArg()
+		flags[config.BootstrapIDsKey] = bootstraps.IDsArg()
 	}
 
 	insideContainer, err := utils.IsInsideDockerContainer()
@@ -1302,7 +2480,14 @@ func (ln *localNetwork) buildArgs(
 
 	// Write staking key/cert etc. to disk so the new node can use them,
 	// and get flag that point the node to those files
-	fileFlags, err := writeFiles(ln.genesisData, ln.upgradeData, dataDir, nodeConfig)
+	var vmAliasesData []byte
+	if len(ln.vmAliases) > 0 {
+		vmAliasesData, err = json.Marshal(ln.vmAliases)
+		if err != nil {
+			return buildArgsReturn{}, err
+		}
+	}
+	fileFlags, err := writeFiles(ln.genesisData, ln.upgradeData, vmAliasesData, dataDir, nodeConfig)
 	if err != nil {
 		return buildArgsReturn{}, err
 	}
@@ -1330,7 +2515,15 @@ func (ln *localNetwork) buildArgs(
 
 	// map input flags to the corresponding avago version, making sure that latest flags don't break
 	// old avago versions
-	flagsForAvagoVersion := getFlagsForAvagoVersion(nodeSemVer, flags)
+	flagsForAvagoVersion, translatedFlags := getFlagsForAvagoVersion(nodeSemVer, flags)
+	for givenName, nodeName := range translatedFlags {
+		ln.log.Info("translated flag for avalanchego version",
+			zap.String("node-name", nodeConfig.Name),
+			zap.String("version", nodeSemVer),
+			zap.String("flag-name", givenName),
+			zap.String("translated-name", nodeName),
+		)
+	}
 
 	configFilePath, err := writeConfigFile(dataDir, nodeConfig, flagsForAvagoVersion)
 	if err != nil {
@@ -1344,6 +2537,7 @@ func (ln *localNetwork) buildArgs(
 
 	return buildArgsReturn{
 		args:      args,
+		flags:     flagsForAvagoVersion,
 		publicIP:  publicIP,
 		apiPort:   apiPort,
 		p2pPort:   p2pPort,
@@ -1357,7 +2551,12 @@ func (ln *localNetwork) buildArgs(
 
 // Get AvalancheGo version
 func (ln *localNetwork) getNodeSemVer(nodeConfig node.Config) (string, error) {
-	nodeVersionOutput, err := ln.nodeProcessCreator.GetNodeVersion(nodeConfig)
+	return getNodeSemVer(ln.nodeProcessCreator, nodeConfig)
+}
+
+// Get AvalancheGo version of the binary of [nodeConfig], as reported by [npc]
+func getNodeSemVer(npc NodeProcessCreator, nodeConfig node.Config) (string, error) {
+	nodeVersionOutput, err := npc.GetNodeVersion(nodeConfig)
 	if err != nil {
 		return "", fmt.Errorf(
 			"couldn't get node version with binary %q: %w",
@@ -1376,9 +2575,14 @@ func (ln *localNetwork) getNodeSemVer(nodeConfig node.Config) (string, error) {
 	return nodeSemVer, nil
 }
 
-// ensure flags are compatible with the running avalanchego version
-func getFlagsForAvagoVersion(avagoVersion string, givenFlags map[string]string) map[string]string {
+// ensure flags are compatible with the running avalanchego version, by
+// giving renamed flags the name known to it: the old name to versions
+// previous to the rename, and the new name to the others.
+// Returns the flags, and the given name --> name passed to the node,
+// of each flag translated.
+func getFlagsForAvagoVersion(avagoVersion string, givenFlags map[string]string) (map[string]string, map[string]string) {
 	flags := maps.Clone(givenFlags)
+	translated := map[string]string{}
 	for _, deprecatedFlagInfo := range deprecatedFlagsSupport {
 		if semver.Compare(avagoVersion, deprecatedFlagInfo.Version) < 0 {
 			if v, ok := flags[deprecatedFlagInfo.NewName]; ok {
@@ -1387,12 +2591,28 @@ func getFlagsForAvagoVersion(avagoVersion string, givenFlags map[string]string)
 						v = filepath.Dir(strings.TrimSuffix(v, "/"))
 					}
 					flags[deprecatedFlagInfo.OldName] = v
+					translated[deprecatedFlagInfo.NewName] = deprecatedFlagInfo.OldName
 				}
 				delete(flags, deprecatedFlagInfo.NewName)
 			}
+			continue
+		}
+		v, ok := flags[deprecatedFlagInfo.OldName]
+		if !ok {
+			continue
+		}
+		delete(flags, deprecatedFlagInfo.OldName)
+		// the new name takes precedence if both are given
+		if _, ok := flags[deprecatedFlagInfo.NewName]; ok || v == "" {
+			continue
+		}
+		if deprecatedFlagInfo.ValueMap == "parent-dir" {
+			v = filepath.Join(v, "plugins")
 		}
+		flags[deprecatedFlagInfo.NewName] = v
+		translated[deprecatedFlagInfo.OldName] = deprecatedFlagInfo.NewName
 	}
-	return flags
+	return flags, translated
 }
 
 func (ln *localNetwork) GetRootDir() string {
@@ -3,13 +3,23 @@ package local
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -21,16 +31,30 @@ import (
 	"github.com/ava-labs/avalanche-network-runner/network/node"
 	"github.com/ava-labs/avalanche-network-runner/network/node/status"
 	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanche-network-runner/utils/constants"
+	avagoapi "github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/api/admin"
 	"github.com/ava-labs/avalanchego/api/health"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/api/keystore"
 	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/genesis"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/message"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
 	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/coreth/plugin/evm"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"golang.org/x/exp/maps"
 )
 
 const (
@@ -41,6 +65,7 @@ const (
 var (
 	_ NodeProcessCreator    = &localTestSuccessfulNodeProcessCreator{}
 	_ NodeProcessCreator    = &localTestFailedStartProcessCreator{}
+	_ NodeProcessCreator    = &localTestExitedProcessCreator{}
 	_ NodeProcessCreator    = &localTestProcessUndefNodeProcessCreator{}
 	_ NodeProcessCreator    = &localTestFlagCheckProcessCreator{}
 	_ api.NewAPIClientF     = newMockAPISuccessful
@@ -68,6 +93,59 @@ func (*localTestFailedStartProcessCreator) GetNodeVersion(_ node.Config) (string
 	return nodeVersion, nil
 }
 
+type localTestExitedProcessCreator struct{}
+
+func (*localTestExitedProcessCreator) NewNodeProcess(node.Config, time.Duration, ...string) (NodeProcess, error) {
+	process := &mocks.NodeProcess{}
+	exitedCh := make(chan struct{})
+	close(exitedCh)
+	process.On("Stop", mock.Anything).Return(0)
+	process.On("Status").Return(status.Stopped)
+	process.On("Done").Return((<-chan struct{})(exitedCh))
+	process.On("CrashReport").Return(nil)
+	return process, nil
+}
+
+func (*localTestExitedProcessCreator) GetNodeVersion(_ node.Config) (string, error) {
+	return nodeVersion, nil
+}
+
+// localTestExitingProcessCreator creates processes that are running until [exited] is set
+type localTestExitingProcessCreator struct {
+	exited atomic.Bool
+}
+
+func (lt *localTestExitingProcessCreator) NewNodeProcess(node.Config, time.Duration, ...string) (NodeProcess, error) {
+	return &localTestExitingProcess{exited: &lt.exited}, nil
+}
+
+func (*localTestExitingProcessCreator) GetNodeVersion(_ node.Config) (string, error) {
+	return nodeVersion, nil
+}
+
+type localTestExitingProcess struct {
+	exited *atomic.Bool
+}
+
+func (*localTestExitingProcess) Stop(context.Context) int {
+	return 0
+}
+
+func (lp *localTestExitingProcess) Status() status.Status {
+	if lp.exited.Load() {
+		return status.Stopped
+	}
+	return status.Running
+}
+
+func (*localTestExitingProcess) Done() <-chan struct{} {
+	return nil
+}
+
+func (*localTestExitingProcess) CrashReport() *node.CrashReport {
+	return nil
+}
+
 type localTestProcessUndefNodeProcessCreator struct{}
 
 func (*localTestProcessUndefNodeProcessCreator) NewNodeProcess(config node.Config, _ time.Duration, flags ...string) (NodeProcess, error) {
@@ -116,8 +194,13 @@ func newMockAPIUnhealthy(string, uint16) api.Client {
 	healthReply := &health.APIReply{Healthy: false}
 	healthClient := &healthmocks.Client{}
 	healthClient.On("Health", mock.Anything, mock.Anything).Return(healthReply, nil)
+	ethClient := &apimocks.EthClient{}
+	ethClient.On("Close").Return()
 	client := &apimocks.Client{}
 	client.On("HealthAPI").Return(healthClient)
+	// not connected to any beacon, checked once the nodes are unhealthy for long
+	client.On("InfoAPI").Return(beaconPeersInfoClient{})
+	client.On("CChainEthAPI").Return(ethClient)
 	return client
 }
 
@@ -131,6 +214,7 @@ func newMockProcessSuccessful(node.Config, ...string) (NodeProcess, error) {
 	process.On("Wait").Return(nil)
 	process.On("Stop", mock.Anything).Return(0)
 	process.On("Status").Return(status.Running)
+	process.On("Done").Return(nil)
 	return process, nil
 }
 
@@ -514,9 +598,14 @@ func TestUnhealthyNetwork(t *testing.T) {
 		false,
 	)
 	require.NoError(err)
+	clock := newFakeClock()
+	net.clock = clock
 	err = net.loadConfig(context.Background(), networkConfig)
 	require.NoError(err)
-	require.Error(awaitNetworkHealthy(net, defaultHealthyTimeout))
+	ctx, cancel := clock.withTimeout(defaultHealthyTimeout)
+	defer cancel()
+	require.Error(net.Healthy(ctx))
+	require.NoError(net.Stop(context.Background()))
 }
 
 // Create a network without giving names to nodes.
@@ -617,6 +706,49 @@ func TestGenerateDefaultNetwork(t *testing.T) {
 	}
 }
 
+func TestDefaultNodeIDs(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	nodeIDs, err := DefaultNodeIDs()
+	require.NoError(err)
+	expected := []string{
+		"NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg",
+		"NodeID-MFrZFVCXPv5iCn6M9K6XduxGTYp891xXZ",
+		"NodeID-NFBbbJ4qCmNaCzeW7sxErhvWqvEQMnYcN",
+		"NodeID-GWPcbFJZFfZreETSoWjPimr846mXEKCtu",
+		"NodeID-P7oB2McjBGgW2NXXWVYjV8JEDFoW9xDE5",
+	}
+	require.Len(nodeIDs, len(expected))
+	for i, nodeID := range nodeIDs {
+		require.Equal(expected[i], nodeID.String())
+	}
+}
+
+func TestNewDefaultConfigWithGenesisGenerator(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	var gotSpec network.GenesisSpec
+	generator := network.GenesisGeneratorFunc(func(spec network.GenesisSpec) ([]byte, error) {
+		gotSpec = spec
+		return []byte(`{"custom":true}`), nil
+	})
+	networkConfig, err := NewDefaultConfigWithGenesisGenerator("pepito", constants.DefaultNumNodes, 1337, generator, "", nil)
+	require.NoError(err)
+	require.Equal(`{"custom":true}`, networkConfig.Genesis)
+	require.EqualValues(1337, gotSpec.NetworkID)
+	nodeIDs, err := DefaultNodeIDs()
+	require.NoError(err)
+	require.Equal(nodeIDs, gotSpec.Validators)
+	require.Len(gotSpec.XChainBalances, 1)
+	require.Equal(genesis.EWOQKey.Address(), gotSpec.XChainBalances[0].Addr)
+	require.Len(gotSpec.CChainBalances, 1)
+	require.Zero(defaultCChainBalance.Cmp(gotSpec.CChainBalances[0].Balance))
+
+	networkConfig, err = NewDefaultConfigWithGenesisGenerator("pepito", constants.DefaultNumNodes, 1337, network.AvalancheGoGenesisGenerator{}, "", nil)
+	require.NoError(err)
+	require.Contains(networkConfig.Genesis, `"networkID":1337`)
+}
+
 // TODO add byzantine node to conf
 // TestNetworkFromConfig creates/waits/checks/stops a network from config file
 // the check verify that all the nodes can be accessed
@@ -739,7 +871,16 @@ func TestNodeNotFound(t *testing.T) {
 	require.Error(err)
 	// remove non-existent node
 	err = net.RemoveNode(context.Background(), networkConfig.NodeConfigs[1].Name)
-	require.Error(err)
+	require.ErrorIs(err, network.ErrNodeNotFound)
+	var nodeErr *network.NodeError
+	require.ErrorAs(err, &nodeErr)
+	require.Equal(networkConfig.NodeConfigs[1].Name, nodeErr.NodeName)
+	require.Equal("remove", nodeErr.Op)
+	// pause non-existent node
+	err = net.PauseNode(context.Background(), networkConfig.NodeConfigs[1].Name)
+	require.ErrorIs(err, network.ErrNodeNotFound)
+	require.ErrorAs(err, &nodeErr)
+	require.Equal("pause", nodeErr.Op)
 	// remove node
 	err = net.RemoveNode(context.Background(), networkConfig.NodeConfigs[0].Name)
 	require.NoError(err)
@@ -828,6 +969,14 @@ func TestGetAllNodes(t *testing.T) {
 	for name, node := range net.nodes {
 		require.EqualValues(node, nodes[name])
 	}
+
+	// the returned map is not changed by later node removals
+	require.NoError(net.RemoveNode(context.Background(), "node1"))
+	require.Len(nodes, 3)
+	require.Equal("node1", nodes["node1"].GetName())
+	nodes, err = net.GetAllNodes()
+	require.NoError(err)
+	require.Len(nodes, 2)
 }
 
 // TestFlags tests that we can pass flags through the network.Config
@@ -1339,7 +1488,7 @@ func TestWriteFiles(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			require := require.New(t)
-			flags, err := writeFiles(tt.genesis, nil, tmpDir, &tt.nodeConfig)
+			flags, err := writeFiles(tt.genesis, nil, nil, tmpDir, &tt.nodeConfig)
 			if tt.shouldErr {
 				require.Error(err)
 				return
@@ -1475,3 +1624,1822 @@ func TestHealthyDuringNetworkStop(t *testing.T) {
 		require.Fail("Healthy should've returned immediately because network closed")
 	}
 }
+
+// Assert that a call to Healthy that is waiting for the nodes
+// doesn't block other operations on the network.
+func TestHealthyDoesNotBlockNodeOps(t *testing.T) {
+	require := require.New(t)
+	// Calls to a node's Healthy() function blocks until context cancelled
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPIHealthyBlocks,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	healthyChan := make(chan error)
+	go func() {
+		healthyChan <- net.Healthy(ctx)
+	}()
+	// Wait to make sure we're actually blocking on Health API call
+	time.Sleep(500 * time.Millisecond)
+
+	opsDone := make(chan error)
+	go func() {
+		nodeConfig := testNetworkConfig(t).NodeConfigs[0]
+		nodeConfig.Name = "node3"
+		if _, err := net.AddNode(nodeConfig); err != nil {
+			opsDone <- err
+			return
+		}
+		if err := net.PauseNode(context.Background(), "node1"); err != nil {
+			opsDone <- err
+			return
+		}
+		opsDone <- net.RemoveNode(context.Background(), "node2")
+	}()
+	select {
+	case err := <-opsDone:
+		require.NoError(err)
+	case <-time.After(5 * time.Second):
+		require.Fail("node operations should not wait for Healthy to return")
+	}
+
+	select {
+	case <-healthyChan:
+		require.Fail("Healthy should still be waiting for the nodes")
+	default:
+	}
+	cancel()
+	require.Error(<-healthyChan)
+	require.NoError(net.Stop(context.Background()))
+}
+
+// TestReconcile checks that nodes missing from the desired config are removed,
+// new ones are added, and only the nodes whose config changed are restarted
+func TestReconcile(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	node0Process := net.nodes["node0"].process
+	node1Process := net.nodes["node1"].process
+
+	desired := networkConfig
+	desired.NodeConfigs = []node.Config{
+		networkConfig.NodeConfigs[0],
+		networkConfig.NodeConfigs[1],
+		{Name: "node3"},
+	}
+	desired.NodeConfigs[1].Flags = maps.Clone(desired.NodeConfigs[1].Flags)
+	desired.NodeConfigs[1].Flags[config.LogLevelKey] = "debug"
+	require.NoError(net.Reconcile(context.Background(), desired))
+
+	names, err := net.GetNodeNames()
+	require.NoError(err)
+	require.ElementsMatch([]string{"node0", "node1", "node3"}, names)
+	require.Same(node0Process, net.nodes["node0"].process)
+	require.NotSame(node1Process, net.nodes["node1"].process)
+	require.Equal("debug", net.nodes["node1"].config.Flags[config.LogLevelKey])
+	require.Equal(networkConfig.NodeConfigs[1].StakingCert, net.nodes["node1"].config.StakingCert)
+
+	// reconciling again against the same config is a no-op
+	node1Process = net.nodes["node1"].process
+	require.NoError(net.Reconcile(context.Background(), desired))
+	require.Same(node0Process, net.nodes["node0"].process)
+	require.Same(node1Process, net.nodes["node1"].process)
+
+	// network ID and genesis can't be changed
+	changedNetworkID := desired
+	changedNetworkID.NetworkID++
+	require.Error(net.Reconcile(context.Background(), changedNetworkID))
+	var genesis map[string]interface{}
+	require.NoError(json.Unmarshal([]byte(desired.Genesis), &genesis))
+	genesis["message"] = "changed"
+	changedGenesis := desired
+	genesisBytes, err := json.Marshal(genesis)
+	require.NoError(err)
+	changedGenesis.Genesis = string(genesisBytes)
+	require.Error(net.Reconcile(context.Background(), changedGenesis))
+
+	// on failure, network defaults are kept
+	errHook := errors.New("hook error")
+	net.RegisterNodeHooks(network.NodeHooks{
+		OnBeforeNodeStart: func(nodeConfig node.Config) error {
+			if nodeConfig.Name == "node4" {
+				return errHook
+			}
+			return nil
+		},
+	})
+	flags := net.flags
+	failing := desired
+	failing.Flags = map[string]interface{}{config.LogLevelKey: "info"}
+	failing.NodeConfigs = append(slices.Clone(desired.NodeConfigs), node.Config{Name: "node4"})
+	require.ErrorIs(net.Reconcile(context.Background(), failing), errHook)
+	require.Equal(flags, net.flags)
+
+	// unnamed node configs can't be reconciled
+	desired.NodeConfigs = append(desired.NodeConfigs, node.Config{})
+	require.Error(net.Reconcile(context.Background(), desired))
+}
+
+// fakeAdminClient records the chain aliases registered on it
+type fakeAdminClient struct {
+	admin.Client
+	lock    sync.Mutex
+	aliases map[string][]string
+	// logger name, log level and display level given to SetLoggerLevel
+	loggerLevels []string
+	// returned by GetConfig
+	nodeConfig interface{}
+}
+
+func (c *fakeAdminClient) AliasChain(_ context.Context, chainID string, alias string, _ ...rpc.Option) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.aliases[chainID] = append(c.aliases[chainID], alias)
+	return nil
+}
+
+func (c *fakeAdminClient) SetLoggerLevel(_ context.Context, loggerName, logLevel, displayLevel string, _ ...rpc.Option) (map[string]admin.LogAndDisplayLevels, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.loggerLevels = append(c.loggerLevels, loggerName+":"+logLevel+":"+displayLevel)
+	return nil, nil
+}
+
+func (c *fakeAdminClient) GetConfig(context.Context, ...rpc.Option) (interface{}, error) {
+	return c.nodeConfig, nil
+}
+
+func TestConsensusParameters(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	// the node config as decoded by the admin client
+	var nodeConfig interface{}
+	require.NoError(json.Unmarshal([]byte(`{
+		"subnetConfigs": {
+			"11111111111111111111111111111111LpoYY": {
+				"consensusParameters": {
+					"k": 5,
+					"alphaPreference": 4,
+					"alphaConfidence": 4,
+					"beta": 3,
+					"concurrentRepolls": 2,
+					"optimalProcessing": 10,
+					"maxOutstandingItems": 256,
+					"maxItemProcessingTime": 30000000000
+				}
+			}
+		}
+	}`), &nodeConfig))
+	newAPIClientF := func(ip string, port uint16) api.Client {
+		client := newMockAPISuccessful(ip, port).(*apimocks.Client)
+		client.On("AdminAPI").Return(&fakeAdminClient{nodeConfig: nodeConfig})
+		return client
+	}
+	net, err := newNetwork(logging.NoLog{}, newAPIClientF, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+
+	node, err := net.GetNode("node0")
+	require.NoError(err)
+	parameters, err := node.ConsensusParameters(context.Background())
+	require.NoError(err)
+	require.Equal(snowball.Parameters{
+		K:                     5,
+		AlphaPreference:       4,
+		AlphaConfidence:       4,
+		Beta:                  3,
+		ConcurrentRepolls:     2,
+		OptimalProcessing:     10,
+		MaxOutstandingItems:   256,
+		MaxItemProcessingTime: 30 * time.Second,
+	}, parameters)
+	networkParameters, err := network.CheckConsensusParameters(context.Background(), net)
+	require.NoError(err)
+	require.Equal(parameters, networkParameters)
+
+	_, err = primaryNetworkConsensusParameters(map[string]interface{}{"subnetConfigs": map[string]interface{}{}})
+	require.ErrorIs(err, errNoConsensusParameters)
+}
+
+func TestSetLogLevel(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	adminClients := []*fakeAdminClient{}
+	newAPIClientF := func(ip string, port uint16) api.Client {
+		adminClient := &fakeAdminClient{}
+		adminClients = append(adminClients, adminClient)
+		client := newMockAPISuccessful(ip, port).(*apimocks.Client)
+		client.On("AdminAPI").Return(adminClient)
+		return client
+	}
+	net, err := newNetwork(logging.NoLog{}, newAPIClientF, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+
+	require.Error(net.SetLogLevel(context.Background(), "loud"))
+	require.NoError(net.SetLogLevel(context.Background(), "debug"))
+	require.Len(adminClients, 3)
+	for _, adminClient := range adminClients {
+		require.Equal([]string{":debug:debug"}, adminClient.loggerLevels)
+	}
+	node, err := net.GetNode("node0")
+	require.NoError(err)
+	require.NoError(node.SetLogLevel(context.Background(), "info"))
+	require.NoError(net.Stop(context.Background()))
+	require.ErrorIs(net.SetLogLevel(context.Background(), "debug"), network.ErrStopped)
+}
+
+func TestAliasBlockchain(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	adminClients := []*fakeAdminClient{}
+	newAPIClientF := func(ip string, port uint16) api.Client {
+		adminClient := &fakeAdminClient{aliases: map[string][]string{}}
+		adminClients = append(adminClients, adminClient)
+		client := newMockAPISuccessful(ip, port).(*apimocks.Client)
+		client.On("AdminAPI").Return(adminClient)
+		return client
+	}
+	networkConfig := testNetworkConfig(t)
+	net, err := newNetwork(
+		logging.NoLog{},
+		newAPIClientF,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	blockchainID := ids.GenerateTestID()
+	require.Error(net.AliasBlockchain(context.Background(), blockchainID, ""))
+	require.NoError(net.AliasBlockchain(context.Background(), blockchainID, "mychain"))
+	require.NoError(net.AliasBlockchain(context.Background(), blockchainID, "mychain"))
+	aliases, err := net.GetBlockchainAliases(blockchainID)
+	require.NoError(err)
+	require.Equal([]string{"mychain"}, aliases)
+	require.Len(adminClients, len(networkConfig.NodeConfigs))
+	for _, adminClient := range adminClients {
+		require.Equal([]string{"mychain"}, adminClient.aliases[blockchainID.String()])
+	}
+	aliases, err = net.GetBlockchainAliases(ids.GenerateTestID())
+	require.NoError(err)
+	require.Empty(aliases)
+
+	// restarted nodes register the aliases again once healthy
+	require.NoError(net.RestartNode(context.Background(), "node1", "", "", "", nil, nil, nil))
+	require.NoError(net.Healthy(context.Background()))
+	require.Len(adminClients, len(networkConfig.NodeConfigs)+1)
+	restartedAdminClient := adminClients[len(adminClients)-1]
+	restartedAdminClient.lock.Lock()
+	defer restartedAdminClient.lock.Unlock()
+	require.Equal([]string{"mychain"}, restartedAdminClient.aliases[blockchainID.String()])
+}
+
+func TestAliasVM(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	node0Process := net.nodes["node0"].process
+
+	vmID := ids.GenerateTestID()
+	require.Error(net.AliasVM(context.Background(), vmID, ""))
+	require.NoError(net.AliasVM(context.Background(), vmID, "myvm"))
+	require.Error(net.AliasVM(context.Background(), ids.GenerateTestID(), "myvm"))
+	aliases, err := net.GetVMAliases(vmID)
+	require.NoError(err)
+	require.Equal([]string{"myvm"}, aliases)
+
+	// nodes are restarted with the aliases file
+	require.NotSame(node0Process, net.nodes["node0"].process)
+	for _, node := range net.nodes {
+		vmAliasesBytes, err := os.ReadFile(filepath.Join(node.GetDataDir(), configsPath, vmAliasesFileName))
+		require.NoError(err)
+		vmAliases := map[string][]string{}
+		require.NoError(json.Unmarshal(vmAliasesBytes, &vmAliases))
+		require.Equal(map[string][]string{vmID.String(): {"myvm"}}, vmAliases)
+	}
+}
+
+// fakeKeystore records the keystore calls of a node, through the clients below
+type fakeKeystore struct {
+	lock  sync.Mutex
+	users map[string]string
+	xKeys map[string][]*secp256k1.PrivateKey
+	cKeys map[string][]*secp256k1.PrivateKey
+	// if set, called on each user creation
+	onCreateUser func()
+}
+
+type fakeKeystoreClient struct {
+	keystore.Client
+	ks *fakeKeystore
+}
+
+func (c *fakeKeystoreClient) CreateUser(_ context.Context, user avagoapi.UserPass, _ ...rpc.Option) error {
+	if c.ks.onCreateUser != nil {
+		c.ks.onCreateUser()
+	}
+	c.ks.lock.Lock()
+	defer c.ks.lock.Unlock()
+	if _, ok := c.ks.users[user.Username]; ok {
+		return errors.New("user already exists")
+	}
+	c.ks.users[user.Username] = user.Password
+	return nil
+}
+
+type fakeXChainClient struct {
+	avm.Client
+	ks *fakeKeystore
+}
+
+func (c *fakeXChainClient) ImportKey(_ context.Context, user avagoapi.UserPass, privateKey *secp256k1.PrivateKey, _ ...rpc.Option) (ids.ShortID, error) {
+	c.ks.lock.Lock()
+	defer c.ks.lock.Unlock()
+	c.ks.xKeys[user.Username] = append(c.ks.xKeys[user.Username], privateKey)
+	return ids.ShortEmpty, nil
+}
+
+type fakeCChainClient struct {
+	evm.Client
+	ks *fakeKeystore
+}
+
+func (c *fakeCChainClient) ImportKey(_ context.Context, user avagoapi.UserPass, privateKey *secp256k1.PrivateKey, _ ...rpc.Option) (common.Address, error) {
+	c.ks.lock.Lock()
+	defer c.ks.lock.Unlock()
+	c.ks.cKeys[user.Username] = append(c.ks.cKeys[user.Username], privateKey)
+	return common.Address{}, nil
+}
+
+func TestCreateKeystoreUser(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	keystores := map[uint16]*fakeKeystore{}
+	newAPIClientF := func(ip string, port uint16) api.Client {
+		ks := &fakeKeystore{
+			users: map[string]string{},
+			xKeys: map[string][]*secp256k1.PrivateKey{},
+			cKeys: map[string][]*secp256k1.PrivateKey{},
+		}
+		keystores[port] = ks
+		client := newMockAPISuccessful(ip, port).(*apimocks.Client)
+		client.On("KeystoreAPI").Return(&fakeKeystoreClient{ks: ks})
+		client.On("XChainAPI").Return(&fakeXChainClient{ks: ks})
+		client.On("CChainAPI").Return(&fakeCChainClient{ks: ks})
+		return client
+	}
+	net, err := newNetwork(
+		logging.NoLog{},
+		newAPIClientF,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	networkConfig := testNetworkConfig(t)
+	for i := range networkConfig.NodeConfigs {
+		networkConfig.NodeConfigs[i].Flags[config.HTTPPortKey] = 9650 + 2*i
+	}
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	key := genesis.EWOQKey
+	require.NoError(net.CreateKeystoreUser(context.Background(), []string{"node0", "node1"}, "user", "pass", key))
+	for nodeName, node := range net.nodes {
+		ks := keystores[node.GetAPIPort()]
+		if nodeName == "node2" {
+			require.Empty(ks.users)
+			continue
+		}
+		require.Equal(map[string]string{"user": "pass"}, ks.users)
+		require.Equal([]*secp256k1.PrivateKey{key}, ks.xKeys["user"])
+		require.Equal([]*secp256k1.PrivateKey{key}, ks.cKeys["user"])
+	}
+	// all nodes by default, already existing user fails
+	require.Error(net.CreateKeystoreUser(context.Background(), nil, "user", "pass"))
+	require.NoError(net.CreateKeystoreUser(context.Background(), nil, "user2", "pass"))
+	for _, ks := range keystores {
+		require.Contains(ks.users, "user2")
+	}
+	require.Error(net.CreateKeystoreUser(context.Background(), []string{"unknown"}, "user3", "pass"))
+
+	// the network lock is not held while the users are created
+	keystores[net.nodes["node0"].GetAPIPort()].onCreateUser = func() {
+		net.RegisterNodeHooks(network.NodeHooks{})
+	}
+	require.NoError(net.CreateKeystoreUser(context.Background(), []string{"node0"}, "user4", "pass"))
+}
+
+func TestRemapPorts(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	// reserve two free ports and check they are used as the remapped ones
+	apiListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	defer apiListener.Close()
+	stakingListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	defer stakingListener.Close()
+	apiPort := apiListener.Addr().(*net.TCPAddr).Port
+	stakingPort := stakingListener.Addr().(*net.TCPAddr).Port
+	flags := map[string]interface{}{
+		config.HTTPPortKey:    float64(apiPort - portRemapOffset),
+		config.StakingPortKey: stakingPort - portRemapOffset,
+	}
+	// remapped ports are in use
+	remapped, err := remapPorts(flags)
+	require.NoError(err)
+	require.False(remapped)
+	require.NoError(apiListener.Close())
+	remapped, err = remapPorts(flags)
+	require.NoError(err)
+	require.False(remapped)
+	require.Equal(float64(apiPort-portRemapOffset), flags[config.HTTPPortKey])
+	require.Equal(stakingPort-portRemapOffset, flags[config.StakingPortKey])
+	require.NoError(stakingListener.Close())
+	remapped, err = remapPorts(flags)
+	require.NoError(err)
+	require.True(remapped)
+	require.Equal(apiPort, flags[config.HTTPPortKey])
+	require.Equal(stakingPort, flags[config.StakingPortKey])
+	// ports not given
+	remapped, err = remapPorts(map[string]interface{}{config.HTTPPortKey: 9650})
+	require.NoError(err)
+	require.False(remapped)
+	// out of range
+	remapped, err = remapPorts(map[string]interface{}{
+		config.HTTPPortKey:    math.MaxUint16 - 1,
+		config.StakingPortKey: math.MaxUint16,
+	})
+	require.NoError(err)
+	require.False(remapped)
+}
+
+// TestWait checks that Wait returns when the network is stopped,
+// or when all its nodes exited
+func TestWait(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	newTestNetwork := func(nodeProcessCreator NodeProcessCreator) *localNetwork {
+		net, err := newNetwork(
+			logging.NoLog{},
+			newMockAPISuccessful,
+			nodeProcessCreator,
+			"",
+			"",
+			"",
+			false,
+			false,
+			false,
+			"",
+			beacon.NewSet(),
+			false,
+		)
+		require.NoError(err)
+		require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+		return net
+	}
+
+	net := newTestNetwork(&localTestSuccessfulNodeProcessCreator{})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(net.Wait(ctx), context.DeadlineExceeded)
+	waitCh := make(chan error)
+	go func() {
+		waitCh <- net.Wait(context.Background())
+	}()
+	require.NoError(net.Stop(context.Background()))
+	require.NoError(<-waitCh)
+
+	net = newTestNetwork(&localTestExitedProcessCreator{})
+	require.ErrorIs(net.Wait(context.Background()), network.ErrNodesExited)
+
+	// nodes exiting while waiting are detected by polling
+	exitingProcessCreator := &localTestExitingProcessCreator{}
+	net = newTestNetwork(exitingProcessCreator)
+	go func() {
+		waitCh <- net.Wait(context.Background())
+	}()
+	exitingProcessCreator.exited.Store(true)
+	require.ErrorIs(<-waitCh, network.ErrNodesExited)
+
+	// saving a snapshot stops the nodes without calling Stop
+	net = newTestNetwork(&localTestSuccessfulNodeProcessCreator{})
+	net.snapshotsDir = t.TempDir()
+	go func() {
+		waitCh <- net.Wait(context.Background())
+	}()
+	_, err := net.SaveSnapshot(context.Background(), "wait", "", false)
+	require.NoError(err)
+	require.NoError(<-waitCh)
+}
+
+// TestNodeHooks checks that lifecycle hooks are called on node start, health and stop,
+// and that a failing start hook prevents the node from being added
+func TestNodeHooks(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	var (
+		lock    sync.Mutex
+		started []string
+		healthy []string
+		stopped []string
+	)
+	errHook := errors.New("hook error")
+	net.RegisterNodeHooks(network.NodeHooks{
+		OnBeforeNodeStart: func(nodeConfig node.Config) error {
+			if nodeConfig.Name == "rejected" {
+				return errHook
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			started = append(started, nodeConfig.Name)
+			return nil
+		},
+		OnAfterNodeHealthy: func(node node.Node) {
+			lock.Lock()
+			defer lock.Unlock()
+			healthy = append(healthy, node.GetName())
+		},
+		OnNodeStop: func(node node.Node) {
+			lock.Lock()
+			defer lock.Unlock()
+			stopped = append(stopped, node.GetName())
+		},
+	})
+	networkConfig := testNetworkConfig(t)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	require.ElementsMatch([]string{"node0", "node1", "node2"}, started)
+
+	// the nodes are seen healthy by their background health polls
+	require.Eventually(func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(healthy) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(net.Healthy(context.Background()))
+	require.NoError(net.Healthy(context.Background()))
+	lock.Lock()
+	require.ElementsMatch([]string{"node0", "node1", "node2"}, healthy)
+	lock.Unlock()
+
+	require.NoError(net.RemoveNode(context.Background(), "node1"))
+	require.Equal([]string{"node1"}, stopped)
+
+	_, err = net.AddNode(node.Config{Name: "rejected"})
+	require.ErrorIs(err, errHook)
+	_, err = net.GetNode("rejected")
+	require.Error(err)
+}
+
+// TestDelayedNodeStart checks that nodes with a start delay are added
+// to the network only after the delay, and not at all if it is stopped before
+func TestDelayedNodeStart(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs[1].IsBeacon = false
+	networkConfig.NodeConfigs[1].StartDelay = 100 * time.Millisecond
+	networkConfig.NodeConfigs[2].IsBeacon = false
+	networkConfig.NodeConfigs[2].StartDelay = time.Hour
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	names, err := net.GetNodeNames()
+	require.NoError(err)
+	require.Equal([]string{"node0"}, names)
+	require.Eventually(func() bool {
+		_, err := net.GetNode("node1")
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	node1, err := net.GetNode("node1")
+	require.NoError(err)
+	require.Zero(node1.GetConfig().StartDelay)
+	// delays are only supported on creation, and pending names are taken
+	_, err = net.AddNode(node.Config{Name: "node3", StartDelay: time.Second})
+	require.ErrorIs(err, network.ErrStartDelay)
+	_, err = net.AddNode(node.Config{Name: "node2"})
+	require.Error(err)
+	require.Error(net.Reconcile(context.Background(), networkConfig))
+	// the pending node is persisted with its remaining delay
+	net.lock.Lock()
+	require.NoError(net.persistNetwork())
+	networkConfigJSON, err := os.ReadFile(filepath.Join(net.rootDir, "network.json"))
+	require.NoError(err)
+	var persistedConfig network.Config
+	require.NoError(json.Unmarshal(networkConfigJSON, &persistedConfig))
+	require.Len(persistedConfig.NodeConfigs, 3)
+	for _, nodeConfig := range persistedConfig.NodeConfigs {
+		if nodeConfig.Name == "node2" {
+			require.Positive(nodeConfig.StartDelay)
+			require.LessOrEqual(nodeConfig.StartDelay, time.Hour)
+		} else {
+			require.Zero(nodeConfig.StartDelay)
+		}
+	}
+	// the internal stop used by SaveSnapshot cancels the pending start
+	require.NoError(net.stop(context.Background()))
+	require.Empty(net.delayedNodes)
+	net.lock.Unlock()
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestDelayedBeaconNode(t *testing.T) {
+	t.Parallel()
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs[0].StartDelay = time.Second
+	require.Error(t, networkConfig.Validate())
+}
+
+func TestAwaitMetric(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	var processing atomic.Int64
+	processing.Store(2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, "# TYPE avalanche_processing gauge\n")
+		fmt.Fprintf(w, "avalanche_processing{chain=\"P\"} %d\n", processing.Load())
+		fmt.Fprintf(w, "avalanche_processing{chain=\"X\"} 1\n")
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(err)
+
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs = networkConfig.NodeConfigs[:1]
+	networkConfig.NodeConfigs[0].Flags[config.HTTPPortKey] = port
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	value, err := getMetricValue(context.Background(), server.URL, "avalanche_processing")
+	require.NoError(err)
+	require.Equal(float64(3), value)
+	_, err = getMetricValue(context.Background(), server.URL, "avalanche_unknown")
+	require.Error(err)
+	value, err = getMetricValue(context.Background(), server.URL, `avalanche_processing{chain="X"}`)
+	require.NoError(err)
+	require.Equal(float64(1), value)
+	_, err = getMetricValue(context.Background(), server.URL, `avalanche_processing{chain="C"}`)
+	require.Error(err)
+	require.Error(net.AwaitMetric(context.Background(), `avalanche_processing{chain=C}`, func(float64) bool { return true }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.Error(net.AwaitMetric(ctx, "avalanche_processing", func(v float64) bool { return v == 1 }))
+
+	processing.Store(0)
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(net.AwaitMetric(ctx, "avalanche_processing", func(v float64) bool { return v == 1 }))
+
+	// the last scrape error is reported on timeout
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = net.AwaitMetric(ctx, "avalanche_unknown", func(float64) bool { return true })
+	require.ErrorIs(err, context.DeadlineExceeded)
+	require.ErrorContains(err, "not found")
+
+	// metrics of unsupported type fail without waiting for the timeout
+	histogramServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, "# TYPE avalanche_latency histogram\n")
+		fmt.Fprintf(w, "avalanche_latency_bucket{le=\"+Inf\"} 1\n")
+		fmt.Fprintf(w, "avalanche_latency_sum 1\n")
+		fmt.Fprintf(w, "avalanche_latency_count 1\n")
+	}))
+	defer histogramServer.Close()
+	_, err = getMetricValue(context.Background(), histogramServer.URL, "avalanche_latency")
+	require.ErrorIs(err, errUnsupportedMetricType)
+
+	values, err := net.GetMetric(context.Background(), `avalanche_processing{chain="X"}`)
+	require.NoError(err)
+	require.Equal(map[string]float64{"node0": 1}, values)
+	_, err = net.GetMetric(context.Background(), "avalanche_unknown")
+	var nodeErr *network.NodeError
+	require.ErrorAs(err, &nodeErr)
+	require.Equal("node0", nodeErr.NodeName)
+
+	// with no running nodes there is nothing to wait for
+	require.NoError(net.PauseNode(context.Background(), "node0"))
+	require.ErrorIs(net.AwaitMetric(context.Background(), "avalanche_processing", func(float64) bool { return true }), network.ErrNoRunningNodes)
+	_, err = net.GetMetric(context.Background(), "avalanche_processing")
+	require.ErrorIs(err, network.ErrNoRunningNodes)
+}
+
+func TestNodeStartTime(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	beforeStart := time.Now()
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	node, err := net.GetNode("node0")
+	require.NoError(err)
+	startTime := node.StartTime()
+	require.False(startTime.Before(beforeStart))
+	require.Eventually(func() bool { return node.Uptime() > 0 }, time.Second, time.Millisecond)
+
+	require.NoError(net.PauseNode(context.Background(), "node0"))
+	require.True(node.StartTime().IsZero())
+	require.Zero(node.Uptime())
+
+	// a resumed node gets a new process
+	require.NoError(net.ResumeNode(context.Background(), "node0"))
+	node, err = net.GetNode("node0")
+	require.NoError(err)
+	require.True(node.StartTime().After(startTime))
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestNodeStopStart(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	node, err := net.GetNode("node0")
+	require.NoError(err)
+
+	require.NoError(node.Stop(context.Background()))
+	require.True(node.GetPaused())
+	require.Error(node.Stop(context.Background()))
+
+	// the handle refers to the new process of the node
+	require.NoError(node.Start(context.Background()))
+	require.Error(node.Start(context.Background()))
+	require.False(node.GetPaused())
+	require.Equal(status.Running, node.Status())
+	current, err := net.GetNode("node0")
+	require.NoError(err)
+	require.Equal(current.GetAPIClient(), node.GetAPIClient())
+	require.NoError(node.AwaitHealthy(context.Background()))
+	require.NoError(node.Stop(context.Background()))
+	require.True(current.GetPaused())
+	require.NoError(node.Start(context.Background()))
+
+	// the handle of a removed node doesn't refer to a new one with the same name
+	nodeConfig := node.GetConfig()
+	require.NoError(net.RemoveNode(context.Background(), "node0"))
+	_, err = net.AddNode(nodeConfig)
+	require.NoError(err)
+	require.ErrorIs(node.Stop(context.Background()), network.ErrNodeNotFound)
+	added, err := net.GetNode("node0")
+	require.NoError(err)
+	require.False(added.GetPaused())
+
+	require.NoError(net.Stop(context.Background()))
+	require.ErrorIs(node.Stop(context.Background()), network.ErrStopped)
+}
+
+func TestNodeGetEffectiveConfig(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	n, err := net.AddNode(node.Config{
+		Name: "node3",
+		Flags: map[string]interface{}{
+			config.LogLevelKey:             "debug",
+			config.StakingTLSKeyContentKey: "secret",
+		},
+	})
+	require.NoError(err)
+
+	effectiveConfig := n.GetEffectiveConfig()
+	require.Equal("node3", effectiveConfig.Name)
+	require.Equal(n.GetNodeID(), effectiveConfig.NodeID)
+	require.Equal(n.GetAPIPort(), effectiveConfig.APIPort)
+	require.Equal(n.GetDataDir(), effectiveConfig.DataDir)
+	require.Equal("debug", effectiveConfig.Flags[config.LogLevelKey])
+	require.Equal(node.Redacted, effectiveConfig.Flags[config.StakingTLSKeyContentKey])
+	// flags set by the network are included
+	require.Equal(fmt.Sprintf("%d", n.GetAPIPort()), effectiveConfig.Flags[config.HTTPPortKey])
+	require.NotEmpty(effectiveConfig.Flags[config.BootstrapIDsKey])
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestNodeDBSeedDir(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	seedDir := t.TempDir()
+	require.NoError(os.WriteFile(filepath.Join(seedDir, "state"), []byte("height 100"), 0o600))
+
+	copied, err := net.AddNode(node.Config{Name: "copied", DBSeedDir: seedDir})
+	require.NoError(err)
+	state, err := os.ReadFile(filepath.Join(copied.GetDbDir(), "state"))
+	require.NoError(err)
+	require.Equal("height 100", string(state))
+
+	linked, err := net.AddNode(node.Config{Name: "linked", DBSeedDir: seedDir, DBSeedSymlink: true})
+	require.NoError(err)
+	info, err := os.Lstat(linked.GetDbDir())
+	require.NoError(err)
+	require.Equal(os.ModeSymlink, info.Mode()&os.ModeSymlink)
+
+	// the db is only seeded before the first start
+	require.NoError(os.WriteFile(filepath.Join(copied.GetDbDir(), "state"), []byte("height 101"), 0o600))
+	require.NoError(net.RestartNode(context.Background(), "copied", "", "", "", nil, nil, nil))
+	state, err = os.ReadFile(filepath.Join(copied.GetDbDir(), "state"))
+	require.NoError(err)
+	require.Equal("height 101", string(state))
+
+	_, err = net.AddNode(node.Config{Name: "missing", DBSeedDir: filepath.Join(seedDir, "missing")})
+	require.Error(err)
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestNodeExec(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	n, err := net.GetNode("node0")
+	require.NoError(err)
+
+	out, err := n.Exec(context.Background(), "sh", "-c", "echo $"+node.ExecNodeNameEnv+" $"+node.ExecDataDirEnv+"; pwd")
+	require.NoError(err)
+	require.Equal(fmt.Sprintf("node0 %s\n%s\n", n.GetDataDir(), n.GetDataDir()), string(out))
+
+	out, err = n.Exec(context.Background(), "sh", "-c", "echo failure; exit 1")
+	require.Error(err)
+	require.Equal("failure\n", string(out))
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestAwaitNodeHealthy(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	net, err := newNetwork(
+		logging.NoLog{},
+		newMockAPISuccessful,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+
+	require.NoError(net.AwaitNodeHealthy(context.Background(), "node0"))
+	require.ErrorIs(net.AwaitNodeHealthy(context.Background(), "node3"), network.ErrNodeNotFound)
+	node, err := net.GetNode("node1")
+	require.NoError(err)
+	require.NoError(node.AwaitHealthy(context.Background()))
+
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	require.Error(net.AwaitNodeHealthy(context.Background(), "node1"))
+	require.Error(node.AwaitHealthy(context.Background()))
+
+	require.NoError(net.Stop(context.Background()))
+	require.ErrorIs(net.AwaitNodeHealthy(context.Background(), "node0"), network.ErrStopped)
+}
+
+// fakePChainClient returns the nodes of [net] as primary network
+// validators with the same weight
+type fakePChainClient struct {
+	platformvm.Client
+	net **localNetwork
+}
+
+func (c *fakePChainClient) GetCurrentValidators(context.Context, ids.ID, []ids.NodeID, ...rpc.Option) ([]platformvm.ClientPermissionlessValidator, error) {
+	vdrs := []platformvm.ClientPermissionlessValidator{}
+	for _, node := range (*c.net).nodes {
+		vdrs = append(vdrs, platformvm.ClientPermissionlessValidator{
+			ClientStaker: platformvm.ClientStaker{NodeID: node.nodeID, Weight: 1},
+		})
+	}
+	return vdrs, nil
+}
+
+func TestRemoveNodeWithOptions(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	var net *localNetwork
+	newAPIClientF := func(ip string, port uint16) api.Client {
+		client := newMockAPISuccessful(ip, port).(*apimocks.Client)
+		client.On("PChainAPI").Return(&fakePChainClient{net: &net})
+		return client
+	}
+	net, err := newNetwork(
+		logging.NoLog{},
+		newAPIClientF,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	networkConfig := testNetworkConfig(t)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	// 2 of 3 validators left is below the 75% needed by default
+	err = net.RemoveNodeWithOptions(context.Background(), "node0", network.RemoveNodeOptions{})
+	require.ErrorIs(err, network.ErrQuorumLoss)
+	_, err = net.GetNode("node0")
+	require.NoError(err)
+
+	// paused validators are already disconnected
+	require.NoError(net.PauseNode(context.Background(), "node2"))
+	require.NoError(net.RemoveNodeWithOptions(context.Background(), "node2", network.RemoveNodeOptions{}))
+
+	// nodes whose API calls don't finish in time aren't removed
+	require.True(net.nodes["node1"].beginCall())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	err = net.RemoveNodeWithOptions(ctx, "node1", network.RemoveNodeOptions{Force: true, DrainFirst: true})
+	cancel()
+	require.ErrorIs(err, context.DeadlineExceeded)
+	require.True(net.nodes["node1"].beginCall())
+	net.nodes["node1"].endCall()
+	net.nodes["node1"].endCall()
+
+	// drained nodes don't get new API calls
+	node, err := net.GetNode("node1")
+	require.NoError(err)
+	require.NoError(net.RemoveNodeWithOptions(context.Background(), "node1", network.RemoveNodeOptions{Force: true, DrainFirst: true}))
+	require.ErrorIs(node.CreateKeystoreUser(context.Background(), "user", "pass"), errNodeDrained)
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestQuorumGuard(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	var net *localNetwork
+	newAPIClientF := func(ip string, port uint16) api.Client {
+		client := newMockAPISuccessful(ip, port).(*apimocks.Client)
+		client.On("PChainAPI").Return(&fakePChainClient{net: &net})
+		return client
+	}
+	net, err := newNetwork(
+		logging.NoLog{},
+		newAPIClientF,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	networkConfig := testNetworkConfig(t)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	require.Error(net.SetQuorumGuard(1.5))
+	require.NoError(net.SetQuorumGuard(0.5))
+
+	// 1 of 3 validators stopped is allowed, 2 of 3 is not
+	require.NoError(net.PauseNode(context.Background(), "node0"))
+	err = net.PauseNode(context.Background(), "node1")
+	require.ErrorIs(err, network.ErrQuorumLoss)
+	var nodeErr *network.NodeError
+	require.ErrorAs(err, &nodeErr)
+	require.Equal("node1", nodeErr.NodeName)
+	require.False(net.nodes["node1"].paused)
+	node1Process := net.nodes["node1"].process
+	require.ErrorIs(net.RestartNode(context.Background(), "node1", "", "", "", nil, nil, nil), network.ErrQuorumLoss)
+	require.Same(node1Process, net.nodes["node1"].process)
+	require.ErrorIs(net.RemoveNode(context.Background(), "node1"), network.ErrQuorumLoss)
+
+	// restarting a paused node doesn't stop anything more
+	require.NoError(net.RestartNode(context.Background(), "node0", "", "", "", nil, nil, nil))
+
+	// removing node2 together with restarting node1 stops 2 of 3 validators
+	desired := networkConfig
+	desired.NodeConfigs = []node.Config{
+		networkConfig.NodeConfigs[0],
+		networkConfig.NodeConfigs[1],
+	}
+	desired.NodeConfigs[1].Flags = maps.Clone(desired.NodeConfigs[1].Flags)
+	desired.NodeConfigs[1].Flags[config.LogLevelKey] = "debug"
+	require.ErrorIs(net.Reconcile(context.Background(), desired), network.ErrQuorumLoss)
+	names, err := net.GetNodeNames()
+	require.NoError(err)
+	require.Len(names, 3)
+
+	// the guard is bypassed when forced or disabled
+	require.NoError(net.RemoveNodeWithOptions(context.Background(), "node2", network.RemoveNodeOptions{Force: true}))
+	require.NoError(net.SetQuorumGuard(0))
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	require.NoError(net.Stop(context.Background()))
+}
+
+// TestNodeDependsOn checks that nodes start after their dependencies are
+// healthy, and that the network isn't created if they never are.
+func TestNodeDependsOn(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	newTestNetwork := func(newAPIClient api.NewAPIClientF) *localNetwork {
+		net, err := newNetwork(
+			logging.NoLog{},
+			newAPIClient,
+			&localTestSuccessfulNodeProcessCreator{},
+			"",
+			"",
+			"",
+			false,
+			false,
+			false,
+			"",
+			beacon.NewSet(),
+			false,
+		)
+		require.NoError(err)
+		return net
+	}
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs[1].DependsOn = []string{"node2"}
+
+	net := newTestNetwork(newMockAPISuccessful)
+	var (
+		lock   sync.Mutex
+		events []string
+	)
+	net.RegisterNodeHooks(network.NodeHooks{
+		OnBeforeNodeStart: func(nodeConfig node.Config) error {
+			lock.Lock()
+			defer lock.Unlock()
+			events = append(events, "start "+nodeConfig.Name)
+			return nil
+		},
+		OnAfterNodeHealthy: func(n node.Node) {
+			if n.GetName() != "node2" {
+				// seen healthy by their background health polls at any time
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			events = append(events, "healthy "+n.GetName())
+		},
+	})
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	lock.Lock()
+	require.Equal([]string{"start node0", "start node2", "healthy node2", "start node1"}, events)
+	lock.Unlock()
+	require.NoError(net.Stop(context.Background()))
+
+	net = newTestNetwork(func(string, uint16) api.Client {
+		healthClient := &healthmocks.Client{}
+		healthClient.On("Health", mock.Anything, mock.Anything).Return(&health.APIReply{Healthy: false}, nil)
+		ethClient := &apimocks.EthClient{}
+		ethClient.On("Close").Return()
+		client := &apimocks.Client{}
+		client.On("HealthAPI").Return(healthClient)
+		client.On("CChainEthAPI").Return(ethClient)
+		return client
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.Error(net.loadConfig(ctx, networkConfig))
+	require.Empty(net.nodes)
+}
+
+// TestRootDataDirModes checks that an ephemeral root data dir is removed
+// on stop, and that a persistent one is reused by the next network.
+func TestRootDataDirModes(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	newTestNetwork := func(rootDir string, mode network.RootDataDirMode) *localNetwork {
+		net, err := newNetwork(
+			logging.NoLog{},
+			newMockAPISuccessful,
+			&localTestSuccessfulNodeProcessCreator{},
+			rootDir,
+			"",
+			"",
+			false,
+			false,
+			false,
+			"",
+			beacon.NewSet(),
+			false,
+		)
+		require.NoError(err)
+		net.rootDataDirMode = mode
+		networkConfig := testNetworkConfig(t)
+		networkConfig.RootDataDir = network.RootDataDir{Mode: mode, Path: rootDir}
+		require.NoError(net.loadConfig(context.Background(), networkConfig))
+		return net
+	}
+
+	net := newTestNetwork("", network.EphemeralRootDataDir)
+	require.DirExists(filepath.Join(net.GetRootDir(), "node1"))
+	require.ErrorContains(net.Reconcile(context.Background(), network.Config{
+		NodeConfigs: testNetworkConfig(t).NodeConfigs,
+		RootDataDir: network.RootDataDir{Mode: network.PersistentRootDataDir, Path: net.GetRootDir()},
+	}), "root data dir")
+	require.NoError(net.Stop(context.Background()))
+	require.NoDirExists(net.GetRootDir())
+
+	// the root data dir is created if needed
+	rootDir := filepath.Join(t.TempDir(), "persistent", "network")
+	net = newTestNetwork(rootDir, network.PersistentRootDataDir)
+	require.Equal(filepath.Join(rootDir, "node1"), net.nodes["node1"].GetDataDir())
+	added, err := net.AddNode(node.Config{Name: "added"})
+	require.NoError(err)
+	require.Equal(filepath.Join(rootDir, "added"), added.DataDir)
+	require.Equal(added.GetDbDir(), added.DBDir)
+	require.Equal(added.GetLogsDir(), added.LogsDir)
+	require.Equal(added.GetAPIPort(), added.APIPort)
+	require.Equal(added.GetP2PPort(), added.P2PPort)
+	require.NotZero(added.APIPort)
+	require.NoError(added.Await(context.Background()))
+	require.NoError(net.Stop(context.Background()))
+	require.DirExists(filepath.Join(rootDir, "added"))
+
+	// generated staking keys are reused
+	net = newTestNetwork(rootDir, network.PersistentRootDataDir)
+	readded, err := net.AddNode(node.Config{Name: "added"})
+	require.NoError(err)
+	require.Equal(added.GetNodeID(), readded.GetNodeID())
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestGetFlagsForAvagoVersion(t *testing.T) {
+	require := require.New(t)
+
+	// new names are given to old versions with the old name
+	flags, translated := getFlagsForAvagoVersion("v1.9.5", map[string]string{
+		config.PluginDirKey: "/build/plugins/",
+		"track-subnets":     "",
+		"log-level":         "debug",
+	})
+	require.Equal(map[string]string{"build-dir": "/build", "log-level": "debug"}, flags)
+	require.Equal(map[string]string{config.PluginDirKey: "build-dir"}, translated)
+
+	// old names are given to new versions with the new name
+	flags, translated = getFlagsForAvagoVersion("v1.11.13", map[string]string{
+		"build-dir":           "/build",
+		"whitelisted-subnets": "subnet",
+		"genesis":             "/old/genesis.json",
+		"genesis-file":        "/genesis.json",
+	})
+	require.Equal(map[string]string{
+		config.PluginDirKey: "/build/plugins",
+		"track-subnets":     "subnet",
+		"genesis-file":      "/genesis.json",
+	}, flags)
+	require.Equal(map[string]string{
+		"build-dir":           config.PluginDirKey,
+		"whitelisted-subnets": "track-subnets",
+	}, translated)
+}
+
+// versionInfoClient is an info.Client whose GetNodeVersion method returns
+// [version]. Its other methods must not be called.
+type versionInfoClient struct {
+	info.Client
+	version string
+}
+
+func (c versionInfoClient) GetNodeVersion(context.Context, ...rpc.Option) (*info.GetNodeVersionReply, error) {
+	return &info.GetNodeVersionReply{
+		Version:         c.version,
+		DatabaseVersion: "v1.4.5",
+		VMVersions:      map[string]string{"platform": c.version},
+	}, nil
+}
+
+func TestVersions(t *testing.T) {
+	require := require.New(t)
+	newAPIClient := func(string, uint16) api.Client {
+		client := newMockAPISuccessful("", 0).(*apimocks.Client)
+		client.On("InfoAPI").Return(versionInfoClient{version: "avalanchego/1.11.13"})
+		return client
+	}
+	net, err := newNetwork(
+		logging.NoLog{},
+		newAPIClient,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	require.NoError(net.PauseNode(context.Background(), "node2"))
+
+	versions, err := net.Versions(context.Background())
+	require.NoError(err)
+	require.Len(versions, 2)
+	require.Equal(node.Version{
+		Version:         "avalanchego/1.11.13",
+		DatabaseVersion: "v1.4.5",
+		VMVersions:      map[string]string{"platform": "avalanchego/1.11.13"},
+	}, versions["node1"])
+	version, err := net.nodes["node0"].GetVersion(context.Background())
+	require.NoError(err)
+	require.Equal(versions["node0"], version)
+
+	require.NoError(net.Stop(context.Background()))
+	_, err = net.Versions(context.Background())
+	require.ErrorIs(err, network.ErrStopped)
+}
+
+func TestNodeGoroutinesStopped(t *testing.T) {
+	require := require.New(t)
+	// the nodes of the config are healthy, so that their health polls return,
+	// while the added ones are not
+	var numClients atomic.Int32
+	newAPIClient := func(string, uint16) api.Client {
+		healthy := numClients.Add(1) <= 3
+		healthClient := &healthmocks.Client{}
+		healthClient.On("Health", mock.Anything, mock.Anything).Return(&health.APIReply{Healthy: healthy}, nil)
+		ethClient := &apimocks.EthClient{}
+		ethClient.On("Close").Return()
+		client := &apimocks.Client{}
+		client.On("HealthAPI").Return(healthClient)
+		client.On("CChainEthAPI").Return(ethClient)
+		return client
+	}
+	net, err := newNetwork(
+		logging.NoLog{},
+		newAPIClient,
+		&localTestSuccessfulNodeProcessCreator{},
+		"",
+		"",
+		"",
+		false,
+		false,
+		false,
+		"",
+		beacon.NewSet(),
+		false,
+	)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	running := goleak.IgnoreCurrent()
+	// unhealthy nodes keep trying to register their aliases
+	net.blockchainAliases = map[string][]string{ids.GenerateTestID().String(): {"alias"}}
+
+	// the goroutines working on a node return once it is removed or paused,
+	// even if its process still reports to be running
+	for _, stop := range []func(nodeName string) error{
+		func(nodeName string) error { return net.RemoveNode(context.Background(), nodeName) },
+		func(nodeName string) error { return net.PauseNode(context.Background(), nodeName) },
+	} {
+		added, err := net.AddNode(node.Config{Name: "added"})
+		require.NoError(err)
+		awaitCh := make(chan error)
+		go func() {
+			awaitCh <- added.AwaitHealthy(context.Background())
+		}()
+		require.NoError(stop("added"))
+		require.Error(<-awaitCh)
+		goleak.VerifyNone(t, running)
+		if _, err := net.GetNode("added"); err == nil {
+			require.NoError(net.RemoveNode(context.Background(), "added"))
+		}
+	}
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestToConfig(t *testing.T) {
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	networkConfig := testNetworkConfig(t)
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	require.NoError(net.RemoveNode(context.Background(), "node2"))
+	added, err := net.AddNode(node.Config{Name: "added", DependsOn: []string{"node2", "node1"}})
+	require.NoError(err)
+
+	toConfig, err := net.ToConfig()
+	require.NoError(err)
+	require.Equal(networkConfig.NetworkID, toConfig.NetworkID)
+	require.Equal(networkConfig.Genesis, toConfig.Genesis)
+	// the network's own beacon is not kept
+	require.Empty(toConfig.BeaconConfig)
+	require.Len(toConfig.NodeConfigs, 3)
+	require.Equal("added", toConfig.NodeConfigs[0].Name)
+	require.Equal([]string{"node1"}, toConfig.NodeConfigs[0].DependsOn)
+	// the generated staking keys are kept, but not the ports nor the dirs
+	require.Equal(added.GetConfig().StakingKey, toConfig.NodeConfigs[0].StakingKey)
+	require.Equal("node0", toConfig.NodeConfigs[1].Name)
+	require.True(toConfig.NodeConfigs[1].IsBeacon)
+	require.Equal("node1", toConfig.NodeConfigs[2].Name)
+	for _, nodeConfig := range toConfig.NodeConfigs {
+		require.NotContains(nodeConfig.Flags, config.HTTPPortKey)
+		require.NotContains(nodeConfig.Flags, config.DataDirKey)
+	}
+
+	// the network can be created again from the saved config
+	path := filepath.Join(t.TempDir(), "network.json")
+	require.NoError(network.SaveConfig(path, toConfig))
+	loadedConfig, err := network.LoadConfig(path, network.ConfigTemplateData{})
+	require.NoError(err)
+	require.NoError(loadedConfig.Validate())
+	require.NoError(net.Stop(context.Background()))
+	recreated, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(recreated.loadConfig(context.Background(), loadedConfig))
+	readded, err := recreated.GetNode("added")
+	require.NoError(err)
+	require.Equal(added.GetNodeID(), readded.GetNodeID())
+	require.NoError(recreated.Stop(context.Background()))
+	_, err = recreated.ToConfig()
+	require.ErrorIs(err, network.ErrStopped)
+}
+
+func TestAddNodeFromTemplate(t *testing.T) {
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	_, err = net.AddNode(node.Config{
+		Name:             "template",
+		Flags:            map[string]interface{}{config.LogLevelKey: "debug", config.TrackSubnetsKey: "subnet1"},
+		ChainConfigFiles: map[string]string{"C": `{"pruning-enabled":false}`},
+	})
+	require.NoError(err)
+	// the template's ports and dirs are set on its restart
+	require.NoError(net.RestartNode(context.Background(), "template", "", "", "", nil, nil, nil))
+	template, err := net.GetNode("template")
+	require.NoError(err)
+
+	cloned, err := net.AddNodeFromTemplate("template", network.NodeOverrides{
+		Name:  "cloned",
+		Flags: map[string]interface{}{config.LogLevelKey: "trace"},
+	})
+	require.NoError(err)
+	require.Equal("cloned", cloned.GetName())
+	require.NotEqual(template.GetNodeID(), cloned.GetNodeID())
+	require.NotEqual(template.GetAPIPort(), cloned.GetAPIPort())
+	require.NotEqual(template.GetDataDir(), cloned.GetDataDir())
+	clonedConfig := cloned.GetConfig()
+	require.Equal("trace", clonedConfig.Flags[config.LogLevelKey])
+	require.Equal("subnet1", clonedConfig.Flags[config.TrackSubnetsKey])
+	require.Equal(`{"pruning-enabled":false}`, clonedConfig.ChainConfigFiles["C"])
+	require.Equal(template.GetBinaryPath(), cloned.GetBinaryPath())
+
+	// a name is assigned if not given
+	unnamed, err := net.AddNodeFromTemplate("template", network.NodeOverrides{})
+	require.NoError(err)
+	require.NotEmpty(unnamed.GetName())
+
+	_, err = net.AddNodeFromTemplate("missing", network.NodeOverrides{})
+	require.ErrorIs(err, network.ErrNodeNotFound)
+	_, err = net.AddNodeFromTemplate("template", network.NodeOverrides{Name: "cloned"})
+	require.Error(err)
+	require.NoError(net.Stop(context.Background()))
+	_, err = net.AddNodeFromTemplate("template", network.NodeOverrides{})
+	require.ErrorIs(err, network.ErrStopped)
+}
+
+func TestStaticValidators(t *testing.T) {
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.StaticValidators = true
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	_, err = net.CreateSubnets(context.Background(), []network.SubnetSpec{{}})
+	require.ErrorIs(err, network.ErrStaticValidators)
+	_, err = net.CreateBlockchains(context.Background(), []network.BlockchainSpec{{VMName: "subnetevm"}})
+	require.ErrorIs(err, network.ErrStaticValidators)
+	_, _, err = net.TransformSubnet(context.Background(), []network.ElasticSubnetSpec{{}})
+	require.ErrorIs(err, network.ErrStaticValidators)
+	require.ErrorIs(net.AddPermissionlessValidators(context.Background(), nil), network.ErrStaticValidators)
+	require.ErrorIs(net.AddPermissionlessDelegators(context.Background(), nil), network.ErrStaticValidators)
+	require.ErrorIs(net.AddSubnetValidators(context.Background(), nil), network.ErrStaticValidators)
+	require.ErrorIs(net.RemoveSubnetValidators(context.Background(), nil), network.ErrStaticValidators)
+	// nodes that are not validators can still be added
+	_, err = net.AddNode(node.Config{Name: "api"})
+	require.NoError(err)
+
+	toConfig, err := net.ToConfig()
+	require.NoError(err)
+	require.True(toConfig.StaticValidators)
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestGetNodeNamesWithFilter(t *testing.T) {
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, "", "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	_, err = net.AddNode(node.Config{Name: "api1", Labels: map[string]string{"role": "api"}})
+	require.NoError(err)
+	_, err = net.AddNode(node.Config{Name: "api0", Labels: map[string]string{"role": "api"}})
+	require.NoError(err)
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+
+	names, err := net.GetNodeNames()
+	require.NoError(err)
+	require.Equal([]string{"api0", "api1", "node0", "node1", "node2"}, names)
+	names, err = net.GetNodeNamesWithFilter(context.Background(), network.NodeFilter{Beacons: true})
+	require.NoError(err)
+	require.Equal([]string{"node0", "node1", "node2"}, names)
+	names, err = net.GetNodeNamesWithFilter(context.Background(), network.NodeFilter{Labels: map[string]string{"role": "api"}})
+	require.NoError(err)
+	require.Equal([]string{"api0", "api1"}, names)
+	// paused nodes are not healthy
+	names, err = net.GetNodeNamesWithFilter(context.Background(), network.NodeFilter{Beacons: true, Healthy: true})
+	require.NoError(err)
+	require.Equal([]string{"node0", "node2"}, names)
+
+	require.NoError(net.Stop(context.Background()))
+	_, err = net.GetNodeNamesWithFilter(context.Background(), network.NodeFilter{})
+	require.ErrorIs(err, network.ErrStopped)
+}
+
+func TestHistory(t *testing.T) {
+	require := require.New(t)
+	rootDir := t.TempDir()
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, rootDir, "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	// loading the config is not an operation on the network
+	require.Empty(net.History())
+
+	_, err = net.AddNode(node.Config{})
+	require.NoError(err)
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	require.Error(net.PauseNode(context.Background(), "node1"))
+	require.NoError(net.ResumeNode(context.Background(), "node1"))
+	require.NoError(net.RemoveNodeWithOptions(context.Background(), "node2", network.RemoveNodeOptions{Force: true}))
+	require.NoError(net.Stop(context.Background()))
+	// rejected operations are not recorded
+	require.ErrorIs(net.PauseNode(context.Background(), "node0"), network.ErrStopped)
+
+	history := net.History()
+	ops := make([]string, 0, len(history))
+	for _, record := range history {
+		ops = append(ops, record.Op+" "+record.NodeName)
+	}
+	require.Equal([]string{
+		"AddNode node3",
+		"PauseNode node1",
+		"PauseNode node1",
+		"ResumeNode node1",
+		"RemoveNode node2",
+		"Stop ",
+	}, ops)
+	require.Empty(history[1].Err)
+	require.Contains(history[2].Err, "paused already")
+	require.Equal(map[string]string{"force": "true", "drainFirst": "false"}, history[4].Params)
+	for i := 1; i < len(history); i++ {
+		require.False(history[i].Time.Before(history[i-1].Time))
+	}
+
+	historyFile, err := os.ReadFile(filepath.Join(rootDir, historyFileName))
+	require.NoError(err)
+	lines := strings.Split(strings.TrimSpace(string(historyFile)), "\n")
+	require.Len(lines, len(history))
+	for i, line := range lines {
+		var record network.OperationRecord
+		require.NoError(json.Unmarshal([]byte(line), &record))
+		require.Equal(history[i].Op, record.Op)
+		require.Equal(history[i].NodeName, record.NodeName)
+		require.Equal(history[i].Err, record.Err)
+	}
+}
+
+func TestStartupReport(t *testing.T) {
+	require := require.New(t)
+	// the nodes only respond to health checks once released, so that their
+	// background health polls don't complete the health phases early
+	releaseCh := make(chan struct{})
+	newAPIClient := func(string, uint16) api.Client {
+		healthClient := &healthmocks.Client{}
+		healthClient.On("Health", mock.Anything, mock.Anything).Return(&health.APIReply{Healthy: true}, nil).
+			Run(func(mock.Arguments) { <-releaseCh })
+		ethClient := &apimocks.EthClient{}
+		ethClient.On("Close").Return()
+		client := &apimocks.Client{}
+		client.On("HealthAPI").Return(healthClient)
+		client.On("CChainEthAPI").Return(ethClient)
+		return client
+	}
+	net, err := newNetwork(logging.NoLog{}, newAPIClient, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	clock := newFakeClock()
+	net.clock = clock
+	net.startup.startTime = clock.Now()
+	net.RegisterNodeHooks(network.NodeHooks{
+		OnBeforeNodeStart: func(node.Config) error {
+			clock.advance(time.Second)
+			return nil
+		},
+	})
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+
+	report, err := net.StartupReport()
+	require.NoError(err)
+	require.Equal(net.startup.startTime, report.StartTime)
+	require.Zero(report.Healthy)
+	require.Len(report.Nodes, 3)
+	for _, nodeReport := range report.Nodes {
+		// health phases are not completed until observed
+		require.Equal(map[network.StartupPhase]time.Duration{
+			network.StartupPhaseKeyGeneration: 0,
+			network.StartupPhaseDirSetup:      time.Second,
+			network.StartupPhaseProcessStart:  0,
+		}, nodeReport.Phases)
+	}
+
+	clock.advance(2 * time.Second)
+	close(releaseCh)
+	require.NoError(net.Healthy(context.Background()))
+	report, err = net.StartupReport()
+	require.NoError(err)
+	require.Equal(5*time.Second, report.Healthy)
+	for _, nodeReport := range report.Nodes {
+		require.Len(nodeReport.Phases, len(network.StartupPhases))
+		require.Equal(time.Second, nodeReport.Phases[network.StartupPhaseDirSetup])
+		// the phases add up to the time from the node start until it was healthy
+		require.Equal(clock.Now(), nodeReport.StartTime.Add(nodeReport.Total()))
+	}
+
+	require.NoError(net.Stop(context.Background()))
+	_, err = net.StartupReport()
+	require.ErrorIs(err, network.ErrStopped)
+}
+
+func TestObserverNodes(t *testing.T) {
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	_, err = net.AddNode(node.Config{Name: "api", Observer: true, BinaryPath: "avalanchego"})
+	require.NoError(err)
+
+	require.Equal([]string{"node0", "node1", "node2"}, net.validatorNodeNames())
+	require.NoError(net.checkNotObservers("add subnet validator", "node0", "node3"))
+	err = net.checkNotObservers("add subnet validator", "node0", "api")
+	require.ErrorIs(err, network.ErrObserverNode)
+	var nodeErr *network.NodeError
+	require.ErrorAs(err, &nodeErr)
+	require.Equal("api", nodeErr.NodeName)
+
+	names, err := net.GetNodeNamesWithFilter(context.Background(), network.NodeFilter{Observers: true})
+	require.NoError(err)
+	require.Equal([]string{"api"}, names)
+	require.True(net.nodes["api"].GetEffectiveConfig().Observer)
+}
+
+func TestSubnetOnlyNodes(t *testing.T) {
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	_, err = net.AddNode(node.Config{Name: "subnet-only", SubnetOnly: true, BinaryPath: "avalanchego"})
+	require.NoError(err)
+
+	require.Equal("true", net.nodes["subnet-only"].flags[config.PartialSyncPrimaryNetworkKey])
+	require.NotContains(net.nodes["node1"].flags, config.PartialSyncPrimaryNetworkKey)
+	require.True(net.nodes["subnet-only"].GetEffectiveConfig().SubnetOnly)
+	// not used to issue txs even having the lowest API port
+	net.nodes["subnet-only"].apiPort = 1
+	require.NotEqual("subnet-only", net.getNode().GetName())
+}
+
+// Records the configs of the node processes created
+type localTestRecordingNodeProcessCreator struct {
+	localTestSuccessfulNodeProcessCreator
+	configs map[string]node.Config
+}
+
+func (npc *localTestRecordingNodeProcessCreator) NewNodeProcess(config node.Config, startupTime time.Duration, flags ...string) (NodeProcess, error) {
+	npc.configs[config.Name] = config
+	return npc.localTestSuccessfulNodeProcessCreator.NewNodeProcess(config, startupTime, flags...)
+}
+
+func TestNetworkLabels(t *testing.T) {
+	require := require.New(t)
+	npc := &localTestRecordingNodeProcessCreator{configs: map[string]node.Config{}}
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, npc, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	networkConfig := testNetworkConfig(t)
+	networkConfig.Labels = map[string]string{"job": "ci", "branch": "main"}
+	networkConfig.NodeConfigs[0].Labels = map[string]string{"branch": "dev", "role": "api"}
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	// node labels override the network ones, and are kept apart from them
+	require.Equal(map[string]string{"job": "ci", "branch": "dev", "role": "api"}, npc.configs["node0"].Labels)
+	require.Equal(map[string]string{"job": "ci", "branch": "main"}, npc.configs["node1"].Labels)
+	require.Equal(map[string]string{"branch": "dev", "role": "api"}, net.nodes["node0"].config.Labels)
+	config, err := net.ToConfig()
+	require.NoError(err)
+	require.Equal(networkConfig.Labels, config.Labels)
+
+	desired := config
+	desired.Labels = map[string]string{"job": "nightly"}
+	require.ErrorContains(net.Reconcile(context.Background(), desired), "labels")
+	desired.Labels = nil
+	require.NoError(net.Reconcile(context.Background(), desired))
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestHealthRequestTimeoutConfig(t *testing.T) {
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.Equal(network.DefaultHealthRequestTimeout, net.healthRequestTimeout)
+
+	networkConfig := testNetworkConfig(t)
+	networkConfig.HealthRequestTimeout = 500 * time.Millisecond
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+	require.Equal(500*time.Millisecond, net.healthRequestTimeout)
+	savedConfig, err := net.getNetworkConfig(true)
+	require.NoError(err)
+	require.Equal(500*time.Millisecond, savedConfig.HealthRequestTimeout)
+	require.NoError(net.Stop(context.Background()))
+
+	networkConfig.HealthRequestTimeout = -time.Second
+	require.Error(networkConfig.Validate())
+}
@@ -4,41 +4,56 @@ import (
 	"context"
 	"crypto"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/netip"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/api"
-	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	anrnode "github.com/ava-labs/avalanche-network-runner/network/node"
 	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	avagoapi "github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/config"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/message"
 	"github.com/ava-labs/avalanchego/network/peer"
 	"github.com/ava-labs/avalanchego/network/throttling"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
 	"github.com/ava-labs/avalanchego/snow/networking/tracker"
 	"github.com/ava-labs/avalanchego/snow/uptime"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/subnets"
 	"github.com/ava-labs/avalanchego/upgrade"
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/math/meter"
 	"github.com/ava-labs/avalanchego/utils/resource"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/version"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
 )
 
 var (
-	_ getConnFunc = defaultGetConnFunc
-	_ node.Node   = (*localNode)(nil)
+	_ getConnFunc  = defaultGetConnFunc
+	_ anrnode.Node = (*localNode)(nil)
+
+	errNoConsensusParameters = errors.New("node config has no primary network consensus parameters")
 )
 
-type getConnFunc func(context.Context, node.Node) (net.Conn, error)
+type getConnFunc func(context.Context, anrnode.Node) (net.Conn, error)
 
 const (
 	peerMsgQueueBufferSize      = 1024
@@ -76,7 +91,9 @@ type localNode struct {
 	// The plugin dir of the node
 	pluginDir string
 	// The node config
-	config node.Config
+	config anrnode.Config
+	// The flags the node process was started with
+	flags map[string]string
 	// The node httpHost
 	httpHost string
 	// maps from peer ID to peer object
@@ -86,15 +103,57 @@ type localNode struct {
 	paused bool
 	// if set, returns 0.0.0.0 if httpHost setting is public
 	zeroIP bool
+	// used to call the healthy hooks only the first time the process is seen healthy
+	onHealthyOnce sync.Once
+	// set once the process is seen healthy while waiting for it
+	seenHealthy atomic.Bool
+	// set once the process API is seen responding while waiting for it,
+	// healthy or not. See node.Config.StartupTimeout.
+	seenResponding atomic.Bool
+	// blockchain aliases to register once the process is healthy, as avalanchego
+	// doesn't persist them. Map from blockchain id to blockchain aliases.
+	blockchainAliases map[string][]string
+	// used to register [blockchainAliases] only once per process
+	registerAliasesOnce sync.Once
+	// time at which the node process was started
+	startTime time.Time
+	// the network the node belongs to, used to stop and start it
+	network *localNetwork
+	// set to the node of the process that replaced this one on resume or
+	// restart, so that handles on this one keep referring to the node
+	next atomic.Pointer[localNode]
+	// guards [calls], [drained] and [callsDoneCh]
+	callsLock sync.Mutex
+	// number of API calls the network is making to the node,
+	// so that they can be drained before removing it
+	calls int
+	// set once drained, so that no new API calls are made
+	drained bool
+	// closed once the calls are done, while draining
+	callsDoneCh chan struct{}
+	// closed once the node process is stopped by the network, on remove, pause,
+	// restart or network stop, so that the background goroutines working on
+	// the node return
+	onStopCh chan struct{}
+	stopOnce sync.Once
+	// cgroup the node process was moved to, to throttle its disk I/O.
+	// See network.Network.SetNodeDiskChaos.
+	cgroupDir string
+	// set once the node process is seen exiting without being stopped by the network
+	crashLock   sync.RWMutex
+	crashReport *anrnode.CrashReport
+	// durations of the startup phases of the node process
+	startup *startupTracker
 }
 
-func defaultGetConnFunc(ctx context.Context, node node.Node) (net.Conn, error) {
+func defaultGetConnFunc(ctx context.Context, node anrnode.Node) (net.Conn, error) {
 	dialer := net.Dialer{}
 	return dialer.DialContext(ctx, constants.NetworkType, net.JoinHostPort(node.GetIP(), fmt.Sprintf("%d", node.GetP2PPort())))
 }
 
 // AttachPeer: see Network
 func (node *localNode) AttachPeer(ctx context.Context, router router.InboundHandler) (peer.Peer, error) {
+	node = node.current()
 	tlsCert, err := staking.NewTLSCert()
 	if err != nil {
 		return nil, err
@@ -190,6 +249,7 @@ func (node *localNode) AttachPeer(ctx context.Context, router router.InboundHand
 }
 
 func (node *localNode) SendOutboundMessage(ctx context.Context, peerID string, content []byte, op uint32) (bool, error) {
+	node = node.current()
 	attachedPeer, ok := node.attachedPeers[peerID]
 	if !ok {
 		return false, fmt.Errorf("peer with ID %s is not attached here", peerID)
@@ -205,16 +265,17 @@ func (node *localNode) GetName() string {
 
 // See node.Node
 func (node *localNode) GetNodeID() ids.NodeID {
-	return node.nodeID
+	return node.current().nodeID
 }
 
 // See node.Node
 func (node *localNode) GetAPIClient() api.Client {
-	return node.client
+	return node.current().client
 }
 
 // See node.Node
 func (node *localNode) GetIP() string {
+	node = node.current()
 	if node.zeroIP && (node.httpHost == "0.0.0.0" || node.httpHost == ".") {
 		return "0.0.0.0"
 	}
@@ -228,56 +289,78 @@ func (node *localNode) GetURI() string {
 
 // See node.Node
 func (node *localNode) GetP2PPort() uint16 {
-	return node.p2pPort
+	return node.current().p2pPort
 }
 
 // See node.Node
 func (node *localNode) GetAPIPort() uint16 {
-	return node.apiPort
+	return node.current().apiPort
 }
 
 func (node *localNode) Status() status.Status {
-	return node.process.Status()
+	return node.current().process.Status()
 }
 
 // See node.Node
 func (node *localNode) GetBinaryPath() string {
-	return node.config.BinaryPath
+	return node.current().config.BinaryPath
 }
 
 // See node.Node
 func (node *localNode) GetPluginDir() string {
-	return node.pluginDir
+	return node.current().pluginDir
 }
 
 // See node.Node
 func (node *localNode) GetDataDir() string {
-	return node.dataDir
+	return node.current().dataDir
 }
 
 // See node.Node
 // TODO rename method so linter doesn't complain.
 func (node *localNode) GetDbDir() string { //nolint
-	return node.dbDir
+	return node.current().dbDir
 }
 
 // See node.Node
 func (node *localNode) GetLogsDir() string {
-	return node.logsDir
+	return node.current().logsDir
 }
 
 // See node.Node
 func (node *localNode) GetConfigFile() string {
-	return node.config.ConfigFile
+	return node.current().config.ConfigFile
 }
 
 // See node.Node
-func (node *localNode) GetConfig() node.Config {
-	return node.config
+func (node *localNode) GetConfig() anrnode.Config {
+	return node.current().config
+}
+
+// See node.Node
+func (node *localNode) GetEffectiveConfig() anrnode.EffectiveConfig {
+	node = node.current()
+	return anrnode.EffectiveConfig{
+		Name:       node.name,
+		NodeID:     node.nodeID,
+		IsBeacon:   node.config.IsBeacon,
+		Observer:   node.config.Observer,
+		SubnetOnly: node.config.SubnetOnly,
+		BinaryPath: node.config.BinaryPath,
+		IP:         node.GetIP(),
+		APIPort:    node.apiPort,
+		P2PPort:    node.p2pPort,
+		DataDir:    node.dataDir,
+		DBDir:      node.dbDir,
+		LogsDir:    node.logsDir,
+		PluginDir:  node.pluginDir,
+		Flags:      anrnode.RedactFlags(node.flags),
+	}
 }
 
 // See node.Node
 func (node *localNode) GetFlag(k string) (string, error) {
+	node = node.current()
 	var v string
 	if node.config.ConfigFile != "" {
 		var configFileMap map[string]interface{}
@@ -305,5 +388,373 @@ func (node *localNode) GetFlag(k string) (string, error) {
 
 // See node.Node
 func (node *localNode) GetPaused() bool {
-	return node.paused
+	return node.current().paused
+}
+
+// See node.Node
+func (node *localNode) StartTime() time.Time {
+	node = node.current()
+	if node.paused {
+		return time.Time{}
+	}
+	return node.startTime
+}
+
+// See node.Node
+func (node *localNode) Uptime() time.Duration {
+	node = node.current()
+	if node.paused || node.Status() != status.Running {
+		return 0
+	}
+	return node.network.clock.Now().Sub(node.startTime)
+}
+
+// See node.Node
+func (node *localNode) PID() int {
+	node = node.current()
+	process, ok := node.process.(pidGetter)
+	if !ok || node.Status() != status.Running {
+		return 0
+	}
+	return process.getPID()
+}
+
+// See node.Node
+func (node *localNode) RecentLogs(n int) []string {
+	node = node.current()
+	process, ok := node.process.(logsGetter)
+	if !ok {
+		return nil
+	}
+	return process.getRecentLogs(n)
+}
+
+// See node.Node
+func (node *localNode) Signal(sig os.Signal) error {
+	node = node.current()
+	process, ok := node.process.(signaler)
+	if !ok {
+		return errors.New("node process can't be signaled")
+	}
+	if err := process.signal(sig); err != nil {
+		return fmt.Errorf("couldn't send %s to node %q: %w", sig, node.name, err)
+	}
+	node.network.log.Info("sent signal to node", zap.String("node", node.name), zap.Stringer("signal", sig))
+	return nil
+}
+
+// Returns the node of the current process of this node, following the
+// processes that replaced this one, so that the methods of node.Node act
+// on the node rather than on the process it was returned for.
+// A node removed for good is its own current process.
+func (node *localNode) current() *localNode {
+	for {
+		next := node.next.Load()
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+}
+
+// Returns false if the node was drained. Otherwise, the caller
+// must call [endCall] once its API call to the node is done.
+func (node *localNode) beginCall() bool {
+	node.callsLock.Lock()
+	defer node.callsLock.Unlock()
+
+	if node.drained {
+		return false
+	}
+	node.calls++
+	return true
+}
+
+func (node *localNode) endCall() {
+	node.callsLock.Lock()
+	defer node.callsLock.Unlock()
+
+	node.calls--
+	if node.calls == 0 && node.callsDoneCh != nil {
+		close(node.callsDoneCh)
+		node.callsDoneCh = nil
+	}
+}
+
+// See node.Node
+func (node *localNode) CrashReport() *anrnode.CrashReport {
+	node = node.current()
+	node.crashLock.RLock()
+	defer node.crashLock.RUnlock()
+
+	if node.crashReport == nil {
+		return nil
+	}
+	crashReport := *node.crashReport
+	return &crashReport
+}
+
+func (node *localNode) setCrashReport(crashReport *anrnode.CrashReport) {
+	node.crashLock.Lock()
+	defer node.crashLock.Unlock()
+
+	node.crashReport = crashReport
+}
+
+// Closes [node.onStopCh] and the peers attached to the node.
+// Called once the node process is stopped by the network.
+func (node *localNode) markStopped() {
+	node.stopOnce.Do(func() {
+		close(node.onStopCh)
+		for _, p := range node.attachedPeers {
+			p.StartClose()
+		}
+		if node.cgroupDir != "" {
+			if err := removeCgroup(node.cgroupDir); err != nil {
+				node.network.log.Warn("couldn't remove node cgroup", zap.String("node", node.name), zap.Error(err))
+			}
+		}
+	})
+}
+
+// Returns true if the node process was stopped by the network.
+func (node *localNode) stopped() bool {
+	select {
+	case <-node.onStopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// Prevents new API calls of the network to the node, and waits for the
+// ongoing ones to finish. If they don't before [ctx] is done, new calls
+// are allowed again, and ctx.Err() is returned.
+func (node *localNode) drain(ctx context.Context) error {
+	node.callsLock.Lock()
+	node.drained = true
+	if node.calls == 0 {
+		node.callsLock.Unlock()
+		return nil
+	}
+	if node.callsDoneCh == nil {
+		node.callsDoneCh = make(chan struct{})
+	}
+	callsDoneCh := node.callsDoneCh
+	node.callsLock.Unlock()
+
+	select {
+	case <-callsDoneCh:
+		return nil
+	case <-ctx.Done():
+		node.callsLock.Lock()
+		node.drained = false
+		node.callsLock.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Makes a single health request to the node, bounded by the health request timeout of the network.
+// The given [ctx] may set a shorter deadline.
+func (node *localNode) isHealthy(ctx context.Context) bool {
+	if !node.beginCall() {
+		return false
+	}
+	defer node.endCall()
+	ctx, cancel := context.WithTimeout(ctx, node.network.healthRequestTimeout)
+	defer cancel()
+	health, err := node.client.HealthAPI().Health(ctx, nil)
+	if err != nil {
+		return false
+	}
+	node.seenResponding.Store(true)
+	if node.startup != nil {
+		node.startup.endPhase(network.StartupPhaseFirstHealthResponse)
+		if health.Healthy {
+			node.startup.endPhase(network.StartupPhaseBootstrapped)
+		}
+	}
+	return health.Healthy
+}
+
+// Returns true if the node is connected to any of the beacons given on its
+// bootstrap ids flag, or has none. Fails if the peers can't be read.
+func (node *localNode) isBeaconConnected(ctx context.Context) (bool, error) {
+	beaconIDs := set.Set[string]{}
+	for _, beaconID := range strings.Split(node.flags[config.BootstrapIDsKey], ",") {
+		if beaconID != "" {
+			beaconIDs.Add(beaconID)
+		}
+	}
+	if beaconIDs.Len() == 0 {
+		return true, nil
+	}
+	if !node.beginCall() {
+		return false, errNodeDrained
+	}
+	defer node.endCall()
+	ctx, cancel := context.WithTimeout(ctx, node.network.healthRequestTimeout)
+	defer cancel()
+	peers, err := node.client.InfoAPI().Peers(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("couldn't get peers: %w", err)
+	}
+	for _, peer := range peers {
+		if beaconIDs.Contains(peer.ID.String()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// See node.Node
+func (node *localNode) AwaitHealthy(ctx context.Context) error {
+	return node.network.awaitNodeHealthy(ctx, node.current())
+}
+
+// See node.Node
+func (node *localNode) Stop(ctx context.Context) error {
+	return node.network.pauseNodeOf(ctx, node.name, node)
+}
+
+// See node.Node
+func (node *localNode) Start(ctx context.Context) error {
+	return node.network.resumeNodeOf(ctx, node.name, node)
+}
+
+// See node.Node
+func (node *localNode) TrackSubnet(ctx context.Context, subnetID ids.ID) error {
+	return node.network.setSubnetTracked(ctx, node, subnetID, true)
+}
+
+// See node.Node
+func (node *localNode) UntrackSubnet(ctx context.Context, subnetID ids.ID) error {
+	return node.network.setSubnetTracked(ctx, node, subnetID, false)
+}
+
+// See node.Node
+func (node *localNode) Exec(ctx context.Context, cmd string, args ...string) ([]byte, error) {
+	node = node.current()
+	c := exec.CommandContext(ctx, cmd, args...)
+	c.Dir = node.dataDir
+	c.Env = append(os.Environ(),
+		anrnode.ExecNodeNameEnv+"="+node.name,
+		anrnode.ExecNodeIDEnv+"="+node.nodeID.String(),
+		anrnode.ExecNodeURIEnv+"="+node.GetURI(),
+		anrnode.ExecDataDirEnv+"="+node.dataDir,
+		anrnode.ExecDBDirEnv+"="+node.dbDir,
+		anrnode.ExecLogsDirEnv+"="+node.logsDir,
+		anrnode.ExecPluginDirEnv+"="+node.pluginDir,
+	)
+	out, err := c.CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("node %q: command %q failed: %w", node.name, cmd, err)
+	}
+	return out, nil
+}
+
+// See node.Node
+func (node *localNode) CreateKeystoreUser(
+	ctx context.Context,
+	user string,
+	pass string,
+	privateKeys ...*secp256k1.PrivateKey,
+) error {
+	node = node.current()
+	if !node.beginCall() {
+		return node.keystoreError(errNodeDrained)
+	}
+	defer node.endCall()
+	userPass := avagoapi.UserPass{Username: user, Password: pass}
+	if err := node.client.KeystoreAPI().CreateUser(ctx, userPass); err != nil {
+		return node.keystoreError(fmt.Errorf("couldn't create user %q: %w", user, err))
+	}
+	for _, privateKey := range privateKeys {
+		if _, err := node.client.XChainAPI().ImportKey(ctx, userPass, privateKey); err != nil {
+			return node.keystoreError(fmt.Errorf("couldn't import key to X-Chain for user %q: %w", user, err))
+		}
+		if _, err := node.client.CChainAPI().ImportKey(ctx, userPass, privateKey); err != nil {
+			return node.keystoreError(fmt.Errorf("couldn't import key to C-Chain for user %q: %w", user, err))
+		}
+	}
+	return nil
+}
+
+// See node.Node
+func (node *localNode) GetVersion(ctx context.Context) (anrnode.Version, error) {
+	node = node.current()
+	if !node.beginCall() {
+		return anrnode.Version{}, &network.NodeError{NodeName: node.name, Op: "get version of", Err: errNodeDrained}
+	}
+	defer node.endCall()
+	reply, err := node.client.InfoAPI().GetNodeVersion(ctx)
+	if err != nil {
+		return anrnode.Version{}, &network.NodeError{NodeName: node.name, Op: "get version of", Err: err}
+	}
+	return anrnode.Version{
+		Version:            reply.Version,
+		DatabaseVersion:    reply.DatabaseVersion,
+		RPCProtocolVersion: uint32(reply.RPCProtocolVersion),
+		GitCommit:          reply.GitCommit,
+		VMVersions:         reply.VMVersions,
+	}, nil
+}
+
+// See node.Node
+func (node *localNode) SetLogLevel(ctx context.Context, level string) error {
+	node = node.current()
+	if _, err := logging.ToLevel(level); err != nil {
+		return &network.NodeError{NodeName: node.name, Op: "set log level of", Err: err}
+	}
+	if !node.beginCall() {
+		return &network.NodeError{NodeName: node.name, Op: "set log level of", Err: errNodeDrained}
+	}
+	defer node.endCall()
+	// an empty logger name sets the level of all the loggers
+	if _, err := node.client.AdminAPI().SetLoggerLevel(ctx, "", level, level); err != nil {
+		return &network.NodeError{NodeName: node.name, Op: "set log level of", Err: err}
+	}
+	return nil
+}
+
+// See node.Node
+func (node *localNode) ConsensusParameters(ctx context.Context) (snowball.Parameters, error) {
+	node = node.current()
+	if !node.beginCall() {
+		return snowball.Parameters{}, &network.NodeError{NodeName: node.name, Op: "get consensus parameters of", Err: errNodeDrained}
+	}
+	defer node.endCall()
+	nodeConfig, err := node.client.AdminAPI().GetConfig(ctx)
+	if err != nil {
+		return snowball.Parameters{}, &network.NodeError{NodeName: node.name, Op: "get consensus parameters of", Err: err}
+	}
+	parameters, err := primaryNetworkConsensusParameters(nodeConfig)
+	if err != nil {
+		return snowball.Parameters{}, &network.NodeError{NodeName: node.name, Op: "get consensus parameters of", Err: err}
+	}
+	return parameters, nil
+}
+
+// Returns the primary network consensus parameters of [nodeConfig],
+// the node config as given by the admin API
+func primaryNetworkConsensusParameters(nodeConfig interface{}) (snowball.Parameters, error) {
+	nodeConfigJSON, err := json.Marshal(nodeConfig)
+	if err != nil {
+		return snowball.Parameters{}, err
+	}
+	var subnetConfigs struct {
+		SubnetConfigs map[string]subnets.Config `json:"subnetConfigs"`
+	}
+	if err := json.Unmarshal(nodeConfigJSON, &subnetConfigs); err != nil {
+		return snowball.Parameters{}, fmt.Errorf("couldn't unmarshal node config: %w", err)
+	}
+	primaryNetworkConfig, ok := subnetConfigs.SubnetConfigs[constants.PrimaryNetworkID.String()]
+	if !ok {
+		return snowball.Parameters{}, errNoConsensusParameters
+	}
+	return primaryNetworkConfig.ConsensusParameters, nil
+}
+
+func (node *localNode) keystoreError(err error) error {
+	return &network.NodeError{NodeName: node.name, Op: "create keystore user on", Err: err}
 }
@@ -18,7 +18,12 @@ import (
 	"go.uber.org/zap"
 )
 
-var _ NodeProcess = (*nodeProcess)(nil)
+var (
+	_ NodeProcess = (*nodeProcess)(nil)
+	_ pidGetter   = (*nodeProcess)(nil)
+	_ signaler    = (*nodeProcess)(nil)
+	_ logsGetter  = (*nodeProcess)(nil)
+)
 
 // NodeProcess as an interface so we can mock running
 // AvalancheGo binaries in tests
@@ -31,6 +36,29 @@ type NodeProcess interface {
 	Stop(ctx context.Context) int
 	// Returns the status of the process.
 	Status() status.Status
+	// Returns a channel that is closed when the process exits.
+	Done() <-chan struct{}
+	// Returns how the process exited if it exited without [Stop] being
+	// called, or nil otherwise. The report's LogTail is left empty.
+	CrashReport() *node.CrashReport
+}
+
+// pidGetter is implemented by the node processes that run an OS process,
+// so that its ID can be exposed, and disk faults injected on it
+type pidGetter interface {
+	getPID() int
+}
+
+// signaler is implemented by the node processes that run an OS process,
+// so that they can be sent signals
+type signaler interface {
+	signal(os.Signal) error
+}
+
+// logsGetter is implemented by the node processes that keep
+// their recent output, so that it can be exposed
+type logsGetter interface {
+	getRecentLogs(n int) []string
 }
 
 // NodeProcessCreator is an interface for new node process creation
@@ -50,6 +78,10 @@ type nodeProcessCreator struct {
 	// If this node's stderr is redirected, it will be to here.
 	// In practice this is usually os.Stderr, but for testing can be replaced.
 	stderr io.Writer
+	// If true, the processes outlive this one, see StartDetached
+	detached bool
+	// If set, prefixes the captured output lines of the processes
+	logStamper *logStamper
 }
 
 // NewNodeProcess creates a new process of the passed binary
@@ -62,26 +94,54 @@ func (npc *nodeProcessCreator) NewNodeProcess(
 ) (NodeProcess, error) {
 	// Start the AvalancheGo node and pass it the flags defined above
 	cmd := exec.Command(config.BinaryPath, args...) //nolint
+	if len(config.Labels) != 0 {
+		cmd.Env = append(os.Environ(), node.LabelsEnv(config.Labels)...)
+	}
+	var u runAsUser
+	if config.RunAsUser != "" {
+		var err error
+		u, err = lookupRunAsUser(config.RunAsUser)
+		if err != nil {
+			return nil, err
+		}
+		if err := u.setCredential(cmd); err != nil {
+			return nil, err
+		}
+	}
 	// assign a new color to this process (might not be used if the config isn't set for it)
 	color := npc.colorPicker.NextColor()
-	// Optionally redirect stdout and stderr
+	// stdout and stderr are always captured, to keep the recent logs,
+	// and the panic trace if the node crashes, and optionally redirected
+	recent := newRecentLogs(recentLogsSize)
+	stdout := &stdoutCapture{recent: recent, stamper: npc.logStamper}
 	if config.RedirectStdout {
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			return nil, fmt.Errorf("couldn't create stdout pipe: %w", err)
-		}
+		reader, writer := io.Pipe()
+		stdout.out = writer
 		// redirect stdout and assign a color to the text
-		utils.ColorAndPrepend(stdout, npc.stdout, config.Name, color)
+		utils.ColorAndPrepend(reader, npc.stdout, config.Name, color)
 	}
+	stderr := &stderrCapture{recent: recent, stamper: npc.logStamper}
 	if config.RedirectStderr {
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			return nil, fmt.Errorf("couldn't create stderr pipe: %w", err)
-		}
+		reader, writer := io.Pipe()
+		stderr.out = writer
 		// redirect stderr and assign a color to the text
-		utils.ColorAndPrepend(stderr, npc.stderr, config.Name, color)
+		utils.ColorAndPrepend(reader, npc.stderr, config.Name, color)
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if npc.detached {
+		// the output pipes, and so the process writing to them, would break
+		// once this process exits, so the output is discarded: the node
+		// logs are on its log files
+		cmd.Stdout = nil
+		cmd.Stderr = nil
+		setDetached(cmd)
+	}
+	np, err := newNodeProcess(config.Name, npc.log, cmd, stdout, stderr, recent, startupTime)
+	if err != nil && config.RunAsUser != "" {
+		err = u.permissionError(err)
 	}
-	return newNodeProcess(config.Name, npc.log, cmd, startupTime)
+	return np, err
 }
 
 type nodeProcess struct {
@@ -93,12 +153,22 @@ type nodeProcess struct {
 	state status.Status
 	// Closed when the process exits.
 	closedOnStop chan struct{}
+	// Receive the process stdout and stderr
+	stdout *stdoutCapture
+	stderr *stderrCapture
+	// Last lines of the process stdout and stderr
+	recent *recentLogs
+	// Set if the process exited without Stop being called
+	crashReport *node.CrashReport
 }
 
 func newNodeProcess(
 	name string,
 	log logging.Logger,
 	cmd *exec.Cmd,
+	stdout *stdoutCapture,
+	stderr *stderrCapture,
+	recent *recentLogs,
 	startupTime time.Duration,
 ) (*nodeProcess, error) {
 	np := &nodeProcess{
@@ -106,6 +176,9 @@ func newNodeProcess(
 		log:          log,
 		cmd:          cmd,
 		closedOnStop: make(chan struct{}),
+		stdout:       stdout,
+		stderr:       stderr,
+		recent:       recent,
 	}
 	return np, np.start(startupTime)
 }
@@ -121,6 +194,8 @@ func (p *nodeProcess) start(
 	if err := p.cmd.Start(); err != nil {
 		p.state = status.Stopped
 		close(p.closedOnStop)
+		_ = p.stdout.Close()
+		_ = p.stderr.Close()
 		p.lock.Unlock()
 		return fmt.Errorf("couldn't start process: %w", err)
 	}
@@ -132,7 +207,8 @@ func (p *nodeProcess) start(
 	p.lock.Lock()
 	defer p.lock.Unlock()
 	if p.state != status.Running {
-		return fmt.Errorf("process failed before startup time of %.0f seconds", startupTime.Seconds())
+		err := fmt.Errorf("process failed before startup time of %.0f seconds", startupTime.Seconds())
+		return withLogs(err, p.recent.last(startupErrorLogLines))
 	}
 
 	return nil
@@ -146,10 +222,21 @@ func (p *nodeProcess) awaitExit() {
 	}
 
 	p.log.Debug("node process finished", zap.String("node", p.name))
+	_ = p.stdout.Close()
+	_ = p.stderr.Close()
 
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
+	if p.state == status.Running {
+		// not stopped by Stop
+		p.crashReport = &node.CrashReport{
+			NodeName:   p.name,
+			ExitCode:   p.cmd.ProcessState.ExitCode(),
+			Time:       time.Now(),
+			PanicTrace: p.stderr.getPanicTrace(),
+		}
+	}
 	p.state = status.Stopped
 	close(p.closedOnStop)
 }
@@ -209,6 +296,39 @@ func (p *nodeProcess) Status() status.Status {
 	return p.state
 }
 
+func (p *nodeProcess) getPID() int {
+	return p.cmd.Process.Pid
+}
+
+func (p *nodeProcess) signal(sig os.Signal) error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.state != status.Running {
+		return errNodeNotRunning
+	}
+	return p.cmd.Process.Signal(sig)
+}
+
+func (p *nodeProcess) getRecentLogs(n int) []string {
+	return p.recent.last(n)
+}
+
+func (p *nodeProcess) Done() <-chan struct{} {
+	return p.closedOnStop
+}
+
+func (p *nodeProcess) CrashReport() *node.CrashReport {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.crashReport == nil {
+		return nil
+	}
+	crashReport := *p.crashReport
+	return &crashReport
+}
+
 func killDescendants(pid int32, log logging.Logger) {
 	procs, err := process.Processes()
 	if err != nil {
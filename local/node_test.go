@@ -6,13 +6,16 @@ import (
 	"crypto"
 	"crypto/tls"
 	"encoding/binary"
+	"errors"
 	"io"
 	"net"
 	"net/netip"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/utils"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/message"
 	"github.com/ava-labs/avalanchego/network/peer"
@@ -264,3 +267,82 @@ func TestAttachPeer(t *testing.T) {
 	// also ensures that [require] calls will be reflected in test results if failed
 	require.NoError(<-errCh)
 }
+
+// TestIsHealthyRequestTimeout tests that a health request that doesn't
+// return is given up after the health request timeout of the network
+func TestIsHealthyRequestTimeout(t *testing.T) {
+	require := require.New(t)
+	net := &localNetwork{healthRequestTimeout: 100 * time.Millisecond}
+	node := localNode{client: newMockAPIHealthyBlocks("", 0), network: net}
+	start := time.Now()
+	require.False(node.isHealthy(context.Background()))
+	require.GreaterOrEqual(time.Since(start), net.healthRequestTimeout)
+
+	node = localNode{client: newMockAPISuccessful("", 0), network: net}
+	require.True(node.isHealthy(context.Background()))
+}
+
+func TestNodeProcessLabelsEnv(t *testing.T) {
+	require := require.New(t)
+	npc := &nodeProcessCreator{log: logging.NoLog{}, colorPicker: utils.NewColorPicker()}
+	proc, err := npc.NewNodeProcess(
+		node.Config{Name: "node", BinaryPath: "sh", Labels: map[string]string{"job": "ci"}},
+		0,
+		"-c", "echo $"+node.LabelEnvPrefix+"JOB",
+	)
+	require.NoError(err)
+	<-proc.Done()
+	require.Equal([]string{"ci"}, proc.(logsGetter).getRecentLogs(0))
+}
+
+func TestNodePID(t *testing.T) {
+	require := require.New(t)
+	npc := &nodeProcessCreator{log: logging.NoLog{}, colorPicker: utils.NewColorPicker()}
+	proc, err := npc.NewNodeProcess(node.Config{Name: "node", BinaryPath: "sleep"}, 0, "10")
+	require.NoError(err)
+	n := &localNode{process: proc}
+	pid := n.PID()
+	require.Equal(proc.(*nodeProcess).cmd.Process.Pid, pid)
+	_ = proc.Stop(context.Background())
+	<-proc.Done()
+	require.Zero(n.PID())
+
+	// mocked processes have no process ID
+	mockProc, err := newMockProcessSuccessful(node.Config{})
+	require.NoError(err)
+	n = &localNode{process: mockProc}
+	require.Zero(n.PID())
+}
+
+func TestNodeSignal(t *testing.T) {
+	require := require.New(t)
+	npc := &nodeProcessCreator{log: logging.NoLog{}, colorPicker: utils.NewColorPicker()}
+	// exits with 3 on SIGHUP
+	proc, err := npc.NewNodeProcess(
+		node.Config{Name: "node", BinaryPath: "sh"},
+		0,
+		"-c", `trap "exit 3" HUP; while true; do sleep 0.01; done`,
+	)
+	require.NoError(err)
+	n := &localNode{name: "node", process: proc, network: &localNetwork{log: logging.NoLog{}}}
+	// the trap may not be set yet
+	require.Eventually(func() bool {
+		if err := n.Signal(syscall.SIGHUP); err != nil {
+			// exited on a previous signal
+			return errors.Is(err, errNodeNotRunning)
+		}
+		select {
+		case <-proc.Done():
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(3, proc.CrashReport().ExitCode)
+	require.ErrorIs(n.Signal(syscall.SIGHUP), errNodeNotRunning)
+
+	mockProc, err := newMockProcessSuccessful(node.Config{})
+	require.NoError(err)
+	n = &localNode{process: mockProc}
+	require.Error(n.Signal(syscall.SIGHUP))
+}
@@ -0,0 +1,33 @@
+package local
+
+import (
+	"sort"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+)
+
+// Returns the sorted names of the nodes that can be validators,
+// that is, the ones that are not observers. See node.Config.Observer.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) validatorNodeNames() []string {
+	nodeNames := []string{}
+	for nodeName, node := range ln.nodes {
+		if !node.config.Observer {
+			nodeNames = append(nodeNames, nodeName)
+		}
+	}
+	sort.Strings(nodeNames)
+	return nodeNames
+}
+
+// Returns network.ErrObserverNode if one of [nodeNames] is an observer node.
+// Nodes not in the network are ignored, as they are added as validators.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) checkNotObservers(op string, nodeNames ...string) error {
+	for _, nodeName := range nodeNames {
+		if node, ok := ln.nodes[nodeName]; ok && node.config.Observer {
+			return &network.NodeError{NodeName: nodeName, Op: op, Err: network.ErrObserverNode}
+		}
+	}
+	return nil
+}
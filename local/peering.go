@@ -0,0 +1,144 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+)
+
+var errNotLocalNetwork = errors.New("network was not created by this package")
+
+// PeerNode restarts the node named [nodeName] of [net] so that it also
+// bootstraps from the node named [peerName] of [peerNet], connecting two
+// independent local networks. This allows testing a partition followed by a
+// merge, when both networks share the same network ID and genesis, or how
+// nodes handle peers on another network ID otherwise.
+// A node may be peered with several nodes, of one or more networks. Peers are
+// kept when the node is restarted, until it is removed or UnpeerNode is called,
+// but are not saved on snapshots. A paused node is not restarted, and gets its
+// peers on resume.
+// Both networks must have been created by this package, and must not be
+// public networks, where bootstrap nodes are not set by the runner.
+func PeerNode(ctx context.Context, net network.Network, nodeName string, peerNet network.Network, peerName string) error {
+	ln, ok := net.(*localNetwork)
+	if !ok {
+		return errNotLocalNetwork
+	}
+	peerLn, ok := peerNet.(*localNetwork)
+	if !ok {
+		return errNotLocalNetwork
+	}
+	if ln == peerLn {
+		return errors.New("can't peer nodes of the same network")
+	}
+	// only one network lock is held at a time, so that peering both ways
+	// concurrently can't deadlock
+	peerID, peerIP, err := peerLn.getPeerBeacon(peerName)
+	if err != nil {
+		return &network.NodeError{NodeName: peerName, Op: "peer with", Err: err}
+	}
+
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	if utils.IsPublicNetwork(ln.networkID) {
+		return errors.New("can't peer nodes of a public network")
+	}
+	if _, ok := ln.nodes[nodeName]; !ok {
+		return &network.NodeError{NodeName: nodeName, Op: "peer", Err: network.ErrNodeNotFound}
+	}
+	peers, ok := ln.peers[nodeName]
+	if !ok {
+		peers = map[ids.NodeID]netip.AddrPort{}
+		ln.peers[nodeName] = peers
+	}
+	peers[peerID] = peerIP
+	if err := ln.restartPeeredNode(ctx, nodeName); err != nil {
+		return &network.NodeError{NodeName: nodeName, Op: "peer", Err: err}
+	}
+	return ln.persistNetwork()
+}
+
+// UnpeerNode restarts the node named [nodeName] of [net] without the peers
+// given to it by PeerNode, so that it only bootstraps from its own network.
+// Connections made meanwhile by other nodes of the network are not affected.
+func UnpeerNode(ctx context.Context, net network.Network, nodeName string) error {
+	ln, ok := net.(*localNetwork)
+	if !ok {
+		return errNotLocalNetwork
+	}
+
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	if _, ok := ln.nodes[nodeName]; !ok {
+		return &network.NodeError{NodeName: nodeName, Op: "unpeer", Err: network.ErrNodeNotFound}
+	}
+	if _, ok := ln.peers[nodeName]; !ok {
+		return nil
+	}
+	delete(ln.peers, nodeName)
+	if err := ln.restartPeeredNode(ctx, nodeName); err != nil {
+		return &network.NodeError{NodeName: nodeName, Op: "unpeer", Err: err}
+	}
+	return ln.persistNetwork()
+}
+
+// Restarts the node named [nodeName] so that its peers are applied.
+// Paused nodes are kept paused, and get their peers on resume.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) restartPeeredNode(ctx context.Context, nodeName string) error {
+	if ln.nodes[nodeName].paused {
+		return nil
+	}
+	return ln.restartNode(ctx, nodeName, "", "", "", nil, nil, nil)
+}
+
+// Returns the ID and the P2P address other networks can bootstrap from
+// for the node named [nodeName].
+func (ln *localNetwork) getPeerBeacon(nodeName string) (ids.NodeID, netip.AddrPort, error) {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	if ln.stopCalled() {
+		return ids.EmptyNodeID, netip.AddrPort{}, network.ErrStopped
+	}
+	node, ok := ln.nodes[nodeName]
+	if !ok {
+		return ids.EmptyNodeID, netip.AddrPort{}, network.ErrNodeNotFound
+	}
+	ip, err := netip.ParseAddr(node.publicIP)
+	if err != nil {
+		return ids.EmptyNodeID, netip.AddrPort{}, err
+	}
+	return node.nodeID, netip.AddrPortFrom(ip, node.p2pPort), nil
+}
+
+// Returns the nodes the node named [nodeName] bootstraps from: the network
+// beacons, and the peers given to it by PeerNode.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) getNodeBootstraps(nodeName string) (beacon.Set, error) {
+	peers, ok := ln.peers[nodeName]
+	if !ok {
+		return ln.bootstraps, nil
+	}
+	bootstraps, err := utils.BeaconMapFromSet(ln.bootstraps)
+	if err != nil {
+		return nil, err
+	}
+	for peerID, peerIP := range peers {
+		bootstraps[peerID] = peerIP
+	}
+	return utils.BeaconMapToSet(bootstraps)
+}
@@ -0,0 +1,71 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerNode(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	newTestNetwork := func() *localNetwork {
+		net, err := newNetwork(
+			logging.NoLog{},
+			newMockAPISuccessful,
+			&localTestSuccessfulNodeProcessCreator{},
+			"",
+			"",
+			"",
+			false,
+			false,
+			false,
+			"",
+			beacon.NewSet(),
+			false,
+		)
+		require.NoError(err)
+		require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+		return net
+	}
+	net := newTestNetwork()
+	peerNet := newTestNetwork()
+	peer, err := peerNet.AddNode(node.Config{Name: "peer"})
+	require.NoError(err)
+	getBootstrapIDs := func() string {
+		configFileBytes, err := os.ReadFile(filepath.Join(net.nodes["node1"].GetDataDir(), configsPath, configFileName))
+		require.NoError(err)
+		var flags map[string]string
+		require.NoError(json.Unmarshal(configFileBytes, &flags))
+		return flags[config.BootstrapIDsKey]
+	}
+	beaconID := net.nodes["node0"].GetNodeID().String()
+	require.Equal(beaconID, getBootstrapIDs())
+
+	require.Error(PeerNode(context.Background(), net, "node1", net, "node0"))
+	require.ErrorIs(PeerNode(context.Background(), net, "node1", peerNet, "unknown"), network.ErrNodeNotFound)
+
+	node1Process := net.nodes["node1"].process
+	require.NoError(PeerNode(context.Background(), net, "node1", peerNet, "peer"))
+	require.NotSame(node1Process, net.nodes["node1"].process)
+	require.Contains(getBootstrapIDs(), beaconID)
+	require.Contains(getBootstrapIDs(), peer.GetNodeID().String())
+
+	// peers are kept on restart
+	require.NoError(net.RestartNode(context.Background(), "node1", "", "", "", nil, nil, nil))
+	require.Contains(getBootstrapIDs(), peer.GetNodeID().String())
+
+	require.NoError(UnpeerNode(context.Background(), net, "node1"))
+	require.Equal(beaconID, getBootstrapIDs())
+	require.NoError(net.Stop(context.Background()))
+	require.NoError(peerNet.Stop(context.Background()))
+}
@@ -0,0 +1,90 @@
+package local
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"go.uber.org/zap"
+)
+
+const (
+	// avalanchego error message when a port it was given is taken
+	portInUseMsg = "bind: address already in use"
+	// time waited before the first port race retry, doubled on each retry
+	portRaceRetryBackoff = 250 * time.Millisecond
+)
+
+// addNodeRetryingPortRaces adds a node as addNode does. If the node gets
+// free ports allocated by the runner, and fails to start because another
+// process took them before the node bound them, it is started again, with
+// new ports, up to [ln.portRaceRetries] times, backing off between retries.
+// Assumes [ln.lock] is held and [ln.Stop] hasn't been called.
+func (ln *localNetwork) addNodeRetryingPortRaces(nodeConfig node.Config) (node.Node, error) {
+	node, err := ln.addNode(nodeConfig)
+	if err == nil || ln.portRaceRetries <= 0 || !ln.allocatesPorts(nodeConfig) {
+		return node, err
+	}
+	backoff := portRaceRetryBackoff
+	for retry := 1; retry <= ln.portRaceRetries && failedToBindPorts(node); retry++ {
+		// keep the dir, and so the keys, of the failed attempt
+		nodeConfig.Name = node.GetName()
+		ln.log.Info("node failed to bind its allocated ports, retrying with new ones",
+			zap.String("node-name", nodeConfig.Name),
+			zap.Int("retry", retry),
+			zap.Duration("backoff", backoff),
+		)
+		select {
+		case <-ln.onStopCh:
+			return node, err
+		case <-ln.clock.After(backoff):
+		}
+		backoff *= 2
+		var retryErr error
+		node, retryErr = ln.addNode(nodeConfig)
+		if retryErr == nil {
+			return node, nil
+		}
+		err = retryErr
+	}
+	return node, err
+}
+
+// allocatesPorts returns true if none of the ports of [nodeConfig] are given
+// on its flags, the network flags or its config file, so that the runner
+// allocates new free ports to it each time it is started.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) allocatesPorts(nodeConfig node.Config) bool {
+	var configFile map[string]interface{}
+	if len(nodeConfig.ConfigFile) != 0 {
+		if err := json.Unmarshal([]byte(nodeConfig.ConfigFile), &configFile); err != nil {
+			return false
+		}
+	}
+	for _, portKey := range []string{config.HTTPPortKey, config.StakingPortKey} {
+		if _, ok := nodeConfig.Flags[portKey]; ok {
+			return false
+		}
+		if _, ok := ln.flags[portKey]; ok {
+			return false
+		}
+		if _, ok := configFile[portKey]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// failedToBindPorts returns true if [node], which failed to start, logged
+// that its ports were taken
+func failedToBindPorts(node node.Node) bool {
+	if node == nil {
+		return false
+	}
+	mainLog, err := os.ReadFile(filepath.Join(node.GetLogsDir(), mainLogFileName))
+	return err == nil && strings.Contains(string(mainLog), portInUseMsg)
+}
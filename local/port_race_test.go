@@ -0,0 +1,120 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+var _ NodeProcessCreator = (*localTestPortRaceProcessCreator)(nil)
+
+// localTestPortRaceProcessCreator fails to start the first [failures]
+// node processes as if their ports were taken, and records the API
+// ports of all the processes
+type localTestPortRaceProcessCreator struct {
+	failures int
+	apiPorts []string
+}
+
+func (lt *localTestPortRaceProcessCreator) NewNodeProcess(config node.Config, _ time.Duration, args ...string) (NodeProcess, error) {
+	configFilePath := strings.TrimPrefix(args[0], "--config-file=")
+	configFileBytes, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+	var flags map[string]string
+	if err := json.Unmarshal(configFileBytes, &flags); err != nil {
+		return nil, err
+	}
+	lt.apiPorts = append(lt.apiPorts, flags["http-port"])
+	if lt.failures == 0 {
+		return newMockProcessSuccessful(config, args...)
+	}
+	lt.failures--
+	logsDir := filepath.Join(flags["data-dir"], defaultLogsSubdir)
+	if err := createFileAndWrite(filepath.Join(logsDir, mainLogFileName), []byte("listen tcp 127.0.0.1:9650: "+portInUseMsg)); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("process failed before startup time")
+}
+
+func (*localTestPortRaceProcessCreator) GetNodeVersion(node.Config) (string, error) {
+	return nodeVersion, nil
+}
+
+func newPortRaceTestNetwork(t *testing.T, npc NodeProcessCreator, portRaceRetries int) *localNetwork {
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, npc, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(t, err)
+	net.clock = newFakeClock()
+	networkConfig := testNetworkConfig(t)
+	networkConfig.NodeConfigs = nil
+	networkConfig.PortRaceRetries = portRaceRetries
+	require.NoError(t, net.loadConfig(context.Background(), networkConfig))
+	return net
+}
+
+func TestAddNodeRetriesPortRaces(t *testing.T) {
+	require := require.New(t)
+	npc := &localTestPortRaceProcessCreator{failures: 2}
+	net := newPortRaceTestNetwork(t, npc, 0)
+
+	addedNode, err := net.AddNode(node.Config{Name: "node1"})
+	require.NoError(err)
+	require.Equal("node1", addedNode.Node.GetName())
+	require.Len(npc.apiPorts, 3)
+	require.NotEqual(npc.apiPorts[0], npc.apiPorts[2])
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestAddNodePortRaceRetriesExhausted(t *testing.T) {
+	require := require.New(t)
+	npc := &localTestPortRaceProcessCreator{failures: network.DefaultPortRaceRetries + 1}
+	net := newPortRaceTestNetwork(t, npc, 0)
+
+	_, err := net.AddNode(node.Config{Name: "node1"})
+	require.Error(err)
+	require.Len(npc.apiPorts, network.DefaultPortRaceRetries+1)
+	require.NotContains(net.nodes, "node1")
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestAddNodeNoPortRaceRetries(t *testing.T) {
+	tests := []struct {
+		name            string
+		portRaceRetries int
+		flags           map[string]interface{}
+	}{
+		{
+			name:            "disabled",
+			portRaceRetries: -1,
+		},
+		{
+			name:  "given ports",
+			flags: map[string]interface{}{config.HTTPPortKey: 9650, config.StakingPortKey: 9651},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			npc := &localTestPortRaceProcessCreator{failures: 1}
+			net := newPortRaceTestNetwork(t, npc, tt.portRaceRetries)
+
+			_, err := net.AddNode(node.Config{Name: "node1", Flags: tt.flags})
+			require.Error(err)
+			require.Len(npc.apiPorts, 1)
+			require.NoError(net.Stop(context.Background()))
+		})
+	}
+}
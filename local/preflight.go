@@ -0,0 +1,229 @@
+package local
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/ulimit"
+	"github.com/shirou/gopsutil/disk"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// MinAvalancheGoVersion is the oldest avalanchego version whose
+	// genesis and APIs are compatible with the network runner
+	MinAvalancheGoVersion = "v1.11.0"
+	// free disk space required on the root data dir for each node, for its
+	// db and logs while bootstrapping a local network
+	minFreeDiskSpacePerNode = 512 * 1024 * 1024
+)
+
+// PreflightError lists all the problems found by Preflight
+type PreflightError struct {
+	Problems []error
+}
+
+func (e *PreflightError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = problem.Error()
+	}
+	return fmt.Sprintf("preflight checks failed: %s", strings.Join(problems, "; "))
+}
+
+func (e *PreflightError) Unwrap() []error {
+	return e.Problems
+}
+
+// Preflight checks that the environment can run a network with [networkConfig]:
+// the avalanchego binaries exist, are executable and are of a supported version,
+// the ports given to the nodes are free, the open files limit allows the nodes'
+// fd limits, and there is enough disk space on the root data dir.
+// Returns a *PreflightError with all the problems found, so that they can be
+// fixed at once, instead of showing up as failures once the network is started,
+// or nil if there are none.
+func Preflight(networkConfig network.Config) error {
+	var problems []error
+
+	// binary path --> names of the nodes using it
+	binaryNodes := map[string][]string{}
+	for _, nodeConfig := range networkConfig.NodeConfigs {
+		binaryPath := nodeConfig.BinaryPath
+		if binaryPath == "" {
+			binaryPath = networkConfig.BinaryPath
+		}
+		if binaryPath == "" {
+			problems = append(problems, fmt.Errorf("node %q: no binary path given", nodeConfig.Name))
+			continue
+		}
+		binaryNodes[binaryPath] = append(binaryNodes[binaryPath], nodeConfig.Name)
+	}
+	binaryPaths := make([]string, 0, len(binaryNodes))
+	for binaryPath := range binaryNodes {
+		binaryPaths = append(binaryPaths, binaryPath)
+	}
+	sort.Strings(binaryPaths)
+	for _, binaryPath := range binaryPaths {
+		if err := checkBinary(binaryPath); err != nil {
+			problems = append(problems, fmt.Errorf("binary of nodes %v: %w", binaryNodes[binaryPath], err))
+		}
+	}
+
+	openFilesLimit, checkOpenFiles, err := getOpenFilesLimit()
+	if err != nil {
+		problems = append(problems, fmt.Errorf("couldn't get open files limit: %w", err))
+	}
+	// port --> name of the first node using it
+	usedPorts := map[uint16]string{}
+	for _, nodeConfig := range networkConfig.NodeConfigs {
+		flagMaps, err := getPreflightFlagMaps(networkConfig, nodeConfig)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("node %q: %w", nodeConfig.Name, err))
+			continue
+		}
+		for _, portKey := range []string{config.HTTPPortKey, config.StakingPortKey} {
+			port, ok, err := getGivenPort(flagMaps, portKey)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("node %q: %w", nodeConfig.Name, err))
+				continue
+			}
+			if !ok || port == 0 {
+				// a free port is chosen on start
+				continue
+			}
+			if nodeName, ok := usedPorts[port]; ok {
+				problems = append(problems, fmt.Errorf("node %q: port %d is also given to node %q", nodeConfig.Name, port, nodeName))
+				continue
+			}
+			usedPorts[port] = nodeConfig.Name
+			if !isFreePort(port) {
+				problems = append(problems, fmt.Errorf("node %q: port %d is in use", nodeConfig.Name, port))
+			}
+		}
+		if checkOpenFiles {
+			fdLimit, err := getGivenFDLimit(flagMaps)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("node %q: %w", nodeConfig.Name, err))
+			} else if fdLimit > openFilesLimit {
+				problems = append(problems, fmt.Errorf(
+					"node %q: fd limit %d is greater than the open files hard limit %d, raise it with ulimit -Hn",
+					nodeConfig.Name, fdLimit, openFilesLimit,
+				))
+			}
+		}
+	}
+
+	rootDir := networkConfig.RootDataDir.Path
+	if rootDir == "" {
+		rootDir = os.TempDir()
+	}
+	if err := checkFreeDiskSpace(rootDir, uint64(len(networkConfig.NodeConfigs))*minFreeDiskSpacePerNode); err != nil {
+		problems = append(problems, err)
+	}
+
+	if len(problems) > 0 {
+		return &PreflightError{Problems: problems}
+	}
+	return nil
+}
+
+// checkBinary returns an error if [binaryPath] is not an executable
+// avalanchego binary of a supported version
+func checkBinary(binaryPath string) error {
+	info, err := os.Stat(binaryPath)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%q is a directory", binaryPath)
+	}
+	if info.Mode()&0o111 == 0 {
+		return fmt.Errorf("%q is not executable", binaryPath)
+	}
+	nodeSemVer, err := getNodeSemVer(&nodeProcessCreator{}, node.Config{BinaryPath: binaryPath})
+	if err != nil {
+		return err
+	}
+	if semver.Compare(nodeSemVer, MinAvalancheGoVersion) < 0 {
+		return fmt.Errorf("avalanchego version %s of %q is not supported, %s or newer is required", nodeSemVer, binaryPath, MinAvalancheGoVersion)
+	}
+	return nil
+}
+
+// Returns the flags given to a node of [networkConfig] with [nodeConfig],
+// by order of precedence: node flags, network flags, and node config file.
+func getPreflightFlagMaps(networkConfig network.Config, nodeConfig node.Config) ([]map[string]interface{}, error) {
+	var configFile map[string]interface{}
+	if len(nodeConfig.ConfigFile) != 0 {
+		if err := json.Unmarshal([]byte(nodeConfig.ConfigFile), &configFile); err != nil {
+			return nil, fmt.Errorf("couldn't unmarshal config file: %w", err)
+		}
+	}
+	return []map[string]interface{}{nodeConfig.Flags, networkConfig.Flags, configFile}, nil
+}
+
+// Returns the port given for [portKey] on the first of [flagMaps] that has it,
+// and false if none has it.
+func getGivenPort(flagMaps []map[string]interface{}, portKey string) (uint16, bool, error) {
+	for _, flags := range flagMaps {
+		if _, ok := flags[portKey]; ok {
+			port, err := getPort(flags, nil, portKey)
+			return port, true, err
+		}
+	}
+	return 0, false, nil
+}
+
+// Returns the fd limit the node is given on the first of [flagMaps] that has
+// it, or the avalanchego default.
+func getGivenFDLimit(flagMaps []map[string]interface{}) (uint64, error) {
+	for _, flags := range flagMaps {
+		fdLimitIntf, ok := flags[config.FdLimitKey]
+		if !ok {
+			continue
+		}
+		switch fdLimit := fdLimitIntf.(type) {
+		case int:
+			return uint64(fdLimit), nil
+		case float64:
+			return uint64(fdLimit), nil
+		default:
+			return 0, fmt.Errorf("expected flag %q to be int/float64 but got %T", config.FdLimitKey, fdLimitIntf)
+		}
+	}
+	return ulimit.DefaultFDLimit, nil
+}
+
+// checkFreeDiskSpace returns an error if the filesystem of [dir], or of its
+// closest existing parent if it doesn't exist yet, has less than [required]
+// bytes free.
+func checkFreeDiskSpace(dir string, required uint64) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		} else if !errors.Is(err, os.ErrNotExist) || filepath.Dir(dir) == dir {
+			return err
+		}
+		dir = filepath.Dir(dir)
+	}
+	usage, err := disk.Usage(dir)
+	if err != nil {
+		return fmt.Errorf("couldn't get free disk space of %q: %w", dir, err)
+	}
+	if usage.Free < required {
+		return fmt.Errorf("%d MiB of disk space free on %q, at least %d MiB are required", usage.Free/(1024*1024), dir, required/(1024*1024))
+	}
+	return nil
+}
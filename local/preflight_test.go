@@ -0,0 +1,117 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/stretchr/testify/require"
+)
+
+// Writes a script to [dir] that prints [version] as avalanchego does
+// on --version, and returns its path
+func writeVersionScript(t *testing.T, dir string, name string, version string) string {
+	path := filepath.Join(dir, name)
+	script := fmt.Sprintf("#!/bin/sh\necho \"avalanchego/%s [database=v1.4.5]\"\n", version)
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o700)) //nolint:gosec
+	return path
+}
+
+func TestPreflight(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	supportedBinary := writeVersionScript(t, dir, "supported", "1.11.13")
+	oldBinary := writeVersionScript(t, dir, "old", "1.10.17")
+	notExecutable := filepath.Join(dir, "not-executable")
+	require.NoError(os.WriteFile(notExecutable, nil, 0o600))
+
+	// the default fd limit may be greater than the open files hard limit
+	fdLimit := 1024
+	require.NoError(Preflight(network.Config{
+		BinaryPath:  supportedBinary,
+		Flags:       map[string]interface{}{config.FdLimitKey: fdLimit},
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	defer listener.Close()
+	usedPort := listener.Addr().(*net.TCPAddr).Port
+
+	err = Preflight(network.Config{
+		Flags: map[string]interface{}{config.HTTPPortKey: usedPort, config.FdLimitKey: fdLimit},
+		NodeConfigs: []node.Config{
+			{Name: "no-binary"},
+			{Name: "missing", BinaryPath: filepath.Join(dir, "missing")},
+			{Name: "not-executable", BinaryPath: notExecutable},
+			{Name: "old", BinaryPath: oldBinary},
+			{
+				Name:       "dup-port",
+				BinaryPath: supportedBinary,
+				Flags:      map[string]interface{}{config.HTTPPortKey: 0},
+				ConfigFile: `{"staking-port": 30001}`,
+			},
+			{
+				Name:       "dup-port2",
+				BinaryPath: supportedBinary,
+				Flags:      map[string]interface{}{config.HTTPPortKey: 0, config.StakingPortKey: 30001},
+			},
+		},
+	})
+	var preflightErr *PreflightError
+	require.ErrorAs(err, &preflightErr)
+	require.ErrorIs(err, os.ErrNotExist)
+	problems := []string{}
+	for _, problem := range preflightErr.Problems {
+		problems = append(problems, problem.Error())
+	}
+	require.Contains(problems, `node "no-binary": no binary path given`)
+	require.Contains(problems, fmt.Sprintf(`binary of nodes [not-executable]: %q is not executable`, notExecutable))
+	require.Contains(problems, fmt.Sprintf(
+		`binary of nodes [old]: avalanchego version v1.10.17 of %q is not supported, %s or newer is required`,
+		oldBinary, MinAvalancheGoVersion,
+	))
+	require.Contains(problems, fmt.Sprintf(`node "no-binary": port %d is in use`, usedPort))
+	require.Contains(problems, `node "dup-port2": port 30001 is also given to node "dup-port"`)
+	// the ports given on the network flags are shared by the nodes not overriding them
+	require.Contains(problems, fmt.Sprintf(`node "missing": port %d is also given to node "no-binary"`, usedPort))
+}
+
+func TestPreflightOpenFilesLimit(t *testing.T) {
+	require := require.New(t)
+	openFilesLimit, ok, err := getOpenFilesLimit()
+	require.NoError(err)
+	if !ok || openFilesLimit >= math.MaxInt32 {
+		t.Skip("open files limit is not enforced")
+	}
+	binaryPath := writeVersionScript(t, t.TempDir(), "avalanchego", "1.11.13")
+	err = Preflight(network.Config{
+		BinaryPath: binaryPath,
+		NodeConfigs: []node.Config{
+			{Name: "node1", Flags: map[string]interface{}{config.FdLimitKey: int(openFilesLimit)}},
+			{Name: "node2", Flags: map[string]interface{}{config.FdLimitKey: int(openFilesLimit) + 1}},
+		},
+	})
+	var preflightErr *PreflightError
+	require.ErrorAs(err, &preflightErr)
+	require.Len(preflightErr.Problems, 1)
+	require.Contains(preflightErr.Problems[0].Error(), `node "node2": fd limit`)
+}
+
+func TestCheckFreeDiskSpace(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	require.NoError(checkFreeDiskSpace(dir, 0))
+	// dirs not yet created are checked on their parent
+	require.NoError(checkFreeDiskSpace(filepath.Join(dir, "a", "b"), 0))
+	err := checkFreeDiskSpace(dir, math.MaxUint64)
+	require.Error(err)
+	require.False(errors.Is(err, os.ErrNotExist))
+}
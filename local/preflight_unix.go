@@ -0,0 +1,15 @@
+//go:build !windows
+
+package local
+
+import "syscall"
+
+// Returns the hard limit of open files, inherited by the node processes.
+// avalanchego fails to start if its fd limit is greater.
+func getOpenFilesLimit() (uint64, bool, error) {
+	var rLimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rLimit); err != nil {
+		return 0, false, err
+	}
+	return uint64(rLimit.Max), true, nil
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package local
+
+// There is no open files limit to check on windows.
+func getOpenFilesLimit() (uint64, bool, error) {
+	return 0, false, nil
+}
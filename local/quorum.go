@@ -0,0 +1,101 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/ids"
+	avagoConstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+)
+
+// See network.Network
+func (ln *localNetwork) QuorumStatus(ctx context.Context) (network.QuorumStatus, error) {
+	ln.lock.RLock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return network.QuorumStatus{}, network.ErrStopped
+	}
+	nodes := ln.runningNodes()
+	// node ID --> node name, for all the nodes, including the paused ones
+	nodeNames := make(map[ids.NodeID]string, len(ln.nodes))
+	for _, node := range ln.nodes {
+		nodeNames[node.nodeID] = node.name
+	}
+	ln.lock.RUnlock()
+	if len(nodes) == 0 {
+		return network.QuorumStatus{}, network.ErrNoRunningNodes
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].name < nodes[j].name
+	})
+
+	var (
+		lock    sync.Mutex
+		wg      sync.WaitGroup
+		healthy = map[ids.NodeID]bool{}
+	)
+	for _, node := range nodes {
+		node := node
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if node.isHealthy(ctx) {
+				lock.Lock()
+				healthy[node.nodeID] = true
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	vdrs, err := ln.getCurrentValidators(ctx, nodes, healthy)
+	if err != nil {
+		return network.QuorumStatus{}, err
+	}
+	status := network.QuorumStatus{
+		Online:  map[ids.NodeID]string{},
+		Offline: map[ids.NodeID]string{},
+	}
+	for _, vdr := range vdrs {
+		status.TotalStake += vdr.Weight
+		if healthy[vdr.NodeID] {
+			status.OnlineStake += vdr.Weight
+			status.Online[vdr.NodeID] = nodeNames[vdr.NodeID]
+		} else {
+			status.OfflineStake += vdr.Weight
+			status.Offline[vdr.NodeID] = nodeNames[vdr.NodeID]
+		}
+	}
+	return status, nil
+}
+
+// Returns the current primary network validators, as reported by the
+// first of [nodes] that is [healthy] and answers.
+// Assumes [ln.lock] is not held.
+func (ln *localNetwork) getCurrentValidators(
+	ctx context.Context,
+	nodes []*localNode,
+	healthy map[ids.NodeID]bool,
+) ([]platformvm.ClientPermissionlessValidator, error) {
+	errs := []error{}
+	for _, node := range nodes {
+		if !healthy[node.nodeID] || !node.beginCall() {
+			continue
+		}
+		vdrs, err := node.client.PChainAPI().GetCurrentValidators(ctx, avagoConstants.PrimaryNetworkID, nil)
+		node.endCall()
+		if err == nil {
+			return vdrs, nil
+		}
+		errs = append(errs, &network.NodeError{NodeName: node.name, Op: "get validators from", Err: err})
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("no healthy node to get the validators from")
+	}
+	return nil, fmt.Errorf("couldn't get validators: %w", errors.Join(errs...))
+}
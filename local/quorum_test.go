@@ -0,0 +1,64 @@
+package local
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/api"
+	apimocks "github.com/ava-labs/avalanche-network-runner/api/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuorumStatus(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	var net *localNetwork
+	newAPIClientF := func(ip string, port uint16) api.Client {
+		client := newMockAPISuccessful(ip, port).(*apimocks.Client)
+		client.On("PChainAPI").Return(&fakePChainClient{net: &net})
+		return client
+	}
+	net, err := newNetwork(logging.NoLog{}, newAPIClientF, &localTestSuccessfulNodeProcessCreator{}, "", "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+
+	quorum, err := net.QuorumStatus(context.Background())
+	require.NoError(err)
+	require.Equal(uint64(3), quorum.TotalStake)
+	require.Equal(uint64(3), quorum.OnlineStake)
+	require.Zero(quorum.OfflineStake)
+	require.Len(quorum.Online, 3)
+	require.Empty(quorum.Offline)
+	require.True(quorum.HasQuorum())
+
+	// paused and unhealthy validators are offline
+	require.NoError(net.PauseNode(context.Background(), "node0"))
+	healthyClients := map[string]api.Client{"node1": net.nodes["node1"].client, "node2": net.nodes["node2"].client}
+	net.nodes["node1"].client = newMockAPIUnhealthy("", 0)
+	quorum, err = net.QuorumStatus(context.Background())
+	require.NoError(err)
+	require.Equal(uint64(1), quorum.OnlineStake)
+	require.Equal(uint64(2), quorum.OfflineStake)
+	require.Equal(map[ids.NodeID]string{net.nodes["node2"].nodeID: "node2"}, quorum.Online)
+	require.Equal(map[ids.NodeID]string{
+		net.nodes["node0"].nodeID: "node0",
+		net.nodes["node1"].nodeID: "node1",
+	}, quorum.Offline)
+	require.False(quorum.HasQuorum())
+
+	// the validators are only asked to healthy nodes
+	net.nodes["node2"].client = newMockAPIUnhealthy("", 0)
+	_, err = net.QuorumStatus(context.Background())
+	require.Error(err)
+
+	for nodeName, client := range healthyClients {
+		net.nodes[nodeName].client = client
+	}
+	require.NoError(net.Stop(context.Background()))
+	_, err = net.QuorumStatus(context.Background())
+	require.ErrorIs(err, network.ErrStopped)
+}
@@ -0,0 +1,126 @@
+package local
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// max number of lines of a node process output kept, see node.Node.RecentLogs
+const recentLogsSize = 1000
+
+// recentLogs keeps the last lines a node process wrote to its stdout and
+// stderr in a ring buffer, so that they can be retrieved even if no one
+// was reading the output at the time. Adding a line never blocks on
+// readers: once the buffer is full, the oldest line is overwritten.
+type recentLogs struct {
+	lock  sync.Mutex
+	lines []string
+	// index of the oldest line, once [lines] is full
+	start int
+}
+
+func newRecentLogs(size int) *recentLogs {
+	return &recentLogs{lines: make([]string, 0, size)}
+}
+
+func (r *recentLogs) add(line string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if len(r.lines) < cap(r.lines) {
+		r.lines = append(r.lines, line)
+		return
+	}
+	r.lines[r.start] = line
+	r.start = (r.start + 1) % len(r.lines)
+}
+
+// Returns the last [n] lines, oldest first, or all of them if [n] is not positive.
+func (r *recentLogs) last(n int) []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if n <= 0 || n > len(r.lines) {
+		n = len(r.lines)
+	}
+	lines := make([]string, 0, n)
+	for i := len(r.lines) - n; i < len(r.lines); i++ {
+		lines = append(lines, r.lines[(r.start+i)%len(r.lines)])
+	}
+	return lines
+}
+
+// Appends [p] to [partial], calling [onLine] with each line completed,
+// including its newline. [partial] is left with the unterminated line.
+func splitLines(partial *[]byte, p []byte, onLine func([]byte)) {
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			*partial = append(*partial, p...)
+			return
+		}
+		*partial = append(*partial, p[:i+1]...)
+		onLine(*partial)
+		*partial = (*partial)[:0]
+		p = p[i+1:]
+	}
+}
+
+// Returns [line] as kept by recentLogs, without its line ending.
+func logLine(line []byte) string {
+	return string(bytes.TrimRight(line, "\r\n"))
+}
+
+// stdoutCapture receives the stdout of a node process, keeping its last
+// lines in [recent], and forwarding it to [out], if set.
+type stdoutCapture struct {
+	lock sync.Mutex
+	// the stdout is forwarded here, if redirected
+	out io.WriteCloser
+	// current line, not yet terminated
+	line   []byte
+	recent *recentLogs
+	// if set, the lines are forwarded and kept stamped,
+	// and so only once terminated
+	stamper *logStamper
+}
+
+func (c *stdoutCapture) Write(p []byte) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.out != nil && c.stamper == nil {
+		// the output is only for display, don't fail the process on it
+		_, _ = c.out.Write(p)
+	}
+	splitLines(&c.line, p, c.addLine)
+	return len(p), nil
+}
+
+// Assumes [c.lock] is held.
+func (c *stdoutCapture) addLine(line []byte) {
+	if c.stamper != nil {
+		line = c.stamper.stamp(line)
+		if c.out != nil {
+			_, _ = c.out.Write(line)
+		}
+	}
+	c.recent.add(logLine(line))
+}
+
+// Close keeps the last line, if not terminated, and closes [c.out], if set.
+// Called once the process exits.
+func (c *stdoutCapture) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(c.line) > 0 {
+		c.addLine(c.line)
+		c.line = c.line[:0]
+	}
+	if c.out == nil {
+		return nil
+	}
+	return c.out.Close()
+}
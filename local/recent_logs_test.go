@@ -0,0 +1,62 @@
+package local
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentLogs(t *testing.T) {
+	require := require.New(t)
+	recent := newRecentLogs(3)
+	require.Empty(recent.last(2))
+	recent.add("line 1")
+	recent.add("line 2")
+	require.Equal([]string{"line 1", "line 2"}, recent.last(0))
+	require.Equal([]string{"line 2"}, recent.last(1))
+	// the oldest lines are overwritten once full
+	recent.add("line 3")
+	recent.add("line 4")
+	recent.add("line 5")
+	require.Equal([]string{"line 3", "line 4", "line 5"}, recent.last(10))
+	require.Equal([]string{"line 4", "line 5"}, recent.last(2))
+}
+
+func TestStdoutCapture(t *testing.T) {
+	require := require.New(t)
+	recent := newRecentLogs(recentLogsSize)
+	capture := &stdoutCapture{recent: recent}
+	for _, s := range []string{"line 1\r\nli", "ne 2\n", "\nunterminated"} {
+		n, err := capture.Write([]byte(s))
+		require.NoError(err)
+		require.Len(s, n)
+	}
+	require.Equal([]string{"line 1", "line 2", ""}, recent.last(0))
+	require.NoError(capture.Close())
+	require.Equal([]string{"line 1", "line 2", "", "unterminated"}, recent.last(0))
+}
+
+// TestNodeProcessRecentLogs checks that the output of a process is kept,
+// interleaving its stdout and stderr, whether it is redirected or not
+func TestNodeProcessRecentLogs(t *testing.T) {
+	require := require.New(t)
+	npc := &nodeProcessCreator{log: logging.NoLog{}, colorPicker: utils.NewColorPicker(), stdout: io.Discard, stderr: io.Discard}
+	script := `echo out 1; sleep 0.1; echo err 1 >&2; sleep 0.1; echo out 2`
+	for _, redirect := range []bool{false, true} {
+		proc, err := npc.NewNodeProcess(
+			node.Config{Name: "logging", BinaryPath: "sh", RedirectStdout: redirect, RedirectStderr: redirect},
+			0,
+			"-c", script,
+		)
+		require.NoError(err)
+		<-proc.Done()
+		_ = proc.Stop(context.Background())
+		logs := proc.(logsGetter).getRecentLogs(0)
+		require.Equal([]string{"out 1", "err 1", "out 2"}, logs, "redirect %t", redirect)
+	}
+}
@@ -0,0 +1,365 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strconv"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanchego/config"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+)
+
+// flags that are managed by the runner itself, and so are only
+// considered on reconciliation if explicitly given in the desired config
+var runnerManagedFlags = []string{
+	config.DataDirKey,
+	config.DBPathKey,
+	config.LogsDirKey,
+	config.HTTPPortKey,
+	config.StakingPortKey,
+}
+
+// reconcilePlan holds the changes needed to make the network match a desired config
+type reconcilePlan struct {
+	// names of the nodes to be removed
+	toRemove []string
+	// configs of the nodes to be restarted with a new config
+	toRestart []node.Config
+	// configs of the nodes to be added
+	toAdd []node.Config
+}
+
+// See network.Network
+func (ln *localNetwork) Reconcile(ctx context.Context, desired network.Config) (err error) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+	record := ln.startOperation("Reconcile", "", map[string]string{"nodes": strconv.Itoa(len(desired.NodeConfigs))})
+	defer ln.finishOperation(record, &err)
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	err = ln.reconcile(ctx, desired)
+	// persist also on failure, as some nodes may have already been changed
+	if persistErr := ln.persistNetwork(); err == nil {
+		err = persistErr
+	}
+	return err
+}
+
+// Assumes [ln.lock] is held and [ln.Stop] hasn't been called.
+func (ln *localNetwork) reconcile(ctx context.Context, desired network.Config) (err error) {
+	if err := desired.ApplyBeaconPolicy(); err != nil {
+		return err
+	}
+	if err := ln.checkReconcilable(desired); err != nil {
+		return err
+	}
+	plan, err := ln.planReconcile(desired)
+	if err != nil {
+		return err
+	}
+	if err := ln.checkReconcileQuorumGuard(ctx, plan); err != nil {
+		return err
+	}
+	if err := ln.checkResources(len(plan.toAdd) - len(plan.toRemove)); err != nil {
+		return err
+	}
+	ln.log.Info("reconciling network",
+		zap.Strings("remove", plan.toRemove),
+		zap.Int("restart", len(plan.toRestart)),
+		zap.Int("add", len(plan.toAdd)),
+	)
+	// new network defaults apply to both restarted and added nodes,
+	// and are only kept if all the changes succeed
+	flags := ln.flags
+	binaryPath := ln.binaryPath
+	chainConfigFiles := ln.chainConfigFiles
+	upgradeConfigFiles := ln.upgradeConfigFiles
+	subnetConfigFiles := ln.subnetConfigFiles
+	defer func() {
+		if err != nil {
+			ln.flags = flags
+			ln.binaryPath = binaryPath
+			ln.chainConfigFiles = chainConfigFiles
+			ln.upgradeConfigFiles = upgradeConfigFiles
+			ln.subnetConfigFiles = subnetConfigFiles
+		}
+	}()
+	if desired.Flags != nil {
+		ln.flags = desired.Flags
+	}
+	if desired.BinaryPath != "" {
+		ln.binaryPath = desired.BinaryPath
+	}
+	if desired.ChainConfigFiles != nil {
+		ln.chainConfigFiles = desired.ChainConfigFiles
+	}
+	if desired.UpgradeConfigFiles != nil {
+		ln.upgradeConfigFiles = desired.UpgradeConfigFiles
+	}
+	if desired.SubnetConfigFiles != nil {
+		ln.subnetConfigFiles = desired.SubnetConfigFiles
+	}
+	for _, nodeName := range plan.toRemove {
+		if err := ln.removeNode(ctx, nodeName); err != nil {
+			return &network.NodeError{NodeName: nodeName, Op: "remove", Err: err}
+		}
+		delete(ln.peers, nodeName)
+		ln.removeNodeLinks(nodeName)
+	}
+	for _, nodeConfig := range plan.toRestart {
+		node := ln.nodes[nodeConfig.Name]
+		// keep same ports, data dirs, and identity
+		nodeConfig.Flags[config.DataDirKey] = node.GetDataDir()
+		nodeConfig.Flags[config.DBPathKey] = node.GetDbDir()
+		nodeConfig.Flags[config.LogsDirKey] = node.GetLogsDir()
+		if _, ok := nodeConfig.Flags[config.HTTPPortKey]; !ok {
+			nodeConfig.Flags[config.HTTPPortKey] = int(node.GetAPIPort())
+		}
+		if _, ok := nodeConfig.Flags[config.StakingPortKey]; !ok {
+			nodeConfig.Flags[config.StakingPortKey] = int(node.GetP2PPort())
+		}
+		if nodeConfig.StakingKey == "" || nodeConfig.StakingCert == "" {
+			nodeConfig.StakingKey = node.config.StakingKey
+			nodeConfig.StakingCert = node.config.StakingCert
+		}
+		if nodeConfig.StakingSigningKey == "" {
+			nodeConfig.StakingSigningKey = node.config.StakingSigningKey
+		}
+		if !node.paused {
+			if err := ln.removeNode(ctx, nodeConfig.Name); err != nil {
+				return &network.NodeError{NodeName: nodeConfig.Name, Op: "stop", Err: err}
+			}
+		}
+		if err := ln.replaceNode(node, nodeConfig); err != nil {
+			return &network.NodeError{NodeName: nodeConfig.Name, Op: "restart", Err: err}
+		}
+	}
+	for _, nodeConfig := range plan.toAdd {
+		if _, err := ln.addNodeRetryingPortRaces(nodeConfig); err != nil {
+			return &network.NodeError{NodeName: nodeConfig.Name, Op: "add", Err: err}
+		}
+	}
+	return nil
+}
+
+// checkReconcileQuorumGuard returns ErrQuorumLoss if the quorum guard is set,
+// and [plan] would stop more stake than it allows. The removed nodes are
+// stopped together with each restarted node in turn.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) checkReconcileQuorumGuard(ctx context.Context, plan reconcilePlan) error {
+	if ln.maxStoppedStake == 0 {
+		return nil
+	}
+	removed := make([]*localNode, 0, len(plan.toRemove))
+	for _, nodeName := range plan.toRemove {
+		removed = append(removed, ln.nodes[nodeName])
+	}
+	if len(plan.toRestart) == 0 {
+		return ln.checkQuorumGuard(ctx, removed...)
+	}
+	for _, nodeConfig := range plan.toRestart {
+		if err := ln.checkQuorumGuard(ctx, append(removed, ln.nodes[nodeConfig.Name])...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkReconcilable returns an error if [desired] changes network
+// settings that can't be changed on a running network.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) checkReconcilable(desired network.Config) error {
+	if desired.NetworkID != 0 && desired.NetworkID != ln.networkID {
+		return fmt.Errorf("can't change network ID from %d to %d", ln.networkID, desired.NetworkID)
+	}
+	if desired.Genesis != "" {
+		matches, err := genesisMatches(ln.genesisData, []byte(desired.Genesis), ln.networkID)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			return errors.New("can't change the genesis of a running network")
+		}
+	}
+	if desired.RootDataDir.Mode != network.DefaultRootDataDir &&
+		(desired.RootDataDir.Mode != ln.rootDataDirMode ||
+			desired.RootDataDir.Mode == network.PersistentRootDataDir && filepath.Clean(desired.RootDataDir.Path) != filepath.Clean(ln.rootDir)) {
+		return errors.New("can't change the root data dir of a running network")
+	}
+	if desired.Upgrade != "" && desired.Upgrade != string(ln.upgradeData) {
+		return errors.New("can't change the upgrade file of a running network")
+	}
+	if desired.Labels != nil && !maps.Equal(desired.Labels, ln.labels) {
+		return errors.New("can't change the labels of a running network")
+	}
+	if len(desired.BeaconConfig) != 0 {
+		beaconConfig, err := utils.BeaconMapFromSet(ln.bootstraps)
+		if err != nil {
+			return err
+		}
+		if !maps.Equal(beaconConfig, desired.BeaconConfig) {
+			return errors.New("can't change the beacon config of a running network")
+		}
+	}
+	for _, nodeConfig := range desired.NodeConfigs {
+		if nodeConfig.StartDelay > 0 {
+			return fmt.Errorf("node %q: %w", nodeConfig.Name, network.ErrStartDelay)
+		}
+	}
+	if len(ln.delayedNodes) != 0 {
+		return errors.New("can't reconcile a network with nodes waiting for their start delay")
+	}
+	return nil
+}
+
+// genesisMatches returns true if [desired] genesis is the same as [running],
+// taking into account that the network ID may have been set on the running one
+func genesisMatches(running []byte, desired []byte, networkID uint32) (bool, error) {
+	desiredNetworkID, err := utils.NetworkIDFromGenesis(desired)
+	if err != nil {
+		return false, err
+	}
+	if desiredNetworkID != networkID {
+		if desired, err = utils.SetGenesisNetworkID(desired, networkID); err != nil {
+			return false, err
+		}
+	}
+	var runningGenesis, desiredGenesis interface{}
+	if err := json.Unmarshal(running, &runningGenesis); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(desired, &desiredGenesis); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(runningGenesis, desiredGenesis), nil
+}
+
+// planReconcile computes the changes needed for the network to match [desired].
+// Nodes are matched by name, so all node configs in [desired] must be named.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) planReconcile(desired network.Config) (reconcilePlan, error) {
+	plan := reconcilePlan{}
+	networkFlags := ln.flags
+	if desired.Flags != nil {
+		networkFlags = desired.Flags
+	}
+	binaryPath := ln.binaryPath
+	if desired.BinaryPath != "" {
+		binaryPath = desired.BinaryPath
+	}
+	chainConfigFiles := ln.chainConfigFiles
+	if desired.ChainConfigFiles != nil {
+		chainConfigFiles = desired.ChainConfigFiles
+	}
+	upgradeConfigFiles := ln.upgradeConfigFiles
+	if desired.UpgradeConfigFiles != nil {
+		upgradeConfigFiles = desired.UpgradeConfigFiles
+	}
+	subnetConfigFiles := ln.subnetConfigFiles
+	if desired.SubnetConfigFiles != nil {
+		subnetConfigFiles = desired.SubnetConfigFiles
+	}
+	desiredNames := map[string]struct{}{}
+	for _, nodeConfig := range desired.NodeConfigs {
+		if nodeConfig.Name == "" {
+			return reconcilePlan{}, fmt.Errorf("all node configs must be named for reconciliation")
+		}
+		if _, ok := desiredNames[nodeConfig.Name]; ok {
+			return reconcilePlan{}, fmt.Errorf("repeated node name %q", nodeConfig.Name)
+		}
+		desiredNames[nodeConfig.Name] = struct{}{}
+		if err := nodeConfig.Validate(ln.networkID); err != nil {
+			return reconcilePlan{}, fmt.Errorf("node %q config failed validation: %w", nodeConfig.Name, err)
+		}
+		// merge network defaults so the desired config can be compared with the running one
+		nodeConfig.Flags = maps.Clone(nodeConfig.Flags)
+		if nodeConfig.Flags == nil {
+			nodeConfig.Flags = map[string]interface{}{}
+		}
+		addNetworkFlags(networkFlags, nodeConfig.Flags)
+		nodeConfig.ChainConfigFiles = withDefaultConfigFiles(nodeConfig.ChainConfigFiles, chainConfigFiles)
+		nodeConfig.UpgradeConfigFiles = withDefaultConfigFiles(nodeConfig.UpgradeConfigFiles, upgradeConfigFiles)
+		nodeConfig.SubnetConfigFiles = withDefaultConfigFiles(nodeConfig.SubnetConfigFiles, subnetConfigFiles)
+		if nodeConfig.BinaryPath == "" {
+			nodeConfig.BinaryPath = binaryPath
+		}
+		runningNode, ok := ln.nodes[nodeConfig.Name]
+		if !ok {
+			plan.toAdd = append(plan.toAdd, nodeConfig)
+			continue
+		}
+		if !nodeConfigMatches(runningNode.config, nodeConfig) {
+			plan.toRestart = append(plan.toRestart, nodeConfig)
+		}
+	}
+	for nodeName := range ln.nodes {
+		if _, ok := desiredNames[nodeName]; !ok {
+			plan.toRemove = append(plan.toRemove, nodeName)
+		}
+	}
+	return plan, nil
+}
+
+// nodeConfigMatches returns true if a node running with [running] config doesn't need
+// to be restarted to satisfy [desired] config.
+// Flags managed by the runner are only compared if given in [desired].
+func nodeConfigMatches(running node.Config, desired node.Config) bool {
+	if running.BinaryPath != desired.BinaryPath ||
+		running.ConfigFile != desired.ConfigFile ||
+		running.IsBeacon != desired.IsBeacon {
+		return false
+	}
+	if desired.StakingCert != "" && running.StakingCert != desired.StakingCert {
+		return false
+	}
+	if !configFilesMatch(running.ChainConfigFiles, desired.ChainConfigFiles) ||
+		!configFilesMatch(running.UpgradeConfigFiles, desired.UpgradeConfigFiles) ||
+		!configFilesMatch(running.SubnetConfigFiles, desired.SubnetConfigFiles) {
+		return false
+	}
+	runningFlags := map[string]string{}
+	for k, v := range running.Flags {
+		runningFlags[k] = fmt.Sprintf("%v", v)
+	}
+	desiredFlags := map[string]string{}
+	for k, v := range desired.Flags {
+		desiredFlags[k] = fmt.Sprintf("%v", v)
+	}
+	for _, k := range runnerManagedFlags {
+		if _, ok := desiredFlags[k]; !ok {
+			delete(runningFlags, k)
+		}
+	}
+	return reflect.DeepEqual(runningFlags, desiredFlags)
+}
+
+// withDefaultConfigFiles returns a copy of [configFiles] with the entries
+// of [defaults] that are not already defined on it
+func withDefaultConfigFiles(configFiles map[string]string, defaults map[string]string) map[string]string {
+	merged := maps.Clone(defaults)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	for k, v := range configFiles {
+		merged[k] = v
+	}
+	return merged
+}
+
+// configFilesMatch compares two config file maps, considering nil and empty maps as equal
+func configFilesMatch(running map[string]string, desired map[string]string) bool {
+	if len(running) == 0 && len(desired) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(running, desired)
+}
@@ -0,0 +1,48 @@
+package local
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/shirou/gopsutil/mem"
+	"go.uber.org/zap"
+)
+
+// Returns the memory, CPUs and free file descriptors of the host
+func getHostResources() (network.HostResources, error) {
+	memory, err := mem.VirtualMemory()
+	if err != nil {
+		return network.HostResources{}, fmt.Errorf("couldn't get available memory: %w", err)
+	}
+	freeOpenFiles, checkOpenFiles, err := getFreeOpenFiles()
+	if err != nil {
+		return network.HostResources{}, fmt.Errorf("couldn't get free file descriptors: %w", err)
+	}
+	return network.HostResources{
+		AvailableMemory: memory.Available,
+		CPUs:            runtime.NumCPU(),
+		FreeOpenFiles:   freeOpenFiles,
+		CheckOpenFiles:  checkOpenFiles,
+	}, nil
+}
+
+// Returns an error wrapping network.ErrInsufficientResources if the resource
+// guard is set, and the host can't support [numAdded] more running nodes.
+// The error is only logged if the guard is WarnOnly.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) checkResources(numAdded int) error {
+	if ln.resourceGuard == nil || numAdded <= 0 {
+		return nil
+	}
+	host, err := ln.getHostResources()
+	if err != nil {
+		return err
+	}
+	err = ln.resourceGuard.Check(host, len(ln.runningNodes()), numAdded)
+	if err != nil && ln.resourceGuard.WarnOnly {
+		ln.log.Warn("adding nodes beyond the host resources", zap.Error(err))
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package local
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Returns the number of files that can still be opened on the host,
+// from the allocated and max file handles of the kernel
+func getFreeOpenFiles() (uint64, bool, error) {
+	fileNr, err := os.ReadFile("/proc/sys/fs/file-nr")
+	if err != nil {
+		return 0, false, err
+	}
+	fields := strings.Fields(string(fileNr))
+	if len(fields) != 3 {
+		return 0, false, fmt.Errorf("unexpected file-nr %q", fileNr)
+	}
+	allocated, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	maxFiles, err := strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return maxFiles - min(allocated, maxFiles), true, nil
+}
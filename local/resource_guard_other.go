@@ -0,0 +1,8 @@
+//go:build !linux
+
+package local
+
+// The free file descriptors of the host are not checked
+func getFreeOpenFiles() (uint64, bool, error) {
+	return 0, false, nil
+}
@@ -0,0 +1,44 @@
+package local
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddNodeResourceGuard(t *testing.T) {
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	networkConfig := testNetworkConfig(t)
+	addedConfig := networkConfig.NodeConfigs[2]
+	networkConfig.NodeConfigs = networkConfig.NodeConfigs[:2]
+	require.NoError(net.loadConfig(context.Background(), networkConfig))
+
+	// enough memory and file descriptors, but only 1 CPU for 3 nodes
+	net.getHostResources = func() (network.HostResources, error) {
+		return network.HostResources{
+			AvailableMemory: 8 * network.DefaultNodeMemory,
+			CPUs:            1,
+			FreeOpenFiles:   8 * network.DefaultNodeOpenFiles,
+			CheckOpenFiles:  true,
+		}, nil
+	}
+	net.resourceGuard = &network.ResourceGuard{}
+	_, err = net.AddNode(addedConfig)
+	require.ErrorIs(err, network.ErrInsufficientResources)
+	var nodeErr *network.NodeError
+	require.ErrorAs(err, &nodeErr)
+	require.Equal(addedConfig.Name, nodeErr.NodeName)
+	require.NotContains(net.nodes, addedConfig.Name)
+
+	net.resourceGuard.WarnOnly = true
+	_, err = net.AddNode(addedConfig)
+	require.NoError(err)
+	require.Contains(net.nodes, addedConfig.Name)
+	require.NoError(net.Stop(context.Background()))
+}
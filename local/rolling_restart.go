@@ -0,0 +1,100 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"go.uber.org/zap"
+)
+
+// See network.Network
+// The network lock is only held while restarting each batch, so that the
+// network can be used while waiting for it to be healthy.
+func (ln *localNetwork) RollingRestart(ctx context.Context, opts network.RollingRestartOptions) (err error) {
+	record := ln.startOperation("RollingRestart", "", nonEmptyParams(map[string]string{
+		"batchSize":  strconv.Itoa(max(opts.BatchSize, 1)),
+		"nodeNames":  strings.Join(opts.NodeNames, ","),
+		"binaryPath": opts.BinaryPath,
+		"pluginDir":  opts.PluginDir,
+	}))
+	defer ln.finishOperation(record, &err)
+
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	ln.lock.RLock()
+	if ln.stopCalled() {
+		ln.lock.RUnlock()
+		return network.ErrStopped
+	}
+	nodeConfigs := []node.Config{}
+	for _, node := range ln.runningNodes() {
+		nodeConfigs = append(nodeConfigs, node.config)
+	}
+	ln.lock.RUnlock()
+
+	for _, batch := range opts.Batches(nodeConfigs) {
+		if err := ln.restartBatch(ctx, batch, opts); err != nil {
+			return err
+		}
+		if err := ln.healthyWithin(ctx, opts.HealthTimeout); err != nil {
+			return fmt.Errorf("network not healthy after restarting %v: %w", batch, err)
+		}
+	}
+	return nil
+}
+
+// Restarts the nodes of [batch] with the binary and plugin dir of [opts],
+// if the quorum guard allows stopping them all.
+// Assumes [ln.lock] is not held.
+func (ln *localNetwork) restartBatch(ctx context.Context, batch []string, opts network.RollingRestartOptions) error {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	nodes := make([]*localNode, 0, len(batch))
+	for _, nodeName := range batch {
+		node, ok := ln.nodes[nodeName]
+		if !ok {
+			return &network.NodeError{NodeName: nodeName, Op: "restart", Err: network.ErrNodeNotFound}
+		}
+		if node.paused {
+			return &network.NodeError{NodeName: nodeName, Op: "restart", Err: errNodePaused}
+		}
+		nodes = append(nodes, node)
+	}
+	var err error
+	if opts.MaxStoppedStake > 0 {
+		err = ln.checkStoppedStake(ctx, opts.MaxStoppedStake, nodes...)
+	} else {
+		err = ln.checkQuorumGuard(ctx, nodes...)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't restart %v: %w", batch, err)
+	}
+	ln.log.Info("restarting nodes", zap.Strings("nodes", batch))
+	for _, nodeName := range batch {
+		if err := ln.restartNode(ctx, nodeName, opts.BinaryPath, opts.PluginDir, "", nil, nil, nil); err != nil {
+			return &network.NodeError{NodeName: nodeName, Op: "restart", Err: err}
+		}
+	}
+	return ln.persistNetwork()
+}
+
+// Waits for the network to be healthy, for at most [timeout] if positive.
+// Assumes [ln.lock] is not held.
+func (ln *localNetwork) healthyWithin(ctx context.Context, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return ln.Healthy(ctx)
+}
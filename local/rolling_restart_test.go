@@ -0,0 +1,64 @@
+package local
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/api"
+	apimocks "github.com/ava-labs/avalanche-network-runner/api/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollingRestart(t *testing.T) {
+	require := require.New(t)
+	var net *localNetwork
+	newAPIClientF := func(ip string, port uint16) api.Client {
+		client := newMockAPISuccessful(ip, port).(*apimocks.Client)
+		client.On("PChainAPI").Return(&fakePChainClient{net: &net})
+		return client
+	}
+	net, err := newNetwork(logging.NoLog{}, newAPIClientF, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	processes := map[string]NodeProcess{}
+	for nodeName, node := range net.nodes {
+		processes[nodeName] = node.process
+	}
+
+	require.Error(net.RollingRestart(context.Background(), network.RollingRestartOptions{BatchSize: -1}))
+	// restarting 2 of 3 validators at once is not quorum safe
+	err = net.RollingRestart(context.Background(), network.RollingRestartOptions{BatchSize: 2, MaxStoppedStake: 0.5})
+	require.ErrorIs(err, network.ErrQuorumLoss)
+	for nodeName, node := range net.nodes {
+		require.Same(processes[nodeName], node.process)
+	}
+
+	require.NoError(net.RollingRestart(context.Background(), network.RollingRestartOptions{
+		BinaryPath:      "/bin/avalanchego-new",
+		PluginDir:       "/plugins",
+		MaxStoppedStake: 0.5,
+	}))
+	require.Len(net.nodes, 3)
+	for nodeName, node := range net.nodes {
+		require.NotSame(processes[nodeName], node.process)
+		require.Equal("/bin/avalanchego-new", node.config.BinaryPath)
+		require.Equal("/plugins", node.config.Flags[config.PluginDirKey])
+	}
+	history := net.History()
+	require.Equal("RollingRestart", history[len(history)-1].Op)
+	require.Empty(history[len(history)-1].Err)
+
+	require.NoError(net.PauseNode(context.Background(), "node2"))
+	err = net.RollingRestart(context.Background(), network.RollingRestartOptions{NodeNames: []string{"node2"}})
+	require.ErrorIs(err, errNodePaused)
+	// paused nodes are not restarted by default
+	require.NoError(net.RollingRestart(context.Background(), network.RollingRestartOptions{}))
+	require.True(net.nodes["node2"].paused)
+
+	require.NoError(net.Stop(context.Background()))
+	require.ErrorIs(net.RollingRestart(context.Background(), network.RollingRestartOptions{}), network.ErrStopped)
+}
@@ -0,0 +1,114 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var errRunAsUserNotSupported = errors.New("running nodes as another user is not supported on this platform")
+
+// runAsUser is the OS user and group a node process runs as,
+// given by node.Config.RunAsUser
+type runAsUser struct {
+	spec string
+	uid  uint32
+	gid  uint32
+}
+
+// Resolves [spec], a user name or uid, optionally followed by ":" and a
+// group name or gid. The group defaults to the user's primary group.
+func lookupRunAsUser(spec string) (runAsUser, error) {
+	userSpec, groupSpec, hasGroup := strings.Cut(spec, ":")
+	if userSpec == "" || (hasGroup && groupSpec == "") {
+		return runAsUser{}, fmt.Errorf("invalid user %q, expected user[:group]", spec)
+	}
+	u, err := lookupUser(userSpec)
+	if err != nil {
+		return runAsUser{}, err
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return runAsUser{}, fmt.Errorf("user %q has non numeric uid %q", userSpec, u.Uid)
+	}
+	gidStr := u.Gid
+	if hasGroup {
+		g, err := lookupGroup(groupSpec)
+		if err != nil {
+			return runAsUser{}, err
+		}
+		gidStr = g.Gid
+	}
+	gid, err := strconv.ParseUint(gidStr, 10, 32)
+	if err != nil {
+		return runAsUser{}, fmt.Errorf("group of user %q has non numeric gid %q", spec, gidStr)
+	}
+	return runAsUser{spec: spec, uid: uint32(uid), gid: uint32(gid)}, nil
+}
+
+// Looks [userSpec] up by name, or by uid if numeric
+func lookupUser(userSpec string) (*user.User, error) {
+	if _, err := strconv.ParseUint(userSpec, 10, 32); err == nil {
+		u, err := user.LookupId(userSpec)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't find user with uid %s: %w", userSpec, err)
+		}
+		return u, nil
+	}
+	u, err := user.Lookup(userSpec)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find user %q: %w", userSpec, err)
+	}
+	return u, nil
+}
+
+// Looks [groupSpec] up by name, or by gid if numeric
+func lookupGroup(groupSpec string) (*user.Group, error) {
+	if _, err := strconv.ParseUint(groupSpec, 10, 32); err == nil {
+		g, err := user.LookupGroupId(groupSpec)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't find group with gid %s: %w", groupSpec, err)
+		}
+		return g, nil
+	}
+	g, err := user.LookupGroup(groupSpec)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find group %q: %w", groupSpec, err)
+	}
+	return g, nil
+}
+
+// Creates [dirs] if needed, and gives them and their contents to [u],
+// so that a node process running as [u] can write them
+func (u runAsUser) chownDirs(dirs ...string) error {
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+		err := filepath.WalkDir(dir, func(path string, _ fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			return os.Lchown(path, int(u.uid), int(u.gid))
+		})
+		if err != nil {
+			return u.permissionError(fmt.Errorf("couldn't give node dir %s to user %q: %w", dir, u.spec, err))
+		}
+	}
+	return nil
+}
+
+// Adds a hint to [err] if it is due to the runner lacking the privileges
+// to act on behalf of [u]
+func (u runAsUser) permissionError(err error) error {
+	if errors.Is(err, syscall.EPERM) || errors.Is(err, fs.ErrPermission) {
+		return fmt.Errorf("%w (running nodes as user %q requires the runner to be root, or to have the CAP_SETUID, CAP_SETGID and CAP_CHOWN capabilities)", err, u.spec)
+	}
+	return err
+}
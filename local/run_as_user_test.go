@@ -0,0 +1,67 @@
+//go:build !windows
+
+package local
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupRunAsUser(t *testing.T) {
+	require := require.New(t)
+	current, err := user.Current()
+	require.NoError(err)
+	uid, err := strconv.ParseUint(current.Uid, 10, 32)
+	require.NoError(err)
+	gid, err := strconv.ParseUint(current.Gid, 10, 32)
+	require.NoError(err)
+	group, err := user.LookupGroupId(current.Gid)
+	require.NoError(err)
+
+	for _, spec := range []string{
+		current.Username,
+		current.Uid,
+		current.Username + ":" + current.Gid,
+		current.Uid + ":" + group.Name,
+	} {
+		u, err := lookupRunAsUser(spec)
+		require.NoError(err, spec)
+		require.Equal(runAsUser{spec: spec, uid: uint32(uid), gid: uint32(gid)}, u)
+	}
+
+	for _, spec := range []string{":", ":0", "0:", "no-such-user-anr", "0:no-such-group-anr"} {
+		_, err := lookupRunAsUser(spec)
+		require.Error(err, spec)
+	}
+}
+
+func TestRunAsUser(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("running as another user requires root")
+	}
+	require := require.New(t)
+	u, err := lookupRunAsUser("nobody")
+	require.NoError(err)
+
+	dir := filepath.Join(t.TempDir(), "node")
+	require.NoError(u.chownDirs(dir))
+	info, err := os.Stat(dir)
+	require.NoError(err)
+	stat := info.Sys().(*syscall.Stat_t)
+	require.Equal(u.uid, stat.Uid)
+	require.Equal(u.gid, stat.Gid)
+
+	cmd := exec.Command("id", "-u")
+	require.NoError(u.setCredential(cmd))
+	out, err := cmd.Output()
+	require.NoError(err)
+	require.Equal(strconv.FormatUint(uint64(u.uid), 10), strings.TrimSpace(string(out)))
+}
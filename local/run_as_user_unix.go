@@ -0,0 +1,23 @@
+//go:build !windows
+
+package local
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Makes [cmd] run as [u]. Supplementary groups are cleared when running as
+// root, and kept otherwise, as setting them requires privileges.
+func (u runAsUser) setCredential(cmd *exec.Cmd) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:         u.uid,
+		Gid:         u.gid,
+		NoSetGroups: os.Geteuid() != 0,
+	}
+	return nil
+}
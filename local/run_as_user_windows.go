@@ -0,0 +1,9 @@
+//go:build windows
+
+package local
+
+import "os/exec"
+
+func (runAsUser) setCredential(*exec.Cmd) error {
+	return errRunAsUserNotSupported
+}
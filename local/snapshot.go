@@ -8,9 +8,10 @@ import (
 	"net/netip"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
-	"github.com/ava-labs/avalanche-network-runner/api"
 	"github.com/ava-labs/avalanche-network-runner/network"
 	"github.com/ava-labs/avalanche-network-runner/network/node"
 	"github.com/ava-labs/avalanche-network-runner/utils"
@@ -32,6 +33,8 @@ type NetworkState struct {
 	SubnetID2ElasticSubnetID map[string]string `json:"subnetID2ElasticSubnetID"`
 	// Map from blockchain id to blockchain aliases
 	BlockchainAliases map[string][]string `json:"blockchainAliases"`
+	// Map from vm id to vm aliases
+	VMAliases map[string][]string `json:"vmAliases,omitempty"`
 }
 
 // snapshots generated using older ANR versions may contain deprecated avago flags
@@ -60,6 +63,8 @@ func fixDeprecatedAvagoFlags(flags map[string]interface{}) error {
 }
 
 // NewNetwork returns a new network from the given snapshot
+// If [reassignPortsIfUsed] is true, nodes whose snapshot ports are already taken are
+// started with their ports shifted by 1000, or with random ports if those are taken too.
 func NewNetworkFromSnapshot(
 	log logging.Logger,
 	snapshotsDir string,
@@ -93,7 +98,7 @@ func NewNetworkFromSnapshot(
 	}
 	net, err := newNetwork(
 		log,
-		api.NewAPIClient,
+		newAPIClientF(nil, 0),
 		&nodeProcessCreator{
 			colorPicker: utils.NewColorPicker(),
 			log:         log,
@@ -128,8 +133,14 @@ func NewNetworkFromSnapshot(
 	return net, err
 }
 
-// Save network conf + state into json at root dir
-func (ln *localNetwork) persistNetwork() error {
+// Returns the config of the network as it is now, with the nodes sorted by
+// name, and without data dir and log dir references. Dependencies on nodes
+// that are no longer in the network are dropped. If [forSnapshot], the
+// current node ports and all the beacons are kept, so that the network can
+// be loaded as it was. Otherwise, the ports are the ones given on the node
+// configs, and the beacons are only the ones of other networks.
+// Assumes [ln.lock] is held.
+func (ln *localNetwork) getNetworkConfig(forSnapshot bool) (network.Config, error) {
 	// clone network flags
 	networkConfigFlags := maps.Clone(ln.flags)
 	// remove data dir, log dir references
@@ -142,41 +153,82 @@ func (ln *localNetwork) persistNetwork() error {
 		// depending on how the user generated the config, different nodes config flags
 		// may point to the same map, so we made a copy to avoid always modifying the same value
 		nodeConfig.Flags = maps.Clone(nodeConfig.Flags)
-		// preserve the current node ports
-		nodeConfig.Flags[config.HTTPPortKey] = ln.nodes[nodeName].GetAPIPort()
-		nodeConfig.Flags[config.StakingPortKey] = ln.nodes[nodeName].GetP2PPort()
+		if forSnapshot {
+			// preserve the current node ports
+			nodeConfig.Flags[config.HTTPPortKey] = ln.nodes[nodeName].GetAPIPort()
+			nodeConfig.Flags[config.StakingPortKey] = ln.nodes[nodeName].GetP2PPort()
+		}
 		// remove data dir, log dir references
 		if nodeConfig.ConfigFile != "" {
 			var err error
 			nodeConfig.ConfigFile, err = utils.SetJSONKey(nodeConfig.ConfigFile, config.LogsDirKey, "")
 			if err != nil {
-				return err
+				return network.Config{}, err
 			}
 			nodeConfig.ConfigFile, err = utils.SetJSONKey(nodeConfig.ConfigFile, config.DataDirKey, "")
 			if err != nil {
-				return err
+				return network.Config{}, err
 			}
 		}
 		delete(nodeConfig.Flags, config.LogsDirKey)
 		delete(nodeConfig.Flags, config.DataDirKey)
 		nodeConfigs = append(nodeConfigs, nodeConfig)
 	}
-	// save network conf
+	// nodes not started yet keep their remaining start delay
+	for _, delayedNode := range ln.delayedNodes {
+		nodeConfig := delayedNode.config
+		nodeConfig.StartDelay = max(delayedNode.startTime.Sub(ln.clock.Now()), 0)
+		nodeConfigs = append(nodeConfigs, nodeConfig)
+	}
+	sort.Slice(nodeConfigs, func(i, j int) bool {
+		return nodeConfigs[i].Name < nodeConfigs[j].Name
+	})
+	for i := range nodeConfigs {
+		dependsOn := []string{}
+		for _, dependency := range nodeConfigs[i].DependsOn {
+			if _, ok := ln.nodes[dependency]; ok {
+				dependsOn = append(dependsOn, dependency)
+			}
+		}
+		if len(dependsOn) != len(nodeConfigs[i].DependsOn) {
+			nodeConfigs[i].DependsOn = dependsOn
+		}
+	}
 	beaconConf, err := utils.BeaconMapFromSet(ln.bootstraps)
 	if err != nil {
-		return err
+		return network.Config{}, err
 	}
-	networkConfig := network.Config{
-		NetworkID:          ln.networkID,
-		Genesis:            string(ln.genesisData),
-		Upgrade:            string(ln.upgradeData),
-		Flags:              networkConfigFlags,
-		NodeConfigs:        nodeConfigs,
-		BinaryPath:         ln.binaryPath,
-		ChainConfigFiles:   ln.chainConfigFiles,
-		UpgradeConfigFiles: ln.upgradeConfigFiles,
-		SubnetConfigFiles:  ln.subnetConfigFiles,
-		BeaconConfig:       beaconConf,
+	if !forSnapshot {
+		for _, node := range ln.nodes {
+			delete(beaconConf, node.nodeID)
+		}
+	}
+	return network.Config{
+		NetworkID:            ln.networkID,
+		Genesis:              string(ln.genesisData),
+		Upgrade:              string(ln.upgradeData),
+		Flags:                networkConfigFlags,
+		NodeConfigs:          nodeConfigs,
+		BinaryPath:           ln.binaryPath,
+		ChainConfigFiles:     ln.chainConfigFiles,
+		UpgradeConfigFiles:   ln.upgradeConfigFiles,
+		SubnetConfigFiles:    ln.subnetConfigFiles,
+		BeaconConfig:         beaconConf,
+		StaticValidators:     ln.staticValidators,
+		ResourceGuard:        ln.resourceGuard,
+		Labels:               maps.Clone(ln.labels),
+		PortRaceRetries:      ln.portRaceRetries,
+		HealthRequestTimeout: ln.healthRequestTimeout,
+		LoopbackIPs:          ln.loopbackIPs,
+		LogTimestamps:        ln.logStamper != nil,
+	}, nil
+}
+
+// Save network conf + state into json at root dir
+func (ln *localNetwork) persistNetwork() error {
+	networkConfig, err := ln.getNetworkConfig(true)
+	if err != nil {
+		return err
 	}
 	networkConfigJSON, err := json.MarshalIndent(networkConfig, "", "    ")
 	if err != nil {
@@ -193,12 +245,50 @@ func (ln *localNetwork) persistNetwork() error {
 	networkState := NetworkState{
 		SubnetID2ElasticSubnetID: subnetID2ElasticSubnetID,
 		BlockchainAliases:        ln.blockchainAliases,
+		VMAliases:                ln.vmAliases,
 	}
 	networkStateJSON, err := json.MarshalIndent(networkState, "", "    ")
 	if err != nil {
 		return err
 	}
-	return createFileAndWrite(filepath.Join(ln.rootDir, "state.json"), networkStateJSON)
+	if err := createFileAndWrite(filepath.Join(ln.rootDir, "state.json"), networkStateJSON); err != nil {
+		return err
+	}
+	if ln.controlFilePath != "" {
+		return ln.writeControlFile(networkConfig)
+	}
+	return nil
+}
+
+// Loads the network state saved by persistNetwork on [dir]
+func (ln *localNetwork) loadNetworkState(dir string) error {
+	networkStateJSON, err := os.ReadFile(filepath.Join(dir, "state.json"))
+	if err != nil {
+		return err
+	}
+	networkState := NetworkState{}
+	if err := json.Unmarshal(networkStateJSON, &networkState); err != nil {
+		return fmt.Errorf("failure unmarshaling network state: %w", err)
+	}
+	ln.subnetID2ElasticSubnetID = map[ids.ID]ids.ID{}
+	for subnetIDStr, elasticSubnetIDStr := range networkState.SubnetID2ElasticSubnetID {
+		subnetID, err := ids.FromString(subnetIDStr)
+		if err != nil {
+			return err
+		}
+		elasticSubnetID, err := ids.FromString(elasticSubnetIDStr)
+		if err != nil {
+			return err
+		}
+		ln.subnetID2ElasticSubnetID[subnetID] = elasticSubnetID
+	}
+	for k, v := range networkState.BlockchainAliases {
+		ln.blockchainAliases[k] = v
+	}
+	for k, v := range networkState.VMAliases {
+		ln.vmAliases[k] = v
+	}
+	return nil
 }
 
 // Save network snapshot
@@ -208,9 +298,15 @@ func (ln *localNetwork) SaveSnapshot(
 	snapshotName string,
 	snapshotPath string,
 	force bool,
-) (string, error) {
+) (_ string, err error) {
 	ln.lock.Lock()
 	defer ln.lock.Unlock()
+	record := ln.startOperation("SaveSnapshot", "", nonEmptyParams(map[string]string{
+		"name":  snapshotName,
+		"path":  snapshotPath,
+		"force": strconv.FormatBool(force),
+	}))
+	defer ln.finishOperation(record, &err)
 
 	if ln.stopCalled() {
 		return "", network.ErrStopped
@@ -370,32 +466,11 @@ func (ln *localNetwork) loadSnapshot(
 		}
 	}
 	// load network state not available at blockchain db
-	networkStateJSON, err := os.ReadFile(filepath.Join(snapshotDir, "state.json"))
-	if err != nil {
+	if err := ln.loadNetworkState(snapshotDir); err != nil {
 		if !errors.Is(err, os.ErrNotExist) {
-			return fmt.Errorf("failure reading network state file from snapshot: %w", err)
+			return fmt.Errorf("failure loading network state from snapshot: %w", err)
 		}
 		ln.log.Warn("network state file not found on snapshot")
-	} else {
-		networkState := NetworkState{}
-		if err := json.Unmarshal(networkStateJSON, &networkState); err != nil {
-			return fmt.Errorf("failure unmarshaling network state from snapshot: %w", err)
-		}
-		ln.subnetID2ElasticSubnetID = map[ids.ID]ids.ID{}
-		for subnetIDStr, elasticSubnetIDStr := range networkState.SubnetID2ElasticSubnetID {
-			subnetID, err := ids.FromString(subnetIDStr)
-			if err != nil {
-				return err
-			}
-			elasticSubnetID, err := ids.FromString(elasticSubnetIDStr)
-			if err != nil {
-				return err
-			}
-			ln.subnetID2ElasticSubnetID[subnetID] = elasticSubnetID
-		}
-		for k, v := range networkState.BlockchainAliases {
-			ln.blockchainAliases[k] = v
-		}
 	}
 	if err := ln.loadConfig(ctx, networkConfig); err != nil {
 		return err
@@ -403,14 +478,7 @@ func (ln *localNetwork) loadSnapshot(
 	if err := ln.healthy(ctx); err != nil {
 		return err
 	}
-	// add aliases included in the snapshot state
-	for blockchainID, blockchainAliases := range ln.blockchainAliases {
-		for _, blockchainAlias := range blockchainAliases {
-			if err := ln.setBlockchainAlias(ctx, blockchainID, blockchainAlias); err != nil {
-				return err
-			}
-		}
-	}
+	// aliases included in the snapshot state were registered by the nodes on becoming healthy
 	// add aliases for blockchain names
 	if !utils.IsPublicNetwork(ln.networkID) {
 		node := ln.getNode()
@@ -0,0 +1,95 @@
+package local
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"golang.org/x/exp/maps"
+)
+
+// startupTracker records the durations of the startup phases of a node process
+type startupTracker struct {
+	clock  clock
+	lock   sync.Mutex
+	report network.NodeStartupReport
+	// end of the last completed phase
+	lastPhaseEnd time.Time
+}
+
+func newStartupTracker(clock clock) *startupTracker {
+	now := clock.Now()
+	return &startupTracker{
+		clock: clock,
+		report: network.NodeStartupReport{
+			StartTime: now,
+			Phases:    map[network.StartupPhase]time.Duration{},
+		},
+		lastPhaseEnd: now,
+	}
+}
+
+// Completes [phase] now, unless it already was
+func (t *startupTracker) endPhase(phase network.StartupPhase) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.report.Phases[phase]; ok {
+		return
+	}
+	now := t.clock.Now()
+	t.report.Phases[phase] = now.Sub(t.lastPhaseEnd)
+	t.lastPhaseEnd = now
+}
+
+// Returns a copy of the report
+func (t *startupTracker) get() network.NodeStartupReport {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	report := t.report
+	report.Phases = maps.Clone(t.report.Phases)
+	return report
+}
+
+// networkStartup records how long the network took to be healthy
+type networkStartup struct {
+	lock      sync.Mutex
+	startTime time.Time
+	// set once the network is first seen healthy
+	healthy     bool
+	healthyTime time.Duration
+}
+
+// Records the network as healthy now, unless it already was
+func (ln *localNetwork) markHealthy() {
+	ln.startup.lock.Lock()
+	defer ln.startup.lock.Unlock()
+
+	if ln.startup.healthy {
+		return
+	}
+	ln.startup.healthy = true
+	ln.startup.healthyTime = ln.clock.Now().Sub(ln.startup.startTime)
+}
+
+// See network.Network
+func (ln *localNetwork) StartupReport() (network.StartupReport, error) {
+	ln.lock.RLock()
+	defer ln.lock.RUnlock()
+
+	if ln.stopCalled() {
+		return network.StartupReport{}, network.ErrStopped
+	}
+	ln.startup.lock.Lock()
+	report := network.StartupReport{
+		StartTime: ln.startup.startTime,
+		Healthy:   ln.startup.healthyTime,
+		Nodes:     make(map[string]network.NodeStartupReport, len(ln.nodes)),
+	}
+	ln.startup.lock.Unlock()
+	for name, node := range ln.nodes {
+		report.Nodes[name] = node.startup.get()
+	}
+	return report, nil
+}
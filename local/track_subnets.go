@@ -0,0 +1,59 @@
+package local
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// Makes the node of [handle] track [subnetID] if [track] is true, or stop tracking
+// it otherwise, restarting the node if it is running and the subnets it
+// tracks change. See node.Node.TrackSubnet.
+// Assumes [ln.lock] is not held.
+func (ln *localNetwork) setSubnetTracked(ctx context.Context, handle *localNode, subnetID ids.ID, track bool) (err error) {
+	nodeName := handle.name
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+	op := "UntrackSubnet"
+	if track {
+		op = "TrackSubnet"
+	}
+	record := ln.startOperation(op, nodeName, map[string]string{"subnetID": subnetID.String()})
+	defer ln.finishOperation(record, &err)
+
+	if ln.stopCalled() {
+		return network.ErrStopped
+	}
+	if err := ln.checkNodeHandle(nodeName, handle); err != nil {
+		return &network.NodeError{NodeName: nodeName, Op: "track subnet", Err: err}
+	}
+	localNode := ln.nodes[nodeName]
+	tracked, err := node.TrackedSubnets(localNode.config.Flags)
+	if err != nil {
+		return &network.NodeError{NodeName: nodeName, Op: "track subnet", Err: err}
+	}
+	i, isTracked := slices.BinarySearch(tracked, subnetID.String())
+	switch {
+	case track && !isTracked:
+		tracked = slices.Insert(tracked, i, subnetID.String())
+	case !track && isTracked:
+		tracked = slices.Delete(tracked, i, i+1)
+	default:
+		return nil
+	}
+	if !localNode.paused {
+		if err := ln.checkQuorumGuard(ctx, localNode); err != nil {
+			return &network.NodeError{NodeName: nodeName, Op: "restart", Err: err}
+		}
+	}
+	node.SetTrackedSubnets(localNode.config.Flags, tracked)
+	if !localNode.paused {
+		if err := ln.restartNode(ctx, nodeName, "", "", "", nil, nil, nil); err != nil {
+			return &network.NodeError{NodeName: nodeName, Op: "restart", Err: err}
+		}
+	}
+	return ln.persistNetwork()
+}
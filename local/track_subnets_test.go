@@ -0,0 +1,58 @@
+package local
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/beacon"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackSubnet(t *testing.T) {
+	require := require.New(t)
+	net, err := newNetwork(logging.NoLog{}, newMockAPISuccessful, &localTestSuccessfulNodeProcessCreator{}, t.TempDir(), "", "", false, false, false, "", beacon.NewSet(), false)
+	require.NoError(err)
+	require.NoError(net.loadConfig(context.Background(), testNetworkConfig(t)))
+	subnetID1, subnetID2 := ids.GenerateTestID(), ids.GenerateTestID()
+	// the deprecated flag name is replaced
+	net.nodes["node0"].config.Flags["whitelisted-subnets"] = subnetID1.String()
+
+	node0, err := net.GetNode("node0")
+	require.NoError(err)
+	process := net.nodes["node0"].process
+	require.NoError(node0.TrackSubnet(context.Background(), subnetID2))
+	require.NotSame(process, net.nodes["node0"].process)
+	flags := net.nodes["node0"].config.Flags
+	require.NotContains(flags, "whitelisted-subnets")
+	tracked := []string{subnetID1.String(), subnetID2.String()}
+	if tracked[0] > tracked[1] {
+		tracked[0], tracked[1] = tracked[1], tracked[0]
+	}
+	require.Equal(tracked[0]+","+tracked[1], flags[config.TrackSubnetsKey])
+
+	// no restart if the subnet is already tracked
+	process = net.nodes["node0"].process
+	node0, err = net.GetNode("node0")
+	require.NoError(err)
+	require.NoError(node0.TrackSubnet(context.Background(), subnetID2))
+	require.Same(process, net.nodes["node0"].process)
+
+	require.NoError(node0.UntrackSubnet(context.Background(), subnetID1))
+	require.Equal(subnetID2.String(), net.nodes["node0"].config.Flags[config.TrackSubnetsKey])
+	history := net.History()
+	require.Equal("UntrackSubnet", history[len(history)-1].Op)
+
+	// paused nodes are not restarted
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	node1 := net.nodes["node1"]
+	require.NoError(node1.TrackSubnet(context.Background(), subnetID1))
+	require.True(net.nodes["node1"].paused)
+	require.Equal(subnetID1.String(), net.nodes["node1"].config.Flags[config.TrackSubnetsKey])
+
+	require.NoError(net.Stop(context.Background()))
+	require.ErrorIs(node1.TrackSubnet(context.Background(), subnetID2), network.ErrStopped)
+}
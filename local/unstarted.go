@@ -0,0 +1,137 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"golang.org/x/exp/maps"
+)
+
+var errNetworkStarted = errors.New("network was already started or discarded")
+
+// UnstartedNetwork is a network whose config was validated and loaded, and
+// whose dirs were created, but whose nodes weren't started yet.
+// See NewUnstartedNetwork.
+type UnstartedNetwork struct {
+	ln *localNetwork
+	// in start order, with their names set
+	nodeConfigs []node.Config
+	// true once Start or Discard were called
+	done bool
+}
+
+// RootDir returns the dir where the node dirs are created, named after
+// the nodes, unless their data dir is given by their flags
+func (un *UnstartedNetwork) RootDir() string {
+	return un.ln.rootDir
+}
+
+// LogRootDir returns the dir where the node log dirs are created
+func (un *UnstartedNetwork) LogRootDir() string {
+	return un.ln.logRootDir
+}
+
+// NodeConfigs returns copies of the configs the nodes will be started with,
+// in start order, with the network defaults, e.g. binary path, flags and
+// config files, applied. Unnamed nodes are given their default names.
+func (un *UnstartedNetwork) NodeConfigs() []node.Config {
+	nodeConfigs := make([]node.Config, len(un.nodeConfigs))
+	for i, nodeConfig := range un.nodeConfigs {
+		nodeConfig = cloneNodeConfig(nodeConfig)
+		un.ln.applyNodeDefaults(&nodeConfig)
+		nodeConfigs[i] = nodeConfig
+	}
+	return nodeConfigs
+}
+
+// SetNodeConfig replaces the config of the node named [nodeConfig.Name].
+// Its position in the start order is kept, so its beacon status, start
+// delay and dependencies can't be changed.
+func (un *UnstartedNetwork) SetNodeConfig(nodeConfig node.Config) error {
+	if un.done {
+		return errNetworkStarted
+	}
+	for i := range un.nodeConfigs {
+		if un.nodeConfigs[i].Name == nodeConfig.Name {
+			if err := nodeConfig.Validate(un.ln.networkID); err != nil {
+				return &network.NodeError{NodeName: nodeConfig.Name, Op: "set config of", Err: err}
+			}
+			current := un.nodeConfigs[i]
+			if nodeConfig.IsBeacon != current.IsBeacon ||
+				nodeConfig.StartDelay != current.StartDelay ||
+				!slices.Equal(nodeConfig.DependsOn, current.DependsOn) {
+				return &network.NodeError{
+					NodeName: nodeConfig.Name,
+					Op:       "set config of",
+					Err:      errors.New("can't change the beacon status, start delay or dependencies of a node"),
+				}
+			}
+			un.nodeConfigs[i] = cloneNodeConfig(nodeConfig)
+			return nil
+		}
+	}
+	return &network.NodeError{NodeName: nodeConfig.Name, Op: "set config of", Err: network.ErrNodeNotFound}
+}
+
+// Start starts the nodes, and returns the running network. If a node fails
+// to start, the nodes already started are stopped.
+// Can only be called once.
+func (un *UnstartedNetwork) Start(ctx context.Context) (network.Network, error) {
+	if un.done {
+		return nil, errNetworkStarted
+	}
+	un.done = true
+	if err := un.ln.startNodes(ctx, un.nodeConfigs); err != nil {
+		un.ln.releaseRootDir()
+		return nil, err
+	}
+	return un.ln, nil
+}
+
+// Discard releases the network without starting it, removing its root
+// dir if ephemeral. Start can't be called afterwards.
+func (un *UnstartedNetwork) Discard() {
+	if un.done {
+		return
+	}
+	un.done = true
+	un.ln.releaseRootDir()
+}
+
+// nameNodeConfigs gives the default names to the unnamed nodes of
+// [nodeConfigs], skipping the names of the other nodes
+func nameNodeConfigs(nodeConfigs []node.Config) []node.Config {
+	names := map[string]struct{}{}
+	for _, nodeConfig := range nodeConfigs {
+		names[nodeConfig.Name] = struct{}{}
+	}
+	suffix := 1
+	for i := range nodeConfigs {
+		if nodeConfigs[i].Name != "" {
+			continue
+		}
+		for {
+			name := fmt.Sprintf("%s%d", defaultNodeNamePrefix, suffix)
+			suffix++
+			if _, ok := names[name]; !ok {
+				nodeConfigs[i].Name = name
+				names[name] = struct{}{}
+				break
+			}
+		}
+	}
+	return nodeConfigs
+}
+
+func cloneNodeConfig(nodeConfig node.Config) node.Config {
+	nodeConfig.Flags = maps.Clone(nodeConfig.Flags)
+	nodeConfig.ChainConfigFiles = maps.Clone(nodeConfig.ChainConfigFiles)
+	nodeConfig.UpgradeConfigFiles = maps.Clone(nodeConfig.UpgradeConfigFiles)
+	nodeConfig.SubnetConfigFiles = maps.Clone(nodeConfig.SubnetConfigFiles)
+	nodeConfig.Labels = maps.Clone(nodeConfig.Labels)
+	return nodeConfig
+}
@@ -0,0 +1,69 @@
+package local
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnstartedNetwork(t *testing.T) {
+	require := require.New(t)
+	npc := &localTestPortRaceProcessCreator{}
+	networkConfig := testNetworkConfig(t)
+	networkConfig.Flags = map[string]interface{}{"log-level": "debug"}
+	networkConfig.NodeConfigs[1].Name = ""
+	un, err := newUnstartedNetwork(logging.NoLog{}, networkConfig, npc, t.TempDir(), "", t.TempDir(), false, false, false, "", false)
+	require.NoError(err)
+	require.Empty(npc.apiPorts)
+
+	nodeConfigs := un.NodeConfigs()
+	require.Len(nodeConfigs, 3)
+	names := []string{}
+	for _, nodeConfig := range nodeConfigs {
+		names = append(names, nodeConfig.Name)
+		require.Equal("debug", nodeConfig.Flags["log-level"])
+		require.Equal("pepito", nodeConfig.BinaryPath)
+	}
+	require.ElementsMatch([]string{"node0", "node1", "node2"}, names)
+
+	nodeConfig := nodeConfigs[0]
+	nodeConfig.Flags["log-level"] = "trace"
+	require.NoError(un.SetNodeConfig(nodeConfig))
+	nodeConfig.IsBeacon = !nodeConfig.IsBeacon
+	require.Error(un.SetNodeConfig(nodeConfig))
+	nodeConfig.Name = "node3"
+	require.ErrorIs(un.SetNodeConfig(nodeConfig), network.ErrNodeNotFound)
+
+	net, err := un.Start(context.Background())
+	require.NoError(err)
+	require.Len(npc.apiPorts, 3)
+	node, err := net.GetNode(nodeConfigs[0].Name)
+	require.NoError(err)
+	require.Equal("trace", node.GetConfig().Flags["log-level"])
+	_, err = un.Start(context.Background())
+	require.ErrorIs(err, errNetworkStarted)
+	require.ErrorIs(un.SetNodeConfig(nodeConfigs[1]), errNetworkStarted)
+	require.NoError(net.Stop(context.Background()))
+}
+
+func TestUnstartedNetworkErrors(t *testing.T) {
+	require := require.New(t)
+	npc := &localTestPortRaceProcessCreator{}
+	networkConfig := testNetworkConfig(t)
+	networkConfig.Genesis = "not a genesis"
+	_, err := newUnstartedNetwork(logging.NoLog{}, networkConfig, npc, t.TempDir(), "", t.TempDir(), false, false, false, "", false)
+	require.Error(err)
+
+	// node start errors are only returned by Start
+	npc.failures = 1
+	networkConfig = testNetworkConfig(t)
+	networkConfig.PortRaceRetries = -1
+	un, err := newUnstartedNetwork(logging.NoLog{}, networkConfig, npc, t.TempDir(), "", t.TempDir(), false, false, false, "", false)
+	require.NoError(err)
+	_, err = un.Start(context.Background())
+	require.Error(err)
+	require.Len(npc.apiPorts, 1)
+}
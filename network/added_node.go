@@ -0,0 +1,39 @@
+package network
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+)
+
+// AddedNode is returned by Network.AddNode and Network.AddNodeFromTemplate:
+// the node just started, with the ports and dirs assigned to it, and a
+// readiness future, see Await
+type AddedNode struct {
+	node.Node
+	// Ports the node listens on, assigned if not given by its config
+	APIPort uint16
+	P2PPort uint16
+	// Dirs of the node, assigned if not given by its config
+	DataDir string
+	DBDir   string
+	LogsDir string
+}
+
+// NewAddedNode returns the AddedNode for [n], just started
+func NewAddedNode(n node.Node) *AddedNode {
+	return &AddedNode{
+		Node:    n,
+		APIPort: n.GetAPIPort(),
+		P2PPort: n.GetP2PPort(),
+		DataDir: n.GetDataDir(),
+		DBDir:   n.GetDbDir(),
+		LogsDir: n.GetLogsDir(),
+	}
+}
+
+// Await returns nil once the added node is healthy, without waiting
+// for the other nodes of the network. See node.Node.AwaitHealthy.
+func (a *AddedNode) Await(ctx context.Context) error {
+	return a.AwaitHealthy(ctx)
+}
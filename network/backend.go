@@ -0,0 +1,73 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"golang.org/x/exp/maps"
+)
+
+// DefaultBackend is the backend of the networks whose config doesn't give
+// one: the local process backend, registered by package local.
+const DefaultBackend = "local"
+
+var ErrUnknownBackend = errors.New("unknown backend")
+
+// BackendFactory creates a network from [config] on a backend, writing
+// its files under [rootDir], or a new temporary dir if empty.
+type BackendFactory func(log logging.Logger, config Config, rootDir string) (Network, error)
+
+var (
+	backendsLock sync.RWMutex
+	backends     = map[string]BackendFactory{}
+)
+
+// RegisterBackend registers [factory] under [name], so that the networks
+// whose config gives [name] as Backend are created by it with
+// NewBackendNetwork. Meant to be called from the init function of the
+// package implementing the backend. Returns an error if [name] is empty
+// or already registered.
+func RegisterBackend(name string, factory BackendFactory) error {
+	if name == "" {
+		return errors.New("backend name is empty")
+	}
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+
+	if _, ok := backends[name]; ok {
+		return fmt.Errorf("backend %q already registered", name)
+	}
+	backends[name] = factory
+	return nil
+}
+
+// Backends returns the names of the registered backends, sorted
+func Backends() []string {
+	backendsLock.RLock()
+	defer backendsLock.RUnlock()
+
+	names := maps.Keys(backends)
+	slices.Sort(names)
+	return names
+}
+
+// NewBackendNetwork creates a network from [config] with the factory
+// registered under its Backend, or DefaultBackend if not given.
+// Returns an error wrapping ErrUnknownBackend if none is registered,
+// e.g. because the package implementing it is not imported.
+func NewBackendNetwork(log logging.Logger, config Config, rootDir string) (Network, error) {
+	name := config.Backend
+	if name == "" {
+		name = DefaultBackend
+	}
+	backendsLock.RLock()
+	factory, ok := backends[name]
+	backendsLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q, registered backends are %v", ErrUnknownBackend, name, Backends())
+	}
+	return factory(log, config, rootDir)
+}
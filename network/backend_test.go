@@ -0,0 +1,34 @@
+package network_test
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBackendNetwork(t *testing.T) {
+	require := require.New(t)
+	var gotRootDir string
+	factory := func(_ logging.Logger, config network.Config, rootDir string) (network.Network, error) {
+		gotRootDir = rootDir
+		return networkfakes.NewNetwork(config)
+	}
+	require.NoError(network.RegisterBackend("test-fake", factory))
+	require.Error(network.RegisterBackend("test-fake", factory))
+	require.Error(network.RegisterBackend("", factory))
+	require.Contains(network.Backends(), "test-fake")
+
+	config := network.Config{Backend: "test-fake", NodeConfigs: []node.Config{{Name: "node1"}}}
+	net, err := network.NewBackendNetwork(logging.NoLog{}, config, "/root")
+	require.NoError(err)
+	require.IsType(&networkfakes.Network{}, net)
+	require.Equal("/root", gotRootDir)
+
+	config.Backend = "unknown"
+	_, err = network.NewBackendNetwork(logging.NoLog{}, config, "")
+	require.ErrorIs(err, network.ErrUnknownBackend)
+}
@@ -0,0 +1,86 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChainProgressPollInterval is the time between reads of the chain
+// heights by AwaitChainProgress
+const ChainProgressPollInterval = 500 * time.Millisecond
+
+var ErrNoChainProgress = errors.New("chain didn't make progress")
+
+// ChainHeightMetric returns the metric holding the last accepted height of
+// [chain], given by its alias, e.g. "C", or by its ID, as read by GetMetric
+func ChainHeightMetric(chain string) string {
+	return fmt.Sprintf("avalanche_snowman_last_accepted_height{chain=%q}", chain)
+}
+
+// AwaitChainProgress waits until [chain], given as by ChainHeightMetric,
+// advances at least [minBlocks] blocks on every running node of [net],
+// as read by its metrics, e.g. to check that the network is live after a
+// chaos experiment. The nodes are the ones running when it is called, and
+// the ones paused or removed since are counted as not advancing.
+// Returns an error wrapping ErrNoChainProgress, listing the nodes that
+// lag behind, if they didn't advance within [window].
+// Timeout is also given by the context parameter.
+func AwaitChainProgress(ctx context.Context, net Network, chain string, minBlocks uint64, window time.Duration) error {
+	metricName := ChainHeightMetric(chain)
+	deadline := time.Now().Add(window)
+	startHeights, err := net.GetMetric(ctx, metricName)
+	if err != nil {
+		return err
+	}
+	heights := startHeights
+	var readErr error
+	for {
+		lagging := []string{}
+		for nodeName, startHeight := range startHeights {
+			if heights[nodeName] < startHeight+float64(minBlocks) {
+				lagging = append(lagging, nodeName)
+			}
+		}
+		if len(lagging) == 0 {
+			return nil
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			sort.Strings(lagging)
+			progress := make([]string, 0, len(lagging))
+			for _, nodeName := range lagging {
+				progress = append(progress, fmt.Sprintf(
+					"%s advanced %g blocks",
+					nodeName, max(heights[nodeName]-startHeights[nodeName], 0),
+				))
+			}
+			err := fmt.Errorf("%w: chain %s advanced less than %d blocks in %s: %s",
+				ErrNoChainProgress, chain, minBlocks, window, strings.Join(progress, ", "))
+			if readErr != nil {
+				err = fmt.Errorf("%w, last read failed: %w", err, readErr)
+			}
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("chain %s didn't advance %d blocks: %w", chain, minBlocks, ctx.Err())
+		case <-time.After(min(ChainProgressPollInterval, remaining)):
+		}
+		// the last heights read are kept if they can't be read,
+		// e.g. while a node restarts after a fault
+		newHeights, err := net.GetMetric(ctx, metricName)
+		switch {
+		case errors.Is(err, ErrStopped):
+			return err
+		case err != nil:
+			readErr = err
+		default:
+			readErr = nil
+			heights = newHeights
+		}
+	}
+}
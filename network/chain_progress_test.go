@@ -0,0 +1,44 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAwaitChainProgress(t *testing.T) {
+	require := require.New(t)
+	net, err := networkfakes.NewNetwork(network.Config{NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}}})
+	require.NoError(err)
+	metricName := network.ChainHeightMetric("C")
+	require.Equal(`avalanche_snowman_last_accepted_height{chain="C"}`, metricName)
+
+	// the height can't be read
+	err = network.AwaitChainProgress(context.Background(), net, "C", 5, time.Second)
+	var nodeErr *network.NodeError
+	require.ErrorAs(err, &nodeErr)
+
+	net.SetMetric(metricName, 10)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		net.SetMetric(metricName, 15)
+	}()
+	require.NoError(network.AwaitChainProgress(context.Background(), net, "C", 5, 5*time.Second))
+
+	err = network.AwaitChainProgress(context.Background(), net, "C", 5, 100*time.Millisecond)
+	require.ErrorIs(err, network.ErrNoChainProgress)
+	require.ErrorContains(err, "node1 advanced 0 blocks, node2 advanced 0 blocks")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = network.AwaitChainProgress(ctx, net, "C", 5, 5*time.Second)
+	require.ErrorIs(err, context.Canceled)
+
+	require.NoError(net.Stop(context.Background()))
+	require.ErrorIs(network.AwaitChainProgress(context.Background(), net, "C", 5, time.Second), network.ErrStopped)
+}
@@ -6,24 +6,145 @@ import (
 	"fmt"
 	"math/big"
 	"net/netip"
+	"regexp"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/network/node"
 	"github.com/ava-labs/avalanche-network-runner/utils"
+	avagoconfig "github.com/ava-labs/avalanchego/config"
 	"github.com/ava-labs/avalanchego/genesis"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/units"
 )
 
 const validatorStake = units.MegaAvax
 
+// DefaultPortRaceRetries is the number of times a node failing to bind its
+// allocated ports is started again, unless given by Config.PortRaceRetries
+const DefaultPortRaceRetries = 3
+
+const (
+	// DefaultAPIRequestTimeout bounds the API calls to the nodes whose
+	// context has no deadline, unless given by Config.APIRequestTimeout
+	DefaultAPIRequestTimeout = time.Minute
+	// DefaultHealthRequestTimeout bounds each health request to the nodes,
+	// unless given by Config.HealthRequestTimeout
+	DefaultHealthRequestTimeout = 2 * time.Second
+)
+
+// Root data dir names must be usable in dir names
+var rootDataDirNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
 // AddrAndBalance holds both an address and its balance
 type AddrAndBalance struct {
 	Addr    ids.ShortID
 	Balance *big.Int
+	// Amounts locked until the given times, in addition to Balance, which is
+	// available immediately. Only supported for X-Chain balances, where they
+	// become P-Chain locked UTXOs. If nil, an amount equal to the genesis
+	// validators' stake is locked for a week.
+	UnlockSchedule []LockedAmount
+}
+
+// LockedAmount is an amount that can't be spent until Locktime
+type LockedAmount struct {
+	Amount   uint64
+	Locktime time.Time
+}
+
+// BeaconPolicy chooses the nodes to mark as beacons when none is marked,
+// given all the node configs. Returns the indexes of the chosen nodes.
+type BeaconPolicy func(nodeConfigs []node.Config) []int
+
+// FirstBeacons returns a BeaconPolicy that chooses the first [k] nodes
+// without a start delay, as beacons can't be delayed.
+func FirstBeacons(k int) BeaconPolicy {
+	return func(nodeConfigs []node.Config) []int {
+		beacons := []int{}
+		for i, nodeConfig := range nodeConfigs {
+			if len(beacons) == k {
+				break
+			}
+			if nodeConfig.StartDelay == 0 {
+				beacons = append(beacons, i)
+			}
+		}
+		return beacons
+	}
+}
+
+// PercentBeacons returns a BeaconPolicy that chooses the given percentage
+// of the nodes, rounded up, in the same way as FirstBeacons.
+func PercentBeacons(percent int) BeaconPolicy {
+	return func(nodeConfigs []node.Config) []int {
+		k := (len(nodeConfigs)*percent + 99) / 100
+		return FirstBeacons(k)(nodeConfigs)
+	}
+}
+
+// RootDataDirMode tells how the root data dir of a network is managed
+type RootDataDirMode int
+
+const (
+	// The root data dir given to the network constructor is used,
+	// or a new temporary dir that is kept after the network stops
+	DefaultRootDataDir RootDataDirMode = iota
+	// A new temporary dir, removed with all the node data when the network
+	// stops. Logs are kept if written to a separate log root dir.
+	EphemeralRootDataDir
+	// The dir at RootDataDir.Path, created if needed, and kept when the
+	// network stops, so that a network created again with it reuses the
+	// node data, including the staking keys.
+	PersistentRootDataDir
+)
+
+// RootDataDir is where the data of a network is written. Each node's data
+// is written under <root data dir>/<node name>, unless its data dir flags
+// say otherwise.
+type RootDataDir struct {
+	Mode RootDataDirMode `json:"mode"`
+	// Required on PersistentRootDataDir mode, not allowed otherwise
+	Path string `json:"path"`
+	// Name of the network, added to the name of the temporary root data dir,
+	// i.e. <artifacts dir>/network_<name>_<timestamp>, so that the runs of a
+	// network can be found. Letters, digits, '-' and '_' only.
+	// Not allowed on PersistentRootDataDir mode, and not used if the root data
+	// dir is given to the network constructor.
+	Name string `json:"name"`
+}
+
+// CleanupPolicy tells which previous runs are removed from the runner's
+// artifacts dir, where the root data dirs of networks created without one
+// are written. Runs are ordered by their last modification, so that runs of
+// networks still running are the last ones. Each limit is ignored if 0, so
+// that the zero policy keeps all the runs.
+type CleanupPolicy struct {
+	// Number of most recent runs kept. The runs still in use, e.g. by
+	// the network applying the policy, are always kept, and count first.
+	KeepLast int `json:"keepLast"`
+	// Runs not modified for longer are removed
+	MaxAge time.Duration `json:"maxAge"`
+	// Max total size of the runs kept, in bytes.
+	// The oldest runs are removed first.
+	MaxTotalSize int64 `json:"maxTotalSize"`
+}
+
+// IsZero returns true if the policy keeps all the runs
+func (p CleanupPolicy) IsZero() bool {
+	return p == CleanupPolicy{}
+}
+
+// Validate returns an error if any of the limits is negative
+func (p CleanupPolicy) Validate() error {
+	if p.KeepLast < 0 || p.MaxAge < 0 || p.MaxTotalSize < 0 {
+		return errors.New("cleanup policy limits must not be negative")
+	}
+	return nil
 }
 
 // Config that defines a network when it is created.
@@ -60,6 +181,89 @@ type Config struct {
 	BeaconConfig map[ids.NodeID]netip.AddrPort `json:"beaconConfig"`
 	// Upgrade file used for all nodes, can be empty
 	Upgrade string `json:"upgrade"`
+	// If set, and no node is a beacon, used to choose the nodes to
+	// mark as beacons, instead of failing validation with ErrNoBeacons.
+	// See FirstBeacons and PercentBeacons.
+	BeaconPolicy BeaconPolicy `json:"-"`
+	// If not on DefaultRootDataDir mode, the root data dir must not also be
+	// given to the network constructor. Can't be changed on a running network.
+	RootDataDir RootDataDir `json:"rootDataDir"`
+	// Applied to the runner's artifacts dir when the network is created
+	Cleanup CleanupPolicy `json:"cleanup"`
+	// Headers sent on all the API requests to the nodes, e.g. auth tokens,
+	// tracing headers or User-Agent, for nodes behind authenticating proxies.
+	// Not saved on snapshots.
+	APIHeaders map[string]string `json:"apiHeaders"`
+	// Timeout of the calls of the API clients of the nodes whose context has
+	// no deadline, including the calls of the network to the nodes.
+	// DefaultAPIRequestTimeout if 0, no timeout if negative.
+	// Not saved on snapshots.
+	APIRequestTimeout time.Duration `json:"apiRequestTimeout,omitempty"`
+	// Timeout of each health request of the network to the nodes, shorter
+	// than the timeout of the health wait so that a hung request is retried.
+	// DefaultHealthRequestTimeout if 0.
+	HealthRequestTimeout time.Duration `json:"healthRequestTimeout,omitempty"`
+	// If true, the primary network validators are the initial stakers of the
+	// genesis, which must all be nodes of the network, and never change: the
+	// operations issuing staking txs, i.e. subnet and blockchain creation, and
+	// validator and delegator changes, return ErrStaticValidators. Keeps
+	// consensus focused tests deterministic.
+	StaticValidators bool `json:"staticValidators"`
+	// If set, nodes are only added to the running network, e.g. by AddNode
+	// or Reconcile, if the host has the resources they are estimated to need.
+	// Not checked on network creation, see local.Preflight.
+	ResourceGuard *ResourceGuard `json:"resourceGuard,omitempty"`
+	// Name of the backend creating the network with NewBackendNetwork,
+	// see RegisterBackend. DefaultBackend if empty.
+	Backend string `json:"backend,omitempty"`
+	// Labels of the network, e.g. the CI job and branch it runs for, so that
+	// the metrics and artifacts of multiple networks can be told apart. They
+	// are given to the node processes together with the node labels, which
+	// override them, see node.Config.Labels, and listed on the manifest.
+	// Names must be valid Prometheus label names. Can't be changed on a
+	// running network.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Times a node is started again, with new ports, when it fails to bind
+	// the free ports the runner allocated to it, because another process
+	// took them in between. Only applies to nodes whose ports aren't given.
+	// DefaultPortRaceRetries if 0, no retries if negative.
+	PortRaceRetries int `json:"portRaceRetries,omitempty"`
+	// If true, each node gets its own loopback IP, from 127.0.0.2 upward, as
+	// public IP and HTTP host, instead of all of them sharing 127.0.0.1, so
+	// that the avalanchego logic based on peer IPs, e.g. IP based rate
+	// limiting or duplicate IP handling, can be tested locally. Nodes whose
+	// public IP or HTTP host are given, other than by Flags, keep them. On macOS the IPs are added
+	// as aliases of the loopback interface, what requires root.
+	LoopbackIPs bool `json:"loopbackIPs,omitempty"`
+	// If true, the lines of the node output captured by the runner, i.e. the
+	// redirected stdout and stderr and the recent logs, are prefixed with a
+	// sequence number shared by all the nodes, and the time elapsed since
+	// the network was created, read from the runner's monotonic clock as it
+	// receives them, e.g. "#42 +1.503021s ", so that the order of events
+	// across nodes can be reconstructed. The node log files are unchanged.
+	LogTimestamps bool `json:"logTimestamps,omitempty"`
+}
+
+// ApplyBeaconPolicy marks the nodes chosen by BeaconPolicy as beacons, if it
+// is set and no node is a beacon. NodeConfigs is copied before being changed.
+func (c *Config) ApplyBeaconPolicy() error {
+	if c.BeaconPolicy == nil || utils.IsPublicNetwork(c.NetworkID) {
+		return nil
+	}
+	for _, nodeConfig := range c.NodeConfigs {
+		if nodeConfig.IsBeacon {
+			return nil
+		}
+	}
+	beacons := c.BeaconPolicy(c.NodeConfigs)
+	c.NodeConfigs = slices.Clone(c.NodeConfigs)
+	for _, i := range beacons {
+		if i < 0 || i >= len(c.NodeConfigs) {
+			return fmt.Errorf("beacon policy chose node index %d, out of %d nodes", i, len(c.NodeConfigs))
+		}
+		c.NodeConfigs[i].IsBeacon = true
+	}
+	return nil
 }
 
 // Validate returns an error if this config is invalid
@@ -67,6 +271,18 @@ func (c *Config) Validate() error {
 	if utils.IsCustomNetwork(c.NetworkID) && len(c.Genesis) == 0 {
 		return errors.New("no genesis given")
 	}
+	if err := c.RootDataDir.validate(); err != nil {
+		return err
+	}
+	if err := c.Cleanup.Validate(); err != nil {
+		return err
+	}
+	if err := validateLabels(c.Labels); err != nil {
+		return err
+	}
+	if c.HealthRequestTimeout < 0 {
+		return errors.New("health request timeout must not be negative")
+	}
 
 	var someNodeIsBeacon bool
 	for i, nodeConfig := range c.NodeConfigs {
@@ -83,16 +299,226 @@ func (c *Config) Validate() error {
 			someNodeIsBeacon = true
 		}
 	}
-	if len(c.NodeConfigs) > 0 && !(utils.IsPublicNetwork(c.NetworkID) || someNodeIsBeacon) {
-		return errors.New("beacon nodes not given")
+	if len(c.NodeConfigs) > 0 && !(utils.IsPublicNetwork(c.NetworkID) || someNodeIsBeacon || c.isBeaconlessSingleNode()) {
+		return ErrNoBeacons
+	}
+	if c.StaticValidators {
+		if err := c.validateStaticValidators(); err != nil {
+			return err
+		}
+	}
+	if err := c.validateSubnetOnlyNodes(); err != nil {
+		return err
+	}
+	if c.ResourceGuard != nil {
+		if err := c.ResourceGuard.Validate(); err != nil {
+			return err
+		}
+	}
+	_, err := c.StartOrder()
+	return err
+}
+
+// Returns an error if the genesis has no initial stakers,
+// or one of them is not a node of the network
+func (c *Config) validateStaticValidators() error {
+	if utils.IsPublicNetwork(c.NetworkID) || len(c.Genesis) == 0 {
+		return errors.New("static validators require a custom genesis")
+	}
+	var genesisConfig genesis.UnparsedConfig
+	if err := json.Unmarshal([]byte(c.Genesis), &genesisConfig); err != nil {
+		return fmt.Errorf("couldn't unmarshal genesis: %w", err)
+	}
+	if len(genesisConfig.InitialStakers) == 0 {
+		return errors.New("static validators require genesis initial stakers")
+	}
+	nodeIDs := set.Set[ids.NodeID]{}
+	for _, nodeConfig := range c.NodeConfigs {
+		if nodeConfig.StakingKey == "" || nodeConfig.StakingCert == "" {
+			continue
+		}
+		nodeID, err := utils.ToNodeID([]byte(nodeConfig.StakingKey), []byte(nodeConfig.StakingCert))
+		if err != nil {
+			return fmt.Errorf("couldn't get node %q ID: %w", nodeConfig.Name, err)
+		}
+		nodeIDs.Add(nodeID)
+	}
+	for _, staker := range genesisConfig.InitialStakers {
+		if !nodeIDs.Contains(staker.NodeID) {
+			return fmt.Errorf("static validator %s is not a node of the network", staker.NodeID)
+		}
 	}
 	return nil
 }
 
+// Returns an error if a subnet-only node is a genesis staker, as it
+// wouldn't validate the primary network it is a staker of
+func (c *Config) validateSubnetOnlyNodes() error {
+	if utils.IsPublicNetwork(c.NetworkID) || len(c.Genesis) == 0 {
+		return nil
+	}
+	subnetOnlyNodes := map[ids.NodeID]string{}
+	for _, nodeConfig := range c.NodeConfigs {
+		if !nodeConfig.SubnetOnly || nodeConfig.StakingKey == "" || nodeConfig.StakingCert == "" {
+			continue
+		}
+		nodeID, err := utils.ToNodeID([]byte(nodeConfig.StakingKey), []byte(nodeConfig.StakingCert))
+		if err != nil {
+			return fmt.Errorf("couldn't get node %q ID: %w", nodeConfig.Name, err)
+		}
+		subnetOnlyNodes[nodeID] = nodeConfig.Name
+	}
+	if len(subnetOnlyNodes) == 0 {
+		return nil
+	}
+	var genesisConfig genesis.UnparsedConfig
+	if err := json.Unmarshal([]byte(c.Genesis), &genesisConfig); err != nil {
+		return fmt.Errorf("couldn't unmarshal genesis: %w", err)
+	}
+	for _, staker := range genesisConfig.InitialStakers {
+		if nodeName, ok := subnetOnlyNodes[staker.NodeID]; ok {
+			return fmt.Errorf("subnet-only node %q is a genesis staker", nodeName)
+		}
+	}
+	return nil
+}
+
+func (d RootDataDir) validate() error {
+	switch d.Mode {
+	case DefaultRootDataDir, EphemeralRootDataDir:
+		if d.Path != "" {
+			return errors.New("root data dir path is only allowed on persistent mode")
+		}
+	case PersistentRootDataDir:
+		if d.Path == "" {
+			return errors.New("persistent root data dir requires a path")
+		}
+		if d.Name != "" {
+			return errors.New("root data dir name is not allowed on persistent mode")
+		}
+	default:
+		return fmt.Errorf("unknown root data dir mode %d", d.Mode)
+	}
+	if !rootDataDirNameRegexp.MatchString(d.Name) {
+		return fmt.Errorf("invalid root data dir name %q, only letters, digits, '-' and '_' are allowed", d.Name)
+	}
+	return nil
+}
+
+// SetCChainOptions sets [opts] on the network's C-Chain config, used by all the
+// nodes, and on the C-Chain configs of the nodes that have their own, so that
+// they apply to every node, e.g. to raise the RPC gas cap for load testing.
+func (c *Config) SetCChainOptions(opts ...node.CChainOption) error {
+	chainConfigFiles, err := node.ApplyCChainOptions(c.ChainConfigFiles, opts...)
+	if err != nil {
+		return err
+	}
+	c.ChainConfigFiles = chainConfigFiles
+	for i := range c.NodeConfigs {
+		if _, ok := c.NodeConfigs[i].ChainConfigFiles[node.CChainAlias]; !ok {
+			continue
+		}
+		if err := c.NodeConfigs[i].SetCChainOptions(opts...); err != nil {
+			return fmt.Errorf("node %q: %w", c.NodeConfigs[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// StartOrder returns the node configs in the order the nodes are started on
+// network creation: beacons first, then the other nodes, each in the order
+// given by NodeConfigs, except that nodes are moved after the nodes they
+// depend on, as given by node.Config.DependsOn.
+// Returns an error if a dependency is not in NodeConfigs, has a start delay,
+// or if there is a dependency cycle.
+func (c *Config) StartOrder() ([]node.Config, error) {
+	// beacons first, keeping the given order otherwise
+	nodeConfigs := make([]node.Config, 0, len(c.NodeConfigs))
+	for _, isBeacon := range []bool{true, false} {
+		for _, nodeConfig := range c.NodeConfigs {
+			if nodeConfig.IsBeacon == isBeacon {
+				nodeConfigs = append(nodeConfigs, nodeConfig)
+			}
+		}
+	}
+	delayed := map[string]bool{}
+	for _, nodeConfig := range nodeConfigs {
+		if nodeConfig.Name != "" {
+			delayed[nodeConfig.Name] = nodeConfig.StartDelay > 0
+		}
+	}
+	for _, nodeConfig := range nodeConfigs {
+		for _, dep := range nodeConfig.DependsOn {
+			isDelayed, ok := delayed[dep]
+			switch {
+			case !ok:
+				return nil, fmt.Errorf("node %q depends on unknown node %q", nodeConfig.Name, dep)
+			case isDelayed:
+				return nil, fmt.Errorf("node %q depends on node %q, which has a start delay", nodeConfig.Name, dep)
+			}
+		}
+	}
+
+	// repeatedly take the first node whose dependencies were all taken
+	ordered := make([]node.Config, 0, len(nodeConfigs))
+	started := map[string]bool{}
+	for len(nodeConfigs) > 0 {
+		next := slices.IndexFunc(nodeConfigs, func(nodeConfig node.Config) bool {
+			for _, dep := range nodeConfig.DependsOn {
+				if !started[dep] {
+					return false
+				}
+			}
+			return true
+		})
+		if next == -1 {
+			names := make([]string, len(nodeConfigs))
+			for i, nodeConfig := range nodeConfigs {
+				names[i] = nodeConfig.Name
+			}
+			return nil, fmt.Errorf("dependency cycle between nodes %q", names)
+		}
+		ordered = append(ordered, nodeConfigs[next])
+		started[nodeConfigs[next].Name] = true
+		nodeConfigs = slices.Delete(nodeConfigs, next, next+1)
+	}
+	return ordered, nil
+}
+
+// isBeaconlessSingleNode returns true if the network has exactly one node
+// and sybil protection was explicitly disabled for it. The flag is looked up
+// with the same precedence the node is started with: node flags, then network
+// flags, then the node config file. Such a node validates on its own and
+// doesn't need a beacon to bootstrap from.
+func (c *Config) isBeaconlessSingleNode() bool {
+	if len(c.NodeConfigs) != 1 {
+		return false
+	}
+	nodeConfig := c.NodeConfigs[0]
+	v, ok := nodeConfig.Flags[avagoconfig.SybilProtectionEnabledKey]
+	if !ok {
+		v, ok = c.Flags[avagoconfig.SybilProtectionEnabledKey]
+	}
+	if !ok && nodeConfig.ConfigFile != "" {
+		var configFile map[string]interface{}
+		if err := json.Unmarshal([]byte(nodeConfig.ConfigFile), &configFile); err != nil {
+			return false
+		}
+		v, ok = configFile[avagoconfig.SybilProtectionEnabledKey]
+	}
+	if !ok {
+		return false
+	}
+	enabled, err := strconv.ParseBool(fmt.Sprintf("%v", v))
+	return err == nil && !enabled
+}
+
 // Return a genesis JSON where:
 // The nodes in [genesisVdrs] are validators.
 // The C-Chain and X-Chain balances are given by
 // [cChainBalances] and [xChainBalances].
+// Each X-Chain balance is a separate genesis allocation, so an address
+// may be given more than once, e.g. to have several vesting schedules.
 // Note that many of the genesis fields (i.e. reward addresses)
 // are randomly generated or hard-coded.
 func NewAvalancheGoGenesis(
@@ -141,18 +567,35 @@ func NewAvalancheGoGenesis(
 
 	for _, xChainBal := range xChainBalances {
 		xChainAddr, _ := address.Format("X", constants.GetHRP(networkID), xChainBal.Addr[:])
+		unlockSchedule := []genesis.LockedAmount{
+			{
+				Amount:   validatorStake * uint64(len(genesisVdrs)), // Stake
+				Locktime: uint64(time.Now().Add(7 * 24 * time.Hour).Unix()),
+			},
+		}
+		if xChainBal.UnlockSchedule != nil {
+			unlockSchedule = make([]genesis.LockedAmount, len(xChainBal.UnlockSchedule))
+			for i, lockedAmount := range xChainBal.UnlockSchedule {
+				if lockedAmount.Amount == 0 {
+					return nil, fmt.Errorf("zero locked amount for address %s", xChainAddr)
+				}
+				var locktime uint64
+				if !lockedAmount.Locktime.IsZero() {
+					locktime = uint64(lockedAmount.Locktime.Unix())
+				}
+				unlockSchedule[i] = genesis.LockedAmount{
+					Amount:   lockedAmount.Amount,
+					Locktime: locktime,
+				}
+			}
+		}
 		config.Allocations = append(
 			config.Allocations,
 			genesis.UnparsedAllocation{
-				ETHAddr:       "0x0000000000000000000000000000000000000000",
-				AVAXAddr:      xChainAddr,
-				InitialAmount: xChainBal.Balance.Uint64(),
-				UnlockSchedule: []genesis.LockedAmount{
-					{
-						Amount:   validatorStake * uint64(len(genesisVdrs)), // Stake
-						Locktime: uint64(time.Now().Add(7 * 24 * time.Hour).Unix()),
-					},
-				},
+				ETHAddr:        "0x0000000000000000000000000000000000000000",
+				AVAXAddr:       xChainAddr,
+				InitialAmount:  xChainBal.Balance.Uint64(),
+				UnlockSchedule: unlockSchedule,
 			},
 		)
 	}
@@ -160,6 +603,9 @@ func NewAvalancheGoGenesis(
 	// Set initial C-Chain balances.
 	cChainAllocs := map[string]interface{}{}
 	for _, cChainBal := range cChainBalances {
+		if cChainBal.UnlockSchedule != nil {
+			return nil, errors.New("unlock schedules are not supported for C-Chain balances")
+		}
 		addrHex := fmt.Sprintf("0x%s", cChainBal.Addr.Hex())
 		balHex := fmt.Sprintf("0x%x", cChainBal.Balance)
 		cChainAllocs[addrHex] = map[string]interface{}{
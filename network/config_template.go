@@ -0,0 +1,116 @@
+package network
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/template"
+
+	"github.com/ava-labs/avalanche-network-runner/utils"
+)
+
+// ConfigTemplateData holds the values config templates can refer to,
+// as {{.BinaryPath}}, {{.PluginDir}}, {{.RootDir}}, and {{.Vars.name}}
+// for any other value. See RenderConfigTemplate.
+type ConfigTemplateData struct {
+	BinaryPath string
+	PluginDir  string
+	RootDir    string
+	Vars       map[string]string
+}
+
+// RenderConfigTemplate executes [text] as a Go template with [data], so that
+// a config file doesn't have to hard-code machine specific values. Besides the
+// fields of [data], templates can use:
+//   - {{env "NAME"}}, the value of the environment variable NAME
+//   - {{port}}, a free port on localhost, different on each use
+//
+// The values are JSON-escaped, as config templates are JSON, so that they
+// can be used within JSON strings, e.g. "binaryPath": "{{.BinaryPath}}",
+// whatever quotes or backslashes they have.
+// Referring to a missing field or variable is an error.
+func RenderConfigTemplate(text string, data ConfigTemplateData) ([]byte, error) {
+	data.BinaryPath = jsonEscape(data.BinaryPath)
+	data.PluginDir = jsonEscape(data.PluginDir)
+	data.RootDir = jsonEscape(data.RootDir)
+	if data.Vars != nil {
+		vars := make(map[string]string, len(data.Vars))
+		for name, value := range data.Vars {
+			vars[name] = jsonEscape(value)
+		}
+		data.Vars = vars
+	}
+	usedPorts := map[uint16]struct{}{}
+	funcs := template.FuncMap{
+		"env": func(name string) string {
+			return jsonEscape(os.Getenv(name))
+		},
+		"port": func() (uint16, error) {
+			for {
+				port, err := utils.GetFreePort()
+				if err != nil {
+					return 0, err
+				}
+				if _, ok := usedPorts[port]; !ok {
+					usedPorts[port] = struct{}{}
+					return port, nil
+				}
+			}
+		},
+	}
+	tmpl, err := template.New("config").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse config template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("couldn't render config template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Returns [s] escaped to be used within a JSON string
+func jsonEscape(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	// strings are always encoded
+	_ = encoder.Encode(s)
+	quoted := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	return string(quoted[1 : len(quoted)-1])
+}
+
+// LoadConfig reads the JSON network config at [path],
+// rendering it first with RenderConfigTemplate.
+func LoadConfig(path string, data ConfigTemplateData) (Config, error) {
+	configBytes, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("couldn't read network config: %w", err)
+	}
+	configBytes, err = RenderConfigTemplate(string(configBytes), data)
+	if err != nil {
+		return Config{}, err
+	}
+	var config Config
+	if err := json.Unmarshal(configBytes, &config); err != nil {
+		return Config{}, fmt.Errorf("couldn't unmarshal network config: %w", err)
+	}
+	return config, nil
+}
+
+// SaveConfig writes [config] to [path] as JSON, so that it can be read back
+// with LoadConfig, e.g. to create again a network described by its ToConfig.
+// Template delimiters found in the config, e.g. in config files, are escaped,
+// so that they are read back as they are.
+func SaveConfig(path string, config Config) error {
+	configBytes, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't marshal network config: %w", err)
+	}
+	configBytes = bytes.ReplaceAll(configBytes, []byte("{{"), []byte(`{{"{{"}}`))
+	if err := os.WriteFile(path, configBytes, 0o600); err != nil {
+		return fmt.Errorf("couldn't write network config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package network_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	require := require.New(t)
+	t.Setenv("ANR_TEST_GENESIS", "test genesis")
+	configPath := filepath.Join(t.TempDir(), "network.json")
+	require.NoError(os.WriteFile(configPath, []byte(`{
+		"genesis": "{{env "ANR_TEST_GENESIS"}}",
+		"binaryPath": "{{.BinaryPath}}",
+		"nodeConfigs": [
+			{"name": "node1", "flags": {"http-port": {{port}}, "staking-port": {{port}}, "plugin-dir": "{{.PluginDir}}"}},
+			{"name": "{{.Vars.name}}"}
+		]
+	}`), 0o600))
+	data := network.ConfigTemplateData{
+		BinaryPath: "/path/to/avalanchego",
+		PluginDir:  "/path/to/plugins",
+		Vars:       map[string]string{"name": "node2"},
+	}
+	config, err := network.LoadConfig(configPath, data)
+	require.NoError(err)
+	require.Equal("test genesis", config.Genesis)
+	require.Equal("/path/to/avalanchego", config.BinaryPath)
+	require.Len(config.NodeConfigs, 2)
+	flags := config.NodeConfigs[0].Flags
+	require.Equal("/path/to/plugins", flags["plugin-dir"])
+	require.NotZero(flags["http-port"])
+	require.NotEqual(flags["http-port"], flags["staking-port"])
+	require.Equal("node2", config.NodeConfigs[1].Name)
+
+	// missing variables are not rendered empty
+	_, err = network.RenderConfigTemplate(`{{.Vars.missing}}`, data)
+	require.Error(err)
+
+	// values are escaped within JSON strings
+	t.Setenv("ANR_TEST_GENESIS", `{"networkID": 1337}`)
+	data = network.ConfigTemplateData{
+		BinaryPath: `C:\avalanchego\avalanchego.exe`,
+		Vars:       map[string]string{"name": `node "2" \ <a&b>`},
+	}
+	config, err = network.LoadConfig(configPath, data)
+	require.NoError(err)
+	require.Equal(`{"networkID": 1337}`, config.Genesis)
+	require.Equal(`C:\avalanchego\avalanchego.exe`, config.BinaryPath)
+	require.Equal(`node "2" \ <a&b>`, config.NodeConfigs[1].Name)
+}
@@ -2,10 +2,17 @@ package network_test
 
 import (
 	"encoding/json"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/network"
 	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/stretchr/testify/require"
 )
 
@@ -58,3 +65,405 @@ func TestConfigMarshalJSON(t *testing.T) {
 
 	require.EqualValues(t, control, netcfg)
 }
+
+func TestConfigValidateBeaconlessSingleNode(t *testing.T) {
+	tests := map[string]struct {
+		config      network.Config
+		expectError bool
+	}{
+		"single node without beacon": {
+			config: network.Config{
+				Genesis:     "{\"networkID\": 1337}",
+				NodeConfigs: []node.Config{{}},
+			},
+			expectError: true,
+		},
+		"single node with sybil protection disabled on node": {
+			config: network.Config{
+				Genesis: "{\"networkID\": 1337}",
+				NodeConfigs: []node.Config{
+					{Flags: map[string]interface{}{"sybil-protection-enabled": false}},
+				},
+			},
+		},
+		"single node with sybil protection disabled on network": {
+			config: network.Config{
+				Genesis:     "{\"networkID\": 1337}",
+				NodeConfigs: []node.Config{{}},
+				Flags:       map[string]interface{}{"sybil-protection-enabled": "false"},
+			},
+		},
+		"node flag overrides network flag": {
+			config: network.Config{
+				Genesis: "{\"networkID\": 1337}",
+				NodeConfigs: []node.Config{
+					{Flags: map[string]interface{}{"sybil-protection-enabled": true}},
+				},
+				Flags: map[string]interface{}{"sybil-protection-enabled": false},
+			},
+			expectError: true,
+		},
+		"single node with sybil protection disabled on config file": {
+			config: network.Config{
+				Genesis: "{\"networkID\": 1337}",
+				NodeConfigs: []node.Config{
+					{ConfigFile: "{\"sybil-protection-enabled\": false}"},
+				},
+			},
+		},
+		"network flag overrides config file": {
+			config: network.Config{
+				Genesis: "{\"networkID\": 1337}",
+				NodeConfigs: []node.Config{
+					{ConfigFile: "{\"sybil-protection-enabled\": false}"},
+				},
+				Flags: map[string]interface{}{"sybil-protection-enabled": true},
+			},
+			expectError: true,
+		},
+		"two nodes with sybil protection disabled": {
+			config: network.Config{
+				Genesis:     "{\"networkID\": 1337}",
+				NodeConfigs: []node.Config{{}, {}},
+				Flags:       map[string]interface{}{"sybil-protection-enabled": false},
+			},
+			expectError: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expectError {
+				require.ErrorIs(t, err, network.ErrNoBeacons)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestApplyBeaconPolicy(t *testing.T) {
+	tests := map[string]struct {
+		policy          network.BeaconPolicy
+		nodeConfigs     []node.Config
+		expectedBeacons []bool
+		expectError     bool
+	}{
+		"first beacons": {
+			policy:          network.FirstBeacons(2),
+			nodeConfigs:     []node.Config{{}, {StartDelay: time.Second}, {}, {}},
+			expectedBeacons: []bool{true, false, true, false},
+		},
+		"more beacons than nodes": {
+			policy:          network.FirstBeacons(3),
+			nodeConfigs:     []node.Config{{}, {}},
+			expectedBeacons: []bool{true, true},
+		},
+		"percent beacons rounded up": {
+			policy:          network.PercentBeacons(50),
+			nodeConfigs:     []node.Config{{}, {}, {}},
+			expectedBeacons: []bool{true, true, false},
+		},
+		"beacon already given": {
+			policy:          network.FirstBeacons(2),
+			nodeConfigs:     []node.Config{{}, {IsBeacon: true}},
+			expectedBeacons: []bool{false, true},
+		},
+		"no policy": {
+			nodeConfigs:     []node.Config{{}, {}},
+			expectedBeacons: []bool{false, false},
+		},
+		"index out of range": {
+			policy:      func([]node.Config) []int { return []int{2} },
+			nodeConfigs: []node.Config{{}, {}},
+			expectError: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			config := network.Config{
+				Genesis:      "{\"networkID\": 1337}",
+				NodeConfigs:  tt.nodeConfigs,
+				BeaconPolicy: tt.policy,
+			}
+			err := config.ApplyBeaconPolicy()
+			if tt.expectError {
+				require.Error(err)
+				return
+			}
+			require.NoError(err)
+			beacons := make([]bool, len(config.NodeConfigs))
+			for i, nodeConfig := range config.NodeConfigs {
+				beacons[i] = nodeConfig.IsBeacon
+			}
+			require.Equal(tt.expectedBeacons, beacons)
+			if tt.policy != nil {
+				require.NoError(config.Validate())
+			}
+			// the given node configs are not changed
+			require.False(tt.nodeConfigs[0].IsBeacon)
+		})
+	}
+}
+
+func TestConfigValidateRootDataDir(t *testing.T) {
+	require := require.New(t)
+	config := network.Config{Genesis: "{\"networkID\": 1337}"}
+	require.NoError(config.Validate())
+	config.RootDataDir = network.RootDataDir{Mode: network.EphemeralRootDataDir}
+	require.NoError(config.Validate())
+	config.RootDataDir.Path = "/tmp/network"
+	require.Error(config.Validate())
+	config.RootDataDir.Mode = network.PersistentRootDataDir
+	require.NoError(config.Validate())
+	config.RootDataDir.Path = ""
+	require.Error(config.Validate())
+	config.RootDataDir.Mode = 5
+	require.Error(config.Validate())
+
+	config.RootDataDir = network.RootDataDir{Name: "my-net_1"}
+	require.NoError(config.Validate())
+	config.RootDataDir.Name = "my/net"
+	require.Error(config.Validate())
+	config.RootDataDir = network.RootDataDir{Mode: network.PersistentRootDataDir, Path: "/tmp/network", Name: "mynet"}
+	require.Error(config.Validate())
+}
+
+func TestConfigValidateLabels(t *testing.T) {
+	require := require.New(t)
+	config := network.Config{Genesis: "{\"networkID\": 1337}"}
+	config.Labels = map[string]string{"job": "ci", "_branch2": "main"}
+	require.NoError(config.Validate())
+	config.Labels = map[string]string{"ci-job": "1"}
+	require.Error(config.Validate())
+	config.Labels = map[string]string{"2job": "1"}
+	require.Error(config.Validate())
+	config.Labels = map[string]string{"__name__": "1"}
+	require.Error(config.Validate())
+}
+
+func TestConfigStartOrder(t *testing.T) {
+	tests := map[string]struct {
+		nodeConfigs   []node.Config
+		expectedOrder []string
+		expectError   bool
+	}{
+		"beacons first": {
+			nodeConfigs:   []node.Config{{Name: "a"}, {Name: "b", IsBeacon: true}, {Name: "c"}},
+			expectedOrder: []string{"b", "a", "c"},
+		},
+		"after dependencies": {
+			nodeConfigs: []node.Config{
+				{Name: "a", IsBeacon: true},
+				{Name: "b", DependsOn: []string{"d"}},
+				{Name: "c"},
+				{Name: "d", DependsOn: []string{"a", "c"}},
+			},
+			expectedOrder: []string{"a", "c", "d", "b"},
+		},
+		"unknown dependency": {
+			nodeConfigs: []node.Config{{Name: "a", IsBeacon: true, DependsOn: []string{"b"}}},
+			expectError: true,
+		},
+		"delayed dependency": {
+			nodeConfigs: []node.Config{
+				{Name: "a", IsBeacon: true, DependsOn: []string{"b"}},
+				{Name: "b", StartDelay: time.Second},
+			},
+			expectError: true,
+		},
+		"cycle": {
+			nodeConfigs: []node.Config{
+				{Name: "a", IsBeacon: true},
+				{Name: "b", DependsOn: []string{"c"}},
+				{Name: "c", DependsOn: []string{"b"}},
+			},
+			expectError: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			config := network.Config{NodeConfigs: tt.nodeConfigs}
+			nodeConfigs, err := config.StartOrder()
+			if tt.expectError {
+				require.Error(err)
+				return
+			}
+			require.NoError(err)
+			order := make([]string, len(nodeConfigs))
+			for i, nodeConfig := range nodeConfigs {
+				order[i] = nodeConfig.Name
+			}
+			require.Equal(tt.expectedOrder, order)
+		})
+	}
+}
+
+func TestNewAvalancheGoGenesisUnlockSchedules(t *testing.T) {
+	require := require.New(t)
+	addr := ids.GenerateTestShortID()
+	locktime := time.Now().Add(time.Hour).Truncate(time.Second)
+	genesisBytes, err := network.NewAvalancheGoGenesis(
+		1337,
+		[]network.AddrAndBalance{
+			{Addr: addr, Balance: big.NewInt(1)},
+			{
+				Addr:    addr,
+				Balance: big.NewInt(2),
+				UnlockSchedule: []network.LockedAmount{
+					{Amount: 10},
+					{Amount: 20, Locktime: locktime},
+				},
+			},
+		},
+		nil,
+		[]ids.NodeID{ids.GenerateTestNodeID()},
+	)
+	require.NoError(err)
+	var config genesis.UnparsedConfig
+	require.NoError(json.Unmarshal(genesisBytes, &config))
+	// the first allocation provides stake to validators
+	allocations := config.Allocations[1:]
+	require.Len(allocations, 2)
+	require.Equal(allocations[0].AVAXAddr, allocations[1].AVAXAddr)
+	require.Equal(uint64(1), allocations[0].InitialAmount)
+	require.Len(allocations[0].UnlockSchedule, 1)
+	require.Equal(uint64(2), allocations[1].InitialAmount)
+	require.Equal([]genesis.LockedAmount{
+		{Amount: 10},
+		{Amount: 20, Locktime: uint64(locktime.Unix())},
+	}, allocations[1].UnlockSchedule)
+
+	_, err = network.NewAvalancheGoGenesis(
+		1337,
+		nil,
+		[]network.AddrAndBalance{
+			{
+				Addr:           addr,
+				Balance:        big.NewInt(1),
+				UnlockSchedule: []network.LockedAmount{{Amount: 1}},
+			},
+		},
+		[]ids.NodeID{ids.GenerateTestNodeID()},
+	)
+	require.Error(err)
+}
+
+func TestConfigSetCChainOptions(t *testing.T) {
+	require := require.New(t)
+	config := network.Config{
+		ChainConfigFiles: map[string]string{"C": `{"log-level":"info","rpc-gas-cap":50000000}`},
+		NodeConfigs: []node.Config{
+			{Name: "node1"},
+			{Name: "node2", ChainConfigFiles: map[string]string{"C": `{"pruning-enabled":false}`}},
+		},
+	}
+	require.NoError(config.SetCChainOptions(
+		node.WithRPCGasCap(1_000_000_000),
+		node.WithTxPoolSlots(64, 10_000),
+		node.WithEthAPIs("eth", "debug-tracer"),
+		node.WithContinuousProfiler("/tmp/profiles", time.Minute, 3),
+		node.WithCorethOption("allow-unprotected-txs", true),
+	))
+	require.JSONEq(`{
+		"log-level": "info",
+		"rpc-gas-cap": 1000000000,
+		"tx-pool-account-slots": 64,
+		"tx-pool-global-slots": 10000,
+		"eth-apis": ["eth", "debug-tracer"],
+		"continuous-profiler-dir": "/tmp/profiles",
+		"continuous-profiler-frequency": "1m0s",
+		"continuous-profiler-max-files": 3,
+		"allow-unprotected-txs": true
+	}`, config.ChainConfigFiles["C"])
+	// nodes without their own C-Chain config use the network's
+	require.Empty(config.NodeConfigs[0].ChainConfigFiles)
+	require.JSONEq(`{
+		"pruning-enabled": false,
+		"rpc-gas-cap": 1000000000,
+		"tx-pool-account-slots": 64,
+		"tx-pool-global-slots": 10000,
+		"eth-apis": ["eth", "debug-tracer"],
+		"continuous-profiler-dir": "/tmp/profiles",
+		"continuous-profiler-frequency": "1m0s",
+		"continuous-profiler-max-files": 3,
+		"allow-unprotected-txs": true
+	}`, config.NodeConfigs[1].ChainConfigFiles["C"])
+
+	config.ChainConfigFiles["C"] = "not json"
+	require.Error(config.SetCChainOptions(node.WithRPCGasCap(0)))
+}
+
+func TestConfigValidateStaticValidators(t *testing.T) {
+	require := require.New(t)
+	stakingCert, stakingKey, err := staking.NewCertAndKeyBytes()
+	require.NoError(err)
+	nodeID, err := utils.ToNodeID(stakingKey, stakingCert)
+	require.NoError(err)
+	newGenesis := func(nodeIDs ...ids.NodeID) string {
+		genesisBytes, err := network.NewAvalancheGoGenesis(
+			1337,
+			[]network.AddrAndBalance{{Addr: ids.GenerateTestShortID(), Balance: big.NewInt(1)}},
+			nil,
+			nodeIDs,
+		)
+		require.NoError(err)
+		return string(genesisBytes)
+	}
+	config := network.Config{
+		Genesis: newGenesis(nodeID),
+		NodeConfigs: []node.Config{
+			{Name: "validator", IsBeacon: true, StakingKey: string(stakingKey), StakingCert: string(stakingCert)},
+			// nodes that are not genesis validators are allowed
+			{Name: "api"},
+		},
+		StaticValidators: true,
+	}
+	require.NoError(config.Validate())
+
+	config.Genesis = newGenesis(nodeID, ids.GenerateTestNodeID())
+	require.ErrorContains(config.Validate(), "is not a node of the network")
+	config.Genesis = `{"networkID": 1337, "initialStakers": []}`
+	require.ErrorContains(config.Validate(), "require genesis initial stakers")
+	config.Genesis = ""
+	config.NetworkID = constants.FujiID
+	require.ErrorContains(config.Validate(), "require a custom genesis")
+	config.StaticValidators = false
+	require.NoError(config.Validate())
+}
+
+func TestConfigValidateSubnetOnlyNodes(t *testing.T) {
+	require := require.New(t)
+	stakingCert, stakingKey, err := staking.NewCertAndKeyBytes()
+	require.NoError(err)
+	nodeID, err := utils.ToNodeID(stakingKey, stakingCert)
+	require.NoError(err)
+	subnetOnlyCert, subnetOnlyKey, err := staking.NewCertAndKeyBytes()
+	require.NoError(err)
+	subnetOnlyNodeID, err := utils.ToNodeID(subnetOnlyKey, subnetOnlyCert)
+	require.NoError(err)
+	newGenesis := func(nodeIDs ...ids.NodeID) string {
+		genesisBytes, err := network.NewAvalancheGoGenesis(
+			1337,
+			[]network.AddrAndBalance{{Addr: ids.GenerateTestShortID(), Balance: big.NewInt(1)}},
+			nil,
+			nodeIDs,
+		)
+		require.NoError(err)
+		return string(genesisBytes)
+	}
+	config := network.Config{
+		Genesis: newGenesis(nodeID),
+		NodeConfigs: []node.Config{
+			{Name: "validator", IsBeacon: true, StakingKey: string(stakingKey), StakingCert: string(stakingCert)},
+			{Name: "subnet-only", SubnetOnly: true, StakingKey: string(subnetOnlyKey), StakingCert: string(subnetOnlyCert)},
+			{Name: "subnet-only-new", SubnetOnly: true},
+		},
+	}
+	require.NoError(config.Validate())
+
+	config.Genesis = newGenesis(nodeID, subnetOnlyNodeID)
+	require.ErrorContains(config.Validate(), `subnet-only node "subnet-only" is a genesis staker`)
+}
@@ -0,0 +1,83 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
+)
+
+var ErrConsensusParametersMismatch = errors.New("nodes run with different consensus parameters")
+
+// CheckConsensusParameters returns the primary network consensus parameters
+// the running nodes of [net] agree on, as given by node.Node's
+// ConsensusParameters. Nodes running with mixed parameters, e.g. because
+// of a config file overriding them on some nodes, may not reach the quorum
+// they expect, so the network can stall without any node being unhealthy.
+// Returns an error wrapping ErrConsensusParametersMismatch, listing the
+// nodes by parameters, if they don't all agree.
+// Returns ErrNoRunningNodes if all the nodes are paused or there are none.
+// Timeout is given by the context parameter.
+func CheckConsensusParameters(ctx context.Context, net Network) (snowball.Parameters, error) {
+	nodes, err := net.GetAllNodes()
+	if err != nil {
+		return snowball.Parameters{}, err
+	}
+	nodeNames := []string{}
+	for nodeName, n := range nodes {
+		if !n.GetPaused() {
+			nodeNames = append(nodeNames, nodeName)
+		}
+	}
+	if len(nodeNames) == 0 {
+		return snowball.Parameters{}, ErrNoRunningNodes
+	}
+	sort.Strings(nodeNames)
+
+	// parameters, in the order they were first seen --> the nodes running with them
+	parameters := []snowball.Parameters{}
+	nodesByParameters := map[snowball.Parameters][]string{}
+	for _, nodeName := range nodeNames {
+		nodeParameters, err := nodes[nodeName].ConsensusParameters(ctx)
+		if err != nil {
+			return snowball.Parameters{}, err
+		}
+		// deprecated, only read when unmarshalling, and a pointer,
+		// so that equal parameters wouldn't compare equal
+		nodeParameters.Alpha = nil
+		if _, ok := nodesByParameters[nodeParameters]; !ok {
+			parameters = append(parameters, nodeParameters)
+		}
+		nodesByParameters[nodeParameters] = append(nodesByParameters[nodeParameters], nodeName)
+	}
+	if len(parameters) == 1 {
+		return parameters[0], nil
+	}
+	groups := make([]string, 0, len(parameters))
+	for _, groupParameters := range parameters {
+		groups = append(groups, fmt.Sprintf(
+			"%s run with %s",
+			strings.Join(nodesByParameters[groupParameters], ", "),
+			formatConsensusParameters(groupParameters),
+		))
+	}
+	return snowball.Parameters{}, fmt.Errorf("%w: %s", ErrConsensusParametersMismatch, strings.Join(groups, "; "))
+}
+
+// Returns [parameters] in short, for errors
+func formatConsensusParameters(parameters snowball.Parameters) string {
+	return fmt.Sprintf(
+		"k=%d alpha-preference=%d alpha-confidence=%d beta=%d concurrent-repolls=%d optimal-processing=%d max-processing=%d max-time-processing=%s",
+		parameters.K,
+		parameters.AlphaPreference,
+		parameters.AlphaConfidence,
+		parameters.Beta,
+		parameters.ConcurrentRepolls,
+		parameters.OptimalProcessing,
+		parameters.MaxOutstandingItems,
+		parameters.MaxItemProcessingTime,
+	)
+}
@@ -0,0 +1,42 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConsensusParameters(t *testing.T) {
+	require := require.New(t)
+	net, err := networkfakes.NewNetwork(network.Config{NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}, {Name: "node3"}}})
+	require.NoError(err)
+
+	parameters, err := network.CheckConsensusParameters(context.Background(), net)
+	require.NoError(err)
+	require.Equal(snowball.DefaultParameters, parameters)
+
+	// a node runs with a smaller sample size
+	mixedParameters := snowball.DefaultParameters
+	mixedParameters.K = 5
+	n, err := net.GetNode("node2")
+	require.NoError(err)
+	n.(*networkfakes.Node).SetConsensusParameters(mixedParameters)
+	_, err = network.CheckConsensusParameters(context.Background(), net)
+	require.ErrorIs(err, network.ErrConsensusParametersMismatch)
+	require.ErrorContains(err, "node1, node3 run with k=20 ")
+	require.ErrorContains(err, "node2 run with k=5 ")
+
+	// paused nodes are not checked
+	require.NoError(net.PauseNode(context.Background(), "node2"))
+	_, err = network.CheckConsensusParameters(context.Background(), net)
+	require.NoError(err)
+
+	require.NoError(net.Stop(context.Background()))
+	_, err = network.CheckConsensusParameters(context.Background(), net)
+	require.ErrorIs(err, network.ErrStopped)
+}
@@ -0,0 +1,376 @@
+// Package crosschain moves AVAX between the X-Chain, P-Chain and C-Chain of
+// a network with export and import txs, issued via one of its nodes, and
+// checks the balances on all its running nodes after each step, as the
+// integration tests of downstream repos commonly do.
+package crosschain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanchego/ids"
+	avagoConstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+	"github.com/ava-labs/coreth/plugin/evm"
+	"golang.org/x/sync/errgroup"
+)
+
+// Chain is a primary network chain, given by its alias
+type Chain = network.Chain
+
+const (
+	XChain = network.XChain
+	PChain = network.PChain
+	CChain = network.CChain
+)
+
+// DefaultPollInterval is the time between checks of the balances on each
+// node, and of the status of the issued txs, unless given by
+// Client.PollInterval
+const DefaultPollInterval = 100 * time.Millisecond
+
+// C-Chain balances are in wei, 1 nAVAX is 10^9 wei
+var weiPerNAVAX = big.NewInt(1_000_000_000)
+
+var ErrUnexpectedBalance = errors.New("unexpected balance")
+
+// Client moves AVAX owned by a key between chains, see NewClient
+type Client struct {
+	net network.Network
+	key *secp256k1.PrivateKey
+	// Name of the node the txs are issued via.
+	// If empty, the first running node by name.
+	NodeName string
+	// Time between checks of the balances on each node, and of the status
+	// of the issued txs. If 0, DefaultPollInterval.
+	PollInterval time.Duration
+}
+
+// NewClient returns a Client moving AVAX owned by [key] on [net], e.g.
+// genesis.EWOQKey on local networks. The key also pays the tx fees.
+func NewClient(net network.Network, key *secp256k1.PrivateKey) *Client {
+	return &Client{net: net, key: key}
+}
+
+// Transfer is the result of Client.Transfer
+type Transfer struct {
+	ExportTxID ids.ID
+	ImportTxID ids.ID
+	// nAVAX received on the destination chain,
+	// i.e. the exported amount minus the import fee
+	Received uint64
+}
+
+// Transfer exports [amount] nAVAX from [from] to [to], and imports it on [to],
+// owned by the client's key on both. After the export, it waits until all
+// the running nodes report the balance left on [from], and after the import,
+// the balance reached on [to], as reported by the issuing node. Returns an
+// error wrapping ErrUnexpectedBalance if the issuing node reports that the
+// balance on [from] didn't decrease by at least [amount], or the balance on
+// [to] didn't increase by at most [amount].
+// Timeout is given by the context parameter.
+func (c *Client) Transfer(ctx context.Context, from Chain, to Chain, amount uint64) (Transfer, error) {
+	if from == to {
+		return Transfer{}, fmt.Errorf("can't transfer from the %s-Chain to itself", from)
+	}
+	issuer, err := c.issuer()
+	if err != nil {
+		return Transfer{}, err
+	}
+	w, err := c.wallet(ctx, issuer)
+	if err != nil {
+		return Transfer{}, err
+	}
+	fromBalance, err := c.Balance(ctx, issuer, from)
+	if err != nil {
+		return Transfer{}, err
+	}
+	toBalance, err := c.Balance(ctx, issuer, to)
+	if err != nil {
+		return Transfer{}, err
+	}
+
+	var transfer Transfer
+	transfer.ExportTxID, err = c.export(ctx, w, from, to, amount)
+	if err != nil {
+		return Transfer{}, fmt.Errorf("couldn't issue export tx from the %s-Chain to the %s-Chain: %w", from, to, err)
+	}
+	newFromBalance, err := c.awaitBalances(ctx, issuer, from)
+	if err != nil {
+		return Transfer{}, err
+	}
+	if newFromBalance > fromBalance || fromBalance-newFromBalance < amount {
+		return Transfer{}, fmt.Errorf("%w: %s-Chain balance went from %d to %d after exporting %d",
+			ErrUnexpectedBalance, from, fromBalance, newFromBalance, amount)
+	}
+
+	transfer.ImportTxID, err = c.importTx(ctx, w, from, to)
+	if err != nil {
+		return Transfer{}, fmt.Errorf("couldn't issue import tx from the %s-Chain to the %s-Chain: %w", from, to, err)
+	}
+	newToBalance, err := c.awaitBalances(ctx, issuer, to)
+	if err != nil {
+		return Transfer{}, err
+	}
+	if newToBalance <= toBalance || newToBalance-toBalance > amount {
+		return Transfer{}, fmt.Errorf("%w: %s-Chain balance went from %d to %d after importing %d",
+			ErrUnexpectedBalance, to, toBalance, newToBalance, amount)
+	}
+	transfer.Received = newToBalance - toBalance
+	return transfer, nil
+}
+
+// Issues a tx exporting [amount] from [from] to [to], owned by the client's key
+func (c *Client) export(ctx context.Context, w primary.Wallet, from Chain, to Chain, amount uint64) (ids.ID, error) {
+	toChainID, err := chainID(w, to)
+	if err != nil {
+		return ids.Empty, err
+	}
+	out := &secp256k1fx.TransferOutput{
+		Amt:          amount,
+		OutputOwners: c.owners(),
+	}
+	outputs := []*avax.TransferableOutput{{
+		Asset: avax.Asset{ID: w.X().Builder().Context().AVAXAssetID},
+		Out:   out,
+	}}
+	options := c.options(ctx)
+	switch from {
+	case XChain:
+		tx, err := w.X().IssueExportTx(toChainID, outputs, options...)
+		if err != nil {
+			return ids.Empty, err
+		}
+		return tx.ID(), nil
+	case PChain:
+		tx, err := w.P().IssueExportTx(toChainID, outputs, options...)
+		if err != nil {
+			return ids.Empty, err
+		}
+		return tx.ID(), nil
+	case CChain:
+		tx, err := w.C().IssueExportTx(toChainID, []*secp256k1fx.TransferOutput{out}, options...)
+		if err != nil {
+			return ids.Empty, err
+		}
+		return tx.ID(), nil
+	default:
+		return ids.Empty, fmt.Errorf("unknown chain %q", from)
+	}
+}
+
+// Issues a tx importing on [to] all the funds exported from [from]
+// to the client's key
+func (c *Client) importTx(ctx context.Context, w primary.Wallet, from Chain, to Chain) (ids.ID, error) {
+	fromChainID, err := chainID(w, from)
+	if err != nil {
+		return ids.Empty, err
+	}
+	owners := c.owners()
+	options := c.options(ctx)
+	switch to {
+	case XChain:
+		tx, err := w.X().IssueImportTx(fromChainID, &owners, options...)
+		if err != nil {
+			return ids.Empty, err
+		}
+		return tx.ID(), nil
+	case PChain:
+		tx, err := w.P().IssueImportTx(fromChainID, &owners, options...)
+		if err != nil {
+			return ids.Empty, err
+		}
+		return tx.ID(), nil
+	case CChain:
+		tx, err := w.C().IssueImportTx(fromChainID, evm.GetEthAddress(c.key), options...)
+		if err != nil {
+			return ids.Empty, err
+		}
+		return tx.ID(), nil
+	default:
+		return ids.Empty, fmt.Errorf("unknown chain %q", to)
+	}
+}
+
+// Balances returns the balance of the client's key on [chain], in nAVAX, as
+// reported by each running node, by node name. On the C-Chain, the balance
+// is truncated to nAVAX.
+// Timeout is given by the context parameter.
+func (c *Client) Balances(ctx context.Context, chain Chain) (map[string]uint64, error) {
+	nodes, err := runningNodes(c.net)
+	if err != nil {
+		return nil, err
+	}
+	balances := map[string]uint64{}
+	for _, n := range nodes {
+		balances[n.GetName()], err = c.Balance(ctx, n, chain)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return balances, nil
+}
+
+// Balance returns the balance of the client's key on [chain], in nAVAX,
+// as reported by [n]. See Balances.
+func (c *Client) Balance(ctx context.Context, n node.Node, chain Chain) (uint64, error) {
+	client := n.GetAPIClient()
+	var balance uint64
+	switch chain {
+	case XChain:
+		res, err := client.XChainAPI().GetBalance(ctx, c.key.Address(), "AVAX", false)
+		if err != nil {
+			return 0, &network.NodeError{NodeName: n.GetName(), Op: "get X-Chain balance of", Err: err}
+		}
+		balance = uint64(res.Balance)
+	case PChain:
+		res, err := client.PChainAPI().GetBalance(ctx, []ids.ShortID{c.key.Address()})
+		if err != nil {
+			return 0, &network.NodeError{NodeName: n.GetName(), Op: "get P-Chain balance of", Err: err}
+		}
+		balance = uint64(res.Unlocked)
+	case CChain:
+		wei, err := client.CChainEthAPI().BalanceAt(ctx, evm.GetEthAddress(c.key), nil)
+		if err != nil {
+			return 0, &network.NodeError{NodeName: n.GetName(), Op: "get C-Chain balance of", Err: err}
+		}
+		balance = new(big.Int).Div(wei, weiPerNAVAX).Uint64()
+	default:
+		return 0, fmt.Errorf("unknown chain %q", chain)
+	}
+	return balance, nil
+}
+
+// Waits until all the running nodes report the balance of the client's key
+// on [chain] that [issuer] reports, and returns it
+func (c *Client) awaitBalances(ctx context.Context, issuer node.Node, chain Chain) (uint64, error) {
+	balance, err := c.Balance(ctx, issuer, chain)
+	if err != nil {
+		return 0, err
+	}
+	nodes, err := runningNodes(c.net)
+	if err != nil {
+		return 0, err
+	}
+	eg, egCtx := errgroup.WithContext(ctx)
+	for _, n := range nodes {
+		eg.Go(func() error {
+			return c.awaitBalance(egCtx, n, chain, balance)
+		})
+	}
+	return balance, eg.Wait()
+}
+
+// Waits until [n] reports [balance] as the balance of the client's key on [chain]
+func (c *Client) awaitBalance(ctx context.Context, n node.Node, chain Chain, balance uint64) error {
+	ticker := time.NewTicker(c.pollInterval())
+	defer ticker.Stop()
+	for {
+		nodeBalance, err := c.Balance(ctx, n, chain)
+		if err != nil {
+			return err
+		}
+		if nodeBalance == balance {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return &network.NodeError{
+				NodeName: n.GetName(),
+				Op:       "await balance on",
+				Err:      fmt.Errorf("%s-Chain balance is %d, expected %d: %w", chain, nodeBalance, balance, ctx.Err()),
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// Returns the owners of the funds moved, i.e. the client's key
+func (c *Client) owners() secp256k1fx.OutputOwners {
+	return secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{c.key.Address()},
+	}
+}
+
+func (c *Client) options(ctx context.Context) []common.Option {
+	return []common.Option{
+		common.WithContext(ctx),
+		common.WithPollFrequency(c.pollInterval()),
+	}
+}
+
+// Returns a wallet issuing txs via [issuer]
+func (c *Client) wallet(ctx context.Context, issuer node.Node) (primary.Wallet, error) {
+	kc := secp256k1fx.NewKeychain(c.key)
+	return primary.MakeWallet(ctx, &primary.WalletConfig{
+		URI:          issuer.GetURI(),
+		AVAXKeychain: kc,
+		EthKeychain:  kc,
+	})
+}
+
+// Returns the node txs are issued via
+func (c *Client) issuer() (node.Node, error) {
+	if c.NodeName != "" {
+		return c.net.GetNode(c.NodeName)
+	}
+	nodes, err := runningNodes(c.net)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, network.ErrNoRunningNodes
+	}
+	return nodes[0], nil
+}
+
+func (c *Client) pollInterval() time.Duration {
+	if c.PollInterval > 0 {
+		return c.PollInterval
+	}
+	return DefaultPollInterval
+}
+
+// Returns the ID of [chain] on the network of [w]
+func chainID(w primary.Wallet, chain Chain) (ids.ID, error) {
+	switch chain {
+	case XChain:
+		return w.X().Builder().Context().BlockchainID, nil
+	case PChain:
+		return avagoConstants.PlatformChainID, nil
+	case CChain:
+		return w.C().Builder().Context().BlockchainID, nil
+	default:
+		return ids.Empty, fmt.Errorf("unknown chain %q", chain)
+	}
+}
+
+// Returns the running nodes of [net], sorted by name
+func runningNodes(net network.Network) ([]node.Node, error) {
+	allNodes, err := net.GetAllNodes()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []node.Node{}
+	for _, n := range allNodes {
+		if !n.GetPaused() && n.Status() == status.Running {
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].GetName() < nodes[j].GetName()
+	})
+	return nodes, nil
+}
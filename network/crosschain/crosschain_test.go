@@ -0,0 +1,129 @@
+package crosschain
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/api"
+	apimocks "github.com/ava-labs/avalanche-network-runner/api/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/avalanchego/ids"
+	avajson "github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBalances reports the balances given, in order, then the last one,
+// on all the chains
+type fakeBalances struct {
+	lock     sync.Mutex
+	balances []uint64
+}
+
+func (f *fakeBalances) next() uint64 {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	balance := f.balances[0]
+	if len(f.balances) > 1 {
+		f.balances = f.balances[1:]
+	}
+	return balance
+}
+
+type fakeXChainClient struct {
+	avm.Client
+	*fakeBalances
+}
+
+func (c *fakeXChainClient) GetBalance(context.Context, ids.ShortID, string, bool, ...rpc.Option) (*avm.GetBalanceReply, error) {
+	return &avm.GetBalanceReply{Balance: avajson.Uint64(c.next())}, nil
+}
+
+type fakePChainClient struct {
+	platformvm.Client
+	*fakeBalances
+}
+
+func (c *fakePChainClient) GetBalance(context.Context, []ids.ShortID, ...rpc.Option) (*platformvm.GetBalanceResponse, error) {
+	return &platformvm.GetBalanceResponse{Unlocked: avajson.Uint64(c.next())}, nil
+}
+
+type fakeEthClient struct {
+	api.EthClient
+	*fakeBalances
+}
+
+func (c *fakeEthClient) BalanceAt(context.Context, common.Address, *big.Int) (*big.Int, error) {
+	// wei not adding up to a nAVAX are truncated
+	wei := new(big.Int).Mul(new(big.Int).SetUint64(c.next()), weiPerNAVAX)
+	return wei.Add(wei, big.NewInt(1)), nil
+}
+
+func newTestNetwork(t *testing.T, balances map[string][]uint64) network.Network {
+	net, err := networkfakes.NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	})
+	require.NoError(t, err)
+	for nodeName, nodeBalances := range balances {
+		n, err := net.GetNode(nodeName)
+		require.NoError(t, err)
+		fake := &fakeBalances{balances: nodeBalances}
+		client := &apimocks.Client{}
+		client.On("XChainAPI").Return(&fakeXChainClient{fakeBalances: fake})
+		client.On("PChainAPI").Return(&fakePChainClient{fakeBalances: fake})
+		client.On("CChainEthAPI").Return(&fakeEthClient{fakeBalances: fake})
+		n.(*networkfakes.Node).SetAPIClient(client)
+	}
+	return net
+}
+
+func TestBalances(t *testing.T) {
+	require := require.New(t)
+	for _, chain := range []Chain{XChain, PChain, CChain} {
+		net := newTestNetwork(t, map[string][]uint64{"node1": {10}, "node2": {20}})
+		balances, err := NewClient(net, genesis.EWOQKey).Balances(context.Background(), chain)
+		require.NoError(err)
+		require.Equal(map[string]uint64{"node1": 10, "node2": 20}, balances)
+	}
+}
+
+func TestAwaitBalances(t *testing.T) {
+	require := require.New(t)
+	net := newTestNetwork(t, map[string][]uint64{"node1": {10}, "node2": {5, 7, 10}})
+	c := NewClient(net, genesis.EWOQKey)
+	c.PollInterval = time.Millisecond
+	issuer, err := c.issuer()
+	require.NoError(err)
+	balance, err := c.awaitBalances(context.Background(), issuer, PChain)
+	require.NoError(err)
+	require.Equal(uint64(10), balance)
+
+	net = newTestNetwork(t, map[string][]uint64{"node1": {10}, "node2": {5}})
+	c = NewClient(net, genesis.EWOQKey)
+	c.PollInterval = time.Millisecond
+	issuer, err = c.issuer()
+	require.NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = c.awaitBalances(ctx, issuer, XChain)
+	require.ErrorIs(err, context.DeadlineExceeded)
+	var nodeErr *network.NodeError
+	require.ErrorAs(err, &nodeErr)
+	require.Equal("node2", nodeErr.NodeName)
+}
+
+func TestTransferToSameChain(t *testing.T) {
+	net := newTestNetwork(t, nil)
+	_, err := NewClient(net, genesis.EWOQKey).Transfer(context.Background(), CChain, CChain, 1)
+	require.Error(t, err)
+}
@@ -0,0 +1,176 @@
+// Package devtools exports ready-to-use configs of common dApp development
+// tools, i.e. the hardhat networks block, the foundry rpc_endpoints table and
+// the MetaMask network JSON, pointing at the C-Chain endpoints of the nodes
+// of a network, so that the tools don't have to be wired to it by hand.
+package devtools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	// Names of the files written by WriteConfigs
+	HardhatFileName  = "hardhat.networks.js"
+	FoundryFileName  = "foundry.toml"
+	MetaMaskFileName = "metamask.json"
+
+	// path of the C-Chain JSON-RPC API of a node, relative to its URI
+	cChainRPCPath = "/ext/bc/C/rpc"
+)
+
+var errNoCChainID = errors.New("couldn't find the C-Chain chain ID in the genesis")
+
+// Endpoint is the C-Chain JSON-RPC URL of a node
+type Endpoint struct {
+	// Node name
+	Name string
+	URL  string
+}
+
+// CChainRPCURL returns the C-Chain JSON-RPC URL of the node whose API
+// listens on [nodeURI]
+func CChainRPCURL(nodeURI string) string {
+	return strings.TrimSuffix(nodeURI, "/") + cChainRPCPath
+}
+
+// CChainEndpoints returns the Endpoints of the running nodes of [net],
+// sorted by node name
+func CChainEndpoints(net network.Network) ([]Endpoint, error) {
+	nodes, err := net.GetAllNodes()
+	if err != nil {
+		return nil, err
+	}
+	names := maps.Keys(nodes)
+	sort.Strings(names)
+	endpoints := []Endpoint{}
+	for _, name := range names {
+		if nodes[name].GetPaused() {
+			continue
+		}
+		endpoints = append(endpoints, Endpoint{Name: name, URL: CChainRPCURL(nodes[name].GetURI())})
+	}
+	return endpoints, nil
+}
+
+// CChainID returns the EVM chain ID of the C-Chain of [genesis], a network
+// genesis as given by network.Config
+func CChainID(genesis string) (uint64, error) {
+	var genesisMap struct {
+		CChainGenesis string `json:"cChainGenesis"`
+	}
+	if err := json.Unmarshal([]byte(genesis), &genesisMap); err != nil {
+		return 0, fmt.Errorf("couldn't unmarshal genesis: %w", err)
+	}
+	var cChainGenesis struct {
+		Config struct {
+			ChainID *uint64 `json:"chainId"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal([]byte(genesisMap.CChainGenesis), &cChainGenesis); err != nil {
+		return 0, fmt.Errorf("couldn't unmarshal C-Chain genesis: %w", err)
+	}
+	if cChainGenesis.Config.ChainID == nil {
+		return 0, errNoCChainID
+	}
+	return *cChainGenesis.Config.ChainID, nil
+}
+
+// Hardhat returns the networks block of a hardhat config, with a network
+// per endpoint, named as its node, on chain [chainID]. If [privateKeys],
+// given as hex, aren't empty, they are set as the accounts of the networks.
+func Hardhat(chainID uint64, endpoints []Endpoint, privateKeys []string) []byte {
+	var b strings.Builder
+	b.WriteString("networks: {\n")
+	for _, endpoint := range endpoints {
+		fmt.Fprintf(&b, "  %s: {\n", strconv.Quote(endpoint.Name))
+		fmt.Fprintf(&b, "    url: %s,\n", strconv.Quote(endpoint.URL))
+		fmt.Fprintf(&b, "    chainId: %d,\n", chainID)
+		if len(privateKeys) != 0 {
+			quoted := make([]string, len(privateKeys))
+			for i, privateKey := range privateKeys {
+				quoted[i] = strconv.Quote(privateKey)
+			}
+			fmt.Fprintf(&b, "    accounts: [%s],\n", strings.Join(quoted, ", "))
+		}
+		b.WriteString("  },\n")
+	}
+	b.WriteString("},\n")
+	return []byte(b.String())
+}
+
+// Foundry returns the rpc_endpoints table of a foundry.toml, with an alias
+// per endpoint, named as its node
+func Foundry(endpoints []Endpoint) []byte {
+	var b strings.Builder
+	b.WriteString("[rpc_endpoints]\n")
+	for _, endpoint := range endpoints {
+		fmt.Fprintf(&b, "%s = %s\n", strconv.Quote(endpoint.Name), strconv.Quote(endpoint.URL))
+	}
+	return []byte(b.String())
+}
+
+// MetaMask returns the JSON of a MetaMask network on chain [chainID] using
+// the URLs of [endpoints] as RPC URLs, as given to wallet_addEthereumChain
+func MetaMask(chainID uint64, endpoints []Endpoint) ([]byte, error) {
+	rpcURLs := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		rpcURLs[i] = endpoint.URL
+	}
+	network := map[string]interface{}{
+		"chainId":   "0x" + strconv.FormatUint(chainID, 16),
+		"chainName": "Avalanche Local C-Chain",
+		"nativeCurrency": map[string]interface{}{
+			"name":     "Avalanche",
+			"symbol":   "AVAX",
+			"decimals": 18,
+		},
+		"rpcUrls": rpcURLs,
+	}
+	return json.MarshalIndent(network, "", "  ")
+}
+
+// WriteConfigs writes to [dir] the Hardhat, Foundry and MetaMask configs,
+// with the names given by HardhatFileName, FoundryFileName and
+// MetaMaskFileName, pointing at the running nodes of [net]. [privateKeys]
+// are given to Hardhat.
+func WriteConfigs(dir string, net network.Network, privateKeys []string) error {
+	endpoints, err := CChainEndpoints(net)
+	if err != nil {
+		return err
+	}
+	networkConfig, err := net.ToConfig()
+	if err != nil {
+		return err
+	}
+	chainID, err := CChainID(networkConfig.Genesis)
+	if err != nil {
+		return err
+	}
+	metaMask, err := MetaMask(chainID, endpoints)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for fileName, contents := range map[string][]byte{
+		HardhatFileName:  Hardhat(chainID, endpoints, privateKeys),
+		FoundryFileName:  Foundry(endpoints),
+		MetaMaskFileName: metaMask,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, fileName), contents, 0o644); err != nil {
+			return fmt.Errorf("couldn't write %s: %w", fileName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package devtools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+const testGenesis = `{"networkID":1337,"cChainGenesis":"{\"config\":{\"chainId\":43112},\"alloc\":{}}"}`
+
+func TestCChainID(t *testing.T) {
+	require := require.New(t)
+	chainID, err := CChainID(testGenesis)
+	require.NoError(err)
+	require.Equal(uint64(43112), chainID)
+
+	_, err = CChainID(`{"cChainGenesis":"{\"config\":{}}"}`)
+	require.ErrorIs(err, errNoCChainID)
+	_, err = CChainID("not a genesis")
+	require.Error(err)
+}
+
+func TestConfigs(t *testing.T) {
+	require := require.New(t)
+	endpoints := []Endpoint{
+		{Name: "node1", URL: CChainRPCURL("http://127.0.0.1:9650")},
+		{Name: "node2", URL: CChainRPCURL("http://127.0.0.1:9652/")},
+	}
+	require.Equal("http://127.0.0.1:9652/ext/bc/C/rpc", endpoints[1].URL)
+
+	require.Equal(`networks: {
+  "node1": {
+    url: "http://127.0.0.1:9650/ext/bc/C/rpc",
+    chainId: 43112,
+  },
+  "node2": {
+    url: "http://127.0.0.1:9652/ext/bc/C/rpc",
+    chainId: 43112,
+  },
+},
+`, string(Hardhat(43112, endpoints, nil)))
+	require.Contains(string(Hardhat(43112, endpoints, []string{"0x56289e99"})), `    accounts: ["0x56289e99"],`)
+
+	require.Equal(`[rpc_endpoints]
+"node1" = "http://127.0.0.1:9650/ext/bc/C/rpc"
+"node2" = "http://127.0.0.1:9652/ext/bc/C/rpc"
+`, string(Foundry(endpoints)))
+
+	metaMaskJSON, err := MetaMask(43112, endpoints)
+	require.NoError(err)
+	var metaMask struct {
+		ChainID string   `json:"chainId"`
+		RPCURLs []string `json:"rpcUrls"`
+	}
+	require.NoError(json.Unmarshal(metaMaskJSON, &metaMask))
+	require.Equal("0xa868", metaMask.ChainID)
+	require.Equal([]string{endpoints[0].URL, endpoints[1].URL}, metaMask.RPCURLs)
+}
+
+func TestWriteConfigs(t *testing.T) {
+	require := require.New(t)
+	net, err := networkfakes.NewNetwork(network.Config{
+		Genesis:     testGenesis,
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	})
+	require.NoError(err)
+	require.NoError(net.PauseNode(context.Background(), "node2"))
+	endpoints, err := CChainEndpoints(net)
+	require.NoError(err)
+	require.Len(endpoints, 1)
+	node1, err := net.GetNode("node1")
+	require.NoError(err)
+	require.Equal(Endpoint{Name: "node1", URL: CChainRPCURL(node1.GetURI())}, endpoints[0])
+
+	dir := t.TempDir()
+	require.NoError(WriteConfigs(dir, net, nil))
+	foundry, err := os.ReadFile(filepath.Join(dir, FoundryFileName))
+	require.NoError(err)
+	require.Equal(Foundry(endpoints), foundry)
+	hardhat, err := os.ReadFile(filepath.Join(dir, HardhatFileName))
+	require.NoError(err)
+	require.Equal(Hardhat(43112, endpoints, nil), hardhat)
+	require.FileExists(filepath.Join(dir, MetaMaskFileName))
+}
@@ -0,0 +1,45 @@
+package network
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+)
+
+// DiskChaos are disk faults injected on a node, to test it under
+// disk pressure. See Network.SetNodeDiskChaos.
+type DiskChaos struct {
+	// Max rate the node process can read from the block device of its
+	// db dir, in bytes per second. Zero is unlimited.
+	ReadBytesPerSec uint64 `json:"readBytesPerSec"`
+	// Max rate the node process can write to the block device of its
+	// db dir, in bytes per second. Zero is unlimited.
+	WriteBytesPerSec uint64 `json:"writeBytesPerSec"`
+	// If true, the filesystem of the node's db dir is filled with a
+	// ballast file, leaving only FreeBytes free.
+	FillDisk  bool   `json:"fillDisk"`
+	FreeBytes uint64 `json:"freeBytes"`
+}
+
+// IsZero returns true if no fault is given
+func (c DiskChaos) IsZero() bool {
+	return c == DiskChaos{}
+}
+
+// Validate returns an error if the faults can't be injected on a node
+// with [nodeConfig]. The disk can only be filled if the node has a
+// DBDirSizeLimit, so that the host disk is not filled, and its I/O can
+// only be throttled otherwise, as its db dir is then a tmpfs, which has
+// no block device.
+func (c DiskChaos) Validate(nodeConfig node.Config) error {
+	if c.FreeBytes != 0 && !c.FillDisk {
+		return errors.New("free bytes given without filling the disk")
+	}
+	if c.FillDisk && nodeConfig.DBDirSizeLimit == 0 {
+		return errors.New("filling the disk requires a db dir size limit")
+	}
+	if (c.ReadBytesPerSec != 0 || c.WriteBytesPerSec != 0) && nodeConfig.DBDirSizeLimit != 0 {
+		return errors.New("throttling disk I/O is not supported with a db dir size limit")
+	}
+	return nil
+}
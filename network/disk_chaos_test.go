@@ -0,0 +1,46 @@
+package network_test
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskChaosValidate(t *testing.T) {
+	limited := node.Config{DBDirSizeLimit: 1024 * 1024}
+	tests := []struct {
+		name       string
+		chaos      network.DiskChaos
+		nodeConfig node.Config
+		wantErr    bool
+	}{
+		{name: "zero", chaos: network.DiskChaos{}},
+		{name: "throttle", chaos: network.DiskChaos{ReadBytesPerSec: 1, WriteBytesPerSec: 1}},
+		{name: "throttle with size limit", chaos: network.DiskChaos{WriteBytesPerSec: 1}, nodeConfig: limited, wantErr: true},
+		{name: "fill", chaos: network.DiskChaos{FillDisk: true, FreeBytes: 1}, nodeConfig: limited},
+		{name: "fill without size limit", chaos: network.DiskChaos{FillDisk: true}, wantErr: true},
+		{name: "free bytes without fill", chaos: network.DiskChaos{FreeBytes: 1}, nodeConfig: limited, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.chaos.Validate(tt.nodeConfig)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNodeConfigValidateDBDirSizeLimit(t *testing.T) {
+	require := require.New(t)
+	nodeConfig := node.Config{DBDirSizeLimit: -1}
+	require.Error(nodeConfig.Validate(0))
+	nodeConfig = node.Config{DBDirSizeLimit: 1024, DBSeedDir: "seed"}
+	require.Error(nodeConfig.Validate(0))
+	nodeConfig = node.Config{DBDirSizeLimit: 1024}
+	require.NoError(nodeConfig.Validate(0))
+}
@@ -0,0 +1,10 @@
+package network
+
+// Chain is a primary network chain, given by its alias, e.g. to Network.Fund
+type Chain string
+
+const (
+	XChain Chain = "X"
+	PChain Chain = "P"
+	CChain Chain = "C"
+)
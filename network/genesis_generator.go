@@ -0,0 +1,38 @@
+package network
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// GenesisSpec is what GenesisGenerator generates a genesis from
+type GenesisSpec struct {
+	NetworkID uint32
+	// Nodes validating the primary network from genesis
+	Validators []ids.NodeID
+	// Funded X-Chain and C-Chain addresses
+	XChainBalances []AddrAndBalance
+	CChainBalances []AddrAndBalance
+}
+
+// GenesisGenerator generates the genesis JSON of a custom network, so that
+// teams running avalanchego forks, e.g. with other chain sets or parameters,
+// can plug their own genesis format into the network config creation.
+// See AvalancheGoGenesisGenerator.
+type GenesisGenerator interface {
+	GenerateGenesis(spec GenesisSpec) ([]byte, error)
+}
+
+// GenesisGeneratorFunc is a function used as a GenesisGenerator
+type GenesisGeneratorFunc func(spec GenesisSpec) ([]byte, error)
+
+func (f GenesisGeneratorFunc) GenerateGenesis(spec GenesisSpec) ([]byte, error) {
+	return f(spec)
+}
+
+// AvalancheGoGenesisGenerator is the default GenesisGenerator,
+// generating avalanchego genesis with NewAvalancheGoGenesis
+type AvalancheGoGenesisGenerator struct{}
+
+func (AvalancheGoGenesisGenerator) GenerateGenesis(spec GenesisSpec) ([]byte, error) {
+	return NewAvalancheGoGenesis(spec.NetworkID, spec.XChainBalances, spec.CChainBalances, spec.Validators)
+}
@@ -0,0 +1,20 @@
+package network
+
+import "time"
+
+// OperationRecord is an entry of the audit log of a network, recording a
+// mutating operation run on it, see Network.History.
+type OperationRecord struct {
+	// When the operation started
+	Time time.Time `json:"time"`
+	// How long the operation took
+	Duration time.Duration `json:"duration"`
+	// Name of the method run, e.g. "AddNode"
+	Op string `json:"op"`
+	// Node the operation was run on, if any
+	NodeName string `json:"nodeName,omitempty"`
+	// Parameters of the operation, formatted for display
+	Params map[string]string `json:"params,omitempty"`
+	// Error returned by the operation, if it failed
+	Err string `json:"err,omitempty"`
+}
@@ -0,0 +1,137 @@
+package network
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultHostsDomain is the domain of the node hostnames, e.g.
+// node1.anr.local, if none is given. See WriteHostsFile.
+const DefaultHostsDomain = "anr.local"
+
+// Returns the marker lines delimiting the hosts file entries of [domain]
+func hostsMarkers(domain string) (string, string) {
+	return "# BEGIN avalanche-network-runner " + domain, "# END avalanche-network-runner " + domain
+}
+
+// NodeHostname returns the hostname of node [nodeName] in [domain], i.e.
+// <node name>.<domain>, with the characters not allowed in hostnames
+// replaced by '-'
+func NodeHostname(nodeName string, domain string) string {
+	label := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, nodeName)
+	return label + "." + domain
+}
+
+// HostsEntries returns the hosts file lines giving each node of [manifest]
+// its hostname in [domain], resolving to the IP of its API, with its URI as
+// a comment, as ports can't be given on hosts files
+func HostsEntries(manifest Manifest, domain string) ([]string, error) {
+	lines := make([]string, 0, len(manifest.Nodes))
+	for _, endpoint := range manifest.Nodes {
+		uri, err := url.Parse(endpoint.URI)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse URI of node %q: %w", endpoint.Name, err)
+		}
+		ip := uri.Hostname()
+		if ip == "0.0.0.0" {
+			// listening on all interfaces
+			ip = "127.0.0.1"
+		}
+		lines = append(lines, fmt.Sprintf("%s\t%s\t# %s", ip, NodeHostname(endpoint.Name, domain), endpoint.URI))
+	}
+	return lines, nil
+}
+
+// WriteHostsFile gives the nodes of [manifest] hostnames in [domain],
+// or in DefaultHostsDomain if empty, by writing their HostsEntries to the
+// hosts file [path], e.g. /etc/hosts, so that the config files of external
+// tools can use stable hostnames. The entries are written in a block
+// delimited by markers naming the domain, that replaces the one previously
+// written, leaving the rest of the file as is. The file is written in place,
+// as /etc/hosts may be a bind mount, e.g. on containers, that can't be
+// replaced, and is created if it doesn't exist.
+func WriteHostsFile(path string, domain string, manifest Manifest) error {
+	if domain == "" {
+		domain = DefaultHostsDomain
+	}
+	lines, err := HostsEntries(manifest, domain)
+	if err != nil {
+		return err
+	}
+	return replaceHostsBlock(path, domain, lines)
+}
+
+// RemoveHostsFileEntries removes the block of [domain], or of
+// DefaultHostsDomain if empty, written by WriteHostsFile to [path]
+func RemoveHostsFileEntries(path string, domain string) error {
+	if domain == "" {
+		domain = DefaultHostsDomain
+	}
+	return replaceHostsBlock(path, domain, nil)
+}
+
+// Replaces the block of [domain] on the hosts file [path] with [lines],
+// removing it if [lines] is empty
+func replaceHostsBlock(path string, domain string, lines []string) error {
+	begin, end := hostsMarkers(domain)
+	mode := fs.FileMode(0o644)
+	content, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if len(lines) == 0 {
+			return nil
+		}
+	case err != nil:
+		return err
+	default:
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		mode = info.Mode().Perm()
+	}
+
+	var out bytes.Buffer
+	inBlock := false
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		switch strings.TrimSpace(line) {
+		case begin:
+			inBlock = true
+			continue
+		case end:
+			inBlock = false
+			continue
+		}
+		if !inBlock {
+			out.WriteString(line)
+		}
+	}
+	if len(lines) > 0 {
+		if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+			out.WriteString("\n")
+		}
+		out.WriteString(begin + "\n")
+		for _, line := range lines {
+			out.WriteString(line + "\n")
+		}
+		out.WriteString(end + "\n")
+	}
+	if bytes.Equal(out.Bytes(), content) {
+		return nil
+	}
+	return os.WriteFile(path, out.Bytes(), mode)
+}
@@ -0,0 +1,75 @@
+package network_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeHostname(t *testing.T) {
+	require := require.New(t)
+	require.Equal("node1.anr.local", network.NodeHostname("node1", "anr.local"))
+	require.Equal("my-node-2.mynet.local", network.NodeHostname("My_Node.2", "mynet.local"))
+}
+
+func TestWriteHostsFile(t *testing.T) {
+	require := require.New(t)
+	hostsFile := filepath.Join(t.TempDir(), "hosts")
+	original := "127.0.0.1\tlocalhost\n::1\tlocalhost"
+	require.NoError(os.WriteFile(hostsFile, []byte(original), 0o600))
+
+	manifest := network.Manifest{Nodes: []network.NodeEndpoint{
+		{Name: "node1", URI: "http://127.0.0.1:9650"},
+		{Name: "node2", URI: "http://0.0.0.0:9652"},
+	}}
+	require.NoError(network.WriteHostsFile(hostsFile, "", manifest))
+	// other domains are kept
+	require.NoError(network.WriteHostsFile(hostsFile, "other.local", network.Manifest{Nodes: manifest.Nodes[:1]}))
+	// previous entries are replaced
+	manifest.Nodes[0].URI = "http://127.0.0.1:19650"
+	require.NoError(network.WriteHostsFile(hostsFile, "", manifest))
+
+	hosts, err := os.ReadFile(hostsFile)
+	require.NoError(err)
+	require.Equal(original+"\n"+
+		"# BEGIN avalanche-network-runner other.local\n"+
+		"127.0.0.1\tnode1.other.local\t# http://127.0.0.1:9650\n"+
+		"# END avalanche-network-runner other.local\n"+
+		"# BEGIN avalanche-network-runner anr.local\n"+
+		"127.0.0.1\tnode1.anr.local\t# http://127.0.0.1:19650\n"+
+		"127.0.0.1\tnode2.anr.local\t# http://0.0.0.0:9652\n"+
+		"# END avalanche-network-runner anr.local\n",
+		string(hosts))
+	info, err := os.Stat(hostsFile)
+	require.NoError(err)
+	require.Equal(os.FileMode(0o600), info.Mode().Perm())
+
+	require.NoError(network.RemoveHostsFileEntries(hostsFile, "other.local"))
+	require.NoError(network.RemoveHostsFileEntries(hostsFile, ""))
+	hosts, err = os.ReadFile(hostsFile)
+	require.NoError(err)
+	require.Equal(original+"\n", string(hosts))
+
+	// nothing to remove from a missing file
+	require.NoError(network.RemoveHostsFileEntries(filepath.Join(t.TempDir(), "hosts"), ""))
+}
+
+func TestNotifyReadyHostsFile(t *testing.T) {
+	require := require.New(t)
+	net, err := networkfakes.NewNetwork(network.Config{NodeConfigs: []node.Config{{Name: "node1"}}})
+	require.NoError(err)
+	hostsFile := filepath.Join(t.TempDir(), "hosts")
+	require.NoError(network.NotifyReady(context.Background(), net, network.ReadyOptions{
+		HostsFile:   hostsFile,
+		HostsDomain: "mynet.local",
+	}))
+	hosts, err := os.ReadFile(hostsFile)
+	require.NoError(err)
+	require.Contains(string(hosts), "node1.mynet.local")
+}
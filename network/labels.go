@@ -0,0 +1,22 @@
+package network
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Network label names must be valid Prometheus label names,
+// so that they can be added to the metrics of the nodes
+var labelNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateLabels returns an error if any of the names of [labels] is not a
+// valid Prometheus label name, or is reserved, i.e. starts with "__"
+func validateLabels(labels map[string]string) error {
+	for name := range labels {
+		if !labelNameRegexp.MatchString(name) || strings.HasPrefix(name, "__") {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Package monitoring generates the Prometheus config scraping the metrics of
+// the nodes of a network, and runs Prometheus and Grafana, provisioned with
+// prebuilt dashboards, with docker compose, so that a network can be
+// observed without setting them up by hand.
+package monitoring
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	// PrometheusConfigFileName is the name of the Prometheus config written
+	// by WriteStack
+	PrometheusConfigFileName = "prometheus.yaml"
+	composeFileName          = "docker-compose.yaml"
+	// PrometheusURL and GrafanaURL are where the stack started by Up listens
+	PrometheusURL = "http://localhost:9090"
+	GrafanaURL    = "http://localhost:3000"
+
+	prometheusConfigCommon = `global:
+  scrape_interval: 15s
+  evaluation_interval: 15s
+scrape_configs:
+  - job_name: prometheus
+    static_configs:
+      - targets:
+        - localhost:9090
+  - job_name: avalanchego-machine
+    static_configs:
+     - targets:
+       - localhost:9100
+       labels:
+         alias: machine
+  - job_name: avalanchego
+    metrics_path: /ext/metrics
+    static_configs:
+      - targets:
+`
+)
+
+// docker compose file, Grafana provisioning and dashboards
+//
+//go:embed stack
+var stack embed.FS
+
+// PrometheusConfig returns a Prometheus config scraping the metrics of the
+// nodes whose APIs listen on [targets], given as host:port, adding [labels]
+// to all their metrics
+func PrometheusConfig(targets []string, labels map[string]string) []byte {
+	config := prometheusConfigCommon
+	for _, target := range targets {
+		config += "        - " + target + "\n"
+	}
+	if len(labels) != 0 {
+		config += "        labels:\n"
+		names := maps.Keys(labels)
+		sort.Strings(names)
+		for _, name := range names {
+			config += "          " + name + ": " + strconv.Quote(labels[name]) + "\n"
+		}
+	}
+	return []byte(config)
+}
+
+// NetworkTargets returns the host:port the APIs of the running nodes of
+// [net] listen on, sorted by node name
+func NetworkTargets(net network.Network) ([]string, error) {
+	nodes, err := net.GetAllNodes()
+	if err != nil {
+		return nil, err
+	}
+	names := maps.Keys(nodes)
+	sort.Strings(names)
+	targets := []string{}
+	for _, name := range names {
+		if nodes[name].GetPaused() {
+			continue
+		}
+		targets = append(targets, strings.TrimPrefix(nodes[name].GetURI(), "http://"))
+	}
+	return targets, nil
+}
+
+// NetworkPrometheusConfig returns the PrometheusConfig scraping the running
+// nodes of [net], adding the network labels to their metrics
+func NetworkPrometheusConfig(net network.Network) ([]byte, error) {
+	targets, err := NetworkTargets(net)
+	if err != nil {
+		return nil, err
+	}
+	networkConfig, err := net.ToConfig()
+	if err != nil {
+		return nil, err
+	}
+	return PrometheusConfig(targets, networkConfig.Labels), nil
+}
+
+// WriteStack writes to [dir] a docker compose file running Prometheus, with
+// [prometheusConfig], and Grafana, provisioned with Prometheus as datasource
+// and with the prebuilt dashboards. Can be called again while the stack
+// runs, e.g. after nodes are added, followed by Up to reload the config.
+func WriteStack(dir string, prometheusConfig []byte) error {
+	err := fs.WalkDir(stack, "stack", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		contents, err := stack.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return writeFile(filepath.Join(dir, strings.TrimPrefix(path, "stack/")), contents)
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't write monitoring stack: %w", err)
+	}
+	return writeFile(filepath.Join(dir, PrometheusConfigFileName), prometheusConfig)
+}
+
+// Up starts the stack written to [dir] by WriteStack, in the background,
+// with docker compose, or makes Prometheus reload its config if already
+// started. Prometheus listens on PrometheusURL, and Grafana, with anonymous
+// access, on GrafanaURL.
+func Up(ctx context.Context, dir string) error {
+	if err := compose(ctx, dir, "up", "--detach"); err != nil {
+		return err
+	}
+	return compose(ctx, dir, "kill", "--signal", "SIGHUP", "prometheus")
+}
+
+// Down stops the stack started by Up, removing its containers
+func Down(ctx context.Context, dir string) error {
+	return compose(ctx, dir, "down")
+}
+
+// Runs docker compose [command] with [flags] on the stack written to [dir]
+func compose(ctx context.Context, dir string, command string, flags ...string) error {
+	composeFile := filepath.Join(dir, composeFileName)
+	args := append([]string{"compose", "--file", composeFile, "--project-directory", dir, command}, flags...)
+	if out, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("docker compose %s failed: %w: %s", command, err, out)
+	}
+	return nil
+}
+
+func writeFile(path string, contents []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, contents, 0o644)
+}
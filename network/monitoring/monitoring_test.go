@@ -0,0 +1,63 @@
+package monitoring
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusConfig(t *testing.T) {
+	require := require.New(t)
+	config := string(PrometheusConfig([]string{"127.0.0.1:9650", "127.0.0.1:9652"}, nil))
+	require.True(strings.HasPrefix(config, prometheusConfigCommon))
+	require.True(strings.HasSuffix(config, "        - 127.0.0.1:9650\n        - 127.0.0.1:9652\n"))
+	require.NotContains(config, "        labels:")
+
+	config = string(PrometheusConfig([]string{"127.0.0.1:9650"}, map[string]string{"job": "ci", "branch": "main"}))
+	require.True(strings.HasSuffix(config, "        - 127.0.0.1:9650\n        labels:\n          branch: \"main\"\n          job: \"ci\"\n"))
+}
+
+func TestNetworkPrometheusConfig(t *testing.T) {
+	require := require.New(t)
+	net, err := networkfakes.NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}, {Name: "node3"}},
+		Labels:      map[string]string{"job": "ci"},
+	})
+	require.NoError(err)
+	require.NoError(net.PauseNode(context.Background(), "node2"))
+	targets, err := NetworkTargets(net)
+	require.NoError(err)
+	require.Len(targets, 2)
+	node1, err := net.GetNode("node1")
+	require.NoError(err)
+	require.Equal(strings.TrimPrefix(node1.GetURI(), "http://"), targets[0])
+
+	config, err := NetworkPrometheusConfig(net)
+	require.NoError(err)
+	require.Equal(PrometheusConfig(targets, map[string]string{"job": "ci"}), config)
+}
+
+func TestWriteStack(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	prometheusConfig := PrometheusConfig([]string{"127.0.0.1:9650"}, nil)
+	require.NoError(WriteStack(dir, prometheusConfig))
+	for _, path := range []string{
+		composeFileName,
+		"grafana/provisioning/datasources/prometheus.yaml",
+		"grafana/provisioning/dashboards/dashboards.yaml",
+		"grafana/dashboards/avalanchego.json",
+	} {
+		require.FileExists(filepath.Join(dir, path))
+	}
+	written, err := os.ReadFile(filepath.Join(dir, PrometheusConfigFileName))
+	require.NoError(err)
+	require.Equal(prometheusConfig, written)
+}
@@ -3,18 +3,73 @@ package network
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/network/node"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
 )
 
 var (
-	ErrUndefined    = errors.New("undefined network")
-	ErrStopped      = errors.New("network stopped")
-	ErrNodeNotFound = errors.New("node not found in network")
+	ErrUndefined          = errors.New("undefined network")
+	ErrStopped            = errors.New("network stopped")
+	ErrNodeNotFound       = errors.New("node not found in network")
+	ErrNodesExited        = errors.New("all network nodes exited")
+	ErrNoBeacons          = errors.New("beacon nodes not given")
+	ErrStartDelay         = errors.New("node start delay is only supported on network creation")
+	ErrNoRunningNodes     = errors.New("no running nodes in network")
+	ErrQuorumLoss         = errors.New("not enough stake would be left connected for consensus")
+	ErrNodeUnhealthy      = errors.New("node is unhealthy")
+	ErrNotEnoughPeers     = errors.New("node has less peers than required")
+	ErrStaticValidators   = errors.New("validator set is static")
+	ErrBeaconNotConnected = errors.New("node not connected to any of its beacons")
+	ErrObserverNode       = errors.New("observer nodes can't be validators")
+	ErrStartupTimeout     = errors.New("node API not responding within its startup timeout")
+
+	// DefaultMaxStoppedStake is the largest fraction of the primary network
+	// stake that can be stopped while the running validators still reach
+	// avalanchego's default consensus threshold.
+	DefaultMaxStoppedStake = 1 - float64(snowball.DefaultParameters.AlphaConfidence)/float64(snowball.DefaultParameters.K)
 )
 
+// DefaultHealthWatchInterval is the time between checks of WatchHealth,
+// unless given by HealthWatchConfig.Interval.
+const DefaultHealthWatchInterval = 5 * time.Second
+
+// NodeError is returned when an operation fails on a given node, so that
+// callers can tell which node failed with errors.As.
+type NodeError struct {
+	NodeName string
+	// Operation that failed, e.g. "remove" or "check health of"
+	Op string
+	// Underlying error, e.g. ErrNodeNotFound or an API error
+	Err error
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("%s node %q: %v", e.Op, e.NodeName, e.Err)
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+// RemoveNodeOptions change how a node is removed by RemoveNodeWithOptions
+type RemoveNodeOptions struct {
+	// Remove the node even if it is a primary network validator, and the
+	// running validators left don't have enough stake for consensus, or more
+	// stake than allowed by the quorum guard would be stopped.
+	// Otherwise, ErrQuorumLoss is returned, and the node is kept.
+	Force bool
+	// Wait for the API calls the network is making to the node to finish,
+	// and don't start new ones, before stopping it. If they don't finish
+	// before the context is done, the node isn't removed, and the context
+	// error is returned.
+	DrainFirst bool
+}
+
 type PermissionlessStakerSpec struct {
 	SubnetID      string
 	AssetID       string
@@ -63,24 +118,138 @@ type BlockchainSpec struct {
 	PerNodeChainConfig map[string][]byte
 }
 
-// Network is an abstraction of an Avalanche network
+// BlockchainInfo describes a blockchain created by CreateBlockchains
+type BlockchainInfo struct {
+	ID   ids.ID
+	VMID ids.ID
+	// Aliases registered for the blockchain on creation,
+	// given by BlockchainSpec.BlockchainAlias
+	Aliases []string
+}
+
+// Blockchain is a blockchain of the network, as given by Network.Blockchains
+type Blockchain struct {
+	ID       ids.ID
+	Name     string
+	SubnetID ids.ID
+	VMID     ids.ID
+	// Name of each running node --> whether it has bootstrapped the
+	// blockchain. False if the node doesn't know the blockchain yet,
+	// or doesn't track its subnet.
+	NodesBootstrapped map[string]bool
+}
+
+// Bootstrapped returns true if all the running nodes bootstrapped [b]
+func (b Blockchain) Bootstrapped() bool {
+	for _, bootstrapped := range b.NodesBootstrapped {
+		if !bootstrapped {
+			return false
+		}
+	}
+	return true
+}
+
+// NodeHooks are called on node lifecycle events. Any of them may be nil.
+// Hooks are called while the network is being modified, so they must
+// not call back into the network.
+type NodeHooks struct {
+	// Called before a node process is started, with the node's final config.
+	// If it returns an error, the node is not started.
+	OnBeforeNodeStart func(node.Config) error
+	// Called the first time a node process is seen healthy, once per process,
+	// including the ones started on resume or restart. Each node process is
+	// polled for health in the background, so the hook doesn't depend on Healthy
+	// being called, and it may be called concurrently for different nodes.
+	OnAfterNodeHealthy func(node.Node)
+	// Called after a node process is stopped, either because the node was
+	// removed, paused, restarted, or the network stopped.
+	OnNodeStop func(node.Node)
+	// Called when a node process exits without being stopped by the network,
+	// with its crash report. Unlike the other hooks, it is called from a
+	// background goroutine while the network is not being modified, so it may
+	// call back into the network, e.g. to restart the node.
+	OnNodeCrash func(node.Node, node.CrashReport)
+}
+
+// HealthAlarm is given to HealthWatchConfig.OnAlarm when a node degrades
+// or recovers.
+type HealthAlarm struct {
+	NodeName string
+	// Why the node is degraded, e.g. ErrNodeUnhealthy or ErrNotEnoughPeers.
+	// Nil if the node recovered.
+	Err error
+	// When the node was first seen degraded
+	Since time.Time
+	// How the node process crashed, if it did and the crash was already
+	// captured. See node.Node.CrashReport.
+	Crash *node.CrashReport
+}
+
+// HealthWatchConfig configures WatchHealth.
+type HealthWatchConfig struct {
+	// Time between checks. Defaults to DefaultHealthWatchInterval.
+	Interval time.Duration
+	// How long a node must stay degraded before OnAlarm is called, so that
+	// short hiccups, e.g. a node being restarted, are ignored.
+	// Zero calls it on the first failed check.
+	Debounce time.Duration
+	// Minimum number of peers each node must be connected to.
+	// Zero disables the check.
+	MinPeers int
+	// Called when a node has been unhealthy, exited, or connected to less
+	// than MinPeers peers, for longer than Debounce, and again once it
+	// recovers. Calls are made one at a time, without holding any network
+	// lock, so it may call back into the network.
+	OnAlarm func(HealthAlarm)
+}
+
+// Network is an abstraction of an Avalanche network.
+// Its methods are safe to call from multiple goroutines.
+// Errors on a given node are returned as a *NodeError.
 type Network interface {
 	// Returns the network ID for the currently running network
 	// Returns ErrStopped if Stop() was previously called.
 	GetNetworkID() (uint32, error)
 	// Returns nil if all the nodes in the network are healthy.
+	// Nodes waiting for their start delay are not considered.
+	// Other operations may run while waiting for the nodes. Only the nodes
+	// present when it is called are checked, and the ones removed, paused or
+	// restarted in the meantime are not waited for.
 	// A stopped network is considered unhealthy.
+	// Fails fast with ErrBeaconNotConnected if a node that was never healthy
+	// isn't connected to any of the beacons it bootstraps from a while after
+	// being started, which means its bootstrap IPs or IDs are wrong.
 	// Timeout is given by the context parameter.
 	Healthy(context.Context) error
+	// Returns nil once the node with this name is healthy, e.g. after adding
+	// or restarting it, without waiting for the rest of the network.
+	// Returns an error if the node is paused, or stops while waiting.
+	// Timeout is given by the context parameter.
+	// Returns ErrNodeNotFound if there is no node with this name.
+	// Returns ErrStopped if Stop() was previously called.
+	AwaitNodeHealthy(ctx context.Context, name string) error
 	// Stop all the nodes.
 	// Returns ErrStopped if Stop() was previously called.
 	Stop(context.Context) error
-	// Start a new node with the given config.
+	// Start a new node with the given config, and return it with the ports
+	// and dirs assigned to it. Its Await waits for it to be healthy.
+	// Returns ErrStartDelay if the config has a start delay.
 	// Returns ErrStopped if Stop() was previously called.
-	AddNode(node.Config) (node.Node, error)
+	AddNode(node.Config) (*AddedNode, error)
+	// Start a new node with the config of the node [templateName], changed by
+	// [overrides], and a new identity. See NewNodeConfigFromTemplate.
+	// Returns ErrNodeNotFound if there is no node with this name.
+	// Returns ErrStopped if Stop() was previously called.
+	AddNodeFromTemplate(templateName string, overrides NodeOverrides) (*AddedNode, error)
 	// Stop the node with this name.
+	// Same as RemoveNodeWithOptions with Force set, unless the quorum guard is set.
 	// Returns ErrStopped if Stop() was previously called.
 	RemoveNode(ctx context.Context, name string) error
+	// Stop the node with this name, checking first that the running validators
+	// left have enough stake for consensus, or that the quorum guard allows it
+	// if set, unless Force is set.
+	// Returns ErrStopped if Stop() was previously called.
+	RemoveNodeWithOptions(ctx context.Context, name string, opts RemoveNodeOptions) error
 	// Pause the node with this name.
 	// Returns ErrStopped if Stop() was previously called.
 	PauseNode(ctx context.Context, name string) error
@@ -92,11 +261,17 @@ type Network interface {
 	GetNode(name string) (node.Node, error)
 	// Return all the nodes in this network.
 	// Node name --> Node.
+	// The map is a copy, so it can be iterated while nodes are added or
+	// removed concurrently, without looking nodes up again by name.
 	// Returns ErrStopped if Stop() was previously called.
 	GetAllNodes() (map[string]node.Node, error)
-	// Returns the names of all nodes in this network.
+	// Returns the names of all nodes in this network, sorted.
 	// Returns ErrStopped if Stop() was previously called.
 	GetNodeNames() ([]string, error)
+	// Returns the names of the nodes in this network selected by [filter], sorted.
+	// Timeout of the health checks is given by the context parameter.
+	// Returns ErrStopped if Stop() was previously called.
+	GetNodeNamesWithFilter(ctx context.Context, filter NodeFilter) ([]string, error)
 	// Save network snapshot
 	// Network is stopped in order to do a safe preservation
 	// Returns the full local path to the snapshot dir
@@ -110,19 +285,195 @@ type Network interface {
 	// a map of subnet configs
 	RestartNode(context.Context, string, string, string, string, map[string]string, map[string]string, map[string]string) error
 	// Create the specified blockchains
-	CreateBlockchains(context.Context, []BlockchainSpec) ([]ids.ID, error)
+	// Returns ErrStaticValidators if the network has static validators.
+	CreateBlockchains(context.Context, []BlockchainSpec) ([]BlockchainInfo, error)
 	// Create the given numbers of subnets
+	// Returns ErrStaticValidators if the network has static validators.
 	CreateSubnets(context.Context, []SubnetSpec) ([]ids.ID, error)
 	// Transform subnet into elastic subnet
+	// Returns ErrStaticValidators if the network has static validators.
 	TransformSubnet(context.Context, []ElasticSubnetSpec) ([]ids.ID, []ids.ID, error)
 	// Delegate stake into a permissionless validator in an elastic subnet
+	// Returns ErrStaticValidators if the network has static validators.
 	AddPermissionlessDelegators(context.Context, []PermissionlessStakerSpec) error
 	// Add a validator into an elastic subnet
+	// Returns ErrStaticValidators if the network has static validators.
 	AddPermissionlessValidators(context.Context, []PermissionlessStakerSpec) error
 	// Remove a validator from a subnet
+	// Returns ErrStaticValidators if the network has static validators.
 	RemoveSubnetValidators(context.Context, []SubnetValidatorsSpec) error
 	// Add a validator toa subnet
+	// Returns ErrStaticValidators if the network has static validators.
 	AddSubnetValidators(context.Context, []SubnetValidatorsSpec) error
+	// Register an alias for the given blockchain on all the running nodes.
+	// The alias is saved on snapshots, and registered again on nodes that are
+	// started afterwards, once they are healthy.
+	// Returns ErrStopped if Stop() was previously called.
+	AliasBlockchain(context.Context, ids.ID, string) error
+	// Return the aliases registered for the given blockchain, either by
+	// AliasBlockchain or by BlockchainSpec.BlockchainAlias on creation.
+	// Returns ErrStopped if Stop() was previously called.
+	GetBlockchainAliases(ids.ID) ([]string, error)
+	// Register an alias for the given VM on all the nodes.
+	// VM aliases can only be set on node startup, so the running nodes are restarted,
+	// and the caller should wait for the network to be healthy again.
+	// The alias is saved on snapshots.
+	// Returns ErrStopped if Stop() was previously called.
+	AliasVM(ctx context.Context, vmID ids.ID, vmAlias string) error
+	// Return the aliases registered for the given VM by AliasVM.
+	// Returns ErrStopped if Stop() was previously called.
+	GetVMAliases(ids.ID) ([]string, error)
+	// Wait until the given metric satisfies the predicate on all the running nodes.
+	// Metric values are added up over all their label combinations, or only
+	// over the ones with the given label values, as in `name{chain="C"}`.
+	// Only counter, gauge and untyped metrics are supported.
+	// Returns ErrNoRunningNodes if all the nodes are paused or there are none.
+	// Timeout is given by the context parameter.
+	// Returns ErrStopped if Stop() was previously called.
+	AwaitMetric(ctx context.Context, metricName string, predicate func(float64) bool) error
+	// Return the value of the given metric on each running node, by node
+	// name, read as with AwaitMetric. Fails if it can't be read on any of them.
+	// Returns ErrNoRunningNodes if all the nodes are paused or there are none.
+	// Returns ErrStopped if Stop() was previously called.
+	GetMetric(ctx context.Context, metricName string) (map[string]float64, error)
+	// Return the versions reported by each running node, by node name, as
+	// with node.Node's GetVersion. Fails if they can't be read on any of them.
+	// Returns ErrNoRunningNodes if all the nodes are paused or there are none.
+	// Timeout is given by the context parameter.
+	// Returns ErrStopped if Stop() was previously called.
+	Versions(ctx context.Context) (map[string]node.Version, error)
+	// Return the blockchains of the network, as given by the P-chain of a
+	// running node, i.e. all but the P-chain, in the order given by it, with
+	// whether each running node bootstrapped them. Fails if the bootstrap
+	// status can't be read on any of the nodes.
+	// Returns ErrNoRunningNodes if all the nodes are paused or there are none.
+	// Timeout is given by the context parameter.
+	// Returns ErrStopped if Stop() was previously called.
+	Blockchains(ctx context.Context) ([]Blockchain, error)
+	// Set the log level of each running node, as with node.Node's SetLogLevel.
+	// Fails if it can't be set on any of them.
+	// Returns ErrNoRunningNodes if all the nodes are paused or there are none.
+	// Timeout is given by the context parameter.
+	// Returns ErrStopped if Stop() was previously called.
+	SetLogLevel(ctx context.Context, level string) error
+	// Return a config describing the network as it is now, including the
+	// nodes added, removed, restarted or reconciled since it was created, and
+	// the nodes not started yet, so that it can be created again from scratch,
+	// e.g. from a file written by SaveConfig. Node ports and dirs are only
+	// kept if given on the node configs, and beacons only if they are not
+	// nodes of the network. Dependencies on removed nodes are dropped.
+	// Returns ErrStopped if Stop() was previously called.
+	ToConfig() (Config, error)
+	// Block until the network stops, either because Stop() or SaveSnapshot()
+	// was called, in which case it returns nil, or because all the nodes that
+	// were not paused exited, in which case it returns ErrNodesExited.
+	// Timeout is given by the context parameter.
+	Wait(ctx context.Context) error
+	// Keep checking the running nodes, calling [config.OnAlarm] when any of
+	// them degrades or recovers, until [ctx] is done or the network stops,
+	// returning nil then. Meant to be called once the network is healthy,
+	// to watch it during long-running tests. Paused and removed nodes are
+	// not watched.
+	// Returns ErrStopped if Stop() was previously called.
+	WatchHealth(ctx context.Context, config HealthWatchConfig) error
+	// Create a keystore user on the given nodes, or on all nodes if [nodeNames]
+	// is empty, and import [privateKeys] to it. See node.Node.CreateKeystoreUser.
+	// Returns ErrStopped if Stop() was previously called.
+	CreateKeystoreUser(ctx context.Context, nodeNames []string, user string, pass string, privateKeys ...*secp256k1.PrivateKey) error
+	// Send [amount] nAVAX on [chain] to [addr], from the key funded on the
+	// network genesis that issues the network txs, and wait for the tx to be
+	// accepted, so that tests don't have to manage keys to fund addresses.
+	// [addr] is given as "X-<bech32>" or "P-<bech32>" on the X-Chain and
+	// P-Chain, and as hex on the C-Chain. Returns the ID of the tx, i.e. its
+	// hash on the C-Chain.
+	// Returns ErrStopped if Stop() was previously called.
+	Fund(ctx context.Context, addr string, amount uint64, chain Chain) (ids.ID, error)
+	// Register hooks to be called on node lifecycle events.
+	// Hooks registered multiple times are called in registration order.
+	RegisterNodeHooks(NodeHooks)
+	// Set the quorum guard, so that RemoveNode, PauseNode, RestartNode and
+	// Reconcile return ErrQuorumLoss, without stopping any node, if they would
+	// leave more than [maxStoppedStake] of the primary network stake stopped
+	// at once. Reconcile checks the nodes it removes together with each node
+	// it restarts, as they are restarted one at a time.
+	// See DefaultMaxStoppedStake. Zero, the default, disables the guard, which
+	// is how the guarded operations are forced.
+	SetQuorumGuard(maxStoppedStake float64) error
+	// Return how much of the primary network stake is online, i.e. on the
+	// validators that are running and healthy nodes of the network, as of
+	// the current validators reported by one of the healthy nodes. Nodes in
+	// maintenance are checked as the others. See QuorumStatus.
+	// Returns ErrNoRunningNodes if no node is running.
+	// Returns ErrStopped if Stop() was previously called.
+	QuorumStatus(context.Context) (QuorumStatus, error)
+	// Make the network match the given config, by removing the nodes not
+	// present in it, adding the new ones, and restarting the nodes whose
+	// config changed. Nodes are matched by name.
+	// Nil Flags and config file maps, and an empty BinaryPath, keep the
+	// current network defaults. NetworkID, Genesis, Upgrade, BeaconConfig and
+	// Labels can't be changed on a running network: if given, they must
+	// match it.
+	// Node start delays are not supported, and a network with nodes still
+	// waiting for their start delay can't be reconciled.
+	// If an error occurs mid-way, the network defaults are kept, and the nodes
+	// already changed are kept on their new config.
+	// Returns ErrStopped if Stop() was previously called.
+	Reconcile(context.Context, Config) error
+	// Inject [chaos] disk faults on the node [nodeName], replacing the ones
+	// previously set. The zero DiskChaos removes them. I/O throttling lasts
+	// until the node process is stopped, and the disk fill until it's removed,
+	// or the node's db dir is unmounted. See DiskChaos.Validate.
+	// On local networks, Linux only, and requires root.
+	// Returns ErrStopped if Stop() was previously called.
+	SetNodeDiskChaos(ctx context.Context, nodeName string, chaos DiskChaos) error
+	// Limit the bandwidth of the link between the nodes [nodeName1] and
+	// [nodeName2] to [bitsPerSec] in each direction, e.g. 1_000_000 for
+	// 1 Mbps, replacing the limit previously set on it, to emulate WAN links
+	// between some of the nodes. Zero removes the limit. Limits are kept
+	// while the nodes are paused or restarted, until either node is removed.
+	// On local networks, Linux only, requires root and tc, and applies to
+	// the IPv4 connections between the node processes, which are shaped on
+	// the loopback interface within a second of being established. Fails if
+	// the loopback interface has a root qdisc other than the HTB one with
+	// handle 1: the links are shaped by, which is added if there is none,
+	// and removed once the networks of the process that added it stop.
+	// Returns ErrStopped if Stop() was previously called.
+	SetLinkBandwidth(ctx context.Context, nodeName1 string, nodeName2 string, bitsPerSec uint64) error
+	// Mark the node [nodeName] as in maintenance, or not, e.g. while it is
+	// intentionally broken. Nodes in maintenance are skipped by Healthy, the
+	// operations waiting for the network to be healthy, and WatchHealth,
+	// which log the skipped nodes, so they don't fail the readiness of the
+	// network. The mark is kept until unset or the node is removed.
+	// Returns ErrNodeNotFound if there is no node with this name.
+	// Returns ErrStopped if Stop() was previously called.
+	SetNodeMaintenance(nodeName string, inMaintenance bool) error
+	// Return the names of the nodes in maintenance, sorted, i.e. the ones
+	// skipped by the health checks. See SetNodeMaintenance.
+	// Returns ErrStopped if Stop() was previously called.
+	NodesInMaintenance() ([]string, error)
+	// Restart the nodes given by [opts] in batches, waiting for the network
+	// to be healthy after each batch, e.g. to upgrade their binary, as in a
+	// production upgrade drill. Each batch is checked by the quorum guard,
+	// with opts.MaxStoppedStake if given, before any of its nodes is stopped.
+	// Stops on the first error, e.g. ErrQuorumLoss, or the network not being
+	// healthy, keeping the nodes already restarted on their new config.
+	// Paused nodes can't be restarted. See RollingRestartOptions.
+	// Returns ErrStopped if Stop() was previously called.
+	RollingRestart(ctx context.Context, opts RollingRestartOptions) error
+	// Return the audit log of the mutating operations run on the network,
+	// e.g. adding, removing, pausing and restarting nodes, in the order they
+	// finished, including the failed ones, but not the ones rejected because
+	// the network was stopped. Operations run by chaos scenarios are recorded
+	// as the network operations they run. On local networks, the records are
+	// also appended as JSON lines to the history.jsonl file of the root dir,
+	// so that they are kept with the nodes logs.
+	// Can be called after Stop().
+	History() []OperationRecord
+	// Return how long each phase of the start of the network and of its
+	// nodes took, e.g. cert generation, process start and bootstrapping,
+	// so that startup time can be optimized. See StartupReport.
+	// Returns ErrStopped if Stop() was previously called.
+	StartupReport() (StartupReport, error)
 	// Get the elastic subnet tx id for the given subnet id
 	GetElasticSubnetID(context.Context, ids.ID) (ids.ID, error)
 	// Get the root dir of the Network
@@ -0,0 +1,2066 @@
+// Package networkfakes provides an in-memory implementation of network.Network,
+// with no processes or sockets behind it, so that code orchestrating networks
+// can be unit tested. Node health, metrics, and method failures are scripted
+// by the test.
+package networkfakes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanche-network-runner/utils/constants"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"golang.org/x/exp/maps"
+)
+
+const defaultNodeNamePrefix = "node"
+
+var (
+	_ network.Network = (*Network)(nil)
+
+	errNodeStopped    = errors.New("node stopped unexpectedly")
+	errNodePaused     = errors.New("node is paused")
+	errNodeNotRunning = errors.New("node process is not running")
+	errSameNodeLink   = errors.New("a link needs two distinct nodes")
+)
+
+// Network is an in-memory network.Network.
+// Nodes are healthy when added, unless changed with SetNodeHealthy.
+type Network struct {
+	lock      sync.RWMutex
+	networkID uint32
+	stopped   bool
+	// Node Name --> Node
+	nodes          map[string]*Node
+	nextNodeSuffix uint64
+	nextPort       uint16
+	// method name --> error returned by it
+	failures map[string]error
+	// metric name --> value, same for all nodes
+	metrics map[string]float64
+	// closed and replaced each time node health or metrics change
+	changedCh         chan struct{}
+	snapshots         map[string]network.Config
+	blockchainAliases map[ids.ID][]string
+	vmAliases         map[ids.ID][]string
+	elasticSubnetIDs  map[ids.ID]ids.ID
+	nodeHooks         []network.NodeHooks
+	// created by CreateBlockchains, in order, without their bootstrap status
+	blockchains []network.Blockchain
+	// blockchain ID --> names of the nodes that didn't bootstrap it,
+	// see SetNodeBootstrapped
+	notBootstrapped map[ids.ID]map[string]bool
+	// 0 if the quorum guard is disabled
+	maxStoppedStake float64
+	// Node Name --> disk faults set with SetNodeDiskChaos
+	diskChaos map[string]network.DiskChaos
+	// names of the nodes of a link, sorted --> bandwidth limit set with
+	// SetLinkBandwidth, in bits per second
+	linkBandwidths map[[2]string]uint64
+	// if true, the staking operations return network.ErrStaticValidators
+	staticValidators bool
+	// if set, nodes are only added if hostResources can support them
+	resourceGuard *network.ResourceGuard
+	// set by SetHostResources, unlimited if nil
+	hostResources *network.HostResources
+	// labels of the network config, kept for ToConfig
+	labels map[string]string
+	// genesis of the network config, kept for ToConfig
+	genesis string
+	// names of the nodes skipped by the health checks, see SetNodeMaintenance
+	maintenanceNodes map[string]bool
+	// chain --> address --> nAVAX sent to it by Fund
+	funded map[network.Chain]map[string]uint64
+	// audit log of the mutating operations. Has its own lock,
+	// as some of them only hold [lock] for reading.
+	historyLock sync.Mutex
+	history     []network.OperationRecord
+	// time at which the network was created
+	startTime time.Time
+}
+
+// NewNetwork returns a fake network with the nodes given in [networkConfig].
+// The config's beacon policy is applied, but the config is not validated.
+// Nodes are added in start order, all at once: start delays are ignored,
+// and nodes don't wait for their dependencies to be healthy.
+func NewNetwork(networkConfig network.Config) (*Network, error) {
+	if err := networkConfig.ApplyBeaconPolicy(); err != nil {
+		return nil, err
+	}
+	networkID := networkConfig.NetworkID
+	if networkID == 0 {
+		networkID = constants.DefaultNetworkID
+	}
+	n := &Network{
+		networkID:         networkID,
+		nodes:             map[string]*Node{},
+		nextNodeSuffix:    1,
+		nextPort:          constants.FirstAPIPort,
+		failures:          map[string]error{},
+		metrics:           map[string]float64{},
+		changedCh:         make(chan struct{}),
+		snapshots:         map[string]network.Config{},
+		blockchainAliases: map[ids.ID][]string{},
+		vmAliases:         map[ids.ID][]string{},
+		elasticSubnetIDs:  map[ids.ID]ids.ID{},
+		notBootstrapped:   map[ids.ID]map[string]bool{},
+		diskChaos:         map[string]network.DiskChaos{},
+		linkBandwidths:    map[[2]string]uint64{},
+		staticValidators:  networkConfig.StaticValidators,
+		resourceGuard:     networkConfig.ResourceGuard,
+		labels:            maps.Clone(networkConfig.Labels),
+		genesis:           networkConfig.Genesis,
+		maintenanceNodes:  map[string]bool{},
+		funded:            map[network.Chain]map[string]uint64{},
+		startTime:         time.Now(),
+	}
+	nodeConfigs, err := networkConfig.StartOrder()
+	if err != nil {
+		return nil, err
+	}
+	for _, nodeConfig := range nodeConfigs {
+		if _, err := n.addNode(nodeConfig); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// FailOn makes all subsequent calls to the network method [method]
+// (e.g. "AddNode") return [err]. A nil [err] clears the failure.
+func (n *Network) FailOn(method string, err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if err == nil {
+		delete(n.failures, method)
+		return
+	}
+	n.failures[method] = err
+}
+
+// SetNodeHealthy sets the health reported for the node [nodeName].
+// Pending Healthy calls are woken up.
+func (n *Network) SetNodeHealthy(nodeName string, healthy bool) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	node, ok := n.nodes[nodeName]
+	if !ok {
+		return network.ErrNodeNotFound
+	}
+	node.lock.Lock()
+	node.healthy = healthy
+	node.lock.Unlock()
+	n.notifyChange()
+	return nil
+}
+
+// SetNodeBootstrapped sets whether the node [nodeName] reports it
+// bootstrapped the blockchain [blockchainID]. Nodes bootstrap the
+// blockchains as they are created by default.
+func (n *Network) SetNodeBootstrapped(nodeName string, blockchainID ids.ID, bootstrapped bool) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if _, ok := n.nodes[nodeName]; !ok {
+		return network.ErrNodeNotFound
+	}
+	if bootstrapped {
+		delete(n.notBootstrapped[blockchainID], nodeName)
+		return nil
+	}
+	if n.notBootstrapped[blockchainID] == nil {
+		n.notBootstrapped[blockchainID] = map[string]bool{}
+	}
+	n.notBootstrapped[blockchainID][nodeName] = true
+	return nil
+}
+
+// SetNodeStatus sets the process status reported for the node [nodeName],
+// e.g. to simulate a node that stopped unexpectedly.
+func (n *Network) SetNodeStatus(nodeName string, nodeStatus status.Status) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	node, ok := n.nodes[nodeName]
+	if !ok {
+		return network.ErrNodeNotFound
+	}
+	node.lock.Lock()
+	node.status = nodeStatus
+	node.lock.Unlock()
+	n.notifyChange()
+	return nil
+}
+
+// CrashNode simulates the process of the node [nodeName] exiting without
+// being stopped: the node is marked as stopped, [crashReport] is returned by
+// its CrashReport method, and the crash hooks are called.
+// NodeName and Time are filled in if not given.
+func (n *Network) CrashNode(nodeName string, crashReport node.CrashReport) error {
+	n.lock.Lock()
+	node, ok := n.nodes[nodeName]
+	if !ok {
+		n.lock.Unlock()
+		return network.ErrNodeNotFound
+	}
+	if crashReport.NodeName == "" {
+		crashReport.NodeName = nodeName
+	}
+	if crashReport.Time.IsZero() {
+		crashReport.Time = time.Now()
+	}
+	node.lock.Lock()
+	node.status = status.Stopped
+	node.crashReport = &crashReport
+	node.lock.Unlock()
+	n.notifyChange()
+	hooks := slices.Clone(n.nodeHooks)
+	n.lock.Unlock()
+
+	// as on local networks, the crash hooks may call back into the network
+	for _, hooks := range hooks {
+		if hooks.OnNodeCrash != nil {
+			hooks.OnNodeCrash(node, crashReport)
+		}
+	}
+	return nil
+}
+
+// SetMetric sets the value reported for [metricName] by all nodes.
+// Pending AwaitMetric calls are woken up.
+func (n *Network) SetMetric(metricName string, value float64) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.metrics[metricName] = value
+	n.notifyChange()
+}
+
+// Assumes [n.lock] is held.
+func (n *Network) notifyChange() {
+	close(n.changedCh)
+	n.changedCh = make(chan struct{})
+}
+
+// Returns the error scripted for [method], or ErrStopped if the network was stopped.
+// Assumes [n.lock] is held.
+func (n *Network) check(method string) error {
+	if err, ok := n.failures[method]; ok {
+		return err
+	}
+	if n.stopped {
+		return network.ErrStopped
+	}
+	return nil
+}
+
+// See network.Network
+func (n *Network) GetNetworkID() (uint32, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("GetNetworkID"); err != nil {
+		return 0, err
+	}
+	return n.networkID, nil
+}
+
+// Healthy waits until all the running nodes are healthy, as
+// scripted with SetNodeHealthy, or the context is done.
+func (n *Network) Healthy(ctx context.Context) error {
+	for {
+		n.lock.RLock()
+		if err := n.check("Healthy"); err != nil {
+			n.lock.RUnlock()
+			return err
+		}
+		healthy := true
+		for nodeName, node := range n.nodes {
+			if node.GetPaused() || n.maintenanceNodes[nodeName] {
+				continue
+			}
+			if node.Status() != status.Running {
+				n.lock.RUnlock()
+				return &network.NodeError{NodeName: nodeName, Op: "check health of", Err: errNodeStopped}
+			}
+			if !node.isHealthy() {
+				healthy = false
+				continue
+			}
+			// as on the local network, each node is reported as soon as it is healthy
+			n.runNodeHealthyHooks(node)
+		}
+		changedCh := n.changedCh
+		n.lock.RUnlock()
+		if healthy {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("network failed to become healthy within timeout: %w", ctx.Err())
+		case <-changedCh:
+		}
+	}
+}
+
+// See network.Network
+func (n *Network) AwaitNodeHealthy(ctx context.Context, nodeName string) error {
+	n.lock.RLock()
+	if err := n.check("AwaitNodeHealthy"); err != nil {
+		n.lock.RUnlock()
+		return err
+	}
+	node, ok := n.nodes[nodeName]
+	n.lock.RUnlock()
+	if !ok {
+		return &network.NodeError{NodeName: nodeName, Op: "check health of", Err: network.ErrNodeNotFound}
+	}
+	return n.awaitNodeHealthy(ctx, node)
+}
+
+// Waits until [node] is healthy, failing if it is removed, paused or stopped.
+func (n *Network) awaitNodeHealthy(ctx context.Context, node *Node) error {
+	for {
+		n.lock.RLock()
+		if err := n.check("AwaitNodeHealthy"); err != nil {
+			n.lock.RUnlock()
+			return err
+		}
+		var err error
+		switch {
+		case n.nodes[node.name] != node:
+			err = network.ErrNodeNotFound
+		case node.GetPaused():
+			err = errNodePaused
+		case node.Status() != status.Running:
+			err = errNodeStopped
+		}
+		if err != nil {
+			err = &network.NodeError{NodeName: node.name, Op: "check health of", Err: err}
+		}
+		healthy := err == nil && node.isHealthy()
+		if healthy {
+			n.runNodeHealthyHooks(node)
+		}
+		changedCh := n.changedCh
+		n.lock.RUnlock()
+		if err != nil || healthy {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return &network.NodeError{
+				NodeName: node.name,
+				Op:       "check health of",
+				Err:      fmt.Errorf("not healthy within timeout: %w", ctx.Err()),
+			}
+		case <-changedCh:
+		}
+	}
+}
+
+// Calls the healthy hooks for [node], only the first time it is seen healthy.
+// Assumes [n.lock] is held.
+func (n *Network) runNodeHealthyHooks(node *Node) {
+	node.onHealthyOnce.Do(func() {
+		for _, hooks := range n.nodeHooks {
+			if hooks.OnAfterNodeHealthy != nil {
+				hooks.OnAfterNodeHealthy(node)
+			}
+		}
+	})
+}
+
+// See network.Network
+func (n *Network) Stop(context.Context) (err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("Stop", "")
+	defer n.finishOperation(record, &err)
+
+	if err := n.check("Stop"); err != nil {
+		return err
+	}
+	for _, node := range n.nodes {
+		n.stopNode(node)
+	}
+	n.nodes = map[string]*Node{}
+	n.stopped = true
+	n.notifyChange()
+	return nil
+}
+
+// Wait waits until the network is stopped, or all the nodes
+// that are not paused are set as stopped with SetNodeStatus
+func (n *Network) Wait(ctx context.Context) error {
+	for {
+		n.lock.RLock()
+		if err, ok := n.failures["Wait"]; ok {
+			n.lock.RUnlock()
+			return err
+		}
+		if n.stopped {
+			n.lock.RUnlock()
+			return nil
+		}
+		exited := false
+		for _, node := range n.nodes {
+			if node.GetPaused() {
+				continue
+			}
+			if node.Status() != status.Stopped {
+				exited = false
+				break
+			}
+			exited = true
+		}
+		changedCh := n.changedCh
+		n.lock.RUnlock()
+		if exited {
+			return network.ErrNodesExited
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changedCh:
+		}
+	}
+}
+
+// WatchHealth checks the nodes each time their health or status changes,
+// and every [config.Interval] while any of them is degraded. Each node is
+// connected to all the other running nodes, as far as MinPeers is concerned.
+func (n *Network) WatchHealth(ctx context.Context, config network.HealthWatchConfig) error {
+	if config.OnAlarm == nil {
+		return errors.New("health watch alarm callback not given")
+	}
+	interval := config.Interval
+	if interval == 0 {
+		interval = network.DefaultHealthWatchInterval
+	}
+	// node name --> when it was first seen degraded, for degraded nodes
+	degradedSince := map[string]time.Time{}
+	// names of the degraded nodes OnAlarm was called for
+	alarmed := map[string]bool{}
+	for first := true; ; first = false {
+		n.lock.RLock()
+		if err, ok := n.failures["WatchHealth"]; ok {
+			n.lock.RUnlock()
+			return err
+		}
+		if n.stopped {
+			n.lock.RUnlock()
+			if first {
+				return network.ErrStopped
+			}
+			return nil
+		}
+		nodeErrs := map[string]error{}
+		for nodeName, node := range n.nodes {
+			if node.GetPaused() || n.maintenanceNodes[nodeName] {
+				continue
+			}
+			nodeErrs[nodeName] = nil
+		}
+		crashReports := map[string]*node.CrashReport{}
+		for nodeName := range nodeErrs {
+			node := n.nodes[nodeName]
+			crashReports[nodeName] = node.CrashReport()
+			switch {
+			case node.Status() != status.Running:
+				nodeErrs[nodeName] = errNodeStopped
+			case !node.isHealthy():
+				nodeErrs[nodeName] = network.ErrNodeUnhealthy
+			case len(nodeErrs)-1 < config.MinPeers:
+				nodeErrs[nodeName] = fmt.Errorf("%w: %d of %d", network.ErrNotEnoughPeers, len(nodeErrs)-1, config.MinPeers)
+			}
+		}
+		changedCh := n.changedCh
+		n.lock.RUnlock()
+
+		for nodeName := range degradedSince {
+			if _, ok := nodeErrs[nodeName]; !ok {
+				// paused or removed
+				delete(degradedSince, nodeName)
+				delete(alarmed, nodeName)
+			}
+		}
+		now := time.Now()
+		nodeNames := maps.Keys(nodeErrs)
+		slices.Sort(nodeNames)
+		for _, nodeName := range nodeNames {
+			err := nodeErrs[nodeName]
+			since, degraded := degradedSince[nodeName]
+			if err == nil {
+				if alarmed[nodeName] {
+					config.OnAlarm(network.HealthAlarm{NodeName: nodeName, Since: since})
+				}
+				delete(degradedSince, nodeName)
+				delete(alarmed, nodeName)
+				continue
+			}
+			if !degraded {
+				since = now
+				degradedSince[nodeName] = since
+			}
+			if !alarmed[nodeName] && now.Sub(since) >= config.Debounce {
+				alarmed[nodeName] = true
+				config.OnAlarm(network.HealthAlarm{
+					NodeName: nodeName,
+					Err:      err,
+					Since:    since,
+					Crash:    crashReports[nodeName],
+				})
+			}
+		}
+
+		var tickCh <-chan time.Time
+		if len(degradedSince) != 0 {
+			tickCh = time.After(interval)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changedCh:
+		case <-tickCh:
+		}
+	}
+}
+
+// See network.Network
+func (n *Network) AddNode(nodeConfig node.Config) (_ *network.AddedNode, err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("AddNode", nodeConfig.Name)
+	defer n.finishOperation(record, &err)
+
+	if err := n.check("AddNode"); err != nil {
+		return nil, err
+	}
+	if nodeConfig.StartDelay > 0 {
+		return nil, network.ErrStartDelay
+	}
+	if err := n.checkResources(1); err != nil {
+		return nil, err
+	}
+	node, err := n.addNode(nodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	record.NodeName = node.GetName()
+	return network.NewAddedNode(node), nil
+}
+
+// See network.Network
+func (n *Network) AddNodeFromTemplate(templateName string, overrides network.NodeOverrides) (_ *network.AddedNode, err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("AddNodeFromTemplate", overrides.Name)
+	defer n.finishOperation(record, &err)
+	record.Params = map[string]string{"template": templateName}
+
+	if err := n.check("AddNodeFromTemplate"); err != nil {
+		return nil, err
+	}
+	template, ok := n.nodes[templateName]
+	if !ok {
+		return nil, network.ErrNodeNotFound
+	}
+	nodeConfig, err := network.NewNodeConfigFromTemplate(template.GetConfig(), overrides)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.checkResources(1); err != nil {
+		return nil, err
+	}
+	node, err := n.addNode(nodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	record.NodeName = node.GetName()
+	return network.NewAddedNode(node), nil
+}
+
+// Assumes [n.lock] is held.
+func (n *Network) addNode(nodeConfig node.Config) (*Node, error) {
+	if nodeConfig.Name == "" {
+		for {
+			nodeConfig.Name = fmt.Sprintf("%s%d", defaultNodeNamePrefix, n.nextNodeSuffix)
+			if _, ok := n.nodes[nodeConfig.Name]; !ok {
+				break
+			}
+			n.nextNodeSuffix++
+		}
+	}
+	if _, ok := n.nodes[nodeConfig.Name]; ok {
+		return nil, fmt.Errorf("repeated node name %q", nodeConfig.Name)
+	}
+	if err := n.runNodeStartHooks(nodeConfig); err != nil {
+		return nil, err
+	}
+	nodeID := ids.GenerateTestNodeID()
+	if nodeConfig.StakingKey != "" && nodeConfig.StakingCert != "" {
+		var err error
+		nodeID, err = utils.ToNodeID([]byte(nodeConfig.StakingKey), []byte(nodeConfig.StakingCert))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get node ID: %w", err)
+		}
+	}
+	node := &Node{
+		name:      nodeConfig.Name,
+		nodeID:    nodeID,
+		apiPort:   n.nextPort,
+		p2pPort:   n.nextPort + 1,
+		config:    nodeConfig,
+		status:    status.Running,
+		healthy:   true,
+		startTime: time.Now(),
+		network:   n,
+
+		consensusParameters: snowball.DefaultParameters,
+	}
+	n.nextPort += 2
+	n.nodes[node.name] = node
+	n.notifyChange()
+	return node, nil
+}
+
+// See network.Network
+func (n *Network) RemoveNode(ctx context.Context, nodeName string) error {
+	n.lock.RLock()
+	force := n.maxStoppedStake == 0
+	n.lock.RUnlock()
+	return n.removeNodeWithOptions(ctx, "RemoveNode", nodeName, network.RemoveNodeOptions{Force: force})
+}
+
+// RemoveNodeWithOptions is as on the local network, considering all the
+// nodes as validators with the same weight. Draining is a no-op.
+func (n *Network) RemoveNodeWithOptions(ctx context.Context, nodeName string, opts network.RemoveNodeOptions) error {
+	return n.removeNodeWithOptions(ctx, "RemoveNodeWithOptions", nodeName, opts)
+}
+
+func (n *Network) removeNodeWithOptions(_ context.Context, method string, nodeName string, opts network.RemoveNodeOptions) (err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("RemoveNode", nodeName)
+	defer n.finishOperation(record, &err)
+
+	if err := n.check(method); err != nil {
+		return err
+	}
+	if node, ok := n.nodes[nodeName]; ok && !opts.Force {
+		maxStoppedStake := n.maxStoppedStake
+		if maxStoppedStake == 0 {
+			maxStoppedStake = network.DefaultMaxStoppedStake
+		}
+		if err := n.checkStoppedStake(maxStoppedStake, node); err != nil {
+			return &network.NodeError{NodeName: nodeName, Op: "remove", Err: err}
+		}
+	}
+	return n.removeNode(nodeName)
+}
+
+// SetNodeDiskChaos records [chaos] for the node [nodeName], as returned by
+// GetNodeDiskChaos, without injecting any fault. As on local networks, the I/O
+// throttling is removed when the node is stopped, and the disk fill when it
+// is removed.
+func (n *Network) SetNodeDiskChaos(_ context.Context, nodeName string, chaos network.DiskChaos) (err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("SetNodeDiskChaos", nodeName)
+	defer n.finishOperation(record, &err)
+
+	if err := n.check("SetNodeDiskChaos"); err != nil {
+		return err
+	}
+	node, ok := n.nodes[nodeName]
+	if !ok {
+		return &network.NodeError{NodeName: nodeName, Op: "set disk chaos of", Err: network.ErrNodeNotFound}
+	}
+	if node.GetPaused() {
+		return &network.NodeError{NodeName: nodeName, Op: "set disk chaos of", Err: errNodePaused}
+	}
+	if err := chaos.Validate(node.GetConfig()); err != nil {
+		return &network.NodeError{NodeName: nodeName, Op: "set disk chaos of", Err: err}
+	}
+	if chaos.IsZero() {
+		delete(n.diskChaos, nodeName)
+	} else {
+		n.diskChaos[nodeName] = chaos
+	}
+	return nil
+}
+
+// GetNodeDiskChaos returns the disk faults in effect on the node [nodeName]
+func (n *Network) GetNodeDiskChaos(nodeName string) (network.DiskChaos, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if _, ok := n.nodes[nodeName]; !ok {
+		return network.DiskChaos{}, network.ErrNodeNotFound
+	}
+	return n.diskChaos[nodeName], nil
+}
+
+// SetLinkBandwidth records the limit of the link between the nodes
+// [nodeName1] and [nodeName2], as returned by GetLinkBandwidth, without
+// shaping any traffic. As on local networks, the limit is kept until either
+// node is removed.
+func (n *Network) SetLinkBandwidth(_ context.Context, nodeName1 string, nodeName2 string, bitsPerSec uint64) (err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("SetLinkBandwidth", nodeName1)
+	defer n.finishOperation(record, &err)
+
+	if err := n.check("SetLinkBandwidth"); err != nil {
+		return err
+	}
+	if nodeName1 == nodeName2 {
+		return &network.NodeError{NodeName: nodeName1, Op: "set link bandwidth of", Err: errSameNodeLink}
+	}
+	for _, nodeName := range []string{nodeName1, nodeName2} {
+		if _, ok := n.nodes[nodeName]; !ok {
+			return &network.NodeError{NodeName: nodeName, Op: "set link bandwidth of", Err: network.ErrNodeNotFound}
+		}
+	}
+	link := linkKey(nodeName1, nodeName2)
+	if bitsPerSec == 0 {
+		delete(n.linkBandwidths, link)
+	} else {
+		n.linkBandwidths[link] = bitsPerSec
+	}
+	return nil
+}
+
+// GetLinkBandwidth returns the bandwidth limit of the link between the nodes
+// [nodeName1] and [nodeName2], in bits per second, or 0 if it isn't limited
+func (n *Network) GetLinkBandwidth(nodeName1 string, nodeName2 string) uint64 {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.linkBandwidths[linkKey(nodeName1, nodeName2)]
+}
+
+// Returns the key of the link between the nodes [nodeName1] and [nodeName2]
+func linkKey(nodeName1 string, nodeName2 string) [2]string {
+	if nodeName2 < nodeName1 {
+		return [2]string{nodeName2, nodeName1}
+	}
+	return [2]string{nodeName1, nodeName2}
+}
+
+// See network.Network
+func (n *Network) SetNodeMaintenance(nodeName string, inMaintenance bool) (err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("SetNodeMaintenance", nodeName)
+	defer n.finishOperation(record, &err)
+
+	if err := n.check("SetNodeMaintenance"); err != nil {
+		return err
+	}
+	if _, ok := n.nodes[nodeName]; !ok {
+		return &network.NodeError{NodeName: nodeName, Op: "set maintenance of", Err: network.ErrNodeNotFound}
+	}
+	if inMaintenance {
+		n.maintenanceNodes[nodeName] = true
+	} else {
+		delete(n.maintenanceNodes, nodeName)
+	}
+	n.notifyChange()
+	return nil
+}
+
+// See network.Network
+func (n *Network) NodesInMaintenance() ([]string, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("NodesInMaintenance"); err != nil {
+		return nil, err
+	}
+	nodeNames := maps.Keys(n.maintenanceNodes)
+	sort.Strings(nodeNames)
+	return nodeNames, nil
+}
+
+// See network.Network
+func (n *Network) SetQuorumGuard(maxStoppedStake float64) (err error) {
+	record := n.startOperation("SetQuorumGuard", "")
+	defer n.finishOperation(record, &err)
+	if maxStoppedStake < 0 || maxStoppedStake > 1 {
+		return fmt.Errorf("max stopped stake must be between 0 and 1, got %v", maxStoppedStake)
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.maxStoppedStake = maxStoppedStake
+	return nil
+}
+
+// QuorumStatus reports the nodes as validators of weight 1, as the quorum
+// guard counts them, online if running and healthy.
+// Returns network.ErrNoRunningNodes if all the nodes are paused or there are none.
+func (n *Network) QuorumStatus(context.Context) (network.QuorumStatus, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("QuorumStatus"); err != nil {
+		return network.QuorumStatus{}, err
+	}
+	quorum := network.QuorumStatus{
+		Online:  map[ids.NodeID]string{},
+		Offline: map[ids.NodeID]string{},
+	}
+	running := false
+	for nodeName, node := range n.nodes {
+		quorum.TotalStake++
+		if node.GetPaused() {
+			quorum.OfflineStake++
+			quorum.Offline[node.nodeID] = nodeName
+			continue
+		}
+		running = true
+		if node.Status() == status.Running && node.isHealthy() {
+			quorum.OnlineStake++
+			quorum.Online[node.nodeID] = nodeName
+		} else {
+			quorum.OfflineStake++
+			quorum.Offline[node.nodeID] = nodeName
+		}
+	}
+	if !running {
+		return network.QuorumStatus{}, network.ErrNoRunningNodes
+	}
+	return quorum, nil
+}
+
+// Assumes [n.lock] is held.
+func (n *Network) checkQuorumGuard(nodes ...*Node) error {
+	if n.maxStoppedStake == 0 {
+		return nil
+	}
+	return n.checkStoppedStake(n.maxStoppedStake, nodes...)
+}
+
+// checkStoppedStake is as on the local network, considering all the
+// nodes as validators with the same weight.
+// Assumes [n.lock] is held.
+func (n *Network) checkStoppedStake(maxStoppedStake float64, nodes ...*Node) error {
+	stopping := map[*Node]struct{}{}
+	for _, node := range nodes {
+		if !node.GetPaused() && node.Status() == status.Running {
+			stopping[node] = struct{}{}
+		}
+	}
+	if len(stopping) == 0 {
+		return nil
+	}
+	stopped := 0
+	for _, node := range n.nodes {
+		if _, ok := stopping[node]; ok || node.GetPaused() || node.Status() != status.Running {
+			stopped++
+		}
+	}
+	if float64(stopped)/float64(len(n.nodes)) > maxStoppedStake {
+		return fmt.Errorf("%w: %d of %d nodes would be stopped", network.ErrQuorumLoss, stopped, len(n.nodes))
+	}
+	return nil
+}
+
+// Assumes [n.lock] is held.
+func (n *Network) removeNode(nodeName string) error {
+	node, ok := n.nodes[nodeName]
+	if !ok {
+		return &network.NodeError{NodeName: nodeName, Op: "remove", Err: network.ErrNodeNotFound}
+	}
+	n.stopNode(node)
+	delete(n.nodes, nodeName)
+	delete(n.diskChaos, nodeName)
+	delete(n.maintenanceNodes, nodeName)
+	for link := range n.linkBandwidths {
+		if link[0] == nodeName || link[1] == nodeName {
+			delete(n.linkBandwidths, link)
+		}
+	}
+	n.notifyChange()
+	return nil
+}
+
+// stopNode marks [node] as stopped and calls the stop hooks,
+// unless it is paused, in which case it was already stopped.
+// Assumes [n.lock] is held.
+func (n *Network) stopNode(node *Node) {
+	if node.GetPaused() {
+		return
+	}
+	node.lock.Lock()
+	node.status = status.Stopped
+	node.lock.Unlock()
+	if chaos, ok := n.diskChaos[node.name]; ok {
+		// the I/O throttling is on the process
+		chaos.ReadBytesPerSec = 0
+		chaos.WriteBytesPerSec = 0
+		if chaos.IsZero() {
+			delete(n.diskChaos, node.name)
+		} else {
+			n.diskChaos[node.name] = chaos
+		}
+	}
+	n.runNodeStopHooks(node)
+}
+
+// Assumes [n.lock] is held.
+func (n *Network) runNodeStopHooks(node *Node) {
+	for _, hooks := range n.nodeHooks {
+		if hooks.OnNodeStop != nil {
+			hooks.OnNodeStop(node)
+		}
+	}
+}
+
+// See network.Network
+func (n *Network) RegisterNodeHooks(hooks network.NodeHooks) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.nodeHooks = append(n.nodeHooks, hooks)
+}
+
+// See network.Network
+func (n *Network) PauseNode(_ context.Context, nodeName string) error {
+	return n.setPaused("PauseNode", nodeName, nil, true)
+}
+
+// See network.Network
+func (n *Network) ResumeNode(_ context.Context, nodeName string) error {
+	return n.setPaused("ResumeNode", nodeName, nil, false)
+}
+
+// Pauses or resumes [nodeName]. If [handle] is given, only if it is the node,
+// not another one added with the same name after it was removed.
+func (n *Network) setPaused(method string, nodeName string, handle *Node, paused bool) (err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation(method, nodeName)
+	defer n.finishOperation(record, &err)
+
+	if err := n.check(method); err != nil {
+		return err
+	}
+	op := "resume"
+	if paused {
+		op = "pause"
+	}
+	node, ok := n.nodes[nodeName]
+	if !ok || (handle != nil && node != handle) {
+		return &network.NodeError{NodeName: nodeName, Op: op, Err: network.ErrNodeNotFound}
+	}
+	if paused {
+		if err := n.checkQuorumGuard(node); err != nil {
+			return &network.NodeError{NodeName: nodeName, Op: op, Err: err}
+		}
+	}
+	if !paused && node.GetPaused() {
+		// as on the local network, a failed start hook keeps the node paused
+		if err := n.runNodeStartHooks(node.GetConfig()); err != nil {
+			return &network.NodeError{NodeName: nodeName, Op: op, Err: err}
+		}
+	}
+	if err := node.setPaused(paused); err != nil {
+		return &network.NodeError{NodeName: nodeName, Op: op, Err: err}
+	}
+	if paused {
+		n.runNodeStopHooks(node)
+	}
+	n.notifyChange()
+	return nil
+}
+
+// See network.Network
+func (n *Network) GetNode(nodeName string) (node.Node, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("GetNode"); err != nil {
+		return nil, err
+	}
+	node, ok := n.nodes[nodeName]
+	if !ok {
+		return nil, network.ErrNodeNotFound
+	}
+	return node, nil
+}
+
+// See network.Network
+func (n *Network) GetAllNodes() (map[string]node.Node, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("GetAllNodes"); err != nil {
+		return nil, err
+	}
+	nodesCopy := make(map[string]node.Node, len(n.nodes))
+	for name, node := range n.nodes {
+		nodesCopy[name] = node
+	}
+	return nodesCopy, nil
+}
+
+// See network.Network
+func (n *Network) GetNodeNames() ([]string, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("GetNodeNames"); err != nil {
+		return nil, err
+	}
+	nodeNames := maps.Keys(n.nodes)
+	sort.Strings(nodeNames)
+	return nodeNames, nil
+}
+
+// See network.Network
+func (n *Network) GetNodeNamesWithFilter(_ context.Context, filter network.NodeFilter) ([]string, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("GetNodeNamesWithFilter"); err != nil {
+		return nil, err
+	}
+	nodeNames := []string{}
+	for nodeName, node := range n.nodes {
+		if !filter.MatchesConfig(node.GetConfig()) {
+			continue
+		}
+		if filter.Healthy && (node.GetPaused() || node.Status() != status.Running || !node.isHealthy()) {
+			continue
+		}
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+	return nodeNames, nil
+}
+
+// SaveSnapshot keeps the node configs in memory, and stops the network
+func (n *Network) SaveSnapshot(_ context.Context, snapshotName string, _ string, force bool) (_ string, err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("SaveSnapshot", "")
+	defer n.finishOperation(record, &err)
+
+	if err := n.check("SaveSnapshot"); err != nil {
+		return "", err
+	}
+	if _, ok := n.snapshots[snapshotName]; ok && !force {
+		return "", fmt.Errorf("snapshot %q already exists", snapshotName)
+	}
+	networkConfig := network.Config{
+		NetworkID:        n.networkID,
+		StaticValidators: n.staticValidators,
+		ResourceGuard:    n.resourceGuard,
+		Labels:           maps.Clone(n.labels),
+		Genesis:          n.genesis,
+	}
+	for _, node := range n.nodes {
+		networkConfig.NodeConfigs = append(networkConfig.NodeConfigs, node.config)
+	}
+	n.snapshots[snapshotName] = networkConfig
+	for _, node := range n.nodes {
+		n.stopNode(node)
+	}
+	n.nodes = map[string]*Node{}
+	n.stopped = true
+	n.notifyChange()
+	return snapshotName, nil
+}
+
+// ToConfig returns the network ID and the configs of the nodes,
+// sorted by name, see network.Network
+func (n *Network) ToConfig() (network.Config, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("ToConfig"); err != nil {
+		return network.Config{}, err
+	}
+	networkConfig := network.Config{
+		NetworkID:        n.networkID,
+		StaticValidators: n.staticValidators,
+		ResourceGuard:    n.resourceGuard,
+		Labels:           maps.Clone(n.labels),
+		Genesis:          n.genesis,
+	}
+	for _, node := range n.nodes {
+		nodeConfig := node.config
+		dependsOn := []string{}
+		for _, dependency := range nodeConfig.DependsOn {
+			if _, ok := n.nodes[dependency]; ok {
+				dependsOn = append(dependsOn, dependency)
+			}
+		}
+		if len(dependsOn) != len(nodeConfig.DependsOn) {
+			nodeConfig.DependsOn = dependsOn
+		}
+		networkConfig.NodeConfigs = append(networkConfig.NodeConfigs, nodeConfig)
+	}
+	sort.Slice(networkConfig.NodeConfigs, func(i, j int) bool {
+		return networkConfig.NodeConfigs[i].Name < networkConfig.NodeConfigs[j].Name
+	})
+	return networkConfig, nil
+}
+
+// GetSnapshot returns the config of a snapshot saved with SaveSnapshot,
+// that can be used to create a new fake network
+func (n *Network) GetSnapshot(snapshotName string) (network.Config, bool) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	networkConfig, ok := n.snapshots[snapshotName]
+	return networkConfig, ok
+}
+
+// See network.Network
+func (n *Network) RemoveSnapshot(snapshotName string, _ string) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if err, ok := n.failures["RemoveSnapshot"]; ok {
+		return err
+	}
+	if _, ok := n.snapshots[snapshotName]; !ok {
+		return fmt.Errorf("snapshot %q not found", snapshotName)
+	}
+	delete(n.snapshots, snapshotName)
+	return nil
+}
+
+// See network.Network
+func (n *Network) GetSnapshotNames() ([]string, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err, ok := n.failures["GetSnapshotNames"]; ok {
+		return nil, err
+	}
+	return maps.Keys(n.snapshots), nil
+}
+
+// RestartNode updates the node config. The node keeps its identity and ports.
+func (n *Network) RestartNode(
+	_ context.Context,
+	nodeName string,
+	binaryPath string,
+	pluginDir string,
+	trackSubnets string,
+	chainConfigs map[string]string,
+	upgradeConfigs map[string]string,
+	subnetConfigs map[string]string,
+) (err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("RestartNode", nodeName)
+	defer n.finishOperation(record, &err)
+
+	if err := n.check("RestartNode"); err != nil {
+		return err
+	}
+	node, ok := n.nodes[nodeName]
+	if !ok {
+		return &network.NodeError{NodeName: nodeName, Op: "restart", Err: network.ErrNodeNotFound}
+	}
+	if err := n.checkQuorumGuard(node); err != nil {
+		return &network.NodeError{NodeName: nodeName, Op: "restart", Err: err}
+	}
+	nodeConfig := node.GetConfig()
+	nodeConfig.Flags = maps.Clone(nodeConfig.Flags)
+	if nodeConfig.Flags == nil {
+		nodeConfig.Flags = map[string]interface{}{}
+	}
+	if binaryPath != "" {
+		nodeConfig.BinaryPath = binaryPath
+	}
+	if pluginDir != "" {
+		nodeConfig.Flags[config.PluginDirKey] = pluginDir
+	}
+	if trackSubnets != "" {
+		nodeConfig.Flags[config.TrackSubnetsKey] = trackSubnets
+	}
+	nodeConfig.ChainConfigFiles = mergeConfigFiles(nodeConfig.ChainConfigFiles, chainConfigs)
+	nodeConfig.UpgradeConfigFiles = mergeConfigFiles(nodeConfig.UpgradeConfigFiles, upgradeConfigs)
+	nodeConfig.SubnetConfigFiles = mergeConfigFiles(nodeConfig.SubnetConfigFiles, subnetConfigs)
+	return n.restartNode(node, nodeConfig)
+}
+
+// Makes node [nodeName] track [subnetID] if [track] is true, or stop
+// tracking it otherwise, restarting the node if it is running and the
+// subnets it tracks change, as on the local network
+func (n *Network) setSubnetTracked(_ context.Context, handle *Node, subnetID ids.ID, track bool) (err error) {
+	nodeName := handle.name
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	op := "UntrackSubnet"
+	if track {
+		op = "TrackSubnet"
+	}
+	record := n.startOperation(op, nodeName)
+	defer n.finishOperation(record, &err)
+
+	if err := n.check(op); err != nil {
+		return err
+	}
+	fakeNode, ok := n.nodes[nodeName]
+	if !ok || fakeNode != handle {
+		return &network.NodeError{NodeName: nodeName, Op: "track subnet", Err: network.ErrNodeNotFound}
+	}
+	nodeConfig := fakeNode.GetConfig()
+	tracked, err := node.TrackedSubnets(nodeConfig.Flags)
+	if err != nil {
+		return &network.NodeError{NodeName: nodeName, Op: "track subnet", Err: err}
+	}
+	i, isTracked := slices.BinarySearch(tracked, subnetID.String())
+	switch {
+	case track && !isTracked:
+		tracked = slices.Insert(tracked, i, subnetID.String())
+	case !track && isTracked:
+		tracked = slices.Delete(tracked, i, i+1)
+	default:
+		return nil
+	}
+	paused := fakeNode.GetPaused()
+	if !paused {
+		if err := n.checkQuorumGuard(fakeNode); err != nil {
+			return &network.NodeError{NodeName: nodeName, Op: "restart", Err: err}
+		}
+	}
+	nodeConfig.Flags = maps.Clone(nodeConfig.Flags)
+	if nodeConfig.Flags == nil {
+		nodeConfig.Flags = map[string]interface{}{}
+	}
+	node.SetTrackedSubnets(nodeConfig.Flags, tracked)
+	if paused {
+		fakeNode.lock.Lock()
+		fakeNode.config = nodeConfig
+		fakeNode.lock.Unlock()
+		return nil
+	}
+	return n.restartNode(fakeNode, nodeConfig)
+}
+
+func mergeConfigFiles(configFiles map[string]string, newConfigFiles map[string]string) map[string]string {
+	merged := maps.Clone(configFiles)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	for k, v := range newConfigFiles {
+		merged[k] = v
+	}
+	return merged
+}
+
+// CreateBlockchains returns a new random ID for each blockchain, and for
+// the subnets of the blockchains not given one. The blockchains are
+// named after their VM, and are then returned by Blockchains.
+func (n *Network) CreateBlockchains(_ context.Context, chainSpecs []network.BlockchainSpec) (_ []network.BlockchainInfo, err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("CreateBlockchains", "")
+	defer n.finishOperation(record, &err)
+
+	if err := n.checkStaking("CreateBlockchains"); err != nil {
+		return nil, err
+	}
+	for _, chainSpec := range chainSpecs {
+		if chainSpec.SubnetSpec != nil {
+			if err := n.checkNotObservers("add subnet validator", chainSpec.SubnetSpec.Participants...); err != nil {
+				return nil, err
+			}
+		}
+	}
+	createdChains := make([]network.BlockchainInfo, 0, len(chainSpecs))
+	for _, chainSpec := range chainSpecs {
+		vmID, err := utils.VMID(chainSpec.VMName)
+		if err != nil {
+			return nil, err
+		}
+		subnetID := ids.GenerateTestID()
+		if chainSpec.SubnetID != nil {
+			subnetID, err = ids.FromString(*chainSpec.SubnetID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		chainInfo := network.BlockchainInfo{
+			ID:   ids.GenerateTestID(),
+			VMID: vmID,
+		}
+		if chainSpec.BlockchainAlias != "" {
+			chainInfo.Aliases = []string{chainSpec.BlockchainAlias}
+			n.blockchainAliases[chainInfo.ID] = []string{chainSpec.BlockchainAlias}
+		}
+		n.blockchains = append(n.blockchains, network.Blockchain{
+			ID:       chainInfo.ID,
+			Name:     chainSpec.VMName,
+			SubnetID: subnetID,
+			VMID:     vmID,
+		})
+		createdChains = append(createdChains, chainInfo)
+	}
+	return createdChains, nil
+}
+
+// CreateSubnets returns a new random ID for each subnet
+func (n *Network) CreateSubnets(_ context.Context, subnetSpecs []network.SubnetSpec) (_ []ids.ID, err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("CreateSubnets", "")
+	defer n.finishOperation(record, &err)
+
+	if err := n.checkStaking("CreateSubnets"); err != nil {
+		return nil, err
+	}
+	for _, subnetSpec := range subnetSpecs {
+		if err := n.checkNotObservers("add subnet validator", subnetSpec.Participants...); err != nil {
+			return nil, err
+		}
+	}
+	subnetIDs := make([]ids.ID, 0, len(subnetSpecs))
+	for range subnetSpecs {
+		subnetIDs = append(subnetIDs, ids.GenerateTestID())
+	}
+	return subnetIDs, nil
+}
+
+// TransformSubnet returns new random elastic subnet and asset IDs for each subnet
+func (n *Network) TransformSubnet(_ context.Context, elasticSubnetSpecs []network.ElasticSubnetSpec) (_ []ids.ID, _ []ids.ID, err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("TransformSubnet", "")
+	defer n.finishOperation(record, &err)
+
+	if err := n.checkStaking("TransformSubnet"); err != nil {
+		return nil, nil, err
+	}
+	elasticSubnetIDs := make([]ids.ID, 0, len(elasticSubnetSpecs))
+	assetIDs := make([]ids.ID, 0, len(elasticSubnetSpecs))
+	for _, elasticSubnetSpec := range elasticSubnetSpecs {
+		if elasticSubnetSpec.SubnetID == nil {
+			return nil, nil, fmt.Errorf("subnet ID is not provided")
+		}
+		subnetID, err := ids.FromString(*elasticSubnetSpec.SubnetID)
+		if err != nil {
+			return nil, nil, err
+		}
+		elasticSubnetID := ids.GenerateTestID()
+		n.elasticSubnetIDs[subnetID] = elasticSubnetID
+		elasticSubnetIDs = append(elasticSubnetIDs, elasticSubnetID)
+		assetIDs = append(assetIDs, ids.GenerateTestID())
+	}
+	return elasticSubnetIDs, assetIDs, nil
+}
+
+// See network.Network
+func (n *Network) AddPermissionlessDelegators(_ context.Context, delegatorSpecs []network.PermissionlessStakerSpec) (err error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	record := n.startOperation("AddPermissionlessDelegators", "")
+	defer n.finishOperation(record, &err)
+
+	if err := n.checkStaking("AddPermissionlessDelegators"); err != nil {
+		return err
+	}
+	for _, spec := range delegatorSpecs {
+		if err := n.checkNotObservers("add permissionless delegator", spec.NodeName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// See network.Network
+func (n *Network) AddPermissionlessValidators(_ context.Context, validatorSpecs []network.PermissionlessStakerSpec) (err error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	record := n.startOperation("AddPermissionlessValidators", "")
+	defer n.finishOperation(record, &err)
+
+	if err := n.checkStaking("AddPermissionlessValidators"); err != nil {
+		return err
+	}
+	for _, spec := range validatorSpecs {
+		if err := n.checkNotObservers("add permissionless validator", spec.NodeName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// See network.Network
+func (n *Network) RemoveSubnetValidators(context.Context, []network.SubnetValidatorsSpec) (err error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	record := n.startOperation("RemoveSubnetValidators", "")
+	defer n.finishOperation(record, &err)
+
+	return n.checkStaking("RemoveSubnetValidators")
+}
+
+// See network.Network
+func (n *Network) AddSubnetValidators(_ context.Context, subnetValidatorsSpecs []network.SubnetValidatorsSpec) (err error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	record := n.startOperation("AddSubnetValidators", "")
+	defer n.finishOperation(record, &err)
+
+	if err := n.checkStaking("AddSubnetValidators"); err != nil {
+		return err
+	}
+	for _, spec := range subnetValidatorsSpecs {
+		if err := n.checkNotObservers("add subnet validator", spec.NodeNames...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns network.ErrObserverNode if one of [nodeNames] is an observer node.
+// Assumes [n.lock] is held.
+func (n *Network) checkNotObservers(op string, nodeNames ...string) error {
+	for _, nodeName := range nodeNames {
+		if node, ok := n.nodes[nodeName]; ok && node.GetConfig().Observer {
+			return &network.NodeError{NodeName: nodeName, Op: op, Err: network.ErrObserverNode}
+		}
+	}
+	return nil
+}
+
+// Returns the error of check, or network.ErrStaticValidators
+// if the network has static validators.
+// Assumes [n.lock] is held.
+func (n *Network) checkStaking(method string) error {
+	if err := n.check(method); err != nil {
+		return err
+	}
+	if n.staticValidators {
+		return network.ErrStaticValidators
+	}
+	return nil
+}
+
+// See network.Network
+func (n *Network) AliasBlockchain(_ context.Context, blockchainID ids.ID, blockchainAlias string) (err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("AliasBlockchain", "")
+	defer n.finishOperation(record, &err)
+
+	if err := n.check("AliasBlockchain"); err != nil {
+		return err
+	}
+	for _, alias := range n.blockchainAliases[blockchainID] {
+		if alias == blockchainAlias {
+			return nil
+		}
+	}
+	n.blockchainAliases[blockchainID] = append(n.blockchainAliases[blockchainID], blockchainAlias)
+	return nil
+}
+
+// See network.Network
+func (n *Network) GetBlockchainAliases(blockchainID ids.ID) ([]string, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("GetBlockchainAliases"); err != nil {
+		return nil, err
+	}
+	aliases := n.blockchainAliases[blockchainID]
+	return append([]string(nil), aliases...), nil
+}
+
+// See network.Network
+func (n *Network) CreateKeystoreUser(
+	ctx context.Context,
+	nodeNames []string,
+	user string,
+	pass string,
+	privateKeys ...*secp256k1.PrivateKey,
+) (err error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	record := n.startOperation("CreateKeystoreUser", "")
+	defer n.finishOperation(record, &err)
+
+	if err := n.check("CreateKeystoreUser"); err != nil {
+		return err
+	}
+	if len(nodeNames) == 0 {
+		nodeNames = maps.Keys(n.nodes)
+	}
+	for _, nodeName := range nodeNames {
+		node, ok := n.nodes[nodeName]
+		if !ok {
+			return &network.NodeError{NodeName: nodeName, Op: "create keystore user on", Err: network.ErrNodeNotFound}
+		}
+		if err := node.CreateKeystoreUser(ctx, user, pass, privateKeys...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fund records the funds sent, see Funded. No tx is issued: the tx ID is random.
+func (n *Network) Fund(_ context.Context, addr string, amount uint64, chain network.Chain) (_ ids.ID, err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("Fund", "")
+	defer n.finishOperation(record, &err)
+
+	if err := n.check("Fund"); err != nil {
+		return ids.Empty, err
+	}
+	switch chain {
+	case network.XChain, network.PChain, network.CChain:
+	default:
+		return ids.Empty, fmt.Errorf("unknown chain %q", chain)
+	}
+	if amount == 0 {
+		return ids.Empty, errors.New("fund amount must be positive")
+	}
+	if n.funded[chain] == nil {
+		n.funded[chain] = map[string]uint64{}
+	}
+	n.funded[chain][addr] += amount
+	return ids.GenerateTestID(), nil
+}
+
+// Funded returns the nAVAX sent to [addr] on [chain] by Fund
+func (n *Network) Funded(addr string, chain network.Chain) uint64 {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.funded[chain][addr]
+}
+
+// AliasVM records the alias. Unlike the local network, no node is restarted.
+func (n *Network) AliasVM(_ context.Context, vmID ids.ID, vmAlias string) (err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("AliasVM", "")
+	defer n.finishOperation(record, &err)
+
+	if err := n.check("AliasVM"); err != nil {
+		return err
+	}
+	if vmAlias == "" {
+		return fmt.Errorf("empty vm alias")
+	}
+	for otherVMID, vmAliases := range n.vmAliases {
+		if slices.Contains(vmAliases, vmAlias) {
+			if otherVMID == vmID {
+				return nil
+			}
+			return fmt.Errorf("vm alias %q already used for vm %s", vmAlias, otherVMID)
+		}
+	}
+	n.vmAliases[vmID] = append(n.vmAliases[vmID], vmAlias)
+	return nil
+}
+
+// See network.Network
+func (n *Network) GetVMAliases(vmID ids.ID) ([]string, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("GetVMAliases"); err != nil {
+		return nil, err
+	}
+	return slices.Clone(n.vmAliases[vmID]), nil
+}
+
+// AwaitMetric waits until the value set with SetMetric satisfies
+// the predicate, or the context is done.
+// Returns network.ErrNoRunningNodes if all the nodes are paused or there are none.
+func (n *Network) AwaitMetric(ctx context.Context, metricName string, predicate func(float64) bool) error {
+	for {
+		n.lock.RLock()
+		if err := n.check("AwaitMetric"); err != nil {
+			n.lock.RUnlock()
+			return err
+		}
+		running := false
+		for _, node := range n.nodes {
+			if !node.GetPaused() {
+				running = true
+			}
+		}
+		value, ok := n.metrics[metricName]
+		changedCh := n.changedCh
+		n.lock.RUnlock()
+		if !running {
+			return network.ErrNoRunningNodes
+		}
+		if ok && predicate(value) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("metric %q didn't satisfy the predicate within timeout: %w", metricName, ctx.Err())
+		case <-changedCh:
+		}
+	}
+}
+
+// GetMetric returns the value set with SetMetric for each running node.
+// Returns network.ErrNoRunningNodes if all the nodes are paused or there are none.
+func (n *Network) GetMetric(_ context.Context, metricName string) (map[string]float64, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("GetMetric"); err != nil {
+		return nil, err
+	}
+	value, ok := n.metrics[metricName]
+	values := map[string]float64{}
+	for nodeName, node := range n.nodes {
+		if node.GetPaused() {
+			continue
+		}
+		if !ok {
+			return nil, &network.NodeError{NodeName: nodeName, Op: "get metric of", Err: fmt.Errorf("metric %q not found", metricName)}
+		}
+		values[nodeName] = value
+	}
+	if len(values) == 0 {
+		return nil, network.ErrNoRunningNodes
+	}
+	return values, nil
+}
+
+// Versions returns the version set with the node's SetVersion for each running node.
+// Returns network.ErrNoRunningNodes if all the nodes are paused or there are none.
+func (n *Network) Versions(ctx context.Context) (map[string]node.Version, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("Versions"); err != nil {
+		return nil, err
+	}
+	versions := map[string]node.Version{}
+	for nodeName, node := range n.nodes {
+		if node.GetPaused() {
+			continue
+		}
+		version, err := node.GetVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		versions[nodeName] = version
+	}
+	if len(versions) == 0 {
+		return nil, network.ErrNoRunningNodes
+	}
+	return versions, nil
+}
+
+// Blockchains returns the blockchains created by CreateBlockchains, bootstrapped
+// by all the running nodes, but the ones set otherwise with SetNodeBootstrapped.
+// Returns network.ErrNoRunningNodes if all the nodes are paused or there are none.
+func (n *Network) Blockchains(context.Context) ([]network.Blockchain, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("Blockchains"); err != nil {
+		return nil, err
+	}
+	nodeNames := []string{}
+	for nodeName, node := range n.nodes {
+		if !node.GetPaused() {
+			nodeNames = append(nodeNames, nodeName)
+		}
+	}
+	if len(nodeNames) == 0 {
+		return nil, network.ErrNoRunningNodes
+	}
+	blockchains := make([]network.Blockchain, 0, len(n.blockchains))
+	for _, blockchain := range n.blockchains {
+		blockchain.NodesBootstrapped = make(map[string]bool, len(nodeNames))
+		for _, nodeName := range nodeNames {
+			blockchain.NodesBootstrapped[nodeName] = !n.notBootstrapped[blockchain.ID][nodeName]
+		}
+		blockchains = append(blockchains, blockchain)
+	}
+	return blockchains, nil
+}
+
+// SetLogLevel sets the log level of all the running nodes
+func (n *Network) SetLogLevel(ctx context.Context, level string) error {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("SetLogLevel"); err != nil {
+		return err
+	}
+	set := false
+	for _, node := range n.nodes {
+		if node.GetPaused() {
+			continue
+		}
+		if err := node.SetLogLevel(ctx, level); err != nil {
+			return err
+		}
+		set = true
+	}
+	if !set {
+		return network.ErrNoRunningNodes
+	}
+	return nil
+}
+
+// Reconcile adds and removes nodes so that the network has the
+// nodes in [desired]. Nodes present on both whose config changed
+// are restarted with the desired config.
+func (n *Network) Reconcile(_ context.Context, desired network.Config) (err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	record := n.startOperation("Reconcile", "")
+	defer n.finishOperation(record, &err)
+
+	if err := n.check("Reconcile"); err != nil {
+		return err
+	}
+	if err := desired.ApplyBeaconPolicy(); err != nil {
+		return err
+	}
+	if desired.NetworkID != 0 && desired.NetworkID != n.networkID {
+		return fmt.Errorf("can't change network ID from %d to %d", n.networkID, desired.NetworkID)
+	}
+	if desired.Labels != nil && !maps.Equal(desired.Labels, n.labels) {
+		return errors.New("can't change the labels of a running network")
+	}
+	desiredNames := map[string]struct{}{}
+	for _, nodeConfig := range desired.NodeConfigs {
+		if nodeConfig.Name == "" {
+			return fmt.Errorf("all node configs must be named for reconciliation")
+		}
+		if nodeConfig.StartDelay > 0 {
+			return fmt.Errorf("node %q: %w", nodeConfig.Name, network.ErrStartDelay)
+		}
+		if _, ok := desiredNames[nodeConfig.Name]; ok {
+			return fmt.Errorf("repeated node name %q", nodeConfig.Name)
+		}
+		desiredNames[nodeConfig.Name] = struct{}{}
+		if err := nodeConfig.Validate(n.networkID); err != nil {
+			return fmt.Errorf("node %q config failed validation: %w", nodeConfig.Name, err)
+		}
+	}
+	if err := n.checkReconcileQuorumGuard(desired, desiredNames); err != nil {
+		return err
+	}
+	// net number of nodes added, as existing nodes are either kept or removed
+	if err := n.checkResources(len(desiredNames) - len(n.nodes)); err != nil {
+		return err
+	}
+	for nodeName := range n.nodes {
+		if _, ok := desiredNames[nodeName]; !ok {
+			if err := n.removeNode(nodeName); err != nil {
+				return err
+			}
+		}
+	}
+	for _, nodeConfig := range desired.NodeConfigs {
+		if node, ok := n.nodes[nodeConfig.Name]; ok {
+			if reflect.DeepEqual(node.GetConfig(), nodeConfig) {
+				continue
+			}
+			if err := n.restartNode(node, nodeConfig); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := n.addNode(nodeConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetHostResources sets the host resources the resource guard of the
+// network config, if any, checks the added nodes against
+func (n *Network) SetHostResources(host network.HostResources) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.hostResources = &host
+}
+
+// Returns an error wrapping network.ErrInsufficientResources if the resource
+// guard and the host resources are set, and the host can't support [numAdded]
+// more running nodes, unless the guard is WarnOnly.
+// Assumes [n.lock] is held.
+func (n *Network) checkResources(numAdded int) error {
+	if n.resourceGuard == nil || n.hostResources == nil || n.resourceGuard.WarnOnly {
+		return nil
+	}
+	numRunning := 0
+	for _, node := range n.nodes {
+		if !node.GetPaused() {
+			numRunning++
+		}
+	}
+	return n.resourceGuard.Check(*n.hostResources, numRunning, numAdded)
+}
+
+// checkReconcileQuorumGuard checks the nodes removed by reconciling to
+// [desired] together with each restarted node, as on the local network.
+// Assumes [n.lock] is held.
+func (n *Network) checkReconcileQuorumGuard(desired network.Config, desiredNames map[string]struct{}) error {
+	if n.maxStoppedStake == 0 {
+		return nil
+	}
+	removed := []*Node{}
+	for nodeName, node := range n.nodes {
+		if _, ok := desiredNames[nodeName]; !ok {
+			removed = append(removed, node)
+		}
+	}
+	restarted := []*Node{}
+	for _, nodeConfig := range desired.NodeConfigs {
+		if node, ok := n.nodes[nodeConfig.Name]; ok && !reflect.DeepEqual(node.GetConfig(), nodeConfig) {
+			restarted = append(restarted, node)
+		}
+	}
+	if len(restarted) == 0 {
+		return n.checkQuorumGuard(removed...)
+	}
+	for _, node := range restarted {
+		if err := n.checkQuorumGuard(append(slices.Clip(removed), node)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Assumes [n.lock] is held.
+func (n *Network) runNodeStartHooks(nodeConfig node.Config) error {
+	for _, hooks := range n.nodeHooks {
+		if hooks.OnBeforeNodeStart != nil {
+			if err := hooks.OnBeforeNodeStart(nodeConfig); err != nil {
+				return fmt.Errorf("node %q start aborted by hook: %w", nodeConfig.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// restartNode stops [node], if not paused, and starts it again with [nodeConfig],
+// calling the node hooks as the local network does.
+// If a start hook fails, the node is removed from the network.
+// Assumes [n.lock] is held.
+func (n *Network) restartNode(node *Node, nodeConfig node.Config) error {
+	n.stopNode(node)
+	if err := n.runNodeStartHooks(nodeConfig); err != nil {
+		delete(n.nodes, node.name)
+		n.notifyChange()
+		return err
+	}
+	node.lock.Lock()
+	node.config = nodeConfig
+	node.paused = false
+	node.status = status.Running
+	node.startTime = time.Now()
+	node.crashReport = nil
+	// healthy hooks are called again for the new process
+	node.onHealthyOnce = sync.Once{}
+	node.lock.Unlock()
+	n.notifyChange()
+	return nil
+}
+
+// RollingRestart restarts the nodes in batches as on the local network,
+// waiting for the network to be healthy, as scripted with SetNodeHealthy,
+// after each batch
+func (n *Network) RollingRestart(ctx context.Context, opts network.RollingRestartOptions) (err error) {
+	record := n.startOperation("RollingRestart", "")
+	defer n.finishOperation(record, &err)
+
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	n.lock.RLock()
+	if err := n.check("RollingRestart"); err != nil {
+		n.lock.RUnlock()
+		return err
+	}
+	nodeConfigs := []node.Config{}
+	for _, node := range n.nodes {
+		if !node.GetPaused() {
+			nodeConfigs = append(nodeConfigs, node.GetConfig())
+		}
+	}
+	n.lock.RUnlock()
+
+	for _, batch := range opts.Batches(nodeConfigs) {
+		if err := n.restartBatch(batch, opts); err != nil {
+			return err
+		}
+		if err := n.healthyWithin(ctx, opts.HealthTimeout); err != nil {
+			return fmt.Errorf("network not healthy after restarting %v: %w", batch, err)
+		}
+	}
+	return nil
+}
+
+// Waits for the network to be healthy, for at most [timeout] if positive
+func (n *Network) healthyWithin(ctx context.Context, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return n.Healthy(ctx)
+}
+
+// Restarts the nodes of [batch] with the binary and plugin dir of [opts],
+// if the quorum guard allows stopping them all
+func (n *Network) restartBatch(batch []string, opts network.RollingRestartOptions) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if err := n.check("RollingRestart"); err != nil {
+		return err
+	}
+	nodes := make([]*Node, 0, len(batch))
+	for _, nodeName := range batch {
+		node, ok := n.nodes[nodeName]
+		if !ok {
+			return &network.NodeError{NodeName: nodeName, Op: "restart", Err: network.ErrNodeNotFound}
+		}
+		if node.GetPaused() {
+			return &network.NodeError{NodeName: nodeName, Op: "restart", Err: errNodePaused}
+		}
+		nodes = append(nodes, node)
+	}
+	var err error
+	if opts.MaxStoppedStake > 0 {
+		err = n.checkStoppedStake(opts.MaxStoppedStake, nodes...)
+	} else {
+		err = n.checkQuorumGuard(nodes...)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't restart %v: %w", batch, err)
+	}
+	for _, node := range nodes {
+		nodeConfig := node.GetConfig()
+		nodeConfig.Flags = maps.Clone(nodeConfig.Flags)
+		if nodeConfig.Flags == nil {
+			nodeConfig.Flags = map[string]interface{}{}
+		}
+		if opts.BinaryPath != "" {
+			nodeConfig.BinaryPath = opts.BinaryPath
+		}
+		if opts.PluginDir != "" {
+			nodeConfig.Flags[config.PluginDirKey] = opts.PluginDir
+		}
+		if err := n.restartNode(node, nodeConfig); err != nil {
+			return &network.NodeError{NodeName: node.name, Op: "restart", Err: err}
+		}
+	}
+	return nil
+}
+
+// GetElasticSubnetID returns the ID given to the subnet on TransformSubnet
+func (n *Network) GetElasticSubnetID(_ context.Context, subnetID ids.ID) (ids.ID, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("GetElasticSubnetID"); err != nil {
+		return ids.Empty, err
+	}
+	elasticSubnetID, ok := n.elasticSubnetIDs[subnetID]
+	if !ok {
+		return ids.Empty, fmt.Errorf("subnet %s is not elastic", subnetID)
+	}
+	return elasticSubnetID, nil
+}
+
+// GetRootDir returns an empty string, as the fake network has no files
+func (*Network) GetRootDir() string {
+	return ""
+}
+
+// GetLogRootDir returns an empty string, as the fake network has no files
+func (*Network) GetLogRootDir() string {
+	return ""
+}
+
+// StartupReport returns when the network and its nodes were started.
+// As there are no processes, the startup phases take no time.
+func (n *Network) StartupReport() (network.StartupReport, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if err := n.check("StartupReport"); err != nil {
+		return network.StartupReport{}, err
+	}
+	report := network.StartupReport{
+		StartTime: n.startTime,
+		Nodes:     make(map[string]network.NodeStartupReport, len(n.nodes)),
+	}
+	for nodeName, node := range n.nodes {
+		report.Nodes[nodeName] = node.startupReport()
+	}
+	return report, nil
+}
+
+// History returns the mutating operations run, as on the local network.
+// The records have no parameters, except the template of AddNodeFromTemplate.
+func (n *Network) History() []network.OperationRecord {
+	n.historyLock.Lock()
+	defer n.historyLock.Unlock()
+
+	return append([]network.OperationRecord(nil), n.history...)
+}
+
+// Returns the record of operation [op] starting now, to be
+// completed and added to the history by [n.finishOperation]
+func (*Network) startOperation(op string, nodeName string) *network.OperationRecord {
+	return &network.OperationRecord{
+		Time:     time.Now(),
+		Op:       op,
+		NodeName: nodeName,
+	}
+}
+
+// Adds [record] to the history, with the error [*errPtr] the operation
+// returned, unless it was rejected because the network was stopped.
+// Meant to be deferred with a pointer to the named error result.
+func (n *Network) finishOperation(record *network.OperationRecord, errPtr *error) {
+	err := *errPtr
+	if errors.Is(err, network.ErrStopped) {
+		return
+	}
+	record.Duration = time.Since(record.Time)
+	if err != nil {
+		record.Err = err.Error()
+	}
+	n.historyLock.Lock()
+	defer n.historyLock.Unlock()
+
+	n.history = append(n.history, *record)
+}
@@ -0,0 +1,840 @@
+package networkfakes
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+)
+
+func TestHealthTransitions(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	})
+	require.NoError(err)
+	require.NoError(net.Healthy(context.Background()))
+
+	require.NoError(net.SetNodeHealthy("node2", false))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.Error(net.Healthy(ctx))
+
+	healthyCh := make(chan error)
+	go func() {
+		healthyCh <- net.Healthy(context.Background())
+	}()
+	require.NoError(net.SetNodeHealthy("node2", true))
+	require.NoError(<-healthyCh)
+
+	require.NoError(net.SetNodeStatus("node1", status.Stopped))
+	require.Error(net.Healthy(context.Background()))
+}
+
+func TestFailOn(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{})
+	require.NoError(err)
+	errTest := errors.New("test error")
+	net.FailOn("AddNode", errTest)
+	_, err = net.AddNode(node.Config{})
+	require.ErrorIs(err, errTest)
+	net.FailOn("AddNode", nil)
+	n, err := net.AddNode(node.Config{})
+	require.NoError(err)
+	require.Equal("node1", n.GetName())
+	require.Equal(n.GetAPIPort(), n.APIPort)
+	require.NoError(n.Await(context.Background()))
+}
+
+func TestStopAndSnapshot(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}},
+	})
+	require.NoError(err)
+	_, err = net.SaveSnapshot(context.Background(), "snap", "", false)
+	require.NoError(err)
+	_, err = net.GetNodeNames()
+	require.ErrorIs(err, network.ErrStopped)
+	snapshotConfig, ok := net.GetSnapshot("snap")
+	require.True(ok)
+	restored, err := NewNetwork(snapshotConfig)
+	require.NoError(err)
+	names, err := restored.GetNodeNames()
+	require.NoError(err)
+	require.Equal([]string{"node1"}, names)
+	require.NoError(restored.Stop(context.Background()))
+	require.ErrorIs(restored.Stop(context.Background()), network.ErrStopped)
+}
+
+func TestAwaitMetric(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}},
+	})
+	require.NoError(err)
+	doneCh := make(chan error)
+	go func() {
+		doneCh <- net.AwaitMetric(context.Background(), "accepted", func(v float64) bool { return v > 10 })
+	}()
+	net.SetMetric("accepted", 5)
+	net.SetMetric("accepted", 11)
+	require.NoError(<-doneCh)
+
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	require.ErrorIs(net.AwaitMetric(context.Background(), "accepted", func(float64) bool { return true }), network.ErrNoRunningNodes)
+}
+
+func TestVersions(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	})
+	require.NoError(err)
+	n, err := net.GetNode("node1")
+	require.NoError(err)
+	version := node.Version{Version: "avalanchego/1.11.13"}
+	n.(*Node).SetVersion(version)
+	versions, err := net.Versions(context.Background())
+	require.NoError(err)
+	require.Equal(map[string]node.Version{"node1": version, "node2": {}}, versions)
+
+	require.NoError(net.PauseNode(context.Background(), "node2"))
+	versions, err = net.Versions(context.Background())
+	require.NoError(err)
+	require.Equal(map[string]node.Version{"node1": version}, versions)
+}
+
+func TestBlockchains(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	})
+	require.NoError(err)
+	blockchains, err := net.Blockchains(context.Background())
+	require.NoError(err)
+	require.Empty(blockchains)
+
+	subnetID := ids.GenerateTestID().String()
+	chains, err := net.CreateBlockchains(context.Background(), []network.BlockchainSpec{
+		{VMName: "myvm", SubnetID: &subnetID},
+	})
+	require.NoError(err)
+	require.NoError(net.SetNodeBootstrapped("node2", chains[0].ID, false))
+	blockchains, err = net.Blockchains(context.Background())
+	require.NoError(err)
+	require.Len(blockchains, 1)
+	require.Equal(chains[0].ID, blockchains[0].ID)
+	require.Equal("myvm", blockchains[0].Name)
+	require.Equal(subnetID, blockchains[0].SubnetID.String())
+	require.Equal(map[string]bool{"node1": true, "node2": false}, blockchains[0].NodesBootstrapped)
+	require.False(blockchains[0].Bootstrapped())
+
+	require.NoError(net.PauseNode(context.Background(), "node2"))
+	blockchains, err = net.Blockchains(context.Background())
+	require.NoError(err)
+	require.Equal(map[string]bool{"node1": true}, blockchains[0].NodesBootstrapped)
+	require.True(blockchains[0].Bootstrapped())
+	require.ErrorIs(net.SetNodeBootstrapped("missing", chains[0].ID, true), network.ErrNodeNotFound)
+}
+
+func TestSetLogLevel(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	})
+	require.NoError(err)
+	require.NoError(net.PauseNode(context.Background(), "node2"))
+	require.Error(net.SetLogLevel(context.Background(), "loud"))
+	require.NoError(net.SetLogLevel(context.Background(), "debug"))
+	n1, err := net.GetNode("node1")
+	require.NoError(err)
+	require.Equal("debug", n1.(*Node).LogLevel())
+	n2, err := net.GetNode("node2")
+	require.NoError(err)
+	require.Empty(n2.(*Node).LogLevel())
+}
+
+func TestNodeMaintenance(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	})
+	require.NoError(err)
+	require.NoError(net.SetNodeHealthy("node2", false))
+	require.ErrorIs(net.SetNodeMaintenance("node3", true), network.ErrNodeNotFound)
+	require.NoError(net.SetNodeMaintenance("node2", true))
+	nodeNames, err := net.NodesInMaintenance()
+	require.NoError(err)
+	require.Equal([]string{"node2"}, nodeNames)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(net.Healthy(ctx))
+
+	require.NoError(net.SetNodeMaintenance("node2", false))
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(net.Healthy(ctx), context.DeadlineExceeded)
+
+	require.NoError(net.SetNodeMaintenance("node2", true))
+	require.NoError(net.RemoveNode(context.Background(), "node2"))
+	nodeNames, err = net.NodesInMaintenance()
+	require.NoError(err)
+	require.Empty(nodeNames)
+}
+
+func TestFund(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{NodeConfigs: []node.Config{{Name: "node1"}}})
+	require.NoError(err)
+	_, err = net.Fund(context.Background(), "P-custom1abc", 2, network.PChain)
+	require.NoError(err)
+	_, err = net.Fund(context.Background(), "P-custom1abc", 3, network.PChain)
+	require.NoError(err)
+	require.Equal(uint64(5), net.Funded("P-custom1abc", network.PChain))
+	require.Zero(net.Funded("P-custom1abc", network.XChain))
+	_, err = net.Fund(context.Background(), "P-custom1abc", 1, "D")
+	require.ErrorContains(err, "unknown chain")
+
+	require.NoError(net.Stop(context.Background()))
+	_, err = net.Fund(context.Background(), "P-custom1abc", 1, network.PChain)
+	require.ErrorIs(err, network.ErrStopped)
+}
+
+func TestQuorumStatus(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}, {Name: "node3"}, {Name: "node4"}},
+	})
+	require.NoError(err)
+	quorum, err := net.QuorumStatus(context.Background())
+	require.NoError(err)
+	require.Equal(uint64(4), quorum.OnlineStake)
+	require.True(quorum.HasQuorum())
+
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	require.NoError(net.SetNodeHealthy("node2", false))
+	quorum, err = net.QuorumStatus(context.Background())
+	require.NoError(err)
+	require.Equal(uint64(4), quorum.TotalStake)
+	require.Equal(uint64(2), quorum.OfflineStake)
+	require.ElementsMatch([]string{"node1", "node2"}, maps.Values(quorum.Offline))
+	require.False(quorum.HasQuorum())
+}
+
+func TestToConfig(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node2"}, {Name: "node1"}},
+	})
+	require.NoError(err)
+	require.NoError(net.RemoveNode(context.Background(), "node1"))
+	_, err = net.AddNode(node.Config{Name: "added", DependsOn: []string{"node1", "node2"}})
+	require.NoError(err)
+	networkConfig, err := net.ToConfig()
+	require.NoError(err)
+	require.Equal([]node.Config{
+		{Name: "added", DependsOn: []string{"node2"}},
+		{Name: "node2"},
+	}, networkConfig.NodeConfigs)
+}
+
+func TestWait(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	})
+	require.NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(net.Wait(ctx), context.DeadlineExceeded)
+
+	require.NoError(net.PauseNode(context.Background(), "node2"))
+	require.NoError(net.SetNodeStatus("node1", status.Stopped))
+	require.ErrorIs(net.Wait(context.Background()), network.ErrNodesExited)
+
+	require.NoError(net.ResumeNode(context.Background(), "node2"))
+	waitCh := make(chan error)
+	go func() {
+		waitCh <- net.Wait(context.Background())
+	}()
+	require.NoError(net.Stop(context.Background()))
+	require.NoError(<-waitCh)
+}
+
+func TestReconcile(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	})
+	require.NoError(err)
+	var started, stopped []string
+	net.RegisterNodeHooks(network.NodeHooks{
+		OnBeforeNodeStart: func(nodeConfig node.Config) error {
+			started = append(started, nodeConfig.Name)
+			return nil
+		},
+		OnNodeStop: func(node node.Node) {
+			stopped = append(stopped, node.GetName())
+		},
+	})
+	require.Error(net.Reconcile(context.Background(), network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node1"}},
+	}))
+	require.Error(net.Reconcile(context.Background(), network.Config{
+		NodeConfigs: []node.Config{{Name: "node1", StartDelay: -time.Second}},
+	}))
+
+	require.NoError(net.Reconcile(context.Background(), network.Config{
+		NodeConfigs: []node.Config{
+			{Name: "node1"},
+			{Name: "node3"},
+		},
+	}))
+	require.Equal([]string{"node3"}, started)
+	require.Equal([]string{"node2"}, stopped)
+
+	require.NoError(net.Reconcile(context.Background(), network.Config{
+		NodeConfigs: []node.Config{
+			{Name: "node1", Flags: map[string]interface{}{"log-level": "debug"}},
+			{Name: "node3"},
+		},
+	}))
+	require.Equal([]string{"node3", "node1"}, started)
+	require.Equal([]string{"node2", "node1"}, stopped)
+	names, err := net.GetNodeNames()
+	require.NoError(err)
+	require.ElementsMatch([]string{"node1", "node3"}, names)
+}
+
+func TestBlockchainAndVMAliases(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{})
+	require.NoError(err)
+	chains, err := net.CreateBlockchains(context.Background(), []network.BlockchainSpec{
+		{VMName: "myvm", BlockchainAlias: "mychain"},
+	})
+	require.NoError(err)
+	require.Len(chains, 1)
+	require.Equal([]string{"mychain"}, chains[0].Aliases)
+	aliases, err := net.GetBlockchainAliases(chains[0].ID)
+	require.NoError(err)
+	require.Equal([]string{"mychain"}, aliases)
+
+	require.NoError(net.AliasVM(context.Background(), chains[0].VMID, "vm"))
+	require.NoError(net.AliasVM(context.Background(), chains[0].VMID, "vm"))
+	require.Error(net.AliasVM(context.Background(), ids.GenerateTestID(), "vm"))
+	aliases, err = net.GetVMAliases(chains[0].VMID)
+	require.NoError(err)
+	require.Equal([]string{"vm"}, aliases)
+}
+
+func TestNodeHooks(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	})
+	require.NoError(err)
+	var (
+		lock                      sync.Mutex
+		started, healthy, stopped []string
+	)
+	record := func(names *[]string, name string) {
+		lock.Lock()
+		defer lock.Unlock()
+		*names = append(*names, name)
+	}
+	recorded := func(names *[]string) []string {
+		lock.Lock()
+		defer lock.Unlock()
+		sorted := slices.Clone(*names)
+		slices.Sort(sorted)
+		*names = nil
+		return sorted
+	}
+	errHook := errors.New("rejected")
+	net.RegisterNodeHooks(network.NodeHooks{
+		OnBeforeNodeStart: func(nodeConfig node.Config) error {
+			if nodeConfig.Name == "rejected" {
+				return errHook
+			}
+			record(&started, nodeConfig.Name)
+			return nil
+		},
+		OnAfterNodeHealthy: func(node node.Node) { record(&healthy, node.GetName()) },
+		OnNodeStop:         func(node node.Node) { record(&stopped, node.GetName()) },
+	})
+
+	// healthy nodes are reported even if others are not
+	require.NoError(net.SetNodeHealthy("node2", false))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.Error(net.Healthy(ctx))
+	require.Equal([]string{"node1"}, recorded(&healthy))
+	require.NoError(net.SetNodeHealthy("node2", true))
+	require.NoError(net.Healthy(context.Background()))
+	require.NoError(net.Healthy(context.Background()))
+	require.Equal([]string{"node2"}, recorded(&healthy))
+
+	// resumed and restarted nodes run all the hooks again
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	require.NoError(net.ResumeNode(context.Background(), "node1"))
+	require.NoError(net.RestartNode(context.Background(), "node2", "", "", "", nil, nil, nil))
+	require.NoError(net.Healthy(context.Background()))
+	require.Equal([]string{"node1", "node2"}, recorded(&started))
+	require.Equal([]string{"node1", "node2"}, recorded(&stopped))
+	require.Equal([]string{"node1", "node2"}, recorded(&healthy))
+
+	_, err = net.AddNode(node.Config{Name: "rejected"})
+	require.ErrorIs(err, errHook)
+
+	// saving a snapshot stops the nodes
+	_, err = net.SaveSnapshot(context.Background(), "hooks", "", false)
+	require.NoError(err)
+	require.Equal([]string{"node1", "node2"}, recorded(&stopped))
+}
+
+func TestNodeStopStart(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}},
+	})
+	require.NoError(err)
+	n, err := net.GetNode("node1")
+	require.NoError(err)
+
+	require.NoError(n.Stop(context.Background()))
+	require.True(n.GetPaused())
+	require.Error(n.Stop(context.Background()))
+	require.NoError(n.Start(context.Background()))
+	require.False(n.GetPaused())
+	require.Error(n.Start(context.Background()))
+
+	require.NoError(net.RemoveNode(context.Background(), "node1"))
+	err = n.Stop(context.Background())
+	require.ErrorIs(err, network.ErrNodeNotFound)
+	var nodeErr *network.NodeError
+	require.ErrorAs(err, &nodeErr)
+	require.Equal("node1", nodeErr.NodeName)
+	require.Equal("pause", nodeErr.Op)
+
+	// not even once another node is added with the same name
+	added, err := net.AddNode(node.Config{Name: "node1"})
+	require.NoError(err)
+	require.ErrorIs(n.Stop(context.Background()), network.ErrNodeNotFound)
+	require.False(added.GetPaused())
+}
+
+func TestAwaitNodeHealthy(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	})
+	require.NoError(err)
+	require.NoError(net.SetNodeHealthy("node2", false))
+	require.NoError(net.AwaitNodeHealthy(context.Background(), "node1"))
+	require.ErrorIs(net.AwaitNodeHealthy(context.Background(), "node3"), network.ErrNodeNotFound)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(net.AwaitNodeHealthy(ctx, "node2"), context.DeadlineExceeded)
+
+	n, err := net.GetNode("node2")
+	require.NoError(err)
+	healthyCh := make(chan error)
+	go func() {
+		healthyCh <- n.AwaitHealthy(context.Background())
+	}()
+	require.NoError(net.SetNodeHealthy("node2", true))
+	require.NoError(<-healthyCh)
+
+	require.NoError(net.SetNodeHealthy("node2", false))
+	go func() {
+		healthyCh <- n.AwaitHealthy(context.Background())
+	}()
+	require.NoError(net.RemoveNode(context.Background(), "node2"))
+	require.ErrorIs(<-healthyCh, network.ErrNodeNotFound)
+}
+
+func TestRemoveNodeWithOptions(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}, {Name: "node3"}, {Name: "node4"}},
+	})
+	require.NoError(err)
+	require.NoError(net.RemoveNodeWithOptions(context.Background(), "node1", network.RemoveNodeOptions{}))
+	require.ErrorIs(net.RemoveNodeWithOptions(context.Background(), "node2", network.RemoveNodeOptions{}), network.ErrQuorumLoss)
+	require.NoError(net.RemoveNodeWithOptions(context.Background(), "node2", network.RemoveNodeOptions{Force: true}))
+}
+
+func TestQuorumGuard(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}, {Name: "node3"}},
+	})
+	require.NoError(err)
+	require.Error(net.SetQuorumGuard(-1))
+	require.NoError(net.SetQuorumGuard(0.5))
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	require.ErrorIs(net.PauseNode(context.Background(), "node2"), network.ErrQuorumLoss)
+	require.ErrorIs(net.RestartNode(context.Background(), "node2", "", "", "", nil, nil, nil), network.ErrQuorumLoss)
+	require.ErrorIs(net.RemoveNode(context.Background(), "node2"), network.ErrQuorumLoss)
+	require.ErrorIs(net.Reconcile(context.Background(), network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}},
+	}), network.ErrQuorumLoss)
+	names, err := net.GetNodeNames()
+	require.NoError(err)
+	require.Len(names, 3)
+	require.NoError(net.RemoveNodeWithOptions(context.Background(), "node2", network.RemoveNodeOptions{Force: true}))
+}
+
+func TestWatchHealth(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}, {Name: "node3"}},
+	})
+	require.NoError(err)
+	require.NoError(net.SetNodeHealthy("node2", false))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var alarms []network.HealthAlarm
+	require.NoError(net.WatchHealth(ctx, network.HealthWatchConfig{
+		Interval: time.Millisecond,
+		Debounce: 10 * time.Millisecond,
+		OnAlarm: func(alarm network.HealthAlarm) {
+			alarms = append(alarms, alarm)
+			if alarm.Err != nil {
+				require.NoError(net.SetNodeHealthy("node2", true))
+			} else {
+				cancel()
+			}
+		},
+	}))
+	require.Len(alarms, 2)
+	require.Equal("node2", alarms[0].NodeName)
+	require.ErrorIs(alarms[0].Err, network.ErrNodeUnhealthy)
+	require.Equal("node2", alarms[1].NodeName)
+	require.NoError(alarms[1].Err)
+
+	// pausing a node leaves the others with a single peer
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	alarms = nil
+	require.NoError(net.WatchHealth(ctx, network.HealthWatchConfig{
+		MinPeers: 2,
+		OnAlarm: func(alarm network.HealthAlarm) {
+			alarms = append(alarms, alarm)
+			if len(alarms) == 2 {
+				cancel()
+			}
+		},
+	}))
+	require.Len(alarms, 2)
+	require.Equal("node2", alarms[0].NodeName)
+	require.Equal("node3", alarms[1].NodeName)
+	require.ErrorIs(alarms[1].Err, network.ErrNotEnoughPeers)
+}
+
+func TestCrashNode(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	})
+	require.NoError(err)
+	var crashed []node.CrashReport
+	net.RegisterNodeHooks(network.NodeHooks{
+		OnNodeCrash: func(n node.Node, crashReport node.CrashReport) {
+			crashed = append(crashed, crashReport)
+			// crash hooks may call back into the network
+			require.Equal(status.Stopped, n.Status())
+			_, err := net.GetNode(n.GetName())
+			require.NoError(err)
+		},
+	})
+	require.ErrorIs(net.CrashNode("node3", node.CrashReport{}), network.ErrNodeNotFound)
+	require.NoError(net.CrashNode("node1", node.CrashReport{ExitCode: 2, PanicTrace: "panic: boom\n"}))
+	require.Len(crashed, 1)
+	require.Equal("node1", crashed[0].NodeName)
+	require.Equal(2, crashed[0].ExitCode)
+	require.False(crashed[0].Time.IsZero())
+
+	node1, err := net.GetNode("node1")
+	require.NoError(err)
+	require.Equal(&crashed[0], node1.CrashReport())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var alarm network.HealthAlarm
+	require.NoError(net.WatchHealth(ctx, network.HealthWatchConfig{
+		OnAlarm: func(a network.HealthAlarm) {
+			alarm = a
+			cancel()
+		},
+	}))
+	require.Equal("node1", alarm.NodeName)
+	require.Equal(&crashed[0], alarm.Crash)
+
+	// a restarted node has no crash report
+	require.NoError(net.RestartNode(context.Background(), "node1", "", "", "", nil, nil, nil))
+	require.Nil(node1.CrashReport())
+}
+
+func TestSetNodeDiskChaos(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "limited", DBDirSizeLimit: 1024 * 1024}},
+	})
+	require.NoError(err)
+	require.ErrorIs(net.SetNodeDiskChaos(context.Background(), "node2", network.DiskChaos{}), network.ErrNodeNotFound)
+	require.Error(net.SetNodeDiskChaos(context.Background(), "node1", network.DiskChaos{FillDisk: true}))
+
+	chaos := network.DiskChaos{FillDisk: true, FreeBytes: 1024}
+	require.NoError(net.SetNodeDiskChaos(context.Background(), "limited", chaos))
+	got, err := net.GetNodeDiskChaos("limited")
+	require.NoError(err)
+	require.Equal(chaos, got)
+	require.NoError(net.SetNodeDiskChaos(context.Background(), "node1", network.DiskChaos{WriteBytesPerSec: 1024}))
+
+	// the throttling is lost when the node process is stopped, the disk fill is kept
+	require.NoError(net.RestartNode(context.Background(), "node1", "", "", "", nil, nil, nil))
+	require.NoError(net.RestartNode(context.Background(), "limited", "", "", "", nil, nil, nil))
+	got, err = net.GetNodeDiskChaos("node1")
+	require.NoError(err)
+	require.True(got.IsZero())
+	got, err = net.GetNodeDiskChaos("limited")
+	require.NoError(err)
+	require.Equal(chaos, got)
+}
+
+func TestSetLinkBandwidth(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}, {Name: "node3"}},
+	})
+	require.NoError(err)
+	require.ErrorIs(net.SetLinkBandwidth(context.Background(), "node1", "node4", 1), network.ErrNodeNotFound)
+	require.ErrorIs(net.SetLinkBandwidth(context.Background(), "node1", "node1", 1), errSameNodeLink)
+
+	require.NoError(net.SetLinkBandwidth(context.Background(), "node3", "node1", 1_000_000))
+	require.NoError(net.SetLinkBandwidth(context.Background(), "node1", "node2", 2_000_000))
+	require.Equal(uint64(1_000_000), net.GetLinkBandwidth("node1", "node3"))
+	require.NoError(net.SetLinkBandwidth(context.Background(), "node1", "node2", 0))
+	require.Zero(net.GetLinkBandwidth("node2", "node1"))
+
+	// kept on restart, removed with the node
+	require.NoError(net.RestartNode(context.Background(), "node3", "", "", "", nil, nil, nil))
+	require.Equal(uint64(1_000_000), net.GetLinkBandwidth("node1", "node3"))
+	require.NoError(net.RemoveNode(context.Background(), "node3"))
+	require.Zero(net.GetLinkBandwidth("node1", "node3"))
+}
+
+func TestAddNodeFromTemplate(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1", BinaryPath: "/bin/avalanchego", Flags: map[string]interface{}{"log-level": "debug"}}},
+	})
+	require.NoError(err)
+	added, err := net.AddNodeFromTemplate("node1", network.NodeOverrides{Name: "node2"})
+	require.NoError(err)
+	require.Equal("node2", added.GetName())
+	require.Equal("/bin/avalanchego", added.GetBinaryPath())
+	require.Equal("debug", added.GetConfig().Flags["log-level"])
+	_, err = net.AddNodeFromTemplate("node3", network.NodeOverrides{})
+	require.ErrorIs(err, network.ErrNodeNotFound)
+}
+
+func TestStaticValidators(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs:      []node.Config{{Name: "node1"}},
+		StaticValidators: true,
+	})
+	require.NoError(err)
+	_, err = net.CreateSubnets(context.Background(), []network.SubnetSpec{{}})
+	require.ErrorIs(err, network.ErrStaticValidators)
+	require.ErrorIs(net.AddPermissionlessValidators(context.Background(), nil), network.ErrStaticValidators)
+	toConfig, err := net.ToConfig()
+	require.NoError(err)
+	require.True(toConfig.StaticValidators)
+}
+
+func TestGetNodeNamesWithFilter(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{
+			{Name: "node2", IsBeacon: true},
+			{Name: "node1", IsBeacon: true},
+			{Name: "api", Labels: map[string]string{"role": "api"}},
+		},
+	})
+	require.NoError(err)
+	names, err := net.GetNodeNames()
+	require.NoError(err)
+	require.Equal([]string{"api", "node1", "node2"}, names)
+	require.NoError(net.SetNodeHealthy("node2", false))
+	names, err = net.GetNodeNamesWithFilter(context.Background(), network.NodeFilter{Beacons: true, Healthy: true})
+	require.NoError(err)
+	require.Equal([]string{"node1"}, names)
+	names, err = net.GetNodeNamesWithFilter(context.Background(), network.NodeFilter{Labels: map[string]string{"role": "api"}})
+	require.NoError(err)
+	require.Equal([]string{"api"}, names)
+}
+
+func TestNodeSignal(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{NodeConfigs: []node.Config{{Name: "node1"}}})
+	require.NoError(err)
+	n, err := net.GetNode("node1")
+	require.NoError(err)
+	require.NoError(n.Signal(syscall.SIGHUP))
+	require.NoError(n.Signal(os.Interrupt))
+	require.Equal([]os.Signal{syscall.SIGHUP, os.Interrupt}, n.(*Node).Signals())
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	require.ErrorIs(n.Signal(syscall.SIGHUP), errNodeNotRunning)
+}
+
+func TestHistory(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{NodeConfigs: []node.Config{{Name: "node1"}}})
+	require.NoError(err)
+	_, err = net.AddNodeFromTemplate("node1", network.NodeOverrides{})
+	require.NoError(err)
+	errScripted := errors.New("scripted")
+	net.FailOn("RestartNode", errScripted)
+	require.ErrorIs(net.RestartNode(context.Background(), "node1", "", "", "", nil, nil, nil), errScripted)
+	require.NoError(net.RemoveNode(context.Background(), "node1"))
+	require.NoError(net.Stop(context.Background()))
+	_, err = net.AddNode(node.Config{})
+	require.ErrorIs(err, network.ErrStopped)
+
+	history := net.History()
+	require.Len(history, 4)
+	require.Equal("AddNodeFromTemplate", history[0].Op)
+	require.Equal("node2", history[0].NodeName)
+	require.Equal(map[string]string{"template": "node1"}, history[0].Params)
+	require.Equal("RestartNode", history[1].Op)
+	require.Equal(errScripted.Error(), history[1].Err)
+	require.Equal("RemoveNode", history[2].Op)
+	require.Equal("node1", history[2].NodeName)
+	require.Equal("Stop", history[3].Op)
+}
+
+func TestStartupReport(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}}})
+	require.NoError(err)
+	require.NoError(net.SetNodeHealthy("node2", false))
+
+	report, err := net.StartupReport()
+	require.NoError(err)
+	require.False(report.StartTime.IsZero())
+	require.Len(report.Nodes["node1"].Phases, len(network.StartupPhases))
+	require.NotContains(report.Nodes["node2"].Phases, network.StartupPhaseBootstrapped)
+
+	require.NoError(net.Stop(context.Background()))
+	_, err = net.StartupReport()
+	require.ErrorIs(err, network.ErrStopped)
+}
+
+func TestRollingRestart(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{NodeConfigs: []node.Config{{Name: "node1", IsBeacon: true}, {Name: "node2"}, {Name: "node3"}}})
+	require.NoError(err)
+
+	err = net.RollingRestart(context.Background(), network.RollingRestartOptions{BatchSize: 2, MaxStoppedStake: 0.5})
+	require.ErrorIs(err, network.ErrQuorumLoss)
+
+	require.NoError(net.RollingRestart(context.Background(), network.RollingRestartOptions{BinaryPath: "/bin/new"}))
+	for _, nodeName := range []string{"node1", "node2", "node3"} {
+		n, err := net.GetNode(nodeName)
+		require.NoError(err)
+		require.Equal("/bin/new", n.GetBinaryPath())
+	}
+
+	require.NoError(net.SetNodeHealthy("node3", false))
+	err = net.RollingRestart(context.Background(), network.RollingRestartOptions{HealthTimeout: 10 * time.Millisecond})
+	require.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func TestObserverNodes(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1", IsBeacon: true}, {Name: "api", Observer: true}},
+	})
+	require.NoError(err)
+	names, err := net.GetNodeNamesWithFilter(context.Background(), network.NodeFilter{Validators: true})
+	require.NoError(err)
+	require.Equal([]string{"node1"}, names)
+	_, err = net.CreateSubnets(context.Background(), []network.SubnetSpec{{Participants: []string{"node1"}}})
+	require.NoError(err)
+	_, err = net.CreateSubnets(context.Background(), []network.SubnetSpec{{Participants: []string{"node1", "api"}}})
+	require.ErrorIs(err, network.ErrObserverNode)
+	var nodeErr *network.NodeError
+	require.ErrorAs(err, &nodeErr)
+	require.Equal("api", nodeErr.NodeName)
+	err = net.AddSubnetValidators(context.Background(), []network.SubnetValidatorsSpec{{NodeNames: []string{"api"}}})
+	require.ErrorIs(err, network.ErrObserverNode)
+	err = net.AddPermissionlessValidators(context.Background(), []network.PermissionlessStakerSpec{{NodeName: "api"}})
+	require.ErrorIs(err, network.ErrObserverNode)
+}
+
+func TestNodeRecentLogs(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{NodeConfigs: []node.Config{{Name: "node1"}}})
+	require.NoError(err)
+	n, err := net.GetNode("node1")
+	require.NoError(err)
+	require.Empty(n.RecentLogs(10))
+	n.(*Node).WriteLogs("line 1", "line 2", "line 3")
+	require.Equal([]string{"line 2", "line 3"}, n.RecentLogs(2))
+	require.Equal([]string{"line 1", "line 2", "line 3"}, n.RecentLogs(0))
+}
+
+func TestTrackSubnet(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{NodeConfigs: []node.Config{{Name: "node1"}}})
+	require.NoError(err)
+	n, err := net.GetNode("node1")
+	require.NoError(err)
+	subnetID := ids.GenerateTestID()
+	require.NoError(n.TrackSubnet(context.Background(), subnetID))
+	tracked, err := node.TrackedSubnets(n.GetConfig().Flags)
+	require.NoError(err)
+	require.Equal([]string{subnetID.String()}, tracked)
+	require.NoError(n.UntrackSubnet(context.Background(), subnetID))
+	tracked, err = node.TrackedSubnets(n.GetConfig().Flags)
+	require.NoError(err)
+	require.Empty(tracked)
+	require.NoError(net.Stop(context.Background()))
+	require.ErrorIs(n.TrackSubnet(context.Background(), subnetID), network.ErrStopped)
+}
+
+func TestAddNodeResourceGuard(t *testing.T) {
+	require := require.New(t)
+	net, err := NewNetwork(network.Config{
+		NodeConfigs:   []node.Config{{Name: "node1"}},
+		ResourceGuard: &network.ResourceGuard{},
+	})
+	require.NoError(err)
+	// unlimited until the host resources are set
+	_, err = net.AddNode(node.Config{Name: "node2"})
+	require.NoError(err)
+	net.SetHostResources(network.HostResources{AvailableMemory: network.DefaultNodeMemory, CPUs: 1})
+	_, err = net.AddNode(node.Config{Name: "node3"})
+	require.ErrorIs(err, network.ErrInsufficientResources)
+	err = net.Reconcile(context.Background(), network.Config{NodeConfigs: []node.Config{{Name: "node3"}, {Name: "node4"}}})
+	require.NoError(err)
+}
@@ -0,0 +1,453 @@
+package networkfakes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/api"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/peer"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
+	"github.com/ava-labs/avalanchego/snow/networking/router"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+var (
+	_ node.Node = (*Node)(nil)
+
+	ErrNotSupported = errors.New("not supported by the fake network")
+)
+
+// Node is an in-memory node.Node, with no process behind it
+type Node struct {
+	lock    sync.RWMutex
+	name    string
+	nodeID  ids.NodeID
+	apiPort uint16
+	p2pPort uint16
+	config  node.Config
+	client  api.Client
+	status  status.Status
+	healthy bool
+	paused  bool
+	// time at which the node was last started, resumed or restarted
+	startTime time.Time
+	// used to call the healthy hooks only the first time the node is seen healthy
+	onHealthyOnce sync.Once
+	// keystore user name to imported keys
+	keystoreUsers map[string][]*secp256k1.PrivateKey
+	// returned by GetVersion
+	version node.Version
+	// returned by ConsensusParameters
+	consensusParameters snowball.Parameters
+	// set by SetLogLevel
+	logLevel string
+	// set by Network.CrashNode, until the node is started again
+	crashReport *node.CrashReport
+	// sent by Signal
+	signals []os.Signal
+	// written by WriteLogs, returned by RecentLogs
+	logs []string
+	// the network the node belongs to, used to stop and start it
+	network *Network
+}
+
+// GetName: see node.Node
+func (n *Node) GetName() string {
+	return n.name
+}
+
+// GetNodeID: see node.Node
+func (n *Node) GetNodeID() ids.NodeID {
+	return n.nodeID
+}
+
+// GetAPIClient returns the client given with SetAPIClient, nil by default
+func (n *Node) GetAPIClient() api.Client {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.client
+}
+
+// SetAPIClient sets the client returned by GetAPIClient, so that tests
+// can provide mocks for the APIs they use
+func (n *Node) SetAPIClient(client api.Client) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.client = client
+}
+
+// GetIP: see node.Node
+func (*Node) GetIP() string {
+	return "127.0.0.1"
+}
+
+// GetP2PPort: see node.Node
+func (n *Node) GetP2PPort() uint16 {
+	return n.p2pPort
+}
+
+// GetAPIPort: see node.Node
+func (n *Node) GetAPIPort() uint16 {
+	return n.apiPort
+}
+
+// GetURI: see node.Node
+func (n *Node) GetURI() string {
+	return fmt.Sprintf("http://%s:%d", n.GetIP(), n.GetAPIPort())
+}
+
+// AttachPeer is not supported by the fake node
+func (*Node) AttachPeer(context.Context, router.InboundHandler) (peer.Peer, error) {
+	return nil, ErrNotSupported
+}
+
+// SendOutboundMessage is not supported by the fake node
+func (*Node) SendOutboundMessage(context.Context, string, []byte, uint32) (bool, error) {
+	return false, ErrNotSupported
+}
+
+// Status: see node.Node
+func (n *Node) Status() status.Status {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.status
+}
+
+// GetBinaryPath: see node.Node
+func (n *Node) GetBinaryPath() string {
+	return n.GetConfig().BinaryPath
+}
+
+// GetDataDir: see node.Node
+func (*Node) GetDataDir() string {
+	return ""
+}
+
+// GetDbDir: see node.Node
+func (*Node) GetDbDir() string { //nolint
+	return ""
+}
+
+// GetLogsDir: see node.Node
+func (*Node) GetLogsDir() string {
+	return ""
+}
+
+// GetPluginDir: see node.Node
+func (*Node) GetPluginDir() string {
+	return ""
+}
+
+// GetConfigFile: see node.Node
+func (n *Node) GetConfigFile() string {
+	return n.GetConfig().ConfigFile
+}
+
+// GetConfig: see node.Node
+func (n *Node) GetConfig() node.Config {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.config
+}
+
+// GetEffectiveConfig returns the node's config flags, formatted as the local
+// network gives them to avalanchego, but none of the network defaults.
+// See node.Node
+func (n *Node) GetEffectiveConfig() node.EffectiveConfig {
+	config := n.GetConfig()
+	flags := make(map[string]string, len(config.Flags))
+	for k, v := range config.Flags {
+		flags[k] = fmt.Sprintf("%v", v)
+	}
+	return node.EffectiveConfig{
+		Name:       n.name,
+		NodeID:     n.nodeID,
+		IsBeacon:   config.IsBeacon,
+		Observer:   config.Observer,
+		SubnetOnly: config.SubnetOnly,
+		BinaryPath: config.BinaryPath,
+		IP:         n.GetIP(),
+		APIPort:    n.apiPort,
+		P2PPort:    n.p2pPort,
+		Flags:      node.RedactFlags(flags),
+	}
+}
+
+// GetFlag: see node.Node
+func (n *Node) GetFlag(k string) (string, error) {
+	v, ok := n.GetConfig().Flags[k]
+	if !ok {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected type for %q expected string got %T", k, v)
+	}
+	return s, nil
+}
+
+// GetPaused: see node.Node
+func (n *Node) GetPaused() bool {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.paused
+}
+
+// StartTime: see node.Node
+func (n *Node) StartTime() time.Time {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if n.paused {
+		return time.Time{}
+	}
+	return n.startTime
+}
+
+// Uptime: see node.Node
+func (n *Node) Uptime() time.Duration {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if n.paused || n.status != status.Running {
+		return 0
+	}
+	return time.Since(n.startTime)
+}
+
+// PID returns 0, as fake nodes have no process, see node.Node
+func (n *Node) PID() int {
+	return 0
+}
+
+// Signal records [sig], see node.Node and Signals
+func (n *Node) Signal(sig os.Signal) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.status != status.Running {
+		return errNodeNotRunning
+	}
+	n.signals = append(n.signals, sig)
+	return nil
+}
+
+// Signals returns the signals sent to the node with Signal, in order
+func (n *Node) Signals() []os.Signal {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return slices.Clone(n.signals)
+}
+
+// WriteLogs appends [lines] to the ones returned by RecentLogs,
+// as if the node process wrote them
+func (n *Node) WriteLogs(lines ...string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.logs = append(n.logs, lines...)
+}
+
+// RecentLogs returns the last [count] lines given to WriteLogs, see node.Node
+func (n *Node) RecentLogs(count int) []string {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if count <= 0 || count > len(n.logs) {
+		count = len(n.logs)
+	}
+	return slices.Clone(n.logs[len(n.logs)-count:])
+}
+
+// AwaitHealthy waits for the node on its network, see node.Node
+func (n *Node) AwaitHealthy(ctx context.Context) error {
+	return n.network.awaitNodeHealthy(ctx, n)
+}
+
+// Stop pauses the node on its network, see node.Node
+func (n *Node) Stop(context.Context) error {
+	return n.network.setPaused("PauseNode", n.name, n, true)
+}
+
+// Start resumes the node on its network, see node.Node
+func (n *Node) Start(context.Context) error {
+	return n.network.setPaused("ResumeNode", n.name, n, false)
+}
+
+// TrackSubnet makes the node track [subnetID] on its network, see node.Node
+func (n *Node) TrackSubnet(ctx context.Context, subnetID ids.ID) error {
+	return n.network.setSubnetTracked(ctx, n, subnetID, true)
+}
+
+// UntrackSubnet makes the node stop tracking [subnetID] on its network, see node.Node
+func (n *Node) UntrackSubnet(ctx context.Context, subnetID ids.ID) error {
+	return n.network.setSubnetTracked(ctx, n, subnetID, false)
+}
+
+// Exec is not supported by the fake node
+func (*Node) Exec(context.Context, string, ...string) ([]byte, error) {
+	return nil, ErrNotSupported
+}
+
+// CreateKeystoreUser records the user and its keys, see GetKeystoreUserKeys
+func (n *Node) CreateKeystoreUser(_ context.Context, user string, _ string, privateKeys ...*secp256k1.PrivateKey) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.keystoreUsers == nil {
+		n.keystoreUsers = map[string][]*secp256k1.PrivateKey{}
+	}
+	if _, ok := n.keystoreUsers[user]; ok {
+		return fmt.Errorf("keystore user %q already exists on node %q", user, n.name)
+	}
+	n.keystoreUsers[user] = append([]*secp256k1.PrivateKey{}, privateKeys...)
+	return nil
+}
+
+// GetKeystoreUserKeys returns the keys imported to the keystore user,
+// and false if the user wasn't created
+func (n *Node) GetKeystoreUserKeys(user string) ([]*secp256k1.PrivateKey, bool) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	privateKeys, ok := n.keystoreUsers[user]
+	return privateKeys, ok
+}
+
+// GetVersion returns the version set with SetVersion, the zero version by default
+func (n *Node) GetVersion(context.Context) (node.Version, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.version, nil
+}
+
+// SetLogLevel records [level], returned by LogLevel
+func (n *Node) SetLogLevel(_ context.Context, level string) error {
+	if _, err := logging.ToLevel(level); err != nil {
+		return err
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.status != status.Running {
+		return errNodeNotRunning
+	}
+	n.logLevel = level
+	return nil
+}
+
+// LogLevel returns the level set with SetLogLevel, empty by default
+func (n *Node) LogLevel() string {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.logLevel
+}
+
+// SetVersion sets the version returned by GetVersion,
+// so that tests can check which versions were launched
+func (n *Node) SetVersion(version node.Version) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.version = version
+}
+
+// ConsensusParameters returns the parameters set with
+// SetConsensusParameters, snowball.DefaultParameters by default
+func (n *Node) ConsensusParameters(context.Context) (snowball.Parameters, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if n.status != status.Running {
+		return snowball.Parameters{}, errNodeNotRunning
+	}
+	return n.consensusParameters, nil
+}
+
+// SetConsensusParameters sets the parameters returned by
+// ConsensusParameters, so that tests can give nodes mixed parameters
+func (n *Node) SetConsensusParameters(parameters snowball.Parameters) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.consensusParameters = parameters
+}
+
+// CrashReport returns the report given to Network.CrashNode,
+// until the node is started again
+func (n *Node) CrashReport() *node.CrashReport {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if n.crashReport == nil {
+		return nil
+	}
+	crashReport := *n.crashReport
+	return &crashReport
+}
+
+// Returns the startup report of the node, whose phases take no time.
+// The health phases are only completed once the node is healthy.
+func (n *Node) startupReport() network.NodeStartupReport {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	phases := map[network.StartupPhase]time.Duration{
+		network.StartupPhaseKeyGeneration: 0,
+		network.StartupPhaseDirSetup:      0,
+		network.StartupPhaseProcessStart:  0,
+	}
+	if n.healthy {
+		phases[network.StartupPhaseFirstHealthResponse] = 0
+		phases[network.StartupPhaseBootstrapped] = 0
+	}
+	return network.NodeStartupReport{StartTime: n.startTime, Phases: phases}
+}
+
+func (n *Node) isHealthy() bool {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.healthy
+}
+
+func (n *Node) setPaused(paused bool) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.paused == paused {
+		if paused {
+			return fmt.Errorf("node has been paused already")
+		}
+		return fmt.Errorf("node has not been paused")
+	}
+	n.paused = paused
+	if paused {
+		n.status = status.Stopped
+	} else {
+		n.status = status.Running
+		n.startTime = time.Now()
+		n.crashReport = nil
+		// healthy hooks are called again for the resumed process
+		n.onHealthyOnce = sync.Once{}
+	}
+	return nil
+}
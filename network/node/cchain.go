@@ -0,0 +1,105 @@
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CChainAlias is the key of the C-Chain config in the chain config files
+const CChainAlias = "C"
+
+// CChainOption sets an option of the C-Chain (coreth) config
+type CChainOption func(map[string]interface{})
+
+// WithRPCGasCap sets the max gas of eth_call and eth_estimateGas. 0 is no cap.
+func WithRPCGasCap(gasCap uint64) CChainOption {
+	return WithCorethOption("rpc-gas-cap", gasCap)
+}
+
+// WithRPCTxFeeCap sets the max fee, in AVAX, of the transactions sent
+// through the RPC. 0 is no cap.
+func WithRPCTxFeeCap(feeCap float64) CChainOption {
+	return WithCorethOption("rpc-tx-fee-cap", feeCap)
+}
+
+// WithTxPoolSlots sets the number of executable transaction slots
+// of the tx pool, per account and in total.
+func WithTxPoolSlots(accountSlots uint64, globalSlots uint64) CChainOption {
+	return func(config map[string]interface{}) {
+		config["tx-pool-account-slots"] = accountSlots
+		config["tx-pool-global-slots"] = globalSlots
+	}
+}
+
+// WithTxPoolQueue sets the number of non-executable transaction slots
+// of the tx pool, per account and in total.
+func WithTxPoolQueue(accountQueue uint64, globalQueue uint64) CChainOption {
+	return func(config map[string]interface{}) {
+		config["tx-pool-account-queue"] = accountQueue
+		config["tx-pool-global-queue"] = globalQueue
+	}
+}
+
+// WithEthAPIs sets the eth API namespaces enabled, e.g. "eth", "debug-tracer".
+func WithEthAPIs(apis ...string) CChainOption {
+	return WithCorethOption("eth-apis", apis)
+}
+
+// WithContinuousProfiler enables the continuous profiler, writing a profile
+// to [dir] every [frequency], keeping the last [maxFiles] ones.
+func WithContinuousProfiler(dir string, frequency time.Duration, maxFiles int) CChainOption {
+	return func(config map[string]interface{}) {
+		config["continuous-profiler-dir"] = dir
+		config["continuous-profiler-frequency"] = frequency.String()
+		config["continuous-profiler-max-files"] = maxFiles
+	}
+}
+
+// WithCorethOption sets the coreth option [key], as named in the
+// C-Chain config file, for the options without a helper.
+func WithCorethOption(key string, value interface{}) CChainOption {
+	return func(config map[string]interface{}) {
+		config[key] = value
+	}
+}
+
+// ApplyCChainOptions sets [opts] on the C-Chain config of [chainConfigFiles],
+// keeping its other options, or on a new C-Chain config if there is none,
+// and returns the updated chain config files. [chainConfigFiles] is not modified.
+func ApplyCChainOptions(chainConfigFiles map[string]string, opts ...CChainOption) (map[string]string, error) {
+	config := map[string]interface{}{}
+	if cChainConfig := chainConfigFiles[CChainAlias]; cChainConfig != "" {
+		// numbers are kept as they are given
+		decoder := json.NewDecoder(strings.NewReader(cChainConfig))
+		decoder.UseNumber()
+		if err := decoder.Decode(&config); err != nil {
+			return nil, fmt.Errorf("couldn't unmarshal C-Chain config: %w", err)
+		}
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	cChainConfig, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal C-Chain config: %w", err)
+	}
+	updated := make(map[string]string, len(chainConfigFiles)+1)
+	for k, v := range chainConfigFiles {
+		updated[k] = v
+	}
+	updated[CChainAlias] = string(cChainConfig)
+	return updated, nil
+}
+
+// SetCChainOptions sets [opts] on the node's C-Chain config. Note that a
+// node's C-Chain config is used instead of the network's, not merged with it.
+func (c *Config) SetCChainOptions(opts ...CChainOption) error {
+	chainConfigFiles, err := ApplyCChainOptions(c.ChainConfigFiles, opts...)
+	if err != nil {
+		return err
+	}
+	c.ChainConfigFiles = chainConfigFiles
+	return nil
+}
@@ -0,0 +1,32 @@
+package node
+
+import (
+	"sort"
+	"strings"
+)
+
+// LabelEnvPrefix prefixes the environment variables the labels of a node
+// are given to its process on, e.g. ANR_LABEL_JOB for the label "job"
+const LabelEnvPrefix = "ANR_LABEL_"
+
+// LabelsEnv returns the environment variables, as NAME=value, [labels] are
+// given to a node process on, sorted. The label names are upper-cased, with
+// the characters not allowed on environment variable names replaced by '_'.
+func LabelsEnv(labels map[string]string) []string {
+	env := make([]string, 0, len(labels))
+	for name, value := range labels {
+		name = strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z':
+				return r - 'a' + 'A'
+			case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+				return r
+			default:
+				return '_'
+			}
+		}, name)
+		env = append(env, LabelEnvPrefix+name+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
@@ -0,0 +1,17 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelsEnv(t *testing.T) {
+	require := require.New(t)
+	require.Empty(node.LabelsEnv(nil))
+	require.Equal([]string{
+		"ANR_LABEL_APP_KUBERNETES_IO_NAME=anr",
+		"ANR_LABEL_JOB=ci",
+	}, node.LabelsEnv(map[string]string{"job": "ci", "app.kubernetes.io/name": "anr"}))
+}
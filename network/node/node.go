@@ -3,17 +3,38 @@ package node
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/ava-labs/avalanche-network-runner/api"
 	"github.com/ava-labs/avalanche-network-runner/network/node/status"
 	"github.com/ava-labs/avalanchego/config"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/peer"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowball"
 	"github.com/ava-labs/avalanchego/snow/networking/router"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
 )
 
-// Node represents an AvalancheGo node
+// Environment variables given to the commands run with Node.Exec
+const (
+	ExecNodeNameEnv  = "ANR_NODE_NAME"
+	ExecNodeIDEnv    = "ANR_NODE_ID"
+	ExecNodeURIEnv   = "ANR_NODE_URI"
+	ExecDataDirEnv   = "ANR_DATA_DIR"
+	ExecDBDirEnv     = "ANR_DB_DIR"
+	ExecLogsDirEnv   = "ANR_LOGS_DIR"
+	ExecPluginDirEnv = "ANR_PLUGIN_DIR"
+)
+
+// Node represents an AvalancheGo node.
+// On local networks, a Node keeps referring to its node when the node process
+// is replaced, on Start, TrackSubnet, or a restart by the network, and
+// reports on the current process. Once the node is removed, its Stop, Start
+// and TrackSubnet fail, even if another node is added with the same name.
 type Node interface {
 	// Return this node's name, which is unique
 	// across all the nodes in its network.
@@ -54,10 +75,97 @@ type Node interface {
 	GetConfigFile() string
 	// Return this node's config
 	GetConfig() Config
+	// Return the config this node's process was started with, after applying
+	// the network defaults, with secrets redacted, for diagnostics.
+	GetEffectiveConfig() EffectiveConfig
 	// Return this node's flag value
 	GetFlag(string) (string, error)
 	// Return this node's paused status
 	GetPaused() bool
+	// Return the time at which the node process was last started,
+	// or the zero time if the node is paused
+	StartTime() time.Time
+	// Return how long the node process has been running,
+	// or 0 if it is not running
+	Uptime() time.Duration
+	// Return nil once this node's process is healthy.
+	// Same as waiting for the node by name on its network.
+	AwaitHealthy(ctx context.Context) error
+	// Stop the node process, keeping its data so that it can be started again
+	// with Start. Same as pausing the node by name on its network.
+	Stop(ctx context.Context) error
+	// Start the node process again after Stop, with the same config.
+	// Same as resuming the node by name on its network.
+	Start(ctx context.Context) error
+	// Make this node's process track [subnetID], restarting it if it didn't
+	// already, with the subnet tracking flag named as its avalanchego version
+	// expects. Paused nodes track the subnet once started again.
+	TrackSubnet(ctx context.Context, subnetID ids.ID) error
+	// Make this node's process stop tracking [subnetID], see TrackSubnet.
+	UntrackSubnet(ctx context.Context, subnetID ids.ID) error
+	// Run [cmd] with [args] from this node's data dir, and return its combined
+	// output. The command gets the node's name, ID, URI and dirs on the
+	// Exec*Env environment variables. Tools that open the node's db
+	// should be run while the node is stopped.
+	Exec(ctx context.Context, cmd string, args ...string) ([]byte, error)
+	// Create a keystore user on this node, and import [privateKeys] to it
+	// on the X-Chain and C-Chain. On local networks, genesis.EWOQKey is the
+	// pre-funded genesis key.
+	CreateKeystoreUser(ctx context.Context, user string, pass string, privateKeys ...*secp256k1.PrivateKey) error
+	// Return the versions this node's process reports it is running,
+	// as given by its info API. Timeout is given by the context parameter.
+	GetVersion(ctx context.Context) (Version, error)
+	// Return the primary network consensus parameters this node's process
+	// is running with, as given by its admin API, after the defaults and
+	// the config file are applied. Timeout is given by the context parameter.
+	ConsensusParameters(ctx context.Context) (snowball.Parameters, error)
+	// Set the log and display levels of all the loggers of this node's
+	// process to [level], e.g. "debug", with its admin API, so that verbose
+	// logging can be enabled only around a given phase. The level is not
+	// kept if the process is restarted. Timeout is given by the context
+	// parameter.
+	SetLogLevel(ctx context.Context, level string) error
+	// Return how this node's process crashed, or nil if it is running, or it
+	// was stopped by the network. The report is kept after the node's dirs
+	// are removed.
+	CrashReport() *CrashReport
+	// Return the last [n] lines this node's process wrote to its stdout and
+	// stderr, oldest first, or all the lines kept if [n] is not positive.
+	// The lines are kept in a fixed size buffer as they are written, even if
+	// the output is not redirected, so that failures can show them.
+	RecentLogs(n int) []string
+	// Return the OS process ID of this node's process, e.g. to attach a
+	// profiler or send it signals, or 0 if the process is not running or
+	// the node doesn't run as an OS process.
+	PID() int
+	// Send [sig] to this node's process, e.g. syscall.SIGHUP. Signals making
+	// the process exit are reported as a crash, see CrashReport: Stop should
+	// be used to stop it. Returns an error if the process is not running.
+	Signal(sig os.Signal) error
+}
+
+// CrashReport is captured when a node process exits without being
+// stopped by the network
+type CrashReport struct {
+	NodeName string    `json:"nodeName"`
+	ExitCode int       `json:"exitCode"`
+	Time     time.Time `json:"time"`
+	// Last lines of the node's main log
+	LogTail []string `json:"logTail"`
+	// Panic or fatal error message and goroutine traces the process
+	// wrote to stderr, if any
+	PanicTrace string `json:"panicTrace"`
+}
+
+// Version is what a node reports it is running
+type Version struct {
+	// e.g. avalanchego/1.11.13
+	Version            string `json:"version"`
+	DatabaseVersion    string `json:"databaseVersion"`
+	RPCProtocolVersion uint32 `json:"rpcProtocolVersion"`
+	GitCommit          string `json:"gitCommit"`
+	// VM ID --> VM version, for the VMs the node has, including plugins
+	VMVersions map[string]string `json:"vmVersions"`
 }
 
 // Config encapsulates an avalanchego configuration
@@ -96,10 +204,154 @@ type Config struct {
 	RedirectStdout bool `json:"redirectStdout"`
 	// If non-nil, direct this node's Stderr to os.Stderr
 	RedirectStderr bool `json:"redirectStderr"`
+	// If set, the node's db dir is created from this prebuilt db dir before
+	// the node is first started, so that it starts from a known chain state
+	// instead of bootstrapping from genesis. It must have the layout of a
+	// node's db dir. Ignored if the node's db dir already exists.
+	DBSeedDir string `json:"dbSeedDir"`
+	// If true, the node's db dir is a symlink to DBSeedDir instead of a copy.
+	// Faster for big dbs, but the node writes to DBSeedDir, so the same
+	// DBSeedDir can't be symlinked by more than one node.
+	DBSeedSymlink bool `json:"dbSeedSymlink"`
+	// If positive, the node is started this long after the network is
+	// created, to simulate nodes joining at different times.
+	// Only supported on network creation, including from a snapshot:
+	// AddNode and Reconcile return network.ErrStartDelay.
+	// Not supported for beacon nodes.
+	// Until the node starts, it is not part of the network: it is not
+	// returned by GetNode, and Healthy doesn't wait for it.
+	// If the network is stopped before, the node is never started. If a snapshot
+	// is saved before, the remaining delay is saved with the node config.
+	StartDelay time.Duration `json:"startDelay"`
+	// Names of the nodes that must be healthy before this node is started,
+	// e.g. so that API nodes only start once given validators are up.
+	// Only applied on network creation, including from a snapshot: nodes
+	// added later are started right away. Nodes with a start delay can't
+	// have nor be dependencies. See network.Config.StartOrder.
+	DependsOn []string `json:"dependsOn"`
+	// If positive, the node's db dir is a tmpfs of this many bytes, so that
+	// the node can be tested under disk exhaustion, e.g. with
+	// network.Network.SetNodeDiskChaos. The tmpfs is mounted before the node is
+	// first started, and unmounted when the node is removed or the network is
+	// stopped, losing its db unless a snapshot was saved.
+	// Linux only, and requires root. Not supported together with DBSeedDir.
+	DBDirSizeLimit int64 `json:"dbDirSizeLimit"`
+	// Labels of the node, e.g. "role": "api", to select nodes with
+	// network.NodeFilter. Given to the node process, together with the
+	// labels of its network, on LabelEnvPrefix environment variables.
+	Labels map[string]string `json:"labels"`
+	// If given, the node process runs as this OS user, so that networks can
+	// run with least privilege, e.g. on shared machines. A user name or uid,
+	// optionally followed by ":" and a group name or gid, e.g. "nobody" or
+	// "1000:1000". The group defaults to the primary group of the user.
+	// The data, db and logs dirs of the node are given to the user.
+	// Not supported on Windows, and requires the runner to be root, or to
+	// have the CAP_SETUID, CAP_SETGID and CAP_CHOWN capabilities.
+	RunAsUser string `json:"runAsUser"`
+	// If true, the node is an observer: an API endpoint that is never
+	// registered as a primary network or subnet validator. Observers are left
+	// out of the nodes that validator operations pick by default, track all
+	// the subnets created, and can't be beacons.
+	Observer bool `json:"observer"`
+	// If positive, waiting for the node to be healthy fails fast with
+	// network.ErrStartupTimeout if its API hasn't responded at all this long
+	// after its process was started, e.g. as its binary or flags are wrong,
+	// instead of lasting until the timeout given to the wait.
+	StartupTimeout time.Duration `json:"startupTimeout"`
+	// If true, the node is a subnet-only validator: it only syncs the P-Chain
+	// of the primary network, with avalanchego's partial sync mode, and
+	// validates the subnets it is added to. It must not be a genesis staker,
+	// and it is never used to issue txs or query the X-Chain or C-Chain. As
+	// subnet validators must be primary network validators, it is registered
+	// as one by the validator operations. Can't be a beacon nor an observer.
+	SubnetOnly bool `json:"subnetOnly"`
+}
+
+// Value given to the redacted flags. See RedactFlags.
+const Redacted = "[redacted]"
+
+// Flags whose values are secrets
+var secretFlags = []string{
+	config.StakingTLSKeyContentKey,
+	config.StakingSignerKeyContentKey,
+	config.HTTPSKeyContentKey,
+}
+
+// EffectiveConfig is the config a node process was started with.
+// Staking keys are not included, and secret flags are redacted,
+// so that it can be logged or exposed safely.
+type EffectiveConfig struct {
+	Name       string     `json:"name"`
+	NodeID     ids.NodeID `json:"nodeID"`
+	IsBeacon   bool       `json:"isBeacon"`
+	Observer   bool       `json:"observer"`
+	SubnetOnly bool       `json:"subnetOnly"`
+	BinaryPath string     `json:"binaryPath"`
+	IP         string     `json:"ip"`
+	APIPort    uint16     `json:"apiPort"`
+	P2PPort    uint16     `json:"p2pPort"`
+	DataDir    string     `json:"dataDir"`
+	DBDir      string     `json:"dbDir"`
+	LogsDir    string     `json:"logsDir"`
+	PluginDir  string     `json:"pluginDir"`
+	// All the flags given to avalanchego, including the ones set by
+	// the network, and the ones from the node's config file
+	Flags map[string]string `json:"flags"`
+}
+
+// RedactFlags returns a copy of [flags] where
+// the values of secret flags are replaced by Redacted
+func RedactFlags(flags map[string]string) map[string]string {
+	redacted := make(map[string]string, len(flags))
+	for k, v := range flags {
+		redacted[k] = v
+	}
+	for _, k := range secretFlags {
+		if _, ok := redacted[k]; ok {
+			redacted[k] = Redacted
+		}
+	}
+	return redacted
 }
 
 // Validate returns an error if this config is invalid
 func (c *Config) Validate(expectedNetworkID uint32) error {
+	if c.StartDelay < 0 {
+		return errors.New("negative start delay")
+	}
+	if c.StartupTimeout < 0 {
+		return errors.New("negative startup timeout")
+	}
+	if c.IsBeacon && c.Observer {
+		return errors.New("observer nodes can't be beacons")
+	}
+	if c.SubnetOnly && c.IsBeacon {
+		return errors.New("subnet-only nodes can't be beacons")
+	}
+	if c.SubnetOnly && c.Observer {
+		return errors.New("subnet-only nodes can't be observers")
+	}
+	if c.IsBeacon && c.StartDelay > 0 {
+		return errors.New("beacon nodes can't have a start delay")
+	}
+	if c.StartDelay > 0 && len(c.DependsOn) > 0 {
+		return errors.New("nodes with a start delay can't depend on other nodes")
+	}
+	if c.DBSeedSymlink && c.DBSeedDir == "" {
+		return errors.New("db seed symlink requires a db seed dir")
+	}
+	if c.DBDirSizeLimit < 0 {
+		return errors.New("negative db dir size limit")
+	}
+	if c.DBDirSizeLimit > 0 && c.DBSeedDir != "" {
+		return errors.New("db dir size limit is not supported with a db seed dir")
+	}
+	if c.RunAsUser != "" {
+		userSpec, groupSpec, hasGroup := strings.Cut(c.RunAsUser, ":")
+		if userSpec == "" || (hasGroup && groupSpec == "") {
+			return fmt.Errorf("invalid run as user %q, expected user[:group]", c.RunAsUser)
+		}
+	}
 	return validateConfigFile([]byte(c.ConfigFile), expectedNetworkID)
 }
 
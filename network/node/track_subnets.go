@@ -0,0 +1,45 @@
+package node
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+// Name of config.TrackSubnetsKey before avalanchego v1.9.6.
+// Networks give nodes the flag name their version expects.
+const deprecatedTrackSubnetsKey = "whitelisted-subnets"
+
+// TrackedSubnets returns the sorted IDs of the subnets tracked by a node
+// with [flags], given by config.TrackSubnetsKey or its deprecated name.
+func TrackedSubnets(flags map[string]interface{}) ([]string, error) {
+	subnetIDs := set.Set[string]{}
+	for _, key := range []string{config.TrackSubnetsKey, deprecatedTrackSubnetsKey} {
+		vIntf, ok := flags[key]
+		if !ok {
+			continue
+		}
+		v, ok := vIntf.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected %q to be of type string but got %T", key, vIntf)
+		}
+		for _, subnetID := range strings.Split(v, ",") {
+			if subnetID = strings.TrimSpace(subnetID); subnetID != "" {
+				subnetIDs.Add(subnetID)
+			}
+		}
+	}
+	tracked := subnetIDs.List()
+	sort.Strings(tracked)
+	return tracked, nil
+}
+
+// SetTrackedSubnets makes a node with [flags] track [subnetIDs], setting
+// config.TrackSubnetsKey, and removing its deprecated name, if given.
+func SetTrackedSubnets(flags map[string]interface{}, subnetIDs []string) {
+	delete(flags, deprecatedTrackSubnetsKey)
+	flags[config.TrackSubnetsKey] = strings.Join(subnetIDs, ",")
+}
@@ -0,0 +1,30 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackedSubnets(t *testing.T) {
+	require := require.New(t)
+	tracked, err := node.TrackedSubnets(nil)
+	require.NoError(err)
+	require.Empty(tracked)
+
+	flags := map[string]interface{}{
+		config.TrackSubnetsKey: "subnet2, subnet1",
+		"whitelisted-subnets":  "subnet3,subnet1,",
+	}
+	tracked, err = node.TrackedSubnets(flags)
+	require.NoError(err)
+	require.Equal([]string{"subnet1", "subnet2", "subnet3"}, tracked)
+
+	node.SetTrackedSubnets(flags, tracked[:2])
+	require.Equal(map[string]interface{}{config.TrackSubnetsKey: "subnet1,subnet2"}, flags)
+
+	_, err = node.TrackedSubnets(map[string]interface{}{config.TrackSubnetsKey: 1})
+	require.Error(err)
+}
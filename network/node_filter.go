@@ -0,0 +1,38 @@
+package network
+
+import "github.com/ava-labs/avalanche-network-runner/network/node"
+
+// NodeFilter selects nodes of a network, see Network.GetNodeNamesWithFilter.
+// A node is selected if it passes all the filters set.
+type NodeFilter struct {
+	// If true, only the beacon nodes
+	Beacons bool
+	// If true, only the observer nodes, see node.Config.Observer
+	Observers bool
+	// If true, only the nodes that aren't observers
+	Validators bool
+	// If true, only the running nodes that report being healthy
+	Healthy bool
+	// Only the nodes having all these labels, see node.Config.Labels
+	Labels map[string]string
+}
+
+// MatchesConfig returns true if a node with [nodeConfig] passes
+// the filters that don't depend on the node's state
+func (f NodeFilter) MatchesConfig(nodeConfig node.Config) bool {
+	if f.Beacons && !nodeConfig.IsBeacon {
+		return false
+	}
+	if f.Observers && !nodeConfig.Observer {
+		return false
+	}
+	if f.Validators && nodeConfig.Observer {
+		return false
+	}
+	for k, v := range f.Labels {
+		if label, ok := nodeConfig.Labels[k]; !ok || label != v {
+			return false
+		}
+	}
+	return true
+}
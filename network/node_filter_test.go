@@ -0,0 +1,43 @@
+package network_test
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeFilterMatchesConfig(t *testing.T) {
+	beacon := node.Config{IsBeacon: true, Labels: map[string]string{"role": "validator", "region": "eu"}}
+	api := node.Config{Labels: map[string]string{"role": "api"}}
+	observer := node.Config{Observer: true}
+	tests := []struct {
+		name       string
+		filter     network.NodeFilter
+		nodeConfig node.Config
+		want       bool
+	}{
+		{name: "no filter", nodeConfig: api, want: true},
+		{name: "beacon", filter: network.NodeFilter{Beacons: true}, nodeConfig: beacon, want: true},
+		{name: "not beacon", filter: network.NodeFilter{Beacons: true}, nodeConfig: api},
+		{name: "observer", filter: network.NodeFilter{Observers: true}, nodeConfig: observer, want: true},
+		{name: "not observer", filter: network.NodeFilter{Observers: true}, nodeConfig: api},
+		{name: "validator", filter: network.NodeFilter{Validators: true}, nodeConfig: api, want: true},
+		{name: "not validator", filter: network.NodeFilter{Validators: true}, nodeConfig: observer},
+		{name: "label", filter: network.NodeFilter{Labels: map[string]string{"role": "api"}}, nodeConfig: api, want: true},
+		{name: "other label value", filter: network.NodeFilter{Labels: map[string]string{"role": "api"}}, nodeConfig: beacon},
+		{name: "missing label", filter: network.NodeFilter{Labels: map[string]string{"region": "eu"}}, nodeConfig: api},
+		{
+			name:       "all filters",
+			filter:     network.NodeFilter{Beacons: true, Labels: map[string]string{"role": "validator", "region": "eu"}},
+			nodeConfig: beacon,
+			want:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.filter.MatchesConfig(tt.nodeConfig))
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package network
+
+import (
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	avagoconfig "github.com/ava-labs/avalanchego/config"
+	"golang.org/x/exp/maps"
+)
+
+// flags specific to a node, which a node created from a template doesn't
+// share with it, so that they are assigned to it as to any new node
+var nodeSpecificFlags = []string{
+	avagoconfig.DataDirKey,
+	avagoconfig.DBPathKey,
+	avagoconfig.LogsDirKey,
+	avagoconfig.HTTPPortKey,
+	avagoconfig.StakingPortKey,
+}
+
+// NodeOverrides change the config a node added by
+// Network.AddNodeFromTemplate takes from its template node
+type NodeOverrides struct {
+	// If empty, a unique name is assigned
+	Name       string
+	IsBeacon   bool
+	Observer   bool
+	SubnetOnly bool
+	// Added to the labels of the template, overriding them
+	Labels map[string]string
+	// If set, replaces the binary of the template
+	BinaryPath string
+	// Added to the ones of the template, overriding them
+	Flags              map[string]interface{}
+	ChainConfigFiles   map[string]string
+	UpgradeConfigFiles map[string]string
+	SubnetConfigFiles  map[string]string
+}
+
+// NewNodeConfigFromTemplate returns the config of a new node like the
+// one with [template], with [overrides] applied. The new node has its own
+// identity, so the staking keys are not copied, nor are the data dir, db dir,
+// logs dir and ports, if given, nor the db seed, start delay and dependencies.
+func NewNodeConfigFromTemplate(template node.Config, overrides NodeOverrides) (node.Config, error) {
+	nodeConfig := node.Config{
+		Name:               overrides.Name,
+		IsBeacon:           overrides.IsBeacon,
+		Observer:           overrides.Observer,
+		SubnetOnly:         overrides.SubnetOnly,
+		ConfigFile:         template.ConfigFile,
+		ChainConfigFiles:   maps.Clone(template.ChainConfigFiles),
+		UpgradeConfigFiles: maps.Clone(template.UpgradeConfigFiles),
+		SubnetConfigFiles:  maps.Clone(template.SubnetConfigFiles),
+		Flags:              maps.Clone(template.Flags),
+		BinaryPath:         template.BinaryPath,
+		RedirectStdout:     template.RedirectStdout,
+		RedirectStderr:     template.RedirectStderr,
+		DBDirSizeLimit:     template.DBDirSizeLimit,
+		Labels:             maps.Clone(template.Labels),
+		RunAsUser:          template.RunAsUser,
+	}
+	if nodeConfig.Flags == nil {
+		nodeConfig.Flags = map[string]interface{}{}
+	}
+	for _, flagName := range nodeSpecificFlags {
+		delete(nodeConfig.Flags, flagName)
+		if nodeConfig.ConfigFile != "" {
+			var err error
+			nodeConfig.ConfigFile, err = utils.SetJSONKey(nodeConfig.ConfigFile, flagName, "")
+			if err != nil {
+				return node.Config{}, err
+			}
+		}
+	}
+	if overrides.BinaryPath != "" {
+		nodeConfig.BinaryPath = overrides.BinaryPath
+	}
+	for k, v := range overrides.Flags {
+		nodeConfig.Flags[k] = v
+	}
+	nodeConfig.ChainConfigFiles = mergeMaps(nodeConfig.ChainConfigFiles, overrides.ChainConfigFiles)
+	nodeConfig.UpgradeConfigFiles = mergeMaps(nodeConfig.UpgradeConfigFiles, overrides.UpgradeConfigFiles)
+	nodeConfig.SubnetConfigFiles = mergeMaps(nodeConfig.SubnetConfigFiles, overrides.SubnetConfigFiles)
+	nodeConfig.Labels = mergeMaps(nodeConfig.Labels, overrides.Labels)
+	return nodeConfig, nil
+}
+
+// Returns [m] with [overrides] added, overriding its values
+func mergeMaps(m map[string]string, overrides map[string]string) map[string]string {
+	if len(overrides) == 0 {
+		return m
+	}
+	if m == nil {
+		m = make(map[string]string, len(overrides))
+	}
+	for k, v := range overrides {
+		m[k] = v
+	}
+	return m
+}
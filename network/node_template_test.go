@@ -0,0 +1,99 @@
+package network_test
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNodeConfigFromTemplate(t *testing.T) {
+	require := require.New(t)
+	template := node.Config{
+		Name:              "node1",
+		IsBeacon:          true,
+		StakingKey:        "key",
+		StakingCert:       "cert",
+		StakingSigningKey: "signing-key",
+		ConfigFile:        `{"log-level":"debug","http-port":9650}`,
+		ChainConfigFiles:  map[string]string{"C": `{"pruning-enabled":false}`, "X": "{}"},
+		Flags: map[string]interface{}{
+			"log-level":            "info",
+			config.HTTPPortKey:     9650,
+			config.DataDirKey:      "/data/node1",
+			config.TrackSubnetsKey: "subnet1",
+		},
+		BinaryPath:     "/bin/avalanchego",
+		RedirectStderr: true,
+		DBSeedDir:      "/seed",
+		DependsOn:      []string{"node0"},
+		DBDirSizeLimit: 1024,
+		Labels:         map[string]string{"role": "validator", "region": "eu"},
+		RunAsUser:      "nobody",
+	}
+	nodeConfig, err := network.NewNodeConfigFromTemplate(template, network.NodeOverrides{
+		Name:             "node4",
+		Observer:         true,
+		Labels:           map[string]string{"role": "api"},
+		Flags:            map[string]interface{}{"log-level": "trace"},
+		ChainConfigFiles: map[string]string{"C": "{}"},
+	})
+	require.NoError(err)
+	require.Equal(node.Config{
+		Name:             "node4",
+		Observer:         true,
+		ConfigFile:       `{"log-level":"debug"}`,
+		ChainConfigFiles: map[string]string{"C": "{}", "X": "{}"},
+		Flags: map[string]interface{}{
+			"log-level":            "trace",
+			config.TrackSubnetsKey: "subnet1",
+		},
+		BinaryPath:     "/bin/avalanchego",
+		RedirectStderr: true,
+		DBDirSizeLimit: 1024,
+		Labels:         map[string]string{"role": "api", "region": "eu"},
+		RunAsUser:      "nobody",
+	}, nodeConfig)
+	// the template is not changed
+	require.Equal(`{"pruning-enabled":false}`, template.ChainConfigFiles["C"])
+	require.Equal("info", template.Flags["log-level"])
+	require.Equal("validator", template.Labels["role"])
+
+	nodeConfig, err = network.NewNodeConfigFromTemplate(node.Config{}, network.NodeOverrides{BinaryPath: "/bin/other"})
+	require.NoError(err)
+	require.Equal("/bin/other", nodeConfig.BinaryPath)
+	require.Empty(nodeConfig.Flags)
+}
+
+func TestNodeConfigValidateRunAsUser(t *testing.T) {
+	require := require.New(t)
+	for _, runAsUser := range []string{":", ":nogroup", "nobody:"} {
+		nodeConfig := node.Config{RunAsUser: runAsUser}
+		require.Error(nodeConfig.Validate(0), runAsUser)
+	}
+	for _, runAsUser := range []string{"nobody", "1000", "nobody:nogroup", "1000:1000"} {
+		nodeConfig := node.Config{RunAsUser: runAsUser}
+		require.NoError(nodeConfig.Validate(0), runAsUser)
+	}
+}
+
+func TestNodeConfigValidateObserver(t *testing.T) {
+	require := require.New(t)
+	nodeConfig := node.Config{Observer: true}
+	require.NoError(nodeConfig.Validate(0))
+	nodeConfig.IsBeacon = true
+	require.ErrorContains(nodeConfig.Validate(0), "observer nodes can't be beacons")
+}
+
+func TestNodeConfigValidateSubnetOnly(t *testing.T) {
+	require := require.New(t)
+	nodeConfig := node.Config{SubnetOnly: true}
+	require.NoError(nodeConfig.Validate(0))
+	nodeConfig.IsBeacon = true
+	require.ErrorContains(nodeConfig.Validate(0), "subnet-only nodes can't be beacons")
+	nodeConfig.IsBeacon = false
+	nodeConfig.Observer = true
+	require.ErrorContains(nodeConfig.Validate(0), "subnet-only nodes can't be observers")
+}
@@ -0,0 +1,259 @@
+// Package pchain issues common P-Chain transactions on a network via one of
+// its nodes, and waits until all its running nodes have accepted them, so
+// that any node can be queried about their effects right after.
+package pchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanche-network-runner/network/node/status"
+	"github.com/ava-labs/avalanchego/ids"
+	avagoConstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	txstatus "github.com/ava-labs/avalanchego/vms/platformvm/status"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// DefaultPollInterval is the time between checks of the status
+	// of a tx on each node, unless given by Client.PollInterval
+	DefaultPollInterval = 100 * time.Millisecond
+	// subnet validations start this long after being issued,
+	// a reasonable delay in most/slow test environments
+	validationStartOffset = 20 * time.Second
+)
+
+var ErrTxNotCommitted = errors.New("tx not committed")
+
+// Client issues P-Chain txs paid and signed by a key, see NewClient
+type Client struct {
+	net network.Network
+	key *secp256k1.PrivateKey
+	// Name of the node the txs are issued via.
+	// If empty, the first running node by name.
+	NodeName string
+	// Time between checks of the status of a tx on each node.
+	// If 0, DefaultPollInterval.
+	PollInterval time.Duration
+}
+
+// NewClient returns a Client issuing txs on [net] paid and signed by [key],
+// e.g. genesis.EWOQKey on local networks. Created subnets are owned by [key].
+func NewClient(net network.Network, key *secp256k1.PrivateKey) *Client {
+	return &Client{net: net, key: key}
+}
+
+// CreateSubnet creates a subnet owned by the client's key,
+// and returns its ID once all the running nodes have accepted it
+func (c *Client) CreateSubnet(ctx context.Context) (ids.ID, error) {
+	w, err := c.wallet(ctx)
+	if err != nil {
+		return ids.Empty, err
+	}
+	tx, err := w.P().IssueCreateSubnetTx(
+		&secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{c.key.Address()},
+		},
+		common.WithContext(ctx),
+		common.WithPollFrequency(c.pollInterval()),
+	)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("couldn't issue create subnet tx: %w", err)
+	}
+	return tx.ID(), AwaitAccepted(ctx, c.net, tx.ID(), c.pollInterval())
+}
+
+// CreateChain creates a blockchain named [chainName] on [subnetID], running
+// the VM [vmID] from [genesis], and returns its ID once all the running nodes
+// have accepted it. The subnet must be owned by the client's key.
+func (c *Client) CreateChain(
+	ctx context.Context,
+	subnetID ids.ID,
+	vmID ids.ID,
+	chainName string,
+	genesis []byte,
+) (ids.ID, error) {
+	w, err := c.wallet(ctx, subnetID)
+	if err != nil {
+		return ids.Empty, err
+	}
+	tx, err := w.P().IssueCreateChainTx(
+		subnetID,
+		genesis,
+		vmID,
+		nil,
+		chainName,
+		common.WithContext(ctx),
+		common.WithPollFrequency(c.pollInterval()),
+	)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("couldn't issue create chain tx: %w", err)
+	}
+	return tx.ID(), AwaitAccepted(ctx, c.net, tx.ID(), c.pollInterval())
+}
+
+// AddSubnetValidator adds [nodeID] as a validator of [subnetID] with [weight],
+// until [end], or until the node stops validating the primary network if
+// [end] is zero, and returns the tx ID once all the running nodes have
+// accepted it. The subnet must be owned by the client's key.
+func (c *Client) AddSubnetValidator(
+	ctx context.Context,
+	subnetID ids.ID,
+	nodeID ids.NodeID,
+	weight uint64,
+	end time.Time,
+) (ids.ID, error) {
+	w, err := c.wallet(ctx, subnetID)
+	if err != nil {
+		return ids.Empty, err
+	}
+	if end.IsZero() {
+		end, err = c.primaryValidationEnd(ctx, nodeID)
+		if err != nil {
+			return ids.Empty, err
+		}
+	}
+	tx, err := w.P().IssueAddSubnetValidatorTx(
+		&txs.SubnetValidator{
+			Validator: txs.Validator{
+				NodeID: nodeID,
+				Start:  uint64(time.Now().Add(validationStartOffset).Unix()),
+				End:    uint64(end.Unix()),
+				Wght:   weight,
+			},
+			Subnet: subnetID,
+		},
+		common.WithContext(ctx),
+		common.WithPollFrequency(c.pollInterval()),
+	)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("couldn't issue add subnet validator tx for node %s: %w", nodeID, err)
+	}
+	return tx.ID(), AwaitAccepted(ctx, c.net, tx.ID(), c.pollInterval())
+}
+
+// Returns the time at which [nodeID] stops validating the primary network
+func (c *Client) primaryValidationEnd(ctx context.Context, nodeID ids.NodeID) (time.Time, error) {
+	issuer, err := c.issuer()
+	if err != nil {
+		return time.Time{}, err
+	}
+	vdrs, err := platformvm.NewClient(issuer.GetURI()).GetCurrentValidators(ctx, avagoConstants.PrimaryNetworkID, []ids.NodeID{nodeID})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(vdrs) == 0 {
+		return time.Time{}, fmt.Errorf("node %s is not a primary network validator", nodeID)
+	}
+	return time.Unix(int64(vdrs[0].EndTime), 0), nil
+}
+
+// Returns a wallet issuing txs via the issuer node, that knows the owners of [subnetIDs]
+func (c *Client) wallet(ctx context.Context, subnetIDs ...ids.ID) (primary.Wallet, error) {
+	issuer, err := c.issuer()
+	if err != nil {
+		return nil, err
+	}
+	kc := secp256k1fx.NewKeychain(c.key)
+	return primary.MakeWallet(ctx, &primary.WalletConfig{
+		URI:          issuer.GetURI(),
+		AVAXKeychain: kc,
+		EthKeychain:  kc,
+		SubnetIDs:    subnetIDs,
+	})
+}
+
+// Returns the node txs are issued via
+func (c *Client) issuer() (node.Node, error) {
+	if c.NodeName != "" {
+		return c.net.GetNode(c.NodeName)
+	}
+	nodes, err := runningNodes(c.net)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, network.ErrNoRunningNodes
+	}
+	return nodes[0], nil
+}
+
+func (c *Client) pollInterval() time.Duration {
+	if c.PollInterval > 0 {
+		return c.PollInterval
+	}
+	return DefaultPollInterval
+}
+
+// AwaitAccepted waits until all the running nodes of [net] report [txID] as
+// committed, checking each node every [pollInterval]. Returns an error
+// wrapping ErrTxNotCommitted if a node reports it as aborted or dropped.
+func AwaitAccepted(ctx context.Context, net network.Network, txID ids.ID, pollInterval time.Duration) error {
+	nodes, err := runningNodes(net)
+	if err != nil {
+		return err
+	}
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, n := range nodes {
+		eg.Go(func() error {
+			if err := awaitCommitted(ctx, n.GetAPIClient().PChainAPI(), txID, pollInterval); err != nil {
+				return &network.NodeError{NodeName: n.GetName(), Op: "await tx", Err: err}
+			}
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+// Waits until [client] reports [txID] as committed
+func awaitCommitted(ctx context.Context, client platformvm.Client, txID ids.ID, pollInterval time.Duration) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		res, err := client.GetTxStatus(ctx, txID)
+		if err != nil {
+			return err
+		}
+		switch res.Status {
+		case txstatus.Committed:
+			return nil
+		case txstatus.Aborted, txstatus.Dropped:
+			return fmt.Errorf("tx %s %s: %w", txID, res.Status, ErrTxNotCommitted)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Returns the running nodes of [net], sorted by name
+func runningNodes(net network.Network) ([]node.Node, error) {
+	allNodes, err := net.GetAllNodes()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []node.Node{}
+	for _, n := range allNodes {
+		if !n.GetPaused() && n.Status() == status.Running {
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].GetName() < nodes[j].GetName()
+	})
+	return nodes, nil
+}
@@ -0,0 +1,102 @@
+package pchain
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	apimocks "github.com/ava-labs/avalanche-network-runner/api/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	txstatus "github.com/ava-labs/avalanchego/vms/platformvm/status"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePChainClient reports the statuses given, in order, then the last one
+type fakePChainClient struct {
+	platformvm.Client
+	lock     sync.Mutex
+	statuses []txstatus.Status
+}
+
+func (c *fakePChainClient) GetTxStatus(context.Context, ids.ID, ...rpc.Option) (*platformvm.GetTxStatusResponse, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	res := &platformvm.GetTxStatusResponse{Status: c.statuses[0]}
+	if len(c.statuses) > 1 {
+		c.statuses = c.statuses[1:]
+	}
+	return res, nil
+}
+
+func newTestNetwork(t *testing.T, statuses map[string][]txstatus.Status) network.Network {
+	net, err := networkfakes.NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}, {Name: "node3"}},
+	})
+	require.NoError(t, err)
+	for nodeName, nodeStatuses := range statuses {
+		n, err := net.GetNode(nodeName)
+		require.NoError(t, err)
+		client := &apimocks.Client{}
+		client.On("PChainAPI").Return(&fakePChainClient{statuses: nodeStatuses})
+		n.(*networkfakes.Node).SetAPIClient(client)
+	}
+	return net
+}
+
+func TestAwaitAccepted(t *testing.T) {
+	require := require.New(t)
+	net := newTestNetwork(t, map[string][]txstatus.Status{
+		"node1": {txstatus.Committed},
+		"node2": {txstatus.Unknown, txstatus.Processing, txstatus.Committed},
+	})
+	// paused nodes are not waited for
+	require.NoError(net.PauseNode(context.Background(), "node3"))
+	require.NoError(AwaitAccepted(context.Background(), net, ids.GenerateTestID(), time.Millisecond))
+
+	net = newTestNetwork(t, map[string][]txstatus.Status{
+		"node1": {txstatus.Committed},
+		"node2": {txstatus.Processing, txstatus.Aborted},
+		"node3": {txstatus.Committed},
+	})
+	err := AwaitAccepted(context.Background(), net, ids.GenerateTestID(), time.Millisecond)
+	require.ErrorIs(err, ErrTxNotCommitted)
+	var nodeErr *network.NodeError
+	require.ErrorAs(err, &nodeErr)
+	require.Equal("node2", nodeErr.NodeName)
+
+	net = newTestNetwork(t, map[string][]txstatus.Status{
+		"node1": {txstatus.Committed},
+		"node2": {txstatus.Unknown},
+		"node3": {txstatus.Committed},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(AwaitAccepted(ctx, net, ids.GenerateTestID(), time.Millisecond), context.DeadlineExceeded)
+}
+
+func TestClientIssuer(t *testing.T) {
+	require := require.New(t)
+	net := newTestNetwork(t, nil)
+	c := NewClient(net, nil)
+	issuer, err := c.issuer()
+	require.NoError(err)
+	require.Equal("node1", issuer.GetName())
+	require.NoError(net.PauseNode(context.Background(), "node1"))
+	issuer, err = c.issuer()
+	require.NoError(err)
+	require.Equal("node2", issuer.GetName())
+	c.NodeName = "node3"
+	issuer, err = c.issuer()
+	require.NoError(err)
+	require.Equal("node3", issuer.GetName())
+	c.NodeName = "node4"
+	_, err = c.issuer()
+	require.ErrorIs(err, network.ErrNodeNotFound)
+}
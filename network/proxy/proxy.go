@@ -0,0 +1,107 @@
+// Package proxy serves the APIs of all the nodes of a network.Network on a
+// single address, routing requests by node name, for tools and browsers that
+// can only be pointed at one host and port.
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+)
+
+const readHeaderTimeout = 10 * time.Second
+
+var _ http.Handler = (*handler)(nil)
+
+type handler struct {
+	net network.Network
+}
+
+// NewHandler returns a handler that forwards a request for /<node name>/<path>
+// to <path> on the node named <node name> of [net], e.g. /node1/ext/health to
+// /ext/health on node1. Nodes are looked up on each request, so nodes added,
+// restarted or removed meanwhile are routed to accordingly.
+// Websocket upgrades, as used by the C-Chain's /ext/bc/C/ws, are passed through.
+// Requests for unknown nodes get a 404, and for a stopped network a 503.
+func NewHandler(net network.Network) http.Handler {
+	return &handler{net: net}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	nodeName, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	n, err := h.net.GetNode(nodeName)
+	switch {
+	case errors.Is(err, network.ErrNodeNotFound):
+		http.Error(w, fmt.Sprintf("node %q not found", nodeName), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	target, err := url.Parse(n.GetURI())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.Out.URL.Path = "/" + path
+			r.Out.URL.RawPath = ""
+			r.SetXForwarded()
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// Server serves NewHandler on a listening address
+type Server struct {
+	httpServer *http.Server
+	listener   net.Listener
+	errCh      chan error
+}
+
+// Start serves the nodes of [nw] on [addr], e.g. "127.0.0.1:9600", or
+// "127.0.0.1:0" for a free port, until Stop is called.
+func Start(nw network.Network, addr string) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't listen on %s: %w", addr, err)
+	}
+	s := &Server{
+		httpServer: &http.Server{
+			Handler:           NewHandler(nw),
+			ReadHeaderTimeout: readHeaderTimeout,
+		},
+		listener: listener,
+		errCh:    make(chan error, 1),
+	}
+	go func() {
+		s.errCh <- s.httpServer.Serve(listener)
+	}()
+	return s, nil
+}
+
+// URI returns the URI the node named [nodeName] is served on,
+// to be used in place of its own URI.
+func (s *Server) URI(nodeName string) string {
+	return "http://" + s.listener.Addr().String() + "/" + nodeName
+}
+
+// Stop stops serving, closing the connections being proxied.
+func (s *Server) Stop() error {
+	if err := s.httpServer.Close(); err != nil {
+		return err
+	}
+	if err := <-s.errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
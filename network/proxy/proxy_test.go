@@ -0,0 +1,108 @@
+package proxy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+// stubNetwork is a network.Network whose GetNode method returns [nodes].
+// Its other methods must not be called.
+type stubNetwork struct {
+	network.Network
+	nodes   map[string]node.Node
+	stopped bool
+}
+
+func (n *stubNetwork) GetNode(nodeName string) (node.Node, error) {
+	if n.stopped {
+		return nil, network.ErrStopped
+	}
+	nd, ok := n.nodes[nodeName]
+	if !ok {
+		return nil, network.ErrNodeNotFound
+	}
+	return nd, nil
+}
+
+// uriNode is a node.Node whose GetURI method returns [uri].
+// Its other methods must not be called.
+type uriNode struct {
+	node.Node
+	uri string
+}
+
+func (n uriNode) GetURI() string {
+	return n.uri
+}
+
+func TestProxy(t *testing.T) {
+	require := require.New(t)
+	nodeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			fmt.Fprintf(w, "%s %s", r.URL.Path, r.Header.Get("X-Forwarded-Host"))
+			return
+		}
+		// echo whatever is sent after the upgrade
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(buf, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n")
+		_ = buf.Flush()
+		_, _ = io.Copy(conn, buf)
+	}))
+	defer nodeServer.Close()
+	nodes := map[string]node.Node{"node1": uriNode{uri: nodeServer.URL}}
+	nw := &stubNetwork{nodes: nodes}
+
+	server, err := Start(nw, "127.0.0.1:0")
+	require.NoError(err)
+	get := func(uri string) (int, string) {
+		resp, err := http.Get(uri) //nolint:gosec
+		require.NoError(err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(err)
+		return resp.StatusCode, string(body)
+	}
+	proxyURL, err := url.Parse(server.URI("node1"))
+	require.NoError(err)
+	status, body := get(server.URI("node1") + "/ext/health")
+	require.Equal(http.StatusOK, status)
+	require.Equal("/ext/health "+proxyURL.Host, body)
+
+	// websocket upgrades are passed through
+	conn, err := net.Dial("tcp", proxyURL.Host)
+	require.NoError(err)
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET /node1/ext/bc/C/ws HTTP/1.1\r\nHost: %s\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n", proxyURL.Host)
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(err)
+	require.Equal(http.StatusSwitchingProtocols, resp.StatusCode)
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(err)
+	echo := make([]byte, 4)
+	_, err = io.ReadFull(reader, echo)
+	require.NoError(err)
+	require.Equal("ping", string(echo))
+
+	status, _ = get(server.URI("node2") + "/ext/health")
+	require.Equal(http.StatusNotFound, status)
+
+	nw.stopped = true
+	status, _ = get(server.URI("node1") + "/ext/health")
+	require.Equal(http.StatusServiceUnavailable, status)
+	require.NoError(server.Stop())
+}
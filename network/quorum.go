@@ -0,0 +1,38 @@
+package network
+
+import "github.com/ava-labs/avalanchego/ids"
+
+// QuorumStatus tells how much of the primary network stake is online, i.e.
+// on validators that are healthy nodes of the network, so that chaos tests
+// can check it stayed above, or dipped below, the consensus threshold as
+// intended. See Network.QuorumStatus.
+type QuorumStatus struct {
+	// Weight of the current primary network validators
+	TotalStake uint64
+	// Weight of the validators that are running and healthy nodes of the network
+	OnlineStake uint64
+	// Weight of the other validators: nodes of the network that are paused,
+	// stopped or unhealthy, and validators that aren't nodes of the network
+	OfflineStake uint64
+	// Validator node ID --> name of its node, for the online validators
+	Online map[ids.NodeID]string
+	// Validator node ID --> name of its node, or empty if it isn't a node
+	// of the network, for the offline validators
+	Offline map[ids.NodeID]string
+}
+
+// OnlineFraction returns the fraction of the stake that is online,
+// or 0 if there is no stake
+func (s QuorumStatus) OnlineFraction() float64 {
+	if s.TotalStake == 0 {
+		return 0
+	}
+	return float64(s.OnlineStake) / float64(s.TotalStake)
+}
+
+// HasQuorum returns true if the online stake reaches avalanchego's default
+// consensus threshold, i.e. at most DefaultMaxStoppedStake of the stake is
+// offline, as checked by the quorum guard
+func (s QuorumStatus) HasQuorum() bool {
+	return s.TotalStake != 0 && float64(s.OfflineStake)/float64(s.TotalStake) <= DefaultMaxStoppedStake
+}
@@ -0,0 +1,21 @@
+package network_test
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuorumStatus(t *testing.T) {
+	require := require.New(t)
+	require.Zero(network.QuorumStatus{}.OnlineFraction())
+	require.False(network.QuorumStatus{}.HasQuorum())
+
+	quorum := network.QuorumStatus{TotalStake: 100, OnlineStake: 80, OfflineStake: 20}
+	require.InDelta(0.8, quorum.OnlineFraction(), 1e-9)
+	require.True(quorum.HasQuorum())
+
+	quorum = network.QuorumStatus{TotalStake: 100, OnlineStake: 70, OfflineStake: 30}
+	require.False(quorum.HasQuorum())
+}
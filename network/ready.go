@@ -0,0 +1,158 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// Environment variable given to ReadyOptions.Command with the
+// path of the ready file, if one is written
+const ReadyFileEnv = "ANR_READY_FILE"
+
+// Manifest lists the endpoints of the running nodes of a network,
+// for external tools to connect to it. See GetManifest.
+type Manifest struct {
+	NetworkID uint32         `json:"networkID"`
+	Nodes     []NodeEndpoint `json:"nodes"`
+	// Labels of the network, see Config.Labels
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// NodeEndpoint holds the endpoints of a node
+type NodeEndpoint struct {
+	Name   string     `json:"name"`
+	NodeID ids.NodeID `json:"nodeID"`
+	URI    string     `json:"uri"`
+	// C-Chain JSON-RPC endpoint, e.g. for hardhat or foundry
+	CChainRPC string `json:"cChainRPC"`
+	// C-Chain websocket endpoint
+	CChainWS string `json:"cChainWS"`
+}
+
+// ReadyOptions configure NotifyReady. Any of them may be empty.
+type ReadyOptions struct {
+	// File the manifest is written to, as JSON. It is written to a temporary
+	// file first and renamed, so that once it exists, it is complete.
+	File string
+	// Command run with its arguments, with the manifest as JSON on its
+	// stdin, and ReadyFileEnv set if File is given.
+	Command []string
+	// Hosts file, e.g. /etc/hosts, the node hostnames in HostsDomain are
+	// written to. See WriteHostsFile.
+	HostsFile   string
+	HostsDomain string
+}
+
+// GetManifest returns the endpoints of the nodes of [net] that are not
+// paused, sorted by name.
+func GetManifest(net Network) (Manifest, error) {
+	networkID, err := net.GetNetworkID()
+	if err != nil {
+		return Manifest{}, err
+	}
+	nodes, err := net.GetAllNodes()
+	if err != nil {
+		return Manifest{}, err
+	}
+	networkConfig, err := net.ToConfig()
+	if err != nil {
+		return Manifest{}, err
+	}
+	manifest := Manifest{NetworkID: networkID, Nodes: []NodeEndpoint{}, Labels: networkConfig.Labels}
+	for _, n := range nodes {
+		if n.GetPaused() {
+			continue
+		}
+		uri := n.GetURI()
+		manifest.Nodes = append(manifest.Nodes, NodeEndpoint{
+			Name:      n.GetName(),
+			NodeID:    n.GetNodeID(),
+			URI:       uri,
+			CChainRPC: uri + "/ext/bc/C/rpc",
+			CChainWS:  "ws" + strings.TrimPrefix(uri, "http") + "/ext/bc/C/ws",
+		})
+	}
+	sort.Slice(manifest.Nodes, func(i, j int) bool {
+		return manifest.Nodes[i].Name < manifest.Nodes[j].Name
+	})
+	return manifest, nil
+}
+
+// NotifyReady waits until [net] is healthy, and then tells external tools,
+// as given by [opts], by writing its manifest to a file and/or running a
+// command, so that they can block on it instead of polling the network.
+// The node hostnames are also written to the hosts file, if given, before
+// running the command.
+// Timeout is given by the context parameter.
+func NotifyReady(ctx context.Context, net Network, opts ReadyOptions) error {
+	if err := net.Healthy(ctx); err != nil {
+		return err
+	}
+	manifest, err := GetManifest(net)
+	if err != nil {
+		return err
+	}
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if opts.File != "" {
+		if err := writeFileAtomic(opts.File, manifestBytes); err != nil {
+			return fmt.Errorf("couldn't write ready file: %w", err)
+		}
+	}
+	if opts.HostsFile != "" {
+		if err := WriteHostsFile(opts.HostsFile, opts.HostsDomain, manifest); err != nil {
+			return fmt.Errorf("couldn't write hosts file: %w", err)
+		}
+	}
+	if len(opts.Command) != 0 {
+		cmd := exec.CommandContext(ctx, opts.Command[0], opts.Command[1:]...) //nolint:gosec
+		cmd.Stdin = bytes.NewReader(manifestBytes)
+		cmd.Env = os.Environ()
+		if opts.File != "" {
+			cmd.Env = append(cmd.Env, ReadyFileEnv+"="+opts.File)
+		}
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("ready command failed: %w: %s", err, output)
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic writes [data] to a temporary file in the dir
+// of [path], and renames it to [path]
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if err := f.Chmod(0o644); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package network_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyReady(t *testing.T) {
+	require := require.New(t)
+	net, err := networkfakes.NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node2"}, {Name: "node1"}, {Name: "paused"}},
+	})
+	require.NoError(err)
+	require.NoError(net.PauseNode(context.Background(), "paused"))
+
+	dir := t.TempDir()
+	readyFile := filepath.Join(dir, "ready.json")
+	commandOutput := filepath.Join(dir, "command.json")
+	require.NoError(network.NotifyReady(context.Background(), net, network.ReadyOptions{
+		File:    readyFile,
+		Command: []string{"sh", "-c", "cat > " + commandOutput + " && test -f $" + network.ReadyFileEnv},
+	}))
+
+	readyBytes, err := os.ReadFile(readyFile)
+	require.NoError(err)
+	var manifest network.Manifest
+	require.NoError(json.Unmarshal(readyBytes, &manifest))
+	n1, err := net.GetNode("node1")
+	require.NoError(err)
+	require.Equal(network.NodeEndpoint{
+		Name:      "node1",
+		NodeID:    n1.GetNodeID(),
+		URI:       n1.GetURI(),
+		CChainRPC: n1.GetURI() + "/ext/bc/C/rpc",
+		CChainWS:  fmt.Sprintf("ws://%s:%d/ext/bc/C/ws", n1.GetIP(), n1.GetAPIPort()),
+	}, manifest.Nodes[0])
+	require.Len(manifest.Nodes, 2)
+	require.Equal("node2", manifest.Nodes[1].Name)
+	commandBytes, err := os.ReadFile(commandOutput)
+	require.NoError(err)
+	require.Equal(readyBytes, commandBytes)
+
+	require.Error(network.NotifyReady(context.Background(), net, network.ReadyOptions{
+		Command: []string{"false"},
+	}))
+
+	// not written until the network is healthy
+	require.NoError(net.SetNodeHealthy("node1", false))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Error(network.NotifyReady(ctx, net, network.ReadyOptions{File: filepath.Join(dir, "unhealthy.json")}))
+	require.NoFileExists(filepath.Join(dir, "unhealthy.json"))
+}
+
+func TestGetManifestLabels(t *testing.T) {
+	require := require.New(t)
+	labels := map[string]string{"job": "ci", "branch": "main"}
+	net, err := networkfakes.NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}},
+		Labels:      labels,
+	})
+	require.NoError(err)
+	manifest, err := network.GetManifest(net)
+	require.NoError(err)
+	require.Equal(labels, manifest.Labels)
+	manifestBytes, err := json.Marshal(manifest)
+	require.NoError(err)
+	require.Contains(string(manifestBytes), `"labels":{"branch":"main","job":"ci"}`)
+}
@@ -0,0 +1,104 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultNodeMemory is the memory a node is estimated to need,
+	// in bytes, unless given by ResourceGuard.MemoryPerNode
+	DefaultNodeMemory = 512 * 1024 * 1024
+	// DefaultNodeCPUs is the number of CPUs a node is estimated to need,
+	// unless given by ResourceGuard.CPUsPerNode
+	DefaultNodeCPUs = 0.5
+	// DefaultNodeOpenFiles is the number of files a node is estimated to
+	// keep open, unless given by ResourceGuard.OpenFilesPerNode
+	DefaultNodeOpenFiles = 4096
+)
+
+var ErrInsufficientResources = errors.New("host can't support the nodes")
+
+// ResourceGuard checks that the host has the memory, CPUs and file
+// descriptors that nodes are estimated to need before they are added to a
+// running network, so that nodes are not killed by the OOM killer or starved
+// mid-test, which looks like consensus failures. Each estimate is the default
+// one if 0.
+type ResourceGuard struct {
+	// Memory needed by each node, in bytes, checked against the memory
+	// available on the host for the added nodes
+	MemoryPerNode uint64 `json:"memoryPerNode"`
+	// CPUs needed by each node, checked against the CPUs of the host
+	// for all the running nodes
+	CPUsPerNode float64 `json:"cpusPerNode"`
+	// Files kept open by each node, checked against the file descriptors
+	// still free on the host for the added nodes
+	OpenFilesPerNode uint64 `json:"openFilesPerNode"`
+	// If true, nodes are added even if the host can't support them,
+	// and the problems are only logged as a warning
+	WarnOnly bool `json:"warnOnly"`
+}
+
+// HostResources are the resources of the host checked by ResourceGuard
+type HostResources struct {
+	// Memory available for new processes, in bytes
+	AvailableMemory uint64
+	// Number of logical CPUs
+	CPUs int
+	// File descriptors that can still be opened on the host
+	FreeOpenFiles uint64
+	// If false, FreeOpenFiles is not known, and not checked
+	CheckOpenFiles bool
+}
+
+// Check returns an error wrapping ErrInsufficientResources, with all the
+// problems found, if [host] can't support adding [numAdded] nodes to
+// a network with [numRunning] running nodes, or nil otherwise.
+func (g ResourceGuard) Check(host HostResources, numRunning int, numAdded int) error {
+	if numAdded <= 0 {
+		return nil
+	}
+	memoryPerNode := g.MemoryPerNode
+	if memoryPerNode == 0 {
+		memoryPerNode = DefaultNodeMemory
+	}
+	cpusPerNode := g.CPUsPerNode
+	if cpusPerNode == 0 {
+		cpusPerNode = DefaultNodeCPUs
+	}
+	openFilesPerNode := g.OpenFilesPerNode
+	if openFilesPerNode == 0 {
+		openFilesPerNode = DefaultNodeOpenFiles
+	}
+
+	var problems []string
+	if required := uint64(numAdded) * memoryPerNode; host.AvailableMemory < required {
+		problems = append(problems, fmt.Sprintf(
+			"%d MiB of memory available, %d MiB are required",
+			host.AvailableMemory/(1024*1024), required/(1024*1024),
+		))
+	}
+	if required := float64(numRunning+numAdded) * cpusPerNode; float64(host.CPUs) < required {
+		problems = append(problems, fmt.Sprintf("%d CPUs, %g are required", host.CPUs, required))
+	}
+	if required := uint64(numAdded) * openFilesPerNode; host.CheckOpenFiles && host.FreeOpenFiles < required {
+		problems = append(problems, fmt.Sprintf(
+			"%d file descriptors free, %d are required",
+			host.FreeOpenFiles, required,
+		))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: adding %d to %d running nodes: %s",
+			ErrInsufficientResources, numAdded, numRunning, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Validate returns an error if any of the estimates is negative
+func (g ResourceGuard) Validate() error {
+	if g.CPUsPerNode < 0 {
+		return errors.New("resource guard CPUs per node must not be negative")
+	}
+	return nil
+}
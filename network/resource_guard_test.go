@@ -0,0 +1,46 @@
+package network_test
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceGuardCheck(t *testing.T) {
+	require := require.New(t)
+	host := network.HostResources{
+		AvailableMemory: 2 * network.DefaultNodeMemory,
+		CPUs:            2,
+		FreeOpenFiles:   2 * network.DefaultNodeOpenFiles,
+		CheckOpenFiles:  true,
+	}
+	guard := network.ResourceGuard{}
+	require.NoError(guard.Check(host, 2, 2))
+	// nothing is checked if no node is added
+	require.NoError(guard.Check(host, 10, 0))
+
+	err := guard.Check(host, 0, 3)
+	require.ErrorIs(err, network.ErrInsufficientResources)
+	require.ErrorContains(err, "memory")
+	require.ErrorContains(err, "file descriptors")
+	require.NotContains(err.Error(), "CPUs")
+
+	err = guard.Check(host, 3, 2)
+	require.ErrorIs(err, network.ErrInsufficientResources)
+	require.ErrorContains(err, "CPUs")
+
+	// file descriptors are not checked if they are unknown
+	host.CheckOpenFiles = false
+	host.FreeOpenFiles = 0
+	require.NoError(guard.Check(host, 0, 2))
+
+	guard = network.ResourceGuard{MemoryPerNode: 1024, CPUsPerNode: 0.1}
+	require.NoError(guard.Check(host, 0, 20))
+}
+
+func TestResourceGuardValidate(t *testing.T) {
+	require := require.New(t)
+	require.NoError(network.ResourceGuard{}.Validate())
+	require.Error(network.ResourceGuard{CPUsPerNode: -1}.Validate())
+}
@@ -0,0 +1,75 @@
+package network
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+)
+
+// RollingRestartOptions configure Network.RollingRestart.
+// The zero options restart all the running nodes, one by one, as they are.
+type RollingRestartOptions struct {
+	// Nodes restarted at once. Defaults to 1.
+	BatchSize int `json:"batchSize"`
+	// Names of the nodes to restart, in order. Defaults to all the running
+	// nodes, sorted by name, with the beacons last, so that the other nodes
+	// restart while the beacons they bootstrap from run.
+	NodeNames []string `json:"nodeNames"`
+	// If set, the nodes are restarted with this binary, e.g. to upgrade them
+	BinaryPath string `json:"binaryPath"`
+	// If set, the nodes are restarted with this plugin dir
+	PluginDir string `json:"pluginDir"`
+	// Max fraction of the primary network stake each batch may stop, as
+	// checked by the quorum guard. If 0, the network's quorum guard applies,
+	// if set. See Network.SetQuorumGuard.
+	MaxStoppedStake float64 `json:"maxStoppedStake"`
+	// How long the network has to become healthy after each batch.
+	// If 0, only the context limits it.
+	HealthTimeout time.Duration `json:"healthTimeout"`
+}
+
+// Validate returns an error if the options are invalid
+func (o RollingRestartOptions) Validate() error {
+	if o.BatchSize < 0 {
+		return errors.New("negative rolling restart batch size")
+	}
+	if o.MaxStoppedStake < 0 || o.MaxStoppedStake > 1 {
+		return errors.New("max stopped stake must be between 0 and 1")
+	}
+	if o.HealthTimeout < 0 {
+		return errors.New("negative rolling restart health timeout")
+	}
+	return nil
+}
+
+// Batches returns the names of the nodes to restart, in the batches they are
+// restarted in, given [nodeConfigs], the configs of the running nodes
+func (o RollingRestartOptions) Batches(nodeConfigs []node.Config) [][]string {
+	nodeNames := o.NodeNames
+	if len(nodeNames) == 0 {
+		nodeConfigs = slices.Clone(nodeConfigs)
+		sort.Slice(nodeConfigs, func(i, j int) bool {
+			if nodeConfigs[i].IsBeacon != nodeConfigs[j].IsBeacon {
+				return !nodeConfigs[i].IsBeacon
+			}
+			return nodeConfigs[i].Name < nodeConfigs[j].Name
+		})
+		for _, nodeConfig := range nodeConfigs {
+			nodeNames = append(nodeNames, nodeConfig.Name)
+		}
+	}
+	batchSize := o.BatchSize
+	if batchSize == 0 {
+		batchSize = 1
+	}
+	batches := [][]string{}
+	for len(nodeNames) > 0 {
+		n := min(batchSize, len(nodeNames))
+		batches = append(batches, slices.Clone(nodeNames[:n]))
+		nodeNames = nodeNames[n:]
+	}
+	return batches
+}
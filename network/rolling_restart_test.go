@@ -0,0 +1,42 @@
+package network_test
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollingRestartOptionsBatches(t *testing.T) {
+	require := require.New(t)
+	nodeConfigs := []node.Config{
+		{Name: "node3", IsBeacon: true},
+		{Name: "node2"},
+		{Name: "node1", IsBeacon: true},
+		{Name: "node4"},
+		{Name: "node5"},
+	}
+	// beacons last
+	require.Equal(
+		[][]string{{"node2"}, {"node4"}, {"node5"}, {"node1"}, {"node3"}},
+		network.RollingRestartOptions{}.Batches(nodeConfigs),
+	)
+	require.Equal(
+		[][]string{{"node2", "node4"}, {"node5", "node1"}, {"node3"}},
+		network.RollingRestartOptions{BatchSize: 2}.Batches(nodeConfigs),
+	)
+	require.Equal(
+		[][]string{{"node5", "node1"}},
+		network.RollingRestartOptions{BatchSize: 3, NodeNames: []string{"node5", "node1"}}.Batches(nodeConfigs),
+	)
+	require.Equal("node3", nodeConfigs[0].Name)
+}
+
+func TestRollingRestartOptionsValidate(t *testing.T) {
+	require := require.New(t)
+	require.NoError(network.RollingRestartOptions{}.Validate())
+	require.Error(network.RollingRestartOptions{BatchSize: -1}.Validate())
+	require.Error(network.RollingRestartOptions{MaxStoppedStake: 1.5}.Validate())
+	require.Error(network.RollingRestartOptions{HealthTimeout: -1}.Validate())
+}
@@ -0,0 +1,251 @@
+package scenarios
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"gopkg.in/yaml.v3"
+)
+
+// ChaosStep is an action run at a given time of a ChaosScenario
+type ChaosStep struct {
+	// Time since the start of the scenario at which the step runs,
+	// e.g. "30s" in YAML
+	At time.Duration `yaml:"at"`
+	// Name of the action, one of DefaultChaosActions,
+	// or of ChaosOptions' Actions
+	Action string `yaml:"action"`
+	// Nodes the action applies to, if any
+	Nodes []string `yaml:"nodes"`
+	// If set, how long the action may take, e.g. for
+	// "assert-healthy", how long the network has to become healthy
+	Within time.Duration `yaml:"within"`
+	// Action specific arguments
+	Args map[string]string `yaml:"args"`
+}
+
+// ChaosScenario is a timed sequence of actions run on a network, e.g.
+//
+//	name: node2-outage
+//	steps:
+//	  - at: 30s
+//	    action: pause
+//	    nodes: [node2]
+//	  - at: 120s
+//	    action: resume
+//	    nodes: [node2]
+//	  - at: 120s
+//	    action: assert-healthy
+//	    within: 60s
+type ChaosScenario struct {
+	Name  string      `yaml:"name"`
+	Steps []ChaosStep `yaml:"steps"`
+}
+
+// ChaosAction runs a step of a ChaosScenario on [net]
+type ChaosAction func(ctx context.Context, net network.Network, step ChaosStep) error
+
+// DefaultChaosActions are the actions a ChaosScenario can use
+// without registering them:
+//   - pause, resume, restart, remove: run on each of the step's nodes
+//   - disk-chaos: sets the disk chaos of each of the step's nodes from the
+//     read-bytes-per-sec, write-bytes-per-sec, fill-disk and free-bytes args
+//   - link-bandwidth: limits the bandwidth of the link between the step's
+//     two nodes to the bits-per-sec arg, 0 removing the limit
+//   - assert-healthy: waits for the network to be healthy
+//   - await-node-healthy: waits for each of the step's nodes to be healthy
+//
+// Faults the network can't inject, e.g. network partitions, can be given
+// as ChaosOptions' Actions.
+var DefaultChaosActions = map[string]ChaosAction{
+	"pause": forEachNode(func(ctx context.Context, net network.Network, nodeName string, _ ChaosStep) error {
+		return net.PauseNode(ctx, nodeName)
+	}),
+	"resume": forEachNode(func(ctx context.Context, net network.Network, nodeName string, _ ChaosStep) error {
+		return net.ResumeNode(ctx, nodeName)
+	}),
+	"restart": forEachNode(func(ctx context.Context, net network.Network, nodeName string, _ ChaosStep) error {
+		return net.RestartNode(ctx, nodeName, "", "", "", nil, nil, nil)
+	}),
+	"remove": forEachNode(func(ctx context.Context, net network.Network, nodeName string, _ ChaosStep) error {
+		return net.RemoveNode(ctx, nodeName)
+	}),
+	"disk-chaos": forEachNode(func(ctx context.Context, net network.Network, nodeName string, step ChaosStep) error {
+		chaos, err := parseDiskChaos(step.Args)
+		if err != nil {
+			return err
+		}
+		return net.SetNodeDiskChaos(ctx, nodeName, chaos)
+	}),
+	"link-bandwidth": func(ctx context.Context, net network.Network, step ChaosStep) error {
+		if len(step.Nodes) != 2 {
+			return fmt.Errorf("a link needs 2 nodes, got %d", len(step.Nodes))
+		}
+		bitsPerSec, err := strconv.ParseUint(step.Args["bits-per-sec"], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid arg %q: %w", "bits-per-sec", err)
+		}
+		return net.SetLinkBandwidth(ctx, step.Nodes[0], step.Nodes[1], bitsPerSec)
+	},
+	"assert-healthy": func(ctx context.Context, net network.Network, _ ChaosStep) error {
+		return net.Healthy(ctx)
+	},
+	"await-node-healthy": forEachNode(func(ctx context.Context, net network.Network, nodeName string, _ ChaosStep) error {
+		return net.AwaitNodeHealthy(ctx, nodeName)
+	}),
+}
+
+// Returns an action running [f] on each of the nodes of its step
+func forEachNode(f func(ctx context.Context, net network.Network, nodeName string, step ChaosStep) error) ChaosAction {
+	return func(ctx context.Context, net network.Network, step ChaosStep) error {
+		if len(step.Nodes) == 0 {
+			return errors.New("no nodes given")
+		}
+		for _, nodeName := range step.Nodes {
+			if err := f(ctx, net, nodeName, step); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Returns the disk chaos given by the args of a disk-chaos step
+func parseDiskChaos(args map[string]string) (network.DiskChaos, error) {
+	chaos := network.DiskChaos{}
+	for k, v := range args {
+		var err error
+		switch k {
+		case "read-bytes-per-sec":
+			chaos.ReadBytesPerSec, err = strconv.ParseUint(v, 10, 64)
+		case "write-bytes-per-sec":
+			chaos.WriteBytesPerSec, err = strconv.ParseUint(v, 10, 64)
+		case "fill-disk":
+			chaos.FillDisk, err = strconv.ParseBool(v)
+		case "free-bytes":
+			chaos.FreeBytes, err = strconv.ParseUint(v, 10, 64)
+		default:
+			return chaos, fmt.Errorf("unknown arg %q", k)
+		}
+		if err != nil {
+			return chaos, fmt.Errorf("invalid arg %q: %w", k, err)
+		}
+	}
+	return chaos, nil
+}
+
+// ParseChaosScenario parses a ChaosScenario from YAML, or JSON
+func ParseChaosScenario(b []byte) (ChaosScenario, error) {
+	scenario := ChaosScenario{}
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&scenario); err != nil {
+		return scenario, fmt.Errorf("couldn't parse chaos scenario: %w", err)
+	}
+	return scenario, nil
+}
+
+// ChaosOptions configure RunChaosScenario
+type ChaosOptions struct {
+	// Action name --> action, in addition to DefaultChaosActions,
+	// which they override
+	Actions map[string]ChaosAction
+}
+
+// ChaosStepResult is the result of running a step of a ChaosScenario
+type ChaosStepResult struct {
+	Step ChaosStep
+	// Time since the start of the scenario at which the step started,
+	// later than its At if the previous step ran late
+	Started time.Duration
+	// How long the step took
+	Duration time.Duration
+	Err      error
+}
+
+// ChaosReport is the result of running a ChaosScenario
+type ChaosReport struct {
+	Name string
+	// Results of the steps that ran, in order.
+	// The steps following a failed one don't run.
+	Steps []ChaosStepResult
+	// Set if the scenario is invalid, a step failed,
+	// or the context was done before all the steps ran
+	Err error
+}
+
+// Passed returns true if all the steps of the scenario ran and succeeded
+func (r ChaosReport) Passed() bool {
+	return r.Err == nil
+}
+
+// RunChaosScenario runs the steps of [scenario] on [net], each at its time
+// since the start of the scenario, in order of time, and the steps given the
+// same time in the order given. A step runs once the previous one finished,
+// so it may run late. The scenario stops at the first failed step.
+// Timeout is given by the context parameter. The steps with Within set
+// are also bounded by it.
+func RunChaosScenario(ctx context.Context, net network.Network, scenario ChaosScenario, opts ChaosOptions) ChaosReport {
+	report := ChaosReport{Name: scenario.Name}
+	actions := make(map[string]ChaosAction, len(DefaultChaosActions)+len(opts.Actions))
+	for name, action := range DefaultChaosActions {
+		actions[name] = action
+	}
+	for name, action := range opts.Actions {
+		actions[name] = action
+	}
+	steps := slices.Clone(scenario.Steps)
+	for i, step := range steps {
+		if step.At < 0 || step.Within < 0 {
+			report.Err = fmt.Errorf("step %d: negative time", i)
+			return report
+		}
+		if _, ok := actions[step.Action]; !ok {
+			report.Err = fmt.Errorf("step %d: unknown action %q", i, step.Action)
+			return report
+		}
+	}
+	slices.SortStableFunc(steps, func(a, b ChaosStep) int {
+		return cmp.Compare(a.At, b.At)
+	})
+
+	start := time.Now()
+	for _, step := range steps {
+		timer := time.NewTimer(time.Until(start.Add(step.At)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			report.Err = ctx.Err()
+			return report
+		case <-timer.C:
+		}
+		result := runChaosStep(ctx, net, actions[step.Action], step, start)
+		report.Steps = append(report.Steps, result)
+		if result.Err != nil {
+			report.Err = fmt.Errorf("step %q at %s failed: %w", step.Action, step.At, result.Err)
+			return report
+		}
+	}
+	return report
+}
+
+// runChaosStep runs [action] for [step] of the scenario started at [start]
+func runChaosStep(ctx context.Context, net network.Network, action ChaosAction, step ChaosStep, start time.Time) ChaosStepResult {
+	stepStart := time.Now()
+	result := ChaosStepResult{Step: step, Started: stepStart.Sub(start)}
+	if step.Within > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, step.Within)
+		defer cancel()
+	}
+	result.Err = action(ctx, net, step)
+	result.Duration = time.Since(stepStart)
+	return result
+}
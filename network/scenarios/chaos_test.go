@@ -0,0 +1,136 @@
+package scenarios
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChaosScenario(t *testing.T) {
+	require := require.New(t)
+	scenario, err := ParseChaosScenario([]byte(`
+name: outage
+steps:
+  - at: 30s
+    action: pause
+    nodes: [node2]
+  - at: 2m
+    action: assert-healthy
+    within: 1m
+  - at: 1m
+    action: disk-chaos
+    nodes: [node1]
+    args:
+      read-bytes-per-sec: "1024"
+`))
+	require.NoError(err)
+	require.Equal(ChaosScenario{
+		Name: "outage",
+		Steps: []ChaosStep{
+			{At: 30 * time.Second, Action: "pause", Nodes: []string{"node2"}},
+			{At: 2 * time.Minute, Action: "assert-healthy", Within: time.Minute},
+			{At: time.Minute, Action: "disk-chaos", Nodes: []string{"node1"}, Args: map[string]string{"read-bytes-per-sec": "1024"}},
+		},
+	}, scenario)
+
+	_, err = ParseChaosScenario([]byte(`{"name": "outage", "step": []}`))
+	require.ErrorContains(err, "field step not found")
+}
+
+func TestRunChaosScenario(t *testing.T) {
+	require := require.New(t)
+	net, err := networkfakes.NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}, {Name: "node3"}},
+	})
+	require.NoError(err)
+
+	var partitioned []string
+	opts := ChaosOptions{
+		Actions: map[string]ChaosAction{
+			"partition": func(_ context.Context, _ network.Network, step ChaosStep) error {
+				partitioned = step.Nodes
+				return nil
+			},
+			"heal": func(context.Context, network.Network, ChaosStep) error {
+				partitioned = nil
+				return nil
+			},
+		},
+	}
+	scenario := ChaosScenario{
+		Name: "outage",
+		Steps: []ChaosStep{
+			{At: 40 * time.Millisecond, Action: "heal"},
+			{At: 10 * time.Millisecond, Action: "pause", Nodes: []string{"node2"}},
+			{At: 20 * time.Millisecond, Action: "partition", Nodes: []string{"node3"}},
+			{At: 30 * time.Millisecond, Action: "disk-chaos", Nodes: []string{"node1"}, Args: map[string]string{"write-bytes-per-sec": "1024"}},
+			{At: 30 * time.Millisecond, Action: "link-bandwidth", Nodes: []string{"node1", "node3"}, Args: map[string]string{"bits-per-sec": "1000000"}},
+			{At: 40 * time.Millisecond, Action: "resume", Nodes: []string{"node2"}},
+			{At: 40 * time.Millisecond, Action: "assert-healthy", Within: time.Second},
+		},
+	}
+	report := RunChaosScenario(context.Background(), net, scenario, opts)
+	require.NoError(report.Err)
+	require.True(report.Passed())
+	require.Equal("outage", report.Name)
+	actions := []string{}
+	for _, result := range report.Steps {
+		require.GreaterOrEqual(result.Started, result.Step.At)
+		actions = append(actions, result.Step.Action)
+	}
+	require.Equal([]string{"pause", "partition", "disk-chaos", "link-bandwidth", "heal", "resume", "assert-healthy"}, actions)
+	require.Nil(partitioned)
+	chaos, err := net.GetNodeDiskChaos("node1")
+	require.NoError(err)
+	require.Equal(network.DiskChaos{WriteBytesPerSec: 1024}, chaos)
+	require.Equal(uint64(1_000_000), net.GetLinkBandwidth("node3", "node1"))
+	paused, err := net.GetNode("node2")
+	require.NoError(err)
+	require.False(paused.GetPaused())
+
+	// the network doesn't become healthy in time
+	require.NoError(net.SetNodeHealthy("node1", false))
+	report = RunChaosScenario(context.Background(), net, ChaosScenario{
+		Steps: []ChaosStep{
+			{Action: "assert-healthy", Within: 10 * time.Millisecond},
+			{Action: "pause", Nodes: []string{"node2"}},
+		},
+	}, ChaosOptions{})
+	require.False(report.Passed())
+	require.ErrorIs(report.Err, context.DeadlineExceeded)
+	require.Len(report.Steps, 1)
+	require.ErrorIs(report.Steps[0].Err, context.DeadlineExceeded)
+
+	// invalid scenarios don't run
+	report = RunChaosScenario(context.Background(), net, ChaosScenario{
+		Steps: []ChaosStep{
+			{Action: "pause", Nodes: []string{"node2"}},
+			{Action: "partition"},
+		},
+	}, ChaosOptions{})
+	require.ErrorContains(report.Err, `step 1: unknown action "partition"`)
+	require.Empty(report.Steps)
+
+	report = RunChaosScenario(context.Background(), net, ChaosScenario{
+		Steps: []ChaosStep{{Action: "disk-chaos", Nodes: []string{"node1"}, Args: map[string]string{"read": "1"}}},
+	}, ChaosOptions{})
+	require.ErrorContains(report.Err, `unknown arg "read"`)
+
+	report = RunChaosScenario(context.Background(), net, ChaosScenario{
+		Steps: []ChaosStep{{Action: "link-bandwidth", Nodes: []string{"node1"}, Args: map[string]string{"bits-per-sec": "1"}}},
+	}, ChaosOptions{})
+	require.ErrorContains(report.Err, "a link needs 2 nodes")
+
+	// the scenario stops when the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report = RunChaosScenario(ctx, net, ChaosScenario{
+		Steps: []ChaosStep{{At: time.Hour, Action: "pause", Nodes: []string{"node2"}}},
+	}, ChaosOptions{})
+	require.ErrorIs(report.Err, context.Canceled)
+}
@@ -0,0 +1,190 @@
+// Package scenarios provides helpers running common test scenarios on a
+// network.Network, so that tests don't have to orchestrate them by hand.
+package scenarios
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/plugin/evm"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// C-Chain last accepted height, as reported by each node
+	CChainHeightMetric = `avalanche_snowman_last_accepted_height{chain="C"}`
+
+	receiptCheckFreq = 100 * time.Millisecond
+	transferGasLimit = 21_000
+)
+
+// StateSyncServerCChainConfig returns a C-Chain config for the nodes a new
+// node state syncs from, so that they serve a state summary every [interval]
+// blocks, instead of the coreth default of 16384. Coreth only allows changing
+// it on the local network ID, and the nodes must be started with it before
+// the blocks are produced.
+func StateSyncServerCChainConfig(interval uint64) string {
+	return fmt.Sprintf(`{"commit-interval":%d,"state-sync-commit-interval":%d}`, interval, interval)
+}
+
+// StateSyncClientCChainConfig returns a C-Chain config for a new node,
+// so that it state syncs when it is at least [minBlocks] behind the network,
+// instead of the coreth default of 300000.
+func StateSyncClientCChainConfig(minBlocks uint64) string {
+	return fmt.Sprintf(`{"state-sync-enabled":true,"state-sync-min-blocks":%d}`, minBlocks)
+}
+
+// StateSyncOptions configure RunStateSync
+type StateSyncOptions struct {
+	// Number of C-Chain blocks to produce before adding the syncing node.
+	// Should be at least the interval given to StateSyncServerCChainConfig,
+	// so that there is a state summary to sync to.
+	Blocks int
+	// Config of the node added to state sync. Its C-Chain config
+	// is set to StateSyncClientCChainConfig(Blocks).
+	NodeConfig node.Config
+	// Produces the given number of blocks on [net].
+	// If nil, ProduceCChainBlocks is used with [Key].
+	ProduceBlocks func(ctx context.Context, net network.Network, blocks int) error
+	// Funded C-Chain key used by the default ProduceBlocks,
+	// e.g. genesis.EWOQKey on local networks.
+	Key *secp256k1.PrivateKey
+}
+
+// RunStateSync produces blocks on [net], adds a node that state syncs the
+// C-Chain from the running nodes, and waits until all of them report, by
+// CChainHeightMetric, a C-Chain height of at least the one reached by the
+// produced blocks. The running nodes should have been started with
+// StateSyncServerCChainConfig. Returns the added node.
+// Timeout is given by the context parameter.
+func RunStateSync(ctx context.Context, net network.Network, opts StateSyncOptions) (node.Node, error) {
+	if opts.Blocks <= 0 {
+		return nil, errors.New("the number of blocks to produce must be positive")
+	}
+	produceBlocks := opts.ProduceBlocks
+	if produceBlocks == nil {
+		if opts.Key == nil {
+			return nil, errors.New("a key is needed to produce C-Chain blocks")
+		}
+		produceBlocks = func(ctx context.Context, net network.Network, blocks int) error {
+			producer, err := getRunningNode(net)
+			if err != nil {
+				return err
+			}
+			return ProduceCChainBlocks(ctx, producer, opts.Key, blocks)
+		}
+	}
+	if err := produceBlocks(ctx, net, opts.Blocks); err != nil {
+		return nil, fmt.Errorf("couldn't produce blocks: %w", err)
+	}
+	producer, err := getRunningNode(net)
+	if err != nil {
+		return nil, err
+	}
+	height, err := producer.GetAPIClient().CChainEthAPI().BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get C-Chain height: %w", err)
+	}
+
+	nodeConfig := opts.NodeConfig
+	chainConfigFiles := map[string]string{}
+	for k, v := range nodeConfig.ChainConfigFiles {
+		chainConfigFiles[k] = v
+	}
+	chainConfigFiles["C"] = StateSyncClientCChainConfig(uint64(opts.Blocks))
+	nodeConfig.ChainConfigFiles = chainConfigFiles
+	syncer, err := net.AddNode(nodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := syncer.Await(ctx); err != nil {
+		return syncer.Node, err
+	}
+	if err := net.AwaitMetric(ctx, CChainHeightMetric, func(v float64) bool {
+		return v >= float64(height)
+	}); err != nil {
+		return syncer.Node, fmt.Errorf("nodes didn't reach C-Chain height %d: %w", height, err)
+	}
+	return syncer.Node, nil
+}
+
+// ProduceCChainBlocks issues [blocks] C-Chain transfers from [key] to itself
+// on [n], one at a time, waiting for each to be accepted, so that each one
+// is included in a new block.
+func ProduceCChainBlocks(ctx context.Context, n node.Node, key *secp256k1.PrivateKey, blocks int) error {
+	client := n.GetAPIClient().CChainEthAPI()
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't get C-Chain ID: %w", err)
+	}
+	signer := types.LatestSignerForChainID(chainID)
+	addr := evm.GetEthAddress(key)
+	nonce, err := client.AcceptedNonceAt(ctx, addr)
+	if err != nil {
+		return fmt.Errorf("couldn't get nonce: %w", err)
+	}
+	for i := 0; i < blocks; i++ {
+		gasPrice, err := client.SuggestGasPrice(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't get gas price: %w", err)
+		}
+		tx, err := types.SignTx(types.NewTransaction(
+			nonce,
+			addr,
+			big.NewInt(1),
+			transferGasLimit,
+			gasPrice,
+			nil,
+		), signer, key.ToECDSA())
+		if err != nil {
+			return fmt.Errorf("couldn't sign tx: %w", err)
+		}
+		if err := client.SendTransaction(ctx, tx); err != nil {
+			return fmt.Errorf("couldn't send tx: %w", err)
+		}
+		if err := awaitReceipt(ctx, n, tx.Hash()); err != nil {
+			return err
+		}
+		nonce++
+	}
+	return nil
+}
+
+// awaitReceipt waits until the tx with [txHash] is accepted on [n]
+func awaitReceipt(ctx context.Context, n node.Node, txHash common.Hash) error {
+	client := n.GetAPIClient().CChainEthAPI()
+	for {
+		if receipt, err := client.TransactionReceipt(ctx, txHash); err == nil {
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return fmt.Errorf("tx %s failed", txHash)
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("tx %s wasn't accepted: %w", txHash, ctx.Err())
+		case <-time.After(receiptCheckFreq):
+		}
+	}
+}
+
+// getRunningNode returns a node of [net] that is not paused
+func getRunningNode(net network.Network) (node.Node, error) {
+	nodes, err := net.GetAllNodes()
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range nodes {
+		if !n.GetPaused() {
+			return n, nil
+		}
+	}
+	return nil, network.ErrNoRunningNodes
+}
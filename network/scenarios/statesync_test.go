@@ -0,0 +1,77 @@
+package scenarios
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/api/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/genesis"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunStateSync(t *testing.T) {
+	require := require.New(t)
+	net, err := networkfakes.NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+	})
+	require.NoError(err)
+	ethClient := &mocks.EthClient{}
+	ethClient.On("BlockNumber", mock.Anything).Return(uint64(10), nil)
+	client := &mocks.Client{}
+	client.On("CChainEthAPI").Return(ethClient)
+	nodes, err := net.GetAllNodes()
+	require.NoError(err)
+	for _, n := range nodes {
+		n.(*networkfakes.Node).SetAPIClient(client)
+	}
+
+	_, err = RunStateSync(context.Background(), net, StateSyncOptions{})
+	require.Error(err)
+
+	var produced int
+	syncer, err := RunStateSync(context.Background(), net, StateSyncOptions{
+		Blocks:     10,
+		NodeConfig: node.Config{Name: "syncer"},
+		ProduceBlocks: func(_ context.Context, _ network.Network, blocks int) error {
+			produced += blocks
+			// the height the nodes report once the syncer caught up
+			net.SetMetric(CChainHeightMetric, 10)
+			return nil
+		},
+	})
+	require.NoError(err)
+	require.Equal(10, produced)
+	require.Equal("syncer", syncer.GetName())
+	require.Equal(StateSyncClientCChainConfig(10), syncer.GetConfig().ChainConfigFiles["C"])
+}
+
+func TestProduceCChainBlocks(t *testing.T) {
+	require := require.New(t)
+	net, err := networkfakes.NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}},
+	})
+	require.NoError(err)
+	var nonces []uint64
+	ethClient := &mocks.EthClient{}
+	ethClient.On("ChainID", mock.Anything).Return(big.NewInt(43112), nil)
+	ethClient.On("AcceptedNonceAt", mock.Anything, mock.Anything).Return(uint64(5), nil)
+	ethClient.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(25_000_000_000), nil)
+	ethClient.On("SendTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		nonces = append(nonces, args.Get(1).(*types.Transaction).Nonce())
+	}).Return(nil)
+	ethClient.On("TransactionReceipt", mock.Anything, mock.Anything).Return(&types.Receipt{Status: types.ReceiptStatusSuccessful}, nil)
+	client := &mocks.Client{}
+	client.On("CChainEthAPI").Return(ethClient)
+	n, err := net.GetNode("node1")
+	require.NoError(err)
+	n.(*networkfakes.Node).SetAPIClient(client)
+
+	require.NoError(ProduceCChainBlocks(context.Background(), n, genesis.EWOQKey, 3))
+	require.Equal([]uint64{5, 6, 7}, nonces)
+}
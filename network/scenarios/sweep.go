@@ -0,0 +1,201 @@
+package scenarios
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"golang.org/x/exp/maps"
+)
+
+// SweepParam is a node flag, e.g. a consensus or gossip parameter,
+// and the values a sweep gives to it.
+type SweepParam struct {
+	Flag   string
+	Values []interface{}
+}
+
+// SweepOptions configure Sweep
+type SweepOptions struct {
+	// Config of the network created for each combination of flag values.
+	// The values are added to its Flags, so they apply to all the nodes
+	// that don't set them.
+	NetworkConfig network.Config
+	// Every combination of their values is run, in order,
+	// with the last param changing the fastest.
+	Params []SweepParam
+	// Creates and starts a network, e.g. by calling local.NewNetwork.
+	NewNetwork func(ctx context.Context, networkConfig network.Config) (network.Network, error)
+	// Run on each network once it is healthy
+	Scenario func(ctx context.Context, net network.Network) error
+	// Metrics read from each node once the scenario finishes,
+	// as by network.Network's GetMetric
+	Metrics []string
+}
+
+// SweepRun is the result of running the scenario with
+// one combination of flag values
+type SweepRun struct {
+	// Flag name --> value
+	Flags map[string]interface{}
+	// How long the scenario took to run
+	Duration time.Duration
+	// Metric name --> node name --> value
+	Metrics map[string]map[string]float64
+	// Set if the network couldn't be created, didn't become healthy, the
+	// scenario failed, or the metrics or network stop failed. Metrics and
+	// Duration are only set if the scenario succeeded.
+	Err error
+}
+
+// Sweep runs [opts.Scenario] on a new network for each combination of
+// the values of [opts.Params], one at a time, and reports the duration
+// and metrics of each run, so that the effect of the flags can be compared.
+// Each network is stopped before the next one is created. A failed run
+// doesn't stop the sweep: its error is reported in its SweepRun.
+// Returns an error, with the runs done so far, if [ctx] is done.
+func Sweep(ctx context.Context, opts SweepOptions) ([]SweepRun, error) {
+	if opts.NewNetwork == nil || opts.Scenario == nil {
+		return nil, errors.New("a network constructor and a scenario must be given")
+	}
+	for _, param := range opts.Params {
+		if len(param.Values) == 0 {
+			return nil, fmt.Errorf("no values given for flag %q", param.Flag)
+		}
+	}
+	combinations := []map[string]interface{}{{}}
+	for _, param := range opts.Params {
+		expanded := make([]map[string]interface{}, 0, len(combinations)*len(param.Values))
+		for _, flags := range combinations {
+			for _, value := range param.Values {
+				combination := maps.Clone(flags)
+				combination[param.Flag] = value
+				expanded = append(expanded, combination)
+			}
+		}
+		combinations = expanded
+	}
+
+	runs := make([]SweepRun, 0, len(combinations))
+	for _, flags := range combinations {
+		if err := ctx.Err(); err != nil {
+			return runs, err
+		}
+		runs = append(runs, runSweepCombination(ctx, opts, flags))
+	}
+	return runs, ctx.Err()
+}
+
+// runSweepCombination runs the scenario of [opts] on a new network with [flags]
+func runSweepCombination(ctx context.Context, opts SweepOptions, flags map[string]interface{}) SweepRun {
+	run := SweepRun{Flags: flags}
+	networkConfig := opts.NetworkConfig
+	networkConfig.Flags = maps.Clone(networkConfig.Flags)
+	if networkConfig.Flags == nil {
+		networkConfig.Flags = map[string]interface{}{}
+	}
+	for k, v := range flags {
+		networkConfig.Flags[k] = v
+	}
+	net, err := opts.NewNetwork(ctx, networkConfig)
+	if err != nil {
+		run.Err = fmt.Errorf("couldn't create network: %w", err)
+		return run
+	}
+	run.Err = runSweepScenario(ctx, opts, net, &run)
+	if err := net.Stop(context.WithoutCancel(ctx)); err != nil && run.Err == nil {
+		run.Err = fmt.Errorf("couldn't stop network: %w", err)
+	}
+	return run
+}
+
+// runSweepScenario runs the scenario of [opts] on [net],
+// setting the duration and metrics of [run]
+func runSweepScenario(ctx context.Context, opts SweepOptions, net network.Network, run *SweepRun) error {
+	if err := net.Healthy(ctx); err != nil {
+		return fmt.Errorf("network not healthy: %w", err)
+	}
+	start := time.Now()
+	if err := opts.Scenario(ctx, net); err != nil {
+		return fmt.Errorf("scenario failed: %w", err)
+	}
+	run.Duration = time.Since(start)
+	run.Metrics = make(map[string]map[string]float64, len(opts.Metrics))
+	for _, metricName := range opts.Metrics {
+		values, err := net.GetMetric(ctx, metricName)
+		if err != nil {
+			return fmt.Errorf("couldn't get metric %q: %w", metricName, err)
+		}
+		run.Metrics[metricName] = values
+	}
+	return nil
+}
+
+// WriteSweepCSV writes [runs] to [w] as CSV, with a row per run, and columns
+// for each flag value, the duration in seconds, the mean value of each
+// metric over the nodes, and the error, if any.
+func WriteSweepCSV(w io.Writer, runs []SweepRun) error {
+	flagNames := map[string]struct{}{}
+	metricNames := map[string]struct{}{}
+	for _, run := range runs {
+		for flagName := range run.Flags {
+			flagNames[flagName] = struct{}{}
+		}
+		for metricName := range run.Metrics {
+			metricNames[metricName] = struct{}{}
+		}
+	}
+	sortedFlagNames := maps.Keys(flagNames)
+	slices.Sort(sortedFlagNames)
+	sortedMetricNames := maps.Keys(metricNames)
+	slices.Sort(sortedMetricNames)
+
+	csvWriter := csv.NewWriter(w)
+	header := append([]string{}, sortedFlagNames...)
+	header = append(header, "duration")
+	header = append(header, sortedMetricNames...)
+	header = append(header, "error")
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+	for _, run := range runs {
+		row := make([]string, 0, len(header))
+		for _, flagName := range sortedFlagNames {
+			value, ok := run.Flags[flagName]
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, fmt.Sprint(value))
+		}
+		row = append(row, strconv.FormatFloat(run.Duration.Seconds(), 'f', -1, 64))
+		for _, metricName := range sortedMetricNames {
+			values, ok := run.Metrics[metricName]
+			if !ok || len(values) == 0 {
+				row = append(row, "")
+				continue
+			}
+			var sum float64
+			for _, value := range values {
+				sum += value
+			}
+			row = append(row, strconv.FormatFloat(sum/float64(len(values)), 'f', -1, 64))
+		}
+		errMsg := ""
+		if run.Err != nil {
+			errMsg = run.Err.Error()
+		}
+		row = append(row, errMsg)
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
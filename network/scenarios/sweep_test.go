@@ -0,0 +1,76 @@
+package scenarios
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweep(t *testing.T) {
+	require := require.New(t)
+	var nets []*networkfakes.Network
+	opts := SweepOptions{
+		NetworkConfig: network.Config{
+			NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}},
+			Flags:       map[string]interface{}{"log-level": "info"},
+		},
+		Params: []SweepParam{
+			{Flag: "snow-sample-size", Values: []interface{}{10, 20}},
+			{Flag: "consensus-app-concurrency", Values: []interface{}{1, 2}},
+		},
+		NewNetwork: func(_ context.Context, networkConfig network.Config) (network.Network, error) {
+			net, err := networkfakes.NewNetwork(networkConfig)
+			if err != nil {
+				return nil, err
+			}
+			require.Equal("info", networkConfig.Flags["log-level"])
+			net.SetMetric("avalanche_accepted", float64(networkConfig.Flags["snow-sample-size"].(int)))
+			nets = append(nets, net)
+			return net, nil
+		},
+		Scenario: func(_ context.Context, net network.Network) error {
+			if len(nets) == 2 {
+				return errors.New("scenario failed")
+			}
+			return nil
+		},
+		Metrics: []string{"avalanche_accepted"},
+	}
+	runs, err := Sweep(context.Background(), opts)
+	require.NoError(err)
+	require.Len(runs, 4)
+	require.Equal(map[string]interface{}{"snow-sample-size": 10, "consensus-app-concurrency": 1}, runs[0].Flags)
+	require.Equal(map[string]interface{}{"snow-sample-size": 10, "consensus-app-concurrency": 2}, runs[1].Flags)
+	require.Equal(map[string]interface{}{"snow-sample-size": 20, "consensus-app-concurrency": 1}, runs[2].Flags)
+	require.NoError(runs[0].Err)
+	require.Equal(map[string]float64{"node1": 10, "node2": 10}, runs[0].Metrics["avalanche_accepted"])
+	require.ErrorContains(runs[1].Err, "scenario failed")
+	require.Equal(map[string]float64{"node1": 20, "node2": 20}, runs[3].Metrics["avalanche_accepted"])
+	// each network is stopped
+	for _, net := range nets {
+		_, err := net.GetNodeNames()
+		require.ErrorIs(err, network.ErrStopped)
+	}
+	// the given config is not changed
+	require.Len(opts.NetworkConfig.Flags, 1)
+
+	var buf bytes.Buffer
+	require.NoError(WriteSweepCSV(&buf, runs[:2]))
+	require.Equal(
+		"consensus-app-concurrency,snow-sample-size,duration,avalanche_accepted,error\n"+
+			"1,10,"+strconv.FormatFloat(runs[0].Duration.Seconds(), 'f', -1, 64)+",10,\n"+
+			"2,10,0,,scenario failed: scenario failed\n",
+		buf.String(),
+	)
+
+	opts.Params = append(opts.Params, SweepParam{Flag: "snow-quorum-size"})
+	_, err = Sweep(context.Background(), opts)
+	require.Error(err)
+}
@@ -0,0 +1,63 @@
+package network
+
+import "time"
+
+// StartupPhase is a phase of the start of a node process.
+// The phases happen one after the other, in the order of StartupPhases.
+type StartupPhase string
+
+const (
+	// Loading, or generating, the staking cert and keys of the node
+	StartupPhaseKeyGeneration StartupPhase = "keyGeneration"
+	// Setting up the dirs, config files and flags of the node
+	StartupPhaseDirSetup StartupPhase = "dirSetup"
+	// Starting the node process, until its API port is known
+	StartupPhaseProcessStart StartupPhase = "processStart"
+	// Until the node first answers a health request, healthy or not
+	StartupPhaseFirstHealthResponse StartupPhase = "firstHealthResponse"
+	// Until the node is first seen healthy, which requires it to have
+	// bootstrapped its chains
+	StartupPhaseBootstrapped StartupPhase = "bootstrapped"
+)
+
+// StartupPhases are the phases of the start of a node, in order
+var StartupPhases = []StartupPhase{
+	StartupPhaseKeyGeneration,
+	StartupPhaseDirSetup,
+	StartupPhaseProcessStart,
+	StartupPhaseFirstHealthResponse,
+	StartupPhaseBootstrapped,
+}
+
+// NodeStartupReport tells where the time went in the last start of a node,
+// e.g. on AddNode, ResumeNode or RestartNode.
+type NodeStartupReport struct {
+	// When the node started being added
+	StartTime time.Time `json:"startTime"`
+	// Duration of each completed phase, since the end of the previous one.
+	// The phases after the process start are only completed when observed by
+	// health requests, e.g. on Network.Healthy, so they are accurate to the
+	// health check frequency.
+	Phases map[StartupPhase]time.Duration `json:"phases"`
+}
+
+// Total returns the duration of the completed phases
+func (r NodeStartupReport) Total() time.Duration {
+	total := time.Duration(0)
+	for _, d := range r.Phases {
+		total += d
+	}
+	return total
+}
+
+// StartupReport tells where the time went in the start of a network,
+// see Network.StartupReport.
+type StartupReport struct {
+	// When the network was created
+	StartTime time.Time `json:"startTime"`
+	// Time from StartTime until the network was first seen healthy, or 0 if
+	// it wasn't yet
+	Healthy time.Duration `json:"healthy"`
+	// Node name --> report of the last start of the node
+	Nodes map[string]NodeStartupReport `json:"nodes"`
+}
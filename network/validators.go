@@ -0,0 +1,132 @@
+package network
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+)
+
+// ValidatorsReport aggregates what the running nodes of a network report
+// about the primary network validators and their uptimes. See GetValidatorsReport.
+type ValidatorsReport struct {
+	// Validator node ID --> what the nodes report about it
+	Validators map[ids.NodeID]*ValidatorReport
+	// Node name --> the node's own uptime, as seen by the validators it is
+	// connected to, which is what its reward depends on
+	NodeUptimes map[string]info.UptimeResponse
+}
+
+// ValidatorReport is what the nodes report about a primary network validator
+type ValidatorReport struct {
+	NodeID    ids.NodeID
+	TxID      ids.ID
+	StartTime time.Time
+	EndTime   time.Time
+	Weight    uint64
+	// Reward given to the validator when its staking period ends,
+	// if its uptime is enough
+	PotentialReward uint64
+	// Delegation fees accrued so far from its delegators
+	AccruedDelegateeReward uint64
+	// Node name --> uptime of the validator observed by the node, in percent
+	Uptimes map[string]float32
+	// Node name --> whether the node is connected to the validator
+	Connected map[string]bool
+}
+
+// MinUptime returns the lowest uptime observed by any node, or 0 if
+// no node reported it.
+func (r *ValidatorReport) MinUptime() float32 {
+	if len(r.Uptimes) == 0 {
+		return 0
+	}
+	minUptime := float32(100)
+	for _, uptime := range r.Uptimes {
+		minUptime = min(minUptime, uptime)
+	}
+	return minUptime
+}
+
+// MeanUptime returns the mean of the uptimes observed by the nodes,
+// or 0 if no node reported it.
+func (r *ValidatorReport) MeanUptime() float32 {
+	if len(r.Uptimes) == 0 {
+		return 0
+	}
+	var sum float32
+	for _, uptime := range r.Uptimes {
+		sum += uptime
+	}
+	return sum / float32(len(r.Uptimes))
+}
+
+// GetValidatorsReport asks each running node of [net] for the current
+// primary network validators, as it observes them, and for its own uptime,
+// so that uptime based rewards can be checked. Rewards are as reported by
+// the first node by name, as they don't depend on the node asked.
+// Timeout is given by the context parameter.
+func GetValidatorsReport(ctx context.Context, net Network) (ValidatorsReport, error) {
+	nodes, err := net.GetAllNodes()
+	if err != nil {
+		return ValidatorsReport{}, err
+	}
+	nodeNames := make([]string, 0, len(nodes))
+	for nodeName, n := range nodes {
+		if !n.GetPaused() {
+			nodeNames = append(nodeNames, nodeName)
+		}
+	}
+	if len(nodeNames) == 0 {
+		return ValidatorsReport{}, ErrNoRunningNodes
+	}
+	sort.Strings(nodeNames)
+
+	report := ValidatorsReport{
+		Validators:  map[ids.NodeID]*ValidatorReport{},
+		NodeUptimes: map[string]info.UptimeResponse{},
+	}
+	for _, nodeName := range nodeNames {
+		client := nodes[nodeName].GetAPIClient()
+		validators, err := client.PChainAPI().GetCurrentValidators(ctx, constants.PrimaryNetworkID, nil)
+		if err != nil {
+			return ValidatorsReport{}, &NodeError{NodeName: nodeName, Op: "get validators of", Err: err}
+		}
+		for _, validator := range validators {
+			validatorReport, ok := report.Validators[validator.NodeID]
+			if !ok {
+				validatorReport = &ValidatorReport{
+					NodeID:    validator.NodeID,
+					TxID:      validator.TxID,
+					StartTime: time.Unix(int64(validator.StartTime), 0),
+					EndTime:   time.Unix(int64(validator.EndTime), 0),
+					Weight:    validator.Weight,
+					Uptimes:   map[string]float32{},
+					Connected: map[string]bool{},
+				}
+				if validator.PotentialReward != nil {
+					validatorReport.PotentialReward = *validator.PotentialReward
+				}
+				if validator.AccruedDelegateeReward != nil {
+					validatorReport.AccruedDelegateeReward = *validator.AccruedDelegateeReward
+				}
+				report.Validators[validator.NodeID] = validatorReport
+			}
+			if validator.Uptime != nil {
+				validatorReport.Uptimes[nodeName] = *validator.Uptime
+			}
+			if validator.Connected != nil {
+				validatorReport.Connected[nodeName] = *validator.Connected
+			}
+		}
+		uptime, err := client.InfoAPI().Uptime(ctx)
+		if err != nil {
+			return ValidatorsReport{}, &NodeError{NodeName: nodeName, Op: "get uptime of", Err: err}
+		}
+		report.NodeUptimes[nodeName] = *uptime
+	}
+	return report, nil
+}
@@ -0,0 +1,110 @@
+package network_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apimocks "github.com/ava-labs/avalanche-network-runner/api/mocks"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/stretchr/testify/require"
+)
+
+// validatorsPClient is a platformvm.Client whose GetCurrentValidators
+// method returns [validators]. Its other methods must not be called.
+type validatorsPClient struct {
+	platformvm.Client
+	validators []platformvm.ClientPermissionlessValidator
+	err        error
+}
+
+func (c validatorsPClient) GetCurrentValidators(context.Context, ids.ID, []ids.NodeID, ...rpc.Option) ([]platformvm.ClientPermissionlessValidator, error) {
+	return c.validators, c.err
+}
+
+// uptimeInfoClient is an info.Client whose Uptime method returns [uptime].
+// Its other methods must not be called.
+type uptimeInfoClient struct {
+	info.Client
+	uptime float64
+}
+
+func (c uptimeInfoClient) Uptime(context.Context, ...rpc.Option) (*info.UptimeResponse, error) {
+	return &info.UptimeResponse{
+		RewardingStakePercentage:  json.Float64(c.uptime),
+		WeightedAveragePercentage: json.Float64(c.uptime),
+	}, nil
+}
+
+func newValidator(nodeID ids.NodeID, uptime float32, connected bool, reward uint64) platformvm.ClientPermissionlessValidator {
+	validator := platformvm.ClientPermissionlessValidator{
+		ClientStaker: platformvm.ClientStaker{
+			NodeID:    nodeID,
+			StartTime: 100,
+			EndTime:   200,
+			Weight:    2000,
+		},
+		Uptime:    &uptime,
+		Connected: &connected,
+	}
+	if reward != 0 {
+		validator.PotentialReward = &reward
+	}
+	return validator
+}
+
+func TestGetValidatorsReport(t *testing.T) {
+	require := require.New(t)
+	net, err := networkfakes.NewNetwork(network.Config{
+		NodeConfigs: []node.Config{{Name: "node1"}, {Name: "node2"}, {Name: "paused"}},
+	})
+	require.NoError(err)
+	require.NoError(net.PauseNode(context.Background(), "paused"))
+
+	validatorID := ids.GenerateTestNodeID()
+	setClient := func(nodeName string, pClient validatorsPClient, uptime float64) {
+		n, err := net.GetNode(nodeName)
+		require.NoError(err)
+		client := &apimocks.Client{}
+		client.On("PChainAPI").Return(pClient)
+		client.On("InfoAPI").Return(uptimeInfoClient{uptime: uptime})
+		n.(*networkfakes.Node).SetAPIClient(client)
+	}
+	setClient("node1", validatorsPClient{validators: []platformvm.ClientPermissionlessValidator{
+		newValidator(validatorID, 90, true, 10),
+	}}, 95)
+	setClient("node2", validatorsPClient{validators: []platformvm.ClientPermissionlessValidator{
+		newValidator(validatorID, 70, false, 20),
+	}}, 85)
+
+	report, err := network.GetValidatorsReport(context.Background(), net)
+	require.NoError(err)
+	require.Len(report.Validators, 1)
+	validator := report.Validators[validatorID]
+	require.Equal(validatorID, validator.NodeID)
+	require.Equal(uint64(2000), validator.Weight)
+	require.Equal(int64(200), validator.EndTime.Unix())
+	// as reported by node1
+	require.Equal(uint64(10), validator.PotentialReward)
+	require.Equal(map[string]float32{"node1": 90, "node2": 70}, validator.Uptimes)
+	require.Equal(map[string]bool{"node1": true, "node2": false}, validator.Connected)
+	require.Equal(float32(70), validator.MinUptime())
+	require.Equal(float32(80), validator.MeanUptime())
+	require.Len(report.NodeUptimes, 2)
+	require.Equal(json.Float64(85), report.NodeUptimes["node2"].WeightedAveragePercentage)
+
+	errFailed := errors.New("failed")
+	setClient("node2", validatorsPClient{err: errFailed}, 85)
+	_, err = network.GetValidatorsReport(context.Background(), net)
+	require.ErrorIs(err, errFailed)
+	var nodeErr *network.NodeError
+	require.ErrorAs(err, &nodeErr)
+	require.Equal("node2", nodeErr.NodeName)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/rpcpb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// HealthzPath is the gRPC gateway path reporting whether the managed
+	// network is healthy, with status 200, or not, with status 503
+	HealthzPath = "/healthz"
+
+	healthCheckInterval = 5 * time.Second
+	healthCheckTimeout  = 3 * time.Second
+)
+
+// Registers the standard gRPC health checking service on [s.gRPCServer].
+// The overall status, of service "", is SERVING while the managed network
+// is healthy, and NOT_SERVING while there is no network, or it is being
+// started or changed, or is not healthy. The statuses of the ping and
+// control services are SERVING while the server runs, so that orchestrators
+// can probe the server itself regardless of the network.
+func (s *server) registerHealthServer() {
+	s.healthServer = health.NewServer()
+	s.healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	for _, serviceName := range []string{
+		rpcpb.PingService_ServiceDesc.ServiceName,
+		rpcpb.ControlService_ServiceDesc.ServiceName,
+	} {
+		s.healthServer.SetServingStatus(serviceName, healthpb.HealthCheckResponse_SERVING)
+	}
+	healthpb.RegisterHealthServer(s.gRPCServer, s.healthServer)
+}
+
+// Updates the overall status of [s.healthServer] every [healthCheckInterval]
+// until [ctx] is done, when all the statuses are set to NOT_SERVING.
+func (s *server) updateHealthStatus(ctx context.Context) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		servingStatus := healthpb.HealthCheckResponse_NOT_SERVING
+		if s.isNetworkHealthy(ctx) {
+			servingStatus = healthpb.HealthCheckResponse_SERVING
+		}
+		s.healthServer.SetServingStatus("", servingStatus)
+		select {
+		case <-ctx.Done():
+			s.healthServer.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Returns true if there is a network that was seen healthy,
+// and still reports healthy within [healthCheckTimeout].
+// Doesn't wait for [s.mu], as the network is not considered healthy
+// while it is being started or changed.
+func (s *server) isNetworkHealthy(ctx context.Context) bool {
+	if !s.mu.TryRLock() {
+		return false
+	}
+	defer s.mu.RUnlock()
+
+	if s.network == nil || s.clusterInfo == nil || !s.clusterInfo.Healthy {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	if err := s.network.nw.Healthy(ctx); err != nil {
+		s.log.Debug("network health check failed", zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// Serves [HealthzPath] with the overall status of [s.healthServer]
+func (s *server) healthzHandler(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	resp, err := s.healthServer.Check(r.Context(), &healthpb.HealthCheckRequest{})
+	if err != nil || resp.Status != healthpb.HealthCheckResponse_SERVING {
+		http.Error(w, healthpb.HealthCheckResponse_NOT_SERVING.String(), http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = w.Write([]byte(healthpb.HealthCheckResponse_SERVING.String()))
+}
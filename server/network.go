@@ -17,6 +17,7 @@ import (
 
 	"github.com/ava-labs/avalanche-network-runner/local"
 	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/monitoring"
 	"github.com/ava-labs/avalanche-network-runner/network/node"
 	"github.com/ava-labs/avalanche-network-runner/rpcpb"
 	"github.com/ava-labs/avalanche-network-runner/utils"
@@ -26,31 +27,11 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	avago_constants "github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"go.uber.org/zap"
 	"golang.org/x/exp/maps"
 )
 
-const (
-	prometheusConfFname  = "prometheus.yaml"
-	prometheusConfCommon = `global:
-  scrape_interval: 15s
-  evaluation_interval: 15s
-scrape_configs:
-  - job_name: prometheus
-    static_configs:
-      - targets:
-        - localhost:9090
-  - job_name: avalanchego-machine
-    static_configs:
-     - targets:
-       - localhost:9100
-       labels:
-         alias: machine
-  - job_name: avalanchego
-    metrics_path: /ext/metrics
-    static_configs:
-      - targets:
-`
-)
+const prometheusConfFname = monitoring.PrometheusConfigFileName
 
 type localNetwork struct {
 	lock sync.Mutex
@@ -79,6 +60,9 @@ type localNetwork struct {
 	subnets map[string]*rpcpb.SubnetInfo
 
 	prometheusConfPath string
+
+	// true once the ready command was run
+	readyNotified bool
 }
 
 type chainInfo struct {
@@ -128,6 +112,13 @@ type localNetworkOptions struct {
 
 	// do not repeate past node IDs
 	freshStakingIds bool
+
+	// external tools notified each time the network is seen healthy,
+	// the command only the first time
+	readyOptions network.ReadyOptions
+
+	// labels of the network, see network.Config.Labels
+	labels map[string]string
 }
 
 func newLocalNetwork(opts localNetworkOptions) (*localNetwork, error) {
@@ -185,6 +176,8 @@ func (lc *localNetwork) createConfig() error {
 		cfg.Flags[k] = v
 	}
 
+	cfg.Labels = lc.options.labels
+
 	if lc.pluginDir != "" {
 		cfg.Flags[config.PluginDirKey] = lc.pluginDir
 	}
@@ -334,10 +327,14 @@ func (lc *localNetwork) CreateChains(
 		return nil, err
 	}
 
-	chainIDs, err := lc.nw.CreateBlockchains(ctx, chainSpecs)
+	chainInfos, err := lc.nw.CreateBlockchains(ctx, chainSpecs)
 	if err != nil {
 		return nil, err
 	}
+	chainIDs := make([]ids.ID, 0, len(chainInfos))
+	for _, chainInfo := range chainInfos {
+		chainIDs = append(chainIDs, chainInfo.ID)
+	}
 
 	if err := lc.awaitHealthyAndUpdateNetworkInfo(ctx); err != nil {
 		return nil, err
@@ -799,6 +796,17 @@ func (lc *localNetwork) awaitHealthyAndUpdateNetworkInfo(ctx context.Context) er
 		lc.log.Debug(fmt.Sprintf(logging.Cyan.Wrap("node-info: node-name %s, node-ID: %s, URI: %s"), nodeName, nodeInfo.Id, nodeInfo.Uri))
 	}
 
+	readyOptions := lc.options.readyOptions
+	if lc.readyNotified {
+		readyOptions.Command = nil
+	}
+	if readyOptions.File != "" || len(readyOptions.Command) != 0 || readyOptions.HostsFile != "" {
+		if err := network.NotifyReady(ctx, lc.nw, readyOptions); err != nil {
+			return err
+		}
+		lc.readyNotified = true
+	}
+
 	return nil
 }
 
@@ -838,6 +846,9 @@ func (lc *localNetwork) updateNodeInfo() error {
 			WhitelistedSubnets: trackSubnets,
 			Paused:             node.GetPaused(),
 		}
+		if startTime := node.StartTime(); !startTime.IsZero() {
+			lc.nodeInfos[name].StartTime = startTime.Unix()
+		}
 
 		// update default exec and pluginDir if empty (snapshots started without these params)
 		if lc.execPath == "" {
@@ -855,18 +866,26 @@ func (lc *localNetwork) generatePrometheusConf() error {
 		lc.prometheusConfPath = filepath.Join(lc.nw.GetRootDir(), prometheusConfFname)
 		lc.log.Info(fmt.Sprintf(logging.Cyan.Wrap("prometheus conf file %s"), lc.prometheusConfPath))
 	}
-	prometheusConf := prometheusConfCommon
-	for _, nodeInfo := range lc.nodeInfos {
-		if !nodeInfo.Paused {
-			prometheusConf += "        - " + strings.TrimPrefix(nodeInfo.Uri, "http://") + "\n"
+	names := maps.Keys(lc.nodeInfos)
+	sort.Strings(names)
+	targets := []string{}
+	for _, name := range names {
+		if nodeInfo := lc.nodeInfos[name]; !nodeInfo.Paused {
+			targets = append(targets, strings.TrimPrefix(nodeInfo.Uri, "http://"))
 		}
 	}
+	// the network labels are added to all the node metrics
+	networkConfig, err := lc.nw.ToConfig()
+	if err != nil {
+		return err
+	}
+	prometheusConf := monitoring.PrometheusConfig(targets, networkConfig.Labels)
 	file, err := os.Create(lc.prometheusConfPath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
-	_, err = file.Write([]byte(prometheusConf))
+	_, err = file.Write(prometheusConf)
 	return err
 }
 
@@ -886,6 +905,11 @@ func (lc *localNetwork) Stop(ctx context.Context) {
 			}
 			ux.Print(lc.log, logging.Red.Wrap(msg))
 		}
+		if hostsFile := lc.options.readyOptions.HostsFile; hostsFile != "" {
+			if err := network.RemoveHostsFileEntries(hostsFile, lc.options.readyOptions.HostsDomain); err != nil {
+				lc.log.Warn("couldn't remove hosts file entries", zap.String("path", hostsFile), zap.Error(err))
+			}
+		}
 	})
 }
 
@@ -40,7 +40,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -81,6 +83,20 @@ type Config struct {
 	RedirectNodesOutput bool
 	SnapshotsDir        string
 	LogLevel            logging.Level
+	// If set, the network's endpoint manifest is written to this file
+	// each time the network is seen healthy. See network.NotifyReady.
+	ReadyFile string
+	// If set, run with "sh -c" the first time each network is seen healthy,
+	// with the endpoint manifest on its stdin. See network.NotifyReady.
+	ReadyCommand string
+	// If set, the hostnames of the nodes in HostsDomain are written to this
+	// hosts file each time the network is seen healthy, and removed when it
+	// stops. See network.WriteHostsFile.
+	HostsFile   string
+	HostsDomain string
+	// Labels of the networks started, e.g. the CI job and branch, added to
+	// the metrics on the prometheus config. See network.Config.Labels.
+	Labels map[string]string
 }
 
 type Server interface {
@@ -103,6 +119,9 @@ type server struct {
 	gwMux    *runtime.ServeMux
 	gwServer *http.Server
 
+	// Standard gRPC health checking service, also served on [HealthzPath]
+	healthServer *health.Server
+
 	clusterInfo *rpcpb.ClusterInfo
 	// Controls running nodes.
 	// Invariant: If [network] is non-nil, then [clusterInfo] is non-nil.
@@ -164,6 +183,8 @@ func (s *server) Run(rootCtx context.Context) (err error) {
 
 	rpcpb.RegisterPingServiceServer(s.gRPCServer, s)
 	rpcpb.RegisterControlServiceServer(s.gRPCServer, s)
+	s.registerHealthServer()
+	go s.updateHealthStatus(s.rootCtx)
 
 	gRPCErrChan := make(chan error)
 	go func() {
@@ -200,6 +221,10 @@ func (s *server) Run(rootCtx context.Context) (err error) {
 				gwErrChan <- err
 				return
 			}
+			if err := s.gwMux.HandlePath(http.MethodGet, HealthzPath, s.healthzHandler); err != nil {
+				gwErrChan <- err
+				return
+			}
 
 			s.log.Info("serving gRPC gateway", zap.String("port", s.cfg.GwPort))
 			gwErrChan <- s.gwServer.ListenAndServe()
@@ -251,6 +276,19 @@ func (s *server) Run(rootCtx context.Context) (err error) {
 	return err
 }
 
+// Returns the options to notify external tools when the network is ready
+func (s *server) getReadyOptions() network.ReadyOptions {
+	readyOptions := network.ReadyOptions{
+		File:        s.cfg.ReadyFile,
+		HostsFile:   s.cfg.HostsFile,
+		HostsDomain: s.cfg.HostsDomain,
+	}
+	if s.cfg.ReadyCommand != "" {
+		readyOptions.Command = []string{"sh", "-c", s.cfg.ReadyCommand}
+	}
+	return readyOptions
+}
+
 func (s *server) Ping(context.Context, *rpcpb.PingRequest) (*rpcpb.PingResponse, error) {
 	s.log.Debug("received ping request")
 	return &rpcpb.PingResponse{Pid: int32(os.Getpid())}, nil
@@ -362,6 +400,8 @@ func (s *server) Start(callContext context.Context, req *rpcpb.StartRequest) (*r
 		reassignPortsIfUsed: req.GetReassignPortsIfUsed(),
 		dynamicPorts:        req.GetDynamicPorts(),
 		snapshotsDir:        s.cfg.SnapshotsDir,
+		readyOptions:        s.getReadyOptions(),
+		labels:              s.cfg.Labels,
 		genesisPath:         req.GenesisPath,
 		beaconConfig:        beaconConfig,
 		upgradePath:         req.UpgradePath,
@@ -977,7 +1017,23 @@ func (s *server) Status(context.Context, *rpcpb.StatusRequest) (*rpcpb.StatusRes
 		return &rpcpb.StatusResponse{}, ErrNotBootstrapped
 	}
 
-	return &rpcpb.StatusResponse{ClusterInfo: s.clusterInfo}, nil
+	return &rpcpb.StatusResponse{ClusterInfo: withUptimes(s.clusterInfo)}, nil
+}
+
+// withUptimes returns a copy of [clusterInfo] with the node uptimes
+// updated to the current time, as node infos are only refreshed on changes
+func withUptimes(clusterInfo *rpcpb.ClusterInfo) *rpcpb.ClusterInfo {
+	if clusterInfo == nil {
+		return nil
+	}
+	clusterInfo = proto.Clone(clusterInfo).(*rpcpb.ClusterInfo)
+	now := time.Now()
+	for _, nodeInfo := range clusterInfo.NodeInfos {
+		if nodeInfo.StartTime != 0 {
+			nodeInfo.Uptime = int64(now.Sub(time.Unix(nodeInfo.StartTime, 0)).Seconds())
+		}
+	}
+	return clusterInfo
 }
 
 // Assumes [s.mu] is held.
@@ -1066,7 +1122,7 @@ func (s *server) sendLoop(stream rpcpb.ControlService_StreamStatusServer, interv
 		s.log.Debug("sending cluster info")
 
 		s.mu.RLock()
-		err := stream.Send(&rpcpb.StreamStatusResponse{ClusterInfo: s.clusterInfo})
+		err := stream.Send(&rpcpb.StreamStatusResponse{ClusterInfo: withUptimes(s.clusterInfo)})
 		s.mu.RUnlock()
 		if err != nil {
 			if isClientCanceled(stream.Context().Err(), err) {
@@ -1410,6 +1466,7 @@ func (s *server) LoadSnapshot(
 		logLevel:            s.cfg.LogLevel,
 		reassignPortsIfUsed: req.GetReassignPortsIfUsed(),
 		snapshotsDir:        s.cfg.SnapshotsDir,
+		readyOptions:        s.getReadyOptions(),
 		zeroIP:              req.ZeroIp,
 	})
 	if err != nil {
@@ -0,0 +1,117 @@
+package testsetup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+)
+
+var (
+	sharedNetworkLock sync.RWMutex
+	// network of the tests of the package, set by TestMain
+	sharedNetwork network.Network
+)
+
+// testRunner runs the tests of a package, as testing.M does
+type testRunner interface {
+	Run() int
+}
+
+// Network returns the network shared by the tests of the package, created
+// by TestMain, or nil if TestMain isn't used
+func Network() network.Network {
+	sharedNetworkLock.RLock()
+	defer sharedNetworkLock.RUnlock()
+	return sharedNetwork
+}
+
+// TestMain is TestMainOptions with the default options
+func TestMain(m *testing.M, networkConfig network.Config) {
+	TestMainOptions(m, networkConfig, Options{})
+}
+
+// TestMainOptions is to be called by the TestMain of a package, so that its
+// tests share a network, given by Network. It creates a network from
+// [networkConfig], as RunWithNetworkOptions does, waits for it to be
+// healthy, runs the tests with [m], and stops the network. If a test fails,
+// or the network can't be created or isn't healthy in time, the root data
+// dir is kept as artifacts named after the package dir. It then exits with
+// the exit code of the tests, or 1 on a network failure.
+func TestMainOptions(m *testing.M, networkConfig network.Config, opts Options) {
+	name := "TestMain"
+	if wd, err := os.Getwd(); err == nil {
+		name = filepath.Base(wd)
+	}
+	os.Exit(runMain(m, networkConfig, opts, name, os.Stderr))
+}
+
+// See TestMainOptions.
+// Returns the exit code of the tests run by [m]. [name] names the artifacts,
+// and the failures are written to [out].
+func runMain(m testRunner, networkConfig network.Config, opts Options, name string, out io.Writer) int {
+	logf := func(format string, args ...interface{}) {
+		fmt.Fprintf(out, "testsetup: "+format+"\n", args...)
+	}
+	if networkConfig.RootDataDir.Mode != network.DefaultRootDataDir {
+		logf("the root data dir is managed by TestMain, and can't be given on the config")
+		return 1
+	}
+	opts.setDefaults()
+	if !opts.KeepPorts {
+		var err error
+		networkConfig, err = withoutPorts(networkConfig)
+		if err != nil {
+			logf("couldn't remove ports from the network config: %s", err)
+			return 1
+		}
+	}
+
+	rootDir, err := os.MkdirTemp("", "testsetup-"+sanitizeTestName(name)+"-")
+	if err != nil {
+		logf("couldn't create root data dir: %s", err)
+		return 1
+	}
+	net, err := opts.NewNetwork(networkConfig, rootDir)
+	if err != nil {
+		logf("couldn't create network: %s", err)
+		copyArtifacts(rootDir, opts.ArtifactsDir, name, logf)
+		return 1
+	}
+
+	code := 1
+	ctx, cancel := context.WithTimeout(context.Background(), opts.HealthyTimeout)
+	err = net.Healthy(ctx)
+	cancel()
+	if err != nil {
+		logf("network not healthy: %s", err)
+	} else {
+		sharedNetworkLock.Lock()
+		sharedNetwork = net
+		sharedNetworkLock.Unlock()
+		code = m.Run()
+		sharedNetworkLock.Lock()
+		sharedNetwork = nil
+		sharedNetworkLock.Unlock()
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), opts.TeardownTimeout)
+	defer cancel()
+	if err := net.Stop(ctx); err != nil {
+		logf("couldn't stop network: %s", err)
+		if code == 0 {
+			code = 1
+		}
+	}
+	if code != 0 {
+		copyArtifacts(rootDir, opts.ArtifactsDir, name, logf)
+	} else if err := os.RemoveAll(rootDir); err != nil {
+		logf("couldn't remove root data dir %q: %s", rootDir, err)
+	}
+	return code
+}
@@ -0,0 +1,216 @@
+// Package testsetup runs test code on a network that is created,
+// waited for and torn down for it, or shared by the tests of a package
+// with TestMain.
+package testsetup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	// registers the default backend
+	_ "github.com/ava-labs/avalanche-network-runner/local"
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/utils"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	dircopy "github.com/otiai10/copy"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	// DefaultHealthyTimeout is how long RunWithNetwork waits
+	// for the network to be healthy by default
+	DefaultHealthyTimeout = 5 * time.Minute
+	// DefaultTeardownTimeout is how long RunWithNetwork waits
+	// for the network to stop by default
+	DefaultTeardownTimeout = 30 * time.Second
+)
+
+// flags holding ports, removed from the configs unless the ports are kept
+var portFlags = []string{config.HTTPPortKey, config.StakingPortKey}
+
+// Options configure RunWithNetworkOptions and TestMainOptions
+type Options struct {
+	// Creates the network, with [rootDir] as root data dir. Defaults to
+	// network.NewBackendNetwork, so that the backend is selected by the
+	// config, which on the local backend reassigns the ports found in use.
+	NewNetwork func(networkConfig network.Config, rootDir string) (network.Network, error)
+	// Log of the networks created by default
+	Log logging.Logger
+	// How long to wait for the network to be healthy. It is also bounded by
+	// the test deadline, keeping TeardownTimeout for the teardown.
+	// Defaults to DefaultHealthyTimeout.
+	HealthyTimeout time.Duration
+	// Defaults to DefaultTeardownTimeout
+	TeardownTimeout time.Duration
+	// If set, the root data dir of the network, with the logs and configs of
+	// its nodes, is copied to <ArtifactsDir>/<test name> if the test fails.
+	// Otherwise, it is kept where it is, and its path is logged.
+	ArtifactsDir string
+	// If true, the ports given in the config are kept. Otherwise, they are
+	// removed, so that free ports are assigned to the nodes, and tests
+	// creating networks from the same config can run in parallel.
+	KeepPorts bool
+}
+
+// Sets the defaults of the options not given
+func (opts *Options) setDefaults() {
+	if opts.HealthyTimeout == 0 {
+		opts.HealthyTimeout = DefaultHealthyTimeout
+	}
+	if opts.TeardownTimeout == 0 {
+		opts.TeardownTimeout = DefaultTeardownTimeout
+	}
+	if opts.Log == nil {
+		opts.Log = logging.NoLog{}
+	}
+	if opts.NewNetwork == nil {
+		log := opts.Log
+		opts.NewNetwork = func(networkConfig network.Config, rootDir string) (network.Network, error) {
+			return network.NewBackendNetwork(log, networkConfig, rootDir)
+		}
+	}
+}
+
+// RunWithNetwork is RunWithNetworkOptions with the default options
+func RunWithNetwork(t testing.TB, networkConfig network.Config, f func(net network.Network)) {
+	t.Helper()
+	RunWithNetworkOptions(t, networkConfig, Options{}, f)
+}
+
+// RunWithNetworkOptions creates a network from [networkConfig] in a new root
+// data dir, waits for it to be healthy, and runs [f] on it. The network is
+// stopped once [f] returns, or the test fails, and its root data dir is
+// removed if the test passed. The test fails if the network can't be
+// created, isn't healthy in time, or can't be stopped.
+// The config given is not modified.
+func RunWithNetworkOptions(t testing.TB, networkConfig network.Config, opts Options, f func(net network.Network)) {
+	t.Helper()
+	if networkConfig.RootDataDir.Mode != network.DefaultRootDataDir {
+		t.Fatal("the root data dir is managed by RunWithNetwork, and can't be given on the config")
+	}
+	opts.setDefaults()
+	if !opts.KeepPorts {
+		var err error
+		networkConfig, err = withoutPorts(networkConfig)
+		if err != nil {
+			t.Fatalf("couldn't remove ports from the network config: %s", err)
+		}
+	}
+
+	rootDir, err := os.MkdirTemp("", "testsetup-"+sanitizeTestName(t.Name())+"-")
+	if err != nil {
+		t.Fatalf("couldn't create root data dir: %s", err)
+	}
+	net, err := opts.NewNetwork(networkConfig, rootDir)
+	if err != nil {
+		keepArtifacts(t, rootDir, opts.ArtifactsDir)
+		t.Fatalf("couldn't create network: %s", err)
+	}
+	// also run when [f] or the health check fail the test
+	defer teardown(t, net, rootDir, opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.HealthyTimeout)
+	defer cancel()
+	if deadline, ok := getDeadline(t); ok {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadline(ctx, deadline.Add(-opts.TeardownTimeout))
+		defer cancelDeadline()
+	}
+	if err := net.Healthy(ctx); err != nil {
+		t.Fatalf("network not healthy: %s", err)
+	}
+	f(net)
+}
+
+// Stops [net], and removes [rootDir] if the test passed,
+// or keeps it as artifacts of the failure otherwise
+func teardown(t testing.TB, net network.Network, rootDir string, opts Options) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), opts.TeardownTimeout)
+	defer cancel()
+	if err := net.Stop(ctx); err != nil {
+		t.Errorf("couldn't stop network: %s", err)
+	}
+	if t.Failed() {
+		keepArtifacts(t, rootDir, opts.ArtifactsDir)
+		return
+	}
+	if err := os.RemoveAll(rootDir); err != nil {
+		t.Logf("couldn't remove root data dir %q: %s", rootDir, err)
+	}
+}
+
+// Copies [rootDir] to [artifactsDir], if given, or logs where it is kept
+func keepArtifacts(t testing.TB, rootDir string, artifactsDir string) {
+	t.Helper()
+	copyArtifacts(rootDir, artifactsDir, t.Name(), t.Logf)
+}
+
+// Copies [rootDir] to <[artifactsDir]>/<[name]>, if [artifactsDir] is
+// given, or logs with [logf] where it is kept
+func copyArtifacts(rootDir string, artifactsDir string, name string, logf func(format string, args ...interface{})) {
+	if artifactsDir == "" {
+		logf("network root data dir kept at %q", rootDir)
+		return
+	}
+	dest := filepath.Join(artifactsDir, sanitizeTestName(name))
+	if err := dircopy.Copy(rootDir, dest); err != nil {
+		logf("couldn't copy network root data dir %q to %q: %s", rootDir, dest, err)
+		return
+	}
+	if err := os.RemoveAll(rootDir); err != nil {
+		logf("couldn't remove root data dir %q: %s", rootDir, err)
+	}
+	logf("network root data dir copied to %q", dest)
+}
+
+// Returns a copy of [networkConfig] without the ports
+// given on its flags, nor on the flags and config files of its nodes
+func withoutPorts(networkConfig network.Config) (network.Config, error) {
+	networkConfig.Flags = removeKeys(networkConfig.Flags, portFlags)
+	networkConfig.NodeConfigs = append(networkConfig.NodeConfigs[:0:0], networkConfig.NodeConfigs...)
+	for i := range networkConfig.NodeConfigs {
+		nodeConfig := &networkConfig.NodeConfigs[i]
+		nodeConfig.Flags = removeKeys(nodeConfig.Flags, portFlags)
+		if nodeConfig.ConfigFile == "" {
+			continue
+		}
+		for _, flagName := range portFlags {
+			var err error
+			nodeConfig.ConfigFile, err = utils.SetJSONKey(nodeConfig.ConfigFile, flagName, "")
+			if err != nil {
+				return network.Config{}, fmt.Errorf("couldn't parse node %q config file: %w", nodeConfig.Name, err)
+			}
+		}
+	}
+	return networkConfig, nil
+}
+
+// Returns a copy of [m] without [keys]
+func removeKeys(m map[string]interface{}, keys []string) map[string]interface{} {
+	copied := maps.Clone(m)
+	for _, k := range keys {
+		delete(copied, k)
+	}
+	return copied
+}
+
+// Returns the deadline of [t], if it is a test run with a timeout
+func getDeadline(t testing.TB) (time.Time, bool) {
+	deadliner, ok := t.(interface{ Deadline() (time.Time, bool) })
+	if !ok {
+		return time.Time{}, false
+	}
+	return deadliner.Deadline()
+}
+
+// Returns [name] usable as a file name, as subtest names have slashes
+func sanitizeTestName(name string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", " ", "_", ":", "_").Replace(name)
+}
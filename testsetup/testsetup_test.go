@@ -0,0 +1,237 @@
+package testsetup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanche-network-runner/network"
+	"github.com/ava-labs/avalanche-network-runner/network/networkfakes"
+	"github.com/ava-labs/avalanche-network-runner/network/node"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTB records the failures and logs of a test, so that
+// RunWithNetwork can be checked to fail the test
+type recordingTB struct {
+	testing.TB
+	name   string
+	lock   sync.Mutex
+	failed bool
+	logs   []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Name() string {
+	return tb.name
+}
+
+func (tb *recordingTB) Logf(format string, args ...interface{}) {
+	tb.lock.Lock()
+	defer tb.lock.Unlock()
+	tb.logs = append(tb.logs, fmt.Sprintf(format, args...))
+}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.Logf(format, args...)
+	tb.lock.Lock()
+	defer tb.lock.Unlock()
+	tb.failed = true
+}
+
+func (tb *recordingTB) Fatal(args ...interface{}) {
+	tb.Errorf("%s", fmt.Sprint(args...))
+	runtime.Goexit()
+}
+
+func (tb *recordingTB) Fatalf(format string, args ...interface{}) {
+	tb.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+func (tb *recordingTB) Failed() bool {
+	tb.lock.Lock()
+	defer tb.lock.Unlock()
+	return tb.failed
+}
+
+// Runs RunWithNetworkOptions with [tb] on a new goroutine, so that it can
+// end it as a failed test would
+func runRecorded(tb *recordingTB, networkConfig network.Config, opts Options, f func(net network.Network)) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		RunWithNetworkOptions(tb, networkConfig, opts, f)
+	}()
+	<-done
+}
+
+func TestRunWithNetwork(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	networkConfig := network.Config{
+		Flags: map[string]interface{}{config.HTTPPortKey: 9650, config.LogLevelKey: "info"},
+		NodeConfigs: []node.Config{
+			{Name: "node1", Flags: map[string]interface{}{config.StakingPortKey: 9651}},
+			{Name: "node2", ConfigFile: `{"http-port": 9652, "log-level": "debug"}`},
+		},
+	}
+	var (
+		gotConfig  network.Config
+		gotRootDir string
+		fakeNet    *networkfakes.Network
+	)
+	opts := Options{
+		NewNetwork: func(networkConfig network.Config, rootDir string) (network.Network, error) {
+			gotConfig, gotRootDir = networkConfig, rootDir
+			var err error
+			fakeNet, err = networkfakes.NewNetwork(networkConfig)
+			return fakeNet, err
+		},
+	}
+	ran := false
+	RunWithNetworkOptions(t, networkConfig, opts, func(net network.Network) {
+		ran = true
+		require.Same(fakeNet, net)
+		require.DirExists(gotRootDir)
+	})
+	require.True(ran)
+	// the network is stopped, and its root data dir removed
+	_, err := fakeNet.GetNodeNames()
+	require.ErrorIs(err, network.ErrStopped)
+	require.NoDirExists(gotRootDir)
+	// the ports are removed, without modifying the given config
+	require.Equal(map[string]interface{}{config.LogLevelKey: "info"}, gotConfig.Flags)
+	require.Empty(gotConfig.NodeConfigs[0].Flags)
+	require.JSONEq(`{"log-level": "debug"}`, gotConfig.NodeConfigs[1].ConfigFile)
+	require.Equal(9650, networkConfig.Flags[config.HTTPPortKey])
+	require.Equal(9651, networkConfig.NodeConfigs[0].Flags[config.StakingPortKey])
+}
+
+func TestRunWithNetworkFailure(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	artifactsDir := t.TempDir()
+	var fakeNet *networkfakes.Network
+	opts := Options{
+		NewNetwork: func(networkConfig network.Config, rootDir string) (network.Network, error) {
+			require.NoError(os.WriteFile(filepath.Join(rootDir, "main.log"), []byte("log"), 0o600))
+			var err error
+			fakeNet, err = networkfakes.NewNetwork(networkConfig)
+			return fakeNet, err
+		},
+		ArtifactsDir: artifactsDir,
+	}
+	tb := &recordingTB{name: "TestFailing/sub"}
+	runRecorded(tb, network.Config{NodeConfigs: []node.Config{{Name: "node1"}}}, opts, func(network.Network) {
+		tb.Fatalf("boom")
+	})
+	require.True(tb.Failed())
+	_, err := fakeNet.GetNodeNames()
+	require.ErrorIs(err, network.ErrStopped)
+	require.FileExists(filepath.Join(artifactsDir, "TestFailing_sub", "main.log"))
+
+	// not healthy in time
+	opts.ArtifactsDir = ""
+	opts.HealthyTimeout = 10 * time.Millisecond
+	opts.NewNetwork = func(networkConfig network.Config, _ string) (network.Network, error) {
+		var err error
+		fakeNet, err = networkfakes.NewNetwork(networkConfig)
+		if err != nil {
+			return nil, err
+		}
+		return fakeNet, fakeNet.SetNodeHealthy("node1", false)
+	}
+	tb = &recordingTB{name: "TestUnhealthy"}
+	ran := false
+	runRecorded(tb, network.Config{NodeConfigs: []node.Config{{Name: "node1"}}}, opts, func(network.Network) {
+		ran = true
+	})
+	require.True(tb.Failed())
+	require.False(ran)
+	require.Contains(tb.logs[0], "network not healthy")
+	require.Contains(tb.logs[1], "network root data dir kept at")
+	_, err = fakeNet.GetNodeNames()
+	require.ErrorIs(err, network.ErrStopped)
+}
+
+// fakeM runs [run] as the tests of a package
+type fakeM struct {
+	run func() int
+}
+
+func (m *fakeM) Run() int {
+	return m.run()
+}
+
+func TestRunMain(t *testing.T) {
+	require := require.New(t)
+	var (
+		gotRootDir string
+		fakeNet    *networkfakes.Network
+	)
+	artifactsDir := t.TempDir()
+	opts := Options{
+		NewNetwork: func(networkConfig network.Config, rootDir string) (network.Network, error) {
+			gotRootDir = rootDir
+			require.NoError(os.WriteFile(filepath.Join(rootDir, "main.log"), []byte("log"), 0o600))
+			var err error
+			fakeNet, err = networkfakes.NewNetwork(networkConfig)
+			return fakeNet, err
+		},
+		ArtifactsDir: artifactsDir,
+	}
+	networkConfig := network.Config{NodeConfigs: []node.Config{{Name: "node1"}}}
+	require.Nil(Network())
+
+	// the tests pass, and share the network
+	var out strings.Builder
+	code := runMain(&fakeM{run: func() int {
+		require.Same(fakeNet, Network())
+		return 0
+	}}, networkConfig, opts, "pkg", &out)
+	require.Equal(0, code)
+	require.Nil(Network())
+	_, err := fakeNet.GetNodeNames()
+	require.ErrorIs(err, network.ErrStopped)
+	require.NoDirExists(gotRootDir)
+	require.Empty(out.String())
+
+	// a test fails
+	code = runMain(&fakeM{run: func() int { return 1 }}, networkConfig, opts, "pkg", &out)
+	require.Equal(1, code)
+	_, err = fakeNet.GetNodeNames()
+	require.ErrorIs(err, network.ErrStopped)
+	require.FileExists(filepath.Join(artifactsDir, "pkg", "main.log"))
+
+	// not healthy in time, the tests aren't run
+	opts.ArtifactsDir = ""
+	opts.HealthyTimeout = 10 * time.Millisecond
+	opts.NewNetwork = func(networkConfig network.Config, _ string) (network.Network, error) {
+		var err error
+		fakeNet, err = networkfakes.NewNetwork(networkConfig)
+		if err != nil {
+			return nil, err
+		}
+		return fakeNet, fakeNet.SetNodeHealthy("node1", false)
+	}
+	out.Reset()
+	ran := false
+	code = runMain(&fakeM{run: func() int {
+		ran = true
+		return 0
+	}}, networkConfig, opts, "pkg", &out)
+	require.Equal(1, code)
+	require.False(ran)
+	require.Contains(out.String(), "network not healthy")
+	require.Contains(out.String(), "network root data dir kept at")
+	_, err = fakeNet.GetNodeNames()
+	require.ErrorIs(err, network.ErrStopped)
+}
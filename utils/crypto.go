@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+)
+
+const stakingRSAKeyBits = 4096
+
+// StakingKeyType is the type of the key a staking cert is generated for
+type StakingKeyType int
+
+const (
+	// ECDSA P-256 key, as generated by avalanchego
+	ECDSAStakingKey StakingKeyType = iota
+	// 4096 bits RSA key
+	RSAStakingKey
+)
+
+// StakingCertOptions customize the certs generated by NewStakingCertAndKeyBytes.
+// The zero value gives the same kind of cert as staking.NewCertAndKeyBytes.
+type StakingCertOptions struct {
+	KeyType StakingKeyType
+	// Start of the validity period. Defaults to 2000-01-01.
+	NotBefore time.Time
+	// End of the validity period. Defaults to 100 years from now.
+	// May be in the past, to get an expired cert.
+	NotAfter time.Time
+	// Subject alternative names
+	DNSNames    []string
+	IPAddresses []net.IP
+	// If true, the cert can be used as CACertPEM to sign other certs
+	IsCA bool
+	// PEM encoded CA cert and key to sign the cert with.
+	// If not given, the cert is self-signed.
+	CACertPEM []byte
+	CAKeyPEM  []byte
+}
+
+// NewStakingCertAndKeyBytes creates a new staking cert and private key as
+// given by [opts]. Returns the PEM encoding of both.
+func NewStakingCertAndKeyBytes(opts StakingCertOptions) ([]byte, []byte, error) {
+	var (
+		key interface{}
+		pub interface{}
+	)
+	switch opts.KeyType {
+	case ECDSAStakingKey:
+		ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't generate ecdsa key: %w", err)
+		}
+		key, pub = ecdsaKey, ecdsaKey.Public()
+	case RSAStakingKey:
+		rsaKey, err := rsa.GenerateKey(rand.Reader, stakingRSAKeyBits)
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't generate rsa key: %w", err)
+		}
+		key, pub = rsaKey, rsaKey.Public()
+	default:
+		return nil, nil, fmt.Errorf("unknown staking key type %d", opts.KeyType)
+	}
+
+	notBefore := opts.NotBefore
+	if notBefore.IsZero() {
+		notBefore = time.Date(2000, time.January, 0, 0, 0, 0, 0, time.UTC)
+	}
+	notAfter := opts.NotAfter
+	if notAfter.IsZero() {
+		notAfter = time.Now().AddDate(100, 0, 0)
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't generate serial number: %w", err)
+	}
+	certTemplate := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		DNSNames:              opts.DNSNames,
+		IPAddresses:           opts.IPAddresses,
+	}
+	if opts.IsCA {
+		certTemplate.IsCA = true
+		certTemplate.KeyUsage |= x509.KeyUsageCertSign
+	}
+
+	// self-signed unless a CA is given
+	parent, signer := certTemplate, key
+	if len(opts.CACertPEM) != 0 || len(opts.CAKeyPEM) != 0 {
+		ca, err := tls.X509KeyPair(opts.CACertPEM, opts.CAKeyPEM)
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't load CA cert and key: %w", err)
+		}
+		parent, err = x509.ParseCertificate(ca.Certificate[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't parse CA cert: %w", err)
+		}
+		signer = ca.PrivateKey
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, certTemplate, parent, pub, signer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't create certificate: %w", err)
+	}
+	var certBuff bytes.Buffer
+	if err := pem.Encode(&certBuff, &pem.Block{Type: "CERTIFICATE", Bytes: certBytes}); err != nil {
+		return nil, nil, fmt.Errorf("couldn't encode cert: %w", err)
+	}
+
+	privBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't marshal private key: %w", err)
+	}
+	var keyBuff bytes.Buffer
+	if err := pem.Encode(&keyBuff, &pem.Block{Type: "PRIVATE KEY", Bytes: privBytes}); err != nil {
+		return nil, nil, fmt.Errorf("couldn't encode private key: %w", err)
+	}
+	return certBuff.Bytes(), keyBuff.Bytes(), nil
+}
+
+// LoadStakingCertAndKeyBytes reads a PEM encoded staking cert and private key
+// from [certPath] and [keyPath], and checks that they can be used by a node.
+// Returns the PEM encoding of both, e.g. for node.Config.
+func LoadStakingCertAndKeyBytes(certPath string, keyPath string) ([]byte, []byte, error) {
+	certBytes, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't read staking cert: %w", err)
+	}
+	keyBytes, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't read staking key: %w", err)
+	}
+	if _, err := ToNodeID(keyBytes, certBytes); err != nil {
+		return nil, nil, fmt.Errorf("invalid staking cert and key: %w", err)
+	}
+	return certBytes, keyBytes, nil
+}
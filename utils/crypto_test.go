@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseCertPEM(t *testing.T, certPEM []byte) *x509.Certificate {
+	block, _ := pem.Decode(certPEM)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestNewStakingCertAndKeyBytes(t *testing.T) {
+	require := require.New(t)
+
+	certPEM, keyPEM, err := NewStakingCertAndKeyBytes(StakingCertOptions{})
+	require.NoError(err)
+	_, err = ToNodeID(keyPEM, certPEM)
+	require.NoError(err)
+	require.IsType(&ecdsa.PublicKey{}, parseCertPEM(t, certPEM).PublicKey)
+
+	// expired, with SANs, and of RSA type
+	notAfter := time.Now().Add(-time.Hour).Truncate(time.Second)
+	certPEM, keyPEM, err = NewStakingCertAndKeyBytes(StakingCertOptions{
+		KeyType:     RSAStakingKey,
+		NotBefore:   notAfter.Add(-time.Hour),
+		NotAfter:    notAfter,
+		DNSNames:    []string{"node1.local"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+	})
+	require.NoError(err)
+	_, err = ToNodeID(keyPEM, certPEM)
+	require.NoError(err)
+	cert := parseCertPEM(t, certPEM)
+	require.IsType(&rsa.PublicKey{}, cert.PublicKey)
+	require.True(cert.NotAfter.Equal(notAfter))
+	require.Equal([]string{"node1.local"}, cert.DNSNames)
+	require.Len(cert.IPAddresses, 1)
+
+	// signed by a custom CA
+	caCertPEM, caKeyPEM, err := NewStakingCertAndKeyBytes(StakingCertOptions{IsCA: true})
+	require.NoError(err)
+	certPEM, _, err = NewStakingCertAndKeyBytes(StakingCertOptions{
+		CACertPEM: caCertPEM,
+		CAKeyPEM:  caKeyPEM,
+	})
+	require.NoError(err)
+	roots := x509.NewCertPool()
+	roots.AddCert(parseCertPEM(t, caCertPEM))
+	_, err = parseCertPEM(t, certPEM).Verify(x509.VerifyOptions{Roots: roots})
+	require.NoError(err)
+
+	_, _, err = NewStakingCertAndKeyBytes(StakingCertOptions{CACertPEM: caCertPEM})
+	require.Error(err)
+}
+
+func TestLoadStakingCertAndKeyBytes(t *testing.T) {
+	require := require.New(t)
+	certPEM, keyPEM, err := NewStakingCertAndKeyBytes(StakingCertOptions{})
+	require.NoError(err)
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "staker.crt")
+	keyPath := filepath.Join(dir, "staker.key")
+	require.NoError(os.WriteFile(certPath, certPEM, 0o600))
+	require.NoError(os.WriteFile(keyPath, keyPEM, 0o600))
+
+	loadedCert, loadedKey, err := LoadStakingCertAndKeyBytes(certPath, keyPath)
+	require.NoError(err)
+	require.Equal(certPEM, loadedCert)
+	require.Equal(keyPEM, loadedKey)
+
+	// cert and key that don't match
+	_, otherKeyPEM, err := NewStakingCertAndKeyBytes(StakingCertOptions{})
+	require.NoError(err)
+	require.NoError(os.WriteFile(keyPath, otherKeyPEM, 0o600))
+	_, _, err = LoadStakingCertAndKeyBytes(certPath, keyPath)
+	require.Error(err)
+}
@@ -8,9 +8,6 @@ import (
 	"time"
 
 	"github.com/ava-labs/avalanchego/upgrade"
-	"github.com/ava-labs/avalanchego/utils/crypto/bls"
-	"github.com/ava-labs/avalanchego/utils/formatting"
-	"github.com/ava-labs/avalanchego/vms/platformvm/signer"
 	coreth_params "github.com/ava-labs/coreth/params"
 )
 
@@ -68,20 +65,11 @@ func GenerateGenesis(
 	initialStakers := []map[string]interface{}{}
 
 	for _, keys := range nodeKeys {
-		nodeID, err := ToNodeID(keys.StakingKey, keys.StakingCert)
+		nodeID, err := keys.NodeID()
 		if err != nil {
 			return nil, fmt.Errorf("couldn't get node ID: %w", err)
 		}
-		blsSk, err := bls.SecretKeyFromBytes(keys.BlsKey)
-		if err != nil {
-			return nil, err
-		}
-		p := signer.NewProofOfPossession(blsSk)
-		pk, err := formatting.Encode(formatting.HexNC, p.PublicKey[:])
-		if err != nil {
-			return nil, err
-		}
-		pop, err := formatting.Encode(formatting.HexNC, p.ProofOfPossession[:])
+		pop, err := ProofOfPossessionFromBLSKey(keys.BlsKey)
 		if err != nil {
 			return nil, err
 		}
@@ -89,10 +77,7 @@ func GenerateGenesis(
 			"delegationFee": 1000000,
 			"nodeID":        nodeID,
 			"rewardAddress": walletAddr,
-			"signer": map[string]interface{}{
-				"proofOfPossession": pop,
-				"publicKey":         pk,
-			},
+			"signer":        pop,
 		}
 		initialStakers = append(initialStakers, initialStaker)
 	}
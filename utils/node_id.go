@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/vms/platformvm/signer"
+)
+
+// NodeIDFromCert returns the ID of the node using the staking cert
+// [certBytes], PEM or DER encoded, without needing its private key,
+// e.g. to build genesis validator lists or test expectations.
+func NodeIDFromCert(certBytes []byte) (ids.NodeID, error) {
+	if block, _ := pem.Decode(certBytes); block != nil {
+		certBytes = block.Bytes
+	}
+	cert, err := staking.ParseCertificate(certBytes)
+	if err != nil {
+		return ids.EmptyNodeID, fmt.Errorf("couldn't parse staking cert: %w", err)
+	}
+	return ids.NodeIDFromCert(cert), nil
+}
+
+// NodeIDFromCertFile returns the ID of the node using the
+// staking cert at [certPath]. See NodeIDFromCert.
+func NodeIDFromCertFile(certPath string) (ids.NodeID, error) {
+	certBytes, err := os.ReadFile(certPath)
+	if err != nil {
+		return ids.EmptyNodeID, fmt.Errorf("couldn't read staking cert: %w", err)
+	}
+	return NodeIDFromCert(certBytes)
+}
+
+// ProofOfPossessionFromBLSKey returns the BLS public key and proof of
+// possession of the node using the BLS secret key [blsKey], as given on
+// NodeKeys. It marshals to the "signer" of genesis initial stakers.
+func ProofOfPossessionFromBLSKey(blsKey []byte) (*signer.ProofOfPossession, error) {
+	sk, err := bls.SecretKeyFromBytes(blsKey)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse BLS key: %w", err)
+	}
+	return signer.NewProofOfPossession(sk), nil
+}
+
+// NodeID returns the ID of the node using these keys
+func (k *NodeKeys) NodeID() (ids.NodeID, error) {
+	return ToNodeID(k.StakingKey, k.StakingCert)
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeIDFromCert(t *testing.T) {
+	require := require.New(t)
+
+	nodesKeys, err := GenerateKeysForNodes(1)
+	require.NoError(err)
+	keys := nodesKeys[0]
+	expectedNodeID, err := keys.NodeID()
+	require.NoError(err)
+
+	nodeID, err := NodeIDFromCert(keys.StakingCert)
+	require.NoError(err)
+	require.Equal(expectedNodeID, nodeID)
+
+	block, _ := pem.Decode(keys.StakingCert)
+	require.NotNil(block)
+	nodeID, err = NodeIDFromCert(block.Bytes)
+	require.NoError(err)
+	require.Equal(expectedNodeID, nodeID)
+
+	certPath := filepath.Join(t.TempDir(), "staker.crt")
+	require.NoError(os.WriteFile(certPath, keys.StakingCert, 0o600))
+	nodeID, err = NodeIDFromCertFile(certPath)
+	require.NoError(err)
+	require.Equal(expectedNodeID, nodeID)
+
+	_, err = NodeIDFromCert([]byte("not a cert"))
+	require.Error(err)
+	_, err = NodeIDFromCertFile(filepath.Join(t.TempDir(), "missing.crt"))
+	require.Error(err)
+}
+
+func TestProofOfPossessionFromBLSKey(t *testing.T) {
+	require := require.New(t)
+
+	nodesKeys, err := GenerateKeysForNodes(1)
+	require.NoError(err)
+	pop, err := ProofOfPossessionFromBLSKey(nodesKeys[0].BlsKey)
+	require.NoError(err)
+	require.NoError(pop.Verify())
+
+	// as given on genesis initial stakers
+	popJSON, err := json.Marshal(pop)
+	require.NoError(err)
+	signer := map[string]string{}
+	require.NoError(json.Unmarshal(popJSON, &signer))
+	require.Contains(signer, "publicKey")
+	require.Contains(signer, "proofOfPossession")
+
+	_, err = ProofOfPossessionFromBLSKey([]byte("not a key"))
+	require.Error(err)
+}
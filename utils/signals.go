@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Stopper is implemented by network.Network. It is defined here,
+// as the network package depends on this one.
+type Stopper interface {
+	Stop(context.Context) error
+}
+
+// WatchShutdownSignals stops [net] on the first SIGINT or SIGTERM received.
+// If a second signal is received while the network is stopping, the context
+// given to Stop is cancelled, so that the nodes still running are killed.
+// The returned channel receives the result of Stop.
+// If [ctx] is done before a signal is received, the signals are no longer
+// handled and the returned channel is closed.
+func WatchShutdownSignals(ctx context.Context, net Stopper) <-chan error {
+	sigChan := make(chan os.Signal, 2)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	errChan := make(chan error, 1)
+	go func() {
+		defer signal.Stop(sigChan)
+		select {
+		case <-ctx.Done():
+			close(errChan)
+			return
+		case <-sigChan:
+		}
+		stopCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stopErrChan := make(chan error, 1)
+		go func() {
+			stopErrChan <- net.Stop(stopCtx)
+		}()
+		select {
+		case err := <-stopErrChan:
+			errChan <- err
+		case <-sigChan:
+			// force kill
+			cancel()
+			errChan <- <-stopErrChan
+		}
+	}()
+	return errChan
+}
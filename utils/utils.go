@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/netip"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -116,9 +118,22 @@ func DirnameWithTimestamp(dirPrefix string) string {
 	return dirPrefix + "_" + currentTime
 }
 
+// MkDirWithTimestamp creates a new dir named [dirPrefix] followed by the
+// current time. If the dir exists, e.g. as it was created on the same second
+// by another network, a counter is appended, so that each call gets its own dir.
 func MkDirWithTimestamp(dirPrefix string) (string, error) {
 	dirName := DirnameWithTimestamp(dirPrefix)
-	return dirName, os.MkdirAll(dirName, os.ModePerm)
+	if err := os.MkdirAll(filepath.Dir(dirName), os.ModePerm); err != nil {
+		return "", err
+	}
+	uniqueDirName := dirName
+	for i := 2; ; i++ {
+		err := os.Mkdir(uniqueDirName, os.ModePerm)
+		if !errors.Is(err, fs.ErrExist) {
+			return uniqueDirName, err
+		}
+		uniqueDirName = fmt.Sprintf("%s_%d", dirName, i)
+	}
 }
 
 func VerifySubnetHasCorrectParticipants(
@@ -209,6 +224,17 @@ func IsCustomNetwork(networkID uint32) bool {
 	return !IsPublicNetwork(networkID) && networkID != constants.LocalID
 }
 
+// GetFreePort returns a TCP port that is free on localhost
+func GetFreePort() (uint16, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	port := uint16(l.Addr().(*net.TCPAddr).Port)
+	_ = l.Close()
+	return port, nil
+}
+
 func BeaconMapToSet(beaconMap map[ids.NodeID]netip.AddrPort) (beacon.Set, error) {
 	set := beacon.NewSet()
 	for id, addr := range beaconMap {
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"syscall"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -123,3 +125,42 @@ func TestCheckPluginPaths(t *testing.T) {
 		require.Equal(t, tv.expectedErr, err, fmt.Sprintf("[%d] unexpected error", i))
 	}
 }
+
+type blockingStopper struct {
+	stopCalled chan struct{}
+}
+
+func (s *blockingStopper) Stop(ctx context.Context) error {
+	close(s.stopCalled)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+type nopStopper struct{}
+
+func (*nopStopper) Stop(context.Context) error {
+	return nil
+}
+
+func TestWatchShutdownSignals(t *testing.T) {
+	require := require.New(t)
+
+	errChan := WatchShutdownSignals(context.Background(), &nopStopper{})
+	require.NoError(syscall.Kill(os.Getpid(), syscall.SIGTERM))
+	require.NoError(<-errChan)
+
+	// second signal cancels the stop context
+	stopper := &blockingStopper{stopCalled: make(chan struct{})}
+	errChan = WatchShutdownSignals(context.Background(), stopper)
+	require.NoError(syscall.Kill(os.Getpid(), syscall.SIGINT))
+	<-stopper.stopCalled
+	require.NoError(syscall.Kill(os.Getpid(), syscall.SIGINT))
+	require.ErrorIs(<-errChan, context.Canceled)
+
+	// cancelling the context unregisters the handler without stopping
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan = WatchShutdownSignals(ctx, stopper)
+	cancel()
+	_, ok := <-errChan
+	require.False(ok)
+}